@@ -0,0 +1,122 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/apiserver"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var backupNamespace, backupFile, backupURL, backupTimeout string
+
+// backupSPIURL returns the URL of the SPI from the command line, or from the config file of the node
+func backupSPIURL() string {
+	if backupURL != "" {
+		return strings.TrimSuffix(backupURL, "/")
+	}
+	coreconfig.Reset()
+	apiserver.InitConfig()
+	_ = config.ReadConfig(configSuffix, cfgFile)
+	return apiserver.SPIURL()
+}
+
+func backupRequest(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	url := fmt.Sprintf("%s/namespaces/%s/%s", backupSPIURL(), backupNamespace, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Request-Timeout", backupTimeout)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		errBody, _ := ioutil.ReadAll(res.Body)
+		return nil, i18n.NewError(ctx, coremsgs.MsgSPIRequestFailed, url, res.StatusCode, strings.TrimSpace(string(errBody)))
+	}
+	return res, nil
+}
+
+func exportNamespace(ctx context.Context, out io.Writer) error {
+	res, err := backupRequest(ctx, http.MethodGet, "export", nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(out, res.Body)
+	return err
+}
+
+func importNamespace(ctx context.Context, in io.Reader) (*core.NamespaceImportResult, error) {
+	// Stream the file as a multi-part upload, so the node does not need to hold it in memory
+	body, writer := io.Pipe()
+	mpw := multipart.NewWriter(writer)
+	go func() {
+		part, err := mpw.CreateFormFile("file", backupNamespace+".ndjson")
+		if err == nil {
+			_, err = io.Copy(part, in)
+		}
+		if err == nil {
+			err = mpw.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	res, err := backupRequest(ctx, http.MethodPost, "import", body, mpw.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var result core.NamespaceImportResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func printImportResult(result *core.NamespaceImportResult) {
+	collections := make([]string, 0, len(result.Records))
+	for collection := range result.Records {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	fmt.Printf("Imported into namespace '%s'\n", result.Namespace)
+	fmt.Printf("%-32s %v\n", "Collection", "Records")
+	fmt.Print("----------------------------------------\n")
+	for _, collection := range collections {
+		fmt.Printf("%-32s %d\n", collection, result.Records[collection])
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testExport = `{"collection":"namespace","record":{"name":"ns1"}}
+{"collection":"datatypes","record":{"namespace":"ns1"}}
+`
+
+func TestExportNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "/spi/v1/namespaces/ns1/export", req.URL.Path)
+		assert.Equal(t, "1h", req.Header.Get("Request-Timeout"))
+		res.Header().Set("Content-Type", "application/octet-stream")
+		res.Write([]byte(testExport))
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	exportFile := filepath.Join(tmpDir, "ns1.ndjson")
+	rootCmd.SetArgs([]string{"export", "-n", "ns1", "--url", server.URL + "/spi/v1/", "--timeout", "1h", "--file", exportFile})
+	defer rootCmd.SetArgs([]string{})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadFile(exportFile)
+	assert.NoError(t, err)
+	assert.Equal(t, testExport, string(b))
+}
+
+func TestExportNamespaceFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusNotFound)
+		res.Write([]byte(`{"error":"not found"}`))
+	}))
+	defer server.Close()
+
+	rootCmd.SetArgs([]string{"export", "-n", "ns1", "--url", server.URL, "--file", filepath.Join(t.TempDir(), "ns1.ndjson")})
+	defer rootCmd.SetArgs([]string{})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF10458.*404.*not found", err)
+}
+
+func TestExportNamespaceBadFile(t *testing.T) {
+	rootCmd.SetArgs([]string{"export", "--url", "http://localhost:5101/spi/v1", "--file", filepath.Join(t.TempDir(), "missing", "ns1.ndjson")})
+	defer rootCmd.SetArgs([]string{})
+	err := rootCmd.Execute()
+	assert.Error(t, err)
+}
+
+func TestImportNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/spi/v1/namespaces/ns1/import", req.URL.Path)
+		part, header, err := req.FormFile("file")
+		assert.NoError(t, err)
+		assert.Equal(t, "ns1.ndjson", header.Filename)
+		b, _ := ioutil.ReadAll(part)
+		assert.Equal(t, testExport, string(b))
+		res.Header().Set("Content-Type", "application/json")
+		res.Write([]byte(`{"namespace":"ns1","records":{"datatypes":1,"data":2}}`))
+	}))
+	defer server.Close()
+
+	importFile := filepath.Join(t.TempDir(), "ns1.ndjson")
+	err := ioutil.WriteFile(importFile, []byte(testExport), 0600)
+	assert.NoError(t, err)
+	rootCmd.SetArgs([]string{"import", "-n", "ns1", "--url", server.URL + "/spi/v1", "--file", importFile})
+	defer rootCmd.SetArgs([]string{})
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+}
+
+func TestImportNamespaceFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusConflict)
+		res.Write([]byte(`{"error":"FF10453: not empty"}`))
+	}))
+	defer server.Close()
+
+	importFile := filepath.Join(t.TempDir(), "ns1.ndjson")
+	err := ioutil.WriteFile(importFile, []byte(testExport), 0600)
+	assert.NoError(t, err)
+	rootCmd.SetArgs([]string{"import", "-n", "ns1", "--url", server.URL, "--file", importFile})
+	defer rootCmd.SetArgs([]string{})
+	err = rootCmd.Execute()
+	assert.Regexp(t, "FF10458.*409.*FF10453", err)
+}
+
+func TestImportNamespaceBadResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`!json`))
+	}))
+	defer server.Close()
+
+	importFile := filepath.Join(t.TempDir(), "ns1.ndjson")
+	err := ioutil.WriteFile(importFile, []byte(testExport), 0600)
+	assert.NoError(t, err)
+	rootCmd.SetArgs([]string{"import", "-n", "ns1", "--url", server.URL, "--file", importFile})
+	defer rootCmd.SetArgs([]string{})
+	err = rootCmd.Execute()
+	assert.Regexp(t, "invalid character", err)
+}
+
+func TestImportNamespaceBadFile(t *testing.T) {
+	rootCmd.SetArgs([]string{"import", "--url", "http://localhost:5101/spi/v1", "--file", filepath.Join(t.TempDir(), "missing.ndjson")})
+	defer rootCmd.SetArgs([]string{})
+	err := rootCmd.Execute()
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestBackupSPIURLFromConfig(t *testing.T) {
+	backupURL = ""
+	assert.Regexp(t, "^http://.*/spi/v1$", backupSPIURL())
+}
@@ -70,6 +70,45 @@ var showConfigCommand = &cobra.Command{
 	},
 }
 
+var exportCommand = &cobra.Command{
+	Use:   "export",
+	Short: "Export every record of a namespace as newline delimited JSON, from the SPI of a running node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := os.Stdout
+		if backupFile != "" {
+			f, err := os.Create(backupFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		return exportNamespace(context.Background(), out)
+	},
+}
+
+var importCommand = &cobra.Command{
+	Use:   "import",
+	Short: "Import an export into an empty namespace, through the SPI of a running node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		in := os.Stdin
+		if backupFile != "" {
+			f, err := os.Open(backupFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		result, err := importNamespace(context.Background(), in)
+		if err != nil {
+			return err
+		}
+		printImportResult(result)
+		return nil
+	},
+}
+
 var cfgFile string
 
 var _utManager namespace.Manager
@@ -77,6 +116,13 @@ var _utManager namespace.Manager
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "f", "", "config file")
 	rootCmd.AddCommand(showConfigCommand)
+	for _, c := range []*cobra.Command{exportCommand, importCommand} {
+		c.Flags().StringVarP(&backupNamespace, "namespace", "n", "default", "the namespace to export or import")
+		c.Flags().StringVar(&backupFile, "file", "", "the file to write the export to, or read the import from (default stdout/stdin)")
+		c.Flags().StringVarP(&backupURL, "url", "u", "", "the base URL of the SPI, such as http://127.0.0.1:5101/spi/v1 (default from the config file)")
+		c.Flags().StringVarP(&backupTimeout, "timeout", "t", "10m", "the request timeout to ask the node to apply")
+		rootCmd.AddCommand(c)
+	}
 }
 
 func getRootManager() namespace.Manager {
@@ -0,0 +1,158 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/namespace"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+var migratePlugin string
+
+var _utMigrator database.Migrator
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the migrations of the database schema, using the database plugin configuration of the node",
+	Long: `Manage the migrations of the database schema, as a separate step to starting the node.
+The node refuses to start if the schema version does not match the one this build requires.`,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the available migrations, and which have been applied to the database",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigration(context.Background(), nil, true)
+	},
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all the pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigration(context.Background(), func(ctx context.Context, migrator database.Migrator) error {
+			return migrator.MigrateUp(ctx)
+		}, false)
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down N",
+	Short: "Revert the last N migrations",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		steps, err := strconv.Atoi(args[0])
+		if err != nil || steps < 1 {
+			return i18n.NewError(ctx, coremsgs.MsgInvalidMigrationArg, "number of migrations", args[0])
+		}
+		return runMigration(ctx, func(ctx context.Context, migrator database.Migrator) error {
+			return migrator.MigrateDown(ctx, steps)
+		}, false)
+	},
+}
+
+var migrateGotoCmd = &cobra.Command{
+	Use:   "goto V",
+	Short: "Apply or revert migrations until the schema is at version V",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		version, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return i18n.NewError(ctx, coremsgs.MsgInvalidMigrationArg, "version", args[0])
+		}
+		return runMigration(ctx, func(ctx context.Context, migrator database.Migrator) error {
+			return migrator.MigrateTo(ctx, uint(version))
+		}, false)
+	},
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringVarP(&migratePlugin, "plugin", "p", "", "the name of the database plugin to migrate, when more than one is configured")
+	migrateCmd.AddCommand(migrateStatusCmd, migrateUpCmd, migrateDownCmd, migrateGotoCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// getMigrator returns the database plugin to migrate, from the config file of the node
+func getMigrator(ctx context.Context) (database.Migrator, error) {
+	if _utMigrator != nil {
+		return _utMigrator, nil
+	}
+	coreconfig.Reset()
+	getRootManager()
+	if err := config.ReadConfig(configSuffix, cfgFile); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
+	}
+	return namespace.GetDatabaseMigrator(ctx, migratePlugin)
+}
+
+// runMigration runs an optional change to the schema, then prints the status of the schema afterwards
+func runMigration(ctx context.Context, change func(ctx context.Context, migrator database.Migrator) error, listMigrations bool) error {
+	migrator, err := getMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	if change != nil {
+		if err := change(ctx, migrator); err != nil {
+			return err
+		}
+	}
+	status, err := migrator.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+	printMigrationStatus(status, listMigrations)
+	return nil
+}
+
+func printMigrationStatus(status *database.MigrationStatus, listMigrations bool) {
+	pending := 0
+	for _, m := range status.Migrations {
+		if !m.Applied {
+			pending++
+		}
+	}
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty)"
+	}
+	fmt.Printf("Schema version %d%s, with %d pending migrations. This build requires version %d\n", status.Version, dirty, pending, status.Required)
+	if !listMigrations {
+		return
+	}
+	fmt.Printf("%-10s %-8s %v\n", "Version", "Applied", "Name")
+	fmt.Print("----------------------------------------------------------------\n")
+	for _, m := range status.Migrations {
+		applied := "no"
+		if m.Applied {
+			applied = "yes"
+		}
+		fmt.Printf("%-10d %-8s %s\n", m.Version, applied, m.Name)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// resolved before any test changes the working directory
+var testMigrationsDir, _ = filepath.Abs("../db/migrations/sqlite")
+
+func newTestMigrator(t *testing.T) *databasemocks.Migrator {
+	mmg := &databasemocks.Migrator{}
+	_utMigrator = mmg
+	t.Cleanup(func() {
+		_utMigrator = nil
+		rootCmd.SetArgs([]string{})
+		mmg.AssertExpectations(t)
+	})
+	return mmg
+}
+
+func testMigrationStatus(version uint) *database.MigrationStatus {
+	return &database.MigrationStatus{
+		Version:  version,
+		Required: 2,
+		Migrations: []*database.Migration{
+			{Version: 1, Name: "create_one", Applied: version >= 1},
+			{Version: 2, Name: "create_two", Applied: version >= 2},
+		},
+	}
+}
+
+func TestMigrateWithDB(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "firefly.core.yaml")
+	err := ioutil.WriteFile(configFile, []byte(fmt.Sprintf(`
+plugins:
+  database:
+  - name: database0
+    type: sqlitego
+    sqlitego:
+      url: %s
+      migrations:
+        directory: %s
+`, filepath.Join(dir, "firefly.db"), testMigrationsDir)), 0644)
+	assert.NoError(t, err)
+	defer func() {
+		cfgFile = ""
+		migratePlugin = ""
+		rootCmd.SetArgs([]string{})
+	}()
+
+	for _, args := range [][]string{
+		{"migrate", "status", "-f", configFile},
+		{"migrate", "up", "-f", configFile},
+		{"migrate", "down", "2", "-f", configFile},
+		{"migrate", "goto", "0", "-f", configFile, "-p", "database0"},
+	} {
+		rootCmd.SetArgs(args)
+		err = rootCmd.Execute()
+		assert.NoError(t, err)
+	}
+}
+
+func TestMigrateConfigFail(t *testing.T) {
+	defer func() {
+		cfgFile = ""
+		rootCmd.SetArgs([]string{})
+	}()
+	rootCmd.SetArgs([]string{"migrate", "status", "-f", filepath.Join(t.TempDir(), "missing.yaml")})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF00101", err)
+}
+
+func TestMigrateStatus(t *testing.T) {
+	mmg := newTestMigrator(t)
+	status := testMigrationStatus(1)
+	status.Dirty = true
+	mmg.On("MigrationStatus", mock.Anything).Return(status, nil)
+
+	rootCmd.SetArgs([]string{"migrate", "status"})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+}
+
+func TestMigrateStatusFail(t *testing.T) {
+	mmg := newTestMigrator(t)
+	mmg.On("MigrationStatus", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	rootCmd.SetArgs([]string{"migrate", "status"})
+	err := rootCmd.Execute()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestMigrateUp(t *testing.T) {
+	mmg := newTestMigrator(t)
+	mmg.On("MigrateUp", mock.Anything).Return(nil)
+	mmg.On("MigrationStatus", mock.Anything).Return(testMigrationStatus(2), nil)
+
+	rootCmd.SetArgs([]string{"migrate", "up"})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+}
+
+func TestMigrateUpFail(t *testing.T) {
+	mmg := newTestMigrator(t)
+	mmg.On("MigrateUp", mock.Anything).Return(fmt.Errorf("pop"))
+
+	rootCmd.SetArgs([]string{"migrate", "up"})
+	err := rootCmd.Execute()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestMigrateDown(t *testing.T) {
+	mmg := newTestMigrator(t)
+	mmg.On("MigrateDown", mock.Anything, 1).Return(nil)
+	mmg.On("MigrationStatus", mock.Anything).Return(testMigrationStatus(1), nil)
+
+	rootCmd.SetArgs([]string{"migrate", "down", "1"})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+}
+
+func TestMigrateDownBadSteps(t *testing.T) {
+	newTestMigrator(t)
+
+	rootCmd.SetArgs([]string{"migrate", "down", "0"})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF10468", err)
+}
+
+func TestMigrateGoto(t *testing.T) {
+	mmg := newTestMigrator(t)
+	mmg.On("MigrateTo", mock.Anything, uint(1)).Return(nil)
+	mmg.On("MigrationStatus", mock.Anything).Return(testMigrationStatus(1), nil)
+
+	rootCmd.SetArgs([]string{"migrate", "goto", "1"})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+}
+
+func TestMigrateGotoBadVersion(t *testing.T) {
+	newTestMigrator(t)
+
+	rootCmd.SetArgs([]string{"migrate", "goto", "latest"})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF10468", err)
+}
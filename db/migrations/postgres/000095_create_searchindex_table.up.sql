@@ -0,0 +1,23 @@
+BEGIN;
+CREATE TABLE searchindex (
+  seq         SERIAL          PRIMARY KEY,
+  namespace   VARCHAR(64)     NOT NULL,
+  hit_type    VARCHAR(64)     NOT NULL,
+  id          UUID            NOT NULL,
+  content     TEXT            NOT NULL
+);
+
+CREATE INDEX searchindex_id ON searchindex(hit_type, id);
+CREATE INDEX searchindex_content ON searchindex USING GIN(to_tsvector('simple', content));
+
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'message', id, concat_ws(' ', tag, topics, author) FROM messages;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'data', id, concat_ws(' ', datatype_name, blob_name, value) FROM data;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'identity', id, concat_ws(' ', name, did, description) FROM identities;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'tokenpool', id, concat_ws(' ', name, symbol) FROM tokenpool;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'ffi', id, concat_ws(' ', name, version, description) FROM ffi;
+COMMIT;
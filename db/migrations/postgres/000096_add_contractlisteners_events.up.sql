@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE contractlisteners ADD COLUMN events TEXT;
+COMMIT;
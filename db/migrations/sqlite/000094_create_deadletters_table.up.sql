@@ -0,0 +1,16 @@
+CREATE TABLE deadletters (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  subscription_id   UUID            NOT NULL,
+  subscription_name VARCHAR(64)     NOT NULL,
+  transport         VARCHAR(64)     NOT NULL,
+  event_id          UUID            NOT NULL,
+  attempts          INTEGER         NOT NULL,
+  error             TEXT,
+  last_response     TEXT,
+  created           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX deadletters_id ON deadletters(id);
+CREATE INDEX deadletters_subscription ON deadletters(namespace, subscription_id);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS searchindex_insert;
+DROP TRIGGER IF EXISTS searchindex_delete;
+DROP TABLE IF EXISTS searchindex_fts;
+DROP TABLE IF EXISTS searchindex;
@@ -0,0 +1,20 @@
+CREATE TABLE searchindex (
+  seq         INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace   VARCHAR(64)     NOT NULL,
+  hit_type    VARCHAR(64)     NOT NULL,
+  id          UUID            NOT NULL,
+  content     TEXT            NOT NULL
+);
+
+CREATE INDEX searchindex_id ON searchindex(hit_type, id);
+
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'message', id, trim(coalesce(tag, '') || ' ' || coalesce(topics, '') || ' ' || coalesce(author, '')) FROM messages;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'data', id, trim(coalesce(datatype_name, '') || ' ' || coalesce(blob_name, '') || ' ' || coalesce(value, '')) FROM data;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'identity', id, trim(coalesce(name, '') || ' ' || coalesce(did, '') || ' ' || coalesce(description, '')) FROM identities;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'tokenpool', id, trim(coalesce(name, '') || ' ' || coalesce(symbol, '')) FROM tokenpool;
+INSERT INTO searchindex (namespace, hit_type, id, content)
+  SELECT namespace, 'ffi', id, trim(coalesce(name, '') || ' ' || coalesce(version, '') || ' ' || coalesce(description, '')) FROM ffi;
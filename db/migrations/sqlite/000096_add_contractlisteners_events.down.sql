@@ -0,0 +1 @@
+ALTER TABLE contractlisteners DROP COLUMN events;
@@ -0,0 +1 @@
+ALTER TABLE contractlisteners ADD COLUMN events TEXT;
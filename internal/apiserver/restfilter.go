@@ -34,7 +34,9 @@ import (
 
 type filterResultsWithCount struct {
 	Count int64       `json:"count"`
-	Total int64       `json:"total"`
+	Total *int64      `json:"total,omitempty"`
+	Next  string      `json:"next,omitempty"`
+	Prev  string      `json:"prev,omitempty"`
 	Items interface{} `json:"items"`
 }
 
@@ -53,11 +55,13 @@ func syncRetcode(isSync bool) int {
 
 func filterResult(items interface{}, res *database.FilterResult, err error) (interface{}, error) {
 	itemsVal := reflect.ValueOf(items)
-	if err != nil || res == nil || res.TotalCount == nil || itemsVal.Kind() != reflect.Slice {
+	if err != nil || res == nil || (res.TotalCount == nil && res.Next == "" && res.Prev == "") || itemsVal.Kind() != reflect.Slice {
 		return items, err
 	}
 	return &filterResultsWithCount{
-		Total: *res.TotalCount,
+		Total: res.TotalCount,
+		Next:  res.Next,
+		Prev:  res.Prev,
 		Count: int64(itemsVal.Len()),
 		Items: items,
 	}, nil
@@ -73,6 +77,47 @@ func (as *apiServer) getValues(values url.Values, key string) (results []string)
 	return results
 }
 
+// getJSONPathFields returns the query parameters that filter on a path inside a JSON field, such as "value.orderId".
+// The path is case sensitive, so these are not matched case insensitively like other fields
+func (as *apiServer) getJSONPathFields(values url.Values, jsonFields []string) (results []string) {
+	for queryName := range values {
+		dot := strings.Index(queryName, ".")
+		if dot <= 0 {
+			continue
+		}
+		for _, jsonField := range jsonFields {
+			if strings.EqualFold(queryName[:dot], jsonField) {
+				results = append(results, queryName)
+				break
+			}
+		}
+	}
+	sort.Strings(results)
+	return results
+}
+
+func (as *apiServer) addFieldConditions(ctx context.Context, fb database.FilterBuilder, filter database.AndFilter, field string, values []string) error {
+	if len(values) == 1 {
+		cond, err := as.getCondition(ctx, fb, field, values[0])
+		if err != nil {
+			return err
+		}
+		filter.Condition(cond)
+	} else if len(values) > 0 {
+		sort.Strings(values)
+		fs := make([]database.Filter, len(values))
+		for i, value := range values {
+			cond, err := as.getCondition(ctx, fb, field, value)
+			if err != nil {
+				return err
+			}
+			fs[i] = cond
+		}
+		filter.Condition(fb.Or(fs...))
+	}
+	return nil
+}
+
 func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (database.AndFilter, error) {
 	ctx := req.Context()
 	log.L(ctx).Debugf("Query: %s", req.URL.RawQuery)
@@ -82,24 +127,13 @@ func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (d
 	filter := fb.And()
 	_ = req.ParseForm()
 	for _, field := range possibleFields {
-		values := as.getValues(req.Form, field)
-		if len(values) == 1 {
-			cond, err := as.getCondition(ctx, fb, field, values[0])
-			if err != nil {
-				return nil, err
-			}
-			filter.Condition(cond)
-		} else if len(values) > 0 {
-			sort.Strings(values)
-			fs := make([]database.Filter, len(values))
-			for i, value := range values {
-				cond, err := as.getCondition(ctx, fb, field, value)
-				if err != nil {
-					return nil, err
-				}
-				fs[i] = cond
-			}
-			filter.Condition(fb.Or(fs...))
+		if err := as.addFieldConditions(ctx, fb, filter, field, as.getValues(req.Form, field)); err != nil {
+			return nil, err
+		}
+	}
+	for _, jsonPathField := range as.getJSONPathFields(req.Form, fb.JSONFields()) {
+		if err := as.addFieldConditions(ctx, fb, filter, jsonPathField, req.Form[jsonPathField]); err != nil {
+			return nil, err
 		}
 	}
 	skipVals := as.getValues(req.Form, "skip")
@@ -135,6 +169,15 @@ func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (d
 	} else if len(ascendingVals) > 0 && (ascendingVals[0] == "" || strings.EqualFold(ascendingVals[0], "true")) {
 		filter.Ascending()
 	}
+	afterVals := as.getValues(req.Form, "after")
+	if len(afterVals) > 0 {
+		// An empty cursor requests the first page, with the cursors of the adjacent pages
+		filter.After(afterVals[0])
+	}
+	beforeVals := as.getValues(req.Form, "before")
+	if len(beforeVals) > 0 && beforeVals[0] != "" {
+		filter.Before(beforeVals[0])
+	}
 	countVals := as.getValues(req.Form, "count")
 	filter.Count(len(countVals) > 0 && (countVals[0] == "" || strings.EqualFold(countVals[0], "true")))
 	return filter, nil
@@ -147,3 +147,77 @@ func TestBuildFilterLimitLimit(t *testing.T) {
 	_, err := as.buildFilter(req, database.MessageQueryFactory)
 	assert.Regexp(t, "FF10184.*500", err)
 }
+
+func TestBuildFilterCursors(t *testing.T) {
+	as := &apiServer{
+		maxFilterLimit: 250,
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/things?created=0&limit=10&after=%s&before=%s&ascending", database.EncodeCursor(10), database.EncodeCursor(20)), nil)
+	filter, err := as.buildFilter(req, database.MessageQueryFactory)
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "( created == 0 ) sort=sequence limit=10 after=10 before=20", fi.String())
+}
+
+func TestBuildFilterCursorFirstPage(t *testing.T) {
+	as := &apiServer{}
+
+	req := httptest.NewRequest("GET", "/things?limit=10&after", nil)
+	filter, err := as.buildFilter(req, database.MessageQueryFactory)
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.True(t, fi.Cursors)
+	assert.Nil(t, fi.After)
+}
+
+func TestBuildFilterCursorInvalid(t *testing.T) {
+	as := &apiServer{}
+
+	req := httptest.NewRequest("GET", "/things?after=bad", nil)
+	filter, err := as.buildFilter(req, database.MessageQueryFactory)
+	assert.NoError(t, err)
+	_, err = filter.Finalize()
+	assert.Regexp(t, "FF10446", err)
+}
+
+func TestFilterResultCursors(t *testing.T) {
+	res, err := filterResult([]string{"a", "b"}, &database.FilterResult{Next: "next1", Prev: "prev1"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &filterResultsWithCount{
+		Count: 2,
+		Next:  "next1",
+		Prev:  "prev1",
+		Items: []string{"a", "b"},
+	}, res)
+
+	res, err = filterResult([]string{"a"}, &database.FilterResult{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, res)
+}
+
+func TestBuildFilterJSONPath(t *testing.T) {
+	as := &apiServer{
+		maxFilterLimit: 250,
+	}
+	req := httptest.NewRequest("GET", "/things?Value.orderId=123&value.amount=>=100&value.amount=<10&value=!abc&datatype.name=order", nil)
+	filter, err := as.buildFilter(req, database.DataQueryFactory)
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( datatype.name == 'order' ) && ( value != 'abc' ) && ( value.orderId == '123' ) && ( ( value.amount << '10' ) || ( value.amount >= '100' ) )", fi.String())
+}
+
+func TestBuildFilterJSONPathInvalid(t *testing.T) {
+	as := &apiServer{
+		maxFilterLimit: 250,
+	}
+	req := httptest.NewRequest("GET", "/things?value.order..id=123", nil)
+	filter, err := as.buildFilter(req, database.DataQueryFactory)
+	assert.NoError(t, err)
+	_, err = filter.Finalize()
+	assert.Regexp(t, "FF10449", err)
+}
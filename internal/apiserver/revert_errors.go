@@ -0,0 +1,75 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+type revertHolderKey struct{}
+
+// revertHolder captures the decoded revert of a failed smart contract invoke or query,
+// so that it can be added to the error response
+type revertHolder struct {
+	revert *core.ContractRevert
+}
+
+type revertRESTError struct {
+	Error  string               `json:"error"`
+	Revert *core.ContractRevert `json:"revert"`
+}
+
+// revertErrorWriter rewrites the error response written by the ffapi handler, to include the captured revert
+type revertErrorWriter struct {
+	http.ResponseWriter
+	holder *revertHolder
+}
+
+func (w *revertErrorWriter) Write(b []byte) (int, error) {
+	var restErr fftypes.RESTError
+	if w.holder.revert == nil || json.Unmarshal(b, &restErr) != nil {
+		return w.ResponseWriter.Write(b)
+	}
+	withRevert, _ := json.Marshal(&revertRESTError{
+		Error:  restErr.Error,
+		Revert: w.holder.revert,
+	})
+	if _, err := w.ResponseWriter.Write(withRevert); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func withRevertErrors(handler http.HandlerFunc) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		holder := &revertHolder{}
+		ctx := context.WithValue(req.Context(), revertHolderKey{}, holder)
+		handler(&revertErrorWriter{ResponseWriter: res, holder: holder}, req.WithContext(ctx))
+	}
+}
+
+func captureRevert(ctx context.Context, err error) {
+	if holder, ok := ctx.Value(revertHolderKey{}).(*revertHolder); ok {
+		holder.revert = blockchain.GetRevert(err)
+	}
+}
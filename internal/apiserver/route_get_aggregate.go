@@ -0,0 +1,95 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+var getAggregate = &ffapi.Route{
+	Name:   "getAggregate",
+	Path:   "aggregate/{collection}",
+	Method: http.MethodGet,
+	PathParams: []*ffapi.PathParam{
+		{Name: "collection", Description: coremsgs.APIParamsCollectionID},
+	},
+	QueryParams: []*ffapi.QueryParam{
+		{Name: "groupBy", Description: coremsgs.APIAggregateGroupByParam},
+		{Name: "metric", Description: coremsgs.APIAggregateMetricParam},
+	},
+	Description:     coremsgs.APIEndpointsGetAggregate,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []*core.AggregateResult{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		FilterFactoryForPath: func(r *ffapi.APIRequest) (database.QueryFactory, error) {
+			collection := database.CollectionName(r.PP["collection"])
+			qf, ok := database.AggregateQueryFactories[collection]
+			if !ok {
+				return nil, i18n.NewError(r.Req.Context(), coremsgs.MsgUnsupportedCollection, collection)
+			}
+			return qf, nil
+		},
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			query, err := parseAggregateQuery(cr.ctx, r.Req.URL.Query()["groupBy"], r.Req.URL.Query()["metric"])
+			if err != nil {
+				return nil, err
+			}
+			return cr.or.GetAggregate(cr.ctx, extractNamespace(r.PP), database.CollectionName(r.PP["collection"]), query, cr.filter)
+		},
+	},
+}
+
+// parseAggregateQuery parses the groupBy fields, which can be comma separated, and metrics in the form "function:field"
+func parseAggregateQuery(ctx context.Context, groupBy, metrics []string) (*core.AggregateQuery, error) {
+	query := &core.AggregateQuery{
+		GroupBy: []string{},
+		Metrics: []*core.AggregateMetric{},
+	}
+	for _, gb := range groupBy {
+		for _, field := range strings.Split(gb, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				query.GroupBy = append(query.GroupBy, field)
+			}
+		}
+	}
+	for _, metric := range metrics {
+		parts := strings.SplitN(metric, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidAggregateMetric, metric)
+		}
+		function := core.AggregateFunction(strings.ToLower(parts[0]))
+		switch function {
+		case core.AggregateFunctionSum, core.AggregateFunctionMin, core.AggregateFunctionMax:
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidAggregateMetric, metric)
+		}
+		query.Metrics = append(query.Metrics, &core.AggregateMetric{
+			Function: function,
+			Field:    parts[1],
+		})
+	}
+	return query, nil
+}
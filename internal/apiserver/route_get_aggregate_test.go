@@ -0,0 +1,91 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAggregate(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/aggregate/tokentransfers?groupBy=pool,type&metric=sum:amount&metric=MAX:amount&type=transfer", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	count := "10"
+	o.On("GetAggregate", mock.Anything, "mynamespace", database.CollectionName("tokentransfers"), &core.AggregateQuery{
+		GroupBy: []string{"pool", "type"},
+		Metrics: []*core.AggregateMetric{
+			{Function: core.AggregateFunctionSum, Field: "amount"},
+			{Function: core.AggregateFunctionMax, Field: "amount"},
+		},
+	}, mock.MatchedBy(func(filter database.AndFilter) bool {
+		fi, _ := filter.Finalize()
+		return strings.HasPrefix(fi.String(), "( type == 'transfer' )")
+	})).Return([]*core.AggregateResult{{Count: count}}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var results []*core.AggregateResult
+	err := json.NewDecoder(res.Body).Decode(&results)
+	assert.NoError(t, err)
+	assert.Equal(t, count, results[0].Count)
+}
+
+func TestGetAggregateUnsupportedCollection(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/aggregate/wrong", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
+
+func TestGetAggregateBadMetric(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/aggregate/messages?metric=sum", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
+
+func TestParseAggregateQuery(t *testing.T) {
+	query, err := parseAggregateQuery(context.Background(), []string{"tag, type", "", "topics"}, []string{"min:sequence"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tag", "type", "topics"}, query.GroupBy)
+	assert.Equal(t, core.AggregateFunctionMin, query.Metrics[0].Function)
+	assert.Equal(t, "sequence", query.Metrics[0].Field)
+
+	_, err = parseAggregateQuery(context.Background(), nil, []string{"avg:sequence"})
+	assert.Regexp(t, "FF10451.*avg:sequence", err)
+	_, err = parseAggregateQuery(context.Background(), nil, []string{"sum:"})
+	assert.Regexp(t, "FF10451", err)
+}
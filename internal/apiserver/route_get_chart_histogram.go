@@ -39,6 +39,7 @@ var getChartHistogram = &ffapi.Route{
 		{Name: "startTime", Description: coremsgs.APIHistogramStartTimeParam, IsBool: false},
 		{Name: "endTime", Description: coremsgs.APIHistogramEndTimeParam, IsBool: false},
 		{Name: "buckets", Description: coremsgs.APIHistogramBucketsParam, IsBool: false},
+		{Name: "groupBy", Description: coremsgs.APIHistogramGroupByParam, IsBool: false},
 	},
 	Description:     coremsgs.APIEndpointsGetChartHistogram,
 	JSONInputValue:  nil,
@@ -58,7 +59,7 @@ var getChartHistogram = &ffapi.Route{
 			if err != nil {
 				return nil, i18n.NewError(cr.ctx, coremsgs.MsgInvalidChartNumberParam, "buckets")
 			}
-			return cr.or.GetChartHistogram(cr.ctx, extractNamespace(r.PP), startTime.UnixNano(), endTime.UnixNano(), buckets, database.CollectionName(r.PP["collection"]))
+			return cr.or.GetChartHistogram(cr.ctx, extractNamespace(r.PP), startTime.UnixNano(), endTime.UnixNano(), buckets, database.CollectionName(r.PP["collection"]), r.QP["groupBy"])
 		},
 	},
 }
@@ -59,5 +59,5 @@ func TestGetEventsWithReferences(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resWithCount.Items)
 	assert.Equal(t, int64(0), resWithCount.Count)
-	assert.Equal(t, int64(10), resWithCount.Total)
+	assert.Equal(t, int64(10), *resWithCount.Total)
 }
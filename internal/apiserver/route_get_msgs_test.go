@@ -59,7 +59,7 @@ func TestGetMessagesWithCount(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resWithCount.Items)
 	assert.Equal(t, int64(0), resWithCount.Count)
-	assert.Equal(t, int64(10), resWithCount.Total)
+	assert.Equal(t, int64(10), *resWithCount.Total)
 }
 
 func TestGetMessagesWithCountAndData(t *testing.T) {
@@ -81,5 +81,5 @@ func TestGetMessagesWithCountAndData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resWithCount.Items)
 	assert.Equal(t, int64(0), resWithCount.Count)
-	assert.Equal(t, int64(10), resWithCount.Total)
+	assert.Equal(t, int64(10), *resWithCount.Total)
 }
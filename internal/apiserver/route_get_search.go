@@ -0,0 +1,110 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	defaultSearchLimit = 25
+	maxSearchLimit     = 100
+)
+
+var searchHitTypes = []core.SearchHitType{
+	core.SearchHitTypeMessage,
+	core.SearchHitTypeData,
+	core.SearchHitTypeIdentity,
+	core.SearchHitTypeTokenPool,
+	core.SearchHitTypeFFI,
+}
+
+var getSearch = &ffapi.Route{
+	Name:       "getSearch",
+	Path:       "search",
+	Method:     http.MethodGet,
+	PathParams: nil,
+	QueryParams: []*ffapi.QueryParam{
+		{Name: "q", Description: coremsgs.APISearchTextParam},
+		{Name: "type", Description: coremsgs.APISearchTypeParam},
+		{Name: "limit", Description: coremsgs.APISearchLimitParam, Default: strconv.Itoa(defaultSearchLimit)},
+	},
+	Description:     coremsgs.APIEndpointsGetSearch,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []*core.SearchHit{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			types, err := parseSearchTypes(cr.ctx, r.Req.URL.Query()["type"])
+			if err != nil {
+				return nil, err
+			}
+			limit, err := parseSearchLimit(cr.ctx, r.QP["limit"])
+			if err != nil {
+				return nil, err
+			}
+			return cr.or.Search(cr.ctx, extractNamespace(r.PP), r.QP["q"], types, limit)
+		},
+	},
+}
+
+// parseSearchTypes parses the types to search, which can be comma separated
+func parseSearchTypes(ctx context.Context, values []string) ([]core.SearchHitType, error) {
+	var types []core.SearchHitType
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t == "" {
+				continue
+			}
+			hitType, ok := lookupSearchHitType(t)
+			if !ok {
+				return nil, i18n.NewError(ctx, coremsgs.MsgInvalidSearchType, t, fftypes.FFEnumValues("searchhittype"))
+			}
+			types = append(types, hitType)
+		}
+	}
+	return types, nil
+}
+
+func lookupSearchHitType(value string) (core.SearchHitType, bool) {
+	for _, hitType := range searchHitTypes {
+		if hitType.Equals(core.SearchHitType(value)) {
+			return hitType, true
+		}
+	}
+	return "", false
+}
+
+func parseSearchLimit(ctx context.Context, value string) (int, error) {
+	if value == "" {
+		return defaultSearchLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		return 0, i18n.NewError(ctx, coremsgs.MsgInvalidSearchLimit, value, maxSearchLimit)
+	}
+	return limit, nil
+}
@@ -34,11 +34,11 @@ var getSubscriptionByID = &ffapi.Route{
 	QueryParams:     nil,
 	Description:     coremsgs.APIEndpointsGetSubscriptionByID,
 	JSONInputValue:  nil,
-	JSONOutputValue: func() interface{} { return &core.Subscription{} },
+	JSONOutputValue: func() interface{} { return &core.SubscriptionWithStatus{} },
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
-			output, err = cr.or.GetSubscriptionByID(cr.ctx, extractNamespace(r.PP), r.PP["subid"])
+			output, err = cr.or.GetSubscriptionByIDWithStatus(cr.ctx, extractNamespace(r.PP), r.PP["subid"])
 			return output, err
 		},
 	},
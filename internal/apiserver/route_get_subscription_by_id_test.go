@@ -31,8 +31,8 @@ func TestGetSubscriptionByID(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	res := httptest.NewRecorder()
 
-	o.On("GetSubscriptionByID", mock.Anything, "mynamespace", "abcd12345").
-		Return(&core.Subscription{}, nil)
+	o.On("GetSubscriptionByIDWithStatus", mock.Anything, "mynamespace", "abcd12345").
+		Return(&core.SubscriptionWithStatus{}, nil)
 	r.ServeHTTP(res, req)
 
 	assert.Equal(t, 200, res.Result().StatusCode)
@@ -19,10 +19,13 @@ package apiserver
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/mocks/contractmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,3 +49,29 @@ func TestPostContractQuery(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestPostContractQueryReverted(t *testing.T) {
+	o, r := newTestAPIServer()
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	input := core.Datatype{}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/contracts/query", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	revert := &core.ContractRevert{
+		Error:  "InsufficientBalance",
+		Params: fftypes.JSONObject{"available": "1"},
+	}
+	mcm.On("InvokeContract", mock.Anything, "ns1", mock.Anything, true).
+		Return(nil, &blockchain.RevertError{Err: fmt.Errorf("pop"), Revert: revert})
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+	var resBody revertRESTError
+	json.NewDecoder(res.Body).Decode(&resBody)
+	assert.Equal(t, "pop", resBody.Error)
+	assert.Equal(t, revert, resBody.Revert)
+}
@@ -0,0 +1,62 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/backupmocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIGetNamespaceExport(t *testing.T) {
+	o, r := newTestSPIServer()
+	mbk := &backupmocks.Manager{}
+	o.On("Backup").Return(mbk)
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+
+	mbk.On("Export", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args[1].(io.Writer).Write([]byte(`{"collection":"namespace","record":{"name":"ns1"}}` + "\n"))
+		}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/octet-stream", res.Result().Header.Get("Content-Type"))
+	assert.Equal(t, `{"collection":"namespace","record":{"name":"ns1"}}`+"\n", res.Body.String())
+}
+
+func TestSPIGetNamespaceExportFail(t *testing.T) {
+	o, r := newTestSPIServer()
+	mbk := &backupmocks.Manager{}
+	o.On("Backup").Return(mbk)
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+
+	mbk.On("Export", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	// The status has already been sent when the export fails, so the error ends the output
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Regexp(t, "FF00165.*pop", res.Body.String())
+}
@@ -0,0 +1,78 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/backupmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testImport = `{"collection":"namespace","record":{"name":"ns1"}}
+{"collection":"datatypes","record":{"namespace":"ns1"}}
+`
+
+func TestSPIPostNamespaceImport(t *testing.T) {
+	o, r := newTestSPIServer()
+	mbk := &backupmocks.Manager{}
+	o.On("Backup").Return(mbk)
+	req := httptest.NewRequest("POST", "/spi/v1/namespaces/ns1/import", strings.NewReader(testImport))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mbk.On("Import", mock.Anything, mock.MatchedBy(func(r io.Reader) bool {
+		b, _ := ioutil.ReadAll(r)
+		return string(b) == testImport
+	})).Return(&core.NamespaceImportResult{Namespace: "ns1", Records: map[string]int64{"datatypes": 1}}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.JSONEq(t, `{"namespace":"ns1","records":{"datatypes":1}}`, res.Body.String())
+}
+
+func TestSPIPostNamespaceImportMultipart(t *testing.T) {
+	o, r := newTestSPIServer()
+	mbk := &backupmocks.Manager{}
+	o.On("Backup").Return(mbk)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	writer, err := w.CreateFormFile("file", "ns1.ndjson")
+	assert.NoError(t, err)
+	writer.Write([]byte(testImport))
+	w.Close()
+	req := httptest.NewRequest("POST", "/spi/v1/namespaces/ns1/import", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res := httptest.NewRecorder()
+
+	mbk.On("Import", mock.Anything, mock.MatchedBy(func(r io.Reader) bool {
+		b, _ := ioutil.ReadAll(r)
+		return string(b) == testImport
+	})).Return(&core.NamespaceImportResult{Namespace: "ns1"}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
@@ -38,6 +38,7 @@ type coreRequest struct {
 
 type coreExtensions struct {
 	FilterFactory         database.QueryFactory
+	FilterFactoryForPath  func(r *ffapi.APIRequest) (database.QueryFactory, error)
 	CoreJSONHandler       func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)
 	CoreFormUploadHandler func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)
 }
@@ -57,6 +58,7 @@ var routes = append(
 	namespacedRoutes([]*ffapi.Route{
 		deleteContractListener,
 		deleteSubscription,
+		getAggregate,
 		getBatchByID,
 		getBatches,
 		getBlockchainEventByID,
@@ -77,6 +79,8 @@ var routes = append(
 		getDataMsgs,
 		getDatatypeByName,
 		getDatatypes,
+		getDeadLetterByID,
+		getDeadLetters,
 		getEventByID,
 		getEvents,
 		getGroupByHash,
@@ -101,6 +105,7 @@ var routes = append(
 		getOpByID,
 		getOps,
 		getPins,
+		getSearch,
 		getStatus,
 		getStatusBatchManager,
 		getSubscriptionByID,
@@ -125,6 +130,7 @@ var routes = append(
 		postContractAPIInvoke,
 		postContractAPIQuery,
 		postContractAPIListeners,
+		postContractDeploy,
 		postContractInterfaceGenerate,
 		postContractInvoke,
 		postContractQuery,
@@ -143,6 +149,7 @@ var routes = append(
 		postNewOrganizationSelf,
 		postNodesSelf,
 		postOpRetry,
+		postSubscriptionReset,
 		postTokenApproval,
 		postTokenBurn,
 		postTokenMint,
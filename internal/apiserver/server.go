@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/ghodss/yaml"
@@ -33,13 +34,16 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/httpserver"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/auth/authfactory"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
+	"github.com/hyperledger/firefly/internal/events/sse"
 	"github.com/hyperledger/firefly/internal/events/websockets"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/namespace"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/auth"
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
@@ -48,8 +52,12 @@ var (
 	apiConfig     = config.RootSection("http")
 	metricsConfig = config.RootSection("metrics")
 	corsConfig    = config.RootSection("cors")
+	apiAuthConfig = apiConfig.SubSection("auth")
 )
 
+// sseRouteName is the name of the route for the server-sent events stream
+const sseRouteName = "sse"
+
 // Server is the external interface for the API Server
 type Server interface {
 	Serve(ctx context.Context, mgr namespace.Manager) error
@@ -64,6 +72,7 @@ type apiServer struct {
 	apiMaxTimeout      time.Duration
 	metricsEnabled     bool
 	ffiSwaggerGen      FFISwaggerGen
+	auth               auth.Plugin
 }
 
 func InitConfig() {
@@ -72,6 +81,7 @@ func InitConfig() {
 	httpserver.InitHTTPConfig(metricsConfig, 6000)
 	httpserver.InitCORSConfig(corsConfig)
 	initMetricsConfig(metricsConfig)
+	authfactory.InitConfig(apiAuthConfig)
 }
 
 func NewAPIServer() Server {
@@ -92,6 +102,10 @@ func (as *apiServer) Serve(ctx context.Context, mgr namespace.Manager) (err erro
 	spiErrChan := make(chan error)
 	metricsErrChan := make(chan error)
 
+	if err := as.initAuth(ctx); err != nil {
+		return err
+	}
+
 	apiHTTPServer, err := httpserver.NewHTTPServer(ctx, "api", as.createMuxRouter(ctx, mgr), httpErrChan, apiConfig, corsConfig)
 	if err != nil {
 		return err
@@ -117,6 +131,17 @@ func (as *apiServer) Serve(ctx context.Context, mgr namespace.Manager) (err erro
 	return as.waitForServerStop(httpErrChan, spiErrChan, metricsErrChan)
 }
 
+func (as *apiServer) initAuth(ctx context.Context) (err error) {
+	authType := apiAuthConfig.GetString(coreconfig.PluginConfigType)
+	if authType == "" {
+		return nil
+	}
+	if as.auth, err = authfactory.GetPlugin(ctx, authType); err != nil {
+		return err
+	}
+	return as.auth.Init(ctx, apiAuthConfig.SubSection(authType))
+}
+
 func (as *apiServer) waitForServerStop(httpErrChan, spiErrChan, metricsErrChan chan error) error {
 	select {
 	case err := <-httpErrChan:
@@ -128,7 +153,12 @@ func (as *apiServer) waitForServerStop(httpErrChan, spiErrChan, metricsErrChan c
 	}
 }
 
-func (as *apiServer) getPublicURL(conf config.Section, pathPrefix string) string {
+// SPIURL returns the base URL of the versioned SPI routes, as configured for this node
+func SPIURL() string {
+	return fmt.Sprintf("%s/v1", getPublicURL(spiConfig, "spi"))
+}
+
+func getPublicURL(conf config.Section, pathPrefix string) string {
 	publicURL := conf.GetString(httpserver.HTTPConfPublicURL)
 	if publicURL == "" {
 		proto := "https"
@@ -143,7 +173,7 @@ func (as *apiServer) getPublicURL(conf config.Section, pathPrefix string) string
 	return publicURL
 }
 
-func (as *apiServer) swaggerGenConf(apiBaseURL string) *ffapi.Options {
+func (as *apiServer) swaggerGenConf(apiBaseURL string, authPlugin auth.Plugin) *ffapi.Options {
 	return &ffapi.Options{
 		BaseURL:                   apiBaseURL,
 		Title:                     "FireFly",
@@ -151,6 +181,9 @@ func (as *apiServer) swaggerGenConf(apiBaseURL string) *ffapi.Options {
 		PanicOnMissingDescription: config.GetBool(coreconfig.APIOASPanicOnMissingDescription),
 		DefaultRequestTimeout:     config.GetDuration(coreconfig.APIRequestTimeout),
 		RouteCustomizations: func(ctx context.Context, sg *ffapi.SwaggerGen, route *ffapi.Route, op *openapi3.Operation) {
+			if authPlugin != nil {
+				op.Security = openapi3.NewSecurityRequirements().With(openapi3.SecurityRequirement{authPlugin.Name(): []string{}})
+			}
 			if ce, ok := route.Extensions.(*coreExtensions); ok {
 				if ce.FilterFactory != nil {
 					fields := ce.FilterFactory.NewFilter(ctx).Fields()
@@ -164,6 +197,8 @@ func (as *apiServer) swaggerGenConf(apiBaseURL string) *ffapi.Options {
 					sg.AddParam(ctx, op, "query", "skip", "", "", coremsgs.APIFilterSkipDesc, false, config.GetUint(coreconfig.APIMaxFilterSkip))
 					sg.AddParam(ctx, op, "query", "limit", "", config.GetString(coreconfig.APIDefaultFilterLimit), coremsgs.APIFilterLimitDesc, false, config.GetUint(coreconfig.APIMaxFilterLimit))
 					sg.AddParam(ctx, op, "query", "count", "", "", coremsgs.APIFilterCountDesc, false)
+					sg.AddParam(ctx, op, "query", "after", "", "", coremsgs.APIFilterAfterDesc, false)
+					sg.AddParam(ctx, op, "query", "before", "", "", coremsgs.APIFilterBeforeDesc, false)
 				}
 			}
 		},
@@ -190,10 +225,16 @@ func (as *apiServer) swaggerHandler(generator func(req *http.Request) (*openapi3
 	}
 }
 
-func (as *apiServer) swaggerGenerator(routes []*ffapi.Route, apiBaseURL string) func(req *http.Request) (*openapi3.T, error) {
-	swg := ffapi.NewSwaggerGen(as.swaggerGenConf(apiBaseURL))
+func (as *apiServer) swaggerGenerator(routes []*ffapi.Route, apiBaseURL string, authPlugin auth.Plugin) func(req *http.Request) (*openapi3.T, error) {
+	swg := ffapi.NewSwaggerGen(as.swaggerGenConf(apiBaseURL, authPlugin))
 	return func(req *http.Request) (*openapi3.T, error) {
-		return swg.Generate(req.Context(), routes), nil
+		doc := swg.Generate(req.Context(), routes)
+		if authPlugin != nil {
+			doc.Components.SecuritySchemes = openapi3.SecuritySchemes{
+				authPlugin.Name(): &openapi3.SecuritySchemeRef{Value: authPlugin.SecurityScheme()},
+			}
+		}
+		return doc, nil
 	}
 }
 
@@ -218,14 +259,122 @@ func (as *apiServer) contractSwaggerGenerator(mgr namespace.Manager, apiBaseURL
 	}
 }
 
-func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manager, apiBaseURL string, route *ffapi.Route) http.HandlerFunc {
+// authorize checks the request against the auth plugin (if one is configured), using the namespace
+// the route operates on, and whether the route is read-only
+func (as *apiServer) authorize(r *ffapi.APIRequest, authPlugin auth.Plugin, route *ffapi.Route) error {
+	if authPlugin == nil {
+		return nil
+	}
+	vars := mux.Vars(r.Req)
+	ns := vars["ns"]
+	if route.Tag == routeTagDefaultNamespace || route.Tag == routeTagNonDefaultNamespace {
+		ns = extractNamespace(vars)
+	}
+	_, err := as.authorizeRequest(r.Req, r.ResponseHeaders, authPlugin, route.Name, ns, route.Method == http.MethodGet)
+	return err
+}
+
+// authorizeRequest authenticates the request, and checks the principal has access to the namespace. When authentication
+// fails, a WWW-Authenticate challenge for the scheme of the auth plugin is set on the response headers to go with the 401
+func (as *apiServer) authorizeRequest(req *http.Request, resHeaders http.Header, authPlugin auth.Plugin, routeName, ns string, readOnly bool) (*auth.Principal, error) {
+	ctx := req.Context()
+	principal, err := authPlugin.Authenticate(ctx, &auth.Request{
+		Method:    req.Method,
+		URL:       req.URL,
+		Header:    req.Header,
+		RouteName: routeName,
+		Namespace: ns,
+		ReadOnly:  readOnly,
+	})
+	if err != nil {
+		if scheme := authPlugin.SecurityScheme().Scheme; scheme != "" {
+			resHeaders.Set("WWW-Authenticate", strings.ToUpper(scheme[:1])+scheme[1:])
+		}
+		return nil, err
+	}
+	if !principal.Authorized(ns, readOnly) {
+		return nil, i18n.NewError(ctx, coremsgs.MsgAuthForbidden, principal.Subject, ns)
+	}
+	return principal, nil
+}
+
+// authorizeHandler applies the same authorization as the API routes to a handler that is registered directly
+// on the router, such as the event streams and the Swagger UIs. The principal is passed on in the request context.
+func (as *apiServer) authorizeHandler(hf *ffapi.HandlerFactory, authPlugin auth.Plugin, routeName string, getNamespace func(req *http.Request) string, handler http.HandlerFunc) http.HandlerFunc {
+	return as.authorizeHandlerAccess(hf, authPlugin, routeName, getNamespace, readOnlyIfGet, handler)
+}
+
+// authorizeHandlerAccess is authorizeHandler for a handler where a GET request might not be read-only
+func (as *apiServer) authorizeHandlerAccess(hf *ffapi.HandlerFactory, authPlugin auth.Plugin, routeName string, getNamespace func(req *http.Request) string, isReadOnly func(req *http.Request) bool, handler http.HandlerFunc) http.HandlerFunc {
+	if authPlugin == nil {
+		return handler
+	}
+	return func(res http.ResponseWriter, req *http.Request) {
+		principal, err := as.authorizeRequest(req, res.Header(), authPlugin, routeName, getNamespace(req), isReadOnly(req))
+		if err != nil {
+			hf.APIWrapper(func(res http.ResponseWriter, req *http.Request) (int, error) {
+				return http.StatusUnauthorized, err
+			})(res, req)
+			return
+		}
+		handler(res, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+	}
+}
+
+// skipRouteMiddleware applies a middleware to every route on the router other than the one with the given name
+func skipRouteMiddleware(routeName string, middleware mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		wrapped := middleware(next)
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if route := mux.CurrentRoute(req); route != nil && route.GetName() == routeName {
+				next.ServeHTTP(res, req)
+				return
+			}
+			wrapped.ServeHTTP(res, req)
+		})
+	}
+}
+
+func readOnlyIfGet(req *http.Request) bool {
+	return req.Method == http.MethodGet
+}
+
+// sseReadOnly is whether a request to the SSE stream is read-only. Resuming a durable subscription
+// from a Last-Event-ID rewinds its offset, so needs the same access as updating the subscription.
+func sseReadOnly(req *http.Request) bool {
+	return readOnlyIfGet(req) && req.Header.Get("Last-Event-ID") == ""
+}
+
+func globalNamespace(req *http.Request) string {
+	return ""
+}
+
+func namespaceFromPath(req *http.Request) string {
+	return mux.Vars(req)["ns"]
+}
+
+func namespaceFromQuery(req *http.Request) string {
+	return req.URL.Query().Get("namespace")
+}
+
+func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manager, apiBaseURL string, authPlugin auth.Plugin, route *ffapi.Route) http.HandlerFunc {
 	// We extend the base ffapi functionality, with standardized DB filter support for all core resources.
 	// We also pass the Orchestrator context through
 	ce := route.Extensions.(*coreExtensions)
 	route.JSONHandler = func(r *ffapi.APIRequest) (output interface{}, err error) {
+		if err = as.authorize(r, authPlugin, route); err != nil {
+			return nil, err
+		}
+
+		filterFactory := ce.FilterFactory
+		if ce.FilterFactoryForPath != nil {
+			if filterFactory, err = ce.FilterFactoryForPath(r); err != nil {
+				return nil, err
+			}
+		}
 		var filter database.AndFilter
-		if ce.FilterFactory != nil {
-			filter, err = as.buildFilter(r.Req, ce.FilterFactory)
+		if filterFactory != nil {
+			filter, err = as.buildFilter(r.Req, filterFactory)
 			if err != nil {
 				return nil, err
 			}
@@ -244,10 +393,18 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			filter:     filter,
 			apiBaseURL: apiBaseURL,
 		}
-		return ce.CoreJSONHandler(r, cr)
+		output, err = ce.CoreJSONHandler(r, cr)
+		if err != nil {
+			captureRevert(cr.ctx, err)
+		}
+		return output, err
 	}
 	if ce.CoreFormUploadHandler != nil {
 		route.FormUploadHandler = func(r *ffapi.APIRequest) (output interface{}, err error) {
+			if err = as.authorize(r, authPlugin, route); err != nil {
+				return nil, err
+			}
+
 			var or orchestrator.Orchestrator
 			if route.Tag == routeTagDefaultNamespace || route.Tag == routeTagNonDefaultNamespace {
 				vars := mux.Vars(r.Req)
@@ -263,7 +420,7 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			return ce.CoreFormUploadHandler(r, cr)
 		}
 	}
-	return hf.RouteHandler(route)
+	return withRevertErrors(hf.RouteHandler(route))
 }
 
 func (as *apiServer) handlerFactory() *ffapi.HandlerFactory {
@@ -278,37 +435,46 @@ func (as *apiServer) createMuxRouter(ctx context.Context, mgr namespace.Manager)
 	hf := as.handlerFactory()
 
 	if as.metricsEnabled {
-		r.Use(metrics.GetRestServerInstrumentation().Middleware)
+		// The event stream is not instrumented, as the metrics middleware hides the flushing and deadline
+		// controls of the response writer, and the duration of a long lived stream is not a useful request metric
+		r.Use(skipRouteMiddleware(sseRouteName, metrics.GetRestServerInstrumentation().Middleware))
 	}
 
-	publicURL := as.getPublicURL(apiConfig, "")
+	publicURL := getPublicURL(apiConfig, "")
 	apiBaseURL := fmt.Sprintf("%s/api/v1", publicURL)
 	for _, route := range routes {
 		if ce, ok := route.Extensions.(*coreExtensions); ok {
 			if ce.CoreJSONHandler != nil {
-				r.HandleFunc(fmt.Sprintf("/api/v1/%s", route.Path), as.routeHandler(hf, mgr, apiBaseURL, route)).
+				r.HandleFunc(fmt.Sprintf("/api/v1/%s", route.Path), as.routeHandler(hf, mgr, apiBaseURL, as.auth, route)).
 					Methods(route.Method)
 			}
 		}
 	}
 
-	r.HandleFunc(`/api/v1/namespaces/{ns}/apis/{apiName}/api/swagger{ext:\.yaml|\.json|}`, hf.APIWrapper(as.swaggerHandler(as.contractSwaggerGenerator(mgr, apiBaseURL))))
-	r.HandleFunc(`/api/v1/namespaces/{ns}/apis/{apiName}/api`, func(rw http.ResponseWriter, req *http.Request) {
-		url := req.URL.String() + "/swagger.yaml"
-		handler := hf.APIWrapper(hf.SwaggerUIHandler(url))
-		handler(rw, req)
-	})
-
-	r.HandleFunc(`/api/swagger{ext:\.yaml|\.json|}`, hf.APIWrapper(as.swaggerHandler(as.swaggerGenerator(routes, apiBaseURL))))
-	r.HandleFunc(`/api`, hf.APIWrapper(hf.SwaggerUIHandler(publicURL+"/api/swagger.yaml")))
-	r.HandleFunc(`/favicon{any:.*}.png`, favIcons)
+	r.HandleFunc(`/api/v1/namespaces/{ns}/apis/{apiName}/api/swagger{ext:\.yaml|\.json|}`, as.authorizeHandler(hf, as.auth, "contractAPISwagger", namespaceFromPath,
+		hf.APIWrapper(as.swaggerHandler(as.contractSwaggerGenerator(mgr, apiBaseURL)))))
+	r.HandleFunc(`/api/v1/namespaces/{ns}/apis/{apiName}/api`, as.authorizeHandler(hf, as.auth, "contractAPISwaggerUI", namespaceFromPath,
+		func(rw http.ResponseWriter, req *http.Request) {
+			url := req.URL.String() + "/swagger.yaml"
+			handler := hf.APIWrapper(hf.SwaggerUIHandler(url))
+			handler(rw, req)
+		}))
+
+	r.HandleFunc(`/api/swagger{ext:\.yaml|\.json|}`, as.authorizeHandler(hf, as.auth, "swagger", globalNamespace,
+		hf.APIWrapper(as.swaggerHandler(as.swaggerGenerator(routes, apiBaseURL, as.auth)))))
+	r.HandleFunc(`/api`, as.authorizeHandler(hf, as.auth, "swaggerUI", globalNamespace,
+		hf.APIWrapper(hf.SwaggerUIHandler(publicURL+"/api/swagger.yaml"))))
+	r.HandleFunc(`/favicon{any:.*}.png`, as.authorizeHandler(hf, as.auth, "favicon", globalNamespace, favIcons))
 
 	ws, _ := eifactory.GetPlugin(ctx, "websockets")
-	r.HandleFunc(`/ws`, ws.(*websockets.WebSockets).ServeHTTP)
+	r.HandleFunc(`/ws`, as.authorizeHandler(hf, as.auth, "websocket", namespaceFromQuery, ws.(*websockets.WebSockets).ServeHTTP))
+	ssePlugin, _ := eifactory.GetPlugin(ctx, "sse")
+	r.HandleFunc(`/sse`, as.authorizeHandlerAccess(hf, as.auth, sseRouteName, namespaceFromQuery, sseReadOnly, ssePlugin.(*sse.SSE).ServeHTTP)).
+		Name(sseRouteName)
 
 	uiPath := config.GetString(coreconfig.UIPath)
 	if uiPath != "" && config.GetBool(coreconfig.UIEnabled) {
-		r.PathPrefix(`/ui`).Handler(newStaticHandler(uiPath, "index.html", `/ui`))
+		r.PathPrefix(`/ui`).Handler(as.authorizeHandler(hf, as.auth, "ui", globalNamespace, newStaticHandler(uiPath, "index.html", `/ui`).ServeHTTP))
 	}
 
 	r.NotFoundHandler = hf.APIWrapper(as.notFoundHandler)
@@ -334,21 +500,23 @@ func (as *apiServer) createAdminMuxRouter(mgr namespace.Manager) *mux.Router {
 	}
 	hf := as.handlerFactory()
 
-	publicURL := as.getPublicURL(spiConfig, "spi")
+	publicURL := getPublicURL(spiConfig, "spi")
 	apiBaseURL := fmt.Sprintf("%s/v1", publicURL)
 	for _, route := range spiRoutes {
 		if ce, ok := route.Extensions.(*coreExtensions); ok {
 			if ce.CoreJSONHandler != nil {
-				r.HandleFunc(fmt.Sprintf("/spi/v1/%s", route.Path), as.routeHandler(hf, mgr, apiBaseURL, route)).
+				r.HandleFunc(fmt.Sprintf("/spi/v1/%s", route.Path), as.routeHandler(hf, mgr, apiBaseURL, as.auth, route)).
 					Methods(route.Method)
 			}
 		}
 	}
-	r.HandleFunc(`/spi/swagger{ext:\.yaml|\.json|}`, hf.APIWrapper(as.swaggerHandler(as.swaggerGenerator(spiRoutes, apiBaseURL))))
-	r.HandleFunc(`/spi`, hf.APIWrapper(hf.SwaggerUIHandler(publicURL+"/swagger.yaml")))
-	r.HandleFunc(`/favicon{any:.*}.png`, favIcons)
+	r.HandleFunc(`/spi/swagger{ext:\.yaml|\.json|}`, as.authorizeHandler(hf, as.auth, "spiSwagger", globalNamespace,
+		hf.APIWrapper(as.swaggerHandler(as.swaggerGenerator(spiRoutes, apiBaseURL, as.auth)))))
+	r.HandleFunc(`/spi`, as.authorizeHandler(hf, as.auth, "spiSwaggerUI", globalNamespace,
+		hf.APIWrapper(hf.SwaggerUIHandler(publicURL+"/swagger.yaml"))))
+	r.HandleFunc(`/favicon{any:.*}.png`, as.authorizeHandler(hf, as.auth, "favicon", globalNamespace, favIcons))
 
-	r.HandleFunc(`/spi/ws`, as.spiWSHandler(mgr))
+	r.HandleFunc(`/spi/ws`, as.authorizeHandler(hf, as.auth, "spiWebsocket", globalNamespace, as.spiWSHandler(mgr)))
 
 	return r
 }
@@ -17,6 +17,7 @@
 package apiserver
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -31,13 +32,17 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/httpserver"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/mocks/apiservermocks"
+	"github.com/hyperledger/firefly/mocks/authmocks"
 	"github.com/hyperledger/firefly/mocks/contractmocks"
 	"github.com/hyperledger/firefly/mocks/namespacemocks"
 	"github.com/hyperledger/firefly/mocks/orchestratormocks"
 	"github.com/hyperledger/firefly/mocks/spieventsmocks"
+	"github.com/hyperledger/firefly/pkg/auth"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -74,6 +79,14 @@ func newTestSPIServer() (*orchestratormocks.Orchestrator, *mux.Router) {
 	return o, r
 }
 
+func TestSPIURL(t *testing.T) {
+	coreconfig.Reset()
+	InitConfig()
+	spiConfig.Set(httpserver.HTTPConfAddress, "127.0.0.1")
+	spiConfig.Set(httpserver.HTTPConfPort, 5101)
+	assert.Equal(t, "http://127.0.0.1:5101/spi/v1", SPIURL())
+}
+
 func TestStartStopServer(t *testing.T) {
 	coreconfig.Reset()
 	metrics.Clear()
@@ -172,7 +185,7 @@ func TestNotFound(t *testing.T) {
 
 func TestFilterTooMany(t *testing.T) {
 	mgr, _, as := newTestServer()
-	handler := as.routeHandler(as.handlerFactory(), mgr, "", getBatches)
+	handler := as.routeHandler(as.handlerFactory(), mgr, "", nil, getBatches)
 
 	req := httptest.NewRequest("GET", "http://localhost:12345/test?limit=99999999999", nil)
 	res := httptest.NewRecorder()
@@ -185,7 +198,7 @@ func TestFilterTooMany(t *testing.T) {
 
 func TestSwaggerYAML(t *testing.T) {
 	_, _, as := newTestServer()
-	handler := as.handlerFactory().APIWrapper(as.swaggerHandler(as.swaggerGenerator(routes, "http://localhost:12345/api/v1")))
+	handler := as.handlerFactory().APIWrapper(as.swaggerHandler(as.swaggerGenerator(routes, "http://localhost:12345/api/v1", nil)))
 	s := httptest.NewServer(http.HandlerFunc(handler))
 	defer s.Close()
 
@@ -333,3 +346,265 @@ func TestContractAPISwaggerUI(t *testing.T) {
 	b, _ := ioutil.ReadAll(res.Body)
 	assert.Regexp(t, "html", string(b))
 }
+
+func newTestAuthAPIServer() (*orchestratormocks.Orchestrator, *authmocks.Plugin, *mux.Router) {
+	mgr, o, as := newTestServer()
+	mauth := &authmocks.Plugin{}
+	mauth.On("Name").Return("basic").Maybe()
+	mauth.On("SecurityScheme").Return(openapi3.NewSecurityScheme().WithType("http").WithScheme("basic")).Maybe()
+	as.auth = mauth
+	r := as.createMuxRouter(context.Background(), mgr)
+	return o, mauth, r
+}
+
+func TestStartServerUnknownAuthPlugin(t *testing.T) {
+	coreconfig.Reset()
+	metrics.Clear()
+	InitConfig()
+	apiAuthConfig.Set(coreconfig.PluginConfigType, "wrong")
+	as := NewAPIServer()
+	mgr := &namespacemocks.Manager{}
+	err := as.Serve(context.Background(), mgr)
+	assert.Regexp(t, "FF10412", err)
+}
+
+func TestStartServerAuthPluginInitFail(t *testing.T) {
+	coreconfig.Reset()
+	metrics.Clear()
+	InitConfig()
+	apiAuthConfig.Set(coreconfig.PluginConfigType, "jwt")
+	as := NewAPIServer()
+	mgr := &namespacemocks.Manager{}
+	err := as.Serve(context.Background(), mgr)
+	assert.Regexp(t, "FF10418", err)
+}
+
+func TestAuthMissingCredentials(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/batches", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "ns1" && req.ReadOnly && req.RouteName == "getBatchesNamespace"
+	})).Return(nil, i18n.NewError(context.Background(), coremsgs.MsgAuthCredentialsMissing))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 401, res.Result().StatusCode)
+	assert.Equal(t, "Basic", res.Result().Header.Get("WWW-Authenticate"))
+	mauth.AssertExpectations(t)
+}
+
+func TestAuthAllowed(t *testing.T) {
+	o, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/batches", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.Anything).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns1", ReadOnly: true}},
+	}, nil)
+	o.On("GetBatches", mock.Anything, "ns1", mock.Anything).
+		Return([]*core.BatchPersisted{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	mauth.AssertExpectations(t)
+	o.AssertExpectations(t)
+}
+
+func TestAuthWrongNamespace(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/batches", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.Anything).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns2"}},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	assert.Empty(t, res.Result().Header.Get("WWW-Authenticate"))
+	var resJSON map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&resJSON)
+	assert.Regexp(t, "FF10415", resJSON["error"])
+}
+
+func TestAuthReadOnlyWrite(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/datatypes", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "ns1" && !req.ReadOnly
+	})).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: auth.AllNamespaces, ReadOnly: true}},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestAuthDefaultNamespaceRoute(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/batches", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "default"
+	})).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns1"}},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestAuthSwaggerSecuritySchemes(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	s := httptest.NewServer(r)
+	defer s.Close()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "" && req.ReadOnly && req.RouteName == "swagger"
+	})).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns1", ReadOnly: true}},
+	}, nil)
+
+	res, err := http.Get(fmt.Sprintf("http://%s/api/swagger.json", s.Listener.Addr()))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	var doc openapi3.T
+	err = json.NewDecoder(res.Body).Decode(&doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "basic", doc.Components.SecuritySchemes["basic"].Value.Scheme)
+	getBatchesOp := doc.Paths["/namespaces/{ns}/batches"].Get
+	assert.Equal(t, openapi3.SecurityRequirements{{"basic": []string{}}}, *getBatchesOp.Security)
+}
+
+func TestAuthWebSocketMissingCredentials(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/ws?namespace=ns1", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "ns1" && req.ReadOnly && req.RouteName == "websocket"
+	})).Return(nil, i18n.NewError(context.Background(), coremsgs.MsgAuthCredentialsMissing))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 401, res.Result().StatusCode)
+	assert.Equal(t, "Basic", res.Result().Header.Get("WWW-Authenticate"))
+	mauth.AssertExpectations(t)
+}
+
+func TestAuthWebSocketWrongNamespace(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/ws?namespace=ns1", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.Anything).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns2"}},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestAuthSSEWrongNamespace(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/sse?namespace=ns1&ephemeral", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "ns1" && req.ReadOnly && req.RouteName == "sse"
+	})).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns2"}},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	mauth.AssertExpectations(t)
+}
+
+func TestAuthSSERewindReadOnly(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/sse?namespace=ns1&name=sub1", nil)
+	req.Header.Set("Last-Event-ID", "5")
+	res := httptest.NewRecorder()
+
+	// Resuming from a Last-Event-ID rewinds the subscription, so a read-only grant is not enough
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "ns1" && !req.ReadOnly && req.RouteName == "sse"
+	})).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns1", ReadOnly: true}},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	mauth.AssertExpectations(t)
+}
+
+func TestSkipRouteMiddleware(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(skipRouteMiddleware("skipped", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Set("X-Wrapped", "true")
+			next.ServeHTTP(res, req)
+		})
+	}))
+	handler := func(res http.ResponseWriter, req *http.Request) {}
+	r.HandleFunc("/skipped", handler).Name("skipped")
+	r.HandleFunc("/wrapped", handler)
+
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, httptest.NewRequest("GET", "/skipped", nil))
+	assert.Empty(t, res.Header().Get("X-Wrapped"))
+
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, httptest.NewRequest("GET", "/wrapped", nil))
+	assert.Equal(t, "true", res.Header().Get("X-Wrapped"))
+}
+
+func TestAuthContractAPISwaggerWrongNamespace(t *testing.T) {
+	_, mauth, r := newTestAuthAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/apis/my-api/api/swagger.json", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "ns1" && req.RouteName == "contractAPISwagger"
+	})).Return(&auth.Principal{
+		Subject: "user1",
+		Grants:  []*auth.Grant{{Namespace: "ns2"}},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	mauth.AssertExpectations(t)
+}
+
+func TestAuthSPIRoute(t *testing.T) {
+	mgr, _, as := newTestServer()
+	mauth := &authmocks.Plugin{}
+	mauth.On("Name").Return("jwt").Maybe()
+	mauth.On("SecurityScheme").Return(openapi3.NewJWTSecurityScheme()).Maybe()
+	as.auth = mauth
+	r := as.createAdminMuxRouter(mgr)
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/operations", nil)
+	res := httptest.NewRecorder()
+
+	mauth.On("Authenticate", mock.Anything, mock.MatchedBy(func(req *auth.Request) bool {
+		return req.Namespace == "ns1"
+	})).Return(nil, i18n.NewError(context.Background(), coremsgs.MsgAuthInvalidCredentials))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 401, res.Result().StatusCode)
+	assert.Equal(t, "Bearer", res.Result().Header.Get("WWW-Authenticate"))
+	mauth.AssertExpectations(t)
+}
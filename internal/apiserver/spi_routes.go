@@ -22,8 +22,10 @@ import "github.com/hyperledger/firefly-common/pkg/ffapi"
 // to act as augmented components to the core.
 var spiRoutes = []*ffapi.Route{
 	spiGetNamespaceByName,
+	spiGetNamespaceExport,
 	spiGetNamespaces,
 	spiGetOpByID,
 	spiGetOps,
 	spiPatchOpByID,
+	spiPostNamespaceImport,
 }
@@ -39,7 +39,7 @@ func TestDownloadSwaggerYAML(t *testing.T) {
 	config.Set(coreconfig.APIOASPanicOnMissingDescription, true)
 	as := &apiServer{}
 	hf := as.handlerFactory()
-	handler := hf.APIWrapper(as.swaggerHandler(as.swaggerGenerator(routes, "http://localhost:5000")))
+	handler := hf.APIWrapper(as.swaggerHandler(as.swaggerGenerator(routes, "http://localhost:5000", nil)))
 	s := httptest.NewServer(http.HandlerFunc(handler))
 	defer s.Close()
 
@@ -0,0 +1,99 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/auth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Basic struct {
+	users map[string]*user
+}
+
+type user struct {
+	passwordHash []byte
+	grants       []*auth.Grant
+}
+
+func (b *Basic) Name() string {
+	return "basic"
+}
+
+func (b *Basic) Init(ctx context.Context, config config.Section) error {
+	b.users = make(map[string]*user)
+
+	usersConfig := config.SubArray(BasicConfUsers)
+	initUsersConfig(usersConfig)
+	for i := 0; i < usersConfig.ArraySize(); i++ {
+		uc := usersConfig.ArrayEntry(i)
+		username := uc.GetString(BasicConfUsername)
+		passwordHash := uc.GetString(BasicConfPasswordHash)
+		if username == "" || passwordHash == "" {
+			return i18n.NewError(ctx, coremsgs.MsgBasicAuthUserConfigInvalid, i)
+		}
+		if _, err := bcrypt.Cost([]byte(passwordHash)); err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgBasicAuthPasswordHashInvalid, username)
+		}
+		u := &user{
+			passwordHash: []byte(passwordHash),
+		}
+		readOnly := uc.GetBool(BasicConfReadOnly)
+		namespaces := uc.GetStringSlice(BasicConfNamespaces)
+		if len(namespaces) == 0 {
+			namespaces = []string{auth.AllNamespaces}
+		}
+		for _, ns := range namespaces {
+			u.grants = append(u.grants, &auth.Grant{Namespace: ns, ReadOnly: readOnly})
+		}
+		b.users[username] = u
+	}
+	log.L(ctx).Infof("Basic auth configured with %d users", len(b.users))
+	return nil
+}
+
+func (b *Basic) Authenticate(ctx context.Context, req *auth.Request) (*auth.Principal, error) {
+	username, password, ok := (&http.Request{Header: req.Header}).BasicAuth()
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgAuthCredentialsMissing)
+	}
+	u, ok := b.users[username]
+	if !ok {
+		log.L(ctx).Warnf("Basic auth failed: unknown user '%s'", username)
+		return nil, i18n.NewError(ctx, coremsgs.MsgAuthInvalidCredentials)
+	}
+	if err := bcrypt.CompareHashAndPassword(u.passwordHash, []byte(password)); err != nil {
+		log.L(ctx).Warnf("Basic auth failed: invalid password for user '%s'", username)
+		return nil, i18n.NewError(ctx, coremsgs.MsgAuthInvalidCredentials)
+	}
+	return &auth.Principal{
+		Subject: username,
+		Grants:  u.grants,
+	}, nil
+}
+
+func (b *Basic) SecurityScheme() *openapi3.SecurityScheme {
+	return openapi3.NewSecurityScheme().WithType("http").WithScheme("basic")
+}
@@ -0,0 +1,139 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/auth"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var utConfig = config.RootSection("basic_unit_tests")
+
+func newTestBasic(t *testing.T, yamlConfig string) (*Basic, error) {
+	coreconfig.Reset()
+	b := &Basic{}
+	b.InitConfig(utConfig)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(yamlConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, "basic", b.Name())
+	return b, b.Init(context.Background(), utConfig)
+}
+
+func testHash(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return string(hash)
+}
+
+func basicAuthRequest(username, password string) *auth.Request {
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v1/status", nil)
+	req.SetBasicAuth(username, password)
+	return &auth.Request{
+		Method: req.Method,
+		URL:    req.URL,
+		Header: req.Header,
+	}
+}
+
+func TestAuthenticateOK(t *testing.T) {
+	b, err := newTestBasic(t, fmt.Sprintf(`
+basic_unit_tests:
+  users:
+  - username: user1
+    passwordhash: "%s"
+    namespaces: [ns1, ns2]
+    readonly: true
+  - username: admin
+    passwordhash: "%s"
+`, testHash(t, "pass1"), testHash(t, "pass2")))
+	assert.NoError(t, err)
+
+	p, err := b.Authenticate(context.Background(), basicAuthRequest("user1", "pass1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", p.Subject)
+	assert.Equal(t, []*auth.Grant{
+		{Namespace: "ns1", ReadOnly: true},
+		{Namespace: "ns2", ReadOnly: true},
+	}, p.Grants)
+
+	p, err = b.Authenticate(context.Background(), basicAuthRequest("admin", "pass2"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*auth.Grant{
+		{Namespace: auth.AllNamespaces, ReadOnly: false},
+	}, p.Grants)
+
+	assert.Equal(t, "basic", b.SecurityScheme().Scheme)
+}
+
+func TestAuthenticateBadPassword(t *testing.T) {
+	b, err := newTestBasic(t, fmt.Sprintf(`
+basic_unit_tests:
+  users:
+  - username: user1
+    passwordhash: "%s"
+`, testHash(t, "pass1")))
+	assert.NoError(t, err)
+
+	_, err = b.Authenticate(context.Background(), basicAuthRequest("user1", "wrong"))
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestAuthenticateUnknownUser(t *testing.T) {
+	b, err := newTestBasic(t, "")
+	assert.NoError(t, err)
+
+	_, err = b.Authenticate(context.Background(), basicAuthRequest("user1", "pass1"))
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestAuthenticateMissingCredentials(t *testing.T) {
+	b, err := newTestBasic(t, "")
+	assert.NoError(t, err)
+
+	_, err = b.Authenticate(context.Background(), &auth.Request{Header: http.Header{}})
+	assert.Regexp(t, "FF10413", err)
+}
+
+func TestInitMissingPassword(t *testing.T) {
+	_, err := newTestBasic(t, `
+basic_unit_tests:
+  users:
+  - username: user1
+`)
+	assert.Regexp(t, "FF10416", err)
+}
+
+func TestInitBadPasswordHash(t *testing.T) {
+	_, err := newTestBasic(t, `
+basic_unit_tests:
+  users:
+  - username: user1
+    passwordhash: not-a-hash
+`)
+	assert.Regexp(t, "FF10417", err)
+}
@@ -0,0 +1,47 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+)
+
+// Keys within each user entry are lowercase, as the config layer does not
+// case-fold the keys of objects nested inside arrays
+const (
+	// BasicConfUsers is the list of users that can authenticate
+	BasicConfUsers = "users"
+	// BasicConfUsername is the username of a user
+	BasicConfUsername = "username"
+	// BasicConfPasswordHash is the bcrypt hash of the password of a user
+	BasicConfPasswordHash = "passwordhash"
+	// BasicConfNamespaces is the list of namespaces a user can access (all namespaces if empty)
+	BasicConfNamespaces = "namespaces"
+	// BasicConfReadOnly restricts a user to read-only routes
+	BasicConfReadOnly = "readonly"
+)
+
+func (b *Basic) InitConfig(config config.Section) {
+	initUsersConfig(config.SubArray(BasicConfUsers))
+}
+
+func initUsersConfig(users config.ArraySection) {
+	users.AddKnownKey(BasicConfUsername)
+	users.AddKnownKey(BasicConfPasswordHash)
+	users.AddKnownKey(BasicConfNamespaces)
+	users.AddKnownKey(BasicConfReadOnly, false)
+}
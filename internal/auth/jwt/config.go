@@ -0,0 +1,54 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+)
+
+const (
+	// JWTConfIssuer is the required value of the iss claim (not checked if empty)
+	JWTConfIssuer = "issuer"
+	// JWTConfAudience is the required value (or one of the values) of the aud claim (not checked if empty)
+	JWTConfAudience = "audience"
+	// JWTConfHMACSecret is a shared secret for verifying HMAC signed tokens, as an alternative to JWKS
+	JWTConfHMACSecret = "hmacSecret"
+	// JWTConfScopeClaim is the claim containing the space separated (or array) list of scopes
+	JWTConfScopeClaim = "scopeClaim"
+	// JWTConfScopePrefix is the prefix of scopes that grant access to FireFly, in the form prefix:namespace:read|write
+	JWTConfScopePrefix = "scopePrefix"
+	// JWTConfClockSkew is the tolerance allowed when checking the exp and nbf claims
+	JWTConfClockSkew = "clockSkew"
+	// JWTConfJWKS is the sub-section for the HTTP client used to fetch a JSON Web Key Set
+	JWTConfJWKS = "jwks"
+	// JWTConfJWKSRefreshInterval is the minimum interval between reloads of the JSON Web Key Set
+	JWTConfJWKSRefreshInterval = "refreshInterval"
+)
+
+func (j *JWT) InitConfig(config config.Section) {
+	config.AddKnownKey(JWTConfIssuer)
+	config.AddKnownKey(JWTConfAudience)
+	config.AddKnownKey(JWTConfHMACSecret)
+	config.AddKnownKey(JWTConfScopeClaim, "scope")
+	config.AddKnownKey(JWTConfScopePrefix, "firefly")
+	config.AddKnownKey(JWTConfClockSkew, "30s")
+
+	jwksConf := config.SubSection(JWTConfJWKS)
+	ffresty.InitConfig(jwksConf)
+	jwksConf.AddKnownKey(JWTConfJWKSRefreshInterval, "5m")
+}
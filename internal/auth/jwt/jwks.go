@@ -0,0 +1,160 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use,omitempty"`
+	N       string `json:"n,omitempty"`
+	E       string `json:"e,omitempty"`
+	Curve   string `json:"crv,omitempty"`
+	X       string `json:"x,omitempty"`
+	Y       string `json:"y,omitempty"`
+}
+
+type jwkSet struct {
+	Keys []*jwk `json:"keys"`
+}
+
+type jwksCache struct {
+	client          *resty.Client
+	refreshInterval time.Duration
+	mux             sync.Mutex
+	keys            map[string]crypto.PublicKey
+	lastRefresh     time.Time
+}
+
+func (jc *jwksCache) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	jc.mux.Lock()
+	defer jc.mux.Unlock()
+
+	key, ok := jc.lookup(kid)
+	if !ok && time.Since(jc.lastRefresh) >= jc.refreshInterval {
+		// Rate limited refresh, to pick up rotated keys
+		if err := jc.refresh(ctx); err != nil {
+			return nil, err
+		}
+		key, ok = jc.lookup(kid)
+	}
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgJWTUnknownKey, kid)
+	}
+	return key, nil
+}
+
+func (jc *jwksCache) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(jc.keys) == 1 {
+		for _, key := range jc.keys {
+			return key, true
+		}
+	}
+	key, ok := jc.keys[kid]
+	return key, ok
+}
+
+func (jc *jwksCache) refresh(ctx context.Context) error {
+	// Failed attempts count towards the rate limit too, so tokens with unknown key IDs
+	// cannot be used to drive a request to the JWKS endpoint on every API call
+	jc.lastRefresh = time.Now()
+	var keySet jwkSet
+	res, err := jc.client.R().
+		SetContext(ctx).
+		SetResult(&keySet).
+		Get("")
+	if err != nil || !res.IsSuccess() {
+		return ffresty.WrapRestErr(ctx, res, err, coremsgs.MsgJWKSFetchFailed)
+	}
+	jc.keys = make(map[string]crypto.PublicKey)
+	for _, k := range keySet.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey(ctx)
+		if err != nil {
+			log.L(ctx).Warnf("Skipping JWK '%s': %s", k.KeyID, err)
+			continue
+		}
+		jc.keys[k.KeyID] = key
+	}
+	log.L(ctx).Debugf("Loaded %d keys from JWKS", len(jc.keys))
+	return nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k *jwk) publicKey(ctx context.Context) (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgJWTUnsupportedKey, k.KeyType, k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgJWTUnsupportedKey, k.KeyType, k.Curve)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/auth"
+)
+
+type JWT struct {
+	issuer      string
+	audience    string
+	hmacSecret  []byte
+	scopeClaim  string
+	scopePrefix string
+	clockSkew   time.Duration
+	jwks        *jwksCache
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+func (j *JWT) Name() string {
+	return "jwt"
+}
+
+func (j *JWT) Init(ctx context.Context, config config.Section) error {
+	j.issuer = config.GetString(JWTConfIssuer)
+	j.audience = config.GetString(JWTConfAudience)
+	j.hmacSecret = []byte(config.GetString(JWTConfHMACSecret))
+	j.scopeClaim = config.GetString(JWTConfScopeClaim)
+	j.scopePrefix = config.GetString(JWTConfScopePrefix)
+	j.clockSkew = config.GetDuration(JWTConfClockSkew)
+
+	jwksConf := config.SubSection(JWTConfJWKS)
+	if jwksConf.GetString(ffresty.HTTPConfigURL) != "" {
+		j.jwks = &jwksCache{
+			client:          ffresty.New(ctx, jwksConf),
+			refreshInterval: jwksConf.GetDuration(JWTConfJWKSRefreshInterval),
+		}
+	} else if len(j.hmacSecret) == 0 {
+		return i18n.NewError(ctx, coremsgs.MsgJWTNoVerificationKey)
+	}
+	return nil
+}
+
+func (j *JWT) Authenticate(ctx context.Context, req *auth.Request) (*auth.Principal, error) {
+	authHeader := req.Header.Get("Authorization")
+	if len(authHeader) < 7 || !strings.EqualFold(authHeader[0:7], "bearer ") {
+		return nil, i18n.NewError(ctx, coremsgs.MsgAuthCredentialsMissing)
+	}
+	claims, err := j.verify(ctx, strings.TrimSpace(authHeader[7:]))
+	if err != nil {
+		log.L(ctx).Warnf("JWT authentication failed: %s", err)
+		return nil, i18n.NewError(ctx, coremsgs.MsgAuthInvalidCredentials)
+	}
+	return &auth.Principal{
+		Subject: claims.GetString("sub"),
+		Grants:  j.parseScopes(claims),
+	}, nil
+}
+
+func (j *JWT) SecurityScheme() *openapi3.SecurityScheme {
+	return openapi3.NewJWTSecurityScheme()
+}
+
+func (j *JWT) verify(ctx context.Context, token string) (fftypes.JSONObject, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgJWTMalformed)
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgJWTMalformed)
+	}
+	var claims fftypes.JSONObject
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgJWTMalformed)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgJWTMalformed)
+	}
+	if err := j.verifySignature(ctx, &header, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+	if err := j.validateClaims(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func hashForAlgorithm(alg string) (crypto.Hash, func() hash.Hash) {
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, sha256.New
+	case "384":
+		return crypto.SHA384, sha512.New384
+	case "512":
+		return crypto.SHA512, sha512.New
+	default:
+		return 0, nil
+	}
+}
+
+func (j *JWT) verifySignature(ctx context.Context, header *jwtHeader, signed, signature []byte) error {
+	alg := header.Algorithm
+	if len(alg) != 5 {
+		return i18n.NewError(ctx, coremsgs.MsgJWTUnsupportedAlgorithm, alg)
+	}
+	hashType, hashFn := hashForAlgorithm(alg)
+	if hashFn == nil {
+		return i18n.NewError(ctx, coremsgs.MsgJWTUnsupportedAlgorithm, alg)
+	}
+
+	if strings.HasPrefix(alg, "HS") {
+		if len(j.hmacSecret) == 0 {
+			return i18n.NewError(ctx, coremsgs.MsgJWTUnsupportedAlgorithm, alg)
+		}
+		mac := hmac.New(hashFn, j.hmacSecret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return i18n.NewError(ctx, coremsgs.MsgJWTSignatureInvalid)
+		}
+		return nil
+	}
+
+	if j.jwks == nil {
+		return i18n.NewError(ctx, coremsgs.MsgJWTUnsupportedAlgorithm, alg)
+	}
+	key, err := j.jwks.getKey(ctx, header.KeyID)
+	if err != nil {
+		return err
+	}
+	h := hashFn()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	valid := false
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[0:2] {
+		case "RS":
+			valid = rsa.VerifyPKCS1v15(k, hashType, digest, signature) == nil
+		case "PS":
+			valid = rsa.VerifyPSS(k, hashType, digest, signature, nil) == nil
+		}
+	case *ecdsa.PublicKey:
+		// JWS encodes ECDSA signatures as the fixed length concatenation of R and S
+		keySize := (k.Curve.Params().BitSize + 7) / 8
+		if alg[0:2] == "ES" && len(signature) == 2*keySize {
+			r := new(big.Int).SetBytes(signature[:keySize])
+			s := new(big.Int).SetBytes(signature[keySize:])
+			valid = ecdsa.Verify(k, digest, r, s)
+		}
+	}
+	if !valid {
+		return i18n.NewError(ctx, coremsgs.MsgJWTSignatureInvalid)
+	}
+	return nil
+}
+
+func (j *JWT) validateClaims(ctx context.Context, claims fftypes.JSONObject) error {
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(j.clockSkew)) {
+		return i18n.NewError(ctx, coremsgs.MsgJWTClaimInvalid, "exp")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(j.clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return i18n.NewError(ctx, coremsgs.MsgJWTClaimInvalid, "nbf")
+	}
+	if j.issuer != "" && claims.GetString("iss") != j.issuer {
+		return i18n.NewError(ctx, coremsgs.MsgJWTClaimInvalid, "iss")
+	}
+	if j.audience != "" {
+		matched := false
+		for _, aud := range claimStrings(claims["aud"]) {
+			if aud == j.audience {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return i18n.NewError(ctx, coremsgs.MsgJWTClaimInvalid, "aud")
+		}
+	}
+	return nil
+}
+
+// claimStrings handles claims that can be either a space separated string, or an array of strings
+func claimStrings(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		return strs
+	default:
+		return nil
+	}
+}
+
+// parseScopes extracts grants from scopes of the form prefix:namespace:read or prefix:namespace:write,
+// where namespace can be "*" to grant access to all namespaces
+func (j *JWT) parseScopes(claims fftypes.JSONObject) []*auth.Grant {
+	grants := make([]*auth.Grant, 0)
+	for _, scope := range claimStrings(claims[j.scopeClaim]) {
+		parts := strings.Split(scope, ":")
+		if len(parts) != 3 || parts[0] != j.scopePrefix {
+			continue
+		}
+		switch parts[2] {
+		case "read":
+			grants = append(grants, &auth.Grant{Namespace: parts[1], ReadOnly: true})
+		case "write":
+			grants = append(grants, &auth.Grant{Namespace: parts[1], ReadOnly: false})
+		}
+	}
+	return grants
+}
@@ -0,0 +1,297 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+var utConfig = config.RootSection("jwt_unit_tests")
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func unsignedToken(header, claims fftypes.JSONObject) string {
+	hb, _ := json.Marshal(header)
+	cb, _ := json.Marshal(claims)
+	return b64(hb) + "." + b64(cb)
+}
+
+func hmacToken(secret string, claims fftypes.JSONObject) string {
+	signed := unsignedToken(fftypes.JSONObject{"alg": "HS256", "typ": "JWT"}, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + b64(mac.Sum(nil))
+}
+
+func rsaToken(key *rsa.PrivateKey, kid string, claims fftypes.JSONObject) string {
+	signed := unsignedToken(fftypes.JSONObject{"alg": "RS256", "kid": kid}, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	return signed + "." + b64(sig)
+}
+
+func ecToken(key *ecdsa.PrivateKey, kid string, claims fftypes.JSONObject) string {
+	signed := unsignedToken(fftypes.JSONObject{"alg": "ES256", "kid": kid}, claims)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+	sig := make([]byte, 64)
+	r.FillBytes(sig[0:32])
+	s.FillBytes(sig[32:64])
+	return signed + "." + b64(sig)
+}
+
+func validClaims() fftypes.JSONObject {
+	return fftypes.JSONObject{
+		"sub":   "user1",
+		"iss":   "https://issuer.example.com",
+		"aud":   []string{"firefly", "other"},
+		"exp":   time.Now().Add(1 * time.Hour).Unix(),
+		"scope": "openid firefly:ns1:read firefly:ns2:write firefly:ns3:bad other:ns4:write",
+	}
+}
+
+func bearerRequest(token string) *auth.Request {
+	return &auth.Request{
+		Header: http.Header{
+			"Authorization": []string{"Bearer " + token},
+		},
+	}
+}
+
+func newTestJWT(t *testing.T, jwksURL string) (*JWT, error) {
+	coreconfig.Reset()
+	j := &JWT{}
+	j.InitConfig(utConfig)
+	utConfig.Set(JWTConfIssuer, "https://issuer.example.com")
+	utConfig.Set(JWTConfAudience, "firefly")
+	if jwksURL != "" {
+		utConfig.SubSection(JWTConfJWKS).Set(ffresty.HTTPConfigURL, jwksURL)
+	} else {
+		utConfig.Set(JWTConfHMACSecret, "secret")
+	}
+	assert.Equal(t, "jwt", j.Name())
+	return j, j.Init(context.Background(), utConfig)
+}
+
+func newTestJWKSServer(t *testing.T, keys ...*jwk) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(res).Encode(&jwkSet{Keys: keys})
+	}))
+}
+
+func TestInitNoKeys(t *testing.T) {
+	coreconfig.Reset()
+	j := &JWT{}
+	j.InitConfig(utConfig)
+	err := j.Init(context.Background(), utConfig)
+	assert.Regexp(t, "FF10418", err)
+}
+
+func TestAuthenticateHMACOK(t *testing.T) {
+	j, err := newTestJWT(t, "")
+	assert.NoError(t, err)
+
+	p, err := j.Authenticate(context.Background(), bearerRequest(hmacToken("secret", validClaims())))
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", p.Subject)
+	assert.Equal(t, []*auth.Grant{
+		{Namespace: "ns1", ReadOnly: true},
+		{Namespace: "ns2", ReadOnly: false},
+	}, p.Grants)
+	assert.Equal(t, "bearer", j.SecurityScheme().Scheme)
+}
+
+func TestAuthenticateHMACBadSignature(t *testing.T) {
+	j, err := newTestJWT(t, "")
+	assert.NoError(t, err)
+
+	_, err = j.Authenticate(context.Background(), bearerRequest(hmacToken("wrong", validClaims())))
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestAuthenticateMissingBearer(t *testing.T) {
+	j, err := newTestJWT(t, "")
+	assert.NoError(t, err)
+
+	_, err = j.Authenticate(context.Background(), &auth.Request{Header: http.Header{}})
+	assert.Regexp(t, "FF10413", err)
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	j, err := newTestJWT(t, "")
+	assert.NoError(t, err)
+
+	_, err = j.verify(context.Background(), "not.a-jwt")
+	assert.Regexp(t, "FF10419", err)
+	_, err = j.verify(context.Background(), "!!.e30.AA")
+	assert.Regexp(t, "FF10419", err)
+	_, err = j.verify(context.Background(), "e30.!!.AA")
+	assert.Regexp(t, "FF10419", err)
+	_, err = j.verify(context.Background(), "e30.e30.!!")
+	assert.Regexp(t, "FF10419", err)
+}
+
+func TestVerifyUnsupportedAlgorithm(t *testing.T) {
+	j, err := newTestJWT(t, "")
+	assert.NoError(t, err)
+
+	_, err = j.verify(context.Background(), unsignedToken(fftypes.JSONObject{"alg": "none"}, validClaims())+".")
+	assert.Regexp(t, "FF10420", err)
+	_, err = j.verify(context.Background(), unsignedToken(fftypes.JSONObject{"alg": "HS999"}, validClaims())+".")
+	assert.Regexp(t, "FF10420", err)
+	_, err = j.verify(context.Background(), unsignedToken(fftypes.JSONObject{"alg": "RS256"}, validClaims())+".")
+	assert.Regexp(t, "FF10420", err)
+}
+
+func TestVerifyClaims(t *testing.T) {
+	j, err := newTestJWT(t, "")
+	assert.NoError(t, err)
+
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-1 * time.Hour).Unix()
+	_, err = j.verify(context.Background(), hmacToken("secret", claims))
+	assert.Regexp(t, "FF10422.*exp", err)
+
+	claims = validClaims()
+	delete(claims, "exp")
+	_, err = j.verify(context.Background(), hmacToken("secret", claims))
+	assert.Regexp(t, "FF10422.*exp", err)
+
+	claims = validClaims()
+	claims["nbf"] = time.Now().Add(1 * time.Hour).Unix()
+	_, err = j.verify(context.Background(), hmacToken("secret", claims))
+	assert.Regexp(t, "FF10422.*nbf", err)
+
+	claims = validClaims()
+	claims["iss"] = "https://other.example.com"
+	_, err = j.verify(context.Background(), hmacToken("secret", claims))
+	assert.Regexp(t, "FF10422.*iss", err)
+
+	claims = validClaims()
+	claims["aud"] = "other"
+	_, err = j.verify(context.Background(), hmacToken("secret", claims))
+	assert.Regexp(t, "FF10422.*aud", err)
+
+	claims = validClaims()
+	claims["aud"] = 12345
+	_, err = j.verify(context.Background(), hmacToken("secret", claims))
+	assert.Regexp(t, "FF10422.*aud", err)
+}
+
+func TestAuthenticateJWKSRSAOK(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	server := newTestJWKSServer(t,
+		&jwk{KeyType: "RSA", KeyID: "key1", Use: "sig", N: b64(key.N.Bytes()), E: b64(big.NewInt(int64(key.E)).Bytes())},
+		&jwk{KeyType: "RSA", KeyID: "enc", Use: "enc"},
+		&jwk{KeyType: "oct", KeyID: "key2"},
+	)
+	defer server.Close()
+
+	j, err := newTestJWT(t, server.URL)
+	assert.NoError(t, err)
+
+	p, err := j.Authenticate(context.Background(), bearerRequest(rsaToken(key, "key1", validClaims())))
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", p.Subject)
+
+	_, err = j.verify(context.Background(), rsaToken(key, "unknown", validClaims()))
+	assert.Regexp(t, "FF10423", err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, err = j.verify(context.Background(), rsaToken(otherKey, "key1", validClaims()))
+	assert.Regexp(t, "FF10421", err)
+}
+
+func TestAuthenticateJWKSECOK(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	server := newTestJWKSServer(t,
+		&jwk{KeyType: "EC", KeyID: "key1", Curve: "P-256", X: b64(key.X.Bytes()), Y: b64(key.Y.Bytes())},
+	)
+	defer server.Close()
+
+	j, err := newTestJWT(t, server.URL)
+	assert.NoError(t, err)
+
+	// Single key in the set can be used without a kid
+	p, err := j.Authenticate(context.Background(), bearerRequest(ecToken(key, "", validClaims())))
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", p.Subject)
+
+	_, err = j.verify(context.Background(), unsignedToken(fftypes.JSONObject{"alg": "ES256", "kid": "key1"}, validClaims())+".AAAA")
+	assert.Regexp(t, "FF10421", err)
+}
+
+func TestJWKSFetchFail(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		fetches++
+		res.WriteHeader(500)
+	}))
+	defer server.Close()
+
+	j, err := newTestJWT(t, server.URL)
+	assert.NoError(t, err)
+
+	_, err = j.verify(context.Background(), unsignedToken(fftypes.JSONObject{"alg": "RS256", "kid": "key1"}, validClaims())+".AAAA")
+	assert.Regexp(t, "FF10425", err)
+
+	// The failed fetch is rate limited in the same way as a successful one
+	_, err = j.verify(context.Background(), unsignedToken(fftypes.JSONObject{"alg": "RS256", "kid": "key1"}, validClaims())+".AAAA")
+	assert.Regexp(t, "FF10423", err)
+	assert.Equal(t, 1, fetches)
+}
+
+func TestJWKPublicKeyErrors(t *testing.T) {
+	ctx := context.Background()
+	_, err := (&jwk{KeyType: "RSA", N: "!!"}).publicKey(ctx)
+	assert.Error(t, err)
+	_, err = (&jwk{KeyType: "RSA", N: "AQAB", E: "!!"}).publicKey(ctx)
+	assert.Error(t, err)
+	_, err = (&jwk{KeyType: "EC", Curve: "P-999"}).publicKey(ctx)
+	assert.Regexp(t, "FF10424", err)
+	_, err = (&jwk{KeyType: "EC", Curve: "P-384", X: "!!"}).publicKey(ctx)
+	assert.Error(t, err)
+	_, err = (&jwk{KeyType: "EC", Curve: "P-521", X: "AQAB", Y: "!!"}).publicKey(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,415 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// collection describes how the records of one collection are exported and restored
+type collection struct {
+	name string
+	// queryFactory is nil for collections that are not namespaced, which are exported by reference from the collections before them
+	queryFactory database.QueryFactory
+	// export writes one page of the collection, returning the result of the query so the next page can be found
+	export  func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error)
+	restore func(ctx context.Context, bm *backupManager, rc *restoreContext) error
+}
+
+// collections are listed in the order they are exported
+var collections = []*collection{
+	{
+		name:         string(database.CollectionDataTypes),
+		queryFactory: database.DatatypeQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			datatypes, fr, err := bm.database.GetDatatypes(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionDataTypes), fr, len(datatypes), func(i int) interface{} { return datatypes[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var datatype core.Datatype
+			if err := rc.parse(ctx, bm, &datatype, func() string { return datatype.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertDatatype(ctx, &datatype, false)
+		},
+	},
+	{
+		name:         string(database.CollectionIdentities),
+		queryFactory: database.IdentityQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			identities, fr, err := bm.database.GetIdentities(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionIdentities), fr, len(identities), func(i int) interface{} { return identities[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var identity core.Identity
+			if err := rc.parse(ctx, bm, &identity, func() string { return identity.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertIdentity(ctx, &identity, database.UpsertOptimizationNew)
+		},
+	},
+	{
+		name:         string(database.CollectionVerifiers),
+		queryFactory: database.VerifierQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			verifiers, fr, err := bm.database.GetVerifiers(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionVerifiers), fr, len(verifiers), func(i int) interface{} { return verifiers[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var verifier core.Verifier
+			if err := rc.parse(ctx, bm, &verifier, func() string { return verifier.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertVerifier(ctx, &verifier, database.UpsertOptimizationNew)
+		},
+	},
+	{
+		name:         string(database.CollectionFFIs),
+		queryFactory: database.FFIQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			ffis, fr, err := bm.database.GetFFIs(ctx, bm.namespace, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionFFIs), fr, len(ffis), func(i int) interface{} { return ffis[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var ffi core.FFI
+			if err := rc.parse(ctx, bm, &ffi, func() string { return ffi.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertFFI(ctx, &ffi)
+		},
+	},
+	{
+		name:         string(database.CollectionFFIMethods),
+		queryFactory: database.FFIMethodQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			methods, fr, err := bm.database.GetFFIMethods(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionFFIMethods), fr, len(methods), func(i int) interface{} { return methods[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var method core.FFIMethod
+			if err := rc.parse(ctx, bm, &method, func() string { return method.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertFFIMethod(ctx, &method)
+		},
+	},
+	{
+		name:         string(database.CollectionFFIEvents),
+		queryFactory: database.FFIEventQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			events, fr, err := bm.database.GetFFIEvents(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionFFIEvents), fr, len(events), func(i int) interface{} { return events[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var event core.FFIEvent
+			if err := rc.parse(ctx, bm, &event, func() string { return event.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertFFIEvent(ctx, &event)
+		},
+	},
+	{
+		name:         string(database.CollectionFFIErrors),
+		queryFactory: database.FFIErrorQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			errors, fr, err := bm.database.GetFFIErrors(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionFFIErrors), fr, len(errors), func(i int) interface{} { return errors[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var errorDef core.FFIError
+			if err := rc.parse(ctx, bm, &errorDef, func() string { return errorDef.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertFFIError(ctx, &errorDef)
+		},
+	},
+	{
+		name:         string(database.CollectionContractAPIs),
+		queryFactory: database.ContractAPIQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			apis, fr, err := bm.database.GetContractAPIs(ctx, bm.namespace, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionContractAPIs), fr, len(apis), func(i int) interface{} { return apis[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var api core.ContractAPI
+			if err := rc.parse(ctx, bm, &api, func() string { return api.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertContractAPI(ctx, &api)
+		},
+	},
+	{
+		name:         string(database.CollectionTokenPools),
+		queryFactory: database.TokenPoolQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			pools, fr, err := bm.database.GetTokenPools(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionTokenPools), fr, len(pools), func(i int) interface{} { return pools[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var pool core.TokenPool
+			if err := rc.parse(ctx, bm, &pool, func() string { return pool.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertTokenPool(ctx, &pool)
+		},
+	},
+	{
+		name:         string(database.CollectionData),
+		queryFactory: database.DataQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			data, fr, err := bm.database.GetData(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			for _, d := range data {
+				if d.Blob != nil {
+					ex.addBlob(d.Blob.Hash)
+				}
+			}
+			return ex.writePage(string(database.CollectionData), fr, len(data), func(i int) interface{} { return data[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var data core.Data
+			if err := rc.parse(ctx, bm, &data, func() string { return data.Namespace }); err != nil {
+				return err
+			}
+			// The value size is not part of the JSON, so is recalculated
+			data.ValueSize = data.Value.Length()
+			return bm.database.InsertDataArray(ctx, core.DataArray{&data})
+		},
+	},
+	{
+		// Blobs are shared between namespaces, so only those referenced by the exported data are included
+		name: string(database.CollectionBlobs),
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, _ database.AndFilter) (int, *database.FilterResult, error) {
+			total := 0
+			for start := 0; start < len(ex.blobs); start += int(bm.pageSize) {
+				end := start + int(bm.pageSize)
+				if end > len(ex.blobs) {
+					end = len(ex.blobs)
+				}
+				hashes := make([]driver.Value, 0, end-start)
+				for _, hash := range ex.blobs[start:end] {
+					hashes = append(hashes, hash)
+				}
+				fb := database.BlobQueryFactory.NewFilter(ctx)
+				blobs, _, err := bm.database.GetBlobs(ctx, fb.In("hash", hashes))
+				if err != nil {
+					return total, nil, err
+				}
+				count, err := ex.writeEach(string(database.CollectionBlobs), len(blobs), func(i int) interface{} { return blobs[i] })
+				total += count
+				if err != nil {
+					return total, nil, err
+				}
+			}
+			return total, nil, nil
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var blob core.Blob
+			if err := rc.parse(ctx, bm, &blob, nil); err != nil {
+				return err
+			}
+			return bm.database.InsertBlob(ctx, &blob)
+		},
+	},
+	{
+		name:         string(database.CollectionMessages),
+		queryFactory: database.MessageQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			messages, fr, err := bm.database.GetMessages(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionMessages), fr, len(messages), func(i int) interface{} { return messages[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var message core.Message
+			if err := rc.parse(ctx, bm, &message, func() string { return message.Header.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.InsertMessages(ctx, []*core.Message{&message})
+		},
+	},
+	{
+		name:         string(database.CollectionBatches),
+		queryFactory: database.BatchQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			batches, fr, err := bm.database.GetBatches(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionBatches), fr, len(batches), func(i int) interface{} { return batches[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var batch core.BatchPersisted
+			if err := rc.parse(ctx, bm, &batch, func() string { return batch.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertBatch(ctx, &batch)
+		},
+	},
+	{
+		name:         string(database.CollectionPins),
+		queryFactory: database.PinQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			pins, fr, err := bm.database.GetPins(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionPins), fr, len(pins), func(i int) interface{} { return pins[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var pin core.Pin
+			if err := rc.parse(ctx, bm, &pin, func() string { return pin.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.InsertPins(ctx, []*core.Pin{&pin})
+		},
+	},
+	{
+		name:         string(database.CollectionTokenTransfers),
+		queryFactory: database.TokenTransferQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			transfers, fr, err := bm.database.GetTokenTransfers(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionTokenTransfers), fr, len(transfers), func(i int) interface{} { return transfers[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var transfer core.TokenTransfer
+			if err := rc.parse(ctx, bm, &transfer, func() string { return transfer.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.UpsertTokenTransfer(ctx, &transfer)
+		},
+	},
+	{
+		// Balances are restored as they were exported, rather than being recalculated from the transfers
+		name:         string(database.CollectionTokenBalances),
+		queryFactory: database.TokenBalanceQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			balances, fr, err := bm.database.GetTokenBalances(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			return ex.writePage(string(database.CollectionTokenBalances), fr, len(balances), func(i int) interface{} { return balances[i] })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var balance core.TokenBalance
+			if err := rc.parse(ctx, bm, &balance, func() string { return balance.Namespace }); err != nil {
+				return err
+			}
+			return bm.database.InsertTokenBalance(ctx, &balance)
+		},
+	},
+	{
+		name:         string(database.CollectionSubscriptions),
+		queryFactory: database.SubscriptionQueryFactory,
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, filter database.AndFilter) (int, *database.FilterResult, error) {
+			subs, fr, err := bm.database.GetSubscriptions(ctx, filter)
+			if err != nil {
+				return 0, nil, err
+			}
+			for _, sub := range subs {
+				ex.subscriptions = append(ex.subscriptions, sub.ID)
+			}
+			// Webhook secrets are write-only, so are not included in the export
+			return ex.writePage(string(database.CollectionSubscriptions), fr, len(subs), func(i int) interface{} { return subs[i].Redacted() })
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var sub core.Subscription
+			if err := rc.parse(ctx, bm, &sub, func() string { return sub.Namespace }); err != nil {
+				return err
+			}
+			options := sub.Options.TransportOptions()
+			if options.GetString("secret") == core.WebhookSecretRedacted {
+				log.L(ctx).Warnf("The secret of subscription '%s' is not included in the export, and must be set again", sub.Name)
+				delete(options, "secret")
+			}
+			return bm.database.UpsertSubscription(ctx, &sub, false)
+		},
+	},
+	{
+		// Offsets are not namespaced, so only the offsets of the exported subscriptions are included
+		name: string(database.CollectionOffsets),
+		export: func(ctx context.Context, bm *backupManager, ex *exporter, _ database.AndFilter) (int, *database.FilterResult, error) {
+			count := 0
+			for _, id := range ex.subscriptions {
+				offset, err := bm.database.GetOffset(ctx, core.OffsetTypeSubscription, id.String())
+				if err != nil {
+					return count, nil, err
+				}
+				if offset != nil {
+					if err := ex.write(string(database.CollectionOffsets), offset); err != nil {
+						return count, nil, err
+					}
+					count++
+				}
+			}
+			return count, nil, nil
+		},
+		restore: func(ctx context.Context, bm *backupManager, rc *restoreContext) error {
+			var offset core.Offset
+			if err := rc.parse(ctx, bm, &offset, nil); err != nil {
+				return err
+			}
+			return bm.database.UpsertOffset(ctx, &offset, false)
+		},
+	},
+}
+
+var collectionsByName = make(map[string]*collection)
+
+func init() {
+	for _, c := range collections {
+		collectionsByName[c.name] = c
+	}
+}
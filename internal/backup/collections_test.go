@@ -0,0 +1,83 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRestoreInvalidRecords(t *testing.T) {
+	bm, _ := newTestBackupManager(t)
+	for _, c := range collections {
+		err := c.restore(context.Background(), bm, &restoreContext{index: 5, record: []byte(`"bad"`)})
+		assert.Regexp(t, "FF10456.*5", err, c.name)
+	}
+}
+
+func TestExportBlobsPaged(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	bm.pageSize = 1
+	ex := newExporter(func(collection string, record interface{}) error { return nil })
+	hash1, hash2 := fftypes.NewRandB32(), fftypes.NewRandB32()
+	ex.addBlob(hash1)
+	ex.addBlob(hash1)
+	ex.addBlob(hash2)
+	ex.addBlob(nil)
+	assert.Len(t, ex.blobs, 2)
+
+	mdi.On("GetBlobs", mock.Anything, mock.Anything).Return([]*core.Blob{{Hash: hash1}}, nil, nil).Twice()
+	count, _, err := collectionsByName[string(database.CollectionBlobs)].export(context.Background(), bm, ex, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mdi.AssertExpectations(t)
+}
+
+func TestExportBlobsFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	ex := newExporter(func(collection string, record interface{}) error { return nil })
+	ex.addBlob(fftypes.NewRandB32())
+	mdi.On("GetBlobs", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, _, err := collectionsByName[string(database.CollectionBlobs)].export(context.Background(), bm, ex, nil)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportBlobsWriteFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	ex := newExporter(func(collection string, record interface{}) error { return fmt.Errorf("pop") })
+	ex.addBlob(fftypes.NewRandB32())
+	mdi.On("GetBlobs", mock.Anything, mock.Anything).Return([]*core.Blob{{}}, nil, nil)
+	_, _, err := collectionsByName[string(database.CollectionBlobs)].export(context.Background(), bm, ex, nil)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportOffsetsWriteFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	ex := newExporter(func(collection string, record interface{}) error { return fmt.Errorf("pop") })
+	ex.subscriptions = []*fftypes.UUID{fftypes.NewUUID(), fftypes.NewUUID()}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, ex.subscriptions[0].String()).Return(nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, ex.subscriptions[1].String()).Return(&core.Offset{}, nil)
+	_, _, err := collectionsByName[string(database.CollectionOffsets)].export(context.Background(), bm, ex, nil)
+	assert.EqualError(t, err, "pop")
+}
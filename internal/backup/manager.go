@@ -0,0 +1,247 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// namespaceCollection is the collection of the first record in every export, which identifies the namespace
+const namespaceCollection = "namespace"
+
+const defaultPageSize = 100
+
+// Manager exports all of the records of a namespace as NDJSON, and imports them again into an empty database
+type Manager interface {
+	Export(ctx context.Context, w io.Writer) error
+	Import(ctx context.Context, r io.Reader) (*core.NamespaceImportResult, error)
+}
+
+// exportRecord is a single line of an export
+type exportRecord struct {
+	Collection string      `json:"collection"`
+	Record     interface{} `json:"record"`
+}
+
+// importRecord is a single line of an import, with the record left for the collection to parse
+type importRecord struct {
+	Collection string          `json:"collection"`
+	Record     json.RawMessage `json:"record"`
+}
+
+type backupManager struct {
+	namespace string
+	database  database.Plugin
+	pageSize  uint64
+}
+
+func NewBackupManager(ctx context.Context, ns string, di database.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "BackupManager")
+	}
+	return &backupManager{
+		namespace: ns,
+		database:  di,
+		pageSize:  defaultPageSize,
+	}, nil
+}
+
+// Export writes every record of the namespace to the writer, one JSON object per line. The collections
+// are written in an order that allows them to be restored one by one.
+func (bm *backupManager) Export(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	ex := newExporter(func(collection string, record interface{}) error {
+		return enc.Encode(&exportRecord{Collection: collection, Record: record})
+	})
+
+	namespace, err := bm.database.GetNamespace(ctx, bm.namespace)
+	if err != nil {
+		return err
+	}
+	if namespace == nil {
+		namespace = &core.Namespace{Name: bm.namespace}
+	}
+	if err := ex.write(namespaceCollection, namespace); err != nil {
+		return err
+	}
+
+	for _, c := range collections {
+		if c.queryFactory == nil {
+			if _, _, err := c.export(ctx, bm, ex, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		// Each page follows the last item of the previous one in the sequence of the collection, so that
+		// records inserted or deleted while the export is running do not shift the pages
+		for cursor := ""; ; {
+			_, fr, err := c.export(ctx, bm, ex, bm.pageFilter(ctx, c, cursor, bm.pageSize))
+			if err != nil {
+				return err
+			}
+			if fr == nil || fr.Next == "" {
+				break
+			}
+			cursor = fr.Next
+		}
+		log.L(ctx).Debugf("Exported %s from namespace '%s'", c.name, bm.namespace)
+	}
+	return nil
+}
+
+// pageFilter queries a page of the namespaced records of a collection, in ascending order of sequence,
+// following the supplied cursor
+func (bm *backupManager) pageFilter(ctx context.Context, c *collection, cursor string, limit uint64) database.AndFilter {
+	fb := c.queryFactory.NewFilter(ctx)
+	filter := fb.And(fb.Eq("namespace", bm.namespace))
+	filter.After(cursor).Ascending().Limit(limit)
+	return filter
+}
+
+// Import restores an export into the namespace, as a single database transaction. The namespace must not
+// contain any records already, and every record in the export must belong to the namespace.
+func (bm *backupManager) Import(ctx context.Context, r io.Reader) (*core.NamespaceImportResult, error) {
+	result := &core.NamespaceImportResult{
+		Namespace: bm.namespace,
+		Records:   make(map[string]int64),
+	}
+	dec := json.NewDecoder(r)
+
+	var header importRecord
+	if err := dec.Decode(&header); err != nil || header.Collection != namespaceCollection {
+		return nil, i18n.NewError(ctx, coremsgs.MsgImportMissingHeader)
+	}
+	var namespace core.Namespace
+	if err := json.Unmarshal(header.Record, &namespace); err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgImportInvalidRecord, 0, err)
+	}
+	if namespace.Name != bm.namespace {
+		return nil, i18n.NewError(ctx, coremsgs.MsgImportNamespaceMismatch, 0, namespace.Name, bm.namespace)
+	}
+
+	err := bm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := bm.checkEmpty(ctx); err != nil {
+			return err
+		}
+		for i := 1; ; i++ {
+			var record importRecord
+			err := dec.Decode(&record)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return i18n.NewError(ctx, coremsgs.MsgImportInvalidRecord, i, err)
+			}
+			c, ok := collectionsByName[record.Collection]
+			if !ok {
+				return i18n.NewError(ctx, coremsgs.MsgImportUnknownCollection, i, record.Collection)
+			}
+			if err := c.restore(ctx, bm, &restoreContext{index: i, record: record.Record}); err != nil {
+				return err
+			}
+			result.Records[c.name]++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Imported %v into namespace '%s'", result.Records, bm.namespace)
+	return result, nil
+}
+
+// checkEmpty verifies that none of the namespaced collections contain a record
+func (bm *backupManager) checkEmpty(ctx context.Context) error {
+	ex := newExporter(func(collection string, record interface{}) error { return nil })
+	for _, c := range collections {
+		if c.queryFactory == nil {
+			continue
+		}
+		count, _, err := c.export(ctx, bm, ex, bm.pageFilter(ctx, c, "", 1))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return i18n.NewError(ctx, coremsgs.MsgImportNamespaceNotEmpty, bm.namespace, c.name)
+		}
+	}
+	return nil
+}
+
+// restoreContext holds a single record of an import, along with its position for error reporting
+type restoreContext struct {
+	index  int
+	record json.RawMessage
+}
+
+func (rc *restoreContext) parse(ctx context.Context, bm *backupManager, v interface{}, namespace func() string) error {
+	if err := json.Unmarshal(rc.record, v); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgImportInvalidRecord, rc.index, err)
+	}
+	if namespace != nil {
+		if ns := namespace(); ns != bm.namespace {
+			return i18n.NewError(ctx, coremsgs.MsgImportNamespaceMismatch, rc.index, ns, bm.namespace)
+		}
+	}
+	return nil
+}
+
+// exporter writes the records of each collection, and collects the references needed to export the
+// collections that are not namespaced themselves
+type exporter struct {
+	write         func(collection string, record interface{}) error
+	blobs         []*fftypes.Bytes32
+	seenBlobs     map[fftypes.Bytes32]bool
+	subscriptions []*fftypes.UUID
+}
+
+func newExporter(write func(collection string, record interface{}) error) *exporter {
+	return &exporter{
+		write:     write,
+		seenBlobs: make(map[fftypes.Bytes32]bool),
+	}
+}
+
+func (ex *exporter) addBlob(hash *fftypes.Bytes32) {
+	if hash != nil && !ex.seenBlobs[*hash] {
+		ex.seenBlobs[*hash] = true
+		ex.blobs = append(ex.blobs, hash)
+	}
+}
+
+// writePage writes the records of a page of a collection, passing through the result of the query for the page
+func (ex *exporter) writePage(collection string, fr *database.FilterResult, count int, record func(i int) interface{}) (int, *database.FilterResult, error) {
+	written, err := ex.writeEach(collection, count, record)
+	return written, fr, err
+}
+
+func (ex *exporter) writeEach(collection string, count int, record func(i int) interface{}) (int, error) {
+	for i := 0; i < count; i++ {
+		if err := ex.write(collection, record(i)); err != nil {
+			return i, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,389 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testRecords struct {
+	datatype *core.Datatype
+	identity *core.Identity
+	verifier *core.Verifier
+	ffi      *core.FFI
+	method   *core.FFIMethod
+	event    *core.FFIEvent
+	errorDef *core.FFIError
+	api      *core.ContractAPI
+	pool     *core.TokenPool
+	data     *core.Data
+	blob     *core.Blob
+	message  *core.Message
+	batch    *core.BatchPersisted
+	pin      *core.Pin
+	transfer *core.TokenTransfer
+	balance  *core.TokenBalance
+	sub      *core.Subscription
+	offset   *core.Offset
+}
+
+func newTestRecords() *testRecords {
+	blobHash := fftypes.NewRandB32()
+	subID := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"}}
+	sub.Options.TransportOptions()["secret"] = "shh"
+	return &testRecords{
+		datatype: &core.Datatype{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "dt1"},
+		identity: &core.Identity{IdentityBase: core.IdentityBase{ID: fftypes.NewUUID(), Namespace: "ns1"}},
+		verifier: &core.Verifier{Hash: fftypes.NewRandB32(), Namespace: "ns1"},
+		ffi:      &core.FFI{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		method:   &core.FFIMethod{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		event:    &core.FFIEvent{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		errorDef: &core.FFIError{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		api:      &core.ContractAPI{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		pool:     &core.TokenPool{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		data: &core.Data{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Value:     fftypes.JSONAnyPtr(`"value1"`),
+			Blob:      &core.BlobRef{Hash: blobHash},
+		},
+		blob:     &core.Blob{Hash: blobHash, PayloadRef: "ref1"},
+		message:  &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}},
+		batch:    &core.BatchPersisted{BatchHeader: core.BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}},
+		pin:      &core.Pin{Namespace: "ns1", Hash: fftypes.NewRandB32()},
+		transfer: &core.TokenTransfer{LocalID: fftypes.NewUUID(), Namespace: "ns1"},
+		balance:  &core.TokenBalance{Pool: fftypes.NewUUID(), Namespace: "ns1", Key: "0x1"},
+		sub:      sub,
+		offset:   &core.Offset{Type: core.OffsetTypeSubscription, Name: subID.String(), Current: 5},
+	}
+}
+
+func newTestBackupManager(t *testing.T) (*backupManager, *databasemocks.Plugin) {
+	mdi := &databasemocks.Plugin{}
+	bm, err := NewBackupManager(context.Background(), "ns1", mdi)
+	assert.NoError(t, err)
+	return bm.(*backupManager), mdi
+}
+
+func mockExport(mdi *databasemocks.Plugin, tr *testRecords) {
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&core.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return([]*core.Datatype{tr.datatype}, nil, nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*core.Identity{tr.identity}, nil, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.Anything).Return([]*core.Verifier{tr.verifier}, nil, nil)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*core.FFI{tr.ffi}, nil, nil)
+	mdi.On("GetFFIMethods", mock.Anything, mock.Anything).Return([]*core.FFIMethod{tr.method}, nil, nil)
+	mdi.On("GetFFIEvents", mock.Anything, mock.Anything).Return([]*core.FFIEvent{tr.event}, nil, nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{tr.errorDef}, nil, nil)
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractAPI{tr.api}, nil, nil)
+	mdi.On("GetTokenPools", mock.Anything, mock.Anything).Return([]*core.TokenPool{tr.pool}, nil, nil)
+	mdi.On("GetData", mock.Anything, mock.Anything).Return(core.DataArray{tr.data}, nil, nil)
+	mdi.On("GetBlobs", mock.Anything, mock.Anything).Return([]*core.Blob{tr.blob}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*core.Message{tr.message}, nil, nil)
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*core.BatchPersisted{tr.batch}, nil, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*core.Pin{tr.pin}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*core.TokenTransfer{tr.transfer}, nil, nil)
+	mdi.On("GetTokenBalances", mock.Anything, mock.Anything).Return([]*core.TokenBalance{tr.balance}, nil, nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{tr.sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, tr.sub.ID.String()).Return(tr.offset, nil)
+}
+
+func mockEmpty(mdi *databasemocks.Plugin) {
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return([]*core.Datatype{}, nil, nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*core.Identity{}, nil, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.Anything).Return([]*core.Verifier{}, nil, nil)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*core.FFI{}, nil, nil)
+	mdi.On("GetFFIMethods", mock.Anything, mock.Anything).Return([]*core.FFIMethod{}, nil, nil)
+	mdi.On("GetFFIEvents", mock.Anything, mock.Anything).Return([]*core.FFIEvent{}, nil, nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractAPI{}, nil, nil)
+	mdi.On("GetTokenPools", mock.Anything, mock.Anything).Return([]*core.TokenPool{}, nil, nil)
+	mdi.On("GetData", mock.Anything, mock.Anything).Return(core.DataArray{}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*core.Message{}, nil, nil)
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*core.BatchPersisted{}, nil, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*core.Pin{}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenBalances", mock.Anything, mock.Anything).Return([]*core.TokenBalance{}, nil, nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
+}
+
+func mockRunAsGroup(mdi *databasemocks.Plugin) {
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+}
+
+func exportTestRecords(t *testing.T, tr *testRecords) string {
+	bm, mdi := newTestBackupManager(t)
+	mockExport(mdi, tr)
+	buf := &bytes.Buffer{}
+	err := bm.Export(context.Background(), buf)
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+	return buf.String()
+}
+
+type errWriter struct{}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("pop")
+}
+
+func TestNewBackupManagerMissingDeps(t *testing.T) {
+	_, err := NewBackupManager(context.Background(), "ns1", nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	tr := newTestRecords()
+	exported := exportTestRecords(t, tr)
+
+	lines := strings.Split(strings.TrimSpace(exported), "\n")
+	assert.Len(t, lines, len(collections)+1)
+	assert.JSONEq(t, `{"collection":"namespace","record":{"id":null,"name":"ns1","description":"","type":"","created":null,"fireflyContract":{"active":{"index":0}}}}`, lines[0])
+	assert.Regexp(t, `^\{"collection":"datatypes"`, lines[1])
+	assert.Regexp(t, `^\{"collection":"offsets"`, lines[len(lines)-1])
+	assert.NotContains(t, exported, "shh")
+
+	bm, mdi := newTestBackupManager(t)
+	mockRunAsGroup(mdi)
+	mockEmpty(mdi)
+	mdi.On("UpsertDatatype", mock.Anything, mock.MatchedBy(func(dt *core.Datatype) bool { return dt.ID.Equals(tr.datatype.ID) }), false).Return(nil)
+	mdi.On("UpsertIdentity", mock.Anything, mock.MatchedBy(func(id *core.Identity) bool { return id.ID.Equals(tr.identity.ID) }), database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertVerifier", mock.Anything, mock.MatchedBy(func(v *core.Verifier) bool { return v.Hash.Equals(tr.verifier.Hash) }), database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertFFI", mock.Anything, mock.MatchedBy(func(ffi *core.FFI) bool { return ffi.ID.Equals(tr.ffi.ID) })).Return(nil)
+	mdi.On("UpsertFFIMethod", mock.Anything, mock.MatchedBy(func(m *core.FFIMethod) bool { return m.ID.Equals(tr.method.ID) })).Return(nil)
+	mdi.On("UpsertFFIEvent", mock.Anything, mock.MatchedBy(func(e *core.FFIEvent) bool { return e.ID.Equals(tr.event.ID) })).Return(nil)
+	mdi.On("UpsertFFIError", mock.Anything, mock.MatchedBy(func(e *core.FFIError) bool { return e.ID.Equals(tr.errorDef.ID) })).Return(nil)
+	mdi.On("UpsertContractAPI", mock.Anything, mock.MatchedBy(func(api *core.ContractAPI) bool { return api.ID.Equals(tr.api.ID) })).Return(nil)
+	mdi.On("UpsertTokenPool", mock.Anything, mock.MatchedBy(func(pool *core.TokenPool) bool { return pool.ID.Equals(tr.pool.ID) })).Return(nil)
+	mdi.On("InsertDataArray", mock.Anything, mock.MatchedBy(func(data core.DataArray) bool {
+		return data[0].ID.Equals(tr.data.ID) && data[0].ValueSize == int64(len(`"value1"`))
+	})).Return(nil)
+	mdi.On("InsertBlob", mock.Anything, mock.MatchedBy(func(blob *core.Blob) bool { return blob.PayloadRef == "ref1" })).Return(nil)
+	mdi.On("InsertMessages", mock.Anything, mock.MatchedBy(func(msgs []*core.Message) bool { return msgs[0].Header.ID.Equals(tr.message.Header.ID) })).Return(nil)
+	mdi.On("UpsertBatch", mock.Anything, mock.MatchedBy(func(batch *core.BatchPersisted) bool { return batch.ID.Equals(tr.batch.ID) })).Return(nil)
+	mdi.On("InsertPins", mock.Anything, mock.MatchedBy(func(pins []*core.Pin) bool { return pins[0].Hash.Equals(tr.pin.Hash) })).Return(nil)
+	mdi.On("UpsertTokenTransfer", mock.Anything, mock.MatchedBy(func(transfer *core.TokenTransfer) bool { return transfer.LocalID.Equals(tr.transfer.LocalID) })).Return(nil)
+	mdi.On("InsertTokenBalance", mock.Anything, mock.MatchedBy(func(balance *core.TokenBalance) bool { return balance.Key == "0x1" })).Return(nil)
+	mdi.On("UpsertSubscription", mock.Anything, mock.MatchedBy(func(sub *core.Subscription) bool {
+		return sub.ID.Equals(tr.sub.ID) && sub.Options.TransportOptions()["secret"] == nil
+	}), false).Return(nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool { return offset.Current == 5 }), false).Return(nil)
+
+	result, err := bm.Import(context.Background(), strings.NewReader(exported))
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", result.Namespace)
+	assert.Len(t, result.Records, len(collections))
+	for _, c := range collections {
+		assert.Equal(t, int64(1), result.Records[c.name], c.name)
+	}
+
+	mdi.AssertExpectations(t)
+}
+
+func TestExportPaged(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	bm.pageSize = 1
+	tr := newTestRecords()
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+	mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.AndFilter) bool {
+		fi, _ := f.Finalize()
+		return fi.After == nil && fi.Skip == 0 && fi.Limit == 1 && fi.Sort[0].Field == "sequence" && !fi.Sort[0].Descending
+	})).Return([]*core.Message{tr.message}, &database.FilterResult{Next: database.EncodeCursor(10)}, nil).Once()
+	mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.AndFilter) bool {
+		fi, _ := f.Finalize()
+		return fi.After != nil && *fi.After == 10 && fi.Skip == 0 && fi.Limit == 1
+	})).Return([]*core.Message{tr.message}, &database.FilterResult{}, nil).Once()
+	mockEmpty(mdi)
+
+	buf := &bytes.Buffer{}
+	err := bm.Export(context.Background(), buf)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, `^\{"collection":"namespace","record":\{"id":null,"name":"ns1"`, lines[0])
+	assert.Regexp(t, `^\{"collection":"messages"`, lines[1])
+	assert.Regexp(t, `^\{"collection":"messages"`, lines[2])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestExportPagedWhileInserting(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	bm.pageSize = 2
+	msg1 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	msg2 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	msg3 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	inserted := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+
+	// The messages have sequences 1-3 when the export starts, and a fourth message is inserted after the
+	// first page has been read. The second page follows the sequence of the last message that was read,
+	// rather than skipping a number of rows, so every message is exported exactly once.
+	messages := map[int64][]*core.Message{
+		0: {msg1, msg2},
+		2: {msg3, inserted},
+	}
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(func(ctx context.Context, f database.Filter) []*core.Message {
+		fi, _ := f.Finalize()
+		after := int64(0)
+		if fi.After != nil {
+			after = *fi.After
+		}
+		assert.Zero(t, fi.Skip)
+		return messages[after]
+	}, func(ctx context.Context, f database.Filter) *database.FilterResult {
+		fi, _ := f.Finalize()
+		if fi.After == nil {
+			return &database.FilterResult{Next: database.EncodeCursor(2)}
+		}
+		return &database.FilterResult{}
+	}, nil).Twice()
+	mockEmpty(mdi)
+
+	buf := &bytes.Buffer{}
+	err := bm.Export(context.Background(), buf)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 5)
+	for i, msg := range []*core.Message{msg1, msg2, msg3, inserted} {
+		assert.Contains(t, lines[i+1], msg.Header.ID.String())
+	}
+
+	mdi.AssertExpectations(t)
+}
+
+func TestExportGetNamespaceFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, fmt.Errorf("pop"))
+	err := bm.Export(context.Background(), &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportWriteHeaderFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+	err := bm.Export(context.Background(), &errWriter{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportQueryFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := bm.Export(context.Background(), &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportNotNamespacedFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	tr := newTestRecords()
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{tr.sub}, nil, nil)
+	mockEmpty(mdi)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, tr.sub.ID.String()).Return(nil, fmt.Errorf("pop"))
+	err := bm.Export(context.Background(), &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportMissingHeader(t *testing.T) {
+	bm, _ := newTestBackupManager(t)
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"messages","record":{}}`))
+	assert.Regexp(t, "FF10455", err)
+	_, err = bm.Import(context.Background(), strings.NewReader(``))
+	assert.Regexp(t, "FF10455", err)
+}
+
+func TestImportBadHeader(t *testing.T) {
+	bm, _ := newTestBackupManager(t)
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":[]}`))
+	assert.Regexp(t, "FF10456.*0", err)
+}
+
+func TestImportWrongNamespace(t *testing.T) {
+	bm, _ := newTestBackupManager(t)
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":{"name":"ns2"}}`))
+	assert.Regexp(t, "FF10454.*ns2.*ns1", err)
+}
+
+func TestImportNotEmpty(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mockRunAsGroup(mdi)
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return([]*core.Datatype{{}}, nil, nil)
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":{"name":"ns1"}}`))
+	assert.Regexp(t, "FF10453.*datatypes", err)
+}
+
+func TestImportCheckEmptyFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mockRunAsGroup(mdi)
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":{"name":"ns1"}}`))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportBadRecord(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mockRunAsGroup(mdi)
+	mockEmpty(mdi)
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":{"name":"ns1"}}
+!bad`))
+	assert.Regexp(t, "FF10456.*1", err)
+}
+
+func TestImportUnknownCollection(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mockRunAsGroup(mdi)
+	mockEmpty(mdi)
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":{"name":"ns1"}}
+{"collection":"events","record":{}}`))
+	assert.Regexp(t, "FF10457.*1.*events", err)
+}
+
+func TestImportRecordWrongNamespace(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mockRunAsGroup(mdi)
+	mockEmpty(mdi)
+	mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(nil)
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":{"name":"ns1"}}
+{"collection":"datatypes","record":{"namespace":"ns1"}}
+{"collection":"datatypes","record":{"namespace":"ns2"}}`))
+	assert.Regexp(t, "FF10454.*2.*ns2.*ns1", err)
+}
+
+func TestImportRestoreFail(t *testing.T) {
+	bm, mdi := newTestBackupManager(t)
+	mockRunAsGroup(mdi)
+	mockEmpty(mdi)
+	mdi.On("InsertBlob", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := bm.Import(context.Background(), strings.NewReader(`{"collection":"namespace","record":{"name":"ns1"}}
+{"collection":"blobs","record":{"hash":"`+fftypes.NewRandB32().String()+`"}}`))
+	assert.EqualError(t, err, "pop")
+}
@@ -0,0 +1,112 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confirmations
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/log"
+)
+
+// Tracker holds back blockchain events until a required number of blocks have been
+// mined on top of the block that contains them, and dispatches them in the order
+// they were added once they are confirmed.
+//
+// The height of the chain is learned from the block numbers of the events and
+// receipts observed by the plugin, and from polling the head block while events are
+// pending. Pending events are held in memory only, so the plugin must not acknowledge
+// a batch of events to the blockchain connector until all of them have been
+// dispatched - any that are still pending when FireFly stops are then redelivered.
+//
+// The zero value is ready to use. A Tracker is not safe for concurrent use, and is
+// intended to be driven from the single event loop of a blockchain plugin.
+type Tracker struct {
+	head    int64
+	pending []*pendingEvent
+}
+
+type pendingEvent struct {
+	protocolID    string
+	blockNumber   int64
+	confirmations int
+	dispatch      func() error
+}
+
+// Add queues an event for dispatch once the required number of confirmations have been observed,
+// and then dispatches any events that are now confirmed (which may include this one).
+// An event that is already pending with the same protocol ID is replaced, so that redelivery
+// of an event by the connector does not result in duplicate dispatch.
+func (t *Tracker) Add(ctx context.Context, protocolID string, blockNumber int64, confirmations int, dispatch func() error) error {
+	pe := &pendingEvent{
+		protocolID:    protocolID,
+		blockNumber:   blockNumber,
+		confirmations: confirmations,
+		dispatch:      dispatch,
+	}
+	replaced := false
+	for i, existing := range t.pending {
+		if existing.protocolID == protocolID {
+			t.pending[i] = pe
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		t.pending = append(t.pending, pe)
+	}
+	return t.BlockObserved(ctx, blockNumber)
+}
+
+// BlockObserved records that the chain has reached at least the given block number, and
+// dispatches all pending events that now have enough confirmations.
+// If a dispatch fails, the event remains pending and the error is returned.
+func (t *Tracker) BlockObserved(ctx context.Context, blockNumber int64) error {
+	if blockNumber > t.head {
+		t.head = blockNumber
+	}
+	remaining := make([]*pendingEvent, 0, len(t.pending))
+	for i, pe := range t.pending {
+		if t.head-pe.blockNumber < int64(pe.confirmations) {
+			remaining = append(remaining, pe)
+			continue
+		}
+		log.L(ctx).Debugf("Dispatching event %s from block %d with %d confirmations (head=%d)", pe.protocolID, pe.blockNumber, pe.confirmations, t.head)
+		if err := pe.dispatch(); err != nil {
+			t.pending = append(remaining, t.pending[i:]...)
+			return err
+		}
+	}
+	t.pending = remaining
+	return nil
+}
+
+// Remove discards a pending event that has been removed from the chain before it was confirmed,
+// returning true if the event was found
+func (t *Tracker) Remove(protocolID string) bool {
+	for i, pe := range t.pending {
+		if pe.protocolID == protocolID {
+			t.pending = append(t.pending[:i], t.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the number of events waiting for confirmation
+func (t *Tracker) Pending() int {
+	return len(t.pending)
+}
@@ -0,0 +1,123 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confirmations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recorder(dispatched *[]string, id string) func() error {
+	return func() error {
+		*dispatched = append(*dispatched, id)
+		return nil
+	}
+}
+
+func TestAddNoConfirmations(t *testing.T) {
+	tr := &Tracker{}
+	ctx := context.Background()
+	var dispatched []string
+
+	err := tr.Add(ctx, "000000000010/000000/000000", 10, 0, recorder(&dispatched, "a"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, dispatched)
+	assert.Equal(t, 0, tr.Pending())
+}
+
+func TestAddWaitsForConfirmations(t *testing.T) {
+	tr := &Tracker{}
+	ctx := context.Background()
+	var dispatched []string
+
+	err := tr.Add(ctx, "000000000010/000000/000000", 10, 3, recorder(&dispatched, "a"))
+	assert.NoError(t, err)
+	err = tr.Add(ctx, "000000000011/000000/000000", 11, 3, recorder(&dispatched, "b"))
+	assert.NoError(t, err)
+	err = tr.Add(ctx, "000000000011/000000/000001", 11, 0, recorder(&dispatched, "c"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c"}, dispatched)
+	assert.Equal(t, 2, tr.Pending())
+
+	err = tr.BlockObserved(ctx, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c"}, dispatched)
+
+	err = tr.BlockObserved(ctx, 14)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "a", "b"}, dispatched)
+	assert.Equal(t, 0, tr.Pending())
+}
+
+func TestAddReplacesRedelivered(t *testing.T) {
+	tr := &Tracker{}
+	ctx := context.Background()
+	var dispatched []string
+
+	err := tr.Add(ctx, "000000000010/000000/000000", 10, 1, recorder(&dispatched, "a"))
+	assert.NoError(t, err)
+	err = tr.Add(ctx, "000000000010/000000/000000", 10, 1, recorder(&dispatched, "a2"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tr.Pending())
+
+	err = tr.BlockObserved(ctx, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a2"}, dispatched)
+}
+
+func TestBlockObservedDispatchFail(t *testing.T) {
+	tr := &Tracker{}
+	ctx := context.Background()
+	var dispatched []string
+
+	err := tr.Add(ctx, "000000000010/000000/000000", 10, 1, recorder(&dispatched, "a"))
+	assert.NoError(t, err)
+	err = tr.Add(ctx, "000000000010/000000/000001", 10, 1, func() error { return fmt.Errorf("pop") })
+	assert.NoError(t, err)
+	err = tr.Add(ctx, "000000000010/000000/000002", 10, 1, recorder(&dispatched, "c"))
+	assert.NoError(t, err)
+
+	err = tr.BlockObserved(ctx, 11)
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, []string{"a"}, dispatched)
+	assert.Equal(t, 2, tr.Pending())
+
+	// A lower block does not move the head backwards
+	err = tr.BlockObserved(ctx, 5)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRemove(t *testing.T) {
+	tr := &Tracker{}
+	ctx := context.Background()
+	var dispatched []string
+
+	err := tr.Add(ctx, "000000000010/000000/000000", 10, 2, recorder(&dispatched, "a"))
+	assert.NoError(t, err)
+	err = tr.Add(ctx, "000000000011/000000/000000", 11, 2, recorder(&dispatched, "b"))
+	assert.NoError(t, err)
+
+	assert.True(t, tr.Remove("000000000010/000000/000000"))
+	assert.False(t, tr.Remove("000000000010/000000/000000"))
+
+	err = tr.BlockObserved(ctx, 13)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, dispatched)
+}
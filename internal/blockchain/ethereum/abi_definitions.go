@@ -18,7 +18,8 @@ package ethereum
 
 import "github.com/hyperledger/firefly-signer/pkg/abi"
 
-var batchPinMethodABI = &abi.Entry{
+// BatchPinMethodABI is the pinBatch method on the FireFly contract
+var BatchPinMethodABI = &abi.Entry{
 	Name: "pinBatch",
 	Type: "function",
 	Inputs: abi.ParameterArray{
@@ -50,7 +51,8 @@ var batchPinMethodABI = &abi.Entry{
 	},
 }
 
-var batchPinEventABI = &abi.Entry{
+// BatchPinEventABI is the BatchPin event emitted by the FireFly contract
+var BatchPinEventABI = &abi.Entry{
 	Name: "BatchPin",
 	Type: "event",
 	Inputs: abi.ParameterArray{
@@ -99,7 +101,8 @@ var batchPinEventABI = &abi.Entry{
 	},
 }
 
-var networkVersionMethodABI = &abi.Entry{
+// NetworkVersionMethodABI is the networkVersion method on the FireFly contract
+var NetworkVersionMethodABI = &abi.Entry{
 	Name:            "networkVersion",
 	Type:            "function",
 	StateMutability: "pure",
@@ -23,11 +23,13 @@ import (
 )
 
 const (
-	defaultBatchSize    = 50
-	defaultBatchTimeout = 500
-	defaultPrefixShort  = "fly"
-	defaultPrefixLong   = "firefly"
-	defaultFromBlock    = "0"
+	defaultBatchSize           = 50
+	defaultBatchTimeout        = 500
+	defaultConfirmations       = 0
+	defaultHeadPollingInterval = "5s"
+	defaultPrefixShort         = "fly"
+	defaultPrefixLong          = "firefly"
+	defaultFromBlock           = "0"
 
 	defaultAddressResolverMethod        = "GET"
 	defaultAddressResolverResponseField = "address"
@@ -45,6 +47,10 @@ const (
 	EthconnectConfigBatchSize = "batchSize"
 	// EthconnectConfigBatchTimeout is the batch timeout to configure on event streams, when auto-defining them
 	EthconnectConfigBatchTimeout = "batchTimeout"
+	// EthconnectConfigConfirmations is the number of blocks that must be mined on top of an event before it is dispatched
+	EthconnectConfigConfirmations = "confirmations"
+	// EthconnectConfigHeadPollingInterval is how often to query the head block while events are waiting for confirmations
+	EthconnectConfigHeadPollingInterval = "headPollingInterval"
 	// EthconnectPrefixShort is used in the query string in requests to ethconnect
 	EthconnectPrefixShort = "prefixShort"
 	// EthconnectPrefixLong is used in HTTP headers in requests to ethconnect
@@ -88,6 +94,8 @@ func (e *Ethereum) InitConfig(config config.Section) {
 	e.ethconnectConf.AddKnownKey(EthconnectConfigTopic)
 	e.ethconnectConf.AddKnownKey(EthconnectConfigBatchSize, defaultBatchSize)
 	e.ethconnectConf.AddKnownKey(EthconnectConfigBatchTimeout, defaultBatchTimeout)
+	e.ethconnectConf.AddKnownKey(EthconnectConfigConfirmations, defaultConfirmations)
+	e.ethconnectConf.AddKnownKey(EthconnectConfigHeadPollingInterval, defaultHeadPollingInterval)
 	e.ethconnectConf.AddKnownKey(EthconnectPrefixShort, defaultPrefixShort)
 	e.ethconnectConf.AddKnownKey(EthconnectPrefixLong, defaultPrefixLong)
 	e.ethconnectConf.AddKnownKey(EthconnectConfigInstanceDeprecated)
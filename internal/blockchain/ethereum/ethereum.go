@@ -25,6 +25,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -34,6 +35,8 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly-common/pkg/wsclient"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/blockchain/confirmations"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -73,6 +76,10 @@ type Ethereum struct {
 	}
 	wsconn           wsclient.WSClient
 	closed           chan struct{}
+	confirmations    int
+	headPolling      time.Duration
+	tracker          confirmations.Tracker
+	unackedBatch     bool
 	addressResolver  *addressResolver
 	metrics          metrics.Manager
 	ethconnectConf   config.Section
@@ -117,6 +124,24 @@ func (cb *callbacks) BlockchainEvent(event *blockchain.EventWithSubscription) er
 	return nil
 }
 
+func (cb *callbacks) BlockchainEventRemoved(event *blockchain.EventWithSubscription) error {
+	for _, cb := range cb.listeners {
+		if err := cb.BlockchainEventRemoved(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cb *callbacks) ContractListenerConfirmations(subscription string) (*int, error) {
+	for _, cb := range cb.listeners {
+		if n, err := cb.ContractListenerConfirmations(subscription); err != nil || n != nil {
+			return n, err
+		}
+	}
+	return nil, nil
+}
+
 type eventStreamWebsocket struct {
 	Topic string `json:"topic"`
 }
@@ -132,6 +157,7 @@ type ethWSCommandPayload struct {
 
 type ethError struct {
 	Error string `json:"error,omitempty"`
+	Data  string `json:"data,omitempty"` // the raw revert data, if the connector reports it
 }
 
 type Location struct {
@@ -221,6 +247,11 @@ func (e *Ethereum) Init(ctx context.Context, config config.Section, metrics metr
 	}
 	e.prefixShort = ethconnectConf.GetString(EthconnectPrefixShort)
 	e.prefixLong = ethconnectConf.GetString(EthconnectPrefixLong)
+	e.confirmations = ethconnectConf.GetInt(EthconnectConfigConfirmations)
+	if e.confirmations < 0 {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidConfirmations, e.confirmations)
+	}
+	e.headPolling = ethconnectConf.GetDuration(EthconnectConfigHeadPollingInterval)
 
 	wsConfig := wsclient.GenerateConfig(ethconnectConf)
 	if wsConfig.WSKeyPath == "" {
@@ -308,7 +339,7 @@ func (e *Ethereum) ConfigureContract(ctx context.Context, contracts *core.FireFl
 		return err
 	}
 
-	sub, err := e.streams.ensureFireFlySubscription(ctx, address, fromBlock, e.streamID, batchPinEventABI)
+	sub, err := e.streams.ensureFireFlySubscription(ctx, address, fromBlock, e.streamID, BatchPinEventABI)
 	if err == nil {
 		var version int
 		version, err = e.getNetworkVersion(ctx, address)
@@ -399,11 +430,12 @@ func (e *Ethereum) parseBlockchainEvent(ctx context.Context, msgJSON fftypes.JSO
 	}
 
 	delete(msgJSON, "data")
+	msgJSON[blockchain.EventInfoBlockNumber] = strconv.FormatInt(blockNumber, 10)
 	return &blockchain.Event{
 		BlockchainTXID: sTransactionHash,
 		Source:         e.Name(),
 		Name:           name,
-		ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", blockNumber, txIndex, logIndex),
+		ProtocolID:     eventProtocolID(blockNumber, txIndex, logIndex),
 		Output:         dataJSON,
 		Info:           msgJSON,
 		Timestamp:      timestamp,
@@ -443,7 +475,9 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 	// Check if this is actually an operator action
 	if strings.HasPrefix(nsOrAction, blockchain.FireFlyActionPrefix) {
 		action := nsOrAction[len(blockchain.FireFlyActionPrefix):]
-		return e.callbacks.BlockchainNetworkAction(action, event, verifier)
+		return e.tracker.Add(ctx, event.ProtocolID, msgJSON.GetInt64("blockNumber"), e.confirmations, func() error {
+			return e.callbacks.BlockchainNetworkAction(action, event, verifier)
+		})
 	}
 
 	hexUUIDs, err := hex.DecodeString(strings.TrimPrefix(sUUIDs, "0x"))
@@ -485,18 +519,47 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 	}
 
 	// If there's an error dispatching the event, we must return the error and shutdown
-	return e.callbacks.BatchPinComplete(batch, verifier)
+	return e.tracker.Add(ctx, event.ProtocolID, msgJSON.GetInt64("blockNumber"), e.confirmations, func() error {
+		return e.callbacks.BatchPinComplete(batch, verifier)
+	})
 }
 
 func (e *Ethereum) handleContractEvent(ctx context.Context, msgJSON fftypes.JSONObject) (err error) {
 	event := e.parseBlockchainEvent(ctx, msgJSON)
-	if event != nil {
-		err = e.callbacks.BlockchainEvent(&blockchain.EventWithSubscription{
+	if event == nil {
+		return nil // move on
+	}
+	subID := msgJSON.GetString("subId")
+	required, err := e.callbacks.ContractListenerConfirmations(subID)
+	if err != nil {
+		return err
+	}
+	if required == nil {
+		required = &e.confirmations
+	}
+	return e.tracker.Add(ctx, event.ProtocolID, msgJSON.GetInt64("blockNumber"), *required, func() error {
+		return e.callbacks.BlockchainEvent(&blockchain.EventWithSubscription{
 			Event:        *event,
-			Subscription: msgJSON.GetString("subId"),
+			Subscription: subID,
 		})
+	})
+}
+
+func (e *Ethereum) handleRemovedEvent(ctx context.Context, msgJSON fftypes.JSONObject) error {
+	protocolID := eventProtocolID(msgJSON.GetInt64("blockNumber"), msgJSON.GetInt64("transactionIndex"), msgJSON.GetInt64("logIndex"))
+	if e.tracker.Remove(protocolID) {
+		log.L(ctx).Infof("Discarded unconfirmed event %s that was removed by a chain re-organization", protocolID)
+		return nil
 	}
-	return err
+	event := e.parseBlockchainEvent(ctx, msgJSON)
+	if event == nil {
+		return nil // move on
+	}
+	log.L(ctx).Warnf("Event %s was removed by a chain re-organization after it was confirmed", protocolID)
+	return e.callbacks.BlockchainEventRemoved(&blockchain.EventWithSubscription{
+		Event:        *event,
+		Subscription: msgJSON.GetString("subId"),
+	})
 }
 
 func (e *Ethereum) handleReceipt(ctx context.Context, reply fftypes.JSONObject) {
@@ -515,10 +578,25 @@ func (e *Ethereum) handleReceipt(ctx context.Context, reply fftypes.JSONObject)
 	if replyType != "TransactionSuccess" {
 		updateType = core.OpStatusFailed
 	}
+	if updateType == core.OpStatusFailed {
+		// Only the built-in errors can be decoded here. The error definitions of the interface are resolved from the
+		// operation when the update is processed, so a custom error replaces this with DecodeOperationRevert
+		if revert := e.DecodeOperationRevert(ctx, reply, nil); revert != nil {
+			reply["revert"] = revert
+		}
+	}
+	if contractAddress := reply.GetString("contractAddress"); contractAddress != "" {
+		// Contract deployments report the new contract in the same format as a contract location
+		reply["contractLocation"] = &Location{Address: contractAddress}
+	}
 	l.Infof("Ethconnect '%s' reply: request=%s tx=%s message=%s", replyType, requestID, txHash, message)
 	e.callbacks.BlockchainOpUpdate(e, requestID, updateType, txHash, message, reply)
 }
 
+func eventProtocolID(blockNumber, txIndex, logIndex int64) string {
+	return fmt.Sprintf("%.12d/%.6d/%.6d", blockNumber, txIndex, logIndex)
+}
+
 func (e *Ethereum) buildEventLocationString(msgJSON fftypes.JSONObject) string {
 	return fmt.Sprintf("address=%s", msgJSON.GetString("address"))
 }
@@ -545,7 +623,11 @@ func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{
 		fireflySub := e.fireflyContract.subscription
 		e.fireflyContract.mux.Unlock()
 
-		if sub == fireflySub {
+		if msgJSON.GetBool("removed") {
+			if err := e.handleRemovedEvent(ctx1, msgJSON); err != nil {
+				return err
+			}
+		} else if sub == fireflySub {
 			// Matches the active FireFly BatchPin subscription
 			switch signature {
 			case broadcastBatchEventSignature:
@@ -573,11 +655,20 @@ func (e *Ethereum) eventLoop() {
 	l := log.L(e.ctx).WithField("role", "event-loop")
 	ctx := log.WithLogger(e.ctx, l)
 	ack, _ := json.Marshal(map[string]string{"type": "ack", "topic": e.topic})
+	var headPoll <-chan time.Time
+	if e.headPolling > 0 {
+		ticker := time.NewTicker(e.headPolling)
+		defer ticker.Stop()
+		headPoll = ticker.C
+	}
 	for {
+		var err error
 		select {
 		case <-ctx.Done():
 			l.Debugf("Event loop exiting (context cancelled)")
 			return
+		case <-headPoll:
+			err = e.pollHeadBlock(ctx)
 		case msgBytes, ok := <-e.wsconn.Receive():
 			if !ok {
 				l.Debugf("Event loop exiting (receive channel closed)")
@@ -585,31 +676,76 @@ func (e *Ethereum) eventLoop() {
 			}
 
 			var msgParsed interface{}
-			err := json.Unmarshal(msgBytes, &msgParsed)
-			if err != nil {
+			if err := json.Unmarshal(msgBytes, &msgParsed); err != nil {
 				l.Errorf("Message cannot be parsed as JSON: %s\n%s", err, string(msgBytes))
 				continue // Swallow this and move on
 			}
 			switch msgTyped := msgParsed.(type) {
 			case []interface{}:
 				err = e.handleMessageBatch(ctx, msgTyped)
-				if err == nil {
-					err = e.wsconn.Send(ctx, ack)
-				}
+				e.unackedBatch = err == nil
 			case map[string]interface{}:
-				e.handleReceipt(ctx, fftypes.JSONObject(msgTyped))
+				receipt := fftypes.JSONObject(msgTyped)
+				e.handleReceipt(ctx, receipt)
+				if receipt.GetString("blockNumber") != "" {
+					// Receipts tell us the chain has progressed, which might confirm pending events
+					err = e.tracker.BlockObserved(ctx, receipt.GetInt64("blockNumber"))
+				}
 			default:
 				l.Errorf("Message unexpected: %+v", msgTyped)
 				continue
 			}
+		}
 
-			// Send the ack - only fails if shutting down
-			if err != nil {
-				l.Errorf("Event loop exiting: %s", err)
-				return
-			}
+		// The batch is only acked once all of its events have been dispatched, as events waiting
+		// for confirmations are held in memory, and ethconnect redelivers an unacked batch on restart
+		if err == nil && e.unackedBatch && e.tracker.Pending() == 0 {
+			err = e.wsconn.Send(ctx, ack)
+			e.unackedBatch = false
 		}
+
+		// Only fails if shutting down
+		if err != nil {
+			l.Errorf("Event loop exiting: %s", err)
+			return
+		}
+	}
+}
+
+// pollHeadBlock queries the head block while events are waiting for confirmations, so that
+// they are dispatched even when no other events or receipts are arriving from the chain
+func (e *Ethereum) pollHeadBlock(ctx context.Context) error {
+	if e.tracker.Pending() == 0 {
+		return nil
+	}
+	blockNumber, err := e.queryBlockNumber(ctx)
+	if err != nil {
+		log.L(ctx).Warnf("Failed to query head block: %s", err)
+		return nil // try again on the next poll
+	}
+	return e.tracker.BlockObserved(ctx, blockNumber)
+}
+
+func (e *Ethereum) queryBlockNumber(ctx context.Context) (int64, error) {
+	var resErr ethError
+	var result struct {
+		Result ethtypes.HexInteger `json:"result"`
+	}
+	res, err := e.client.R().
+		SetContext(ctx).
+		SetBody(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "eth_blockNumber",
+			"params":  []interface{}{},
+		}).
+		SetError(&resErr).
+		SetResult(&result).
+		Post("/")
+	if err != nil || !res.IsSuccess() {
+		return -1, wrapError(ctx, &resErr, res, err)
 	}
+	return result.Result.BigInt().Int64(), nil
 }
 
 func validateEthAddress(ctx context.Context, key string) (string, error) {
@@ -667,7 +803,7 @@ func (e *Ethereum) buildEthconnectRequestBody(ctx context.Context, messageType,
 	return body, nil
 }
 
-func (e *Ethereum) invokeContractMethod(ctx context.Context, address, signingKey string, abi *abi.Entry, requestID string, input []interface{}, options map[string]interface{}) error {
+func (e *Ethereum) invokeContractMethod(ctx context.Context, address, signingKey string, abi *abi.Entry, requestID string, input []interface{}, errors []*core.FFIError, options map[string]interface{}) error {
 	if e.metrics.IsMetricsEnabled() {
 		e.metrics.BlockchainTransaction(address, abi.Name)
 	}
@@ -687,12 +823,12 @@ func (e *Ethereum) invokeContractMethod(ctx context.Context, address, signingKey
 		SetError(&resErr).
 		Post("/")
 	if err != nil || !res.IsSuccess() {
-		return wrapError(ctx, &resErr, res, err)
+		return e.RevertError(ctx, wrapError(ctx, &resErr, res, err), errors, resErr.Data)
 	}
 	return nil
 }
 
-func (e *Ethereum) queryContractMethod(ctx context.Context, address string, abi *abi.Entry, input []interface{}, options map[string]interface{}) (*resty.Response, error) {
+func (e *Ethereum) queryContractMethod(ctx context.Context, address string, abi *abi.Entry, input []interface{}, errors []*core.FFIError, options map[string]interface{}) (*resty.Response, error) {
 	if e.metrics.IsMetricsEnabled() {
 		e.metrics.BlockchainQuery(address, abi.Name)
 	}
@@ -708,7 +844,7 @@ func (e *Ethereum) queryContractMethod(ctx context.Context, address string, abi
 		SetError(&resErr).
 		Post("/")
 	if err != nil || !res.IsSuccess() {
-		return res, wrapError(ctx, &resErr, res, err)
+		return res, e.RevertError(ctx, wrapError(ctx, &resErr, res, err), errors, resErr.Data)
 	}
 	return res, nil
 }
@@ -731,7 +867,7 @@ func (e *Ethereum) SubmitBatchPin(ctx context.Context, nsOpID string, signingKey
 	e.fireflyContract.mux.Lock()
 	address := e.fireflyContract.address
 	e.fireflyContract.mux.Unlock()
-	return e.invokeContractMethod(ctx, address, signingKey, batchPinMethodABI, nsOpID, input, nil)
+	return e.invokeContractMethod(ctx, address, signingKey, BatchPinMethodABI, nsOpID, input, nil, nil)
 }
 
 func (e *Ethereum) SubmitNetworkAction(ctx context.Context, nsOpID string, signingKey string, action core.NetworkActionType) error {
@@ -745,10 +881,10 @@ func (e *Ethereum) SubmitNetworkAction(ctx context.Context, nsOpID string, signi
 	e.fireflyContract.mux.Lock()
 	address := e.fireflyContract.address
 	e.fireflyContract.mux.Unlock()
-	return e.invokeContractMethod(ctx, address, signingKey, batchPinMethodABI, nsOpID, input, nil)
+	return e.invokeContractMethod(ctx, address, signingKey, BatchPinMethodABI, nsOpID, input, nil, nil)
 }
 
-func (e *Ethereum) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) error {
+func (e *Ethereum) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) error {
 	ethereumLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
 		return err
@@ -757,10 +893,49 @@ func (e *Ethereum) InvokeContract(ctx context.Context, nsOpID string, signingKey
 	if err != nil {
 		return err
 	}
-	return e.invokeContractMethod(ctx, ethereumLocation.Address, signingKey, abi, nsOpID, orderedInput, options)
+	return e.invokeContractMethod(ctx, ethereumLocation.Address, signingKey, abi, nsOpID, orderedInput, errors, options)
+}
+
+func (e *Ethereum) DeployContract(ctx context.Context, nsOpID string, signingKey string, definition *fftypes.JSONAny, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) error {
+	if contract == nil {
+		return i18n.NewError(ctx, coremsgs.MsgContractDeployMissingContract)
+	}
+	headers := EthconnectMessageHeaders{
+		Type: "DeployContract",
+		ID:   nsOpID,
+	}
+	body := map[string]interface{}{
+		"headers":    headers,
+		"from":       signingKey,
+		"params":     input,
+		"definition": definition,
+		"contract":   contract,
+	}
+	for k, v := range options {
+		// Set the new field if it's not already set. Do not allow overriding of existing fields
+		if _, ok := body[k]; !ok {
+			body[k] = v
+		} else {
+			return i18n.NewError(ctx, coremsgs.MsgOverrideExistingFieldCustomOption, k)
+		}
+	}
+	client := e.fftmClient
+	if client == nil {
+		client = e.client
+	}
+	var resErr ethError
+	res, err := client.R().
+		SetContext(ctx).
+		SetBody(body).
+		SetError(&resErr).
+		Post("/")
+	if err != nil || !res.IsSuccess() {
+		return wrapError(ctx, &resErr, res, err)
+	}
+	return nil
 }
 
-func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) (interface{}, error) {
+func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) (interface{}, error) {
 	ethereumLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
 		return nil, err
@@ -769,7 +944,7 @@ func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny,
 	if err != nil {
 		return nil, err
 	}
-	res, err := e.queryContractMethod(ctx, ethereumLocation.Address, abi, orderedInput, options)
+	res, err := e.queryContractMethod(ctx, ethereumLocation.Address, abi, orderedInput, errors, options)
 	if err != nil || !res.IsSuccess() {
 		return nil, err
 	}
@@ -812,13 +987,23 @@ func (e *Ethereum) AddContractListener(ctx context.Context, listener *core.Contr
 	if err != nil {
 		return err
 	}
-	abi, err := e.FFIEventDefinitionToABI(ctx, &listener.Event.FFIEventDefinition)
-	if err != nil {
-		return i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+	events := listener.AllEvents()
+	filters := make([]*eventFilter, len(events))
+	for i, event := range events {
+		abi, err := e.FFIEventDefinitionToABI(ctx, &event.FFIEventDefinition)
+		if err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+		}
+		filters[i] = &eventFilter{Event: abi}
+		if len(listener.Options.Filters) > 0 {
+			if filters[i].Topics, err = EventFilterTopics(ctx, abi, listener.Options.Filters); err != nil {
+				return err
+			}
+		}
 	}
 
 	subName := fmt.Sprintf("ff-sub-%s", listener.ID)
-	result, err := e.streams.createSubscription(ctx, location, e.streamID, subName, listener.Options.FirstEvent, abi)
+	result, err := e.streams.createSubscription(ctx, location, e.streamID, subName, listener.Options.FirstEvent, filters)
 	if err != nil {
 		return err
 	}
@@ -1044,6 +1229,16 @@ func (e *Ethereum) convertABIToFFI(ctx context.Context, ns, name, version, descr
 		ffi.Events[i] = event
 		i++
 	}
+	for _, entry := range *abi {
+		if entry.Type != "error" {
+			continue
+		}
+		errorDef, err := e.convertABIErrorToFFIError(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+		ffi.Errors = append(ffi.Errors, errorDef)
+	}
 	return ffi, nil
 }
 
@@ -1119,6 +1314,28 @@ func (e *Ethereum) convertABIEventToFFIEvent(ctx context.Context, abiEvent *abi.
 	}, nil
 }
 
+func (e *Ethereum) convertABIErrorToFFIError(ctx context.Context, abiError *abi.Entry) (*core.FFIError, error) {
+	params := make([]*core.FFIParam, len(abiError.Inputs))
+	for i, input := range abiError.Inputs {
+		typeComponent, err := input.TypeComponentTreeCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		schema := e.getSchemaForABIInput(ctx, typeComponent)
+		param := &core.FFIParam{
+			Name:   input.Name,
+			Schema: fftypes.JSONAnyPtr(schema.ToJSON()),
+		}
+		params[i] = param
+	}
+	return &core.FFIError{
+		FFIErrorDefinition: core.FFIErrorDefinition{
+			Name:   abiError.Name,
+			Params: params,
+		},
+	}, nil
+}
+
 func (e *Ethereum) getSchemaForABIInput(ctx context.Context, typeComponent abi.TypeComponent) *Schema {
 	schema := &Schema{
 		Details: &paramDetails{
@@ -1178,7 +1395,7 @@ func (e *Ethereum) getFFIType(solidityType string) core.FFIInputType {
 }
 
 func (e *Ethereum) getNetworkVersion(ctx context.Context, address string) (int, error) {
-	res, err := e.queryContractMethod(ctx, address, networkVersionMethodABI, []interface{}{}, nil)
+	res, err := e.queryContractMethod(ctx, address, NetworkVersionMethodABI, []interface{}{}, nil, nil)
 	if err != nil || !res.IsSuccess() {
 		// "Call failed" is interpreted as "method does not exist, default to version 1"
 		if strings.Contains(err.Error(), "FFEC100148") {
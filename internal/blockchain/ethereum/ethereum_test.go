@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -145,6 +146,18 @@ func TestInitMissingTopic(t *testing.T) {
 	assert.Regexp(t, "FF10138.*topic", err)
 }
 
+func TestInitBadConfirmations(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf(e)
+	utEthconnectConf.Set(ffresty.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	utEthconnectConf.Set(EthconnectConfigConfirmations, -1)
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.Regexp(t, "FF10482", err)
+}
+
 func TestInitAndStartWithFFTM(t *testing.T) {
 
 	log.SetLevel("trace")
@@ -1550,6 +1563,86 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 	em.AssertExpectations(t)
 }
 
+func TestHandleReceiptContractDeploySuccess(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	wsm := &wsmocks.WSClient{}
+	e := &Ethereum{
+		ctx:       context.Background(),
+		topic:     "topic1",
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+		wsconn:    wsm,
+	}
+
+	var reply fftypes.JSONObject
+	operationID := fftypes.NewUUID()
+	data := fftypes.JSONAnyPtr(`{
+		"contractAddress": "0x2b3a9a9a1c3d9b7a4f5cbf2a6b8ed7fa84e0bc91",
+		"from": "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635",
+		"headers": {
+			"requestId": "ns1:` + operationID.String() + `",
+			"type": "TransactionSuccess"
+		},
+		"transactionHash": "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8"
+	}`)
+
+	em.On("BlockchainOpUpdate",
+		e,
+		"ns1:"+operationID.String(),
+		core.OpStatusSucceeded,
+		"0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8",
+		"",
+		mock.MatchedBy(func(output fftypes.JSONObject) bool {
+			location := output["contractLocation"].(*Location)
+			return location.Address == "0x2b3a9a9a1c3d9b7a4f5cbf2a6b8ed7fa84e0bc91"
+		})).Return(nil)
+
+	err := json.Unmarshal(data.Bytes(), &reply)
+	assert.NoError(t, err)
+	e.handleReceipt(context.Background(), reply)
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleReceiptTXFailRevert(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	wsm := &wsmocks.WSClient{}
+	e := &Ethereum{
+		ctx:       context.Background(),
+		topic:     "topic1",
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+		wsconn:    wsm,
+	}
+
+	var reply fftypes.JSONObject
+	operationID := fftypes.NewUUID()
+	data := fftypes.JSONAnyPtr(`{
+		"errorMessage": "execution reverted",
+		"headers": {
+			"requestId": "ns1:` + operationID.String() + `",
+			"type": "TransactionFailure"
+		},
+		"returnValue": "` + testRevertReason + `",
+		"transactionHash": "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8"
+	}`)
+
+	em.On("BlockchainOpUpdate",
+		e,
+		"ns1:"+operationID.String(),
+		core.OpStatusFailed,
+		"0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8",
+		"execution reverted",
+		mock.MatchedBy(func(output fftypes.JSONObject) bool {
+			revert := output["revert"].(*core.ContractRevert)
+			return revert.Error == "Error" && revert.Params.GetString("message") == "insufficient funds"
+		})).Return(nil)
+
+	err := json.Unmarshal(data.Bytes(), &reply)
+	assert.NoError(t, err)
+	e.handleReceipt(context.Background(), reply)
+
+	em.AssertExpectations(t)
+}
+
 func TestHandleBadPayloadsAndThenReceiptFailure(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1662,6 +1755,107 @@ func TestAddSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAddSubscriptionMultipleEventsWithFilters(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID = "es-1"
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	addressSchema := fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address", "indexed": true}}`)
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Events: core.FFISerializedEvents{
+				{
+					FFIEventDefinition: core.FFIEventDefinition{
+						Name: "Transfer",
+						Params: core.FFIParams{
+							{Name: "from", Schema: addressSchema},
+							{Name: "to", Schema: addressSchema},
+						},
+					},
+				},
+				{
+					FFIEventDefinition: core.FFIEventDefinition{
+						Name: "Mint",
+						Params: core.FFIParams{
+							{Name: "to", Schema: addressSchema},
+						},
+					},
+				},
+			},
+			Options: &core.ContractListenerOptions{
+				FirstEvent: string(core.SubOptsFirstEventOldest),
+				Filters: fftypes.JSONObject{
+					"to": "0x00000000000000000000000000000000000000aa",
+				},
+			},
+		},
+	}
+	toTopic := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "0", body.FromBlock)
+			assert.Empty(t, body.Address)
+			assert.Nil(t, body.Event)
+			assert.Len(t, body.Filters, 2)
+			assert.Equal(t, "0x123", body.Filters[0].Address)
+			assert.Equal(t, "Transfer", body.Filters[0].Event.Name)
+			assert.Equal(t, []interface{}{EventTopic("Transfer(address,address)").String(), nil, toTopic}, body.Filters[0].Topics)
+			assert.Equal(t, "0x123", body.Filters[1].Address)
+			assert.Equal(t, "Mint", body.Filters[1].Event.Name)
+			assert.Equal(t, []interface{}{EventTopic("Mint(address)").String(), toTopic}, body.Filters[1].Topics)
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "sub1"})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", sub.BackendID)
+}
+
+func TestAddSubscriptionFilterNotIndexed(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.streamID = "es-1"
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &core.FFISerializedEvent{
+				FFIEventDefinition: core.FFIEventDefinition{
+					Name: "Changed",
+					Params: core.FFIParams{
+						{
+							Name:   "value",
+							Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "string"}}`),
+						},
+					},
+				},
+			},
+			Options: &core.ContractListenerOptions{
+				Filters: fftypes.JSONObject{"value": "abc"},
+			},
+		},
+	}
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10485", err)
+}
+
 func TestAddSubscriptionBadParamDetails(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1825,6 +2019,7 @@ func TestHandleMessageContractEvent(t *testing.T) {
 	}
 	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
 
+	em.On("ContractListenerConfirmations", "sub2").Return(nil, nil)
 	em.On("BlockchainEvent", mock.MatchedBy(func(e *blockchain.EventWithSubscription) bool {
 		assert.Equal(t, "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628", e.BlockchainTXID)
 		assert.Equal(t, "000000038011/000000/000050", e.Event.ProtocolID)
@@ -1837,7 +2032,7 @@ func TestHandleMessageContractEvent(t *testing.T) {
 	err = e.handleMessageBatch(context.Background(), events)
 	assert.NoError(t, err)
 
-	ev := em.Calls[0].Arguments[0].(*blockchain.EventWithSubscription)
+	ev := em.Calls[1].Arguments[0].(*blockchain.EventWithSubscription)
 	assert.Equal(t, "sub2", ev.Subscription)
 	assert.Equal(t, "Changed", ev.Event.Name)
 
@@ -1887,6 +2082,7 @@ func TestHandleMessageContractEventError(t *testing.T) {
 	}
 	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
 
+	em.On("ContractListenerConfirmations", "sub2").Return(nil, nil)
 	em.On("BlockchainEvent", mock.Anything).Return(fmt.Errorf("pop"))
 
 	var events []interface{}
@@ -1898,6 +2094,317 @@ func TestHandleMessageContractEventError(t *testing.T) {
 	em.AssertExpectations(t)
 }
 
+func TestHandleMessageContractEventConfirmations(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"blockHash": "0xad269b2b43481e44500f583108e8d24bd841fb767c7f526772959d195b9c72d5",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "1"
+    },
+		"subId": "sub2",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+	}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+
+	confirmations := 2
+	em.On("ContractListenerConfirmations", "sub2").Return(&confirmations, nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, e.tracker.Pending())
+
+	em.On("BlockchainEvent", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.ProtocolID == "000000038011/000000/000050" &&
+			ev.Info.GetString(blockchain.EventInfoBlockNumber) == "38011" &&
+			ev.Info.GetString(blockchain.EventInfoBlockHash) == "0xad269b2b43481e44500f583108e8d24bd841fb767c7f526772959d195b9c72d5"
+	})).Return(nil)
+
+	err = e.tracker.BlockObserved(context.Background(), 38013)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, e.tracker.Pending())
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageContractEventConfirmationsFail(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "1"
+    },
+		"subId": "sub2",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+	}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+
+	em.On("ContractListenerConfirmations", "sub2").Return(nil, fmt.Errorf("pop"))
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.EqualError(t, err, "pop")
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageBatchPinRemovedUnconfirmed(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+    "address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+    "blockNumber": "38011",
+    "transactionIndex": "0x0",
+    "transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+    "data": {
+      "author": "0X91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+      "namespace": "ns1",
+      "uuids": "0xe19af8b390604051812d7597d19adfb9847d3bfd074249efb65d3fed15f5b0a6",
+      "batchHash": "0xd71eb138d74c229a388eb0e1abc03f4c7cbb21d4fc4b839fbf0ec73e4263f6be",
+      "payloadRef": "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+      "contexts": []
+    },
+    "subId": "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+    "signature": "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])",
+    "logIndex": "50",
+    "timestamp": "1620576488"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks:     callbacks{listeners: []blockchain.Callbacks{em}},
+		confirmations: 5,
+	}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, e.tracker.Pending())
+
+	err = json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	events[0].(map[string]interface{})["removed"] = true
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, e.tracker.Pending())
+
+	err = e.tracker.BlockObserved(context.Background(), 40000)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageContractEventRemoved(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"logIndex": "49",
+		"subId": "sub2",
+		"removed": true
+  },
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "1"
+    },
+		"subId": "sub2",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383",
+		"removed": true
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+	}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+
+	em.On("BlockchainEventRemoved", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.ProtocolID == "000000038011/000000/000050" && ev.Subscription == "sub2"
+	})).Return(fmt.Errorf("pop"))
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.EqualError(t, err, "pop")
+
+	em.AssertExpectations(t)
+}
+
+func TestEventLoopReceiptConfirmsEvents(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	wsm := &wsmocks.WSClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e := &Ethereum{
+		ctx:       ctx,
+		topic:     "topic1",
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+		wsconn:    wsm,
+		closed:    make(chan struct{}),
+	}
+
+	dispatched := false
+	err := e.tracker.Add(ctx, "000000000010/000000/000000", 10, 2, func() error {
+		dispatched = true
+		return fmt.Errorf("pop")
+	})
+	assert.NoError(t, err)
+
+	r := make(chan []byte, 1)
+	r <- []byte(`{"headers":{"requestId":"ns1:` + fftypes.NewUUID().String() + `","type":"TransactionSuccess"},"blockNumber":"12"}`)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Close").Return()
+	em.On("BlockchainOpUpdate", e, mock.Anything, core.OpStatusSucceeded, "", "", mock.Anything).Return()
+
+	e.eventLoop() // exits on the dispatch error
+
+	assert.True(t, dispatched)
+	wsm.AssertExpectations(t)
+	em.AssertExpectations(t)
+}
+
+func testConfirmationsBatch() []byte {
+	return []byte(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "1"
+    },
+		"subId": "sub2",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383"
+  }
+]`)
+}
+
+func TestEventLoopBatchNotAckedWhileEventsPending(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	em := &blockchainmocks.Callbacks{}
+	e.callbacks = callbacks{listeners: []blockchain.Callbacks{em}}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+	e.closed = make(chan struct{})
+
+	confirmations := 2
+	em.On("ContractListenerConfirmations", "sub2").Return(&confirmations, nil)
+
+	// FireFly stops before the event is confirmed, so the batch must be left for ethconnect to redeliver
+	r := make(chan []byte, 1)
+	r <- testConfirmationsBatch()
+	close(r)
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Close").Return()
+
+	e.eventLoop()
+
+	assert.Equal(t, 1, e.tracker.Pending())
+	assert.True(t, e.unackedBatch)
+	wsm.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	wsm.AssertExpectations(t)
+	em.AssertExpectations(t)
+}
+
+func TestEventLoopHeadPollConfirmsEvents(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	em := &blockchainmocks.Callbacks{}
+	e.callbacks = callbacks{listeners: []blockchain.Callbacks{em}}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+	e.headPolling = time.Millisecond
+	e.closed = make(chan struct{})
+
+	confirmations := 2
+	em.On("ContractListenerConfirmations", "sub2").Return(&confirmations, nil)
+	em.On("BlockchainEvent", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.ProtocolID == "000000038011/000000/000050"
+	})).Return(nil)
+
+	// No further events or receipts arrive, so only the head block query can confirm the event
+	polls := 0
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "eth_blockNumber", body["method"])
+			polls++
+			if polls == 1 {
+				return httpmock.NewJsonResponderOrPanic(500, map[string]interface{}{"error": "pop"})(req)
+			}
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": "0x947d"})(req)
+		})
+
+	r := make(chan []byte, 1)
+	r <- testConfirmationsBatch()
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Send", mock.Anything, []byte(`{"topic":"topic1","type":"ack"}`)).Run(func(args mock.Arguments) {
+		assert.Equal(t, 0, e.tracker.Pending())
+		go cancel()
+	}).Return(nil)
+	wsm.On("Close").Return()
+
+	e.eventLoop()
+
+	assert.Equal(t, 2, polls)
+	assert.False(t, e.unackedBatch)
+	wsm.AssertExpectations(t)
+	em.AssertExpectations(t)
+}
+
 func TestInvokeContractOK(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1929,7 +2436,7 @@ func TestInvokeContractOK(t *testing.T) {
 			assert.Equal(t, body["customOption"].(string), "customValue")
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.NoError(t, err)
 }
 
@@ -1963,7 +2470,7 @@ func TestInvokeContractInvalidOption(t *testing.T) {
 			assert.Equal(t, "1000000000000000000000000", params[1])
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF10398", err)
 }
 
@@ -1998,7 +2505,7 @@ func TestInvokeContractInvalidInput(t *testing.T) {
 			assert.Equal(t, body["customOption"].(string), "customValue")
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "unsupported type", err)
 }
 
@@ -2015,7 +2522,7 @@ func TestInvokeContractAddressNotSet(t *testing.T) {
 	options := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "'address' not set", err)
 }
 
@@ -2040,7 +2547,67 @@ func TestInvokeContractEthconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
+	assert.Regexp(t, "FF10111", err)
+}
+
+func TestDeployContractOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	signingKey := ethHexFormatB32(fftypes.NewRandB32())
+	definition := fftypes.JSONAnyPtr(`[{"type":"constructor","inputs":[{"name":"x","type":"uint256"}]}]`)
+	contract := fftypes.JSONAnyPtr(`"0x608060405234801561001057600080fd5b50"`)
+	input := []interface{}{float64(1)}
+	options := map[string]interface{}{
+		"customOption": "customValue",
+	}
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "DeployContract", headers["type"])
+			assert.Equal(t, "ns1:123", headers["id"])
+			assert.Equal(t, signingKey, body["from"])
+			assert.Equal(t, float64(1), body["params"].([]interface{})[0])
+			assert.Equal(t, "0x608060405234801561001057600080fd5b50", body["contract"])
+			assert.Equal(t, "constructor", body["definition"].([]interface{})[0].(map[string]interface{})["type"])
+			assert.Equal(t, "customValue", body["customOption"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.DeployContract(context.Background(), "ns1:123", signingKey, definition, contract, input, options)
+	assert.NoError(t, err)
+}
+
+func TestDeployContractMissingContract(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.DeployContract(context.Background(), "ns1:123", "0x12345", fftypes.JSONAnyPtr(`[]`), nil, []interface{}{}, map[string]interface{}{})
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestDeployContractInvalidOption(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	options := map[string]interface{}{
+		"contract": "shouldn't be allowed",
+	}
+	err := e.DeployContract(context.Background(), "ns1:123", "0x12345", fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0x1234"`), []interface{}{}, options)
+	assert.Regexp(t, "FF10398", err)
+}
+
+func TestDeployContractEthconnectError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponderOrPanic(400, "")(req)
+		})
+	err := e.DeployContract(context.Background(), "ns1:123", "0x12345", fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0x1234"`), []interface{}{}, map[string]interface{}{})
 	assert.Regexp(t, "FF10111", err)
 }
 
@@ -2068,7 +2635,7 @@ func TestInvokeContractPrepareFail(t *testing.T) {
 	options := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "invalid json", err)
 }
 
@@ -2096,7 +2663,7 @@ func TestQueryContractOK(t *testing.T) {
 			assert.Equal(t, body["customOption"].(string), "customValue")
 			return httpmock.NewJsonResponderOrPanic(200, queryOutput{Output: "3"})(req)
 		})
-	result, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	result, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.NoError(t, err)
 	j, err := json.Marshal(result)
 	assert.NoError(t, err)
@@ -2126,7 +2693,7 @@ func TestQueryContractInvalidOption(t *testing.T) {
 			assert.Equal(t, "Query", headers["type"])
 			return httpmock.NewJsonResponderOrPanic(200, queryOutput{Output: "3"})(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF10398", err)
 }
 
@@ -2150,7 +2717,7 @@ func TestQueryContractErrorPrepare(t *testing.T) {
 	options := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "invalid json", err)
 }
 
@@ -2166,7 +2733,7 @@ func TestQueryContractAddressNotSet(t *testing.T) {
 	options := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "'address' not set", err)
 }
 
@@ -2190,10 +2757,40 @@ func TestQueryContractEthconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, queryOutput{})(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF10111", err)
 }
 
+func TestQueryContractReverted(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	location := &Location{
+		Address: "0x12345",
+	}
+	method := testFFIMethod()
+	params := map[string]interface{}{
+		"x": float64(1),
+		"y": float64(2),
+	}
+	options := map[string]interface{}{}
+	locationBytes, err := json.Marshal(location)
+	assert.NoError(t, err)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponderOrPanic(500, ethError{
+				Error: "execution reverted",
+				Data:  testRevertInsufficientBalance,
+			})(req)
+		})
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, testFFIErrors(), options)
+	assert.Regexp(t, "FF10489.*execution reverted", err)
+	revert := blockchain.GetRevert(err)
+	assert.Equal(t, "InsufficientBalance", revert.Error)
+	assert.Equal(t, "1", revert.Params.GetString("available"))
+}
+
 func TestQueryContractUnmarshalResponseError(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -2218,7 +2815,7 @@ func TestQueryContractUnmarshalResponseError(t *testing.T) {
 			assert.Equal(t, "Query", headers["type"])
 			return httpmock.NewStringResponder(200, "[definitely not JSON}")(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "invalid character", err)
 }
 
@@ -3215,6 +3812,56 @@ func TestConvertABIToFFIBadEventType(t *testing.T) {
 	assert.Regexp(t, "FF22025", err)
 }
 
+func TestConvertABIToFFIWithErrors(t *testing.T) {
+	e, _ := newTestEthereum()
+
+	abiJSON := `[
+		{
+			"inputs": [
+				{
+					"internalType": "uint256",
+					"name": "available",
+					"type": "uint256"
+				}
+			],
+			"name": "InsufficientBalance",
+			"type": "error"
+		}
+	]`
+
+	var abi *abi.ABI
+	json.Unmarshal([]byte(abiJSON), &abi)
+	ffi, err := e.convertABIToFFI(context.Background(), "ns1", "name", "version", "description", abi)
+	assert.NoError(t, err)
+	assert.Len(t, ffi.Errors, 1)
+	assert.Equal(t, "InsufficientBalance", ffi.Errors[0].Name)
+	assert.Equal(t, "available", ffi.Errors[0].Params[0].Name)
+	assert.Contains(t, ffi.Errors[0].Params[0].Schema.String(), `"details":{"type":"uint256","internalType":"uint256"}`)
+}
+
+func TestConvertABIToFFIBadErrorType(t *testing.T) {
+	e, _ := newTestEthereum()
+
+	abiJSON := `[
+		{
+			"inputs": [
+				{
+					"internalType": "string",
+					"name": "name",
+					"type": "foobar"
+				}
+			],
+			"name": "Bad",
+			"type": "error"
+		}
+	]`
+
+	var abi *abi.ABI
+	json.Unmarshal([]byte(abiJSON), &abi)
+	_, err := e.convertABIToFFI(context.Background(), "ns1", "name", "version", "description", abi)
+	assert.Regexp(t, "FF22025", err)
+}
+
 func TestConvertABIEventFFIEvent(t *testing.T) {
 	e, _ := newTestEthereum()
 
@@ -3254,3 +3901,27 @@ func TestNetworkVersion(t *testing.T) {
 	e.fireflyContract.networkVersion = 2
 	assert.Equal(t, 2, e.NetworkVersion())
 }
+
+func TestCallbacksContractListenerConfirmations(t *testing.T) {
+	em1 := &blockchainmocks.Callbacks{}
+	em2 := &blockchainmocks.Callbacks{}
+	cb := callbacks{listeners: []blockchain.Callbacks{em1, em2}}
+
+	confirmations := 3
+	em1.On("ContractListenerConfirmations", "sub1").Return(nil, nil)
+	em2.On("ContractListenerConfirmations", "sub1").Return(&confirmations, nil)
+
+	result, err := cb.ContractListenerConfirmations("sub1")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, *result)
+
+	em1.On("ContractListenerConfirmations", "sub2").Return(nil, nil)
+	em2.On("ContractListenerConfirmations", "sub2").Return(nil, nil)
+
+	result, err = cb.ContractListenerConfirmations("sub2")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	em1.AssertExpectations(t)
+	em2.AssertExpectations(t)
+}
@@ -0,0 +1,135 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"golang.org/x/crypto/sha3"
+)
+
+func keccak256(b []byte) ethtypes.HexBytes0xPrefix {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(b)
+	return hash.Sum(nil)
+}
+
+// EventTopic returns the first topic of the logs emitted for an event, which is the hash of its signature
+func EventTopic(signature string) ethtypes.HexBytes0xPrefix {
+	return keccak256([]byte(signature))
+}
+
+// EventFilterTopics builds the topics that match the logs of an event, in the format of an eth_getLogs filter.
+// The filters are a map of indexed parameter names to a value, or an array of values, that must match.
+// Each entry in the result is nil to match any value, a single topic, or an array of topics to match any of.
+func EventFilterTopics(ctx context.Context, event *abi.Entry, filters fftypes.JSONObject) ([]interface{}, error) {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		found := false
+		for _, param := range event.Inputs {
+			if param.Indexed && param.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, i18n.NewError(ctx, coremsgs.MsgListenerFilterParamNotIndexed, name, event.Name)
+		}
+	}
+
+	var topics []interface{}
+	if !event.Anonymous {
+		topics = append(topics, EventTopic(ABIMethodToSignature(event)).String())
+	}
+	for _, param := range event.Inputs {
+		if !param.Indexed {
+			continue
+		}
+		value, ok := filters[param.Name]
+		if !ok {
+			topics = append(topics, nil)
+			continue
+		}
+		values, isArray := value.([]interface{})
+		if !isArray {
+			values = []interface{}{value}
+		}
+		paramTopics := make([]string, len(values))
+		for i, v := range values {
+			topic, err := indexedParamTopic(ctx, param, v)
+			if err != nil {
+				return nil, err
+			}
+			paramTopics[i] = topic.String()
+		}
+		if len(paramTopics) == 1 {
+			topics = append(topics, paramTopics[0])
+		} else {
+			topics = append(topics, paramTopics)
+		}
+	}
+
+	// Trailing wildcards are implied
+	for len(topics) > 0 && topics[len(topics)-1] == nil {
+		topics = topics[:len(topics)-1]
+	}
+	return topics, nil
+}
+
+// indexedParamTopic encodes a value of an indexed parameter as it appears in the log topics. Value types are
+// encoded in place, while strings and bytes are stored as a hash of their content.
+func indexedParamTopic(ctx context.Context, param *abi.Parameter, value interface{}) (ethtypes.HexBytes0xPrefix, error) {
+	switch {
+	case param.Type == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, i18n.NewError(ctx, coremsgs.MsgListenerFilterInvalidValue, param.Name, fmt.Sprintf("%v", value))
+		}
+		return keccak256([]byte(s)), nil
+	case param.Type == "bytes":
+		s, _ := value.(string)
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil || s == "" {
+			return nil, i18n.NewError(ctx, coremsgs.MsgListenerFilterInvalidValue, param.Name, fmt.Sprintf("%v", value))
+		}
+		return keccak256(b), nil
+	case strings.HasSuffix(param.Type, "]") || strings.HasPrefix(param.Type, "tuple"):
+		return nil, i18n.NewError(ctx, coremsgs.MsgListenerFilterUnsupportedType, param.Name, param.Type)
+	}
+	cv, err := abi.ParameterArray{param}.ParseExternalData([]interface{}{value})
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgListenerFilterInvalidValue, param.Name, err)
+	}
+	data, err := cv.EncodeABIData()
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgListenerFilterInvalidValue, param.Name, err)
+	}
+	return data, nil
+}
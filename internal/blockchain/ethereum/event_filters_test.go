@@ -0,0 +1,148 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/stretchr/testify/assert"
+)
+
+var transferEventABI = &abi.Entry{
+	Name: "Transfer",
+	Type: "event",
+	Inputs: abi.ParameterArray{
+		{Name: "from", Type: "address", Indexed: true},
+		{Name: "to", Type: "address", Indexed: true},
+		{Name: "value", Type: "uint256"},
+	},
+}
+
+const transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+func TestEventTopic(t *testing.T) {
+	assert.Equal(t, transferTopic, EventTopic("Transfer(address,address,uint256)").String())
+}
+
+func TestEventFilterTopicsNoFilters(t *testing.T) {
+	topics, err := EventFilterTopics(context.Background(), transferEventABI, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{transferTopic}, topics)
+}
+
+func TestEventFilterTopicsSingleValue(t *testing.T) {
+	topics, err := EventFilterTopics(context.Background(), transferEventABI, fftypes.JSONObject{
+		"to": "0x00000000000000000000000000000000000000aa",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		transferTopic,
+		nil,
+		"0x00000000000000000000000000000000000000000000000000000000000000aa",
+	}, topics)
+}
+
+func TestEventFilterTopicsMultipleValues(t *testing.T) {
+	topics, err := EventFilterTopics(context.Background(), transferEventABI, fftypes.JSONObject{
+		"from": []interface{}{
+			"0x0000000000000000000000000000000000000001",
+			"0x0000000000000000000000000000000000000002",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		transferTopic,
+		[]string{
+			"0x0000000000000000000000000000000000000000000000000000000000000001",
+			"0x0000000000000000000000000000000000000000000000000000000000000002",
+		},
+	}, topics)
+}
+
+func TestEventFilterTopicsHashedValues(t *testing.T) {
+	event := &abi.Entry{
+		Name:      "Named",
+		Type:      "event",
+		Anonymous: true,
+		Inputs: abi.ParameterArray{
+			{Name: "name", Type: "string", Indexed: true},
+			{Name: "data", Type: "bytes", Indexed: true},
+		},
+	}
+	topics, err := EventFilterTopics(context.Background(), event, fftypes.JSONObject{
+		"name": "abc",
+		"data": "0x0102",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		"0x4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+		"0x22ae6da6b482f9b1b19b0b897c3fd43884180a1c5ee361e1107a1bc635649dda",
+	}, topics)
+}
+
+func TestEventFilterTopicsNotIndexed(t *testing.T) {
+	_, err := EventFilterTopics(context.Background(), transferEventABI, fftypes.JSONObject{
+		"value": "1",
+	})
+	assert.Regexp(t, "FF10485.*value.*Transfer", err)
+}
+
+func TestEventFilterTopicsBadAddress(t *testing.T) {
+	_, err := EventFilterTopics(context.Background(), transferEventABI, fftypes.JSONObject{
+		"to": "bad",
+	})
+	assert.Regexp(t, "FF10487.*to", err)
+}
+
+func TestEventFilterTopicsBadString(t *testing.T) {
+	event := &abi.Entry{
+		Name:   "Named",
+		Type:   "event",
+		Inputs: abi.ParameterArray{{Name: "name", Type: "string", Indexed: true}},
+	}
+	_, err := EventFilterTopics(context.Background(), event, fftypes.JSONObject{
+		"name": float64(1),
+	})
+	assert.Regexp(t, "FF10487.*name", err)
+}
+
+func TestEventFilterTopicsBadBytes(t *testing.T) {
+	event := &abi.Entry{
+		Name:   "Data",
+		Type:   "event",
+		Inputs: abi.ParameterArray{{Name: "data", Type: "bytes", Indexed: true}},
+	}
+	_, err := EventFilterTopics(context.Background(), event, fftypes.JSONObject{
+		"data": "not hex",
+	})
+	assert.Regexp(t, "FF10487.*data", err)
+}
+
+func TestEventFilterTopicsUnsupportedType(t *testing.T) {
+	event := &abi.Entry{
+		Name:   "Values",
+		Type:   "event",
+		Inputs: abi.ParameterArray{{Name: "values", Type: "uint256[]", Indexed: true}},
+	}
+	_, err := EventFilterTopics(context.Background(), event, fftypes.JSONObject{
+		"values": []interface{}{"1"},
+	})
+	assert.Regexp(t, "FF10486.*values.*uint256\\[\\]", err)
+}
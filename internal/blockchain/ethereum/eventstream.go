@@ -46,12 +46,21 @@ type eventStream struct {
 }
 
 type subscription struct {
-	ID        string     `json:"id"`
-	Name      string     `json:"name,omitempty"`
-	Stream    string     `json:"stream"`
-	FromBlock string     `json:"fromBlock"`
-	Address   string     `json:"address"`
-	Event     *abi.Entry `json:"event"`
+	ID        string         `json:"id"`
+	Name      string         `json:"name,omitempty"`
+	Stream    string         `json:"stream"`
+	FromBlock string         `json:"fromBlock"`
+	Address   string         `json:"address,omitempty"`
+	Event     *abi.Entry     `json:"event,omitempty"`
+	Filters   []*eventFilter `json:"filters,omitempty"`
+}
+
+// eventFilter selects the logs of one event, optionally restricted by topics built from indexed
+// parameter values, for a subscription that covers multiple events or filters on indexed parameters
+type eventFilter struct {
+	Address string        `json:"address"`
+	Event   *abi.Entry    `json:"event"`
+	Topics  []interface{} `json:"topics,omitempty"`
 }
 
 func (s *streamManager) getEventStreams(ctx context.Context) (streams []*eventStream, err error) {
@@ -135,7 +144,7 @@ func (s *streamManager) getSubscriptions(ctx context.Context) (subs []*subscript
 	return subs, nil
 }
 
-func (s *streamManager) createSubscription(ctx context.Context, location *Location, stream, subName, fromBlock string, abi *abi.Entry) (*subscription, error) {
+func (s *streamManager) createSubscription(ctx context.Context, location *Location, stream, subName, fromBlock string, filters []*eventFilter) (*subscription, error) {
 	// Map FireFly "firstEvent" values to Ethereum "fromBlock" values
 	switch fromBlock {
 	case string(core.SubOptsFirstEventOldest):
@@ -147,8 +156,16 @@ func (s *streamManager) createSubscription(ctx context.Context, location *Locati
 		Name:      subName,
 		Stream:    stream,
 		FromBlock: fromBlock,
-		Address:   location.Address,
-		Event:     abi,
+	}
+	if len(filters) == 1 && len(filters[0].Topics) == 0 {
+		// A single unfiltered event uses the simple form of subscription
+		sub.Address = location.Address
+		sub.Event = filters[0].Event
+	} else {
+		for _, f := range filters {
+			f.Address = location.Address
+		}
+		sub.Filters = filters
 	}
 	res, err := s.client.R().
 		SetContext(ctx).
@@ -199,7 +216,7 @@ func (s *streamManager) ensureFireFlySubscription(ctx context.Context, instanceP
 	}
 
 	if sub == nil {
-		if sub, err = s.createSubscription(ctx, location, stream, subName, fromBlock, abi); err != nil {
+		if sub, err = s.createSubscription(ctx, location, stream, subName, fromBlock, []*eventFilter{{Event: abi}}); err != nil {
 			return nil, err
 		}
 	}
@@ -0,0 +1,142 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// The built-in errors that Solidity reverts with for require/revert with a reason string, and for failed assertions
+var (
+	revertReasonABI = &abi.Entry{
+		Type:   abi.Error,
+		Name:   "Error",
+		Inputs: abi.ParameterArray{{Name: "message", Type: "string"}},
+	}
+	revertPanicABI = &abi.Entry{
+		Type:   abi.Error,
+		Name:   "Panic",
+		Inputs: abi.ParameterArray{{Name: "code", Type: "uint256"}},
+	}
+)
+
+func (e *Ethereum) FFIErrorDefinitionToABI(ctx context.Context, errorDef *core.FFIErrorDefinition) (*abi.Entry, error) {
+	abiInputs, err := e.convertFFIParamsToABIParameters(ctx, errorDef.Params)
+	if err != nil {
+		return nil, err
+	}
+	return &abi.Entry{
+		Name:   errorDef.Name,
+		Type:   abi.Error,
+		Inputs: abiInputs,
+	}, nil
+}
+
+func (e *Ethereum) GenerateErrorSignature(ctx context.Context, errorDef *core.FFIErrorDefinition) string {
+	abi, err := e.FFIErrorDefinitionToABI(ctx, errorDef)
+	if err != nil {
+		return ""
+	}
+	return ABIMethodToSignature(abi)
+}
+
+// DecodeRevert decodes the data returned by a reverted transaction or call, against the built-in Solidity
+// errors and the supplied error definitions. Returns nil if the data does not match any of them.
+func (e *Ethereum) DecodeRevert(ctx context.Context, errors []*core.FFIError, data []byte) *core.ContractRevert {
+	if len(data) < 4 {
+		return nil
+	}
+	entries := []*abi.Entry{revertReasonABI, revertPanicABI}
+	for _, errorDef := range errors {
+		entry, err := e.FFIErrorDefinitionToABI(ctx, &errorDef.FFIErrorDefinition)
+		if err != nil {
+			log.L(ctx).Warnf("Skipping invalid error definition '%s': %s", errorDef.Name, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	for _, entry := range entries {
+		if !bytes.Equal(entry.IDBytes(), data[0:4]) {
+			continue
+		}
+		cv, err := entry.DecodeCallDataCtx(ctx, data)
+		if err != nil {
+			log.L(ctx).Warnf("Failed to decode revert data as '%s': %s", entry.Name, err)
+			return nil
+		}
+		b, err := abi.NewSerializer().
+			SetFormattingMode(abi.FormatAsObjects).
+			SetByteSerializer(abi.HexByteSerializer0xPrefix).
+			SerializeJSONCtx(ctx, cv)
+		params := fftypes.JSONObject{}
+		if err == nil {
+			err = json.Unmarshal(b, &params)
+		}
+		if err != nil {
+			log.L(ctx).Warnf("Failed to serialize revert data as '%s': %s", entry.Name, err)
+			return nil
+		}
+		return &core.ContractRevert{
+			Error:  entry.Name,
+			Params: params,
+		}
+	}
+	return nil
+}
+
+// DecodeRevertHex is a convenience wrapper for DecodeRevert, for revert data reported as a hex string
+func (e *Ethereum) DecodeRevertHex(ctx context.Context, errors []*core.FFIError, data string) *core.ContractRevert {
+	b, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil {
+		return nil
+	}
+	return e.DecodeRevert(ctx, errors, b)
+}
+
+// RevertError returns a blockchain.RevertError wrapping the supplied error, if the revert data can be
+// decoded against the error definitions. Otherwise the error is returned unchanged.
+func (e *Ethereum) RevertError(ctx context.Context, err error, errors []*core.FFIError, data string) error {
+	revert := e.DecodeRevertHex(ctx, errors, data)
+	if revert == nil {
+		return err
+	}
+	return &blockchain.RevertError{
+		Err:    i18n.WrapError(ctx, err, coremsgs.MsgContractReverted, revert.Error),
+		Revert: revert,
+	}
+}
+
+// DecodeOperationRevert decodes the revert data that ethconnect reports as the return value of a failed transaction
+func (e *Ethereum) DecodeOperationRevert(ctx context.Context, opOutput fftypes.JSONObject, errors []*core.FFIError) *core.ContractRevert {
+	revertData := opOutput.GetString("returnValue")
+	if revertData == "" {
+		return nil
+	}
+	return e.DecodeRevertHex(ctx, errors, revertData)
+}
@@ -0,0 +1,146 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testRevertReason              = "0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000012696e73756666696369656e742066756e64730000000000000000000000000000"
+	testRevertPanic               = "0x4e487b710000000000000000000000000000000000000000000000000000000000000011"
+	testRevertInsufficientBalance = "0xcf47918100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000064"
+)
+
+func testFFIErrors() []*core.FFIError {
+	return []*core.FFIError{
+		{
+			FFIErrorDefinition: core.FFIErrorDefinition{
+				Name: "InsufficientBalance",
+				Params: core.FFIParams{
+					{
+						Name:   "available",
+						Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+					},
+					{
+						Name:   "required",
+						Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateErrorSignature(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	signature := e.GenerateErrorSignature(context.Background(), &testFFIErrors()[0].FFIErrorDefinition)
+	assert.Equal(t, "InsufficientBalance(uint256,uint256)", signature)
+}
+
+func TestGenerateErrorSignatureInvalid(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	errorDef := &core.FFIErrorDefinition{
+		Name: "Bad",
+		Params: []*core.FFIParam{
+			{
+				Name:   "x",
+				Schema: fftypes.JSONAnyPtr(`{"!bad": "bad"`),
+			},
+		},
+	}
+	signature := e.GenerateErrorSignature(context.Background(), errorDef)
+	assert.Equal(t, "", signature)
+}
+
+func TestDecodeRevertReason(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	revert := e.DecodeRevertHex(context.Background(), nil, testRevertReason)
+	assert.Equal(t, "Error", revert.Error)
+	assert.Equal(t, "insufficient funds", revert.Params.GetString("message"))
+}
+
+func TestDecodeRevertPanic(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	revert := e.DecodeRevertHex(context.Background(), nil, testRevertPanic)
+	assert.Equal(t, "Panic", revert.Error)
+	assert.Equal(t, "17", revert.Params.GetString("code"))
+}
+
+func TestDecodeRevertCustomError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	errors := append([]*core.FFIError{
+		{
+			FFIErrorDefinition: core.FFIErrorDefinition{
+				Name: "Bad",
+				Params: core.FFIParams{
+					{Name: "x", Schema: fftypes.JSONAnyPtr(`{badschema}`)},
+				},
+			},
+		},
+	}, testFFIErrors()...)
+	revert := e.DecodeRevertHex(context.Background(), errors, testRevertInsufficientBalance)
+	assert.Equal(t, "InsufficientBalance", revert.Error)
+	assert.Equal(t, "1", revert.Params.GetString("available"))
+	assert.Equal(t, "100", revert.Params.GetString("required"))
+}
+
+func TestDecodeRevertNoMatch(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	assert.Nil(t, e.DecodeRevertHex(context.Background(), nil, testRevertInsufficientBalance))
+	assert.Nil(t, e.DecodeRevertHex(context.Background(), nil, "0x08c3"))
+	assert.Nil(t, e.DecodeRevertHex(context.Background(), nil, "not hex"))
+}
+
+func TestDecodeRevertBadData(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	assert.Nil(t, e.DecodeRevertHex(context.Background(), nil, testRevertReason[0:20]))
+}
+
+func TestRevertError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.RevertError(context.Background(), fmt.Errorf("pop"), testFFIErrors(), testRevertInsufficientBalance)
+	assert.Regexp(t, "FF10489.*InsufficientBalance.*pop", err)
+	assert.Equal(t, "InsufficientBalance", blockchain.GetRevert(err).Error)
+
+	err = e.RevertError(context.Background(), fmt.Errorf("pop"), testFFIErrors(), "")
+	assert.Nil(t, blockchain.GetRevert(err))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDecodeOperationRevert(t *testing.T) {
+	e := &Ethereum{}
+	revert := e.DecodeOperationRevert(context.Background(), fftypes.JSONObject{"returnValue": testRevertInsufficientBalance}, testFFIErrors())
+	assert.Equal(t, "InsufficientBalance", revert.Error)
+	assert.Nil(t, e.DecodeOperationRevert(context.Background(), fftypes.JSONObject{"returnValue": testRevertInsufficientBalance}, nil))
+	assert.Nil(t, e.DecodeOperationRevert(context.Background(), fftypes.JSONObject{}, testFFIErrors()))
+}
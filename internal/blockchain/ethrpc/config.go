@@ -0,0 +1,83 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+)
+
+const (
+	defaultPollingInterval = "1s"
+	defaultBlockRange      = 500
+	defaultConfirmations   = 0
+	defaultFromBlock       = "oldest"
+)
+
+const (
+	// RPCConfigKey is a sub-key in the config to contain the HTTP JSON-RPC connection to the Ethereum node
+	RPCConfigKey = "rpc"
+	// RPCConfigChainID is the chain ID to use when signing transactions (queried from the node if not set)
+	RPCConfigChainID = "chainId"
+
+	// KeystoreConfigKey is a sub-key in the config to contain the local keystore used for signing
+	KeystoreConfigKey = "keystore"
+	// KeystoreConfigPath is the directory containing V3 keystore files
+	KeystoreConfigPath = "path"
+	// KeystoreConfigPasswordFile is a file containing the password for the keystore files
+	KeystoreConfigPasswordFile = "passwordFile"
+
+	// EventsConfigKey is a sub-key in the config to contain the event polling configuration
+	EventsConfigKey = "events"
+	// EventsConfigPollingInterval is how often to poll for new blocks, logs and receipts
+	EventsConfigPollingInterval = "pollingInterval"
+	// EventsConfigBlockRange is the maximum number of blocks to query in a single eth_getLogs call
+	EventsConfigBlockRange = "blockRange"
+	// EventsConfigConfirmations is the number of blocks that must be mined on top of an event before it is dispatched
+	EventsConfigConfirmations = "confirmations"
+	// EventsConfigStateFile is a file used to persist listeners, checkpoints and pending transactions
+	EventsConfigStateFile = "stateFile"
+
+	// FireFlyContractConfigKey is a sub-key in the config to contain the info on the deployed FireFly contract
+	FireFlyContractConfigKey = "fireflyContract"
+	// FireFlyContractAddress is the ethereum address of the FireFly contract
+	FireFlyContractAddress = "address"
+	// FireFlyContractFromBlock is the first block to query for events from the FireFly contract.
+	// It is lower case, as keys inside the fireflyContract array are matched case-sensitively
+	FireFlyContractFromBlock = "fromblock"
+)
+
+func (e *EthRPC) InitConfig(config config.Section) {
+	e.rpcConf = config.SubSection(RPCConfigKey)
+	ffresty.InitConfig(e.rpcConf)
+	e.rpcConf.AddKnownKey(RPCConfigChainID)
+
+	e.keystoreConf = config.SubSection(KeystoreConfigKey)
+	e.keystoreConf.AddKnownKey(KeystoreConfigPath)
+	e.keystoreConf.AddKnownKey(KeystoreConfigPasswordFile)
+
+	e.eventsConf = config.SubSection(EventsConfigKey)
+	e.eventsConf.AddKnownKey(EventsConfigPollingInterval, defaultPollingInterval)
+	e.eventsConf.AddKnownKey(EventsConfigBlockRange, defaultBlockRange)
+	e.eventsConf.AddKnownKey(EventsConfigConfirmations, defaultConfirmations)
+	e.eventsConf.AddKnownKey(EventsConfigStateFile)
+
+	e.contractConf = config.SubArray(FireFlyContractConfigKey)
+	e.contractConf.AddKnownKey(FireFlyContractAddress)
+	e.contractConf.AddKnownKey(FireFlyContractFromBlock, defaultFromBlock)
+	e.contractConfSize = e.contractConf.ArraySize()
+}
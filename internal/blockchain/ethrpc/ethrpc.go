@@ -0,0 +1,556 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// EthRPC is a blockchain plugin that talks JSON-RPC directly to an Ethereum node, signing
+// transactions locally with keys from a keystore directory, and polling the node for events.
+type EthRPC struct {
+	rpcID           int64
+	ctx             context.Context
+	capabilities    *blockchain.Capabilities
+	callbacks       callbacks
+	client          *resty.Client
+	chainID         int64
+	keystore        *keystore
+	nonces          *nonceManager
+	abiConverter    *ethereum.Ethereum
+	fireflyContract struct {
+		mux            sync.Mutex
+		address        string
+		networkVersion int
+	}
+	pollingInterval  time.Duration
+	blockRange       uint64
+	confirmations    uint64
+	stateFile        string
+	stateMux         sync.Mutex
+	state            *pluginState
+	closed           chan struct{}
+	metrics          metrics.Manager
+	rpcConf          config.Section
+	keystoreConf     config.Section
+	eventsConf       config.Section
+	contractConf     config.ArraySection
+	contractConfSize int
+}
+
+type callbacks struct {
+	listeners []blockchain.Callbacks
+}
+
+func (cb *callbacks) BlockchainOpUpdate(plugin blockchain.Plugin, nsOpID string, txState blockchain.TransactionStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) {
+	for _, cb := range cb.listeners {
+		cb.BlockchainOpUpdate(plugin, nsOpID, txState, blockchainTXID, errorMessage, opOutput)
+	}
+}
+
+func (cb *callbacks) BatchPinComplete(batch *blockchain.BatchPin, signingKey *core.VerifierRef) error {
+	for _, cb := range cb.listeners {
+		if err := cb.BatchPinComplete(batch, signingKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cb *callbacks) BlockchainNetworkAction(action string, event *blockchain.Event, signingKey *core.VerifierRef) error {
+	for _, cb := range cb.listeners {
+		if err := cb.BlockchainNetworkAction(action, event, signingKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cb *callbacks) BlockchainEvent(event *blockchain.EventWithSubscription) error {
+	for _, cb := range cb.listeners {
+		if err := cb.BlockchainEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *EthRPC) Name() string {
+	return "ethrpc"
+}
+
+func (e *EthRPC) VerifierType() core.VerifierType {
+	return core.VerifierTypeEthAddress
+}
+
+func (e *EthRPC) Init(ctx context.Context, config config.Section, metrics metrics.Manager) (err error) {
+	e.InitConfig(config)
+
+	e.ctx = log.WithLogField(ctx, "proto", "ethrpc")
+	e.metrics = metrics
+	e.capabilities = &blockchain.Capabilities{}
+	e.abiConverter = &ethereum.Ethereum{}
+	e.nonces = newNonceManager()
+
+	if e.rpcConf.GetString(ffresty.HTTPConfigURL) == "" {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "url", "blockchain.ethrpc.rpc")
+	}
+	e.client = ffresty.New(e.ctx, e.rpcConf)
+
+	e.keystore, err = loadKeystore(e.ctx, e.keystoreConf.GetString(KeystoreConfigPath), e.keystoreConf.GetString(KeystoreConfigPasswordFile))
+	if err != nil {
+		return err
+	}
+
+	e.pollingInterval = e.eventsConf.GetDuration(EventsConfigPollingInterval)
+	e.blockRange = uint64(e.eventsConf.GetUint(EventsConfigBlockRange))
+	if e.blockRange == 0 {
+		e.blockRange = defaultBlockRange
+	}
+	confirmations := e.eventsConf.GetInt(EventsConfigConfirmations)
+	if confirmations < 0 {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidConfirmations, confirmations)
+	}
+	e.confirmations = uint64(confirmations)
+	e.stateFile = e.eventsConf.GetString(EventsConfigStateFile)
+	if e.stateFile == "" {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "stateFile", "blockchain.ethrpc.events")
+	}
+	if err = e.loadState(e.ctx); err != nil {
+		return err
+	}
+
+	e.chainID = e.rpcConf.GetInt64(RPCConfigChainID)
+	if e.chainID == 0 {
+		var chainID hexUint64
+		if err = e.callRPC(e.ctx, &chainID, "eth_chainId"); err != nil {
+			return err
+		}
+		e.chainID = int64(chainID.Uint64())
+	}
+	log.L(e.ctx).Infof("Ethereum JSON-RPC connector initialized (chainId=%d)", e.chainID)
+
+	return nil
+}
+
+func (e *EthRPC) RegisterListener(listener blockchain.Callbacks) {
+	e.callbacks.listeners = append(e.callbacks.listeners, listener)
+}
+
+func (e *EthRPC) Start() error {
+	e.closed = make(chan struct{})
+	go e.eventLoop()
+	return nil
+}
+
+func (e *EthRPC) Capabilities() *blockchain.Capabilities {
+	return e.capabilities
+}
+
+func (e *EthRPC) resolveFireFlyContract(ctx context.Context, contractIndex int) (address, fromBlock string, err error) {
+	if contractIndex >= e.contractConfSize {
+		return "", "", i18n.NewError(ctx, coremsgs.MsgInvalidFireFlyContractIndex, fmt.Sprintf("blockchain.ethrpc.fireflyContract[%d]", contractIndex))
+	}
+	entry := e.contractConf.ArrayEntry(contractIndex)
+	address = entry.GetString(FireFlyContractAddress)
+	if address == "" {
+		return "", "", i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "address", "blockchain.ethrpc.fireflyContract")
+	}
+	address, err = e.abiConverter.NormalizeSigningKey(ctx, address)
+	return address, entry.GetString(FireFlyContractFromBlock), err
+}
+
+func (e *EthRPC) ConfigureContract(ctx context.Context, contracts *core.FireFlyContracts) (err error) {
+
+	log.L(ctx).Infof("Resolving FireFly contract at index %d", contracts.Active.Index)
+	address, fromBlock, err := e.resolveFireFlyContract(ctx, contracts.Active.Index)
+	if err != nil {
+		return err
+	}
+
+	version, err := e.getNetworkVersion(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	e.stateMux.Lock()
+	_, hasCheckpoint := e.state.FireFlyCheckpoints[address]
+	e.stateMux.Unlock()
+	if !hasCheckpoint {
+		checkpoint, err := e.resolveFromBlock(ctx, fromBlock)
+		if err != nil {
+			return err
+		}
+		e.stateMux.Lock()
+		e.state.FireFlyCheckpoints[address] = checkpoint
+		e.stateMux.Unlock()
+		if err = e.saveState(ctx); err != nil {
+			return err
+		}
+	}
+
+	e.fireflyContract.mux.Lock()
+	e.fireflyContract.address = address
+	e.fireflyContract.networkVersion = version
+	e.fireflyContract.mux.Unlock()
+	contracts.Active.Info = fftypes.JSONObject{
+		"address":   address,
+		"fromBlock": fromBlock,
+	}
+	return nil
+}
+
+func (e *EthRPC) TerminateContract(ctx context.Context, contracts *core.FireFlyContracts, termination *blockchain.Event) (err error) {
+
+	address, err := e.abiConverter.NormalizeSigningKey(ctx, termination.Info.GetString("address"))
+	if err != nil {
+		return err
+	}
+	e.fireflyContract.mux.Lock()
+	fireflyAddress := e.fireflyContract.address
+	e.fireflyContract.mux.Unlock()
+	if address != fireflyAddress {
+		log.L(ctx).Warnf("Ignoring termination request from address %s, which differs from active address %s", address, fireflyAddress)
+		return nil
+	}
+
+	log.L(ctx).Infof("Processing termination request from address %s", address)
+	contracts.Active.FinalEvent = termination.ProtocolID
+	contracts.Terminated = append(contracts.Terminated, contracts.Active)
+	contracts.Active = core.FireFlyContractInfo{Index: contracts.Active.Index + 1}
+	return e.ConfigureContract(ctx, contracts)
+}
+
+// resolveFromBlock converts "oldest", "newest" or a block number, into the first block to query
+func (e *EthRPC) resolveFromBlock(ctx context.Context, fromBlock string) (uint64, error) {
+	switch fromBlock {
+	case "", string(core.SubOptsFirstEventOldest):
+		return 0, nil
+	case string(core.SubOptsFirstEventNewest):
+		var head hexUint64
+		if err := e.callRPC(ctx, &head, "eth_blockNumber"); err != nil {
+			return 0, err
+		}
+		return head.Uint64() + 1, nil
+	default:
+		block, err := strconv.ParseUint(fromBlock, 10, 64)
+		if err != nil {
+			return 0, i18n.NewError(ctx, coremsgs.MsgEthRPCInvalidFromBlock, fromBlock)
+		}
+		return block, nil
+	}
+}
+
+func ethHexFormatB32(b *fftypes.Bytes32) string {
+	if b == nil {
+		return "0x0000000000000000000000000000000000000000000000000000000000000000"
+	}
+	return "0x" + hex.EncodeToString(b[0:32])
+}
+
+func (e *EthRPC) NormalizeSigningKey(ctx context.Context, key string) (string, error) {
+	return e.abiConverter.NormalizeSigningKey(ctx, key)
+}
+
+func (e *EthRPC) fireflyContractAddress(ctx context.Context) (*ethtypes.Address0xHex, error) {
+	e.fireflyContract.mux.Lock()
+	address := e.fireflyContract.address
+	e.fireflyContract.mux.Unlock()
+	return ethtypes.NewAddress(address)
+}
+
+func (e *EthRPC) submitBatchPinTransaction(ctx context.Context, nsOpID, signingKey string, input []interface{}) error {
+	to, err := e.fireflyContractAddress(ctx)
+	if err != nil {
+		return err
+	}
+	if e.metrics.IsMetricsEnabled() {
+		e.metrics.BlockchainTransaction(to.String(), ethereum.BatchPinMethodABI.Name)
+	}
+	data, err := encodeCallData(ctx, ethereum.BatchPinMethodABI, input)
+	if err != nil {
+		return err
+	}
+	return e.sendTransaction(ctx, nsOpID, signingKey, to, data, nil)
+}
+
+func (e *EthRPC) SubmitBatchPin(ctx context.Context, nsOpID string, signingKey string, batch *blockchain.BatchPin) error {
+	ethHashes := make([]string, len(batch.Contexts))
+	for i, v := range batch.Contexts {
+		ethHashes[i] = ethHexFormatB32(v)
+	}
+	var uuids fftypes.Bytes32
+	copy(uuids[0:16], (*batch.TransactionID)[:])
+	copy(uuids[16:32], (*batch.BatchID)[:])
+	input := []interface{}{
+		batch.Namespace,
+		ethHexFormatB32(&uuids),
+		ethHexFormatB32(batch.BatchHash),
+		batch.BatchPayloadRef,
+		ethHashes,
+	}
+	return e.submitBatchPinTransaction(ctx, nsOpID, signingKey, input)
+}
+
+func (e *EthRPC) SubmitNetworkAction(ctx context.Context, nsOpID string, signingKey string, action core.NetworkActionType) error {
+	input := []interface{}{
+		blockchain.FireFlyActionPrefix + action,
+		ethHexFormatB32(nil),
+		ethHexFormatB32(nil),
+		"",
+		[]string{},
+	}
+	return e.submitBatchPinTransaction(ctx, nsOpID, signingKey, input)
+}
+
+func parseContractLocation(ctx context.Context, location *fftypes.JSONAny) (*ethtypes.Address0xHex, error) {
+	ethLocation := ethereum.Location{}
+	if err := json.Unmarshal(location.Bytes(), &ethLocation); err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractLocationInvalid, err)
+	}
+	if ethLocation.Address == "" {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractLocationInvalid, "'address' not set")
+	}
+	address, err := ethtypes.NewAddress(ethLocation.Address)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractLocationInvalid, err)
+	}
+	return address, nil
+}
+
+func (e *EthRPC) prepareRequest(ctx context.Context, method *core.FFIMethod, input map[string]interface{}) (*abi.Entry, []interface{}, error) {
+	orderedInput := make([]interface{}, len(method.Params))
+	abiEntry, err := e.abiConverter.FFIMethodToABI(ctx, method, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, ffiParam := range method.Params {
+		orderedInput[i] = input[ffiParam.Name]
+	}
+	return abiEntry, orderedInput, nil
+}
+
+func encodeCallData(ctx context.Context, abiEntry *abi.Entry, input []interface{}) ([]byte, error) {
+	cv, err := abiEntry.Inputs.ParseExternalData(input)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCEncodingFailed, abiEntry.Name, err)
+	}
+	data, err := abiEntry.EncodeCallData(cv)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCEncodingFailed, abiEntry.Name, err)
+	}
+	return data, nil
+}
+
+// revertError decodes the revert data returned by the node with a failed request, against the built-in
+// Solidity errors and the error definitions of the interface
+func (e *EthRPC) revertError(ctx context.Context, err error, ffiErrors []*core.FFIError) error {
+	var rpcErr *rpcCallError
+	if errors.As(err, &rpcErr) {
+		return e.abiConverter.RevertError(ctx, err, ffiErrors, rpcErr.data)
+	}
+	return err
+}
+
+func (e *EthRPC) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) error {
+	to, err := parseContractLocation(ctx, location)
+	if err != nil {
+		return err
+	}
+	abiEntry, orderedInput, err := e.prepareRequest(ctx, method, input)
+	if err != nil {
+		return err
+	}
+	if e.metrics.IsMetricsEnabled() {
+		e.metrics.BlockchainTransaction(to.String(), abiEntry.Name)
+	}
+	data, err := encodeCallData(ctx, abiEntry, orderedInput)
+	if err != nil {
+		return err
+	}
+	// A transaction that would revert is rejected by the node when estimating gas
+	return e.revertError(ctx, e.sendTransaction(ctx, nsOpID, signingKey, to, data, options), errors)
+}
+
+func (e *EthRPC) DeployContract(ctx context.Context, nsOpID string, signingKey string, definition *fftypes.JSONAny, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) error {
+	if contract == nil {
+		return i18n.NewError(ctx, coremsgs.MsgContractDeployMissingContract)
+	}
+	var bytecode ethtypes.HexBytes0xPrefix
+	if err := json.Unmarshal(contract.Bytes(), &bytecode); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgEthRPCEncodingFailed, "contract", err)
+	}
+	var abiDefinition abi.ABI
+	if definition != nil {
+		if err := json.Unmarshal(definition.Bytes(), &abiDefinition); err != nil {
+			return i18n.NewError(ctx, coremsgs.MsgEthRPCEncodingFailed, "definition", err)
+		}
+	}
+	data := []byte(bytecode)
+	for _, entry := range abiDefinition {
+		if entry.Type == "constructor" {
+			cv, err := entry.Inputs.ParseExternalData(input)
+			if err != nil {
+				return i18n.NewError(ctx, coremsgs.MsgEthRPCEncodingFailed, "constructor", err)
+			}
+			args, err := cv.EncodeABIData()
+			if err != nil {
+				return i18n.NewError(ctx, coremsgs.MsgEthRPCEncodingFailed, "constructor", err)
+			}
+			data = append(data, args...)
+		}
+	}
+	return e.sendTransaction(ctx, nsOpID, signingKey, nil, data, options)
+}
+
+func (e *EthRPC) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) (interface{}, error) {
+	to, err := parseContractLocation(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	abiEntry, orderedInput, err := e.prepareRequest(ctx, method, input)
+	if err != nil {
+		return nil, err
+	}
+	if e.metrics.IsMetricsEnabled() {
+		e.metrics.BlockchainQuery(to.String(), abiEntry.Name)
+	}
+	output, err := e.callContract(ctx, to, abiEntry, orderedInput, options)
+	if err != nil {
+		return nil, e.revertError(ctx, err, errors)
+	}
+	return output, nil
+}
+
+// callContract executes a method with eth_call, and returns the outputs as "output", "output1", "output2" etc.
+func (e *EthRPC) callContract(ctx context.Context, to *ethtypes.Address0xHex, abiEntry *abi.Entry, input []interface{}, options map[string]interface{}) (fftypes.JSONObject, error) {
+	data, err := encodeCallData(ctx, abiEntry, input)
+	if err != nil {
+		return nil, err
+	}
+	call := &txCall{
+		To:   to,
+		Data: data,
+	}
+	if from, ok := options["from"].(string); ok {
+		call.From = from
+	}
+	var result ethtypes.HexBytes0xPrefix
+	if err := e.callRPC(ctx, &result, "eth_call", call, "latest"); err != nil {
+		return nil, err
+	}
+	output := fftypes.JSONObject{}
+	if len(result) == 0 {
+		return output, nil
+	}
+	cv, err := abiEntry.Outputs.DecodeABIData(result, 0)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCDecodingFailed, abiEntry.Name, err)
+	}
+	b, err := abi.NewSerializer().
+		SetFormattingMode(abi.FormatAsFlatArrays).
+		SetByteSerializer(abi.HexByteSerializer0xPrefix).
+		SerializeJSON(cv)
+	var values []interface{}
+	if err == nil {
+		err = json.Unmarshal(b, &values)
+	}
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCDecodingFailed, abiEntry.Name, err)
+	}
+	for i, v := range values {
+		if i == 0 {
+			output["output"] = v
+		} else {
+			output[fmt.Sprintf("output%d", i)] = v
+		}
+	}
+	return output, nil
+}
+
+func (e *EthRPC) getNetworkVersion(ctx context.Context, address string) (int, error) {
+	to, err := ethtypes.NewAddress(address)
+	if err != nil {
+		return 0, err
+	}
+	output, err := e.callContract(ctx, to, ethereum.NetworkVersionMethodABI, []interface{}{}, nil)
+	if err != nil {
+		// A failed call is interpreted as "method does not exist, default to version 1"
+		if strings.Contains(err.Error(), "FF10472") {
+			return 1, nil
+		}
+		return 0, err
+	}
+	version, ok := output.GetStringOk("output")
+	if !ok {
+		return 1, nil
+	}
+	return strconv.Atoi(version)
+}
+
+func (e *EthRPC) NetworkVersion() int {
+	e.fireflyContract.mux.Lock()
+	defer e.fireflyContract.mux.Unlock()
+	return e.fireflyContract.networkVersion
+}
+
+func (e *EthRPC) NormalizeContractLocation(ctx context.Context, location *fftypes.JSONAny) (*fftypes.JSONAny, error) {
+	return e.abiConverter.NormalizeContractLocation(ctx, location)
+}
+
+func (e *EthRPC) GetFFIParamValidator(ctx context.Context) (core.FFIParamValidator, error) {
+	return e.abiConverter.GetFFIParamValidator(ctx)
+}
+
+func (e *EthRPC) GenerateFFI(ctx context.Context, generationRequest *core.FFIGenerationRequest) (*core.FFI, error) {
+	return e.abiConverter.GenerateFFI(ctx, generationRequest)
+}
+
+func (e *EthRPC) GenerateEventSignature(ctx context.Context, event *core.FFIEventDefinition) string {
+	return e.abiConverter.GenerateEventSignature(ctx, event)
+}
+
+func (e *EthRPC) GenerateErrorSignature(ctx context.Context, errorDef *core.FFIErrorDefinition) string {
+	return e.abiConverter.GenerateErrorSignature(ctx, errorDef)
+}
+
+func (e *EthRPC) DecodeOperationRevert(ctx context.Context, opOutput fftypes.JSONObject, errors []*core.FFIError) *core.ContractRevert {
+	// Transaction receipts do not include the revert data, which is only available by replaying the transaction
+	return nil
+}
@@ -0,0 +1,927 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
+	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfig = config.RootSection("ethrpc_unit_tests")
+var utRPCConf = utConfig.SubSection(RPCConfigKey)
+var utKeystoreConf = utConfig.SubSection(KeystoreConfigKey)
+var utEventsConf = utConfig.SubSection(EventsConfigKey)
+
+const testContractAddress = "0x71c7656ec7ab88b098defb751b7401b5f6d8976f"
+
+func testFFIMethod() *core.FFIMethod {
+	return &core.FFIMethod{
+		Name: "sum",
+		Params: []*core.FFIParam{
+			{
+				Name:   "x",
+				Schema: fftypes.JSONAnyPtr(`{"oneOf":[{"type":"string"},{"type":"integer"}],"details":{"type":"uint256"}}`),
+			},
+			{
+				Name:   "y",
+				Schema: fftypes.JSONAnyPtr(`{"oneOf":[{"type":"string"},{"type":"integer"}],"details":{"type":"uint256"}}`),
+			},
+		},
+		Returns: []*core.FFIParam{
+			{
+				Name:   "z",
+				Schema: fftypes.JSONAnyPtr(`{"oneOf":[{"type":"string"},{"type":"integer"}],"details":{"type":"uint256"}}`),
+			},
+		},
+	}
+}
+
+type rpcHandler func(params []interface{}) (interface{}, *rpcError)
+
+// mockRPC is a minimal JSON-RPC server, dispatching to a handler for each method
+type mockRPC struct {
+	t        *testing.T
+	mux      sync.Mutex
+	handlers map[string]rpcHandler
+	calls    map[string][][]interface{}
+}
+
+func newMockRPC(t *testing.T) *mockRPC {
+	return &mockRPC{
+		t:        t,
+		handlers: make(map[string]rpcHandler),
+		calls:    make(map[string][][]interface{}),
+	}
+}
+
+func (m *mockRPC) on(method string, handler rpcHandler) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.handlers[method] = handler
+}
+
+func (m *mockRPC) result(method string, result interface{}) {
+	m.on(method, func(params []interface{}) (interface{}, *rpcError) {
+		return result, nil
+	})
+}
+
+func (m *mockRPC) fail(method string, message string) {
+	m.on(method, func(params []interface{}) (interface{}, *rpcError) {
+		return nil, &rpcError{Code: -32000, Message: message}
+	})
+}
+
+func (m *mockRPC) revert(method string, data string) {
+	m.on(method, func(params []interface{}) (interface{}, *rpcError) {
+		return nil, &rpcError{Code: 3, Message: "execution reverted", Data: fftypes.JSONAnyPtr(`"` + data + `"`)}
+	})
+}
+
+func (m *mockRPC) getCalls(method string) [][]interface{} {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.calls[method]
+}
+
+func (m *mockRPC) respond(req *http.Request) (*http.Response, error) {
+	var rpcReq rpcRequest
+	err := json.NewDecoder(req.Body).Decode(&rpcReq)
+	assert.NoError(m.t, err)
+	assert.Equal(m.t, "2.0", rpcReq.JSONRPC)
+
+	m.mux.Lock()
+	m.calls[rpcReq.Method] = append(m.calls[rpcReq.Method], rpcReq.Params)
+	handler, ok := m.handlers[rpcReq.Method]
+	m.mux.Unlock()
+
+	rpcRes := &rpcResponse{JSONRPC: "2.0", ID: rpcReq.ID}
+	if !ok {
+		rpcRes.Error = &rpcError{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", rpcReq.Method)}
+	} else {
+		result, rpcErr := handler(rpcReq.Params)
+		if rpcErr != nil {
+			rpcRes.Error = rpcErr
+		} else {
+			b, _ := json.Marshal(result)
+			rpcRes.Result = fftypes.JSONAnyPtrBytes(b)
+		}
+	}
+	return httpmock.NewJsonResponse(200, rpcRes)
+}
+
+func newTestEthRPC(t *testing.T) (*EthRPC, *mockRPC, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mm := &metricsmocks.Manager{}
+	mm.On("IsMetricsEnabled").Return(true)
+	mm.On("BlockchainTransaction", mock.Anything, mock.Anything).Return(nil)
+	mm.On("BlockchainQuery", mock.Anything, mock.Anything).Return(nil)
+	e := &EthRPC{
+		ctx:             ctx,
+		capabilities:    &blockchain.Capabilities{},
+		client:          resty.New().SetBaseURL("http://localhost:12345"),
+		chainID:         1337,
+		keystore:        &keystore{keys: make(map[string]*secp256k1.KeyPair)},
+		nonces:          newNonceManager(),
+		abiConverter:    &ethereum.Ethereum{},
+		pollingInterval: time.Millisecond,
+		blockRange:      100,
+		state:           newPluginState(),
+		metrics:         mm,
+	}
+	rpc := newMockRPC(t)
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	httpmock.RegisterResponder("POST", "http://localhost:12345/", rpc.respond)
+	return e, rpc, func() {
+		cancel()
+		if e.closed != nil {
+			// We've started, wait to close
+			<-e.closed
+		}
+		httpmock.DeactivateAndReset()
+	}
+}
+
+func resetConf(e *EthRPC) {
+	coreconfig.Reset()
+	e.InitConfig(utConfig)
+}
+
+func initWithMockRPC(t *testing.T, e *EthRPC) *mockRPC {
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	rpc := newMockRPC(t)
+	httpmock.RegisterResponder("POST", "http://localhost:12345/", rpc.respond)
+	utRPCConf.Set(ffresty.HTTPConfigURL, "http://localhost:12345")
+	utRPCConf.Set(ffresty.HTTPCustomClient, mockedClient)
+	utEventsConf.Set(EventsConfigStateFile, filepath.Join(t.TempDir(), "state.json"))
+	return rpc
+}
+
+func addTestKey(t *testing.T, e *EthRPC) string {
+	keyPair, err := secp256k1.GenerateSecp256k1KeyPair()
+	assert.NoError(t, err)
+	address := keyPair.Address.String()
+	e.keystore.keys[address] = keyPair
+	return address
+}
+
+func TestName(t *testing.T) {
+	e := &EthRPC{}
+	assert.Equal(t, "ethrpc", e.Name())
+	assert.Equal(t, core.VerifierTypeEthAddress, e.VerifierType())
+}
+
+func TestInitMissingURL(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.Regexp(t, "FF10138.*url", err)
+}
+
+func TestInitAndStartChainIDFromNode(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	rpc := initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	rpc.result("eth_chainId", "0x539")
+	rpc.result("eth_blockNumber", "0x0")
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1337), e.chainID)
+	assert.Equal(t, uint64(defaultBlockRange), e.blockRange)
+	assert.Equal(t, time.Second, e.pollingInterval)
+	assert.NotNil(t, e.Capabilities())
+
+	err = e.Start()
+	assert.NoError(t, err)
+}
+
+func TestInitChainIDConfigured(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	rpc := initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	utRPCConf.Set(RPCConfigChainID, 2022)
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2022), e.chainID)
+	assert.Empty(t, rpc.getCalls("eth_chainId"))
+}
+
+func TestInitChainIDFail(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.Regexp(t, "FF10472.*eth_chainId", err)
+}
+
+func TestInitBadKeystore(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	utKeystoreConf.Set(KeystoreConfigPath, filepath.Join(t.TempDir(), "missing"))
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.Regexp(t, "FF10474", err)
+}
+
+func TestInitMissingStateFile(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	utEventsConf.Set(EventsConfigStateFile, "")
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.Regexp(t, "FF10138.*stateFile", err)
+}
+
+func TestInitBadStateFile(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	err := os.WriteFile(stateFile, []byte("!json"), 0600)
+	assert.NoError(t, err)
+	utEventsConf.Set(EventsConfigStateFile, stateFile)
+
+	err = e.Init(e.ctx, utConfig, e.metrics)
+	assert.Regexp(t, "FF10479", err)
+}
+
+func TestInitBadConfirmations(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	utEventsConf.Set(EventsConfigConfirmations, -1)
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.Regexp(t, "FF10482", err)
+}
+
+func TestCallRPCHTTPError(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewStringResponder(500, "pop"))
+
+	err := e.callRPC(e.ctx, nil, "eth_blockNumber")
+	assert.Regexp(t, "FF10471.*pop", err)
+}
+
+func TestCallRPCInvalidResult(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	rpc.result("eth_blockNumber", map[string]interface{}{"not": "a number"})
+
+	var head uint64
+	err := e.callRPC(e.ctx, &head, "eth_blockNumber")
+	assert.Regexp(t, "FF10473.*eth_blockNumber", err)
+}
+
+func mockNetworkVersion(rpc *mockRPC, version int) {
+	rpc.result("eth_call", fmt.Sprintf("0x%064x", version))
+}
+
+func TestConfigureContract(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	rpc := initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	rpc.result("eth_chainId", "0x539")
+	mockNetworkVersion(rpc, 2)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, "0x71C7656EC7ab88b098defB751B7401B5f6d8976F")
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.NoError(t, err)
+	contracts := &core.FireFlyContracts{}
+	err = e.ConfigureContract(e.ctx, contracts)
+	assert.NoError(t, err)
+
+	assert.Equal(t, testContractAddress, e.fireflyContract.address)
+	assert.Equal(t, 2, e.NetworkVersion())
+	assert.Equal(t, uint64(0), e.state.FireFlyCheckpoints[testContractAddress])
+	assert.Equal(t, testContractAddress, contracts.Active.Info.GetString("address"))
+	assert.Equal(t, "oldest", contracts.Active.Info.GetString("fromBlock"))
+	assert.Equal(t, testContractAddress, rpc.getCalls("eth_call")[0][0].(map[string]interface{})["to"])
+}
+
+func TestConfigureContractNewest(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	rpc := initWithMockRPC(t, e)
+	defer httpmock.DeactivateAndReset()
+	rpc.result("eth_chainId", "0x539")
+	rpc.result("eth_blockNumber", "0x64")
+	mockNetworkVersion(rpc, 2)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	utConfig.Set(FireFlyContractConfigKey+".0."+FireFlyContractFromBlock, "newest")
+
+	err := e.Init(e.ctx, utConfig, e.metrics)
+	assert.NoError(t, err)
+	err = e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(101), e.state.FireFlyCheckpoints[testContractAddress])
+}
+
+func TestConfigureContractExistingCheckpoint(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	e.state.FireFlyCheckpoints[testContractAddress] = 50
+	mockNetworkVersion(rpc, 2)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	utConfig.Set(FireFlyContractConfigKey+".0."+FireFlyContractFromBlock, "newest")
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), e.state.FireFlyCheckpoints[testContractAddress])
+	assert.Empty(t, rpc.getCalls("eth_blockNumber"))
+}
+
+func TestConfigureContractBadFromBlock(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	mockNetworkVersion(rpc, 2)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	utConfig.Set(FireFlyContractConfigKey+".0."+FireFlyContractFromBlock, "last")
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.Regexp(t, "FF10480.*last", err)
+}
+
+func TestConfigureContractNewestFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	mockNetworkVersion(rpc, 2)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	utConfig.Set(FireFlyContractConfigKey+".0."+FireFlyContractFromBlock, "newest")
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.Regexp(t, "FF10472.*eth_blockNumber", err)
+}
+
+func TestConfigureContractNetworkVersionReverted(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	rpc.fail("eth_call", "execution reverted")
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, e.NetworkVersion())
+}
+
+func TestConfigureContractNetworkVersionEmpty(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	rpc.result("eth_call", "0x")
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, e.NetworkVersion())
+}
+
+func TestConfigureContractNetworkVersionError(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewStringResponder(500, "pop"))
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.Regexp(t, "FF10471", err)
+}
+
+func TestConfigureContractBadIndex(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{
+		Active: core.FireFlyContractInfo{Index: 1},
+	})
+	assert.Regexp(t, "FF10396", err)
+}
+
+func TestConfigureContractMissingAddress(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, "")
+	e.InitConfig(utConfig)
+
+	err := e.ConfigureContract(e.ctx, &core.FireFlyContracts{})
+	assert.Regexp(t, "FF10138.*address", err)
+}
+
+func TestTerminateContract(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	resetConf(e)
+	mockNetworkVersion(rpc, 2)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".0."+FireFlyContractAddress, testContractAddress)
+	utConfig.AddKnownKey(FireFlyContractConfigKey+".1."+FireFlyContractAddress, "0x1C197604587F046FD40684A8f21f4609FB811A7b")
+	e.InitConfig(utConfig)
+	e.fireflyContract.address = testContractAddress
+
+	contracts := &core.FireFlyContracts{}
+	termination := &blockchain.Event{
+		ProtocolID: "000000000010/000020/000030",
+		Info: fftypes.JSONObject{
+			"address": "0x71C7656EC7ab88b098defB751B7401B5f6d8976F",
+		},
+	}
+	err := e.TerminateContract(e.ctx, contracts, termination)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, contracts.Active.Index)
+	assert.Equal(t, "0x1c197604587f046fd40684a8f21f4609fb811a7b", e.fireflyContract.address)
+	assert.Equal(t, 1, len(contracts.Terminated))
+	assert.Equal(t, "000000000010/000020/000030", contracts.Terminated[0].FinalEvent)
+}
+
+func TestTerminateContractOtherAddress(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+
+	contracts := &core.FireFlyContracts{}
+	termination := &blockchain.Event{
+		Info: fftypes.JSONObject{
+			"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		},
+	}
+	err := e.TerminateContract(e.ctx, contracts, termination)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, contracts.Active.Index)
+	assert.Equal(t, testContractAddress, e.fireflyContract.address)
+}
+
+func TestTerminateContractBadAddress(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	termination := &blockchain.Event{
+		Info: fftypes.JSONObject{
+			"address": "bad",
+		},
+	}
+	err := e.TerminateContract(e.ctx, &core.FireFlyContracts{}, termination)
+	assert.Regexp(t, "FF10141", err)
+}
+
+func mockSendTransaction(rpc *mockRPC) {
+	rpc.result("eth_getTransactionCount", "0x5")
+	rpc.result("eth_estimateGas", "0x5208")
+	rpc.result("eth_gasPrice", "0x3b9aca00")
+	rpc.result("eth_sendRawTransaction", "0x3fbb8ec4bb3bb41c5e9bd50dfa3f6e0a3d4e3e0c1da1ddabfa0a0bd4a6b0a2c9")
+}
+
+func TestSubmitBatchPin(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+
+	batch := &blockchain.BatchPin{
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+		Contexts: []*fftypes.Bytes32{
+			fftypes.NewRandB32(),
+			fftypes.NewRandB32(),
+		},
+	}
+	err := e.SubmitBatchPin(e.ctx, "ns1:"+fftypes.NewUUID().String(), signer, batch)
+	assert.NoError(t, err)
+
+	estimate := rpc.getCalls("eth_estimateGas")[0][0].(map[string]interface{})
+	assert.Equal(t, testContractAddress, estimate["to"])
+	assert.Equal(t, signer, estimate["from"])
+	assert.Len(t, rpc.getCalls("eth_sendRawTransaction"), 1)
+}
+
+func TestSubmitNetworkAction(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+
+	err := e.SubmitNetworkAction(e.ctx, "ns1:"+fftypes.NewUUID().String(), signer, core.NetworkActionTerminate)
+	assert.NoError(t, err)
+	assert.Len(t, rpc.getCalls("eth_sendRawTransaction"), 1)
+}
+
+func TestSubmitBatchPinNoContract(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+
+	err := e.SubmitNetworkAction(e.ctx, "ns1:"+fftypes.NewUUID().String(), signer, core.NetworkActionTerminate)
+	assert.Error(t, err)
+}
+
+func TestInvokeContract(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	params := map[string]interface{}{
+		"x": float64(1),
+		"y": float64(2),
+	}
+	err := e.InvokeContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), signer, location, testFFIMethod(), params, nil, nil)
+	assert.NoError(t, err)
+
+	estimate := rpc.getCalls("eth_estimateGas")[0][0].(map[string]interface{})
+	assert.Equal(t, testContractAddress, estimate["to"])
+	// sum(uint256,uint256) selector, followed by the encoded parameters
+	assert.Equal(t, "0xcad0899b"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000002", estimate["data"])
+}
+
+func TestInvokeContractBadLocation(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": "bad",
+	}.String())
+	err := e.InvokeContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", location, testFFIMethod(), map[string]interface{}{}, nil, nil)
+	assert.Regexp(t, "FF10310", err)
+}
+
+func TestInvokeContractReverted(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	// Error("insufficient funds")
+	rpc.revert("eth_estimateGas", "0x08c379a0"+
+		"0000000000000000000000000000000000000000000000000000000000000020"+
+		"0000000000000000000000000000000000000000000000000000000000000012"+
+		"696e73756666696369656e742066756e64730000000000000000000000000000")
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	params := map[string]interface{}{
+		"x": float64(1),
+		"y": float64(2),
+	}
+	err := e.InvokeContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), signer, location, testFFIMethod(), params, nil, nil)
+	assert.Regexp(t, "FF10489.*FF10472.*execution reverted", err)
+	revert := blockchain.GetRevert(err)
+	assert.Equal(t, "Error", revert.Error)
+	assert.Equal(t, "insufficient funds", revert.Params.GetString("message"))
+}
+
+func TestInvokeContractMissingAddress(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	location := fftypes.JSONAnyPtr(`{}`)
+	err := e.InvokeContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", location, testFFIMethod(), map[string]interface{}{}, nil, nil)
+	assert.Regexp(t, "FF10310.*address", err)
+}
+
+func TestInvokeContractBadJSONLocation(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	location := fftypes.JSONAnyPtr(`!json`)
+	err := e.InvokeContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", location, testFFIMethod(), map[string]interface{}{}, nil, nil)
+	assert.Regexp(t, "FF10310", err)
+}
+
+func TestInvokeContractBadInput(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	params := map[string]interface{}{
+		"x": "not a number",
+		"y": float64(2),
+	}
+	err := e.InvokeContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", location, testFFIMethod(), params, nil, nil)
+	assert.Regexp(t, "FF10476.*sum", err)
+}
+
+func TestInvokeContractBadMethod(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	method := &core.FFIMethod{
+		Name: "set",
+		Params: []*core.FFIParam{
+			{
+				Name:   "x",
+				Schema: fftypes.JSONAnyPtr(`{"type":"integer","detailz":{"type":"uint256"}}`),
+			},
+		},
+	}
+	err := e.InvokeContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", location, method, map[string]interface{}{}, nil, nil)
+	assert.Regexp(t, "compilation failed", err)
+}
+
+func TestDeployContract(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+
+	definition := fftypes.JSONAnyPtr(`[{"type":"constructor","inputs":[{"name":"initial","type":"uint256"}]}]`)
+	contract := fftypes.JSONAnyPtr(`"0x6080"`)
+	err := e.DeployContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), signer, definition, contract, []interface{}{"10"}, nil)
+	assert.NoError(t, err)
+
+	estimate := rpc.getCalls("eth_estimateGas")[0][0].(map[string]interface{})
+	assert.Nil(t, estimate["to"])
+	assert.Equal(t, "0x6080000000000000000000000000000000000000000000000000000000000000000a", estimate["data"])
+}
+
+func TestDeployContractNoConstructor(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+
+	contract := fftypes.JSONAnyPtr(`"0x6080"`)
+	err := e.DeployContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), signer, nil, contract, nil, nil)
+	assert.NoError(t, err)
+
+	estimate := rpc.getCalls("eth_estimateGas")[0][0].(map[string]interface{})
+	assert.Equal(t, "0x6080", estimate["data"])
+}
+
+func TestDeployContractMissingContract(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	err := e.DeployContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", nil, nil, nil, nil)
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestDeployContractBadContract(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	err := e.DeployContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", nil, fftypes.JSONAnyPtr(`"not hex"`), nil, nil)
+	assert.Regexp(t, "FF10476.*contract", err)
+}
+
+func TestDeployContractBadDefinition(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	err := e.DeployContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", fftypes.JSONAnyPtr(`{}`), fftypes.JSONAnyPtr(`"0x6080"`), nil, nil)
+	assert.Regexp(t, "FF10476.*definition", err)
+}
+
+func TestDeployContractBadInput(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	definition := fftypes.JSONAnyPtr(`[{"type":"constructor","inputs":[{"name":"initial","type":"uint256"}]}]`)
+	err := e.DeployContract(e.ctx, "ns1:"+fftypes.NewUUID().String(), "0x123", definition, fftypes.JSONAnyPtr(`"0x6080"`), []interface{}{"bad"}, nil)
+	assert.Regexp(t, "FF10476.*constructor", err)
+}
+
+func TestQueryContract(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	rpc.result("eth_call", fmt.Sprintf("0x%064x", 3))
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	params := map[string]interface{}{
+		"x": float64(1),
+		"y": float64(2),
+	}
+	result, err := e.QueryContract(e.ctx, location, testFFIMethod(), params, nil, map[string]interface{}{
+		"from": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.JSONObject{"output": "3"}, result)
+
+	call := rpc.getCalls("eth_call")[0]
+	assert.Equal(t, "0x1C197604587F046FD40684A8f21f4609FB811A7b", call[0].(map[string]interface{})["from"])
+	assert.Equal(t, "latest", call[1])
+}
+
+func TestQueryContractMultipleOutputs(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	rpc.result("eth_call", fmt.Sprintf("0x%064x%064x", 3, 4))
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	method := testFFIMethod()
+	method.Returns = append(method.Returns, method.Returns[0])
+	result, err := e.QueryContract(e.ctx, location, method, map[string]interface{}{"x": float64(1), "y": float64(2)}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.JSONObject{"output": "3", "output1": "4"}, result)
+}
+
+func TestQueryContractBadOutput(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	rpc.result("eth_call", "0x01")
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	_, err := e.QueryContract(e.ctx, location, testFFIMethod(), map[string]interface{}{"x": float64(1), "y": float64(2)}, nil, nil)
+	assert.Regexp(t, "FF10477.*sum", err)
+}
+
+func TestQueryContractCallFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	rpc.fail("eth_call", "execution reverted")
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	_, err := e.QueryContract(e.ctx, location, testFFIMethod(), map[string]interface{}{"x": float64(1), "y": float64(2)}, nil, nil)
+	assert.Regexp(t, "FF10472.*execution reverted", err)
+}
+
+func TestQueryContractReverted(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	// InsufficientBalance(1, 100)
+	rpc.revert("eth_call", "0xcf479181"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000064")
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	errors := []*core.FFIError{
+		{
+			FFIErrorDefinition: core.FFIErrorDefinition{
+				Name: "InsufficientBalance",
+				Params: core.FFIParams{
+					{Name: "available", Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`)},
+					{Name: "required", Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`)},
+				},
+			},
+		},
+	}
+	_, err := e.QueryContract(e.ctx, location, testFFIMethod(), map[string]interface{}{"x": float64(1), "y": float64(2)}, errors, nil)
+	assert.Regexp(t, "FF10489.*InsufficientBalance", err)
+	revert := blockchain.GetRevert(err)
+	assert.Equal(t, "InsufficientBalance", revert.Error)
+	assert.Equal(t, fftypes.JSONObject{"available": "1", "required": "100"}, revert.Params)
+}
+
+func TestQueryContractBadLocation(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	_, err := e.QueryContract(e.ctx, fftypes.JSONAnyPtr(`{}`), testFFIMethod(), map[string]interface{}{}, nil, nil)
+	assert.Regexp(t, "FF10310", err)
+}
+
+func TestQueryContractBadInput(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": testContractAddress,
+	}.String())
+	_, err := e.QueryContract(e.ctx, location, testFFIMethod(), map[string]interface{}{"x": "bad"}, nil, nil)
+	assert.Regexp(t, "FF10476", err)
+}
+
+func TestDelegatedMethods(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	key, err := e.NormalizeSigningKey(e.ctx, "0x71C7656EC7ab88b098defB751B7401B5f6d8976F")
+	assert.NoError(t, err)
+	assert.Equal(t, testContractAddress, key)
+
+	location, err := e.NormalizeContractLocation(e.ctx, fftypes.JSONAnyPtr(`{"address":"0x71C7656EC7ab88b098defB751B7401B5f6d8976F"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"address":"0x71c7656ec7ab88b098defb751b7401b5f6d8976f"}`, location.String())
+
+	validator, err := e.GetFFIParamValidator(e.ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, validator)
+
+	ffi, err := e.GenerateFFI(e.ctx, &core.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input:   fftypes.JSONAnyPtr(`{"abi":[{"name":"get","type":"function","inputs":[],"outputs":[{"name":"x","type":"uint256"}]}]}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "get", ffi.Methods[0].Name)
+
+	signature := e.GenerateEventSignature(e.ctx, &core.FFIEventDefinition{
+		Name: "Changed",
+		Params: core.FFIParams{
+			{
+				Name:   "value",
+				Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+			},
+		},
+	})
+	assert.Equal(t, "Changed(uint256)", signature)
+
+	signature = e.GenerateErrorSignature(e.ctx, &core.FFIErrorDefinition{
+		Name: "Unauthorized",
+		Params: core.FFIParams{
+			{
+				Name:   "account",
+				Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address"}}`),
+			},
+		},
+	})
+	assert.Equal(t, "Unauthorized(address)", signature)
+
+	assert.Nil(t, e.DecodeOperationRevert(e.ctx, fftypes.JSONObject{}, nil))
+}
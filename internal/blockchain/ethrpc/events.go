@@ -0,0 +1,489 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// logFilter is the filter object passed to eth_getLogs
+type logFilter struct {
+	FromBlock hexUint64     `json:"fromBlock"`
+	ToBlock   hexUint64     `json:"toBlock"`
+	Address   string        `json:"address"`
+	Topics    []interface{} `json:"topics,omitempty"`
+}
+
+type ethLog struct {
+	Address          string                      `json:"address"`
+	Topics           []ethtypes.HexBytes0xPrefix `json:"topics"`
+	Data             ethtypes.HexBytes0xPrefix   `json:"data"`
+	BlockNumber      hexUint64                   `json:"blockNumber"`
+	BlockHash        string                      `json:"blockHash"`
+	TransactionHash  string                      `json:"transactionHash"`
+	TransactionIndex hexUint64                   `json:"transactionIndex"`
+	LogIndex         hexUint64                   `json:"logIndex"`
+	Removed          bool                        `json:"removed"`
+}
+
+type blockHeader struct {
+	Timestamp hexUint64 `json:"timestamp"`
+}
+
+// logSubscription is a set of logs that are being polled - either the FireFly contract, or a contract listener
+type logSubscription struct {
+	id            string
+	firefly       bool
+	address       string
+	events        []*listenerEvent
+	confirmations uint64
+}
+
+func (sub *logSubscription) String() string {
+	if sub.firefly {
+		return fmt.Sprintf("FireFly contract %s", sub.address)
+	}
+	return fmt.Sprintf("listener %s", sub.id)
+}
+
+// matchedLog is a log returned for one of the events of a subscription
+type matchedLog struct {
+	event *abi.Entry
+	log   *ethLog
+}
+
+func (e *EthRPC) eventLoop() {
+	defer close(e.closed)
+	l := log.L(e.ctx).WithField("role", "event-loop")
+	ctx := log.WithLogger(e.ctx, l)
+	for {
+		if err := e.poll(ctx); err != nil {
+			l.Errorf("Polling failed: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			l.Debugf("Event loop exiting (context cancelled)")
+			return
+		case <-time.After(e.pollingInterval):
+		}
+	}
+}
+
+func (e *EthRPC) poll(ctx context.Context) error {
+	if err := e.checkReceipts(ctx); err != nil {
+		return err
+	}
+	var head hexUint64
+	if err := e.callRPC(ctx, &head, "eth_blockNumber"); err != nil {
+		return err
+	}
+	blockTimes := make(map[uint64]*fftypes.FFTime)
+	for _, sub := range e.getSubscriptions() {
+		if err := e.pollSubscription(ctx, sub, head.Uint64(), blockTimes); err != nil {
+			// The subscription is retried from its checkpoint on the next poll, without holding up the others
+			log.L(ctx).Errorf("Polling %s failed: %s", sub, err)
+		}
+	}
+	return nil
+}
+
+func (e *EthRPC) getSubscriptions() []*logSubscription {
+	var subs []*logSubscription
+	e.fireflyContract.mux.Lock()
+	fireflyAddress := e.fireflyContract.address
+	e.fireflyContract.mux.Unlock()
+	if fireflyAddress != "" {
+		subs = append(subs, &logSubscription{
+			firefly: true,
+			address: fireflyAddress,
+			events: []*listenerEvent{{
+				Event:  ethereum.BatchPinEventABI,
+				Topics: []interface{}{ethereum.EventTopic(ethereum.ABIMethodToSignature(ethereum.BatchPinEventABI)).String()},
+			}},
+			confirmations: e.confirmations,
+		})
+	}
+
+	e.stateMux.Lock()
+	defer e.stateMux.Unlock()
+	for _, l := range e.state.Listeners {
+		sub := &logSubscription{
+			id:            l.ID,
+			address:       l.Address,
+			events:        l.Events,
+			confirmations: e.confirmations,
+		}
+		if l.Confirmations != nil {
+			sub.confirmations = uint64(*l.Confirmations)
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func (e *EthRPC) getCheckpoint(sub *logSubscription) (uint64, bool) {
+	e.stateMux.Lock()
+	defer e.stateMux.Unlock()
+	if sub.firefly {
+		checkpoint, ok := e.state.FireFlyCheckpoints[sub.address]
+		return checkpoint, ok
+	}
+	l, ok := e.state.Listeners[sub.id]
+	if !ok {
+		return 0, false
+	}
+	return l.Checkpoint, true
+}
+
+func (e *EthRPC) setCheckpoint(ctx context.Context, sub *logSubscription, checkpoint uint64) error {
+	e.stateMux.Lock()
+	if sub.firefly {
+		e.state.FireFlyCheckpoints[sub.address] = checkpoint
+	} else if l, ok := e.state.Listeners[sub.id]; ok {
+		l.Checkpoint = checkpoint
+	}
+	e.stateMux.Unlock()
+	return e.saveState(ctx)
+}
+
+// pollSubscription queries the logs for a subscription from its checkpoint up to the last block
+// with the required number of confirmations, in ranges of at most blockRange blocks, and dispatches
+// each event in order. Each event of the subscription is queried separately, as the indexed
+// parameters being filtered might be in different positions.
+func (e *EthRPC) pollSubscription(ctx context.Context, sub *logSubscription, head uint64, blockTimes map[uint64]*fftypes.FFTime) error {
+	if head < sub.confirmations {
+		return nil // no confirmed blocks yet
+	}
+	confirmed := head - sub.confirmations
+	for {
+		from, ok := e.getCheckpoint(sub)
+		if !ok || from > confirmed {
+			return nil // deleted, or up to date
+		}
+		to := from + e.blockRange - 1
+		if to > confirmed {
+			to = confirmed
+		}
+		var matched []*matchedLog
+		for _, ev := range sub.events {
+			filter := &logFilter{
+				FromBlock: hexUint64(from),
+				ToBlock:   hexUint64(to),
+				Address:   sub.address,
+				Topics:    ev.Topics,
+			}
+			var logs []*ethLog
+			if err := e.callRPC(ctx, &logs, "eth_getLogs", filter); err != nil {
+				return err
+			}
+			for _, l := range logs {
+				matched = append(matched, &matchedLog{event: ev.Event, log: l})
+			}
+		}
+		if len(sub.events) > 1 {
+			sort.SliceStable(matched, func(i, j int) bool {
+				li, lj := matched[i].log, matched[j].log
+				if li.BlockNumber != lj.BlockNumber {
+					return li.BlockNumber < lj.BlockNumber
+				}
+				if li.TransactionIndex != lj.TransactionIndex {
+					return li.TransactionIndex < lj.TransactionIndex
+				}
+				return li.LogIndex < lj.LogIndex
+			})
+		}
+		for _, m := range matched {
+			if err := e.dispatchLog(ctx, sub, m.event, m.log, blockTimes); err != nil {
+				return err
+			}
+		}
+		if err := e.setCheckpoint(ctx, sub, to+1); err != nil {
+			return err
+		}
+	}
+}
+
+func (e *EthRPC) dispatchLog(ctx context.Context, sub *logSubscription, abiEvent *abi.Entry, l *ethLog, blockTimes map[uint64]*fftypes.FFTime) error {
+	if l.Removed {
+		return nil
+	}
+	event, err := e.buildEvent(ctx, sub, abiEvent, l, blockTimes)
+	if err != nil || event == nil {
+		return err
+	}
+	if sub.firefly {
+		return e.handleBatchPinEvent(ctx, event)
+	}
+	return e.callbacks.BlockchainEvent(&blockchain.EventWithSubscription{
+		Event:        *event,
+		Subscription: sub.id,
+	})
+}
+
+func (e *EthRPC) getBlockTimestamp(ctx context.Context, blockNumber uint64, blockTimes map[uint64]*fftypes.FFTime) (*fftypes.FFTime, error) {
+	if timestamp, ok := blockTimes[blockNumber]; ok {
+		return timestamp, nil
+	}
+	var block *blockHeader
+	if err := e.callRPC(ctx, &block, "eth_getBlockByNumber", hexUint64(blockNumber), false); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCInvalidResult, "eth_getBlockByNumber", "null")
+	}
+	timestamp := fftypes.UnixTime(int64(block.Timestamp.Uint64()))
+	blockTimes[blockNumber] = timestamp
+	return timestamp, nil
+}
+
+// buildEvent decodes a log into an event, in the same format as the ethereum plugin
+func (e *EthRPC) buildEvent(ctx context.Context, sub *logSubscription, abiEvent *abi.Entry, l *ethLog, blockTimes map[uint64]*fftypes.FFTime) (*blockchain.Event, error) {
+	signature := ethereum.ABIMethodToSignature(abiEvent)
+	cv, err := decodeEventData(ctx, abiEvent, l.Topics, l.Data)
+	var output fftypes.JSONObject
+	if err == nil {
+		var b []byte
+		b, err = abi.NewSerializer().
+			SetFormattingMode(abi.FormatAsObjects).
+			SetByteSerializer(abi.HexByteSerializer0xPrefix).
+			SerializeJSON(cv)
+		if err == nil {
+			err = json.Unmarshal(b, &output)
+		}
+	}
+	if err != nil {
+		log.L(ctx).Errorf("Blockchain event is not valid - failed to decode '%s' in tx %s: %s", signature, l.TransactionHash, err)
+		return nil, nil // move on
+	}
+
+	timestamp, err := e.getBlockTimestamp(ctx, l.BlockNumber.Uint64(), blockTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	info := fftypes.JSONObject{
+		"address":          l.Address,
+		"blockHash":        l.BlockHash,
+		"blockNumber":      strconv.FormatUint(l.BlockNumber.Uint64(), 10),
+		"logIndex":         strconv.FormatUint(l.LogIndex.Uint64(), 10),
+		"signature":        signature,
+		"timestamp":        timestamp.String(),
+		"transactionHash":  l.TransactionHash,
+		"transactionIndex": strconv.FormatUint(l.TransactionIndex.Uint64(), 10),
+	}
+	if sub.id != "" {
+		info["subId"] = sub.id
+	}
+	return &blockchain.Event{
+		BlockchainTXID: l.TransactionHash,
+		Source:         e.Name(),
+		Name:           abiEvent.Name,
+		ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", l.BlockNumber.Uint64(), l.TransactionIndex.Uint64(), l.LogIndex.Uint64()),
+		Output:         output,
+		Info:           info,
+		Timestamp:      timestamp,
+		Location:       fmt.Sprintf("address=%s", l.Address),
+		Signature:      signature,
+	}, nil
+}
+
+// decodeEventData decodes the indexed parameters of an event from the topics of a log (after the
+// event signature), and the other parameters from its data. Only the hash of the value of a dynamic
+// type is stored in a topic, so those parameters are decoded as bytes32
+func decodeEventData(ctx context.Context, abiEvent *abi.Entry, topics []ethtypes.HexBytes0xPrefix, data ethtypes.HexBytes0xPrefix) (*abi.ComponentValue, error) {
+	tc, err := abiEvent.Inputs.TypeComponentTreeCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var dataParams abi.ParameterArray
+	for _, param := range abiEvent.Inputs {
+		if !param.Indexed {
+			dataParams = append(dataParams, param)
+		}
+	}
+	dataValues, err := dataParams.DecodeABIDataCtx(ctx, data, 0)
+	if err != nil {
+		return nil, err
+	}
+	cv := &abi.ComponentValue{
+		Component: tc,
+		Children:  make([]*abi.ComponentValue, len(abiEvent.Inputs)),
+	}
+	topicIdx, dataIdx := 1, 0
+	for i, param := range abiEvent.Inputs {
+		if !param.Indexed {
+			cv.Children[i] = dataValues.Children[dataIdx]
+			dataIdx++
+			continue
+		}
+		var topic []byte // a missing topic fails to decode
+		if topicIdx < len(topics) {
+			topic = topics[topicIdx]
+		}
+		topicParam := param
+		if ptc := tc.TupleChildren()[i]; ptc.ComponentType() != abi.ElementaryComponent || ptc.String() == "string" || ptc.String() == "bytes" {
+			topicParam = &abi.Parameter{Name: param.Name, Type: "bytes32"}
+		}
+		topicValue, err := abi.ParameterArray{topicParam}.DecodeABIDataCtx(ctx, topic, 0)
+		if err != nil {
+			return nil, err
+		}
+		cv.Children[i] = topicValue.Children[0]
+		topicIdx++
+	}
+	return cv, nil
+}
+
+func (e *EthRPC) handleBatchPinEvent(ctx context.Context, event *blockchain.Event) (err error) {
+	authorAddress := event.Output.GetString("author")
+	nsOrAction := event.Output.GetString("namespace")
+	sUUIDs := event.Output.GetString("uuids")
+	sBatchHash := event.Output.GetString("batchHash")
+	sPayloadRef := event.Output.GetString("payloadRef")
+	sContexts := event.Output.GetStringArray("contexts")
+
+	if authorAddress == "" || sUUIDs == "" || sBatchHash == "" {
+		log.L(ctx).Errorf("BatchPin event is not valid - missing data: %+v", event.Output)
+		return nil // move on
+	}
+
+	authorAddress, err = e.NormalizeSigningKey(ctx, authorAddress)
+	if err != nil {
+		log.L(ctx).Errorf("BatchPin event is not valid - bad from address (%s): %+v", err, event.Output)
+		return nil // move on
+	}
+	verifier := &core.VerifierRef{
+		Type:  core.VerifierTypeEthAddress,
+		Value: authorAddress,
+	}
+
+	// Check if this is actually an operator action
+	if strings.HasPrefix(nsOrAction, blockchain.FireFlyActionPrefix) {
+		action := nsOrAction[len(blockchain.FireFlyActionPrefix):]
+		return e.callbacks.BlockchainNetworkAction(action, event, verifier)
+	}
+
+	hexUUIDs, err := hex.DecodeString(strings.TrimPrefix(sUUIDs, "0x"))
+	if err != nil || len(hexUUIDs) != 32 {
+		log.L(ctx).Errorf("BatchPin event is not valid - bad uuids (%s): %+v", err, event.Output)
+		return nil // move on
+	}
+	var txnID fftypes.UUID
+	copy(txnID[:], hexUUIDs[0:16])
+	var batchID fftypes.UUID
+	copy(batchID[:], hexUUIDs[16:32])
+
+	var batchHash fftypes.Bytes32
+	err = batchHash.UnmarshalText([]byte(sBatchHash))
+	if err != nil {
+		log.L(ctx).Errorf("BatchPin event is not valid - bad batchHash (%s): %+v", err, event.Output)
+		return nil // move on
+	}
+
+	contexts := make([]*fftypes.Bytes32, len(sContexts))
+	for i, sHash := range sContexts {
+		var hash fftypes.Bytes32
+		err = hash.UnmarshalText([]byte(sHash))
+		if err != nil {
+			log.L(ctx).Errorf("BatchPin event is not valid - bad pin %d (%s): %+v", i, err, event.Output)
+			return nil // move on
+		}
+		contexts[i] = &hash
+	}
+
+	batch := &blockchain.BatchPin{
+		Namespace:       nsOrAction,
+		TransactionID:   &txnID,
+		BatchID:         &batchID,
+		BatchHash:       &batchHash,
+		BatchPayloadRef: sPayloadRef,
+		Contexts:        contexts,
+		Event:           *event,
+	}
+
+	// If there's an error dispatching the event, we must return the error and shutdown
+	return e.callbacks.BatchPinComplete(batch, verifier)
+}
+
+func (e *EthRPC) AddContractListener(ctx context.Context, subscription *core.ContractListenerInput) error {
+	address, err := parseContractLocation(ctx, subscription.Location)
+	if err != nil {
+		return err
+	}
+	var filters fftypes.JSONObject
+	firstEvent := ""
+	if subscription.Options != nil {
+		filters = subscription.Options.Filters
+		firstEvent = subscription.Options.FirstEvent
+	}
+	events := subscription.AllEvents()
+	listenerEvents := make([]*listenerEvent, len(events))
+	for i, event := range events {
+		abiEntry, err := e.abiConverter.FFIEventDefinitionToABI(ctx, &event.FFIEventDefinition)
+		if err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+		}
+		topics, err := ethereum.EventFilterTopics(ctx, abiEntry, filters)
+		if err != nil {
+			return err
+		}
+		listenerEvents[i] = &listenerEvent{Event: abiEntry, Topics: topics}
+	}
+	checkpoint, err := e.resolveFromBlock(ctx, firstEvent)
+	if err != nil {
+		return err
+	}
+
+	subscription.BackendID = subscription.ID.String()
+	e.stateMux.Lock()
+	l := &listener{
+		ID:         subscription.BackendID,
+		Address:    address.String(),
+		Events:     listenerEvents,
+		Checkpoint: checkpoint,
+	}
+	if subscription.Options != nil {
+		l.Confirmations = subscription.Options.Confirmations
+	}
+	e.state.Listeners[subscription.BackendID] = l
+	e.stateMux.Unlock()
+	return e.saveState(ctx)
+}
+
+func (e *EthRPC) DeleteContractListener(ctx context.Context, subscription *core.ContractListener) error {
+	e.stateMux.Lock()
+	delete(e.state.Listeners, subscription.BackendID)
+	e.stateMux.Unlock()
+	return e.saveState(ctx)
+}
@@ -0,0 +1,665 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testAuthor = "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635"
+
+func encodeTestLog(t *testing.T, event *abi.Entry, blockNumber, logIndex int, input []interface{}) map[string]interface{} {
+	cv, err := event.Inputs.ParseExternalData(input)
+	assert.NoError(t, err)
+	data, err := cv.EncodeABIData()
+	assert.NoError(t, err)
+	return map[string]interface{}{
+		"address":          testContractAddress,
+		"topics":           []string{ethereum.EventTopic(ethereum.ABIMethodToSignature(event)).String()},
+		"data":             "0x" + hex.EncodeToString(data),
+		"blockNumber":      fmt.Sprintf("0x%x", blockNumber),
+		"blockHash":        fmt.Sprintf("0x%064x", blockNumber),
+		"transactionHash":  fmt.Sprintf("0x%064x", 1000+blockNumber),
+		"transactionIndex": "0x1",
+		"logIndex":         fmt.Sprintf("0x%x", logIndex),
+	}
+}
+
+func testBatchPinLog(t *testing.T, namespace string, txID, batchID *fftypes.UUID, batchHash *fftypes.Bytes32) map[string]interface{} {
+	var uuids fftypes.Bytes32
+	copy(uuids[0:16], (*txID)[:])
+	copy(uuids[16:32], (*batchID)[:])
+	return encodeTestLog(t, ethereum.BatchPinEventABI, 10, 2, []interface{}{
+		testAuthor,
+		"1620576488",
+		namespace,
+		ethHexFormatB32(&uuids),
+		ethHexFormatB32(batchHash),
+		"Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+		[]string{ethHexFormatB32(batchHash)},
+	})
+}
+
+func testChangedEvent() *core.FFIEventDefinition {
+	return &core.FFIEventDefinition{
+		Name: "Changed",
+		Params: core.FFIParams{
+			{
+				Name:   "value",
+				Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+			},
+		},
+	}
+}
+
+func testContractListener() *core.ContractListenerInput {
+	return &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			ID: fftypes.NewUUID(),
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": testContractAddress,
+			}.String()),
+			Event: &core.FFISerializedEvent{
+				FFIEventDefinition: *testChangedEvent(),
+			},
+			Options: &core.ContractListenerOptions{
+				FirstEvent: string(core.SubOptsFirstEventOldest),
+			},
+		},
+	}
+}
+
+func mockBlock(rpc *mockRPC) {
+	rpc.result("eth_getBlockByNumber", map[string]interface{}{
+		"timestamp": "0x6097ac68",
+	})
+}
+
+func TestPollBatchPin(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 5
+
+	txID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	batchHash := fftypes.NewRandB32()
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{testBatchPinLog(t, "ns1", txID, batchID, batchHash)})
+	mockBlock(rpc)
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BatchPinComplete", mock.MatchedBy(func(batch *blockchain.BatchPin) bool {
+		return batch.Namespace == "ns1" &&
+			batch.TransactionID.Equals(txID) &&
+			batch.BatchID.Equals(batchID) &&
+			batch.BatchHash.Equals(batchHash) &&
+			batch.BatchPayloadRef == "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD" &&
+			len(batch.Contexts) == 1 &&
+			batch.Event.ProtocolID == "000000000010/000001/000002" &&
+			batch.Event.Name == "BatchPin" &&
+			batch.Event.Source == "ethrpc" &&
+			batch.Event.Location == "address="+testContractAddress &&
+			batch.Event.Signature == "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])" &&
+			batch.Event.Timestamp.UnixNano() == int64(1620552808)*1e9 &&
+			batch.Event.Info.GetString("blockNumber") == "10"
+	}), &core.VerifierRef{
+		Type:  core.VerifierTypeEthAddress,
+		Value: testAuthor,
+	}).Return(nil)
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(17), e.state.FireFlyCheckpoints[testContractAddress])
+
+	filter := rpc.getCalls("eth_getLogs")[0][0].(map[string]interface{})
+	assert.Equal(t, "0x5", filter["fromBlock"])
+	assert.Equal(t, "0x10", filter["toBlock"])
+	assert.Equal(t, testContractAddress, filter["address"])
+	assert.Equal(t, []interface{}{ethereum.EventTopic(ethereum.ABIMethodToSignature(ethereum.BatchPinEventABI)).String()}, filter["topics"])
+
+	cb.AssertExpectations(t)
+}
+
+func TestPollNetworkAction(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 0
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{testBatchPinLog(t, "firefly:terminate", &fftypes.UUID{}, &fftypes.UUID{}, &fftypes.Bytes32{})})
+	mockBlock(rpc)
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BlockchainNetworkAction", "terminate", mock.AnythingOfType("*blockchain.Event"), &core.VerifierRef{
+		Type:  core.VerifierTypeEthAddress,
+		Value: testAuthor,
+	}).Return(nil)
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+
+	cb.AssertExpectations(t)
+}
+
+func TestPollBatchPinCallbackFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 5
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{testBatchPinLog(t, "ns1", fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewRandB32())})
+	mockBlock(rpc)
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BatchPinComplete", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := e.pollSubscription(e.ctx, e.getSubscriptions()[0], 0x10, make(map[uint64]*fftypes.FFTime))
+	assert.EqualError(t, err, "pop")
+	// The checkpoint is not moved, so the events are redelivered
+	assert.Equal(t, uint64(5), e.state.FireFlyCheckpoints[testContractAddress])
+}
+
+func TestPollBlockRanges(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 0
+
+	rpc.result("eth_blockNumber", "0xfa")
+	rpc.result("eth_getLogs", []interface{}{})
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(251), e.state.FireFlyCheckpoints[testContractAddress])
+
+	calls := rpc.getCalls("eth_getLogs")
+	assert.Len(t, calls, 3)
+	assert.Equal(t, "0x63", calls[0][0].(map[string]interface{})["toBlock"])
+	assert.Equal(t, "0x64", calls[1][0].(map[string]interface{})["fromBlock"])
+	assert.Equal(t, "0xfa", calls[2][0].(map[string]interface{})["toBlock"])
+}
+
+func TestPollUpToDate(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 17
+
+	rpc.result("eth_blockNumber", "0x10")
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, rpc.getCalls("eth_getLogs"))
+}
+
+func TestPollConfirmations(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.confirmations = 6
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 0
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{})
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(11), e.state.FireFlyCheckpoints[testContractAddress])
+
+	calls := rpc.getCalls("eth_getLogs")
+	assert.Len(t, calls, 1)
+	assert.Equal(t, "0xa", calls[0][0].(map[string]interface{})["toBlock"])
+}
+
+func TestPollNotEnoughConfirmations(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.confirmations = 20
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 0
+
+	rpc.result("eth_blockNumber", "0x10")
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, rpc.getCalls("eth_getLogs"))
+}
+
+func TestPollSkipsBadAndRemovedLogs(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 0
+
+	removed := testBatchPinLog(t, "ns1", fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewRandB32())
+	removed["removed"] = true
+	badData := testBatchPinLog(t, "ns1", fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewRandB32())
+	badData["data"] = "0x01"
+	valid := testBatchPinLog(t, "ns1", fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewRandB32())
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{removed, badData, valid})
+	mockBlock(rpc)
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BatchPinComplete", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(17), e.state.FireFlyCheckpoints[testContractAddress])
+
+	cb.AssertExpectations(t)
+}
+
+func TestPollGetLogsFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 5
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.fail("eth_getLogs", "pop")
+
+	err := e.pollSubscription(e.ctx, e.getSubscriptions()[0], 0x10, make(map[uint64]*fftypes.FFTime))
+	assert.Regexp(t, "FF10472.*pop", err)
+	assert.Equal(t, uint64(5), e.state.FireFlyCheckpoints[testContractAddress])
+}
+
+func TestPollGetBlockFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 5
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{testBatchPinLog(t, "ns1", fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewRandB32())})
+	rpc.fail("eth_getBlockByNumber", "pop")
+
+	err := e.pollSubscription(e.ctx, e.getSubscriptions()[0], 0x10, make(map[uint64]*fftypes.FFTime))
+	assert.Regexp(t, "FF10472.*pop", err)
+}
+
+func TestPollGetBlockNotFound(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 5
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{testBatchPinLog(t, "ns1", fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewRandB32())})
+	rpc.result("eth_getBlockByNumber", nil)
+
+	err := e.pollSubscription(e.ctx, e.getSubscriptions()[0], 0x10, make(map[uint64]*fftypes.FFTime))
+	assert.Regexp(t, "FF10473.*eth_getBlockByNumber", err)
+}
+
+func TestPollContinuesAfterSubscriptionFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.fireflyContract.address = testContractAddress
+	e.state.FireFlyCheckpoints[testContractAddress] = 5
+	listenerAddress := "0x2222222222222222222222222222222222222222"
+	e.state.Listeners["sub1"] = &listener{
+		ID:      "sub1",
+		Address: listenerAddress,
+		Events:  []*listenerEvent{{Event: ethereum.BatchPinEventABI}},
+	}
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.on("eth_getLogs", func(params []interface{}) (interface{}, *rpcError) {
+		if params[0].(map[string]interface{})["address"] == testContractAddress {
+			return nil, &rpcError{Code: -32000, Message: "pop"}
+		}
+		return []interface{}{}, nil
+	})
+
+	err := e.poll(e.ctx)
+	assert.NoError(t, err)
+	// The FireFly contract is retried from its checkpoint, while the listener has moved on
+	assert.Equal(t, uint64(5), e.state.FireFlyCheckpoints[testContractAddress])
+	assert.Equal(t, uint64(17), e.state.Listeners["sub1"].Checkpoint)
+}
+
+func TestPollBlockNumberFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	rpc.fail("eth_blockNumber", "pop")
+
+	err := e.poll(e.ctx)
+	assert.Regexp(t, "FF10472.*pop", err)
+}
+
+func TestPollReceiptsFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	txHash := fmt.Sprintf("0x%064x", 1)
+	e.state.Pending[txHash] = &pendingTransaction{NsOpID: "ns1:op1", TxHash: txHash}
+	rpc.fail("eth_getTransactionReceipt", "pop")
+
+	err := e.poll(e.ctx)
+	assert.Regexp(t, "FF10472.*pop", err)
+}
+
+func TestContractListener(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.stateFile = filepath.Join(t.TempDir(), "state.json")
+
+	sub := testContractListener()
+	err := e.AddContractListener(e.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, sub.ID.String(), sub.BackendID)
+
+	abiEvent, err := e.abiConverter.FFIEventDefinitionToABI(e.ctx, testChangedEvent())
+	assert.NoError(t, err)
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{encodeTestLog(t, abiEvent, 12, 0, []interface{}{"42"})})
+	mockBlock(rpc)
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BlockchainEvent", mock.MatchedBy(func(event *blockchain.EventWithSubscription) bool {
+		return event.Subscription == sub.BackendID &&
+			event.Name == "Changed" &&
+			event.Output.GetString("value") == "42" &&
+			event.Info.GetString("subId") == sub.BackendID &&
+			event.ProtocolID == "000000000012/000001/000000"
+	})).Return(nil)
+
+	err = e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(17), e.state.Listeners[sub.BackendID].Checkpoint)
+
+	// A restarted plugin resumes from the persisted checkpoint
+	e2 := &EthRPC{stateFile: e.stateFile}
+	err = e2.loadState(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(17), e2.state.Listeners[sub.BackendID].Checkpoint)
+	assert.Equal(t, "Changed", e2.state.Listeners[sub.BackendID].Events[0].Event.Name)
+
+	err = e.DeleteContractListener(e.ctx, &sub.ContractListener)
+	assert.NoError(t, err)
+	assert.Empty(t, e.state.Listeners)
+
+	cb.AssertExpectations(t)
+}
+
+func TestContractListenerMultipleEventsWithFilters(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	ownerSchema := fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address", "indexed": true}}`)
+	sub := testContractListener()
+	sub.Event = nil
+	sub.Events = core.FFISerializedEvents{
+		{
+			FFIEventDefinition: core.FFIEventDefinition{
+				Name: "Changed",
+				Params: core.FFIParams{
+					{Name: "owner", Schema: ownerSchema},
+					{Name: "value", Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`)},
+				},
+			},
+		},
+		{
+			FFIEventDefinition: core.FFIEventDefinition{
+				Name: "Cleared",
+				Params: core.FFIParams{
+					{Name: "owner", Schema: ownerSchema},
+				},
+			},
+		},
+	}
+	sub.Options.Filters = fftypes.JSONObject{"owner": testAuthor}
+	err := e.AddContractListener(e.ctx, sub)
+	assert.NoError(t, err)
+
+	changedTopic := ethereum.EventTopic("Changed(address,uint256)").String()
+	clearedTopic := ethereum.EventTopic("Cleared(address)").String()
+	ownerTopic := "0x000000000000000000000000" + testAuthor[2:]
+	testLog := func(topic string, blockNumber int, data string) map[string]interface{} {
+		return map[string]interface{}{
+			"address":          testContractAddress,
+			"topics":           []string{topic, ownerTopic},
+			"data":             data,
+			"blockNumber":      fmt.Sprintf("0x%x", blockNumber),
+			"blockHash":        fmt.Sprintf("0x%064x", blockNumber),
+			"transactionHash":  fmt.Sprintf("0x%064x", 1000+blockNumber),
+			"transactionIndex": "0x0",
+			"logIndex":         "0x0",
+		}
+	}
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.on("eth_getLogs", func(params []interface{}) (interface{}, *rpcError) {
+		topics := params[0].(map[string]interface{})["topics"].([]interface{})
+		if topics[0] == changedTopic {
+			return []interface{}{testLog(changedTopic, 12, fmt.Sprintf("0x%064x", 42))}, nil
+		}
+		return []interface{}{testLog(clearedTopic, 11, "0x")}, nil
+	})
+	mockBlock(rpc)
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BlockchainEvent", mock.MatchedBy(func(event *blockchain.EventWithSubscription) bool {
+		return event.Subscription == sub.BackendID
+	})).Return(nil).Twice()
+
+	err = e.poll(e.ctx)
+	assert.NoError(t, err)
+
+	calls := rpc.getCalls("eth_getLogs")
+	assert.Len(t, calls, 2)
+	assert.Equal(t, []interface{}{changedTopic, ownerTopic}, calls[0][0].(map[string]interface{})["topics"])
+	assert.Equal(t, []interface{}{clearedTopic, ownerTopic}, calls[1][0].(map[string]interface{})["topics"])
+
+	// Events are delivered in the order they occurred on chain, across all the events of the listener
+	assert.Len(t, cb.Calls, 2)
+	first := cb.Calls[0].Arguments[0].(*blockchain.EventWithSubscription)
+	assert.Equal(t, "Cleared", first.Name)
+	assert.Equal(t, "Cleared(address)", first.Signature)
+	assert.Equal(t, testAuthor, first.Output.GetString("owner"))
+	second := cb.Calls[1].Arguments[0].(*blockchain.EventWithSubscription)
+	assert.Equal(t, "Changed", second.Name)
+	assert.Equal(t, "42", second.Output.GetString("value"))
+
+	cb.AssertExpectations(t)
+}
+
+func TestContractListenerFilterNotIndexed(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	sub := testContractListener()
+	sub.Options.Filters = fftypes.JSONObject{"value": "42"}
+	err := e.AddContractListener(e.ctx, sub)
+	assert.Regexp(t, "FF10485", err)
+}
+
+func TestContractListenerConfirmations(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	confirmations := 2
+	sub := testContractListener()
+	sub.Options.Confirmations = &confirmations
+	err := e.AddContractListener(e.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *e.state.Listeners[sub.BackendID].Confirmations)
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{})
+
+	err = e.poll(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(15), e.state.Listeners[sub.BackendID].Checkpoint)
+
+	calls := rpc.getCalls("eth_getLogs")
+	assert.Len(t, calls, 1)
+	assert.Equal(t, "0xe", calls[0][0].(map[string]interface{})["toBlock"])
+}
+
+func TestContractListenerNewest(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	rpc.result("eth_blockNumber", "0x10")
+
+	sub := testContractListener()
+	sub.Options.FirstEvent = string(core.SubOptsFirstEventNewest)
+	err := e.AddContractListener(e.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(17), e.state.Listeners[sub.BackendID].Checkpoint)
+}
+
+func TestContractListenerBlockNumber(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	sub := testContractListener()
+	sub.Options.FirstEvent = "1000"
+	err := e.AddContractListener(e.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), e.state.Listeners[sub.BackendID].Checkpoint)
+}
+
+func TestContractListenerBadFirstEvent(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	sub := testContractListener()
+	sub.Options.FirstEvent = "latest"
+	err := e.AddContractListener(e.ctx, sub)
+	assert.Regexp(t, "FF10480.*latest", err)
+}
+
+func TestContractListenerBadLocation(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	sub := testContractListener()
+	sub.Location = fftypes.JSONAnyPtr(`{}`)
+	err := e.AddContractListener(e.ctx, sub)
+	assert.Regexp(t, "FF10310", err)
+}
+
+func TestContractListenerBadEvent(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	sub := testContractListener()
+	sub.Event.Params[0].Schema = fftypes.JSONAnyPtr(`{"type": "integer", "detailz": {"type": "uint256"}}`)
+	err := e.AddContractListener(e.ctx, sub)
+	assert.Regexp(t, "FF10311", err)
+}
+
+func TestContractListenerAnonymous(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	sub := testContractListener()
+	sub.Event.Details = fftypes.JSONObject{"anonymous": true}
+	err := e.AddContractListener(e.ctx, sub)
+	assert.NoError(t, err)
+
+	rpc.result("eth_blockNumber", "0x10")
+	rpc.result("eth_getLogs", []interface{}{})
+	err = e.poll(e.ctx)
+	assert.NoError(t, err)
+
+	filter := rpc.getCalls("eth_getLogs")[0][0].(map[string]interface{})
+	assert.Nil(t, filter["topics"])
+}
+
+func TestDecodeEventDataIndexed(t *testing.T) {
+	event := &abi.Entry{
+		Type: abi.Event,
+		Name: "Changed",
+		Inputs: abi.ParameterArray{
+			{Name: "owner", Type: "address", Indexed: true},
+			{Name: "name", Type: "string", Indexed: true},
+			{Name: "value", Type: "uint256"},
+		},
+	}
+	data, _ := hex.DecodeString("000000000000000000000000000000000000000000000000000000000000000c")
+	owner, _ := hex.DecodeString("000000000000000000000000" + testAuthor[2:])
+	nameHash, _ := hex.DecodeString("1111111111111111111111111111111111111111111111111111111111111111")
+	topics := []ethtypes.HexBytes0xPrefix{{}, owner, nameHash}
+
+	cv, err := decodeEventData(context.Background(), event, topics, data)
+	assert.NoError(t, err)
+	output, err := abi.NewSerializer().
+		SetFormattingMode(abi.FormatAsObjects).
+		SetByteSerializer(abi.HexByteSerializer0xPrefix).
+		SerializeJSON(cv)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"owner": "`+testAuthor+`",
+		"name": "0x1111111111111111111111111111111111111111111111111111111111111111",
+		"value": "12"
+	}`, string(output))
+
+	_, err = decodeEventData(context.Background(), event, topics[0:2], data)
+	assert.Error(t, err)
+}
+
+func TestLoadStateMissingFile(t *testing.T) {
+	e := &EthRPC{stateFile: filepath.Join(t.TempDir(), "state.json")}
+	err := e.loadState(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, e.state.Listeners)
+}
+
+func TestLoadStateEmptyObject(t *testing.T) {
+	e := &EthRPC{stateFile: filepath.Join(t.TempDir(), "state.json")}
+	err := os.WriteFile(e.stateFile, []byte(`{}`), 0600)
+	assert.NoError(t, err)
+	err = e.loadState(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, e.state.FireFlyCheckpoints)
+	assert.NotNil(t, e.state.Listeners)
+	assert.NotNil(t, e.state.Pending)
+}
+
+func TestSaveStateFail(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.stateFile = filepath.Join(t.TempDir(), "missing", "state.json")
+
+	err := e.DeleteContractListener(e.ctx, &core.ContractListener{BackendID: "sub1"})
+	assert.Regexp(t, "FF10479", err)
+}
@@ -0,0 +1,103 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/keystorev3"
+	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// keystore holds the decrypted signing keys loaded from a directory of V3 keystore files
+type keystore struct {
+	keys map[string]*secp256k1.KeyPair
+}
+
+func loadKeystore(ctx context.Context, path, passwordFile string) (*keystore, error) {
+	ks := &keystore{
+		keys: make(map[string]*secp256k1.KeyPair),
+	}
+	if path == "" {
+		log.L(ctx).Warnf("No keystore configured - this node will not be able to submit transactions")
+		return ks, nil
+	}
+
+	var password []byte
+	if passwordFile != "" {
+		b, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgKeystoreReadFailed, passwordFile, err)
+		}
+		password = []byte(strings.TrimSpace(string(b)))
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgKeystoreReadFailed, path, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		filename := filepath.Join(path, entry.Name())
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgKeystoreReadFailed, filename, err)
+		}
+		if !isKeystoreFile(b) {
+			log.L(ctx).Debugf("Skipping non-keystore file %s", filename)
+			continue
+		}
+		wallet, err := keystorev3.ReadWalletFile(b, password)
+		if err != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgKeystoreReadFailed, filename, err)
+		}
+		keyPair := wallet.KeyPair()
+		address := strings.ToLower(keyPair.Address.String())
+		ks.keys[address] = keyPair
+		log.L(ctx).Infof("Loaded signing key %s from %s", address, filename)
+	}
+	return ks, nil
+}
+
+func isKeystoreFile(b []byte) bool {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return false
+	}
+	_, hasCrypto := fields["crypto"]
+	if !hasCrypto {
+		_, hasCrypto = fields["Crypto"]
+	}
+	return hasCrypto
+}
+
+func (ks *keystore) getKey(ctx context.Context, address string) (*secp256k1.KeyPair, error) {
+	keyPair, ok := ks.keys[strings.ToLower(address)]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgKeystoreKeyNotFound, address)
+	}
+	return keyPair, nil
+}
@@ -0,0 +1,96 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly-signer/pkg/keystorev3"
+	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestKeystore(t *testing.T, password string) (dir, passwordFile string, keyPair *secp256k1.KeyPair) {
+	dir = t.TempDir()
+	keyPair, err := secp256k1.GenerateSecp256k1KeyPair()
+	assert.NoError(t, err)
+	wallet := keystorev3.NewWalletFileLight(password, keyPair)
+	b, err := json.Marshal(wallet)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, keyPair.Address.String()[2:]+".key.json"), b, 0600)
+	assert.NoError(t, err)
+
+	passwordFile = filepath.Join(t.TempDir(), "password")
+	err = os.WriteFile(passwordFile, []byte(password+"\n"), 0600)
+	assert.NoError(t, err)
+	return dir, passwordFile, keyPair
+}
+
+func TestLoadKeystore(t *testing.T) {
+	dir, passwordFile, keyPair := writeTestKeystore(t, "correcthorsebatterystaple")
+	err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a keystore"), 0600)
+	assert.NoError(t, err)
+	err = os.Mkdir(filepath.Join(dir, "subdir"), 0700)
+	assert.NoError(t, err)
+
+	ks, err := loadKeystore(context.Background(), dir, passwordFile)
+	assert.NoError(t, err)
+	assert.Len(t, ks.keys, 1)
+
+	loaded, err := ks.getKey(context.Background(), keyPair.Address.String())
+	assert.NoError(t, err)
+	assert.Equal(t, keyPair.Address, loaded.Address)
+}
+
+func TestLoadKeystoreNoPath(t *testing.T) {
+	ks, err := loadKeystore(context.Background(), "", "")
+	assert.NoError(t, err)
+	_, err = ks.getKey(context.Background(), "0x71c7656ec7ab88b098defb751b7401b5f6d8976f")
+	assert.Regexp(t, "FF10475", err)
+}
+
+func TestLoadKeystoreBadPassword(t *testing.T) {
+	dir, _, _ := writeTestKeystore(t, "correcthorsebatterystaple")
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	err := os.WriteFile(passwordFile, []byte("wrong"), 0600)
+	assert.NoError(t, err)
+
+	_, err = loadKeystore(context.Background(), dir, passwordFile)
+	assert.Regexp(t, "FF10474", err)
+}
+
+func TestLoadKeystoreMissingPasswordFile(t *testing.T) {
+	dir, _, _ := writeTestKeystore(t, "correcthorsebatterystaple")
+	_, err := loadKeystore(context.Background(), dir, filepath.Join(t.TempDir(), "missing"))
+	assert.Regexp(t, "FF10474.*missing", err)
+}
+
+func TestLoadKeystoreMissingDir(t *testing.T) {
+	_, err := loadKeystore(context.Background(), filepath.Join(t.TempDir(), "missing"), "")
+	assert.Regexp(t, "FF10474.*missing", err)
+}
+
+func TestIsKeystoreFile(t *testing.T) {
+	assert.True(t, isKeystoreFile([]byte(`{"crypto":{}}`)))
+	assert.True(t, isKeystoreFile([]byte(`{"Crypto":{}}`)))
+	assert.False(t, isKeystoreFile([]byte(`{"version":3}`)))
+	assert.False(t, isKeystoreFile([]byte(`!json`)))
+}
@@ -0,0 +1,83 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/log"
+)
+
+// nonceManager assigns nonces to transactions, separately for each signing key.
+// Submission for a given key is serialized, so that a failure to submit a transaction does
+// not leave a gap in the nonces - the next transaction re-queries the node instead.
+type nonceManager struct {
+	mux   sync.Mutex
+	nexts map[string]*nextNonce
+}
+
+type nextNonce struct {
+	mux    sync.Mutex
+	loaded bool
+	nonce  uint64
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{
+		nexts: make(map[string]*nextNonce),
+	}
+}
+
+func (nm *nonceManager) forAddress(address string) *nextNonce {
+	nm.mux.Lock()
+	defer nm.mux.Unlock()
+	address = strings.ToLower(address)
+	n, ok := nm.nexts[address]
+	if !ok {
+		n = &nextNonce{}
+		nm.nexts[address] = n
+	}
+	return n
+}
+
+// submitWithNonce assigns the next nonce for the address, and passes it to the submit function.
+// The nonce is only consumed if the submission succeeds.
+func (e *EthRPC) submitWithNonce(ctx context.Context, address string, submit func(nonce uint64) error) error {
+	n := e.nonces.forAddress(address)
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	if !n.loaded {
+		var count hexUint64
+		if err := e.callRPC(ctx, &count, "eth_getTransactionCount", address, "pending"); err != nil {
+			return err
+		}
+		n.nonce = count.Uint64()
+		n.loaded = true
+		log.L(ctx).Debugf("Next nonce for %s is %d", address, n.nonce)
+	}
+
+	if err := submit(n.nonce); err != nil {
+		// We do not know whether the node accepted the transaction, so query again next time
+		n.loaded = false
+		return err
+	}
+	n.nonce++
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcError struct {
+	Code    int64            `json:"code"`
+	Message string           `json:"message"`
+	Data    *fftypes.JSONAny `json:"data,omitempty"`
+}
+
+// rpcCallError is returned when the node rejects a JSON-RPC request, retaining any data returned
+// with the error - such as the revert data of a failed eth_call or eth_estimateGas
+type rpcCallError struct {
+	error
+	data string
+}
+
+func (re *rpcCallError) Unwrap() error {
+	return re.error
+}
+
+type rpcResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      int64            `json:"id"`
+	Result  *fftypes.JSONAny `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+// hexUint64 is a quantity in a JSON-RPC request or result, which is a 0x prefixed hex string
+type hexUint64 uint64
+
+func (h hexUint64) Uint64() uint64 {
+	return uint64(h)
+}
+
+func (h hexUint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("0x%x", uint64(h)))
+}
+
+func (h *hexUint64) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	i, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+	if err != nil {
+		return err
+	}
+	*h = hexUint64(i)
+	return nil
+}
+
+// callRPC makes a single JSON-RPC request to the node, and parses the result into the supplied pointer (if non-nil)
+func (e *EthRPC) callRPC(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	req := &rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddInt64(&e.rpcID, 1),
+		Method:  method,
+		Params:  params,
+	}
+	log.L(ctx).Tracef("JSON-RPC %s request: %+v", method, params)
+	var rpcRes rpcResponse
+	res, err := e.client.R().
+		SetContext(ctx).
+		SetBody(req).
+		SetResult(&rpcRes).
+		SetError(&rpcRes).
+		Post("/")
+	if rpcRes.Error != nil && rpcRes.Error.Message != "" {
+		err := i18n.NewError(ctx, coremsgs.MsgEthRPCCallFailed, method, rpcRes.Error.Message)
+		var data string
+		if rpcRes.Error.Data != nil && json.Unmarshal(rpcRes.Error.Data.Bytes(), &data) == nil && data != "" {
+			return &rpcCallError{error: err, data: data}
+		}
+		return err
+	}
+	if err != nil || !res.IsSuccess() {
+		return ffresty.WrapRestErr(ctx, res, err, coremsgs.MsgEthRPCRESTErr)
+	}
+	if result != nil && rpcRes.Result != nil {
+		if err := json.Unmarshal(rpcRes.Result.Bytes(), result); err != nil {
+			return i18n.NewError(ctx, coremsgs.MsgEthRPCInvalidResult, method, err)
+		}
+	}
+	return nil
+}
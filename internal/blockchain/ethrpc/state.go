@@ -0,0 +1,109 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// pluginState is everything the connector needs to resume after a restart.
+// It is persisted to the configured state file on every change.
+type pluginState struct {
+	// FireFlyCheckpoints is the next block to query for each FireFly contract, by address
+	FireFlyCheckpoints map[string]uint64 `json:"fireflyCheckpoints"`
+	// Listeners are the contract listeners, by backend ID
+	Listeners map[string]*listener `json:"listeners"`
+	// Pending are the submitted transactions that are waiting for a receipt, by transaction hash
+	Pending map[string]*pendingTransaction `json:"pending"`
+}
+
+type listener struct {
+	ID            string           `json:"id"`
+	Address       string           `json:"address"`
+	Events        []*listenerEvent `json:"events"`
+	Checkpoint    uint64           `json:"checkpoint"`
+	Confirmations *int             `json:"confirmations,omitempty"`
+}
+
+// listenerEvent is one of the events of a listener, with the topics used to query its logs
+type listenerEvent struct {
+	Event  *abi.Entry    `json:"event"`
+	Topics []interface{} `json:"topics,omitempty"`
+}
+
+func newPluginState() *pluginState {
+	return &pluginState{
+		FireFlyCheckpoints: make(map[string]uint64),
+		Listeners:          make(map[string]*listener),
+		Pending:            make(map[string]*pendingTransaction),
+	}
+}
+
+func (e *EthRPC) loadState(ctx context.Context) error {
+	e.state = newPluginState()
+	if e.stateFile == "" {
+		return nil
+	}
+	b, err := os.ReadFile(e.stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err == nil {
+		err = json.Unmarshal(b, e.state)
+	}
+	if err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgEthRPCStateFileFailed, e.stateFile, err)
+	}
+	if e.state.FireFlyCheckpoints == nil {
+		e.state.FireFlyCheckpoints = make(map[string]uint64)
+	}
+	if e.state.Listeners == nil {
+		e.state.Listeners = make(map[string]*listener)
+	}
+	if e.state.Pending == nil {
+		e.state.Pending = make(map[string]*pendingTransaction)
+	}
+	return nil
+}
+
+// saveState writes the state to a temporary file, then renames it over the state file,
+// so that a crash part way through cannot leave a truncated file behind.
+func (e *EthRPC) saveState(ctx context.Context) error {
+	if e.stateFile == "" {
+		return nil
+	}
+	e.stateMux.Lock()
+	defer e.stateMux.Unlock()
+	b, err := json.Marshal(e.state)
+	if err == nil {
+		tmpFile := e.stateFile + ".tmp"
+		err = os.WriteFile(tmpFile, b, 0600)
+		if err == nil {
+			err = os.Rename(tmpFile, e.stateFile)
+		}
+	}
+	if err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgEthRPCStateFileFailed, e.stateFile, err)
+	}
+	return nil
+}
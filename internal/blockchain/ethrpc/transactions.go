@@ -0,0 +1,199 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/ethsigner"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// txCall is the transaction object passed to eth_call and eth_estimateGas
+type txCall struct {
+	From  string                    `json:"from,omitempty"`
+	To    *ethtypes.Address0xHex    `json:"to,omitempty"`
+	Data  ethtypes.HexBytes0xPrefix `json:"data,omitempty"`
+	Value *ethtypes.HexInteger      `json:"value,omitempty"`
+}
+
+type txReceipt struct {
+	TransactionHash string                 `json:"transactionHash"`
+	BlockHash       string                 `json:"blockHash"`
+	BlockNumber     *ethtypes.HexInteger   `json:"blockNumber"`
+	GasUsed         *ethtypes.HexInteger   `json:"gasUsed"`
+	Status          *ethtypes.HexInteger   `json:"status"`
+	ContractAddress *ethtypes.Address0xHex `json:"contractAddress"`
+}
+
+// pendingTransaction is a submitted transaction, for which we are waiting for a receipt
+type pendingTransaction struct {
+	NsOpID string `json:"nsOpId"`
+	TxHash string `json:"txHash"`
+}
+
+// reservedTxOptions are fields of the transaction that are set by the connector, and cannot be overridden
+var reservedTxOptions = []string{"from", "to", "data", "nonce"}
+
+func buildTransaction(ctx context.Context, to *ethtypes.Address0xHex, data []byte, options map[string]interface{}) (*ethsigner.Transaction, error) {
+	tx := &ethsigner.Transaction{}
+	for _, reserved := range reservedTxOptions {
+		if _, ok := options[reserved]; ok {
+			return nil, i18n.NewError(ctx, coremsgs.MsgOverrideExistingFieldCustomOption, reserved)
+		}
+	}
+	if len(options) > 0 {
+		b, _ := json.Marshal(options)
+		if err := json.Unmarshal(b, tx); err != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCEncodingFailed, "options", err)
+		}
+	}
+	tx.To = to
+	tx.Data = data
+	if tx.Value == nil {
+		tx.Value = ethtypes.NewHexInteger64(0)
+	}
+	return tx, nil
+}
+
+// sendTransaction signs a transaction with the key from the local keystore, and submits it to the node.
+// The receipt is picked up asynchronously by the polling loop, and reported as an operation update.
+func (e *EthRPC) sendTransaction(ctx context.Context, nsOpID, from string, to *ethtypes.Address0xHex, data []byte, options map[string]interface{}) error {
+	keyPair, err := e.keystore.getKey(ctx, from)
+	if err != nil {
+		return err
+	}
+	tx, err := buildTransaction(ctx, to, data, options)
+	if err != nil {
+		return err
+	}
+
+	if tx.GasLimit == nil {
+		var gas ethtypes.HexInteger
+		call := &txCall{From: from, To: to, Data: data, Value: tx.Value}
+		if err := e.callRPC(ctx, &gas, "eth_estimateGas", call); err != nil {
+			return err
+		}
+		tx.GasLimit = &gas
+	}
+	if tx.GasPrice == nil && tx.MaxFeePerGas == nil {
+		var gasPrice ethtypes.HexInteger
+		if err := e.callRPC(ctx, &gasPrice, "eth_gasPrice"); err != nil {
+			return err
+		}
+		tx.GasPrice = &gasPrice
+	}
+
+	return e.submitWithNonce(ctx, from, func(nonce uint64) error {
+		tx.Nonce = ethtypes.NewHexInteger64(int64(nonce))
+		signed, err := tx.Sign(keyPair, e.chainID)
+		if err != nil {
+			return i18n.NewError(ctx, coremsgs.MsgEthRPCSigningFailed, from, err)
+		}
+		var txHash string
+		if err := e.callRPC(ctx, &txHash, "eth_sendRawTransaction", ethtypes.HexBytes0xPrefix(signed)); err != nil {
+			return err
+		}
+		log.L(ctx).Infof("Submitted transaction operation=%s from=%s nonce=%d tx=%s", nsOpID, from, nonce, txHash)
+		e.addPendingTransaction(ctx, &pendingTransaction{
+			NsOpID: nsOpID,
+			TxHash: txHash,
+		})
+		return nil
+	})
+}
+
+func (e *EthRPC) addPendingTransaction(ctx context.Context, ptx *pendingTransaction) {
+	e.stateMux.Lock()
+	e.state.Pending[ptx.TxHash] = ptx
+	e.stateMux.Unlock()
+	// The transaction has already been accepted by the node, so we only warn if we fail to persist it
+	if err := e.saveState(ctx); err != nil {
+		log.L(ctx).Warnf("Failed to persist pending transaction %s: %s", ptx.TxHash, err)
+	}
+}
+
+func (e *EthRPC) getPendingTransactions() []*pendingTransaction {
+	e.stateMux.Lock()
+	defer e.stateMux.Unlock()
+	pending := make([]*pendingTransaction, 0, len(e.state.Pending))
+	for _, ptx := range e.state.Pending {
+		pending = append(pending, ptx)
+	}
+	return pending
+}
+
+// checkReceipts queries the receipt of each pending transaction, and reports any that have been mined
+func (e *EthRPC) checkReceipts(ctx context.Context) error {
+	completed := 0
+	for _, ptx := range e.getPendingTransactions() {
+		var receipt *txReceipt
+		if err := e.callRPC(ctx, &receipt, "eth_getTransactionReceipt", ptx.TxHash); err != nil {
+			return err
+		}
+		if receipt == nil {
+			continue // not yet mined
+		}
+		e.handleReceipt(ctx, ptx, receipt)
+		e.stateMux.Lock()
+		delete(e.state.Pending, ptx.TxHash)
+		e.stateMux.Unlock()
+		completed++
+	}
+	if completed > 0 {
+		return e.saveState(ctx)
+	}
+	return nil
+}
+
+func (e *EthRPC) handleReceipt(ctx context.Context, ptx *pendingTransaction, receipt *txReceipt) {
+	output := fftypes.JSONObject{
+		"transactionHash": ptx.TxHash,
+		"blockHash":       receipt.BlockHash,
+	}
+	if receipt.BlockNumber != nil {
+		output["blockNumber"] = receipt.BlockNumber.BigInt().String()
+	}
+	if receipt.GasUsed != nil {
+		output["gasUsed"] = receipt.GasUsed.BigInt().String()
+	}
+	if receipt.ContractAddress != nil {
+		// Contract deployments report the new contract in the same format as a contract location
+		output["contractAddress"] = receipt.ContractAddress.String()
+		output["contractLocation"] = &ethereum.Location{Address: receipt.ContractAddress.String()}
+	}
+
+	updateType := core.OpStatusSucceeded
+	message := ""
+	// Receipts from chains before the Byzantium fork do not have a status
+	if receipt.Status != nil {
+		output["status"] = receipt.Status.BigInt().String()
+		if receipt.Status.BigInt().Sign() == 0 {
+			updateType = core.OpStatusFailed
+			message = i18n.NewError(ctx, coremsgs.MsgEthRPCTransactionReverted, ptx.TxHash).Error()
+		}
+	}
+	log.L(ctx).Infof("Receipt received operation=%s tx=%s status=%s", ptx.NsOpID, ptx.TxHash, updateType)
+	e.callbacks.BlockchainOpUpdate(e, ptx.NsOpID, updateType, ptx.TxHash, message, output)
+}
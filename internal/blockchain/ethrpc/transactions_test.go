@@ -0,0 +1,275 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethrpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendTransactionNonces(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+	txCount := 0
+	rpc.on("eth_sendRawTransaction", func(params []interface{}) (interface{}, *rpcError) {
+		txCount++
+		return fmt.Sprintf("0x%064x", txCount), nil
+	})
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.NoError(t, err)
+	err = e.sendTransaction(e.ctx, "ns1:op2", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, rpc.getCalls("eth_getTransactionCount"), 1)
+	assert.Equal(t, []interface{}{signer, "pending"}, rpc.getCalls("eth_getTransactionCount")[0])
+	assert.Equal(t, uint64(7), e.nonces.forAddress(signer).nonce)
+	assert.Len(t, e.state.Pending, 2)
+	assert.Equal(t, "ns1:op1", e.state.Pending[fmt.Sprintf("0x%064x", 1)].NsOpID)
+	assert.Equal(t, "ns1:op2", e.state.Pending[fmt.Sprintf("0x%064x", 2)].NsOpID)
+}
+
+func TestSendTransactionSubmitFailReloadsNonce(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+	rpc.fail("eth_sendRawTransaction", "nonce too low")
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.Regexp(t, "FF10472.*nonce too low", err)
+	assert.False(t, e.nonces.forAddress(signer).loaded)
+
+	mockSendTransaction(rpc)
+	err = e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, rpc.getCalls("eth_getTransactionCount"), 2)
+}
+
+func TestSendTransactionNonceFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+	rpc.fail("eth_getTransactionCount", "pop")
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.Regexp(t, "FF10472.*pop", err)
+	assert.Empty(t, rpc.getCalls("eth_sendRawTransaction"))
+}
+
+func TestSendTransactionOptions(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, map[string]interface{}{
+		"gas":      "0x100000",
+		"gasPrice": "0x0",
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, rpc.getCalls("eth_estimateGas"))
+	assert.Empty(t, rpc.getCalls("eth_gasPrice"))
+	assert.Len(t, rpc.getCalls("eth_sendRawTransaction"), 1)
+}
+
+func TestSendTransactionEIP1559Options(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, map[string]interface{}{
+		"maxFeePerGas":         "0x3b9aca00",
+		"maxPriorityFeePerGas": "0x1",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rpc.getCalls("eth_estimateGas"), 1)
+	assert.Empty(t, rpc.getCalls("eth_gasPrice"))
+}
+
+func TestSendTransactionReservedOption(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, map[string]interface{}{
+		"nonce": 10,
+	})
+	assert.Regexp(t, "FF10398.*nonce", err)
+}
+
+func TestSendTransactionBadOptions(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, map[string]interface{}{
+		"gas": "not a number",
+	})
+	assert.Regexp(t, "FF10476.*options", err)
+}
+
+func TestSendTransactionUnknownKey(t *testing.T) {
+	e, _, cancel := newTestEthRPC(t)
+	defer cancel()
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", "0x71c7656ec7ab88b098defb751b7401b5f6d8976f", nil, []byte{0x60, 0x80}, nil)
+	assert.Regexp(t, "FF10475", err)
+}
+
+func TestSendTransactionEstimateGasFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+	rpc.fail("eth_estimateGas", "execution reverted")
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.Regexp(t, "FF10472.*execution reverted", err)
+}
+
+func TestSendTransactionGasPriceFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+	rpc.fail("eth_gasPrice", "pop")
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.Regexp(t, "FF10472.*pop", err)
+}
+
+func TestSendTransactionPersistFailIgnored(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	signer := addTestKey(t, e)
+	mockSendTransaction(rpc)
+	e.stateFile = filepath.Join(t.TempDir(), "missing", "state.json")
+
+	err := e.sendTransaction(e.ctx, "ns1:op1", signer, nil, []byte{0x60, 0x80}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, e.state.Pending, 1)
+}
+
+func TestCheckReceiptsSuccess(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	e.stateFile = filepath.Join(t.TempDir(), "state.json")
+	txHash := fmt.Sprintf("0x%064x", 1)
+	e.state.Pending[txHash] = &pendingTransaction{NsOpID: "ns1:op1", TxHash: txHash}
+	rpc.result("eth_getTransactionReceipt", map[string]interface{}{
+		"transactionHash": txHash,
+		"blockHash":       fmt.Sprintf("0x%064x", 2),
+		"blockNumber":     "0x10",
+		"gasUsed":         "0x5208",
+		"status":          "0x1",
+		"contractAddress": testContractAddress,
+	})
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BlockchainOpUpdate", e, "ns1:op1", core.OpStatusSucceeded, txHash, "", mock.MatchedBy(func(output fftypes.JSONObject) bool {
+		return output.GetString("blockNumber") == "16" &&
+			output.GetString("gasUsed") == "21000" &&
+			output.GetString("status") == "1" &&
+			output.GetString("contractAddress") == testContractAddress &&
+			output["contractLocation"].(*ethereum.Location).Address == testContractAddress
+	})).Return()
+
+	err := e.checkReceipts(e.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, e.state.Pending)
+
+	b, err := os.ReadFile(e.stateFile)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), txHash)
+
+	cb.AssertExpectations(t)
+}
+
+func TestCheckReceiptsReverted(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	txHash := fmt.Sprintf("0x%064x", 1)
+	e.state.Pending[txHash] = &pendingTransaction{NsOpID: "ns1:op1", TxHash: txHash}
+	rpc.result("eth_getTransactionReceipt", map[string]interface{}{
+		"transactionHash": txHash,
+		"blockNumber":     "0x10",
+		"status":          "0x0",
+	})
+
+	cb := &blockchainmocks.Callbacks{}
+	e.RegisterListener(cb)
+	cb.On("BlockchainOpUpdate", e, "ns1:op1", core.OpStatusFailed, txHash, mock.MatchedBy(func(message string) bool {
+		return assert.Regexp(t, "FF10481", message)
+	}), mock.Anything).Return()
+
+	err := e.checkReceipts(e.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, e.state.Pending)
+
+	cb.AssertExpectations(t)
+}
+
+func TestCheckReceiptsNotMined(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	txHash := fmt.Sprintf("0x%064x", 1)
+	e.state.Pending[txHash] = &pendingTransaction{NsOpID: "ns1:op1", TxHash: txHash}
+	rpc.result("eth_getTransactionReceipt", nil)
+
+	err := e.checkReceipts(e.ctx)
+	assert.NoError(t, err)
+	assert.Len(t, e.state.Pending, 1)
+}
+
+func TestCheckReceiptsFail(t *testing.T) {
+	e, rpc, cancel := newTestEthRPC(t)
+	defer cancel()
+	txHash := fmt.Sprintf("0x%064x", 1)
+	e.state.Pending[txHash] = &pendingTransaction{NsOpID: "ns1:op1", TxHash: txHash}
+	rpc.fail("eth_getTransactionReceipt", "pop")
+
+	err := e.checkReceipts(e.ctx)
+	assert.Regexp(t, "FF10472.*pop", err)
+	assert.Len(t, e.state.Pending, 1)
+}
+
+func TestBuildTransactionDefaults(t *testing.T) {
+	to, err := ethtypes.NewAddress(testContractAddress)
+	assert.NoError(t, err)
+	tx, err := buildTransaction(context.Background(), to, []byte{0x01}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, to, tx.To)
+	assert.Equal(t, "0", tx.Value.BigInt().String())
+	assert.Nil(t, tx.GasLimit)
+}
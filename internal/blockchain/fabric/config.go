@@ -22,10 +22,12 @@ import (
 )
 
 const (
-	defaultBatchSize    = 50
-	defaultBatchTimeout = 500
-	defaultPrefixShort  = "fly"
-	defaultPrefixLong   = "firefly"
+	defaultBatchSize           = 50
+	defaultBatchTimeout        = 500
+	defaultConfirmations       = 0
+	defaultHeadPollingInterval = "5s"
+	defaultPrefixShort         = "fly"
+	defaultPrefixLong          = "firefly"
 )
 
 const (
@@ -43,6 +45,10 @@ const (
 	FabconnectConfigBatchSize = "batchSize"
 	// FabconnectConfigBatchTimeout is the batch timeout to configure on event streams, when auto-defining them
 	FabconnectConfigBatchTimeout = "batchTimeout"
+	// FabconnectConfigConfirmations is the number of blocks that must be committed on top of an event before it is dispatched
+	FabconnectConfigConfirmations = "confirmations"
+	// FabconnectConfigHeadPollingInterval is how often to query the head block while events are waiting for confirmations
+	FabconnectConfigHeadPollingInterval = "headPollingInterval"
 	// FabconnectPrefixShort is used in the query string in requests to ethconnect
 	FabconnectPrefixShort = "prefixShort"
 	// FabconnectPrefixLong is used in HTTP headers in requests to ethconnect
@@ -67,6 +73,8 @@ func (f *Fabric) InitConfig(config config.Section) {
 	f.fabconnectConf.AddKnownKey(FabconnectConfigTopic)
 	f.fabconnectConf.AddKnownKey(FabconnectConfigBatchSize, defaultBatchSize)
 	f.fabconnectConf.AddKnownKey(FabconnectConfigBatchTimeout, defaultBatchTimeout)
+	f.fabconnectConf.AddKnownKey(FabconnectConfigConfirmations, defaultConfirmations)
+	f.fabconnectConf.AddKnownKey(FabconnectConfigHeadPollingInterval, defaultHeadPollingInterval)
 	f.fabconnectConf.AddKnownKey(FabconnectPrefixShort, defaultPrefixShort)
 	f.fabconnectConf.AddKnownKey(FabconnectPrefixLong, defaultPrefixLong)
 
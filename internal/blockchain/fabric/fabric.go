@@ -23,8 +23,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -33,6 +35,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly-common/pkg/wsclient"
+	"github.com/hyperledger/firefly/internal/blockchain/confirmations"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -65,6 +68,10 @@ type Fabric struct {
 	idCache          map[string]*fabIdentity
 	wsconn           wsclient.WSClient
 	closed           chan struct{}
+	confirmations    int
+	headPolling      time.Duration
+	tracker          confirmations.Tracker
+	unackedBatch     bool
 	metrics          metrics.Manager
 	fabconnectConf   config.Section
 	contractConf     config.ArraySection
@@ -108,6 +115,15 @@ func (cb *callbacks) BlockchainEvent(event *blockchain.EventWithSubscription) er
 	return nil
 }
 
+func (cb *callbacks) ContractListenerConfirmations(subscription string) (*int, error) {
+	for _, cb := range cb.listeners {
+		if n, err := cb.ContractListenerConfirmations(subscription); err != nil || n != nil {
+			return n, err
+		}
+	}
+	return nil, nil
+}
+
 type eventStreamWebsocket struct {
 	Topic string `json:"topic"`
 }
@@ -121,6 +137,14 @@ type fabTxInputHeaders struct {
 	Chaincode     string         `json:"chaincode,omitempty"`
 }
 
+// fabDeployChaincode is the contract of a deployment, which refers to a chaincode package rather than containing it
+type fabDeployChaincode struct {
+	Channel   string `json:"channel,omitempty"`
+	Chaincode string `json:"chaincode"`
+	Version   string `json:"version"`
+	Package   string `json:"package"`
+}
+
 type fabError struct {
 	Error string `json:"error,omitempty"`
 }
@@ -221,6 +245,11 @@ func (f *Fabric) Init(ctx context.Context, config config.Section, metrics metric
 	}
 	f.prefixShort = fabconnectConf.GetString(FabconnectPrefixShort)
 	f.prefixLong = fabconnectConf.GetString(FabconnectPrefixLong)
+	f.confirmations = fabconnectConf.GetInt(FabconnectConfigConfirmations)
+	if f.confirmations < 0 {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidConfirmations, f.confirmations)
+	}
+	f.headPolling = fabconnectConf.GetDuration(FabconnectConfigHeadPollingInterval)
 
 	wsConfig := wsclient.GenerateConfig(fabconnectConf)
 	if wsConfig.WSKeyPath == "" {
@@ -381,6 +410,7 @@ func (f *Fabric) parseBlockchainEvent(ctx context.Context, msgJSON fftypes.JSONO
 	chaincode := msgJSON.GetString("chaincodeId")
 
 	delete(msgJSON, "payload")
+	msgJSON[blockchain.EventInfoBlockNumber] = strconv.FormatInt(blockNumber, 10)
 	return &blockchain.Event{
 		BlockchainTXID: sTransactionHash,
 		Source:         f.Name(),
@@ -415,7 +445,9 @@ func (f *Fabric) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSONOb
 	// Check if this is actually an operator action
 	if strings.HasPrefix(nsOrAction, blockchain.FireFlyActionPrefix) {
 		action := nsOrAction[len(blockchain.FireFlyActionPrefix):]
-		return f.callbacks.BlockchainNetworkAction(action, event, verifier)
+		return f.tracker.Add(ctx, event.ProtocolID, msgJSON.GetInt64("blockNumber"), f.confirmations, func() error {
+			return f.callbacks.BlockchainNetworkAction(action, event, verifier)
+		})
 	}
 
 	hexUUIDs, err := hex.DecodeString(strings.TrimPrefix(sUUIDs, "0x"))
@@ -457,7 +489,9 @@ func (f *Fabric) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSONOb
 	}
 
 	// If there's an error dispatching the event, we must return the error and shutdown
-	return f.callbacks.BatchPinComplete(batch, verifier)
+	return f.tracker.Add(ctx, event.ProtocolID, msgJSON.GetInt64("blockNumber"), f.confirmations, func() error {
+		return f.callbacks.BatchPinComplete(batch, verifier)
+	})
 }
 
 func (f *Fabric) buildEventLocationString(chaincode string) string {
@@ -469,9 +503,19 @@ func (f *Fabric) handleContractEvent(ctx context.Context, msgJSON fftypes.JSONOb
 	if event == nil {
 		return nil // move on
 	}
-	return f.callbacks.BlockchainEvent(&blockchain.EventWithSubscription{
-		Event:        *event,
-		Subscription: msgJSON.GetString("subId"),
+	subID := msgJSON.GetString("subId")
+	required, err := f.callbacks.ContractListenerConfirmations(subID)
+	if err != nil {
+		return err
+	}
+	if required == nil {
+		required = &f.confirmations
+	}
+	return f.tracker.Add(ctx, event.ProtocolID, msgJSON.GetInt64("blockNumber"), *required, func() error {
+		return f.callbacks.BlockchainEvent(&blockchain.EventWithSubscription{
+			Event:        *event,
+			Subscription: subID,
+		})
 	})
 }
 
@@ -491,6 +535,10 @@ func (f *Fabric) handleReceipt(ctx context.Context, reply fftypes.JSONObject) {
 	if replyType != "TransactionSuccess" {
 		updateType = core.OpStatusFailed
 	}
+	if chaincode := reply.GetString("chaincode"); chaincode != "" && updateType == core.OpStatusSucceeded {
+		// Chaincode deployments report the committed chaincode in the same format as a contract location
+		reply["contractLocation"] = &Location{Channel: reply.GetString("channel"), Chaincode: chaincode}
+	}
 	l.Infof("Fabconnect '%s' reply tx=%s (request=%s) %s", replyType, txHash, requestID, message)
 	f.callbacks.BlockchainOpUpdate(f, requestID, updateType, txHash, message, reply)
 }
@@ -545,11 +593,20 @@ func (f *Fabric) eventLoop() {
 	l := log.L(f.ctx).WithField("role", "event-loop")
 	ctx := log.WithLogger(f.ctx, l)
 	ack, _ := json.Marshal(map[string]string{"type": "ack", "topic": f.topic})
+	var headPoll <-chan time.Time
+	if f.headPolling > 0 {
+		ticker := time.NewTicker(f.headPolling)
+		defer ticker.Stop()
+		headPoll = ticker.C
+	}
 	for {
+		var err error
 		select {
 		case <-ctx.Done():
 			l.Debugf("Event loop exiting (context cancelled)")
 			return
+		case <-headPoll:
+			err = f.pollHeadBlock(ctx)
 		case msgBytes, ok := <-f.wsconn.Receive():
 			if !ok {
 				l.Debugf("Event loop exiting (receive channel closed)")
@@ -557,31 +614,75 @@ func (f *Fabric) eventLoop() {
 			}
 
 			var msgParsed interface{}
-			err := json.Unmarshal(msgBytes, &msgParsed)
-			if err != nil {
+			if err := json.Unmarshal(msgBytes, &msgParsed); err != nil {
 				l.Errorf("Message cannot be parsed as JSON: %s\n%s", err, string(msgBytes))
 				continue // Swallow this and move on
 			}
 			switch msgTyped := msgParsed.(type) {
 			case []interface{}:
 				err = f.handleMessageBatch(ctx, msgTyped)
-				if err == nil {
-					err = f.wsconn.Send(ctx, ack)
-				}
+				f.unackedBatch = err == nil
 			case map[string]interface{}:
-				f.handleReceipt(ctx, fftypes.JSONObject(msgTyped))
+				receipt := fftypes.JSONObject(msgTyped)
+				f.handleReceipt(ctx, receipt)
+				if receipt.GetString("blockNumber") != "" {
+					// Receipts tell us the chain has progressed, which might confirm pending events
+					err = f.tracker.BlockObserved(ctx, receipt.GetInt64("blockNumber"))
+				}
 			default:
 				l.Errorf("Message unexpected: %+v", msgTyped)
 				continue
 			}
+		}
 
-			// Send the ack - only fails if shutting down
-			if err != nil {
-				l.Errorf("Event loop exiting: %s", err)
-				return
-			}
+		// The batch is only acked once all of its events have been dispatched, as events waiting
+		// for confirmations are held in memory, and fabconnect redelivers an unacked batch on restart
+		if err == nil && f.unackedBatch && f.tracker.Pending() == 0 {
+			err = f.wsconn.Send(ctx, ack)
+			f.unackedBatch = false
 		}
+
+		// Only fails if shutting down
+		if err != nil {
+			l.Errorf("Event loop exiting: %s", err)
+			return
+		}
+	}
+}
+
+// pollHeadBlock queries the height of the channel while events are waiting for confirmations,
+// so that they are dispatched even when no other events or receipts are arriving from the chain
+func (f *Fabric) pollHeadBlock(ctx context.Context) error {
+	if f.tracker.Pending() == 0 {
+		return nil
+	}
+	blockNumber, err := f.queryBlockNumber(ctx)
+	if err != nil {
+		log.L(ctx).Warnf("Failed to query head block: %s", err)
+		return nil // try again on the next poll
+	}
+	return f.tracker.BlockObserved(ctx, blockNumber)
+}
+
+func (f *Fabric) queryBlockNumber(ctx context.Context) (int64, error) {
+	var resErr fabError
+	var chainInfo struct {
+		Result struct {
+			Height int64 `json:"height"`
+		} `json:"result"`
+	}
+	res, err := f.client.R().
+		SetContext(ctx).
+		SetQueryParam(f.prefixShort+"-channel", f.defaultChannel).
+		SetQueryParam(f.prefixShort+"-signer", f.signer).
+		SetError(&resErr).
+		SetResult(&chainInfo).
+		Get("/chaininfo")
+	if err != nil || !res.IsSuccess() {
+		return -1, wrapError(ctx, &resErr, res, err)
 	}
+	// The height is the number of blocks, so the head block is one less
+	return chainInfo.Result.Height - 1, nil
 }
 
 func (f *Fabric) NormalizeSigningKey(ctx context.Context, signingKeyInput string) (string, error) {
@@ -743,7 +844,7 @@ func (f *Fabric) buildFabconnectRequestBody(ctx context.Context, channel, chainc
 	return body, nil
 }
 
-func (f *Fabric) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) error {
+func (f *Fabric) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) error {
 	fabricOnChainLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
 		return err
@@ -766,7 +867,72 @@ func (f *Fabric) InvokeContract(ctx context.Context, nsOpID string, signingKey s
 	return f.invokeContractMethod(ctx, fabricOnChainLocation.Channel, fabricOnChainLocation.Chaincode, method.Name, signingKey, nsOpID, prefixItems, input, options)
 }
 
-func (f *Fabric) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) (interface{}, error) {
+// DeployContract asks fabconnect to install, approve and commit a chaincode package through the Fabric chaincode lifecycle.
+// The contract refers to the package, and the optional definition is passed through as the chaincode definition (such
+// as the sequence and endorsement policy). Any input is passed as the arguments to initialize the chaincode.
+func (f *Fabric) DeployContract(ctx context.Context, nsOpID string, signingKey string, definition *fftypes.JSONAny, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) error {
+	if contract == nil {
+		return i18n.NewError(ctx, coremsgs.MsgContractDeployMissingContract)
+	}
+	var deploy fabDeployChaincode
+	if err := json.Unmarshal(contract.Bytes(), &deploy); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgContractDeployInvalidChaincode, err)
+	}
+	if deploy.Chaincode == "" || deploy.Version == "" || deploy.Package == "" {
+		return i18n.NewError(ctx, coremsgs.MsgContractDeployInvalidChaincode, "'chaincode', 'version' and 'package' must be set")
+	}
+	if deploy.Channel == "" {
+		deploy.Channel = f.defaultChannel
+	}
+	args := make([]string, len(input))
+	for i, v := range input {
+		if s, ok := v.(string); ok {
+			args[i] = s
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, "input")
+		}
+		args[i] = string(b)
+	}
+	body := map[string]interface{}{
+		"headers": &fabTxInputHeaders{
+			ID:        nsOpID,
+			Type:      "DeployChaincode",
+			Channel:   deploy.Channel,
+			Chaincode: deploy.Chaincode,
+			Signer:    getUserName(signingKey),
+		},
+		"version": deploy.Version,
+		"package": deploy.Package,
+		"args":    args,
+	}
+	if definition != nil {
+		body["definition"] = definition
+	}
+	for k, v := range options {
+		// Set the new field if it's not already set. Do not allow overriding of existing fields
+		if _, ok := body[k]; !ok {
+			body[k] = v
+		} else {
+			return i18n.NewError(ctx, coremsgs.MsgOverrideExistingFieldCustomOption, k)
+		}
+	}
+	var resErr fabError
+	res, err := f.client.R().
+		SetContext(ctx).
+		SetHeader("x-firefly-sync", "false").
+		SetBody(body).
+		SetError(&resErr).
+		Post("/chaincodes")
+	if err != nil || !res.IsSuccess() {
+		return wrapError(ctx, &resErr, res, err)
+	}
+	return nil
+}
+
+func (f *Fabric) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) (interface{}, error) {
 	fabricOnChainLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
 		return nil, err
@@ -841,7 +1007,21 @@ func (f *Fabric) AddContractListener(ctx context.Context, listener *core.Contrac
 	if err != nil {
 		return err
 	}
-	result, err := f.streams.createSubscription(ctx, location, f.streamID, "", listener.Event.Name, listener.Options.FirstEvent)
+	if len(listener.Options.Filters) > 0 {
+		return i18n.NewError(ctx, coremsgs.MsgListenerFiltersNotSupported, f.Name())
+	}
+	// Fabconnect matches chaincode event names against a regular expression, so multiple events are
+	// combined into a single anchored alternation
+	events := listener.AllEvents()
+	eventFilter := events[0].Name
+	if len(events) > 1 {
+		names := make([]string, len(events))
+		for i, event := range events {
+			names[i] = regexp.QuoteMeta(event.Name)
+		}
+		eventFilter = fmt.Sprintf("^(%s)$", strings.Join(names, "|"))
+	}
+	result, err := f.streams.createSubscription(ctx, location, f.streamID, "", eventFilter, listener.Options.FirstEvent)
 	if err != nil {
 		return err
 	}
@@ -866,6 +1046,15 @@ func (f *Fabric) GenerateEventSignature(ctx context.Context, event *core.FFIEven
 	return event.Name
 }
 
+func (f *Fabric) GenerateErrorSignature(ctx context.Context, errorDef *core.FFIErrorDefinition) string {
+	return errorDef.Name
+}
+
+func (f *Fabric) DecodeOperationRevert(ctx context.Context, opOutput fftypes.JSONObject, errors []*core.FFIError) *core.ContractRevert {
+	// Chaincode errors are reported as a message, with no structured data to decode
+	return nil
+}
+
 func (f *Fabric) getNetworkVersion(ctx context.Context, chaincode string) (int, error) {
 	res, err := f.queryContractMethod(ctx, f.defaultChannel, chaincode, networkVersionMethodName, f.signer, "", []*PrefixItem{}, map[string]interface{}{}, nil)
 	if err != nil || !res.IsSuccess() {
@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -132,6 +133,19 @@ func TestInitMissingTopic(t *testing.T) {
 	assert.Regexp(t, "FF10138.*topic", err)
 }
 
+func TestInitBadConfirmations(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	resetConf(e)
+	utFabconnectConf.Set(ffresty.HTTPConfigURL, "http://localhost:12345")
+	utFabconnectConf.Set(FabconnectConfigSigner, "signer001")
+	utFabconnectConf.Set(FabconnectConfigTopic, "topic1")
+	utFabconnectConf.Set(FabconnectConfigConfirmations, -1)
+
+	err := e.Init(e.ctx, utConfig, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10482", err)
+}
+
 func TestInitAllNewStreamsAndWSEvent(t *testing.T) {
 
 	log.SetLevel("trace")
@@ -1290,6 +1304,132 @@ func TestEventLoopUnexpectedMessage(t *testing.T) {
 	e.ctx.Done()
 }
 
+func TestEventLoopReceiptConfirmsEvents(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	wsm := &wsmocks.WSClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e := &Fabric{
+		ctx:       ctx,
+		topic:     "topic1",
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+		wsconn:    wsm,
+		closed:    make(chan struct{}),
+	}
+
+	dispatched := false
+	err := e.tracker.Add(ctx, "000000000010/000000/000000", 10, 2, func() error {
+		dispatched = true
+		return fmt.Errorf("pop")
+	})
+	assert.NoError(t, err)
+
+	r := make(chan []byte, 1)
+	r <- []byte(`{"headers":{"requestId":"ns1:` + fftypes.NewUUID().String() + `","type":"TransactionSuccess"},"blockNumber":12}`)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Close").Return()
+	em.On("BlockchainOpUpdate", e, mock.Anything, core.OpStatusSucceeded, "", "", mock.Anything).Return()
+
+	e.eventLoop() // exits on the dispatch error
+
+	assert.True(t, dispatched)
+	wsm.AssertExpectations(t)
+	em.AssertExpectations(t)
+}
+
+func testConfirmationsBatch() []byte {
+	return []byte(`
+[
+	{
+		"chaincodeId": "basic",
+	  "blockNumber": 10,
+		"transactionId": "4763a0c50e3bba7cef1a7ba35dd3f9f3426bb04d0156f326e84ec99387c4746d",
+		"transactionIndex": 20,
+		"eventIndex": 30,
+		"eventName": "AssetCreated",
+		"payload": "eyJBcHByYWlzZWRWYWx1ZSI6MTAsIkNvbG9yIjoicmVkIiwiSUQiOiIxMjM0IiwiT3duZXIiOiJtZSIsIlNpemUiOjN9",
+		"subId": "sb-cb37cc07-e873-4f58-44ab-55add6bba320"
+	}
+]`)
+}
+
+func TestEventLoopBatchNotAckedWhileEventsPending(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	em := &blockchainmocks.Callbacks{}
+	e.callbacks = callbacks{listeners: []blockchain.Callbacks{em}}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+	e.confirmations = 1
+	e.closed = make(chan struct{})
+
+	em.On("ContractListenerConfirmations", "sb-cb37cc07-e873-4f58-44ab-55add6bba320").Return(nil, nil)
+
+	// FireFly stops before the event is confirmed, so the batch must be left for fabconnect to redeliver
+	r := make(chan []byte, 1)
+	r <- testConfirmationsBatch()
+	close(r)
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Close").Return()
+
+	e.eventLoop()
+
+	assert.Equal(t, 1, e.tracker.Pending())
+	assert.True(t, e.unackedBatch)
+	wsm.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	wsm.AssertExpectations(t)
+	em.AssertExpectations(t)
+}
+
+func TestEventLoopHeadPollConfirmsEvents(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	em := &blockchainmocks.Callbacks{}
+	e.callbacks = callbacks{listeners: []blockchain.Callbacks{em}}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+	e.confirmations = 1
+	e.signer = signer
+	e.headPolling = time.Millisecond
+	e.closed = make(chan struct{})
+
+	em.On("ContractListenerConfirmations", "sb-cb37cc07-e873-4f58-44ab-55add6bba320").Return(nil, nil)
+	em.On("BlockchainEvent", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.ProtocolID == "000000000010/000020/000030"
+	})).Return(nil)
+
+	// No further events or receipts arrive, so only the chain info query can confirm the event
+	polls := 0
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaininfo",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "firefly", req.URL.Query().Get("fly-channel"))
+			assert.Equal(t, signer, req.URL.Query().Get("fly-signer"))
+			polls++
+			if polls == 1 {
+				return httpmock.NewJsonResponderOrPanic(500, map[string]interface{}{"error": "pop"})(req)
+			}
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{"result": map[string]interface{}{"height": 12}})(req)
+		})
+
+	r := make(chan []byte, 1)
+	r <- testConfirmationsBatch()
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Send", mock.Anything, []byte(`{"topic":"topic1","type":"ack"}`)).Run(func(args mock.Arguments) {
+		assert.Equal(t, 0, e.tracker.Pending())
+		go cancel()
+	}).Return(nil)
+	wsm.On("Close").Return()
+
+	e.eventLoop()
+
+	assert.Equal(t, 2, polls)
+	assert.False(t, e.unackedBatch)
+	wsm.AssertExpectations(t)
+	em.AssertExpectations(t)
+}
+
 func TestHandleReceiptTXSuccess(t *testing.T) {
 	em := &blockchainmocks.Callbacks{}
 	wsm := &wsmocks.WSClient{}
@@ -1330,6 +1470,33 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 
 }
 
+func TestHandleReceiptDeploySuccess(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	e := &Fabric{
+		ctx:       context.Background(),
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+	}
+
+	var reply fftypes.JSONObject
+	err := json.Unmarshal([]byte(`{
+		"headers": {
+			"requestId": "ns1:123",
+			"type": "TransactionSuccess"
+		},
+		"transactionId": "tx1",
+		"channel": "firefly",
+		"chaincode": "asset_transfer"
+	}`), &reply)
+	assert.NoError(t, err)
+
+	em.On("BlockchainOpUpdate", e, "ns1:123", core.OpStatusSucceeded, "tx1", "", mock.MatchedBy(func(output fftypes.JSONObject) bool {
+		return assert.Equal(t, &Location{Channel: "firefly", Chaincode: "asset_transfer"}, output["contractLocation"])
+	})).Return(nil)
+	e.handleReceipt(context.Background(), reply)
+
+	em.AssertExpectations(t)
+}
+
 func TestHandleReceiptNoRequestID(t *testing.T) {
 	em := &blockchainmocks.Callbacks{}
 	wsm := &wsmocks.WSClient{}
@@ -1427,6 +1594,70 @@ func TestAddSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAddSubscriptionMultipleEvents(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.streamID = "es-1"
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"channel":   "firefly",
+				"chaincode": "mycode",
+			}.String()),
+			Events: core.FFISerializedEvents{
+				{FFIEventDefinition: core.FFIEventDefinition{Name: "AssetCreated"}},
+				{FFIEventDefinition: core.FFIEventDefinition{Name: "Asset.Deleted"}},
+			},
+			Options: &core.ContractListenerOptions{
+				FirstEvent: string(core.SubOptsFirstEventNewest),
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "mycode", body.Filter.ChaincodeID)
+			assert.Equal(t, `^(AssetCreated|Asset\.Deleted)$`, body.Filter.EventFilter)
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "sub1"})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", sub.BackendID)
+}
+
+func TestAddSubscriptionFiltersNotSupported(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"channel":   "firefly",
+				"chaincode": "mycode",
+			}.String()),
+			Event: &core.FFISerializedEvent{},
+			Options: &core.ContractListenerOptions{
+				Filters: fftypes.JSONObject{"owner": "alice"},
+			},
+		},
+	}
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10488.*fabric", err)
+}
+
 func TestAddSubscriptionBadLocation(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -1549,6 +1780,7 @@ func TestHandleMessageContractEvent(t *testing.T) {
 	}
 	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
 
+	em.On("ContractListenerConfirmations", "sb-cb37cc07-e873-4f58-44ab-55add6bba320").Return(nil, nil)
 	em.On("BlockchainEvent", mock.MatchedBy(func(e *blockchain.EventWithSubscription) bool {
 		assert.Equal(t, "4763a0c50e3bba7cef1a7ba35dd3f9f3426bb04d0156f326e84ec99387c4746d", e.BlockchainTXID)
 		assert.Equal(t, "000000000010/000020/000030", e.Event.ProtocolID)
@@ -1561,7 +1793,7 @@ func TestHandleMessageContractEvent(t *testing.T) {
 	err = e.handleMessageBatch(context.Background(), events)
 	assert.NoError(t, err)
 
-	ev := em.Calls[0].Arguments[0].(*blockchain.EventWithSubscription)
+	ev := em.Calls[1].Arguments[0].(*blockchain.EventWithSubscription)
 	assert.Equal(t, "sb-cb37cc07-e873-4f58-44ab-55add6bba320", ev.Subscription)
 	assert.Equal(t, "AssetCreated", ev.Event.Name)
 
@@ -1575,7 +1807,7 @@ func TestHandleMessageContractEvent(t *testing.T) {
 	assert.Equal(t, outputs, ev.Event.Output)
 
 	info := fftypes.JSONObject{
-		"blockNumber":      float64(10),
+		"blockNumber":      "10",
 		"chaincodeId":      "basic",
 		"eventName":        "AssetCreated",
 		"subId":            "sb-cb37cc07-e873-4f58-44ab-55add6bba320",
@@ -1635,6 +1867,7 @@ func TestHandleMessageContractEventError(t *testing.T) {
 	}
 	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
 
+	em.On("ContractListenerConfirmations", "sb-cb37cc07-e873-4f58-44ab-55add6bba320").Return(nil, nil)
 	em.On("BlockchainEvent", mock.Anything).Return(fmt.Errorf("pop"))
 
 	var events []interface{}
@@ -1646,6 +1879,78 @@ func TestHandleMessageContractEventError(t *testing.T) {
 	em.AssertExpectations(t)
 }
 
+func TestHandleMessageContractEventConfirmations(t *testing.T) {
+	data := []byte(`
+[
+	{
+		"chaincodeId": "basic",
+	  "blockNumber": 10,
+		"transactionId": "4763a0c50e3bba7cef1a7ba35dd3f9f3426bb04d0156f326e84ec99387c4746d",
+		"transactionIndex": 20,
+		"eventIndex": 30,
+		"eventName": "AssetCreated",
+		"payload": "eyJBcHByYWlzZWRWYWx1ZSI6MTAsIkNvbG9yIjoicmVkIiwiSUQiOiIxMjM0IiwiT3duZXIiOiJtZSIsIlNpemUiOjN9",
+		"subId": "sb-cb37cc07-e873-4f58-44ab-55add6bba320"
+	}
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Fabric{
+		callbacks:     callbacks{listeners: []blockchain.Callbacks{em}},
+		confirmations: 1,
+	}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+
+	em.On("ContractListenerConfirmations", "sb-cb37cc07-e873-4f58-44ab-55add6bba320").Return(nil, nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data, &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, e.tracker.Pending())
+
+	em.On("BlockchainEvent", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.ProtocolID == "000000000010/000020/000030"
+	})).Return(nil)
+
+	err = e.tracker.BlockObserved(context.Background(), 11)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, e.tracker.Pending())
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageContractEventConfirmationsFail(t *testing.T) {
+	data := []byte(`
+[
+	{
+		"chaincodeId": "basic",
+	  "blockNumber": 10,
+		"transactionId": "4763a0c50e3bba7cef1a7ba35dd3f9f3426bb04d0156f326e84ec99387c4746d",
+		"eventName": "AssetCreated",
+		"payload": "eyJBcHByYWlzZWRWYWx1ZSI6MTAsIkNvbG9yIjoicmVkIiwiSUQiOiIxMjM0IiwiT3duZXIiOiJtZSIsIlNpemUiOjN9",
+		"subId": "sb-cb37cc07-e873-4f58-44ab-55add6bba320"
+	}
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Fabric{
+		callbacks: callbacks{listeners: []blockchain.Callbacks{em}},
+	}
+	e.fireflyContract.subscription = "sb-b5b97a4e-a317-4053-6400-1474650efcb5"
+
+	em.On("ContractListenerConfirmations", "sb-cb37cc07-e873-4f58-44ab-55add6bba320").Return(nil, fmt.Errorf("pop"))
+
+	var events []interface{}
+	err := json.Unmarshal(data, &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.EqualError(t, err, "pop")
+
+	em.AssertExpectations(t)
+}
+
 func TestInvokeContractOK(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -1680,7 +1985,7 @@ func TestInvokeContractOK(t *testing.T) {
 			assert.Equal(t, "customValue", body["customOption"])
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.NoError(t, err)
 }
 
@@ -1712,7 +2017,7 @@ func TestInvokeContractBadSchema(t *testing.T) {
 	options := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF00127", err)
 }
 
@@ -1734,7 +2039,7 @@ func TestInvokeContractInvalidOption(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF10398", err)
 }
 
@@ -1751,7 +2056,7 @@ func TestInvokeContractChaincodeNotSet(t *testing.T) {
 	options := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF10310", err)
 }
 
@@ -1777,7 +2082,7 @@ func TestInvokeContractFabconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF10284", err)
 }
 
@@ -1814,7 +2119,7 @@ func TestQueryContractOK(t *testing.T) {
 			assert.Equal(t, "customValue", body["customOption"])
 			return httpmock.NewJsonResponderOrPanic(200, &fabQueryNamedOutput{})(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.NoError(t, err)
 }
 
@@ -1836,7 +2141,7 @@ func TestQueryContractInputNotJSON(t *testing.T) {
 	options := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF00127", err)
 }
 
@@ -1853,7 +2158,7 @@ func TestQueryContractBadLocation(t *testing.T) {
 		"y": float64(2),
 	}
 	options := map[string]interface{}{}
-	_, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtr(`{"validLocation": false}`), method, params, options)
+	_, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtr(`{"validLocation": false}`), method, params, nil, options)
 	assert.Regexp(t, "FF10310", err)
 }
 
@@ -1878,7 +2183,7 @@ func TestQueryContractFabconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, &fabQueryNamedOutput{})(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF10284", err)
 }
 
@@ -1909,7 +2214,7 @@ func TestQueryContractUnmarshalResponseError(t *testing.T) {
 			assert.Equal(t, "2", body["args"].(map[string]interface{})["y"])
 			return httpmock.NewStringResponder(200, "[definitely not JSON}")(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "invalid character", err)
 }
 
@@ -1960,7 +2265,7 @@ func TestInvokeJSONEncodeParamsError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, options)
+	err = e.InvokeContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil, options)
 	assert.Regexp(t, "FF00127", err)
 }
 
@@ -1982,12 +2287,105 @@ func TestGenerateFFI(t *testing.T) {
 	assert.Regexp(t, "FF10347", err)
 }
 
+func TestDeployContractOK(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	signingKey := fftypes.NewRandB32().String()
+	contract := fftypes.JSONAnyPtr(`{"chaincode":"asset_transfer","version":"1.0","package":"asset_transfer:abcd"}`)
+	definition := fftypes.JSONAnyPtr(`{"sequence":1}`)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/chaincodes`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "DeployChaincode", headers["type"])
+			assert.Equal(t, "ns1:123", headers["id"])
+			assert.Equal(t, signingKey, headers["signer"])
+			assert.Equal(t, "firefly", headers["channel"])
+			assert.Equal(t, "asset_transfer", headers["chaincode"])
+			assert.Equal(t, "1.0", body["version"])
+			assert.Equal(t, "asset_transfer:abcd", body["package"])
+			assert.Equal(t, []interface{}{"a", `{"b":1}`}, body["args"])
+			assert.Equal(t, float64(1), body["definition"].(map[string]interface{})["sequence"])
+			assert.Equal(t, "customValue", body["customOption"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.DeployContract(context.Background(), "ns1:123", signingKey, definition, contract, []interface{}{"a", map[string]interface{}{"b": 1}}, map[string]interface{}{
+		"customOption": "customValue",
+	})
+	assert.NoError(t, err)
+}
+
+func TestDeployContractMissingContract(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	err := e.DeployContract(context.Background(), "ns1:123", "signer", nil, nil, nil, nil)
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestDeployContractInvalidChaincode(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	err := e.DeployContract(context.Background(), "ns1:123", "signer", nil, fftypes.JSONAnyPtr(`"bytes"`), nil, nil)
+	assert.Regexp(t, "FF10469", err)
+	err = e.DeployContract(context.Background(), "ns1:123", "signer", nil, fftypes.JSONAnyPtr(`{"chaincode":"cc1"}`), nil, nil)
+	assert.Regexp(t, "FF10469.*package", err)
+}
+
+func TestDeployContractBadInput(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	contract := fftypes.JSONAnyPtr(`{"chaincode":"cc1","version":"1.0","package":"cc1:abcd"}`)
+	err := e.DeployContract(context.Background(), "ns1:123", "signer", nil, contract, []interface{}{map[bool]bool{true: false}}, nil)
+	assert.Regexp(t, "FF00127", err)
+}
+
+func TestDeployContractInvalidOption(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	contract := fftypes.JSONAnyPtr(`{"chaincode":"cc1","version":"1.0","package":"cc1:abcd"}`)
+	err := e.DeployContract(context.Background(), "ns1:123", "signer", nil, contract, nil, map[string]interface{}{
+		"package": "other",
+	})
+	assert.Regexp(t, "FF10398", err)
+}
+
+func TestDeployContractFail(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	contract := fftypes.JSONAnyPtr(`{"channel":"channel2","chaincode":"cc1","version":"1.0","package":"cc1:abcd"}`)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/chaincodes`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "channel2", body["headers"].(map[string]interface{})["channel"])
+			return httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"})(req)
+		})
+	err := e.DeployContract(context.Background(), "ns1:123", "signer", nil, contract, nil, nil)
+	assert.Regexp(t, "FF10284.*pop", err)
+}
+
 func TestGenerateEventSignature(t *testing.T) {
 	e, _ := newTestFabric()
 	signature := e.GenerateEventSignature(context.Background(), &core.FFIEventDefinition{Name: "Changed"})
 	assert.Equal(t, "Changed", signature)
 }
 
+func TestGenerateErrorSignature(t *testing.T) {
+	e, _ := newTestFabric()
+	signature := e.GenerateErrorSignature(context.Background(), &core.FFIErrorDefinition{Name: "CustomError"})
+	assert.Equal(t, "CustomError", signature)
+}
+
+func TestDecodeOperationRevert(t *testing.T) {
+	e, _ := newTestFabric()
+	assert.Nil(t, e.DecodeOperationRevert(context.Background(), fftypes.JSONObject{"errorMessage": "pop"}, nil))
+}
+
 func TestSubmitNetworkAction(t *testing.T) {
 
 	e, cancel := newTestFabric()
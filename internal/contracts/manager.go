@@ -47,6 +47,7 @@ type Manager interface {
 
 	InvokeContract(ctx context.Context, ns string, req *core.ContractCallRequest, waitConfirm bool) (interface{}, error)
 	InvokeContractAPI(ctx context.Context, ns, apiName, methodPath string, req *core.ContractCallRequest, waitConfirm bool) (interface{}, error)
+	DeployContract(ctx context.Context, ns string, req *core.ContractDeployRequest, waitConfirm bool) (interface{}, error)
 	GetContractAPI(ctx context.Context, httpServerURL, ns, apiName string) (*core.ContractAPI, error)
 	GetContractAPIInterface(ctx context.Context, ns, apiName string) (*core.FFI, error)
 	GetContractAPIs(ctx context.Context, httpServerURL, ns string, filter database.AndFilter) ([]*core.ContractAPI, *database.FilterResult, error)
@@ -100,6 +101,7 @@ func NewContractManager(ctx context.Context, di database.Plugin, bm broadcast.Ma
 
 	om.RegisterHandler(ctx, cm, []core.OpType{
 		core.OpTypeBlockchainInvoke,
+		core.OpTypeBlockchainContractDeploy,
 	})
 
 	return cm, nil
@@ -132,6 +134,9 @@ func (cm *contractManager) BroadcastFFI(ctx context.Context, ns string, ffi *cor
 	for _, event := range ffi.Events {
 		event.ID = fftypes.NewUUID()
 	}
+	for _, errorDef := range ffi.Errors {
+		errorDef.ID = fftypes.NewUUID()
+	}
 	if err := cm.ValidateFFIAndSetPathnames(ctx, ffi); err != nil {
 		return nil, err
 	}
@@ -181,7 +186,21 @@ func (cm *contractManager) getFFIChildren(ctx context.Context, ffi *core.FFI) (e
 	for _, event := range ffi.Events {
 		event.Signature = cm.blockchain.GenerateEventSignature(ctx, &event.FFIEventDefinition)
 	}
-	return nil
+
+	ffi.Errors, err = cm.getFFIErrors(ctx, ffi.ID)
+	return err
+}
+
+func (cm *contractManager) getFFIErrors(ctx context.Context, interfaceID *fftypes.UUID) ([]*core.FFIError, error) {
+	fb := database.FFIErrorQueryFactory.NewFilter(ctx)
+	errors, _, err := cm.database.GetFFIErrors(ctx, fb.Eq("interface", interfaceID))
+	if err != nil {
+		return nil, err
+	}
+	for _, errorDef := range errors {
+		errorDef.Signature = cm.blockchain.GenerateErrorSignature(ctx, &errorDef.FFIErrorDefinition)
+	}
+	return errors, nil
 }
 
 func (cm *contractManager) GetFFIByIDWithChildren(ctx context.Context, id *fftypes.UUID) (ffi *core.FFI, err error) {
@@ -255,12 +274,58 @@ func (cm *contractManager) InvokeContract(ctx context.Context, ns string, req *c
 		err = send(ctx)
 		return op, err
 	case core.CallTypeQuery:
-		return cm.blockchain.QueryContract(ctx, req.Location, req.Method, req.Input, req.Options)
+		return cm.blockchain.QueryContract(ctx, req.Location, req.Method, req.Input, req.Errors, req.Options)
 	default:
 		panic(fmt.Sprintf("unknown call type: %s", req.Type))
 	}
 }
 
+func (cm *contractManager) writeDeployTransaction(ctx context.Context, ns string, req *core.ContractDeployRequest) (*core.Operation, error) {
+	txid, err := cm.txHelper.SubmitNewTransaction(ctx, ns, core.TransactionTypeContractDeploy)
+	if err != nil {
+		return nil, err
+	}
+
+	op := core.NewOperation(
+		cm.blockchain,
+		ns,
+		txid,
+		core.OpTypeBlockchainContractDeploy)
+	if err = addBlockchainDeployInputs(op, req); err == nil {
+		err = cm.database.InsertOperation(ctx, op)
+	}
+	return op, err
+}
+
+func (cm *contractManager) DeployContract(ctx context.Context, ns string, req *core.ContractDeployRequest, waitConfirm bool) (res interface{}, err error) {
+	if req.Contract == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractDeployMissingContract)
+	}
+	req.Key, err = cm.identity.NormalizeSigningKey(ctx, ns, req.Key, identity.KeyNormalizationBlockchainPlugin)
+	if err != nil {
+		return nil, err
+	}
+
+	var op *core.Operation
+	err = cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		op, err = cm.writeDeployTransaction(ctx, ns, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	send := func(ctx context.Context) error {
+		_, err := cm.operations.RunOperation(ctx, opBlockchainContractDeploy(op, req))
+		return err
+	}
+	if waitConfirm {
+		return cm.syncasync.WaitForDeployOperation(ctx, ns, op.ID, send)
+	}
+	err = send(ctx)
+	return op, err
+}
+
 func (cm *contractManager) InvokeContractAPI(ctx context.Context, ns, apiName, methodPath string, req *core.ContractCallRequest, waitConfirm bool) (interface{}, error) {
 	api, err := cm.database.GetContractAPIByName(ctx, ns, apiName)
 	if err != nil {
@@ -286,7 +351,10 @@ func (cm *contractManager) resolveInvokeContractRequest(ctx context.Context, ns
 			return i18n.NewError(ctx, coremsgs.MsgContractMethodResolveError, err)
 		}
 	}
-	return nil
+	if req.Errors == nil && req.Interface != nil {
+		req.Errors, err = cm.getFFIErrors(ctx, req.Interface)
+	}
+	return err
 }
 
 func (cm *contractManager) addContractURLs(httpServerURL string, api *core.ContractAPI) {
@@ -422,6 +490,16 @@ func (cm *contractManager) ValidateFFIAndSetPathnames(ctx context.Context, ffi *
 			return err
 		}
 	}
+
+	errorPathNames := map[string]bool{}
+	for _, errorDef := range ffi.Errors {
+		errorDef.Interface = ffi.ID
+		errorDef.Namespace = ffi.Namespace
+		errorDef.Pathname = cm.uniquePathName(errorDef.Name, errorPathNames)
+		if err := cm.validateFFIError(ctx, &errorDef.FFIErrorDefinition); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -465,10 +543,27 @@ func (cm *contractManager) validateFFIEvent(ctx context.Context, event *core.FFI
 	return nil
 }
 
+func (cm *contractManager) validateFFIError(ctx context.Context, errorDef *core.FFIErrorDefinition) error {
+	if errorDef.Name == "" {
+		return i18n.NewError(ctx, coremsgs.MsgErrorNameMustBeSet)
+	}
+	for _, param := range errorDef.Params {
+		if err := cm.validateFFIParam(ctx, param); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (cm *contractManager) validateInvokeContractRequest(ctx context.Context, req *core.ContractCallRequest) error {
 	if err := cm.validateFFIMethod(ctx, req.Method); err != nil {
 		return err
 	}
+	for _, errorDef := range req.Errors {
+		if err := cm.validateFFIError(ctx, &errorDef.FFIErrorDefinition); err != nil {
+			return err
+		}
+	}
 
 	for _, param := range req.Method.Params {
 		value, ok := req.Input[param.Name]
@@ -496,6 +591,80 @@ func (cm *contractManager) resolveEvent(ctx context.Context, ns string, ffi *cor
 	return &core.FFISerializedEvent{FFIEventDefinition: event.FFIEventDefinition}, nil
 }
 
+// resolveListenerEvents builds the full list of events for a listener, from the in-line event definitions
+// or the event paths on the referenced FFI
+func (cm *contractManager) resolveListenerEvents(ctx context.Context, ns string, listener *core.ContractListenerInput) (err error) {
+	events := listener.Events
+	if listener.Event != nil {
+		events = append(core.FFISerializedEvents{listener.Event}, events...)
+	}
+	if len(events) > 0 {
+		listener.Interface = nil
+	} else {
+		eventPaths := listener.EventPaths
+		if listener.EventPath != "" {
+			eventPaths = append([]string{listener.EventPath}, eventPaths...)
+		}
+		if len(eventPaths) == 0 || listener.Interface == nil {
+			return i18n.NewError(ctx, coremsgs.MsgListenerNoEvent)
+		}
+		// Copy the event definitions into the listener
+		events = make(core.FFISerializedEvents, len(eventPaths))
+		for i, eventPath := range eventPaths {
+			if events[i], err = cm.resolveEvent(ctx, ns, listener.Interface, eventPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	names := make(map[string]bool, len(events))
+	for _, event := range events {
+		if names[event.Name] {
+			return i18n.NewError(ctx, coremsgs.MsgListenerDuplicateEvent, event.Name)
+		}
+		names[event.Name] = true
+	}
+	listener.Events = events
+	return nil
+}
+
+func filtersEqual(f1, f2 fftypes.JSONObject) bool {
+	if len(f1) == 0 || len(f2) == 0 {
+		return len(f1) == len(f2)
+	}
+	return f1.String() == f2.String()
+}
+
+// validateListenerFilters checks each filter is a parameter of every event on the listener, and that
+// the values are valid for that parameter. Whether the parameter can be filtered on is checked by the
+// blockchain plugin.
+func (cm *contractManager) validateListenerFilters(ctx context.Context, listener *core.ContractListenerInput) error {
+	for name, value := range listener.Options.Filters {
+		values, isArray := value.([]interface{})
+		if !isArray {
+			values = []interface{}{value}
+		}
+		for _, event := range listener.Events {
+			var param *core.FFIParam
+			for _, p := range event.Params {
+				if p.Name == name {
+					param = p
+					break
+				}
+			}
+			if param == nil {
+				return i18n.NewError(ctx, coremsgs.MsgListenerFilterParamNotFound, name, event.Name)
+			}
+			for _, v := range values {
+				if err := cm.checkParamSchema(ctx, v, param); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (cm *contractManager) AddContractListener(ctx context.Context, ns string, listener *core.ContractListenerInput) (output *core.ContractListener, err error) {
 	listener.ID = fftypes.NewUUID()
 	listener.Namespace = ns
@@ -520,6 +689,9 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 	} else if listener.Options.FirstEvent == "" {
 		listener.Options.FirstEvent = cm.getDefaultContractListenerOptions().FirstEvent
 	}
+	if listener.Options.Confirmations != nil && *listener.Options.Confirmations < 0 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidConfirmations, *listener.Options.Confirmations)
+	}
 
 	err = cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
 		// Namespace + Name must be unique
@@ -531,30 +703,34 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 			}
 		}
 
-		if listener.Event == nil {
-			if listener.EventPath == "" || listener.Interface == nil {
-				return i18n.NewError(ctx, coremsgs.MsgListenerNoEvent)
-			}
-			// Copy the event definition into the listener
-			if listener.Event, err = cm.resolveEvent(ctx, ns, listener.Interface, listener.EventPath); err != nil {
-				return err
-			}
-		} else {
-			listener.Interface = nil
+		if err = cm.resolveListenerEvents(ctx, ns, listener); err != nil {
+			return err
 		}
 
-		// Namespace + Topic + Location + Signature must be unique
-		listener.Signature = cm.blockchain.GenerateEventSignature(ctx, &listener.Event.FFIEventDefinition)
+		// Namespace + Topic + Location + Signature + Filters must be unique
+		signatures := make([]string, len(listener.Events))
+		for i, event := range listener.Events {
+			signatures[i] = cm.blockchain.GenerateEventSignature(ctx, &event.FFIEventDefinition)
+		}
+		listener.Signature = strings.Join(signatures, ";")
 		fb := database.ContractListenerQueryFactory.NewFilter(ctx)
-		if existing, _, err := cm.database.GetContractListeners(ctx, fb.And(
+		existing, _, err := cm.database.GetContractListeners(ctx, fb.And(
 			fb.Eq("namespace", listener.Namespace),
 			fb.Eq("topic", listener.Topic),
 			fb.Eq("location", listener.Location.Bytes()),
 			fb.Eq("signature", listener.Signature),
-		)); err != nil {
+		))
+		if err != nil {
 			return err
-		} else if len(existing) > 0 {
-			return i18n.NewError(ctx, coremsgs.MsgContractListenerExists)
+		}
+		for _, e := range existing {
+			var existingFilters fftypes.JSONObject
+			if e.Options != nil {
+				existingFilters = e.Options.Filters
+			}
+			if filtersEqual(existingFilters, listener.Options.Filters) {
+				return i18n.NewError(ctx, coremsgs.MsgContractListenerExists)
+			}
 		}
 		return nil
 	})
@@ -562,9 +738,19 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 		return nil, err
 	}
 
-	if err := cm.validateFFIEvent(ctx, &listener.Event.FFIEventDefinition); err != nil {
+	for _, event := range listener.Events {
+		if err := cm.validateFFIEvent(ctx, &event.FFIEventDefinition); err != nil {
+			return nil, err
+		}
+	}
+	if err := cm.validateListenerFilters(ctx, listener); err != nil {
 		return nil, err
 	}
+	// A listener on a single event is stored with just the event, as it was before multi-event listeners
+	listener.Event = listener.Events[0]
+	if len(listener.Events) == 1 {
+		listener.Events = nil
+	}
 	if err = cm.blockchain.AddContractListener(ctx, listener); err != nil {
 		return nil, err
 	}
@@ -653,6 +653,208 @@ func TestAddContractListenerByEventPath(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestAddContractListenerMultipleEventPaths(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	interfaceID := fftypes.NewUUID()
+
+	addressParam := &core.FFIParam{
+		Name:   "to",
+		Schema: fftypes.JSONAnyPtr(`{"type": "string"}`),
+	}
+	event1 := &core.FFIEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		FFIEventDefinition: core.FFIEventDefinition{
+			Name:   "Transfer",
+			Params: core.FFIParams{addressParam},
+		},
+	}
+	event2 := &core.FFIEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		FFIEventDefinition: core.FFIEventDefinition{
+			Name:   "Mint",
+			Params: core.FFIParams{addressParam},
+		},
+	}
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Interface: &core.FFIReference{
+				ID: interfaceID,
+			},
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Topic: "test-topic",
+			Options: &core.ContractListenerOptions{
+				Filters: fftypes.JSONObject{
+					"to": []interface{}{"0x111", "0x222"},
+				},
+			},
+		},
+		EventPath:  "Transfer",
+		EventPaths: []string{"Mint"},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), sub.Location).Return(sub.Location, nil)
+	mbi.On("GenerateEventSignature", context.Background(), &event1.FFIEventDefinition).Return("Transfer(address)")
+	mbi.On("GenerateEventSignature", context.Background(), &event2.FFIEventDefinition).Return("Mint(address)")
+	mdi.On("GetContractListeners", context.Background(), mock.Anything).Return([]*core.ContractListener{
+		{Options: &core.ContractListenerOptions{Filters: fftypes.JSONObject{"to": "0x333"}}},
+	}, nil, nil)
+	mbi.On("AddContractListener", context.Background(), mock.MatchedBy(func(l *core.ContractListenerInput) bool {
+		return len(l.Events) == 2 && l.Event == l.Events[0]
+	})).Return(nil)
+	mdi.On("GetFFIByID", context.Background(), interfaceID).Return(&core.FFI{}, nil)
+	mdi.On("GetFFIEvent", context.Background(), "ns1", interfaceID, "Transfer").Return(event1, nil)
+	mdi.On("GetFFIEvent", context.Background(), "ns1", interfaceID, "Mint").Return(event2, nil)
+	mdi.On("InsertContractListener", context.Background(), &sub.ContractListener).Return(nil)
+
+	result, err := cm.AddContractListener(context.Background(), "ns1", sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "Transfer(address);Mint(address)", result.Signature)
+	assert.Equal(t, "Transfer", result.Event.Name)
+	assert.Len(t, result.Events, 2)
+	assert.Equal(t, "Mint", result.Events[1].Name)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerDuplicateEvent(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	event := &core.FFISerializedEvent{
+		FFIEventDefinition: core.FFIEventDefinition{Name: "changed"},
+	}
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event:  event,
+			Events: core.FFISerializedEvents{event},
+			Topic:  "test-topic",
+		},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), sub.Location).Return(sub.Location, nil)
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10483", err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterParamNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &core.FFISerializedEvent{
+				FFIEventDefinition: core.FFIEventDefinition{Name: "changed"},
+			},
+			Topic: "test-topic",
+			Options: &core.ContractListenerOptions{
+				Filters: fftypes.JSONObject{"to": "0x111"},
+			},
+		},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), sub.Location).Return(sub.Location, nil)
+	mbi.On("GenerateEventSignature", context.Background(), mock.Anything).Return("changed")
+	mdi.On("GetContractListeners", context.Background(), mock.Anything).Return(nil, nil, nil)
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10484.*to.*changed", err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterBadValue(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &core.FFISerializedEvent{
+				FFIEventDefinition: core.FFIEventDefinition{
+					Name: "changed",
+					Params: core.FFIParams{
+						{
+							Name:   "value",
+							Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`),
+						},
+					},
+				},
+			},
+			Topic: "test-topic",
+			Options: &core.ContractListenerOptions{
+				Filters: fftypes.JSONObject{"value": []interface{}{float64(1), "two"}},
+			},
+		},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), sub.Location).Return(sub.Location, nil)
+	mbi.On("GenerateEventSignature", context.Background(), mock.Anything).Return("changed")
+	mdi.On("GetContractListeners", context.Background(), mock.Anything).Return(nil, nil, nil)
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "does not validate", err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterConflict(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &core.FFISerializedEvent{},
+			Topic: "test-topic",
+			Options: &core.ContractListenerOptions{
+				Filters: fftypes.JSONObject{"to": "0x111"},
+			},
+		},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), sub.Location).Return(sub.Location, nil)
+	mbi.On("GenerateEventSignature", context.Background(), mock.Anything).Return("changed")
+	mdi.On("GetContractListeners", context.Background(), mock.Anything).Return([]*core.ContractListener{
+		{},
+		{Options: &core.ContractListenerOptions{Filters: fftypes.JSONObject{"to": "0x111"}}},
+	}, nil, nil)
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10383", err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
 func TestAddContractListenerBadLocation(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -678,6 +880,32 @@ func TestAddContractListenerBadLocation(t *testing.T) {
 	mbi.AssertExpectations(t)
 }
 
+func TestAddContractListenerBadConfirmations(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+
+	confirmations := -1
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Options: &core.ContractListenerOptions{
+				Confirmations: &confirmations,
+			},
+			Topic: "test-topic",
+		},
+		EventPath: "changed",
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), sub.Location).Return(sub.Location, nil)
+
+	_, err := cm.AddContractListener(context.Background(), "ns1", sub)
+	assert.Regexp(t, "FF10482", err)
+
+	mbi.AssertExpectations(t)
+}
+
 func TestAddContractListenerFFILookupFail(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -1141,6 +1369,12 @@ func TestGetFFIWithChildren(t *testing.T) {
 	mbi.On("GenerateEventSignature", mock.Anything, mock.MatchedBy(func(ev *core.FFIEventDefinition) bool {
 		return ev.Name == "event1"
 	})).Return("event1Sig")
+	mdb.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{
+		{ID: fftypes.NewUUID(), FFIErrorDefinition: core.FFIErrorDefinition{Name: "error1"}},
+	}, nil, nil)
+	mbi.On("GenerateErrorSignature", mock.Anything, mock.MatchedBy(func(errorDef *core.FFIErrorDefinition) bool {
+		return errorDef.Name == "error1"
+	})).Return("error1Sig")
 
 	_, err := cm.GetFFIWithChildren(context.Background(), "ns1", "ffi", "v1.0.0")
 	assert.NoError(t, err)
@@ -1176,6 +1410,12 @@ func TestGetFFIByIDWithChildren(t *testing.T) {
 	mbi.On("GenerateEventSignature", mock.Anything, mock.MatchedBy(func(ev *core.FFIEventDefinition) bool {
 		return ev.Name == "event1"
 	})).Return("event1Sig")
+	mdb.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{
+		{ID: fftypes.NewUUID(), FFIErrorDefinition: core.FFIErrorDefinition{Name: "error1"}},
+	}, nil, nil)
+	mbi.On("GenerateErrorSignature", mock.Anything, mock.MatchedBy(func(errorDef *core.FFIErrorDefinition) bool {
+		return errorDef.Name == "error1"
+	})).Return("error1Sig")
 
 	ffi, err := cm.GetFFIByIDWithChildren(context.Background(), cid)
 
@@ -1185,6 +1425,7 @@ func TestGetFFIByIDWithChildren(t *testing.T) {
 
 	assert.Equal(t, "method1", ffi.Methods[0].Name)
 	assert.Equal(t, "event1", ffi.Events[0].Name)
+	assert.Equal(t, "error1Sig", ffi.Errors[0].Signature)
 }
 
 func TestGetFFIByIDWithChildrenEventsFail(t *testing.T) {
@@ -1279,6 +1520,7 @@ func TestInvokeContract(t *testing.T) {
 
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
@@ -1319,6 +1561,7 @@ func TestInvokeContractConfirm(t *testing.T) {
 
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
@@ -1365,6 +1608,7 @@ func TestInvokeContractFail(t *testing.T) {
 
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
@@ -1412,7 +1656,7 @@ func TestInvokeContractFailResolve(t *testing.T) {
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
-	mbi.On("InvokeContract", mock.Anything, mock.AnythingOfType("*fftypes.UUID"), "key-resolved", req.Location, req.Method, req.Input).Return(nil)
+	mbi.On("InvokeContract", mock.Anything, mock.AnythingOfType("*fftypes.UUID"), "key-resolved", req.Location, req.Method, req.Input, req.Errors, req.Options).Return(nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req, false)
 
@@ -1422,6 +1666,7 @@ func TestInvokeContractFailResolve(t *testing.T) {
 func TestInvokeContractTXFail(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
 	mth := cm.txHelper.(*txcommonmocks.Helper)
 
 	req := &core.ContractCallRequest{
@@ -1437,6 +1682,7 @@ func TestInvokeContractTXFail(t *testing.T) {
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractInvoke).Return(nil, fmt.Errorf("pop"))
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req, false)
@@ -1444,6 +1690,146 @@ func TestInvokeContractTXFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestDeployContract(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+
+	req := &core.ContractDeployRequest{
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x4567"`),
+		Input:      []interface{}{"1"},
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
+		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainContractDeploy && op.Plugin == "mockblockchain"
+	})).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *core.PreparedOperation) bool {
+		data := op.Data.(blockchainContractDeployData)
+		return op.Type == core.OpTypeBlockchainContractDeploy && data.Request == req
+	})).Return(nil, nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "key-resolved", req.Key)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestDeployContractConfirm(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	msa := cm.syncasync.(*syncasyncmocks.Bridge)
+
+	req := &core.ContractDeployRequest{
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x4567"`),
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
+		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainContractDeploy
+	})).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *core.PreparedOperation) bool {
+		return op.Type == core.OpTypeBlockchainContractDeploy
+	})).Return(nil, nil)
+	msa.On("WaitForDeployOperation", mock.Anything, "ns1", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			send := args[3].(syncasync.RequestSender)
+			send(context.Background())
+		}).
+		Return(&core.Operation{}, nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req, true)
+
+	assert.NoError(t, err)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+	msa.AssertExpectations(t)
+}
+
+func TestDeployContractMissingContract(t *testing.T) {
+	cm := newTestContractManager()
+
+	req := &core.ContractDeployRequest{
+		Definition: fftypes.JSONAnyPtr(`[]`),
+	}
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req, false)
+
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestDeployContractFailNormalizeSigningKey(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &core.ContractDeployRequest{
+		Contract: fftypes.JSONAnyPtr(`"0x4567"`),
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req, false)
+
+	assert.Regexp(t, "pop", err)
+}
+
+func TestDeployContractTXFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+
+	req := &core.ContractDeployRequest{
+		Contract: fftypes.JSONAnyPtr(`"0x4567"`),
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractDeploy).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req, false)
+
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeployContractFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+
+	req := &core.ContractDeployRequest{
+		Contract: fftypes.JSONAnyPtr(`"0x4567"`),
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req, false)
+
+	assert.EqualError(t, err, "pop")
+
+	mom.AssertExpectations(t)
+}
+
 func TestInvokeContractMethodNotFound(t *testing.T) {
 	cm := newTestContractManager()
 	mdb := cm.database.(*databasemocks.Plugin)
@@ -1467,6 +1853,7 @@ func TestInvokeContractMethodNotFound(t *testing.T) {
 func TestInvokeContractMethodBadInput(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
 
 	req := &core.ContractCallRequest{
 		Type:      core.CallTypeInvoke,
@@ -1494,6 +1881,7 @@ func TestInvokeContractMethodBadInput(t *testing.T) {
 		},
 	}
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req, false)
 	assert.Regexp(t, "FF10304", err)
@@ -1519,11 +1907,12 @@ func TestQueryContract(t *testing.T) {
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
-	mbi.On("QueryContract", mock.Anything, req.Location, req.Method, req.Input, req.Options).Return(struct{}{}, nil)
+	mbi.On("QueryContract", mock.Anything, req.Location, req.Method, req.Input, []*core.FFIError{}, req.Options).Return(struct{}{}, nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req, false)
 
@@ -1548,6 +1937,7 @@ func TestCallContractInvalidType(t *testing.T) {
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
@@ -1784,6 +2174,7 @@ func TestInvokeContractAPI(t *testing.T) {
 
 	mim.On("NormalizeSigningKey", mock.Anything, "ns1", "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
 	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(api, nil)
+	mdb.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{}, nil, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", core.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
@@ -1907,6 +2298,12 @@ func TestGetContractAPIInterface(t *testing.T) {
 	mbi.On("GenerateEventSignature", mock.Anything, mock.MatchedBy(func(ev *core.FFIEventDefinition) bool {
 		return ev.Name == "event1"
 	})).Return("event1Sig")
+	mdb.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*core.FFIError{
+		{ID: fftypes.NewUUID(), FFIErrorDefinition: core.FFIErrorDefinition{Name: "error1"}},
+	}, nil, nil)
+	mbi.On("GenerateErrorSignature", mock.Anything, mock.MatchedBy(func(errorDef *core.FFIErrorDefinition) bool {
+		return errorDef.Name == "error1"
+	})).Return("error1Sig")
 
 	result, err := cm.GetContractAPIInterface(context.Background(), "ns1", "banana")
 
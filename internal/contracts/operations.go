@@ -22,8 +22,10 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 )
 
@@ -31,6 +33,10 @@ type blockchainInvokeData struct {
 	Request *core.ContractCallRequest `json:"request"`
 }
 
+type blockchainContractDeployData struct {
+	Request *core.ContractDeployRequest `json:"request"`
+}
+
 func addBlockchainInvokeInputs(op *core.Operation, req *core.ContractCallRequest) (err error) {
 	var reqJSON []byte
 	if reqJSON, err = json.Marshal(req); err == nil {
@@ -48,6 +54,23 @@ func retrieveBlockchainInvokeInputs(ctx context.Context, op *core.Operation) (*c
 	return &req, nil
 }
 
+func addBlockchainDeployInputs(op *core.Operation, req *core.ContractDeployRequest) (err error) {
+	var reqJSON []byte
+	if reqJSON, err = json.Marshal(req); err == nil {
+		err = json.Unmarshal(reqJSON, &op.Input)
+	}
+	return err
+}
+
+func retrieveBlockchainDeployInputs(ctx context.Context, op *core.Operation) (*core.ContractDeployRequest, error) {
+	var req core.ContractDeployRequest
+	s := op.Input.String()
+	if err := json.Unmarshal([]byte(s), &req); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, s)
+	}
+	return &req, nil
+}
+
 func (cm *contractManager) PrepareOperation(ctx context.Context, op *core.Operation) (*core.PreparedOperation, error) {
 	switch op.Type {
 	case core.OpTypeBlockchainInvoke:
@@ -57,6 +80,13 @@ func (cm *contractManager) PrepareOperation(ctx context.Context, op *core.Operat
 		}
 		return opBlockchainInvoke(op, req), nil
 
+	case core.OpTypeBlockchainContractDeploy:
+		req, err := retrieveBlockchainDeployInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		return opBlockchainContractDeploy(op, req), nil
+
 	default:
 		return nil, i18n.NewError(ctx, coremsgs.MsgOperationNotSupported, op.Type)
 	}
@@ -66,7 +96,16 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *core.PreparedOp
 	switch data := op.Data.(type) {
 	case blockchainInvokeData:
 		req := data.Request
-		return nil, false, cm.blockchain.InvokeContract(ctx, op.NamespacedIDString(), req.Key, req.Location, req.Method, req.Input, req.Options)
+		err = cm.blockchain.InvokeContract(ctx, op.NamespacedIDString(), req.Key, req.Location, req.Method, req.Input, req.Errors, req.Options)
+		if revert := blockchain.GetRevert(err); revert != nil {
+			// Record the decoded revert on the failed operation
+			outputs = fftypes.JSONObject{"revert": revert}
+		}
+		return outputs, false, err
+
+	case blockchainContractDeployData:
+		req := data.Request
+		return nil, false, cm.blockchain.DeployContract(ctx, op.NamespacedIDString(), req.Key, req.Definition, req.Contract, req.Input, req.Options)
 
 	default:
 		return nil, false, i18n.NewError(ctx, coremsgs.MsgOperationDataIncorrect, op.Data)
@@ -74,20 +113,55 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *core.PreparedOp
 }
 
 func (cm *contractManager) OnOperationUpdate(ctx context.Context, op *core.Operation, update *operations.OperationUpdate) error {
-	// Special handling for OpTypeBlockchainInvoke, which writes an event when it succeeds or fails
-	if op.Type == core.OpTypeBlockchainInvoke {
-		if update.Status == core.OpStatusSucceeded {
-			event := core.NewEvent(core.EventTypeBlockchainInvokeOpSucceeded, op.Namespace, op.ID, op.Transaction, "")
-			if err := cm.database.InsertEvent(ctx, event); err != nil {
-				return err
-			}
+	// Special handling for blockchain invoke and deploy operations, which write an event when they succeed or fail
+	var succeeded, failed core.EventType
+	switch op.Type {
+	case core.OpTypeBlockchainInvoke:
+		succeeded, failed = core.EventTypeBlockchainInvokeOpSucceeded, core.EventTypeBlockchainInvokeOpFailed
+	case core.OpTypeBlockchainContractDeploy:
+		succeeded, failed = core.EventTypeBlockchainContractDeployOpSucceeded, core.EventTypeBlockchainContractDeployOpFailed
+	default:
+		return nil
+	}
+	if update.Status == core.OpStatusSucceeded {
+		event := core.NewEvent(succeeded, op.Namespace, op.ID, op.Transaction, "")
+		if err := cm.database.InsertEvent(ctx, event); err != nil {
+			return err
 		}
-		if update.Status == core.OpStatusFailed {
-			event := core.NewEvent(core.EventTypeBlockchainInvokeOpFailed, op.Namespace, op.ID, op.Transaction, "")
-			if err := cm.database.InsertEvent(ctx, event); err != nil {
+	}
+	if update.Status == core.OpStatusFailed {
+		if op.Type == core.OpTypeBlockchainInvoke {
+			if err := cm.decodeInvokeRevert(ctx, op, update); err != nil {
 				return err
 			}
 		}
+		event := core.NewEvent(failed, op.Namespace, op.ID, op.Transaction, "")
+		if err := cm.database.InsertEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeInvokeRevert decodes the revert data of a failed invoke against the error definitions of the interface,
+// which are resolved from the request stored on the operation
+func (cm *contractManager) decodeInvokeRevert(ctx context.Context, op *core.Operation, update *operations.OperationUpdate) error {
+	req, err := retrieveBlockchainInvokeInputs(ctx, op)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to decode the revert of operation %s: %s", op.ID, err)
+		return nil
+	}
+	errors := req.Errors
+	if errors == nil && req.Interface != nil {
+		if errors, err = cm.getFFIErrors(ctx, req.Interface); err != nil {
+			return err
+		}
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	if revert := cm.blockchain.DecodeOperationRevert(ctx, update.Output, errors); revert != nil {
+		update.Output["revert"] = revert
 	}
 	return nil
 }
@@ -100,3 +174,12 @@ func opBlockchainInvoke(op *core.Operation, req *core.ContractCallRequest) *core
 		Data:      blockchainInvokeData{Request: req},
 	}
 }
+
+func opBlockchainContractDeploy(op *core.Operation, req *core.ContractDeployRequest) *core.PreparedOperation {
+	return &core.PreparedOperation{
+		ID:        op.ID,
+		Namespace: op.Namespace,
+		Type:      op.Type,
+		Data:      blockchainContractDeployData{Request: req},
+	}
+}
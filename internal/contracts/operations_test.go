@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -55,7 +56,7 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 		return loc.String() == req.Location.String()
 	}), mock.MatchedBy(func(method *core.FFIMethod) bool {
 		return method.Name == req.Method.Name
-	}), req.Input, req.Options).Return(nil)
+	}), req.Input, req.Errors, req.Options).Return(nil)
 
 	po, err := cm.PrepareOperation(context.Background(), op)
 	assert.NoError(t, err)
@@ -69,6 +70,78 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 	mbi.AssertExpectations(t)
 }
 
+func TestRunBlockchainInvokeReverted(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		Type:      core.OpTypeBlockchainInvoke,
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	req := &core.ContractCallRequest{
+		Key:      "0x123",
+		Location: fftypes.JSONAnyPtr(`{"address":"0x1111"}`),
+		Method: &core.FFIMethod{
+			Name: "set",
+		},
+		Errors: []*core.FFIError{
+			{FFIErrorDefinition: core.FFIErrorDefinition{Name: "Unauthorized"}},
+		},
+	}
+	revert := &core.ContractRevert{
+		Error:  "Unauthorized",
+		Params: fftypes.JSONObject{},
+	}
+
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("InvokeContract", context.Background(), "ns1:"+op.ID.String(), "0x123", req.Location, req.Method, req.Input, req.Errors, req.Options).
+		Return(&blockchain.RevertError{Err: fmt.Errorf("pop"), Revert: revert})
+
+	outputs, complete, err := cm.RunOperation(context.Background(), opBlockchainInvoke(op, req))
+
+	assert.False(t, complete)
+	assert.Regexp(t, "pop", err)
+	assert.Equal(t, revert, outputs["revert"])
+
+	mbi.AssertExpectations(t)
+}
+
+func TestPrepareAndRunBlockchainContractDeploy(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		Type:      core.OpTypeBlockchainContractDeploy,
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	req := &core.ContractDeployRequest{
+		Key:        "0x123",
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x4567"`),
+		Input:      []interface{}{"1"},
+	}
+	err := addBlockchainDeployInputs(op, req)
+	assert.NoError(t, err)
+
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("DeployContract", context.Background(), "ns1:"+op.ID.String(), "0x123", mock.MatchedBy(func(definition *fftypes.JSONAny) bool {
+		return definition.String() == req.Definition.String()
+	}), mock.MatchedBy(func(contract *fftypes.JSONAny) bool {
+		return contract.String() == req.Contract.String()
+	}), req.Input, req.Options).Return(nil)
+
+	po, err := cm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, req, po.Data.(blockchainContractDeployData).Request)
+
+	_, complete, err := cm.RunOperation(context.Background(), po)
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
 func TestPrepareOperationNotSupported(t *testing.T) {
 	cm := newTestContractManager()
 
@@ -90,6 +163,18 @@ func TestPrepareOperationBlockchainInvokeBadInput(t *testing.T) {
 	assert.Regexp(t, "FF00127", err)
 }
 
+func TestPrepareOperationBlockchainContractDeployBadInput(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		Type:  core.OpTypeBlockchainContractDeploy,
+		Input: fftypes.JSONObject{"input": "bad"},
+	}
+
+	_, err := cm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF00127", err)
+}
+
 func TestRunOperationNotSupported(t *testing.T) {
 	cm := newTestContractManager()
 
@@ -151,3 +236,153 @@ func TestOperationUpdateInvokeFail(t *testing.T) {
 
 	mdi.AssertExpectations(t)
 }
+
+func TestOperationUpdateDeploySucceed(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: core.OpTypeBlockchainContractDeploy,
+	}
+	update := &operations.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+	}
+
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", context.Background(), mock.MatchedBy(func(event *core.Event) bool {
+		return event.Type == core.EventTypeBlockchainContractDeployOpSucceeded && *event.Reference == *op.ID
+	})).Return(nil)
+
+	err := cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationUpdateDeployFail(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: core.OpTypeBlockchainContractDeploy,
+	}
+	update := &operations.OperationUpdate{
+		Status: core.OpStatusFailed,
+	}
+
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", context.Background(), mock.MatchedBy(func(event *core.Event) bool {
+		return event.Type == core.EventTypeBlockchainContractDeployOpFailed && *event.Reference == *op.ID
+	})).Return(fmt.Errorf("pop"))
+
+	err := cm.OnOperationUpdate(context.Background(), op, update)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailDecodeRevert(t *testing.T) {
+	cm := newTestContractManager()
+
+	errors := []*core.FFIError{{FFIErrorDefinition: core.FFIErrorDefinition{Name: "CustomError"}}}
+	op := &core.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: core.OpTypeBlockchainInvoke,
+	}
+	err := addBlockchainInvokeInputs(op, &core.ContractCallRequest{Errors: errors})
+	assert.NoError(t, err)
+	update := &operations.OperationUpdate{
+		Status: core.OpStatusFailed,
+		Output: fftypes.JSONObject{"returnValue": "0x1234"},
+	}
+	revert := &core.ContractRevert{Error: "CustomError"}
+
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("DecodeOperationRevert", context.Background(), update.Output, mock.MatchedBy(func(e []*core.FFIError) bool {
+		return len(e) == 1 && e[0].Name == "CustomError"
+	})).Return(revert)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", context.Background(), mock.Anything).Return(nil)
+
+	err = cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+	assert.Equal(t, revert, update.Output["revert"])
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailDecodeRevertByInterface(t *testing.T) {
+	cm := newTestContractManager()
+
+	interfaceID := fftypes.NewUUID()
+	op := &core.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: core.OpTypeBlockchainInvoke,
+	}
+	err := addBlockchainInvokeInputs(op, &core.ContractCallRequest{Interface: interfaceID})
+	assert.NoError(t, err)
+	update := &operations.OperationUpdate{
+		Status: core.OpStatusFailed,
+		Output: fftypes.JSONObject{"returnValue": "0x1234"},
+	}
+
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("GenerateErrorSignature", context.Background(), mock.Anything).Return("CustomError()")
+	mbi.On("DecodeOperationRevert", context.Background(), update.Output, mock.Anything).Return(nil)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("GetFFIErrors", context.Background(), mock.Anything).Return([]*core.FFIError{
+		{FFIErrorDefinition: core.FFIErrorDefinition{Name: "CustomError"}},
+	}, nil, nil)
+	mdi.On("InsertEvent", context.Background(), mock.Anything).Return(nil)
+
+	err = cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+	assert.Nil(t, update.Output["revert"])
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailGetErrorsFail(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: core.OpTypeBlockchainInvoke,
+	}
+	err := addBlockchainInvokeInputs(op, &core.ContractCallRequest{Interface: fftypes.NewUUID()})
+	assert.NoError(t, err)
+	update := &operations.OperationUpdate{
+		Status: core.OpStatusFailed,
+	}
+
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("GetFFIErrors", context.Background(), mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err = cm.OnOperationUpdate(context.Background(), op, update)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailBadInput(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		ID:    fftypes.NewUUID(),
+		Type:  core.OpTypeBlockchainInvoke,
+		Input: fftypes.JSONObject{"interface": "bad"},
+	}
+	update := &operations.OperationUpdate{
+		Status: core.OpStatusFailed,
+	}
+
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", context.Background(), mock.Anything).Return(nil)
+
+	err := cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
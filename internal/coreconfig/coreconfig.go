@@ -47,6 +47,27 @@ const (
 	NamespaceMultipartyOrgDescription = "multiparty.org.description"
 	// NamespaceMultipartyOrgKey is the signing key allocated to the local root org within a namespace
 	NamespaceMultipartyOrgKey = "multiparty.org.key"
+	// NamespaceRetentionInterval is how often the retention policies for a namespace are applied.
+	// The retention keys are lower case, as keys inside the predefined namespaces array are matched case-sensitively
+	NamespaceRetentionInterval = "retention.interval"
+	// NamespaceRetentionBatchSize is the maximum number of rows deleted in a single database transaction when pruning
+	NamespaceRetentionBatchSize = "retention.batchsize"
+	// NamespaceRetentionEventsMaxAge is the age after which events are pruned
+	NamespaceRetentionEventsMaxAge = "retention.events.maxage"
+	// NamespaceRetentionEventsMaxRows is the maximum number of events retained
+	NamespaceRetentionEventsMaxRows = "retention.events.maxrows"
+	// NamespaceRetentionMessagesMaxAge is the age after which messages are pruned
+	NamespaceRetentionMessagesMaxAge = "retention.messages.maxage"
+	// NamespaceRetentionMessagesMaxRows is the maximum number of messages retained
+	NamespaceRetentionMessagesMaxRows = "retention.messages.maxrows"
+	// NamespaceRetentionOperationsMaxAge is the age after which operations are pruned
+	NamespaceRetentionOperationsMaxAge = "retention.operations.maxage"
+	// NamespaceRetentionOperationsMaxRows is the maximum number of operations retained
+	NamespaceRetentionOperationsMaxRows = "retention.operations.maxrows"
+	// NamespaceRetentionBlockchainEventsMaxAge is the age after which blockchain events are pruned
+	NamespaceRetentionBlockchainEventsMaxAge = "retention.blockchainevents.maxage"
+	// NamespaceRetentionBlockchainEventsMaxRows is the maximum number of blockchain events retained
+	NamespaceRetentionBlockchainEventsMaxRows = "retention.blockchainevents.maxrows"
 )
 
 // The following keys can be access from the root configuration.
@@ -130,8 +151,6 @@ var (
 	PrivateMessagingRetryInitDelay = ffc("privatemessaging.retry.initDelay")
 	// PrivateMessagingRetryMaxDelay the maximum delay to use for retry of data base operations
 	PrivateMessagingRetryMaxDelay = ffc("privatemessaging.retry.maxDelay")
-	// DatabaseType the type of the database interface plugin to use
-	HistogramsMaxChartRows = ffc("histograms.maxChartRows")
 	// TokensList is the root key containing a list of supported token connectors
 	TokensList = ffc("tokens")
 	// PluginsTokensList is the key containing a list of supported tokens plugins
@@ -258,6 +277,8 @@ var (
 	OrchestratorStartupAttempts = ffc("orchestrator.startupAttempts")
 	// SubscriptionDefaultsReadAhead default read ahead to enable for subscriptions that do not explicitly configure readahead
 	SubscriptionDefaultsReadAhead = ffc("subscription.defaults.batchSize")
+	// SubscriptionDefaultsBatchTimeout default time to wait for a batch to fill, for subscriptions with batch delivery that do not explicitly configure a timeout
+	SubscriptionDefaultsBatchTimeout = ffc("subscription.defaults.batchTimeout")
 	// SubscriptionMax maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)
 	SubscriptionMax = ffc("subscription.max")
 	// SubscriptionsRetryInitialDelay is the initial retry delay
@@ -312,7 +333,6 @@ func setDefaults() {
 	viper.SetDefault(string(BroadcastBatchSize), 200)
 	viper.SetDefault(string(BroadcastBatchPayloadLimit), "800Kb")
 	viper.SetDefault(string(BroadcastBatchTimeout), "1s")
-	viper.SetDefault(string(HistogramsMaxChartRows), 100)
 	viper.SetDefault(string(DebugPort), -1)
 	viper.SetDefault(string(DownloadWorkerCount), 10)
 	viper.SetDefault(string(DownloadRetryMaxAttempts), 100)
@@ -366,6 +386,7 @@ func setDefaults() {
 	viper.SetDefault(string(PrivateMessagingBatchTimeout), "1s")
 	viper.SetDefault(string(PrivateMessagingBatchPayloadLimit), "800Kb")
 	viper.SetDefault(string(SubscriptionDefaultsReadAhead), 0)
+	viper.SetDefault(string(SubscriptionDefaultsBatchTimeout), "50ms")
 	viper.SetDefault(string(SubscriptionMax), 500)
 	viper.SetDefault(string(SubscriptionsRetryInitialDelay), "250ms")
 	viper.SetDefault(string(SubscriptionsRetryMaxDelay), "30s")
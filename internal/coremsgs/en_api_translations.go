@@ -28,6 +28,7 @@ var (
 	APIParamsContractListenerNameOrID       = ffm("api.params.contractListenerNameOrID", "The contract listener name or ID")
 	APIParamsContractListenerID             = ffm("api.params.contractListenerID", "The contract listener ID")
 	APIParamsSubscriptionID                 = ffm("api.params.subscriptionID", "The subscription ID")
+	APIParamsDeadLetterID                   = ffm("api.params.deadLetterID", "The dead letter ID")
 	APIParamsBatchID                        = ffm("api.params.batchId", "The batch ID")
 	APIParamsBlockchainEventID              = ffm("api.params.blockchainEventID", "The blockchain event ID")
 	APIParamsCollectionID                   = ffm("api.params.collectionID", "The collection ID")
@@ -63,22 +64,25 @@ var (
 	APIParamsAutometa                       = ffm("api.params.autometa", "When set, FireFly will automatically generate JSON metadata with the upload details")
 	APIParamsContractAPIID                  = ffm("api.params.contractAPIID", "The ID of the contract API")
 
-	APIEndpointsAdminDeleteConfigRecord = ffm("api.endpoints.adminDeleteConfigRecord", "Deletes a configuration record from the database")
-	APIEndpointsAdminGetConfigRecord    = ffm("api.endpoints.adminGetConfigRecord", "Gets a configuration record from the database")
-	APIEndpointsAdminGetConfigRecords   = ffm("api.endpoints.adminGetConfigRecords", "Lists configuration records from the database")
-	APIEndpointsAdminGetConfig          = ffm("api.endpoints.adminGetConfig", "Gets the full configuration for this node")
-	APIEndpointsAdminGetNamespaceByName = ffm("api.endpoints.adminGetNamespaceByName", "Gets a namespace by name")
-	APIEndpointsAdminGetNamespaces      = ffm("api.endpoints.adminGetNamespaces", "List namespaces")
-	APIEndpointsAdminGetOpByID          = ffm("api.endpoints.adminGetOpByID", "Gets an operation by ID")
-	APIEndpointsAdminGetOps             = ffm("api.endpoints.adminGetOps", "Lists operations")
-	APIEndpointsAdminPutConfigRecord    = ffm("api.endpoints.adminPutConfigRecord", "Sets a configuration key in the database. This will override matching configuration keys set in the core config file.")
-	APIEndpointsAdminPostResetConfig    = ffm("api.endpoints.adminPostResetConfig", "Restarts FireFly Core HTTP servers and apply all configuration updates. This will apply any new changes that have been made to the core config file, or through the admin API.")
-	APIEndpointsAdminPatchOpByID        = ffm("api.endpoints.adminPatchOpByID", "Updates an operation by ID")
-	APIEndpointsAdminGetListenerByID    = ffm("api.endpoints.adminGetListenerByID", "Gets a contract listener by ID")
-	APIEndpointsAdminGetListeners       = ffm("api.endpoints.adminGetListeners", "Lists contract listeners")
+	APIEndpointsAdminDeleteConfigRecord  = ffm("api.endpoints.adminDeleteConfigRecord", "Deletes a configuration record from the database")
+	APIEndpointsAdminGetConfigRecord     = ffm("api.endpoints.adminGetConfigRecord", "Gets a configuration record from the database")
+	APIEndpointsAdminGetConfigRecords    = ffm("api.endpoints.adminGetConfigRecords", "Lists configuration records from the database")
+	APIEndpointsAdminGetConfig           = ffm("api.endpoints.adminGetConfig", "Gets the full configuration for this node")
+	APIEndpointsAdminGetNamespaceByName  = ffm("api.endpoints.adminGetNamespaceByName", "Gets a namespace by name")
+	APIEndpointsAdminGetNamespaces       = ffm("api.endpoints.adminGetNamespaces", "List namespaces")
+	APIEndpointsAdminGetNamespaceExport  = ffm("api.endpoints.adminGetNamespaceExport", "Exports every record of a namespace as newline delimited JSON")
+	APIEndpointsAdminPostNamespaceImport = ffm("api.endpoints.adminPostNamespaceImport", "Imports the newline delimited JSON from a namespace export into the namespace, which must not contain any records")
+	APIEndpointsAdminGetOpByID           = ffm("api.endpoints.adminGetOpByID", "Gets an operation by ID")
+	APIEndpointsAdminGetOps              = ffm("api.endpoints.adminGetOps", "Lists operations")
+	APIEndpointsAdminPutConfigRecord     = ffm("api.endpoints.adminPutConfigRecord", "Sets a configuration key in the database. This will override matching configuration keys set in the core config file.")
+	APIEndpointsAdminPostResetConfig     = ffm("api.endpoints.adminPostResetConfig", "Restarts FireFly Core HTTP servers and apply all configuration updates. This will apply any new changes that have been made to the core config file, or through the admin API.")
+	APIEndpointsAdminPatchOpByID         = ffm("api.endpoints.adminPatchOpByID", "Updates an operation by ID")
+	APIEndpointsAdminGetListenerByID     = ffm("api.endpoints.adminGetListenerByID", "Gets a contract listener by ID")
+	APIEndpointsAdminGetListeners        = ffm("api.endpoints.adminGetListeners", "Lists contract listeners")
 
 	APIEndpointsDeleteContractListener          = ffm("api.endpoints.deleteContractListener", "Deletes a contract listener referenced by its name or its ID")
 	APIEndpointsDeleteSubscription              = ffm("api.endpoints.deleteSubscription", "Deletes a subscription")
+	APIEndpointsGetAggregate                    = ffm("api.endpoints.getAggregate", "Gets the count of records matching the filter in a database collection, and optional metrics over numeric fields, grouped by the values of one or more fields")
 	APIEndpointsGetBatchBbyID                   = ffm("api.endpoints.getBatchByID", "Gets a message batch")
 	APIEndpointsGetBatches                      = ffm("api.endpoints.getBatches", "Gets a list of message batches")
 	APIEndpointsGetBlockchainEventByID          = ffm("api.endpoints.getBlockchainEventByID", "Gets a blockchain event")
@@ -97,6 +101,8 @@ var (
 	APIEndpointsGetData                         = ffm("api.endpoints.getData", "Gets a list of data items")
 	APIEndpointsGetDatatypeByName               = ffm("api.endpoints.getDatatypeByName", "Gets a datatype by its name and version")
 	APIEndpointsGetDatatypes                    = ffm("api.endpoints.getDatatypes", "Gets a list of datatypes that have been published")
+	APIEndpointsGetDeadLetterByID               = ffm("api.endpoints.getDeadLetterByID", "Gets a dead letter by its ID")
+	APIEndpointsGetDeadLetters                  = ffm("api.endpoints.getDeadLetters", "Gets a list of events that could not be delivered to a subscription, after all retries were exhausted")
 	APIEndpointsGetEventByID                    = ffm("api.endpoints.eventID", "Gets an event by its ID")
 	APIEndpointsGetEvents                       = ffm("api.endpoints.getEvents", "Gets a list of events")
 	APIEndpointsGetGroupByHash                  = ffm("api.endpoints.getGroupByHash", "Gets a group by its ID (hash)")
@@ -122,6 +128,7 @@ var (
 	APIEndpointsGetNetworkOrgs                  = ffm("api.endpoints.APIEndpointsGetNetworkOrgs", "Gets a list of orgs in the network")
 	APIEndpointsGetOpByID                       = ffm("api.endpoints.getOpByID", "Gets an operation by ID")
 	APIEndpointsGetOps                          = ffm("api.endpoints.getOps", "Gets a a list of operations")
+	APIEndpointsGetSearch                       = ffm("api.endpoints.getSearch", "Searches the text of messages, data, identities, token pools and contract interfaces in the namespace, returning the best matches first")
 	APIEndpointsGetStatusBatchManager           = ffm("api.endpoints.getStatusBatchManager", "Gets the status of the batch manager")
 	APIEndpointsGetPins                         = ffm("api.endpoints.getPins", "Queries the list of pins received from the blockchain")
 	APIEndpointsGetStatusWebSockets             = ffm("api.endpoints.getStatusWebSockets", "Gets the status of the current WebSocket connections to this node")
@@ -150,6 +157,7 @@ var (
 	APIEndpointsPostContractInterfaceGenerate   = ffm("api.endpoints.postContractInterfaceGenerate", "A convenience method to convert a blockchain specific smart contract format into a FireFly Interface format. The specific blockchain plugin in use must support this functionality.")
 	APIEndpointsPostContractInterfaceInvoke     = ffm("api.endpoints.postContractInterfaceInvoke", "Invokes a method on a smart contract that matches a given contract interface. Performs a blockchain transaction.")
 	APIEndpointsPostContractInterfaceQuery      = ffm("api.endpoints.postContractInterfaceQuery", "Queries a method on a smart contract that matches a given contract interface. Performs a read-only query.")
+	APIEndpointsPostContractDeploy              = ffm("api.endpoints.postContractDeploy", "Deploys a new smart contract. Performs a blockchain transaction, and the resulting contract location is recorded in the output of the operation")
 	APIEndpointsPostContractInvoke              = ffm("api.endpoints.postContractInvoke", "Invokes a method on a smart contract. Performs a blockchain transaction.")
 	APIEndpointsPostContractQuery               = ffm("api.endpoints.postContractQuery", "Queries a method on a smart contract. Performs a read-only query.")
 	APIEndpointsPostData                        = ffm("api.endpoints.postData", "Creates a new data item in this FireFly node")
@@ -167,6 +175,7 @@ var (
 	APIEndpointsPostNewOrganization             = ffm("api.endpoints.postNewOrganization", "Registers a new org in the network")
 	APIEndpointsPostNewSubscription             = ffm("api.endpoints.postNewSubscription", "Creates a new subscription for an application to receive events from FireFly")
 	APIEndpointsPostOpRetry                     = ffm("api.endpoints.postOpRetry", "Retries a failed operation")
+	APIEndpointsPostSubscriptionReset           = ffm("api.endpoints.postSubscriptionReset", "Moves a durable subscription to a new position in the event stream, restarting delivery from that position")
 	APIEndpointsPostTokenApproval               = ffm("api.endpoints.postTokenApproval", "Creates a token approval")
 	APIEndpointsPostTokenBurn                   = ffm("api.endpoints.postTokenBurn", "Burns some tokens")
 	APIEndpointsPostTokenMint                   = ffm("api.endpoints.postTokenMint", "Mints some tokens")
@@ -184,11 +193,19 @@ var (
 	APIFilterSkipDesc          = ffm("api.filterSkip", "The number of records to skip (max: %d). Unsuitable for bulk operations")
 	APIFilterLimitDesc         = ffm("api.filterLimit", "The maximum number of records to return (max: %d)")
 	APIFilterCountDesc         = ffm("api.filterCount", "Return a total count as well as items (adds extra database processing)")
+	APIFilterAfterDesc         = ffm("api.filterAfter", "Return the page of records after this cursor, taken from the 'next' field of a previous response")
+	APIFilterBeforeDesc        = ffm("api.filterBefore", "Return the page of records before this cursor, taken from the 'prev' field of a previous response")
 	APIFetchDataDesc           = ffm("api.fetchData", "Fetch the data and include it in the messages returned")
 	APIConfirmQueryParam       = ffm("api.confirmQueryParam", "When true the HTTP request blocks until the message is confirmed")
 	APIHistogramStartTimeParam = ffm("api.histogramStartTime", "Start time of the data to be fetched")
 	APIHistogramEndTimeParam   = ffm("api.histogramEndTime", "End time of the data to be fetched")
 	APIHistogramBucketsParam   = ffm("api.histogramBuckets", "Number of buckets between start time and end time")
+	APIHistogramGroupByParam   = ffm("api.histogramGroupBy", "Field to break down the count in each bucket by. Defaults to the type of the record, where the collection has one")
+	APIAggregateGroupByParam   = ffm("api.aggregateGroupBy", "Fields to group the records by, comma separated or repeated. All matching records are a single group if not set")
+	APISearchTextParam         = ffm("api.searchText", "The text to search for. Records matching all of the words are returned")
+	APISearchTypeParam         = ffm("api.searchType", "Types of record to search, comma separated or repeated. One of 'message', 'data', 'identity', 'tokenpool' or 'ffi'. All types are searched if not set")
+	APISearchLimitParam        = ffm("api.searchLimit", "The maximum number of results to return (default: 25, max: 100)")
+	APIAggregateMetricParam    = ffm("api.aggregateMetric", "Metrics to calculate for each group in addition to the count, such as 'sum:amount'. Can be repeated. Supported functions are 'sum', 'min' and 'max' on numeric fields")
 	APIIntegerDescription      = ffm("api.integer", "An integer. You are recommended to use a JSON string. A JSON number can be used for values up to the safe maximum.")
 
 	APISmartContractDetails      = ffm("api.smartContractDetails", "Additional smart contract details")
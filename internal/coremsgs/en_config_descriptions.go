@@ -68,15 +68,17 @@ var (
 
 	ConfigBlockchainEthereumAddressResolverProxyURL = ffc("config.blockchain.ethereum.addressResolver.proxy.url", "Optional HTTP proxy server to use when connecting to the Address Resolver", "URL "+i18n.StringType)
 
-	ConfigBlockchainEthereumEthconnectBatchSize    = ffc("config.blockchain.ethereum.ethconnect.batchSize", "The number of events Ethconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
-	ConfigBlockchainEthereumEthconnectBatchTimeout = ffc("config.blockchain.ethereum.ethconnect.batchTimeout", "How long Ethconnect should wait for new events to arrive and fill a batch, before sending the batch to FireFly core. Only applies when automatically creating a new event stream", i18n.TimeDurationType)
-	ConfigBlockchainEthereumEthconnectInstance     = ffc("config.blockchain.ethereum.ethconnect.instance", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain (deprecated - use fireflyContract[].address)", "Address "+i18n.StringType)
-	ConfigBlockchainEthereumEthconnectFromBlock    = ffc("config.blockchain.ethereum.ethconnect.fromBlock", "The first event this FireFly instance should listen to from the BatchPin smart contract. Default=0. Only affects initial creation of the event stream (deprecated - use fireflyContract[].fromBlock)", "Address "+i18n.StringType)
-	ConfigBlockchainEthereumEthconnectPrefixLong   = ffc("config.blockchain.ethereum.ethconnect.prefixLong", "The prefix that will be used for Ethconnect specific HTTP headers when FireFly makes requests to Ethconnect", i18n.StringType)
-	ConfigBlockchainEthereumEthconnectPrefixShort  = ffc("config.blockchain.ethereum.ethconnect.prefixShort", "The prefix that will be used for Ethconnect specific query parameters when FireFly makes requests to Ethconnect", i18n.StringType)
-	ConfigBlockchainEthereumEthconnectTopic        = ffc("config.blockchain.ethereum.ethconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single ethconnect", i18n.StringType)
-	ConfigBlockchainEthereumEthconnectURL          = ffc("config.blockchain.ethereum.ethconnect.url", "The URL of the Ethconnect instance", "URL "+i18n.StringType)
-	ConfigBlockchainEthereumEthconnectProxyURL     = ffc("config.blockchain.ethereum.ethconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Ethconnect", "URL "+i18n.StringType)
+	ConfigBlockchainEthereumEthconnectBatchSize           = ffc("config.blockchain.ethereum.ethconnect.batchSize", "The number of events Ethconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
+	ConfigBlockchainEthereumEthconnectBatchTimeout        = ffc("config.blockchain.ethereum.ethconnect.batchTimeout", "How long Ethconnect should wait for new events to arrive and fill a batch, before sending the batch to FireFly core. Only applies when automatically creating a new event stream", i18n.TimeDurationType)
+	ConfigBlockchainEthereumEthconnectConfirmations       = ffc("config.blockchain.ethereum.ethconnect.confirmations", "The number of blocks that must be mined on top of an event before it is delivered to FireFly core. Events removed by a chain re-organization before this are discarded. Can be overridden for each contract listener", i18n.IntType)
+	ConfigBlockchainEthereumEthconnectHeadPollingInterval = ffc("config.blockchain.ethereum.ethconnect.headPollingInterval", "How often to query the head block of the chain while events are waiting for confirmations, so they are delivered even when no other activity is observed on the chain", i18n.TimeDurationType)
+	ConfigBlockchainEthereumEthconnectInstance            = ffc("config.blockchain.ethereum.ethconnect.instance", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain (deprecated - use fireflyContract[].address)", "Address "+i18n.StringType)
+	ConfigBlockchainEthereumEthconnectFromBlock           = ffc("config.blockchain.ethereum.ethconnect.fromBlock", "The first event this FireFly instance should listen to from the BatchPin smart contract. Default=0. Only affects initial creation of the event stream (deprecated - use fireflyContract[].fromBlock)", "Address "+i18n.StringType)
+	ConfigBlockchainEthereumEthconnectPrefixLong          = ffc("config.blockchain.ethereum.ethconnect.prefixLong", "The prefix that will be used for Ethconnect specific HTTP headers when FireFly makes requests to Ethconnect", i18n.StringType)
+	ConfigBlockchainEthereumEthconnectPrefixShort         = ffc("config.blockchain.ethereum.ethconnect.prefixShort", "The prefix that will be used for Ethconnect specific query parameters when FireFly makes requests to Ethconnect", i18n.StringType)
+	ConfigBlockchainEthereumEthconnectTopic               = ffc("config.blockchain.ethereum.ethconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single ethconnect", i18n.StringType)
+	ConfigBlockchainEthereumEthconnectURL                 = ffc("config.blockchain.ethereum.ethconnect.url", "The URL of the Ethconnect instance", "URL "+i18n.StringType)
+	ConfigBlockchainEthereumEthconnectProxyURL            = ffc("config.blockchain.ethereum.ethconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Ethconnect", "URL "+i18n.StringType)
 
 	ConfigBlockchainEthereumContractAddress   = ffc("config.blockchain.ethereum.fireflyContract[].address", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain", "Address "+i18n.StringType)
 	ConfigBlockchainEthereumContractFromBlock = ffc("config.blockchain.ethereum.fireflyContract[].fromBlock", "The first event this FireFly instance should listen to from the BatchPin smart contract. Default=0. Only affects initial creation of the event stream", "Address "+i18n.StringType)
@@ -84,16 +86,33 @@ var (
 	ConfigBlockchainEthereumFFTMURL      = ffc("config.blockchain.ethereum.fftm.url", "The URL of the FireFly Transaction Manager runtime, if enabled", i18n.StringType)
 	ConfigBlockchainEthereumFFTMProxyURL = ffc("config.blockchain.ethereum.fftm.proxy.url", "Optional HTTP proxy server to use when connecting to the Transaction Manager", i18n.StringType)
 
-	ConfigBlockchainFabricFabconnectBatchSize    = ffc("config.blockchain.fabric.fabconnect.batchSize", "The number of events Fabconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
-	ConfigBlockchainFabricFabconnectBatchTimeout = ffc("config.blockchain.fabric.fabconnect.batchTimeout", "The maximum amount of time to wait for a batch to complete", i18n.TimeDurationType)
-	ConfigBlockchainFabricFabconnectChaincode    = ffc("config.blockchain.fabric.fabconnect.chaincode", "The name of the Fabric chaincode that FireFly will use for BatchPin transactions (deprecated - use fireflyContract[].chaincode)", i18n.StringType)
-	ConfigBlockchainFabricFabconnectChannel      = ffc("config.blockchain.fabric.fabconnect.channel", "The Fabric channel that FireFly will use for BatchPin transactions", i18n.StringType)
-	ConfigBlockchainFabricFabconnectPrefixLong   = ffc("config.blockchain.fabric.fabconnect.prefixLong", "The prefix that will be used for Fabconnect specific HTTP headers when FireFly makes requests to Fabconnect", i18n.StringType)
-	ConfigBlockchainFabricFabconnectPrefixShort  = ffc("config.blockchain.fabric.fabconnect.prefixShort", "The prefix that will be used for Fabconnect specific query parameters when FireFly makes requests to Fabconnect", i18n.StringType)
-	ConfigBlockchainFabricFabconnectSigner       = ffc("config.blockchain.fabric.fabconnect.signer", "The Fabric signing key to use when submitting transactions to Fabconnect", i18n.StringType)
-	ConfigBlockchainFabricFabconnectTopic        = ffc("config.blockchain.fabric.fabconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single Fabconnect", i18n.StringType)
-	ConfigBlockchainFabricFabconnectURL          = ffc("config.blockchain.fabric.fabconnect.url", "The URL of the Fabconnect instance", "URL "+i18n.StringType)
-	ConfigBlockchainFabricFabconnectProxyURL     = ffc("config.blockchain.fabric.fabconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Fabconnect", "URL "+i18n.StringType)
+	ConfigBlockchainEthRPCRPCURL      = ffc("config.blockchain.ethrpc.rpc.url", "The URL of the JSON-RPC endpoint of the Ethereum node", "URL "+i18n.StringType)
+	ConfigBlockchainEthRPCRPCProxyURL = ffc("config.blockchain.ethrpc.rpc.proxy.url", "Optional HTTP proxy server to use when connecting to the Ethereum node", "URL "+i18n.StringType)
+	ConfigBlockchainEthRPCRPCChainID  = ffc("config.blockchain.ethrpc.rpc.chainId", "The chain ID used when signing transactions. Queried from the node with eth_chainId when not set", i18n.IntType)
+
+	ConfigBlockchainEthRPCKeystorePath         = ffc("config.blockchain.ethrpc.keystore.path", "The directory containing the V3 keystore files for the signing keys used by this node", i18n.StringType)
+	ConfigBlockchainEthRPCKeystorePasswordFile = ffc("config.blockchain.ethrpc.keystore.passwordFile", "A file containing the password used to decrypt the keystore files", i18n.StringType)
+
+	ConfigBlockchainEthRPCEventsPollingInterval = ffc("config.blockchain.ethrpc.events.pollingInterval", "How often to poll the node for new blocks, event logs and transaction receipts", i18n.TimeDurationType)
+	ConfigBlockchainEthRPCEventsBlockRange      = ffc("config.blockchain.ethrpc.events.blockRange", "The maximum number of blocks to query in a single eth_getLogs request", i18n.IntType)
+	ConfigBlockchainEthRPCEventsConfirmations   = ffc("config.blockchain.ethrpc.events.confirmations", "The number of blocks that must be mined on top of an event before it is delivered to FireFly core. Events removed by a chain re-organization before this are discarded. Can be overridden for each contract listener", i18n.IntType)
+	ConfigBlockchainEthRPCEventsStateFile       = ffc("config.blockchain.ethrpc.events.stateFile", "A file used to persist contract listeners, event checkpoints and pending transactions across restarts. Required", i18n.StringType)
+
+	ConfigBlockchainEthRPCContractAddress   = ffc("config.blockchain.ethrpc.fireflyContract[].address", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain", "Address "+i18n.StringType)
+	ConfigBlockchainEthRPCContractFromBlock = ffc("config.blockchain.ethrpc.fireflyContract[].fromblock", "The first block to query for events from the BatchPin smart contract - 'oldest', 'newest' or a block number. Only used when no checkpoint has been stored", i18n.StringType)
+
+	ConfigBlockchainFabricFabconnectBatchSize           = ffc("config.blockchain.fabric.fabconnect.batchSize", "The number of events Fabconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
+	ConfigBlockchainFabricFabconnectBatchTimeout        = ffc("config.blockchain.fabric.fabconnect.batchTimeout", "The maximum amount of time to wait for a batch to complete", i18n.TimeDurationType)
+	ConfigBlockchainFabricFabconnectConfirmations       = ffc("config.blockchain.fabric.fabconnect.confirmations", "The number of blocks that must be committed on top of an event before it is delivered to FireFly core. Can be overridden for each contract listener", i18n.IntType)
+	ConfigBlockchainFabricFabconnectHeadPollingInterval = ffc("config.blockchain.fabric.fabconnect.headPollingInterval", "How often to query the head block of the chain while events are waiting for confirmations, so they are delivered even when no other activity is observed on the chain", i18n.TimeDurationType)
+	ConfigBlockchainFabricFabconnectChaincode           = ffc("config.blockchain.fabric.fabconnect.chaincode", "The name of the Fabric chaincode that FireFly will use for BatchPin transactions (deprecated - use fireflyContract[].chaincode)", i18n.StringType)
+	ConfigBlockchainFabricFabconnectChannel             = ffc("config.blockchain.fabric.fabconnect.channel", "The Fabric channel that FireFly will use for BatchPin transactions", i18n.StringType)
+	ConfigBlockchainFabricFabconnectPrefixLong          = ffc("config.blockchain.fabric.fabconnect.prefixLong", "The prefix that will be used for Fabconnect specific HTTP headers when FireFly makes requests to Fabconnect", i18n.StringType)
+	ConfigBlockchainFabricFabconnectPrefixShort         = ffc("config.blockchain.fabric.fabconnect.prefixShort", "The prefix that will be used for Fabconnect specific query parameters when FireFly makes requests to Fabconnect", i18n.StringType)
+	ConfigBlockchainFabricFabconnectSigner              = ffc("config.blockchain.fabric.fabconnect.signer", "The Fabric signing key to use when submitting transactions to Fabconnect", i18n.StringType)
+	ConfigBlockchainFabricFabconnectTopic               = ffc("config.blockchain.fabric.fabconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single Fabconnect", i18n.StringType)
+	ConfigBlockchainFabricFabconnectURL                 = ffc("config.blockchain.fabric.fabconnect.url", "The URL of the Fabconnect instance", "URL "+i18n.StringType)
+	ConfigBlockchainFabricFabconnectProxyURL            = ffc("config.blockchain.fabric.fabconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Fabconnect", "URL "+i18n.StringType)
 
 	ConfigBlockchainFabricContractChaincode = ffc("config.blockchain.fabric.fireflyContract[].chaincode", "The name of the Fabric chaincode that FireFly will use for BatchPin transactions", i18n.StringType)
 	ConfigBlockchainFabricContractFromBlock = ffc("config.blockchain.fabric.fireflyContract[].fromBlock", "The first event this FireFly instance should listen to from the BatchPin chaincode. Default=0. Only affects initial creation of the event stream", "Address "+i18n.StringType)
@@ -102,17 +121,34 @@ var (
 	ConfigPluginDatabaseName = ffc("config.plugins.database[].name", "The name of the Database plugin", i18n.StringType)
 	ConfigPluginDatabaseType = ffc("config.plugins.database[].type", "The type of the configured Database plugin", i18n.StringType)
 
-	ConfigPluginDatabasePostgresMaxConnIdleTime = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConnLifetime = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConns        = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresMaxIdleConns    = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresURL             = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
-
-	ConfigPluginDatabaseSqlite3MaxConnIdleTime = ffc("config.plugins.database[].sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigPluginDatabaseSqlite3MaxConnLifetime = ffc("config.plugins.database[].sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigPluginDatabaseSqlite3MaxConns        = ffc("config.plugins.database[].sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigPluginDatabaseSqlite3MaxIdleConns    = ffc("config.plugins.database[].sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigPluginDatabaseSqlite3URL             = ffc("config.plugins.database[].sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigPluginDatabasePostgresMaxConnIdleTime          = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConnLifetime          = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConns                 = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresMaxIdleConns             = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresURL                      = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigPluginDatabasePostgresDataValueIndexesDatatype = ffc("config.plugins.database[].postgres.dataValueIndexes[].datatype", "The name of a datatype, to restrict the index to data of that datatype", i18n.StringType)
+	ConfigPluginDatabasePostgresDataValueIndexesPaths    = ffc("config.plugins.database[].postgres.dataValueIndexes[].paths", "Dot separated paths inside the JSON value of data to index, such as orderId or customer.name", "List "+i18n.StringType)
+	ConfigPluginDatabasePostgresReplicaURL               = ffc("config.plugins.database[].postgres.replica.url", "The PostgreSQL connection string for an optional read replica, which serves query-only API calls", i18n.StringType)
+	ConfigPluginDatabasePostgresReplicaMaxConnIdleTime   = ffc("config.plugins.database[].postgres.replica.maxConnIdleTime", "The maximum amount of time a read replica connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresReplicaMaxConnLifetime   = ffc("config.plugins.database[].postgres.replica.maxConnLifetime", "The maximum amount of time to keep a read replica connection open", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresReplicaMaxConns          = ffc("config.plugins.database[].postgres.replica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigPluginDatabasePostgresReplicaMaxIdleConns      = ffc("config.plugins.database[].postgres.replica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+
+	ConfigPluginDatabaseSqlite3MaxConnIdleTime          = ffc("config.plugins.database[].sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3MaxConnLifetime          = ffc("config.plugins.database[].sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3MaxConns                 = ffc("config.plugins.database[].sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlite3MaxIdleConns             = ffc("config.plugins.database[].sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlite3URL                      = ffc("config.plugins.database[].sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigPluginDatabaseSqlite3DataValueIndexesDatatype = ffc("config.plugins.database[].sqlite3.dataValueIndexes[].datatype", "The name of a datatype, to restrict the index to data of that datatype", i18n.StringType)
+	ConfigPluginDatabaseSqlite3DataValueIndexesPaths    = ffc("config.plugins.database[].sqlite3.dataValueIndexes[].paths", "Dot separated paths inside the JSON value of data to index, such as orderId or customer.name", "List "+i18n.StringType)
+
+	ConfigPluginDatabaseSqlitegoMaxConnIdleTime          = ffc("config.plugins.database[].sqlitego.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlitegoMaxConnLifetime          = ffc("config.plugins.database[].sqlitego.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlitegoMaxConns                 = ffc("config.plugins.database[].sqlitego.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlitegoMaxIdleConns             = ffc("config.plugins.database[].sqlitego.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlitegoURL                      = ffc("config.plugins.database[].sqlitego.url", "The SQLite connection string for the embedded pure Go database", i18n.StringType)
+	ConfigPluginDatabaseSqlitegoDataValueIndexesDatatype = ffc("config.plugins.database[].sqlitego.dataValueIndexes[].datatype", "The name of a datatype, to restrict the index to data of that datatype", i18n.StringType)
+	ConfigPluginDatabaseSqlitegoDataValueIndexesPaths    = ffc("config.plugins.database[].sqlitego.dataValueIndexes[].paths", "Dot separated paths inside the JSON value of data to index, such as orderId or customer.name", "List "+i18n.StringType)
 
 	ConfigPluginBlockchain     = ffc("config.plugins.blockchain", "The list of configured Blockchain plugins", i18n.StringType)
 	ConfigPluginBlockchainName = ffc("config.plugins.blockchain[].name", "The name of the configured Blockchain plugin", i18n.StringType)
@@ -133,15 +169,17 @@ var (
 
 	ConfigPluginBlockchainEthereumAddressResolverProxyURL = ffc("config.plugins.blockchain[].ethereum.addressResolver.proxy.url", "Optional HTTP proxy server to use when connecting to the Address Resolver", "URL "+i18n.StringType)
 
-	ConfigPluginBlockchainEthereumEthconnectBatchSize    = ffc("config.plugins.blockchain[].ethereum.ethconnect.batchSize", "The number of events Ethconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
-	ConfigPluginBlockchainEthereumEthconnectBatchTimeout = ffc("config.plugins.blockchain[].ethereum.ethconnect.batchTimeout", "How long Ethconnect should wait for new events to arrive and fill a batch, before sending the batch to FireFly core. Only applies when automatically creating a new event stream", i18n.TimeDurationType)
-	ConfigPluginBlockchainEthereumEthconnectInstance     = ffc("config.plugins.blockchain[].ethereum.ethconnect.instance", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain", "Address "+i18n.StringType)
-	ConfigPluginBlockchainEthereumEthconnectFromBlock    = ffc("config.plugins.blockchain[].ethereum.ethconnect.fromBlock", "The first event this FireFly instance should listen to from the BatchPin smart contract. Default=0. Only affects initial creation of the event stream", "Address "+i18n.StringType)
-	ConfigPluginBlockchainEthereumEthconnectPrefixLong   = ffc("config.plugins.blockchain[].ethereum.ethconnect.prefixLong", "The prefix that will be used for Ethconnect specific HTTP headers when FireFly makes requests to Ethconnect", i18n.StringType)
-	ConfigPluginBlockchainEthereumEthconnectPrefixShort  = ffc("config.plugins.blockchain[].ethereum.ethconnect.prefixShort", "The prefix that will be used for Ethconnect specific query parameters when FireFly makes requests to Ethconnect", i18n.StringType)
-	ConfigPluginBlockchainEthereumEthconnectTopic        = ffc("config.plugins.blockchain[].ethereum.ethconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single ethconnect", i18n.StringType)
-	ConfigPluginBlockchainEthereumEthconnectURL          = ffc("config.plugins.blockchain[].ethereum.ethconnect.url", "The URL of the Ethconnect instance", "URL "+i18n.StringType)
-	ConfigPluginBlockchainEthereumEthconnectProxyURL     = ffc("config.plugins.blockchain[].ethereum.ethconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Ethconnect", "URL "+i18n.StringType)
+	ConfigPluginBlockchainEthereumEthconnectBatchSize           = ffc("config.plugins.blockchain[].ethereum.ethconnect.batchSize", "The number of events Ethconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
+	ConfigPluginBlockchainEthereumEthconnectBatchTimeout        = ffc("config.plugins.blockchain[].ethereum.ethconnect.batchTimeout", "How long Ethconnect should wait for new events to arrive and fill a batch, before sending the batch to FireFly core. Only applies when automatically creating a new event stream", i18n.TimeDurationType)
+	ConfigPluginBlockchainEthereumEthconnectConfirmations       = ffc("config.plugins.blockchain[].ethereum.ethconnect.confirmations", "The number of blocks that must be mined on top of an event before it is delivered to FireFly core. Events removed by a chain re-organization before this are discarded. Can be overridden for each contract listener", i18n.IntType)
+	ConfigPluginBlockchainEthereumEthconnectHeadPollingInterval = ffc("config.plugins.blockchain[].ethereum.ethconnect.headPollingInterval", "How often to query the head block of the chain while events are waiting for confirmations, so they are delivered even when no other activity is observed on the chain", i18n.TimeDurationType)
+	ConfigPluginBlockchainEthereumEthconnectInstance            = ffc("config.plugins.blockchain[].ethereum.ethconnect.instance", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain", "Address "+i18n.StringType)
+	ConfigPluginBlockchainEthereumEthconnectFromBlock           = ffc("config.plugins.blockchain[].ethereum.ethconnect.fromBlock", "The first event this FireFly instance should listen to from the BatchPin smart contract. Default=0. Only affects initial creation of the event stream", "Address "+i18n.StringType)
+	ConfigPluginBlockchainEthereumEthconnectPrefixLong          = ffc("config.plugins.blockchain[].ethereum.ethconnect.prefixLong", "The prefix that will be used for Ethconnect specific HTTP headers when FireFly makes requests to Ethconnect", i18n.StringType)
+	ConfigPluginBlockchainEthereumEthconnectPrefixShort         = ffc("config.plugins.blockchain[].ethereum.ethconnect.prefixShort", "The prefix that will be used for Ethconnect specific query parameters when FireFly makes requests to Ethconnect", i18n.StringType)
+	ConfigPluginBlockchainEthereumEthconnectTopic               = ffc("config.plugins.blockchain[].ethereum.ethconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single ethconnect", i18n.StringType)
+	ConfigPluginBlockchainEthereumEthconnectURL                 = ffc("config.plugins.blockchain[].ethereum.ethconnect.url", "The URL of the Ethconnect instance", "URL "+i18n.StringType)
+	ConfigPluginBlockchainEthereumEthconnectProxyURL            = ffc("config.plugins.blockchain[].ethereum.ethconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Ethconnect", "URL "+i18n.StringType)
 
 	ConfigPluginBlockchainEthereumContractAddress   = ffc("config.plugins.blockchain[].ethereum.fireflyContract[].address", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain", "Address "+i18n.StringType)
 	ConfigPluginBlockchainEthereumContractFromBlock = ffc("config.plugins.blockchain[].ethereum.fireflyContract[].fromBlock", "The first event this FireFly instance should listen to from the BatchPin smart contract. Default=0. Only affects initial creation of the event stream", "Address "+i18n.StringType)
@@ -149,16 +187,33 @@ var (
 	ConfigPluginBlockchainEthereumFFTMURL      = ffc("config.plugins.blockchain[].ethereum.fftm.url", "The URL of the FireFly Transaction Manager runtime, if enabled", i18n.StringType)
 	ConfigPluginBlockchainEthereumFFTMProxyURL = ffc("config.plugins.blockchain[].ethereum.fftm.proxy.url", "Optional HTTP proxy server to use when connecting to the Transaction Manager", i18n.StringType)
 
-	ConfigPluginBlockchainFabricFabconnectBatchSize    = ffc("config.plugins.blockchain[].fabric.fabconnect.batchSize", "The number of events Fabconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
-	ConfigPluginBlockchainFabricFabconnectBatchTimeout = ffc("config.plugins.blockchain[].fabric.fabconnect.batchTimeout", "The maximum amount of time to wait for a batch to complete", i18n.TimeDurationType)
-	ConfigPluginBlockchainFabricFabconnectChaincode    = ffc("config.plugins.blockchain[].fabric.fabconnect.chaincode", "The name of the Fabric chaincode that FireFly will use for BatchPin transactions (deprecated - use fireflyContract[].chaincode)", i18n.StringType)
-	ConfigPluginBlockchainFabricFabconnectChannel      = ffc("config.plugins.blockchain[].fabric.fabconnect.channel", "The Fabric channel that FireFly will use for BatchPin transactions", i18n.StringType)
-	ConfigPluginBlockchainFabricFabconnectPrefixLong   = ffc("config.plugins.blockchain[].fabric.fabconnect.prefixLong", "The prefix that will be used for Fabconnect specific HTTP headers when FireFly makes requests to Fabconnect", i18n.StringType)
-	ConfigPluginBlockchainFabricFabconnectPrefixShort  = ffc("config.plugins.blockchain[].fabric.fabconnect.prefixShort", "The prefix that will be used for Fabconnect specific query parameters when FireFly makes requests to Fabconnect", i18n.StringType)
-	ConfigPluginBlockchainFabricFabconnectSigner       = ffc("config.plugins.blockchain[].fabric.fabconnect.signer", "The Fabric signing key to use when submitting transactions to Fabconnect", i18n.StringType)
-	ConfigPluginBlockchainFabricFabconnectTopic        = ffc("config.plugins.blockchain[].fabric.fabconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single Fabconnect", i18n.StringType)
-	ConfigPluginBlockchainFabricFabconnectURL          = ffc("config.plugins.blockchain[].fabric.fabconnect.url", "The URL of the Fabconnect instance", "URL "+i18n.StringType)
-	ConfigPluginBlockchainFabricFabconnectProxyURL     = ffc("config.plugins.blockchain[].fabric.fabconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Fabconnect", "URL "+i18n.StringType)
+	ConfigPluginBlockchainEthRPCRPCURL      = ffc("config.plugins.blockchain[].ethrpc.rpc.url", "The URL of the JSON-RPC endpoint of the Ethereum node", "URL "+i18n.StringType)
+	ConfigPluginBlockchainEthRPCRPCProxyURL = ffc("config.plugins.blockchain[].ethrpc.rpc.proxy.url", "Optional HTTP proxy server to use when connecting to the Ethereum node", "URL "+i18n.StringType)
+	ConfigPluginBlockchainEthRPCRPCChainID  = ffc("config.plugins.blockchain[].ethrpc.rpc.chainId", "The chain ID used when signing transactions. Queried from the node with eth_chainId when not set", i18n.IntType)
+
+	ConfigPluginBlockchainEthRPCKeystorePath         = ffc("config.plugins.blockchain[].ethrpc.keystore.path", "The directory containing the V3 keystore files for the signing keys used by this node", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystorePasswordFile = ffc("config.plugins.blockchain[].ethrpc.keystore.passwordFile", "A file containing the password used to decrypt the keystore files", i18n.StringType)
+
+	ConfigPluginBlockchainEthRPCEventsPollingInterval = ffc("config.plugins.blockchain[].ethrpc.events.pollingInterval", "How often to poll the node for new blocks, event logs and transaction receipts", i18n.TimeDurationType)
+	ConfigPluginBlockchainEthRPCEventsBlockRange      = ffc("config.plugins.blockchain[].ethrpc.events.blockRange", "The maximum number of blocks to query in a single eth_getLogs request", i18n.IntType)
+	ConfigPluginBlockchainEthRPCEventsConfirmations   = ffc("config.plugins.blockchain[].ethrpc.events.confirmations", "The number of blocks that must be mined on top of an event before it is delivered to FireFly core. Events removed by a chain re-organization before this are discarded. Can be overridden for each contract listener", i18n.IntType)
+	ConfigPluginBlockchainEthRPCEventsStateFile       = ffc("config.plugins.blockchain[].ethrpc.events.stateFile", "A file used to persist contract listeners, event checkpoints and pending transactions across restarts. Required", i18n.StringType)
+
+	ConfigPluginBlockchainEthRPCContractAddress   = ffc("config.plugins.blockchain[].ethrpc.fireflyContract[].address", "The Ethereum address of the FireFly BatchPin smart contract that has been deployed to the blockchain", "Address "+i18n.StringType)
+	ConfigPluginBlockchainEthRPCContractFromBlock = ffc("config.plugins.blockchain[].ethrpc.fireflyContract[].fromblock", "The first block to query for events from the BatchPin smart contract - 'oldest', 'newest' or a block number. Only used when no checkpoint has been stored", i18n.StringType)
+
+	ConfigPluginBlockchainFabricFabconnectBatchSize           = ffc("config.plugins.blockchain[].fabric.fabconnect.batchSize", "The number of events Fabconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
+	ConfigPluginBlockchainFabricFabconnectBatchTimeout        = ffc("config.plugins.blockchain[].fabric.fabconnect.batchTimeout", "The maximum amount of time to wait for a batch to complete", i18n.TimeDurationType)
+	ConfigPluginBlockchainFabricFabconnectConfirmations       = ffc("config.plugins.blockchain[].fabric.fabconnect.confirmations", "The number of blocks that must be committed on top of an event before it is delivered to FireFly core. Can be overridden for each contract listener", i18n.IntType)
+	ConfigPluginBlockchainFabricFabconnectHeadPollingInterval = ffc("config.plugins.blockchain[].fabric.fabconnect.headPollingInterval", "How often to query the head block of the chain while events are waiting for confirmations, so they are delivered even when no other activity is observed on the chain", i18n.TimeDurationType)
+	ConfigPluginBlockchainFabricFabconnectChaincode           = ffc("config.plugins.blockchain[].fabric.fabconnect.chaincode", "The name of the Fabric chaincode that FireFly will use for BatchPin transactions (deprecated - use fireflyContract[].chaincode)", i18n.StringType)
+	ConfigPluginBlockchainFabricFabconnectChannel             = ffc("config.plugins.blockchain[].fabric.fabconnect.channel", "The Fabric channel that FireFly will use for BatchPin transactions", i18n.StringType)
+	ConfigPluginBlockchainFabricFabconnectPrefixLong          = ffc("config.plugins.blockchain[].fabric.fabconnect.prefixLong", "The prefix that will be used for Fabconnect specific HTTP headers when FireFly makes requests to Fabconnect", i18n.StringType)
+	ConfigPluginBlockchainFabricFabconnectPrefixShort         = ffc("config.plugins.blockchain[].fabric.fabconnect.prefixShort", "The prefix that will be used for Fabconnect specific query parameters when FireFly makes requests to Fabconnect", i18n.StringType)
+	ConfigPluginBlockchainFabricFabconnectSigner              = ffc("config.plugins.blockchain[].fabric.fabconnect.signer", "The Fabric signing key to use when submitting transactions to Fabconnect", i18n.StringType)
+	ConfigPluginBlockchainFabricFabconnectTopic               = ffc("config.plugins.blockchain[].fabric.fabconnect.topic", "The websocket listen topic that the node should register on, which is important if there are multiple nodes using a single Fabconnect", i18n.StringType)
+	ConfigPluginBlockchainFabricFabconnectURL                 = ffc("config.plugins.blockchain[].fabric.fabconnect.url", "The URL of the Fabconnect instance", "URL "+i18n.StringType)
+	ConfigPluginBlockchainFabricFabconnectProxyURL            = ffc("config.plugins.blockchain[].fabric.fabconnect.proxy.url", "Optional HTTP proxy server to use when connecting to Fabconnect", "URL "+i18n.StringType)
 
 	ConfigPluginBlockchainFabricContractChaincode = ffc("config.plugins.blockchain[].fabric.fireflyContract[].chaincode", "The name of the Fabric chaincode that FireFly will use for BatchPin transactions", i18n.StringType)
 	ConfigPluginBlockchainFabricContractFromBlock = ffc("config.plugins.blockchain[].fabric.fireflyContract[].fromBlock", "The first event this FireFly instance should listen to from the BatchPin chaincode. Default=0. Only affects initial creation of the event stream", "Address "+i18n.StringType)
@@ -170,17 +225,34 @@ var (
 
 	ConfigDatabaseType = ffc("config.database.type", "The type of the database interface plugin to use", i18n.IntType)
 
-	ConfigDatabasePostgresMaxConnIdleTime = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConnLifetime = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConns        = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigDatabasePostgresMaxIdleConns    = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigDatabasePostgresURL             = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
-
-	ConfigDatabaseSqlite3MaxConnIdleTime = ffc("config.database.sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigDatabaseSqlite3MaxConnLifetime = ffc("config.database.sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigDatabaseSqlite3MaxConns        = ffc("config.database.sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigDatabaseSqlite3MaxIdleConns    = ffc("config.database.sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigDatabaseSqlite3URL             = ffc("config.database.sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigDatabasePostgresMaxConnIdleTime          = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConnLifetime          = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConns                 = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabasePostgresMaxIdleConns             = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabasePostgresURL                      = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigDatabasePostgresDataValueIndexesDatatype = ffc("config.database.postgres.dataValueIndexes[].datatype", "The name of a datatype, to restrict the index to data of that datatype", i18n.StringType)
+	ConfigDatabasePostgresDataValueIndexesPaths    = ffc("config.database.postgres.dataValueIndexes[].paths", "Dot separated paths inside the JSON value of data to index, such as orderId or customer.name", "List "+i18n.StringType)
+	ConfigDatabasePostgresReplicaURL               = ffc("config.database.postgres.replica.url", "The PostgreSQL connection string for an optional read replica, which serves query-only API calls", i18n.StringType)
+	ConfigDatabasePostgresReplicaMaxConnIdleTime   = ffc("config.database.postgres.replica.maxConnIdleTime", "The maximum amount of time a read replica connection can be idle", i18n.TimeDurationType)
+	ConfigDatabasePostgresReplicaMaxConnLifetime   = ffc("config.database.postgres.replica.maxConnLifetime", "The maximum amount of time to keep a read replica connection open", i18n.TimeDurationType)
+	ConfigDatabasePostgresReplicaMaxConns          = ffc("config.database.postgres.replica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigDatabasePostgresReplicaMaxIdleConns      = ffc("config.database.postgres.replica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+
+	ConfigDatabaseSqlite3MaxConnIdleTime          = ffc("config.database.sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3MaxConnLifetime          = ffc("config.database.sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3MaxConns                 = ffc("config.database.sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabaseSqlite3MaxIdleConns             = ffc("config.database.sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabaseSqlite3URL                      = ffc("config.database.sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigDatabaseSqlite3DataValueIndexesDatatype = ffc("config.database.sqlite3.dataValueIndexes[].datatype", "The name of a datatype, to restrict the index to data of that datatype", i18n.StringType)
+	ConfigDatabaseSqlite3DataValueIndexesPaths    = ffc("config.database.sqlite3.dataValueIndexes[].paths", "Dot separated paths inside the JSON value of data to index, such as orderId or customer.name", "List "+i18n.StringType)
+
+	ConfigDatabaseSqlitegoMaxConnIdleTime          = ffc("config.database.sqlitego.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabaseSqlitegoMaxConnLifetime          = ffc("config.database.sqlitego.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabaseSqlitegoMaxConns                 = ffc("config.database.sqlitego.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabaseSqlitegoMaxIdleConns             = ffc("config.database.sqlitego.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabaseSqlitegoURL                      = ffc("config.database.sqlitego.url", "The SQLite connection string for the embedded pure Go database", i18n.StringType)
+	ConfigDatabaseSqlitegoDataValueIndexesDatatype = ffc("config.database.sqlitego.dataValueIndexes[].datatype", "The name of a datatype, to restrict the index to data of that datatype", i18n.StringType)
+	ConfigDatabaseSqlitegoDataValueIndexesPaths    = ffc("config.database.sqlitego.dataValueIndexes[].paths", "Dot separated paths inside the JSON value of data to index, such as orderId or customer.name", "List "+i18n.StringType)
 
 	ConfigDataexchangeType = ffc("config.dataexchange.type", "The Data Exchange plugin to use", i18n.StringType)
 
@@ -221,14 +293,27 @@ var (
 	ConfigEventTransportsDefault = ffc("config.event.transports.default", "The default event transport for new subscriptions", i18n.StringType)
 	ConfigEventTransportsEnabled = ffc("config.event.transports.enabled", "Which event interface plugins are enabled", i18n.BooleanType)
 
-	ConfigHistogramsMaxChartRows = ffc("config.histograms.maxChartRows", "The maximum rows to fetch for each histogram bucket", i18n.IntType)
-
 	ConfigHTTPAddress      = ffc("config.http.address", "The IP address on which the HTTP API should listen", "IP Address "+i18n.StringType)
 	ConfigHTTPPort         = ffc("config.http.port", "The port on which the HTTP API should listen", i18n.IntType)
 	ConfigHTTPPublicURL    = ffc("config.http.publicURL", "The fully qualified public URL for the API. This is used for building URLs in HTTP responses and in OpenAPI Spec generation", "URL "+i18n.StringType)
 	ConfigHTTPReadTimeout  = ffc("config.http.readTimeout", "The maximum time to wait when reading from an HTTP connection", i18n.TimeDurationType)
 	ConfigHTTPWriteTimeout = ffc("config.http.writeTimeout", "The maximum time to wait when writing to an HTTP connection", i18n.TimeDurationType)
 
+	ConfigHTTPAuthType                   = ffc("config.http.auth.type", "The auth plugin to use to authenticate and authorize requests to the HTTP API. Supported values are `basic` and `jwt`. Authentication is disabled if not set", i18n.StringType)
+	ConfigHTTPAuthBasicUsersUsername     = ffc("config.http.auth.basic.users[].username", "The username of a user that can access the API", i18n.StringType)
+	ConfigHTTPAuthBasicUsersPasswordHash = ffc("config.http.auth.basic.users[].passwordhash", "The bcrypt hash of the password of the user", i18n.StringType)
+	ConfigHTTPAuthBasicUsersNamespaces   = ffc("config.http.auth.basic.users[].namespaces", "The list of namespaces the user can access. The user can access all namespaces if not set", "List "+i18n.StringType)
+	ConfigHTTPAuthBasicUsersReadOnly     = ffc("config.http.auth.basic.users[].readonly", "Restricts the user to read-only (GET) routes", i18n.BooleanType)
+	ConfigHTTPAuthJWTIssuer              = ffc("config.http.auth.jwt.issuer", "The required value of the `iss` claim. Not checked if not set", i18n.StringType)
+	ConfigHTTPAuthJWTAudience            = ffc("config.http.auth.jwt.audience", "The value that must be present in the `aud` claim. Not checked if not set", i18n.StringType)
+	ConfigHTTPAuthJWTHMACSecret          = ffc("config.http.auth.jwt.hmacSecret", "A shared secret for verifying tokens signed with HS256, HS384 or HS512", i18n.StringType)
+	ConfigHTTPAuthJWTScopeClaim          = ffc("config.http.auth.jwt.scopeClaim", "The claim containing the scopes granted to the caller, as a space separated string or an array", i18n.StringType)
+	ConfigHTTPAuthJWTScopePrefix         = ffc("config.http.auth.jwt.scopePrefix", "The prefix of scopes that grant access to FireFly. Scopes take the form `prefix:namespace:read` or `prefix:namespace:write`, where namespace can be `*` for all namespaces", i18n.StringType)
+	ConfigHTTPAuthJWTClockSkew           = ffc("config.http.auth.jwt.clockSkew", "The tolerance allowed when checking the `exp` and `nbf` claims", i18n.TimeDurationType)
+	ConfigHTTPAuthJWTJWKSURL             = ffc("config.http.auth.jwt.jwks.url", "The URL of the JSON Web Key Set used to verify RSA and EC signed tokens", "URL "+i18n.StringType)
+	ConfigHTTPAuthJWTJWKSProxyURL        = ffc("config.http.auth.jwt.jwks.proxy.url", "Optional HTTP proxy server to use when fetching the JSON Web Key Set", "URL "+i18n.StringType)
+	ConfigHTTPAuthJWTJWKSRefreshInterval = ffc("config.http.auth.jwt.jwks.refreshInterval", "The minimum interval between reloads of the JSON Web Key Set, when a token is signed by an unknown key. Applies to retries after a failed reload as well", i18n.TimeDurationType)
+
 	ConfigPluginIdentity     = ffc("config.plugins.identity", "The list of available Identity plugins", i18n.StringType)
 	ConfigPluginIdentityType = ffc("config.plugins.identity[].type", "The type of a configured Identity plugin", i18n.StringType)
 	ConfigPluginIdentityName = ffc("config.plugins.identity[].name", "The name of a configured Identity plugin", i18n.StringType)
@@ -259,17 +344,27 @@ var (
 	ConfigMetricsReadTimeout  = ffc("config.metrics.readTimeout", "The maximum time to wait when reading from an HTTP connection", i18n.TimeDurationType)
 	ConfigMetricsWriteTimeout = ffc("config.metrics.writeTimeout", "The maximum time to wait when writing to an HTTP connection", i18n.TimeDurationType)
 
-	ConfigNamespacesDefault               = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
-	ConfigNamespacesPredefined            = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
-	ConfigNamespacesPredefinedName        = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
-	ConfigNamespacesPredefinedDescription = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
-	ConfigNamespacesPredefinedPlugins     = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
-	ConfigNamespacesPredefinedRemoteName  = ffc("config.namespaces.predefined[].remoteName", "The namespace name to be sent in plugin calls, if it differs from namespace name", i18n.StringType)
-	ConfigNamespacesPredefinedDefaultKey  = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyEnabled     = ffc("config.namespaces.predefined[].multiparty.enabled", "Enables multi-party mode for this namespace (defaults to true if an org name or key is configured, either here or at the root level)", i18n.BooleanType)
-	ConfigNamespacesMultipartyOrgName     = ffc("config.namespaces.predefined[].multiparty.org.name", "A short name for the local root organization within this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyOrgDesc     = ffc("config.namespaces.predefined[].multiparty.org.description", "A description for the local root organization within this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyOrgKey      = ffc("config.namespaces.predefined[].multiparty.org.key", "The signing key allocated to the root organization within this namespace", i18n.StringType)
+	ConfigNamespacesDefault                          = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
+	ConfigNamespacesPredefined                       = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
+	ConfigNamespacesPredefinedName                   = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
+	ConfigNamespacesPredefinedDescription            = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
+	ConfigNamespacesPredefinedPlugins                = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
+	ConfigNamespacesPredefinedRemoteName             = ffc("config.namespaces.predefined[].remoteName", "The namespace name to be sent in plugin calls, if it differs from namespace name", i18n.StringType)
+	ConfigNamespacesPredefinedDefaultKey             = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyEnabled                = ffc("config.namespaces.predefined[].multiparty.enabled", "Enables multi-party mode for this namespace (defaults to true if an org name or key is configured, either here or at the root level)", i18n.BooleanType)
+	ConfigNamespacesMultipartyOrgName                = ffc("config.namespaces.predefined[].multiparty.org.name", "A short name for the local root organization within this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyOrgDesc                = ffc("config.namespaces.predefined[].multiparty.org.description", "A description for the local root organization within this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyOrgKey                 = ffc("config.namespaces.predefined[].multiparty.org.key", "The signing key allocated to the root organization within this namespace", i18n.StringType)
+	ConfigNamespacesRetentionInterval                = ffc("config.namespaces.predefined[].retention.interval", "How often the retention policies for this namespace are applied", i18n.TimeDurationType)
+	ConfigNamespacesRetentionBatchSize               = ffc("config.namespaces.predefined[].retention.batchsize", "The maximum number of rows deleted in a single database transaction when pruning", i18n.IntType)
+	ConfigNamespacesRetentionEventsMaxAge            = ffc("config.namespaces.predefined[].retention.events.maxage", "Events older than this are pruned, once they have been acknowledged by all subscriptions", i18n.TimeDurationType)
+	ConfigNamespacesRetentionEventsMaxRows           = ffc("config.namespaces.predefined[].retention.events.maxrows", "The maximum number of events to retain in this namespace", i18n.IntType)
+	ConfigNamespacesRetentionMessagesMaxAge          = ffc("config.namespaces.predefined[].retention.messages.maxage", "Messages older than this are pruned, along with any data that is not attached to another message", i18n.TimeDurationType)
+	ConfigNamespacesRetentionMessagesMaxRows         = ffc("config.namespaces.predefined[].retention.messages.maxrows", "The maximum number of messages to retain in this namespace", i18n.IntType)
+	ConfigNamespacesRetentionOperationsMaxAge        = ffc("config.namespaces.predefined[].retention.operations.maxage", "Operations older than this are pruned, once they have succeeded or failed", i18n.TimeDurationType)
+	ConfigNamespacesRetentionOperationsMaxRows       = ffc("config.namespaces.predefined[].retention.operations.maxrows", "The maximum number of operations to retain in this namespace", i18n.IntType)
+	ConfigNamespacesRetentionBlockchainEventsMaxAge  = ffc("config.namespaces.predefined[].retention.blockchainevents.maxage", "Blockchain events older than this are pruned", i18n.TimeDurationType)
+	ConfigNamespacesRetentionBlockchainEventsMaxRows = ffc("config.namespaces.predefined[].retention.blockchainevents.maxrows", "The maximum number of blockchain events to retain in this namespace", i18n.IntType)
 
 	ConfigNodeDescription = ffc("config.node.description", "The description of this FireFly node", i18n.StringType)
 	ConfigNodeName        = ffc("config.node.name", "The name of this FireFly node", i18n.StringType)
@@ -304,8 +399,9 @@ var (
 	ConfigPluginSharedstorageIpfsGatewayURL      = ffc("config.plugins.sharedstorage[].ipfs.gateway.url", "The URL for the IPFS Gateway", "URL "+i18n.StringType)
 	ConfigPluginSharedstorageIpfsGatewayProxyURL = ffc("config.plugins.sharedstorage[].ipfs.gateway.proxy.url", "Optional HTTP proxy server to use when connecting to the IPFS Gateway", "URL "+i18n.StringType)
 
-	ConfigSubscriptionMax               = ffc("config.subscription.max", "The maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)", i18n.IntType)
-	ConfigSubscriptionDefaultsBatchSize = ffc("config.subscription.defaults.batchSize", "Default read ahead to enable for subscriptions that do not explicitly configure readahead", i18n.IntType)
+	ConfigSubscriptionMax                  = ffc("config.subscription.max", "The maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)", i18n.IntType)
+	ConfigSubscriptionDefaultsBatchSize    = ffc("config.subscription.defaults.batchSize", "Default read ahead to enable for subscriptions that do not explicitly configure readahead", i18n.IntType)
+	ConfigSubscriptionDefaultsBatchTimeout = ffc("config.subscription.defaults.batchTimeout", "Default time to wait for a batch to fill, for subscriptions with batch delivery enabled that do not explicitly configure batchTimeout", i18n.TimeDurationType)
 
 	ConfigTokensConnector = ffc("config.tokens[].connector", "The name of the Tokens Connector. This will be used in the FireFly API path to refer to this specific Token Connector", i18n.StringType)
 	ConfigTokensName      = ffc("config.tokens[].name", "The name of the Tokens Connector. This will be used in the FireFly API path to refer to this specific Token Connector", i18n.StringType)
@@ -251,4 +251,84 @@ var (
 	MsgDefRejectedWrongAuthor             = ffe("FF10409", "Rejected %s '%s' - wrong author: %s")
 	MsgDefRejectedHashMismatch            = ffe("FF10410", "Rejected %s '%s' - hash mismatch: %s != %s")
 	MsgInvalidNamespaceUUID               = ffe("FF10411", "Expected 'namespace:' prefix on ID '%s'", 400)
+	MsgUnknownAuthPlugin                  = ffe("FF10412", "Unknown auth plugin: %s")
+	MsgAuthCredentialsMissing             = ffe("FF10413", "Authentication credentials missing from request", 401)
+	MsgAuthInvalidCredentials             = ffe("FF10414", "Authentication failed", 401)
+	MsgAuthForbidden                      = ffe("FF10415", "'%s' is not authorized to perform this request on namespace '%s'", 403)
+	MsgBasicAuthUserConfigInvalid         = ffe("FF10416", "Invalid basic auth configuration for user %d - username and passwordhash are required")
+	MsgBasicAuthPasswordHashInvalid       = ffe("FF10417", "Invalid bcrypt password hash configured for user '%s'")
+	MsgJWTNoVerificationKey               = ffe("FF10418", "JWT auth requires either jwks.url or hmacSecret to be configured")
+	MsgJWTMalformed                       = ffe("FF10419", "Malformed JWT")
+	MsgJWTUnsupportedAlgorithm            = ffe("FF10420", "Unsupported JWT algorithm '%s'")
+	MsgJWTSignatureInvalid                = ffe("FF10421", "Invalid JWT signature")
+	MsgJWTClaimInvalid                    = ffe("FF10422", "Invalid or missing JWT claim '%s'")
+	MsgJWTUnknownKey                      = ffe("FF10423", "No key found in JWKS for key ID '%s'")
+	MsgJWTUnsupportedKey                  = ffe("FF10424", "Unsupported JWK key type '%s' curve '%s'")
+	MsgJWKSFetchFailed                    = ffe("FF10425", "Failed to fetch JWKS: %s")
+	MsgMQPublishFailed                    = ffe("FF10426", "Failed to publish to message queue: %s")
+	MsgMQPublishNotConfirmed              = ffe("FF10427", "Message queue topic '%s' confirmed %d of %d published messages")
+	MsgMQPublishRejected                  = ffe("FF10428", "Message queue rejected message %d published to topic '%s': %s")
+	MsgMQInvalidTopic                     = ffe("FF10429", "Invalid message queue topic '%v' - must be 1-249 characters of a-z, A-Z, 0-9, '.', '_' or '-'", 400)
+	MsgWebhookInvalidRetryOption          = ffe("FF10430", "Webhook subscription option '%s' is invalid: %v", 400)
+	MsgWebhookFailedStatus                = ffe("FF10431", "Webhook request failed with HTTP status %d")
+	MsgWebhookBatchWithReply              = ffe("FF10432", "Webhook subscriptions cannot use batch delivery when reply is enabled", 400)
+	MsgBatchDeliveryNotSupported          = ffe("FF10433", "Batch delivery not supported by transport '%s'", 400)
+	MsgInvalidSubscriptionBatchTimeout    = ffe("FF10434", "Invalid subscription batchTimeout '%s': %s", 400)
+	MsgInvalidSubscriptionAmount          = ffe("FF10435", "Invalid amount '%s' for subscription filter '%s'", 400)
+	MsgInvalidJSONPath                    = ffe("FF10436", "Invalid JSONPath expression '%s' at position %d", 400)
+	MsgInvalidSubscriptionReset           = ffe("FF10437", "Exactly one of 'firstEvent' or 'timestamp' must be set to reset a subscription", 400)
+	MsgDurableSubscriptionNotFound        = ffe("FF10438", "Subscription '%s' not found in namespace '%s'", 404)
+	MsgSSENotEnabled                      = ffe("FF10439", "The sse event transport is not enabled", 404)
+	MsgSSEInvalidStart                    = ffe("FF10440", "Query parameters must set namespace and either a name or ephemeral=true", 400)
+	MsgSSEInvalidQueryParam               = ffe("FF10441", "Invalid value '%s' for query parameter '%s'", 400)
+	MsgSSEInvalidLastEventID              = ffe("FF10442", "Invalid Last-Event-ID '%s' - must be an event sequence", 400)
+	MsgSSEStreamingNotSupported           = ffe("FF10443", "The HTTP connection does not support streaming", 500)
+	MsgSSEConnectionNotActive             = ffe("FF10444", "SSE connection '%s' no longer active")
+	MsgInvalidSubscriptionPartitionBy     = ffe("FF10445", "Invalid subscription partitionBy '%s' - must be one of 'topic', 'author' or 'group'", 400)
+	MsgInvalidFilterCursor                = ffe("FF10446", "Invalid cursor '%s'", 400)
+	MsgFilterCursorRequiresSequenceSort   = ffe("FF10447", "Cursors can only be used when sorting by sequence", 400)
+	MsgInvalidChartGroupBy                = ffe("FF10448", "Invalid groupBy field '%s' for collection '%s'", 400)
+	MsgInvalidJSONFilterPath              = ffe("FF10449", "Invalid JSON path '%s' - must be dot separated names containing only letters, numbers, '_' and '-'", 400)
+	MsgDataValueIndexFailed               = ffe("FF10450", "Failed to create data value index for datatype '%s' path '%s'")
+	MsgInvalidAggregateMetric             = ffe("FF10451", "Invalid metric '%s' - must be 'sum', 'min' or 'max' followed by ':' and a field, such as 'sum:amount'", 400)
+	MsgAggregateFieldNotNumeric           = ffe("FF10452", "Field '%s' of collection '%s' is not numeric, so cannot be used in a metric", 400)
+	MsgImportNamespaceNotEmpty            = ffe("FF10453", "Cannot import into namespace '%s' as it already contains %s", 409)
+	MsgImportNamespaceMismatch            = ffe("FF10454", "Record %d of the import is for namespace '%s', which does not match namespace '%s'", 400)
+	MsgImportMissingHeader                = ffe("FF10455", "Import must begin with a namespace record", 400)
+	MsgImportInvalidRecord                = ffe("FF10456", "Record %d of the import is invalid: %s", 400)
+	MsgImportUnknownCollection            = ffe("FF10457", "Record %d of the import has unknown collection '%s'", 400)
+	MsgSPIRequestFailed                   = ffe("FF10458", "Request to '%s' failed with status %d: %s")
+	MsgSearchTextMissing                  = ffe("FF10459", "Search text must be provided in the 'q' parameter", 400)
+	MsgInvalidSearchType                  = ffe("FF10460", "Invalid search type '%s' - must be one of: %s", 400)
+	MsgInvalidSearchLimit                 = ffe("FF10461", "Invalid search limit '%s' - must be a number between 1 and %d", 400)
+	MsgSearchNotSupported                 = ffe("FF10462", "Full-text search is not supported by the '%s' database plugin")
+	MsgDBSchemaVersionMismatch            = ffe("FF10463", "The %s database schema is at version %d, but this build of FireFly requires version %d. Use 'firefly migrate' to migrate the schema")
+	MsgDBSchemaDirty                      = ffe("FF10464", "The %s database schema is dirty at version %d, as a migration failed part way through. The schema must be repaired manually")
+	MsgMigrationsNotSupported             = ffe("FF10465", "Migrations are not supported by the '%s' database plugin")
+	MsgDatabasePluginNotSelected          = ffe("FF10466", "Multiple database plugins are configured - select one of: %s")
+	MsgDatabasePluginNotFound             = ffe("FF10467", "Database plugin '%s' not found - configured plugins: %s")
+	MsgInvalidMigrationArg                = ffe("FF10468", "Invalid %s '%s' - must be a positive number")
+	MsgContractDeployInvalidChaincode     = ffe("FF10469", "Invalid chaincode package to deploy: %v", 400)
+	MsgContractDeployMissingContract      = ffe("FF10470", "The 'contract' field must be provided to deploy a smart contract", 400)
+	MsgEthRPCRESTErr                      = ffe("FF10471", "Error from Ethereum JSON-RPC endpoint: %s")
+	MsgEthRPCCallFailed                   = ffe("FF10472", "JSON-RPC '%s' request failed: %s")
+	MsgEthRPCInvalidResult                = ffe("FF10473", "Invalid result from JSON-RPC '%s' request: %s")
+	MsgKeystoreReadFailed                 = ffe("FF10474", "Failed to read keystore file '%s': %s")
+	MsgKeystoreKeyNotFound                = ffe("FF10475", "No key found in the local keystore for signing address '%s'", 400)
+	MsgEthRPCEncodingFailed               = ffe("FF10476", "Failed to encode input for '%s': %s", 400)
+	MsgEthRPCDecodingFailed               = ffe("FF10477", "Failed to decode output of '%s': %s")
+	MsgEthRPCSigningFailed                = ffe("FF10478", "Failed to sign transaction from '%s': %s")
+	MsgEthRPCStateFileFailed              = ffe("FF10479", "Failed to read or write event state file '%s': %s")
+	MsgEthRPCInvalidFromBlock             = ffe("FF10480", "Invalid block number '%s' - must be 'oldest', 'newest' or a block number", 400)
+	MsgEthRPCTransactionReverted          = ffe("FF10481", "Transaction %s reverted")
+	MsgInvalidConfirmations               = ffe("FF10482", "Invalid confirmations '%d' - must be zero or greater", 400)
+	MsgListenerDuplicateEvent             = ffe("FF10483", "Event '%s' is included more than once in the listener", 400)
+	MsgListenerFilterParamNotFound        = ffe("FF10484", "Filter parameter '%s' is not a parameter of event '%s'", 400)
+	MsgListenerFilterParamNotIndexed      = ffe("FF10485", "Filter parameter '%s' is not an indexed parameter of event '%s'", 400)
+	MsgListenerFilterUnsupportedType      = ffe("FF10486", "Filtering on parameter '%s' of type '%s' is not supported", 400)
+	MsgListenerFilterInvalidValue         = ffe("FF10487", "Invalid value for filter parameter '%s': %s", 400)
+	MsgListenerFiltersNotSupported        = ffe("FF10488", "Filtering events on parameters is not supported by the '%s' blockchain plugin", 400)
+	MsgContractReverted                   = ffe("FF10489", "Smart contract execution reverted: %s")
+	MsgErrorNameMustBeSet                 = ffe("FF10490", "Error name must be set", 400)
+	MsgWebhookSecretRedacted              = ffe("FF10491", "Webhook subscription option 'secret' must be set to the secret itself, as the redacted value only keeps the secret of an existing subscription", 400)
 )
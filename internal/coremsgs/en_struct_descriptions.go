@@ -200,11 +200,37 @@ var (
 	BlockchainEventTimestamp  = ffm("BlockchainEvent.timestamp", "The time allocated to this event by the blockchain. This is the block timestamp for most blockchain connectors")
 	BlockchainEventTX         = ffm("BlockchainEvent.tx", "If this blockchain event is coorelated to FireFly transaction such as a FireFly submitted token transfer, this field is set to the UUID of the FireFly transaction")
 
+	// AggregateMetric field descriptions
+	AggregateMetricFunction = ffm("AggregateMetric.function", "The function calculated over the values of the field, for the records in the group")
+	AggregateMetricField    = ffm("AggregateMetric.field", "The numeric field the function is calculated over")
+
+	// AggregateQuery field descriptions
+	AggregateQueryGroupBy = ffm("AggregateQuery.groupBy", "The fields to group the records by")
+	AggregateQueryMetrics = ffm("AggregateQuery.metrics", "The metrics to calculate for each group, in addition to the count of records")
+
+	// AggregateResult field descriptions
+	AggregateResultGroup   = ffm("AggregateResult.group", "The values of the groupBy fields shared by the records in this group")
+	AggregateResultCount   = ffm("AggregateResult.count", "The number of records in the group")
+	AggregateResultMetrics = ffm("AggregateResult.metrics", "The value of each requested metric for the group")
+
+	// SearchHit field descriptions
+	SearchHitType      = ffm("SearchHit.type", "The type of the record matched by the search")
+	SearchHitID        = ffm("SearchHit.id", "The UUID of the record matched by the search")
+	SearchHitRank      = ffm("SearchHit.rank", "The relevance of the match, where a higher number is a better match")
+	SearchHitMessage   = ffm("SearchHit.message", "The matched message, when the type is 'message'")
+	SearchHitData      = ffm("SearchHit.data", "The matched data, when the type is 'data'")
+	SearchHitIdentity  = ffm("SearchHit.identity", "The matched identity, when the type is 'identity'")
+	SearchHitTokenPool = ffm("SearchHit.tokenPool", "The matched token pool, when the type is 'tokenpool'")
+	SearchHitFFI       = ffm("SearchHit.ffi", "The matched FireFly interface, when the type is 'ffi'")
+
+	// AggregateMetricResult field descriptions
+	AggregateMetricResultValue = ffm("AggregateMetricResult.value", "The value of the metric, or null if no record in the group has a value for the field")
+
 	// ChartHistogram field descriptions
 	ChartHistogramCount     = ffm("ChartHistogram.count", "Total count of entries in this time bucket within the histogram")
 	ChartHistogramTimestamp = ffm("ChartHistogram.timestamp", "Starting timestamp for the bucket")
 	ChartHistogramTypes     = ffm("ChartHistogram.types", "Array of separate counts for individual types of record within the bucket")
-	ChartHistogramIsCapped  = ffm("ChartHistogram.isCapped", "Indicates whether there are more results in this bucket that are not being displayed")
+	ChartHistogramIsCapped  = ffm("ChartHistogram.isCapped", "Deprecated - counts are calculated in the database and are never capped, so this is always false")
 
 	// ChartHistogramType field descriptions
 	ChartHistogramTypeCount = ffm("ChartHistogramType.count", "Count of entries of a given type within a bucket")
@@ -219,6 +245,13 @@ var (
 	ContractAPIMessage   = ffm("ContractAPI.message", "The UUID of the broadcast message that was used to publish this API to the network")
 	ContractAPIURLs      = ffm("ContractAPI.urls", "The URLs to use to access the API")
 
+	// ContractDeployRequest field descriptions
+	ContractDeployRequestKey        = ffm("ContractDeployRequest.key", "The blockchain signing key that will be used to deploy the contract. Defaults to the first signing key of the organization that operates the node")
+	ContractDeployRequestDefinition = ffm("ContractDeployRequest.definition", "The definition of the smart contract, in a blockchain specific format. For example the ABI of an Ethereum contract, or the chaincode definition for Fabric")
+	ContractDeployRequestContract   = ffm("ContractDeployRequest.contract", "The smart contract to deploy, in a blockchain specific format. For example the compiled bytecode of an Ethereum contract, or a reference to a chaincode package for Fabric")
+	ContractDeployRequestInput      = ffm("ContractDeployRequest.input", "An ordered list of inputs to pass to the constructor of the smart contract")
+	ContractDeployRequestOptions    = ffm("ContractDeployRequest.options", "A map of named inputs that will be passed through to the blockchain connector")
+
 	// ContractURLs field descriptions
 	ContractURLsOpenAPI = ffm("ContractURLs.openapi", "The URL to download the OpenAPI v3 (Swagger) description for the API generated in JSON or YAML format")
 	ContractURLsUI      = ffm("ContractURLs.ui", "The URL to use in a web browser to access the SwaggerUI explorer/exerciser for the API")
@@ -237,6 +270,7 @@ var (
 	FFIVersion     = ffm("FFI.version", "A version for the FFI - use of semantic versioning such as 'v1.0.1' is encouraged")
 	FFIMethods     = ffm("FFI.methods", "An array of smart contract method definitions")
 	FFIEvents      = ffm("FFI.events", "An array of smart contract event definitions")
+	FFIErrors      = ffm("FFI.errors", "An array of smart contract error definitions")
 
 	// FFIMethod field descriptions
 	FFIMethodID          = ffm("FFIMethod.id", "The UUID of the FFI method definition")
@@ -260,6 +294,20 @@ var (
 	FFIEventSignature   = ffm("FFIEvent.signature", "The stringified signature of the event, as computed by the blockchain plugin")
 	FFIEventDetails     = ffm("FFIEvent.details", "Additional blockchain specific fields about this event from the original smart contract. Used by the blockchain plugin and for documentation generation.")
 
+	// FFIError field descriptions
+	FFIErrorID          = ffm("FFIError.id", "The UUID of the FFI error definition")
+	FFIErrorInterface   = ffm("FFIError.interface", "The UUID of the FFI smart contract definition that this error is part of")
+	FFIErrorName        = ffm("FFIError.name", "The name of the error")
+	FFIErrorNamespace   = ffm("FFIError.namespace", "The namespace of the FFI")
+	FFIErrorPathname    = ffm("FFIError.pathname", "The unique name allocated to this error within the FFI for use on URL paths")
+	FFIErrorDescription = ffm("FFIError.description", "A description of the smart contract error")
+	FFIErrorParams      = ffm("FFIError.params", "An array of error parameter/argument definitions")
+	FFIErrorSignature   = ffm("FFIError.signature", "The stringified signature of the error, as computed by the blockchain plugin")
+
+	// ContractRevert field descriptions
+	ContractRevertError  = ffm("ContractRevert.error", "The name of the error the smart contract reverted with. Solidity reverts with a reason string are reported as 'Error', and failed assertions as 'Panic'")
+	ContractRevertParams = ffm("ContractRevert.params", "The parameters of the error, decoded against the error definition")
+
 	// FFIParam field descriptions
 	FFIParamName   = ffm("FFIParam.name", "The name of the parameter. Note that parameters must be ordered correctly on the FFI, according to the order in the blockchain smart contract")
 	FFIParamSchema = ffm("FFIParam.schema", "FireFly uses an extended subset of JSON Schema to describe parameters, similar to OpenAPI/Swagger. Converters are available for native blockchain interface definitions / type systems - such as an Ethereum ABI. See the documentation for more detail")
@@ -272,22 +320,26 @@ var (
 	FFIGenerationRequestInput       = ffm("FFIGenerationRequest.input", "A blockchain connector specific payload. For example in Ethereum this is a JSON structure containing an 'abi' array, and optionally a 'devdocs' array.")
 
 	// ContractListener field descriptions
-	ContractListenerID        = ffm("ContractListener.id", "The UUID of the smart contract listener")
-	ContractListenerInterface = ffm("ContractListener.interface", "A reference to an existing FFI, containing pre-registered type information for the event")
-	ContractListenerNamespace = ffm("ContractListener.namespace", "The namespace of the listener, which defines the namespace of all blockchain events detected by this listener")
-	ContractListenerName      = ffm("ContractListener.name", "A descriptive name for the listener")
-	ContractListenerBackendID = ffm("ContractListener.backendId", "An ID assigned by the blockchain connector to this listener")
-	ContractListenerLocation  = ffm("ContractListener.location", "A blockchain specific contract identifier. For example an Ethereum contract address, or a Fabric chaincode name and channel")
-	ContractListenerCreated   = ffm("ContractListener.created", "The creation time of the listener")
-	ContractListenerEvent     = ffm("ContractListener.event", "The definition of the event, either provided in-line when creating the listener, or extracted from the referenced FFI")
-	ContractListenerTopic     = ffm("ContractListener.topic", "A topic to set on the FireFly event that is emitted each time a blockchain event is detected from the blockchain. Setting this topic on a number of listeners allows applications to easily subscribe to all events they need")
-	ContractListenerOptions   = ffm("ContractListener.options", "Options that control how the listener subscribes to events from the underlying blockchain")
-	ContractListenerEventPath = ffm("ContractListener.eventPath", "When creating a listener from an existing FFI, this is the pathname of the event on that FFI to be detected by this listener")
-	ContractListenerSignature = ffm("ContractListener.signature", "The stringified signature of the event, as computed by the blockchain plugin")
-	ContractListenerState     = ffm("ContractListener.state", "This field is provided for the event listener implementation of the blockchain provider to record state, such as checkpoint information")
+	ContractListenerID         = ffm("ContractListener.id", "The UUID of the smart contract listener")
+	ContractListenerInterface  = ffm("ContractListener.interface", "A reference to an existing FFI, containing pre-registered type information for the event")
+	ContractListenerNamespace  = ffm("ContractListener.namespace", "The namespace of the listener, which defines the namespace of all blockchain events detected by this listener")
+	ContractListenerName       = ffm("ContractListener.name", "A descriptive name for the listener")
+	ContractListenerBackendID  = ffm("ContractListener.backendId", "An ID assigned by the blockchain connector to this listener")
+	ContractListenerLocation   = ffm("ContractListener.location", "A blockchain specific contract identifier. For example an Ethereum contract address, or a Fabric chaincode name and channel")
+	ContractListenerCreated    = ffm("ContractListener.created", "The creation time of the listener")
+	ContractListenerEvent      = ffm("ContractListener.event", "The definition of the event, either provided in-line when creating the listener, or extracted from the referenced FFI. For a listener on multiple events, this is the first of those events")
+	ContractListenerEvents     = ffm("ContractListener.events", "The definitions of all the events detected by a listener on multiple events of the same FFI and location")
+	ContractListenerTopic      = ffm("ContractListener.topic", "A topic to set on the FireFly event that is emitted each time a blockchain event is detected from the blockchain. Setting this topic on a number of listeners allows applications to easily subscribe to all events they need")
+	ContractListenerOptions    = ffm("ContractListener.options", "Options that control how the listener subscribes to events from the underlying blockchain")
+	ContractListenerEventPath  = ffm("ContractListener.eventPath", "When creating a listener from an existing FFI, this is the pathname of the event on that FFI to be detected by this listener")
+	ContractListenerEventPaths = ffm("ContractListener.eventPaths", "When creating a listener from an existing FFI, the pathnames of multiple events on that FFI to be detected by this listener")
+	ContractListenerSignature  = ffm("ContractListener.signature", "The stringified signature of the event, as computed by the blockchain plugin. For a listener on multiple events, the signatures of all events separated by ';'")
+	ContractListenerState      = ffm("ContractListener.state", "This field is provided for the event listener implementation of the blockchain provider to record state, such as checkpoint information")
 
 	// ContractListenerOptions field descriptions
-	ContractListenerOptionsFirstEvent = ffm("ContractListenerOptions.firstEvent", "A blockchain specific string, such as a block number, to start listening from. The special strings 'oldest' and 'newest' are supported by all blockchain connectors. Default is 'newest'")
+	ContractListenerOptionsFirstEvent    = ffm("ContractListenerOptions.firstEvent", "A blockchain specific string, such as a block number, to start listening from. The special strings 'oldest' and 'newest' are supported by all blockchain connectors. Default is 'newest'")
+	ContractListenerOptionsConfirmations = ffm("ContractListenerOptions.confirmations", "The number of blocks that must be mined on top of an event before it is delivered. When unset the confirmations configured on the blockchain plugin are used")
+	ContractListenerOptionsFilters       = ffm("ContractListenerOptions.filters", "A map of indexed event parameter names to a value, or an array of values, that the parameter must match for an event to be delivered. Each parameter must be indexed on every event of the listener")
 
 	// DIDDocument field descriptions
 	DIDDocumentContext            = ffm("DIDDocument.@context", "See https://www.w3.org/TR/did-core/#json-ld")
@@ -387,6 +439,8 @@ var (
 	FireFlyContractFinalEvent  = ffm("FireFlyContractInfo.finalEvent", "The identifier for the final blockchain event received from this contract before termination")
 	FireFlyContractInfo        = ffm("FireFlyContractInfo.info", "Blockchain-specific info on the contract, such as its location on chain")
 	NetworkActionType          = ffm("NetworkAction.type", "The action to be performed")
+	NamespaceImportNamespace   = ffm("NamespaceImportResult.namespace", "The namespace the records were imported into")
+	NamespaceImportRecords     = ffm("NamespaceImportResult.records", "The number of records imported, keyed by collection")
 
 	// NodeStatus field descriptions
 	NodeNamespace  = ffm("NodeStatus.namespace", "The namespace that this status applies to")
@@ -466,12 +520,25 @@ var (
 	SubscriptionCreated   = ffm("Subscription.created", "Creation time of the subscription")
 	SubscriptionUpdated   = ffm("Subscription.updated", "Last time the subscription was updated")
 
+	// SubscriptionStatus field descriptions
+	SubscriptionStatusCurrentOffset = ffm("SubscriptionStatus.currentOffset", "The sequence of the last event in the namespace that the subscription has processed. Delivery resumes from the next event after this sequence. While the subscription is being delivered on the node serving the request, this is the offset of the active dispatcher, which can be ahead of the offset last committed to the database")
+	SubscriptionStatusLag           = ffm("SubscriptionStatus.lag", "The number of events in the namespace that have a sequence greater than the current offset of the subscription. Includes events that do not match the subscription filter")
+
+	// SubscriptionWithStatus field descriptions
+	SubscriptionWithStatusStatus = ffm("SubscriptionWithStatus.status", "The position of the subscription in the event stream of the namespace")
+
+	// SubscriptionReset field descriptions
+	SubscriptionResetFirstEvent = ffm("SubscriptionReset.firstEvent", "Moves the subscription to the 'oldest' event, the 'newest' event, or a specific event sequence. Delivery restarts from the next event after that position")
+	SubscriptionResetTimestamp  = ffm("SubscriptionReset.timestamp", "Moves the subscription to the position immediately before the first event created at or after this time")
+
 	// SubscriptionFilter field descriptions
 	SubscriptionFilterEvents           = ffm("SubscriptionFilter.events", "Regular expression to apply to the event type, to subscribe to a subset of event types")
 	SubscriptionFilterTopic            = ffm("SubscriptionFilter.topic", "Regular expression to apply to the topic of the event, to subscribe to a subset of topics. Note for messages sent with multiple topics, a separate event is emitted for each topic")
 	SubscriptionFilterMessage          = ffm("SubscriptionFilter.message", "Filters specific to message events. If an event is not a message event, these filters are ignored")
 	SubscriptionFilterTransaction      = ffm("SubscriptionFilter.transaction", "Filters specific to events with a transaction. If an event is not associated with a transaction, this filter is ignored")
 	SubscriptionFilterBlockchainEvent  = ffm("SubscriptionFilter.blockchainevent", "Filters specific to blockchain events. If an event is not a blockchain event, these filters are ignored")
+	SubscriptionFilterToken            = ffm("SubscriptionFilter.token", "Filters on the token pool, transfer or approval associated with an event")
+	SubscriptionFilterData             = ffm("SubscriptionFilter.data", "Filter on the value of the first data item of a message, using a JSONPath expression. If an event is not a message event, it does not match this filter")
 	SubscriptionFilterDeprecatedTopics = ffm("SubscriptionFilter.topics", "Deprecated: Please use 'topic' instead")
 	SubscriptionFilterDeprecatedTag    = ffm("SubscriptionFilter.tag", "Deprecated: Please use 'message.tag' instead")
 	SubscriptionFilterDeprecatedGroup  = ffm("SubscriptionFilter.group", "Deprecated: Please use 'message.group' instead")
@@ -489,10 +556,24 @@ var (
 	SubscriptionBlockchainEventFilterName     = ffm("SubscriptionBlockchainEventFilter.name", "Regular expression to apply to the blockchain event 'name' field, which is the name of the event in the underlying blockchain smart contract")
 	SubscriptionBlockchainEventFilterListener = ffm("SubscriptionBlockchainEventFilter.listener", "Regular expression to apply to the blockchain event 'listener' field, which is the UUID of the event listener. So you can restrict your subscription to certain blockchain listeners. Alternatively to avoid your application need to know listener UUIDs you can set the 'topic' field of blockchain event listeners, and use a topic filter on your subscriptions")
 
+	// SubscriptionTokenFilter field descriptions
+	SubscriptionTokenFilterPool      = ffm("SubscriptionTokenFilter.pool", "Regular expression to apply to the UUID of the token pool associated with a token pool, transfer or approval event")
+	SubscriptionTokenFilterFrom      = ffm("SubscriptionTokenFilter.from", "Regular expression to apply to the 'from' field of a token transfer")
+	SubscriptionTokenFilterTo        = ffm("SubscriptionTokenFilter.to", "Regular expression to apply to the 'to' field of a token transfer")
+	SubscriptionTokenFilterMinAmount = ffm("SubscriptionTokenFilter.minAmount", "The minimum amount of a token transfer, as a base 10 integer string. Events that are not token transfers do not match")
+	SubscriptionTokenFilterMaxAmount = ffm("SubscriptionTokenFilter.maxAmount", "The maximum amount of a token transfer, as a base 10 integer string. Events that are not token transfers do not match")
+
+	// SubscriptionDataFilter field descriptions
+	SubscriptionDataFilterJSONPath = ffm("SubscriptionDataFilter.jsonpath", "A JSONPath expression to select a field from the value of the first data item in the message, such as $.customer.region. Only child names and array indexes are supported")
+	SubscriptionDataFilterValue    = ffm("SubscriptionDataFilter.value", "Regular expression to apply to the selected field. Values that are not strings are matched against their JSON representation. If not set, the field must exist and be non-null")
+
 	// SubscriptionCoreOptions field descriptions
-	SubscriptionCoreOptionsFirstEvent = ffm("SubscriptionCoreOptions.firstEvent", "Whether your appplication would like to receive events from the 'oldest' event emitted by your FireFly node (from the beginning of time), or the 'newest' event (from now), or a specific event sequence. Default is 'newest'")
-	SubscriptionCoreOptionsReadAhead  = ffm("SubscriptionCoreOptions.readAhead", "The number of events to stream ahead to your application, while waiting for confirmation of consumption of those events. At least once delivery semantics are used in FireFly, so if your application crashes/reconnects this is the maximum number of events you would expect to be redelivered after it restarts")
-	SubscriptionCoreOptionsWithData   = ffm("SubscriptionCoreOptions.withData", "Whether message events delivered over the subscription, should be packaged with the full data of those messages in-line as part of the event JSON payload. Or if the application should make separate REST calls to download that data. May not be supported on some transports.")
+	SubscriptionCoreOptionsFirstEvent   = ffm("SubscriptionCoreOptions.firstEvent", "Whether your appplication would like to receive events from the 'oldest' event emitted by your FireFly node (from the beginning of time), or the 'newest' event (from now), or a specific event sequence. Default is 'newest'")
+	SubscriptionCoreOptionsReadAhead    = ffm("SubscriptionCoreOptions.readAhead", "The number of events to stream ahead to your application, while waiting for confirmation of consumption of those events. At least once delivery semantics are used in FireFly, so if your application crashes/reconnects this is the maximum number of events you would expect to be redelivered after it restarts")
+	SubscriptionCoreOptionsWithData     = ffm("SubscriptionCoreOptions.withData", "Whether message events delivered over the subscription, should be packaged with the full data of those messages in-line as part of the event JSON payload. Or if the application should make separate REST calls to download that data. May not be supported on some transports.")
+	SubscriptionCoreOptionsBatch        = ffm("SubscriptionCoreOptions.batch", "Whether to deliver events in batches of up to readAhead events, on transports that support batch delivery")
+	SubscriptionCoreOptionsBatchTimeout = ffm("SubscriptionCoreOptions.batchTimeout", "The maximum time to wait for a batch to fill before it is delivered. Defaults to the subscription.defaults.batchTimeout config setting")
+	SubscriptionCoreOptionsPartitionBy  = ffm("SubscriptionCoreOptions.partitionBy", "Deliver events in parallel across keys, while preserving the order of events with the same key. One of 'topic', 'author' or 'group'. Up to readAhead events are in flight, but only one for each key")
 
 	// TokenApproval field descriptions
 	TokenApprovalLocalID         = ffm("TokenApproval.localId", "The UUID of this token approval, in the local FireFly node")
@@ -588,6 +669,7 @@ var (
 	ContractCallRequestKey        = ffm("ContractCallRequest.key", "The blockchain signing key that will sign the invocation. Defaults to the first signing key of the organization that operates the node")
 	ContractCallRequestMethod     = ffm("ContractCallRequest.method", "An in-line FFI method definition for the method to invoke. Required when FFI is not specified")
 	ContractCallRequestMethodPath = ffm("ContractCallRequest.methodPath", "The pathname of the method on the specified FFI")
+	ContractCallRequestErrors     = ffm("ContractCallRequest.errors", "An in-line list of FFI error definitions, used to decode the reason if the smart contract reverts. Defaults to the errors of the FFI when 'interface' is specified")
 	ContractCallRequestInput      = ffm("ContractCallRequest.input", "A map of named inputs. The name and type of each input must be compatible with the FFI description of the method, so that FireFly knows how to serialize it to the blockchain via the connector")
 	ContractCallRequestOptions    = ffm("ContractCallRequest.options", "A map of named inputs that will be passed through to the blockchain connector")
 
@@ -606,6 +688,28 @@ var (
 	WSSubscriptionStatusNamespace = ffm("WSSubscriptionStatus.namespace", "The subscription namespace")
 	WSSubscriptionStatusName      = ffm("WSSubscriptionStatus.name", "The subscription name (for durable subscriptions only)")
 
+	// WSActionBase field descriptions
+	WSActionBaseType = ffm("WSActionBase.type", "The type of the action sent by the client on the websocket")
+
+	// WSStart field descriptions
+	WSStartAutoAck   = ffm("WSStart.autoack", "When true events are acknowledged automatically as they are delivered, without the client sending an ack")
+	WSStartNamespace = ffm("WSStart.namespace", "The namespace of the subscription to start")
+	WSStartName      = ffm("WSStart.name", "The name of an existing durable subscription to start. Not used for ephemeral subscriptions")
+	WSStartEphemeral = ffm("WSStart.ephemeral", "When true an ephemeral subscription is created for the lifetime of the connection, using the filter and options supplied")
+	WSStartFilter    = ffm("WSStart.filter", "The filter to apply to an ephemeral subscription")
+	WSStartOptions   = ffm("WSStart.options", "The options to apply to an ephemeral subscription")
+
+	// WSAck field descriptions
+	WSAckID           = ffm("WSAck.id", "The ID of the event being acknowledged. Optional when only one event is in flight on the connection")
+	WSAckSubscription = ffm("WSAck.subscription", "The subscription the event was delivered on. Optional when only one subscription is started on the connection")
+
+	// WSNack field descriptions
+	WSNackReason = ffm("WSNack.reason", "An optional reason for rejecting the event. The event, and any that follow it on the subscription, are redelivered")
+
+	// WSAckUpTo field descriptions
+	WSAckUpToSequence     = ffm("WSAckUpTo.sequence", "All events in flight with a sequence less than or equal to this value are acknowledged")
+	WSAckUpToSubscription = ffm("WSAckUpTo.subscription", "Optionally restricts the acknowledgement to events delivered on this subscription. When omitted events on all subscriptions are acknowledged")
+
 	WebhooksOptJSON         = ffm("WebhookSubOptions.json", "Webhooks only: Whether to assume the response body is JSON, regardless of the returned Content-Type")
 	WebhooksOptReply        = ffm("WebhookSubOptions.reply", "Webhooks only: Whether to automatically send a reply event, using the body returned by the webhook")
 	WebhooksOptHeaders      = ffm("WebhookSubOptions.headers", "Webhooks only: Static headers to set on the webhook request")
@@ -621,4 +725,22 @@ var (
 	WebhooksOptInputBody    = ffm("WebhookInputOptions.body", "A top-level property of the first data input, to use for the request body. Default is the whole first body")
 	WebhooksOptInputPath    = ffm("WebhookInputOptions.path", "A top-level property of the first data input, to use for a path to append with escaping to the webhook path")
 	WebhooksOptInputReplyTx = ffm("WebhookInputOptions.replytx", "A top-level property of the first data input, to use to dynamically set whether to pin the response (so the requester can choose)")
+	WebhooksOptRetry        = ffm("WebhookSubOptions.retry", "Webhooks only: The policy for retrying failed webhook requests, before the event is recorded as a dead letter")
+	WebhooksOptSecret       = ffm("WebhookSubOptions.secret", "Webhooks only: A secret used to sign each request with HMAC-SHA256, over the X-Firefly-Timestamp header and the body joined by a '.'. The signature is set in the X-Firefly-Signature header as sha256=<hex>. The secret is write-only, and is returned as ********")
+	WebhooksOptRetryCount   = ffm("WebhookRetryOptions.count", "The maximum number of times to retry a failed request. Default=0 (no retries)")
+	WebhooksOptRetryInitial = ffm("WebhookRetryOptions.initialDelay", "The delay before the first retry, which doubles on each subsequent retry. Default=250ms")
+	WebhooksOptRetryMax     = ffm("WebhookRetryOptions.maxDelay", "The maximum delay between retries. Default=30s")
+	WebhooksOptRetryElapsed = ffm("WebhookRetryOptions.maxElapsed", "The maximum total time to spend delivering an event, including all retries, before it is recorded as a dead letter. Default=1m")
+	WebhooksOptRetryStatus  = ffm("WebhookRetryOptions.statusCodes", "The HTTP status codes that can be retried. Requests that fail to connect are always retried. Default=[429,500,502,503,504]")
+
+	// DeadLetter field descriptions
+	DeadLetterID           = ffm("DeadLetter.id", "The UUID of the dead letter")
+	DeadLetterNamespace    = ffm("DeadLetter.namespace", "The namespace of the dead letter")
+	DeadLetterSubscription = ffm("DeadLetter.subscription", "The subscription the event could not be delivered to")
+	DeadLetterTransport    = ffm("DeadLetter.transport", "The transport plugin that attempted the delivery")
+	DeadLetterEvent        = ffm("DeadLetter.event", "The UUID of the event that could not be delivered")
+	DeadLetterAttempts     = ffm("DeadLetter.attempts", "The number of delivery attempts made")
+	DeadLetterError        = ffm("DeadLetter.error", "The error from the final delivery attempt")
+	DeadLetterLastResponse = ffm("DeadLetter.lastResponse", "The response received on the final delivery attempt")
+	DeadLetterCreated      = ffm("DeadLetter.created", "The time the dead letter was recorded")
 )
@@ -237,6 +237,11 @@ func (dm *dataManager) dataLookupAndCache(ctx context.Context, msg *core.Message
 	if !foundAllData {
 		return data, false, err
 	}
+	if database.IsReadReplica(ctx) {
+		// A read replica can lag behind the primary, so what it returns must not replace the cached
+		// message that the rest of FireFly relies on
+		return data, true, nil
+	}
 	dm.UpdateMessageCache(msg, data)
 	return data, true, nil
 }
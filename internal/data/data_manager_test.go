@@ -374,6 +374,39 @@ func TestGetMessageDataOk(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestGetMessageDataReadReplicaNotCached(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	dataID := fftypes.NewUUID()
+	hash := fftypes.NewRandB32()
+	msg := &core.Message{
+		Header: core.MessageHeader{ID: fftypes.NewUUID()},
+		Data:   core.DataRefs{{ID: dataID, Hash: hash}},
+	}
+
+	mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil).Once()
+	mdi.On("GetDataByID", mock.Anything, dataID, true).Return(&core.Data{
+		ID:   dataID,
+		Hash: hash,
+	}, nil).Twice()
+
+	replicaCtx := database.WithReadReplica(ctx)
+	data, foundAll, err := dm.GetMessageDataCached(replicaCtx, msg)
+	assert.NoError(t, err)
+	assert.True(t, foundAll)
+	assert.Equal(t, *dataID, *data[0].ID)
+	_, _, foundAll, err = dm.GetMessageWithDataCached(replicaCtx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.True(t, foundAll)
+
+	cachedMsg, _ := dm.PeekMessageCache(ctx, msg.Header.ID)
+	assert.Nil(t, cachedMsg)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestCheckDatatypeVerifiesTheSchema(t *testing.T) {
 
 	dm, ctx, cancel := newTestDataManager(t)
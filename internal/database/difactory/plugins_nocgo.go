@@ -21,9 +21,11 @@ package difactory
 
 import (
 	"github.com/hyperledger/firefly/internal/database/postgres"
+	"github.com/hyperledger/firefly/internal/database/sqlitego"
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
 var pluginsByName = map[string]func() database.Plugin{
 	(*postgres.Postgres)(nil).Name(): func() database.Plugin { return &postgres.Postgres{} },
+	(*sqlitego.SQLiteGo)(nil).Name(): func() database.Plugin { return &sqlitego.SQLiteGo{} }, // pure Go translation of SQLite
 }
@@ -19,6 +19,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"database/sql"
 
@@ -39,7 +40,14 @@ type Postgres struct {
 
 func (psql *Postgres) Init(ctx context.Context, config config.Section) error {
 	capabilities := &database.Capabilities{}
-	return psql.SQLCommon.Init(ctx, psql, config, capabilities)
+	if err := psql.SQLCommon.Init(ctx, psql, config, capabilities); err != nil {
+		return err
+	}
+	return psql.SQLCommon.InitReadReplica(ctx, config.SubSection(sqlcommon.SQLConfReadReplica))
+}
+
+func (psql *Postgres) InitMigrator(ctx context.Context, config config.Section) error {
+	return psql.SQLCommon.InitMigrator(ctx, psql, config)
 }
 
 func (psql *Postgres) RegisterListener(listener database.Callbacks) {
@@ -62,6 +70,20 @@ func (psql *Postgres) Features() sqlcommon.SQLFeatures {
 		return fmt.Sprintf(`LOCK TABLE "%s" IN EXCLUSIVE MODE;`, table)
 	}
 	features.MultiRowInsert = true
+	features.JSONExtractTextSQL = func(column string, path []string) string {
+		return fmt.Sprintf("(%s::jsonb #>> '{%s}')", column, strings.Join(path, ","))
+	}
+	features.JSONExtractNumberSQL = func(column string, path []string) string {
+		return fmt.Sprintf("(CASE WHEN jsonb_typeof(%[1]s::jsonb #> '{%[2]s}') = 'number' THEN (%[1]s::jsonb #>> '{%[2]s}')::numeric END)", column, strings.Join(path, ","))
+	}
+	features.FullTextSearch = func(query sq.SelectBuilder, table, text string) sq.SelectBuilder {
+		// The 'simple' configuration does not stem words or drop stop words, so IDs and tags match exactly
+		vector := fmt.Sprintf("to_tsvector('simple', %s.content)", table)
+		return query.
+			Column(sq.Alias(sq.Expr(fmt.Sprintf("ts_rank(%s, plainto_tsquery('simple', ?))", vector), text), "hit_rank")).
+			Where(sq.Expr(fmt.Sprintf("%s @@ plainto_tsquery('simple', ?)", vector), text))
+	}
+	features.InitFullTextSearch = nil // The index is created by the migrations
 	return features
 }
 
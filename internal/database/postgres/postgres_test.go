@@ -41,11 +41,49 @@ func TestPostgresProvider(t *testing.T) {
 	assert.Equal(t, "postgres", psql.Name())
 	assert.Equal(t, sq.Dollar, psql.Features().PlaceholderFormat)
 	assert.Equal(t, `LOCK TABLE "events" IN EXCLUSIVE MODE;`, psql.Features().ExclusiveTableLockSQL("events"))
+	assert.Equal(t, `(value::jsonb #>> '{order,id}')`, psql.Features().JSONExtractTextSQL("value", []string{"order", "id"}))
+	assert.Equal(t, `(CASE WHEN jsonb_typeof(value::jsonb #> '{amount}') = 'number' THEN (value::jsonb #>> '{amount}')::numeric END)`, psql.Features().JSONExtractNumberSQL("value", []string{"amount"}))
+
+	search := psql.Features().FullTextSearch(sq.Select("hit_type").From("searchindex"), "searchindex", "widget")
+	sql, args, err := search.PlaceholderFormat(sq.Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT hit_type, (ts_rank(to_tsvector('simple', searchindex.content), plainto_tsquery('simple', $1))) AS hit_rank FROM searchindex WHERE to_tsvector('simple', searchindex.content) @@ plainto_tsquery('simple', $2)", sql)
+	assert.Equal(t, []interface{}{"widget", "widget"}, args)
 
 	insert := sq.Insert("test").Columns("col1").Values("val1")
 	insert, query := psql.ApplyInsertQueryCustomizations(insert, true)
-	sql, _, err := insert.ToSql()
+	sql, _, err = insert.ToSql()
 	assert.NoError(t, err)
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)  ON CONFLICT DO NOTHING RETURNING seq", sql)
 	assert.True(t, query)
 }
+
+func TestPostgresProviderReadReplica(t *testing.T) {
+	psql := &Postgres{}
+	config := config.RootSection("unittest.replica")
+	psql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	config.SubSection(sqlcommon.SQLConfReadReplica).Set(sqlcommon.SQLConfDatasourceURL, "!bad replica connection")
+	err := psql.Init(context.Background(), config)
+	assert.NoError(t, err)
+	psql.Close()
+}
+
+func TestPostgresProviderInitMigrator(t *testing.T) {
+	psql := &Postgres{}
+	config := config.RootSection("unittest.migrator")
+	psql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	err := psql.InitMigrator(context.Background(), config)
+	assert.NoError(t, err)
+	err = psql.CheckSchema(context.Background())
+	assert.Regexp(t, "FF10163", err)
+}
+
+func TestPostgresProviderInitFail(t *testing.T) {
+	psql := &Postgres{}
+	config := config.RootSection("unittest.nourl")
+	psql.InitConfig(config)
+	err := psql.Init(context.Background(), config)
+	assert.Regexp(t, "FF10138", err)
+}
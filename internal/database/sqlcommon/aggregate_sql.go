@@ -0,0 +1,311 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// aggregateBigIntFields are numeric fields stored as hex encoded big integers, which the database cannot calculate
+// metrics over. When a metric uses one of these, the metrics are calculated as the matching rows are read
+var aggregateBigIntFields = map[database.CollectionName]map[string]bool{
+	database.CollectionName(database.CollectionTokenTransfers): {"amount": true},
+}
+
+// aggregateColumns are the columns resolved from the fields of an aggregate query
+type aggregateColumns struct {
+	groupBy       []string
+	groupColumns  []string
+	metrics       []*core.AggregateMetric
+	metricColumns []string
+	bigInt        []bool
+	inMemory      bool
+}
+
+func (s *SQLCommon) getAggregateColumns(ctx context.Context, collection database.CollectionName, cc *chartCollection, query *core.AggregateQuery) (*aggregateColumns, error) {
+	fb := cc.queryFactory.NewFilter(ctx)
+	isField := func(name string, fields []string) bool {
+		for _, f := range fields {
+			if f == name {
+				return true
+			}
+		}
+		return false
+	}
+	fields := fb.Fields()
+	numericFields := fb.NumericFields()
+
+	ac := &aggregateColumns{}
+	for _, groupBy := range query.GroupBy {
+		groupBy = strings.ToLower(groupBy)
+		if !isField(groupBy, fields) {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidChartGroupBy, groupBy, collection)
+		}
+		ac.groupBy = append(ac.groupBy, groupBy)
+		ac.groupColumns = append(ac.groupColumns, s.mapField("", groupBy, cc.fieldMap))
+	}
+	for _, metric := range query.Metrics {
+		field := strings.ToLower(metric.Field)
+		if !isField(field, numericFields) {
+			return nil, i18n.NewError(ctx, coremsgs.MsgAggregateFieldNotNumeric, field, collection)
+		}
+		switch metric.Function {
+		case core.AggregateFunctionSum, core.AggregateFunctionMin, core.AggregateFunctionMax:
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidAggregateMetric, fmt.Sprintf("%s:%s", metric.Function, metric.Field))
+		}
+		bigInt := aggregateBigIntFields[collection][field]
+		ac.metrics = append(ac.metrics, &core.AggregateMetric{Function: metric.Function, Field: field})
+		ac.metricColumns = append(ac.metricColumns, s.mapField("", field, cc.fieldMap))
+		ac.bigInt = append(ac.bigInt, bigInt)
+		ac.inMemory = ac.inMemory || bigInt
+	}
+	return ac, nil
+}
+
+// getAggregateQuery builds a query that calculates the count and metrics for each group in the database
+func (s *SQLCommon) getAggregateQuery(cc *chartCollection, ac *aggregateColumns, fop sq.Sqlizer, fi *database.FilterInfo) sq.SelectBuilder {
+	query := sq.Select()
+	groupAliases := make([]string, len(ac.groupColumns))
+	for i, column := range ac.groupColumns {
+		groupAliases[i] = fmt.Sprintf("ff_group_%d", i)
+		query = query.Column(fmt.Sprintf("%s AS %s", column, groupAliases[i]))
+	}
+	query = query.Column("COUNT(*)")
+	for i, metric := range ac.metrics {
+		query = query.Column(fmt.Sprintf("%s(%s)", strings.ToUpper(string(metric.Function)), ac.metricColumns[i]))
+	}
+	query = query.From(cc.tableName).Where(fop)
+	if len(groupAliases) > 0 {
+		query = query.GroupBy(groupAliases...).OrderBy(groupAliases...)
+	}
+	if fi.Skip > 0 {
+		query = query.Offset(fi.Skip)
+	}
+	if fi.Limit > 0 {
+		query = query.Limit(fi.Limit)
+	}
+	return query
+}
+
+// getAggregateRowsQuery builds a query for the group and metric values of each matching row, ordered by group
+func (s *SQLCommon) getAggregateRowsQuery(cc *chartCollection, ac *aggregateColumns, fop sq.Sqlizer) sq.SelectBuilder {
+	columns := make([]string, 0, len(ac.groupColumns)+len(ac.metricColumns))
+	columns = append(columns, ac.groupColumns...)
+	columns = append(columns, ac.metricColumns...)
+	query := sq.Select(columns...).From(cc.tableName).Where(fop)
+	if len(ac.groupColumns) > 0 {
+		query = query.OrderBy(ac.groupColumns...)
+	}
+	return query
+}
+
+func (s *SQLCommon) newAggregateResult(ac *aggregateColumns, groupValues []sql.NullString) *core.AggregateResult {
+	result := &core.AggregateResult{
+		Group:   fftypes.JSONObject{},
+		Metrics: make([]*core.AggregateMetricResult, len(ac.metrics)),
+	}
+	for i, field := range ac.groupBy {
+		if groupValues[i].Valid {
+			result.Group[field] = groupValues[i].String
+		} else {
+			result.Group[field] = nil
+		}
+	}
+	for i, metric := range ac.metrics {
+		result.Metrics[i] = &core.AggregateMetricResult{AggregateMetric: *metric}
+	}
+	return result
+}
+
+func (s *SQLCommon) aggregateResults(ctx context.Context, tableName string, rows *sql.Rows, ac *aggregateColumns) ([]*core.AggregateResult, error) {
+	results := make([]*core.AggregateResult, 0)
+	for rows.Next() {
+		groupValues := make([]sql.NullString, len(ac.groupColumns))
+		metricValues := make([]sql.NullString, len(ac.metrics))
+		var count int64
+		cols := make([]interface{}, 0, len(groupValues)+1+len(metricValues))
+		for i := range groupValues {
+			cols = append(cols, &groupValues[i])
+		}
+		cols = append(cols, &count)
+		for i := range metricValues {
+			cols = append(cols, &metricValues[i])
+		}
+		if err := rows.Scan(cols...); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tableName)
+		}
+		result := s.newAggregateResult(ac, groupValues)
+		result.Count = fmt.Sprintf("%d", count)
+		for i, mv := range metricValues {
+			if mv.Valid {
+				value := mv.String
+				result.Metrics[i].Value = &value
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// aggregateRowsInMemory calculates the count and metrics for each group from the matching rows, which are read in group order
+func (s *SQLCommon) aggregateRowsInMemory(ctx context.Context, tableName string, rows *sql.Rows, ac *aggregateColumns, fi *database.FilterInfo) ([]*core.AggregateResult, error) {
+	results := make([]*core.AggregateResult, 0)
+	var current *core.AggregateResult
+	var currentGroup []sql.NullString
+	var count int64
+	var values []*big.Int
+	complete := func() {
+		if current != nil {
+			current.Count = fmt.Sprintf("%d", count)
+			for i, v := range values {
+				if v != nil {
+					value := v.String()
+					current.Metrics[i].Value = &value
+				}
+			}
+			results = append(results, current)
+		}
+	}
+	for rows.Next() {
+		groupValues := make([]sql.NullString, len(ac.groupColumns))
+		metricValues := make([]sql.NullString, len(ac.metrics))
+		cols := make([]interface{}, 0, len(groupValues)+len(metricValues))
+		for i := range groupValues {
+			cols = append(cols, &groupValues[i])
+		}
+		for i := range metricValues {
+			cols = append(cols, &metricValues[i])
+		}
+		if err := rows.Scan(cols...); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tableName)
+		}
+		if current == nil || !sameAggregateGroup(currentGroup, groupValues) {
+			complete()
+			current = s.newAggregateResult(ac, groupValues)
+			currentGroup = groupValues
+			count = 0
+			values = make([]*big.Int, len(ac.metrics))
+		}
+		count++
+		for i, mv := range metricValues {
+			if !mv.Valid {
+				continue
+			}
+			base := 10
+			if ac.bigInt[i] {
+				base = 16
+			}
+			v, ok := new(big.Int).SetString(mv.String, base)
+			if !ok {
+				continue
+			}
+			values[i] = applyAggregateFunction(ac.metrics[i].Function, values[i], v)
+		}
+	}
+	if current == nil && len(ac.groupColumns) == 0 {
+		// Without any groupBy fields there is always a single result, as there is from the database
+		current = s.newAggregateResult(ac, nil)
+		values = make([]*big.Int, len(ac.metrics))
+	}
+	complete()
+
+	if fi.Skip > 0 {
+		if fi.Skip >= uint64(len(results)) {
+			return []*core.AggregateResult{}, nil
+		}
+		results = results[fi.Skip:]
+	}
+	if fi.Limit > 0 && fi.Limit < uint64(len(results)) {
+		results = results[:fi.Limit]
+	}
+	return results, nil
+}
+
+func sameAggregateGroup(a, b []sql.NullString) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func applyAggregateFunction(fn core.AggregateFunction, current, v *big.Int) *big.Int {
+	if current == nil {
+		return v
+	}
+	switch fn {
+	case core.AggregateFunctionMin:
+		if v.Cmp(current) < 0 {
+			return v
+		}
+	case core.AggregateFunctionMax:
+		if v.Cmp(current) > 0 {
+			return v
+		}
+	default:
+		return current.Add(current, v)
+	}
+	return current
+}
+
+func (s *SQLCommon) GetAggregate(ctx context.Context, collection database.CollectionName, query *core.AggregateQuery, filter database.Filter) ([]*core.AggregateResult, error) {
+	cc, err := s.getChartCollection(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	ac, err := s.getAggregateColumns(ctx, collection, cc, query)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := filter.Finalize()
+	if err != nil {
+		return nil, err
+	}
+	fop, err := s.filterOp(ctx, "", fi, cc.fieldMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if ac.inMemory {
+		rows, _, err := s.query(ctx, cc.tableName, s.getAggregateRowsQuery(cc, ac, fop))
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		return s.aggregateRowsInMemory(ctx, cc.tableName, rows, ac, fi)
+	}
+
+	rows, _, err := s.query(ctx, cc.tableName, s.getAggregateQuery(cc, ac, fop, fi))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return s.aggregateResults(ctx, cc.tableName, rows, ac)
+}
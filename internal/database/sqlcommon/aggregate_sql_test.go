@@ -0,0 +1,278 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestAggregateE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	// Data with blobs of different sizes, across two datatypes
+	for i, dt := range []string{"order", "order", "invoice", "order"} {
+		err := s.UpsertData(ctx, &core.Data{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Datatype:  &core.DatatypeRef{Name: dt, Version: "1"},
+			Hash:      fftypes.NewRandB32(),
+			Created:   fftypes.Now(),
+			Blob:      &core.BlobRef{Hash: fftypes.NewRandB32(), Size: int64((i + 1) * 100)},
+		}, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+
+	// Token transfers, with amounts that are stored as hex in the database
+	pool1, pool2 := fftypes.NewUUID(), fftypes.NewUUID()
+	for i, tt := range []struct {
+		pool   *fftypes.UUID
+		amount int64
+	}{{pool1, 10}, {pool1, 255}, {pool2, 4096}} {
+		transfer := &core.TokenTransfer{
+			LocalID:    fftypes.NewUUID(),
+			Type:       core.TokenTransferTypeTransfer,
+			Pool:       tt.pool,
+			Namespace:  "ns1",
+			ProtocolID: fmt.Sprintf("%.12d", i),
+		}
+		transfer.Amount.Int().SetInt64(tt.amount)
+		err := s.UpsertTokenTransfer(ctx, transfer)
+		assert.NoError(t, err)
+	}
+
+	// Metrics calculated in the database, with a GROUP BY query
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	dataQuery := &core.AggregateQuery{
+		GroupBy: []string{"datatype.name"},
+		Metrics: []*core.AggregateMetric{
+			{Function: core.AggregateFunctionSum, Field: "blob.size"},
+			{Function: core.AggregateFunctionMin, Field: "blob.size"},
+			{Function: core.AggregateFunctionMax, Field: "Blob.Size"},
+		},
+	}
+	ac, err := s.getAggregateColumns(ctx, database.CollectionName(database.CollectionData), chartCollections[database.CollectionName(database.CollectionData)], dataQuery)
+	assert.NoError(t, err)
+	assert.False(t, ac.inMemory)
+	results, err := s.GetAggregate(ctx, database.CollectionName(database.CollectionData), dataQuery, fb.And(fb.Eq("namespace", "ns1")))
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, fftypes.JSONObject{"datatype.name": "invoice"}, results[0].Group)
+	assert.Equal(t, "1", results[0].Count)
+	assert.Equal(t, "300", *results[0].Metrics[0].Value)
+	assert.Equal(t, fftypes.JSONObject{"datatype.name": "order"}, results[1].Group)
+	assert.Equal(t, "3", results[1].Count)
+	assert.Equal(t, "700", *results[1].Metrics[0].Value)
+	assert.Equal(t, "100", *results[1].Metrics[1].Value)
+	assert.Equal(t, "400", *results[1].Metrics[2].Value)
+	assert.Equal(t, "blob.size", results[1].Metrics[2].Field)
+
+	// No groupBy fields is a single group
+	results, err = s.GetAggregate(ctx, database.CollectionName(database.CollectionData), &core.AggregateQuery{},
+		fb.And(fb.Eq("namespace", "ns1"), fb.Gt("blob.size", 150)))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "3", results[0].Count)
+	assert.Empty(t, results[0].Group)
+
+	// Metrics over big integers calculated as the rows are read
+	tfb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	query := &core.AggregateQuery{
+		GroupBy: []string{"pool"},
+		Metrics: []*core.AggregateMetric{
+			{Function: core.AggregateFunctionSum, Field: "amount"},
+			{Function: core.AggregateFunctionMin, Field: "amount"},
+			{Function: core.AggregateFunctionMax, Field: "amount"},
+		},
+	}
+	ac, err = s.getAggregateColumns(ctx, database.CollectionName(database.CollectionTokenTransfers), chartCollections[database.CollectionName(database.CollectionTokenTransfers)], query)
+	assert.NoError(t, err)
+	assert.True(t, ac.inMemory)
+	results, err = s.GetAggregate(ctx, database.CollectionName(database.CollectionTokenTransfers), query, tfb.And(tfb.Eq("namespace", "ns1")))
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	for _, r := range results {
+		if r.Group["pool"] == pool1.String() {
+			assert.Equal(t, "2", r.Count)
+			assert.Equal(t, "265", *r.Metrics[0].Value)
+			assert.Equal(t, "10", *r.Metrics[1].Value)
+			assert.Equal(t, "255", *r.Metrics[2].Value)
+		} else {
+			assert.Equal(t, pool2.String(), r.Group["pool"])
+			assert.Equal(t, "1", r.Count)
+			assert.Equal(t, "4096", *r.Metrics[0].Value)
+		}
+	}
+
+	// Skip and limit apply to the groups
+	results, err = s.GetAggregate(ctx, database.CollectionName(database.CollectionTokenTransfers), query, tfb.And(tfb.Eq("namespace", "ns1")).Skip(1).Limit(1))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	results, err = s.GetAggregate(ctx, database.CollectionName(database.CollectionTokenTransfers), query, tfb.And(tfb.Eq("namespace", "ns1")).Skip(2))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	// No matching rows, and no groupBy fields
+	tfb = database.TokenTransferQueryFactory.NewFilter(ctx)
+	results, err = s.GetAggregate(ctx, database.CollectionName(database.CollectionTokenTransfers), &core.AggregateQuery{
+		Metrics: query.Metrics,
+	}, tfb.And(tfb.Eq("namespace", "ns2")))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "0", results[0].Count)
+	assert.Nil(t, results[0].Metrics[0].Value)
+}
+
+func TestGetAggregateQuery(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	cc := chartCollections[database.CollectionName(database.CollectionMessages)]
+	ac, err := s.getAggregateColumns(context.Background(), database.CollectionName(database.CollectionMessages), cc, &core.AggregateQuery{
+		GroupBy: []string{"type", "tag"},
+		Metrics: []*core.AggregateMetric{{Function: core.AggregateFunctionMax, Field: "sequence"}},
+	})
+	assert.NoError(t, err)
+	fi, err := fb.And(fb.Eq("topics", "topic1")).Skip(10).Limit(5).Finalize()
+	assert.NoError(t, err)
+	fop, err := s.filterOp(context.Background(), "", fi, cc.fieldMap)
+	assert.NoError(t, err)
+
+	sqlQuery, args, err := s.getAggregateQuery(cc, ac, fop, fi).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT mtype AS ff_group_0, tag AS ff_group_1, COUNT(*), MAX(seq) FROM messages WHERE (topics = ?) "+
+		"GROUP BY ff_group_0, ff_group_1 ORDER BY ff_group_0, ff_group_1 LIMIT 5 OFFSET 10", sqlQuery)
+	assert.Equal(t, []interface{}{"topic1"}, args)
+}
+
+func TestGetAggregateUnsupportedCollection(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName("wrong"), &core.AggregateQuery{}, fb.And())
+	assert.Regexp(t, "FF10301", err)
+}
+
+func TestGetAggregateBadGroupBy(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionMessages), &core.AggregateQuery{
+		GroupBy: []string{"wrong"},
+	}, fb.And())
+	assert.Regexp(t, "FF10448.*wrong", err)
+}
+
+func TestGetAggregateMetricNotNumeric(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionMessages), &core.AggregateQuery{
+		Metrics: []*core.AggregateMetric{{Function: core.AggregateFunctionSum, Field: "tag"}},
+	}, fb.And())
+	assert.Regexp(t, "FF10452.*tag", err)
+}
+
+func TestGetAggregateMetricBadFunction(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionMessages), &core.AggregateQuery{
+		Metrics: []*core.AggregateMetric{{Function: "avg", Field: "sequence"}},
+	}, fb.And())
+	assert.Regexp(t, "FF10451.*avg:sequence", err)
+}
+
+func TestGetAggregateBadFilter(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionMessages), &core.AggregateQuery{}, fb.And(fb.Eq("wrong", "")))
+	assert.Regexp(t, "FF00142", err)
+}
+
+func TestGetAggregateQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionMessages), &core.AggregateQuery{}, fb.And())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAggregateScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"ff_group_0"}).AddRow("only one"))
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionMessages), &core.AggregateQuery{
+		GroupBy: []string{"tag"},
+	}, fb.And())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAggregateInMemoryQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	fb := database.TokenTransferQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionTokenTransfers), &core.AggregateQuery{
+		Metrics: []*core.AggregateMetric{{Function: core.AggregateFunctionSum, Field: "amount"}},
+	}, fb.And())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAggregateInMemoryScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"pool_id"}).AddRow("only one"))
+	fb := database.TokenTransferQueryFactory.NewFilter(context.Background())
+	_, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionTokenTransfers), &core.AggregateQuery{
+		GroupBy: []string{"pool"},
+		Metrics: []*core.AggregateMetric{{Function: core.AggregateFunctionSum, Field: "amount"}},
+	}, fb.And())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAggregateInMemoryIgnoresBadValues(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"pool_id", "amount"}).
+		AddRow("pool1", "zz").
+		AddRow("pool1", nil).
+		AddRow(nil, "ff"),
+	)
+	fb := database.TokenTransferQueryFactory.NewFilter(context.Background())
+	results, err := s.GetAggregate(context.Background(), database.CollectionName(database.CollectionTokenTransfers), &core.AggregateQuery{
+		GroupBy: []string{"pool"},
+		Metrics: []*core.AggregateMetric{{Function: core.AggregateFunctionSum, Field: "amount"}},
+	}, fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "2", results[0].Count)
+	assert.Nil(t, results[0].Metrics[0].Value)
+	assert.Nil(t, results[1].Group["pool"])
+	assert.Equal(t, strPtr("255"), results[1].Metrics[0].Value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -210,7 +210,8 @@ func (s *SQLCommon) GetBatches(ctx context.Context, filter database.Filter) (mes
 		batches = append(batches, batch)
 	}
 
-	return batches, s.queryRes(ctx, batchesTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, batchesTable, tx, fop, fi)
+	return batches, fr, err
 
 }
 
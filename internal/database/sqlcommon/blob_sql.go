@@ -185,7 +185,8 @@ func (s *SQLCommon) GetBlobs(ctx context.Context, filter database.Filter) (messa
 		blob = append(blob, d)
 	}
 
-	return blob, s.queryRes(ctx, blobsTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, blobsTable, tx, fop, fi)
+	return blob, fr, err
 
 }
 
@@ -171,5 +171,6 @@ func (s *SQLCommon) GetBlockchainEvents(ctx context.Context, filter database.Fil
 		events = append(events, event)
 	}
 
-	return events, s.queryRes(ctx, blockchaineventsTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, blockchaineventsTable, tx, fop, fi)
+	return events, fr, err
 }
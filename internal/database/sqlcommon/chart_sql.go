@@ -19,147 +19,170 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strconv"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
-	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
-func (s *SQLCommon) getTableNameFromCollection(ctx context.Context, collection database.CollectionName) (tableName string, fieldMap map[string]string, err error) {
-	switch collection {
-	case database.CollectionName(database.CollectionMessages):
-		return "messages", msgFilterFieldMap, nil
-	case database.CollectionName(database.CollectionTransactions):
-		return "transactions", transactionFilterFieldMap, nil
-	case database.CollectionName(database.CollectionOperations):
-		return "operations", opFilterFieldMap, nil
-	case database.CollectionName(database.CollectionEvents):
-		return "events", eventFilterFieldMap, nil
-	case database.CollectionName(database.CollectionTokenTransfers):
-		return "tokentransfer", tokenTransferFilterFieldMap, nil
-	case database.CollectionName(database.CollectionBlockchainEvents):
-		return "blockchainevents", blockchainEventFilterFieldMap, nil
-	default:
-		return "", nil, i18n.NewError(ctx, coremsgs.MsgUnsupportedCollection, collection)
-	}
+// chartCollection describes how to build a histogram over a collection
+type chartCollection struct {
+	tableName    string
+	timestampKey string
+	fieldMap     map[string]string
+	queryFactory database.QueryFactory
 }
 
-func (s *SQLCommon) getSelectStatements(ns string, tableName string, intervals []core.ChartHistogramInterval, timestampKey string, sql sq.SelectBuilder) (queries []sq.SelectBuilder) {
-	for _, interval := range intervals {
-		queries = append(queries, sql.
-			From(tableName).
-			Where(
-				sq.And{
-					sq.GtOrEq{timestampKey: interval.StartTime},
-					sq.Lt{timestampKey: interval.EndTime},
-					sq.Eq{"namespace": ns},
-				}).
-			OrderBy(timestampKey).
-			Limit(uint64(config.GetInt(coreconfig.HistogramsMaxChartRows))))
-	}
+var chartCollections = map[database.CollectionName]*chartCollection{
+	database.CollectionName(database.CollectionMessages):         {messagesTable, "created", msgFilterFieldMap, database.MessageQueryFactory},
+	database.CollectionName(database.CollectionTransactions):     {transactionsTable, "created", transactionFilterFieldMap, database.TransactionQueryFactory},
+	database.CollectionName(database.CollectionOperations):       {operationsTable, "created", opFilterFieldMap, database.OperationQueryFactory},
+	database.CollectionName(database.CollectionEvents):           {eventsTable, "created", eventFilterFieldMap, database.EventQueryFactory},
+	database.CollectionName(database.CollectionTokenTransfers):   {tokentransferTable, "created", tokenTransferFilterFieldMap, database.TokenTransferQueryFactory},
+	database.CollectionName(database.CollectionTokenApprovals):   {tokenapprovalTable, "created", tokenApprovalFilterFieldMap, database.TokenApprovalQueryFactory},
+	database.CollectionName(database.CollectionTokenPools):       {tokenpoolTable, "created", tokenPoolFilterFieldMap, database.TokenPoolQueryFactory},
+	database.CollectionName(database.CollectionBlockchainEvents): {blockchaineventsTable, "timestamp", blockchainEventFilterFieldMap, database.BlockchainEventQueryFactory},
+	database.CollectionName(database.CollectionPins):             {pinsTable, "created", pinFilterFieldMap, database.PinQueryFactory},
+	database.CollectionName(database.CollectionIdentities):       {identitiesTable, "created", identityFilterFieldMap, database.IdentityQueryFactory},
+	database.CollectionName(database.CollectionBatches):          {batchesTable, "created", batchFilterFieldMap, database.BatchQueryFactory},
+	database.CollectionName(database.CollectionData):             {dataTable, "created", dataFilterFieldMap, database.DataQueryFactory},
+}
 
-	return queries
+func (s *SQLCommon) getChartCollection(ctx context.Context, collection database.CollectionName) (*chartCollection, error) {
+	cc, ok := chartCollections[collection]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgUnsupportedCollection, collection)
+	}
+	return cc, nil
 }
 
-func (s *SQLCommon) histogramResult(ctx context.Context, tableName string, rows *sql.Rows, onlyTimestamp bool) (map[string]int, string, error) {
-	total := 0
-	if onlyTimestamp {
-		countMap := map[string]int{}
-		for rows.Next() {
-			var timestamp string
-			err := rows.Scan(&timestamp)
-			if err != nil {
-				return nil, "", i18n.NewError(ctx, coremsgs.MsgDBReadErr, tableName)
+// getGroupColumn returns the column to break down each bucket by, which defaults to the
+// type of the record for collections that have one
+func (s *SQLCommon) getGroupColumn(ctx context.Context, cc *chartCollection, collection database.CollectionName, groupBy string) (string, error) {
+	fields := cc.queryFactory.NewFilter(ctx).Fields()
+	hasField := func(name string) bool {
+		for _, f := range fields {
+			if f == name {
+				return true
 			}
-			total++
 		}
-		return countMap, strconv.Itoa(total), nil
+		return false
 	}
-
-	typeMap := map[string]int{}
-	for rows.Next() {
-		var timestamp string
-		var typeStr string
-		err := rows.Scan(&timestamp, &typeStr)
-		if err != nil {
-			return nil, "", i18n.NewError(ctx, coremsgs.MsgDBReadErr, tableName)
+	if groupBy == "" {
+		if !hasField("type") {
+			return "", nil
 		}
+		groupBy = "type"
+	}
+	groupBy = strings.ToLower(groupBy)
+	if !hasField(groupBy) {
+		return "", i18n.NewError(ctx, coremsgs.MsgInvalidChartGroupBy, groupBy, collection)
+	}
+	return s.mapField("", groupBy, cc.fieldMap), nil
+}
 
-		if _, ok := typeMap[typeStr]; !ok {
-			typeMap[typeStr] = 1
-		} else {
-			typeMap[typeStr]++
+// getHistogramQuery builds a single query that counts the rows in each interval (and group),
+// with the bucket of each row determined by a CASE over the interval boundaries
+func (s *SQLCommon) getHistogramQuery(ns string, cc *chartCollection, intervals []core.ChartHistogramInterval, groupColumn string) sq.SelectBuilder {
+	var bucketExpr strings.Builder
+	bucketArgs := make([]interface{}, 0, len(intervals)*2)
+	bucketExpr.WriteString("CASE")
+	minStart, maxEnd := intervals[0].StartTime, intervals[0].EndTime
+	for i, interval := range intervals {
+		bucketExpr.WriteString(fmt.Sprintf(" WHEN %s >= ? AND %s < ? THEN %d", cc.timestampKey, cc.timestampKey, i))
+		bucketArgs = append(bucketArgs, interval.StartTime, interval.EndTime)
+		if interval.StartTime.UnixNano() < minStart.UnixNano() {
+			minStart = interval.StartTime
+		}
+		if interval.EndTime.UnixNano() > maxEnd.UnixNano() {
+			maxEnd = interval.EndTime
 		}
+	}
+	bucketExpr.WriteString(" END AS ff_bucket")
 
-		total++
+	query := sq.Select().Column(bucketExpr.String(), bucketArgs...)
+	groupBy := []string{"ff_bucket"}
+	if groupColumn != "" {
+		query = query.Column(fmt.Sprintf("%s AS ff_group", groupColumn))
+		groupBy = append(groupBy, "ff_group")
 	}
+	return query.
+		Column("COUNT(*)").
+		From(cc.tableName).
+		Where(sq.And{
+			sq.Eq{"namespace": ns},
+			sq.GtOrEq{cc.timestampKey: minStart},
+			sq.Lt{cc.timestampKey: maxEnd},
+		}).
+		GroupBy(groupBy...).
+		OrderBy(groupBy...)
+}
 
-	return typeMap, strconv.Itoa(total), nil
+func (s *SQLCommon) histogramResult(ctx context.Context, tableName string, rows *sql.Rows, grouped bool, histogram []*core.ChartHistogram, totals []int64) error {
+	for rows.Next() {
+		var bucket sql.NullInt64
+		var group sql.NullString
+		var count int64
+		var err error
+		if grouped {
+			err = rows.Scan(&bucket, &group, &count)
+		} else {
+			err = rows.Scan(&bucket, &count)
+		}
+		if err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tableName)
+		}
+		if !bucket.Valid || bucket.Int64 < 0 || bucket.Int64 >= int64(len(histogram)) {
+			// Rows between overlapping or non-contiguous intervals do not belong to any bucket
+			continue
+		}
+		totals[bucket.Int64] += count
+		if grouped {
+			histogram[bucket.Int64].Types = append(histogram[bucket.Int64].Types, &core.ChartHistogramType{
+				Count: strconv.FormatInt(count, 10),
+				Type:  group.String,
+			})
+		}
+	}
+	return nil
 }
 
-func (s *SQLCommon) GetChartHistogram(ctx context.Context, ns string, intervals []core.ChartHistogramInterval, collection database.CollectionName) (histogramList []*core.ChartHistogram, err error) {
-	tableName, fieldMap, err := s.getTableNameFromCollection(ctx, collection)
+func (s *SQLCommon) GetChartHistogram(ctx context.Context, ns string, intervals []core.ChartHistogramInterval, collection database.CollectionName, groupBy string) (histogramList []*core.ChartHistogram, err error) {
+	cc, err := s.getChartCollection(ctx, collection)
 	if err != nil {
 		return nil, err
 	}
-
-	// Timestamp column name
-	timestampKey := "created"
-	if tableName == "blockchainevents" {
-		// Blockchain Events have a `timestamp` column name
-		timestampKey = "timestamp"
+	groupColumn, err := s.getGroupColumn(ctx, cc, collection, groupBy)
+	if err != nil {
+		return nil, err
 	}
 
-	// Number of columns to read.
-	// Some tables don't have a `type` field and therefore
-	// a `type` field will not be queried
-	onlyTimestamp := true
-	cols := []string{timestampKey}
-	if typeStr, ok := fieldMap["type"]; ok {
-		cols = append(cols, typeStr)
-		onlyTimestamp = false
+	histogramList = make([]*core.ChartHistogram, len(intervals))
+	for i, interval := range intervals {
+		histogramList[i] = &core.ChartHistogram{
+			Timestamp: interval.StartTime,
+			Types:     make([]*core.ChartHistogramType, 0),
+		}
 	}
-
-	queries := s.getSelectStatements(ns, tableName, intervals, timestampKey, sq.Select(cols...))
-
-	for i, query := range queries {
-		// Query bucket's data
-		rows, _, err := s.query(ctx, tableName, query)
+	totals := make([]int64, len(intervals))
+	if len(intervals) > 0 {
+		rows, _, err := s.query(ctx, cc.tableName, s.getHistogramQuery(ns, cc, intervals, groupColumn))
 		if err != nil {
 			return nil, err
 		}
 		defer rows.Close()
 
-		data, total, err := s.histogramResult(ctx, tableName, rows, onlyTimestamp)
-		if err != nil {
+		if err = s.histogramResult(ctx, cc.tableName, rows, groupColumn != "", histogramList, totals); err != nil {
 			return nil, err
 		}
-
-		histTypes := make([]*core.ChartHistogramType, 0)
-		histBucket := core.ChartHistogram{
-			Count:     total,
-			Timestamp: intervals[i].StartTime,
-			Types:     histTypes,
-			IsCapped:  total == config.GetString(coreconfig.HistogramsMaxChartRows),
-		}
-
-		// If the bucket has types, add their counts
-		if !onlyTimestamp {
-			for k, v := range data {
-				histBucket.Types = append(histBucket.Types,
-					&core.ChartHistogramType{
-						Count: strconv.Itoa(v),
-						Type:  k,
-					})
-			}
-		}
-
-		histogramList = append(histogramList, &histBucket)
+	}
+	for i, total := range totals {
+		histogramList[i].Count = strconv.FormatInt(total, 10)
 	}
 
 	return histogramList, nil
@@ -1,4 +1,4 @@
-// Copyright © 2021 Kaleido, Inc.
+// Copyright © 2022 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -22,12 +22,11 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
-	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var (
@@ -46,28 +45,11 @@ var (
 			Timestamp: fftypes.UnixTime(1000000000),
 			Types: []*core.ChartHistogramType{
 				{
-					Count: "5",
+					Count: "4",
 					Type:  "typeA",
 				},
 				{
-					Count: "5",
-					Type:  "typeB",
-				},
-			},
-		},
-	}
-	expectedHistogramResultIsCapped = []*core.ChartHistogram{
-		{
-			Count:     "10",
-			IsCapped:  true,
-			Timestamp: fftypes.UnixTime(1000000000),
-			Types: []*core.ChartHistogramType{
-				{
-					Count: "5",
-					Type:  "typeA",
-				},
-				{
-					Count: "5",
+					Count: "6",
 					Type:  "typeB",
 				},
 			},
@@ -94,69 +76,51 @@ var (
 		"operations",
 		"transactions",
 		"tokentransfers",
+		"tokenpools",
+		"identities",
+		"batches",
 	}
 	validCollectionsNoTypes = []string{
 		"blockchainevents",
+		"tokenapprovals",
+		"pins",
+		"data",
 	}
 )
 
 func TestGetChartHistogramInvalidCollectionName(t *testing.T) {
-	s, mock := newMockProvider().init()
-	mock.ExpectBegin()
-	_, err := s.GetChartHistogram(context.Background(), "ns1", []core.ChartHistogramInterval{}, database.CollectionName("abc"))
+	s, _ := newMockProvider().init()
+	_, err := s.GetChartHistogram(context.Background(), "ns1", []core.ChartHistogramInterval{}, database.CollectionName("abc"), "")
 	assert.Regexp(t, "FF10301", err)
 }
 
-func TestGetChartHistogramValidCollectionNameWithTypes(t *testing.T) {
-	s, mock := newMockProvider().init()
-	for i := range validCollectionsWithTypes {
-		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp", "type"}).
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB"))
-
-		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsWithTypes[i]))
+func TestGetChartHistogramInvalidGroupBy(t *testing.T) {
+	s, _ := newMockProvider().init()
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "wrong")
+	assert.Regexp(t, "FF10448.*wrong", err)
+}
 
-		assert.NoError(t, err)
-		assert.Equal(t, expectedHistogramResult[0].Count, histogram[0].Count)
-		assert.Equal(t, expectedHistogramResult[0].IsCapped, histogram[0].IsCapped)
-		assert.Equal(t, expectedHistogramResult[0].Timestamp, histogram[0].Timestamp)
-		assert.ElementsMatch(t, expectedHistogramResult[0].Types, histogram[0].Types)
-		assert.NoError(t, mock.ExpectationsWereMet())
-	}
+func TestGetChartHistogramNoIntervals(t *testing.T) {
+	s, mock := newMockProvider().init()
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", []core.ChartHistogramInterval{}, database.CollectionName("messages"), "")
+	assert.NoError(t, err)
+	assert.Empty(t, histogram)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetChartHistogramValidCollectionNameWithTypesAndCapped(t *testing.T) {
+func TestGetChartHistogramValidCollectionNameWithTypes(t *testing.T) {
 	s, mock := newMockProvider().init()
-	coreconfig.Reset()
-	config.Set(coreconfig.HistogramsMaxChartRows, 10)
 	for i := range validCollectionsWithTypes {
-		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp", "type"}).
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeA").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB").
-			AddRow(fftypes.UnixTime(1000000000), "typeB"))
-
-		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsWithTypes[i]))
+		mock.ExpectQuery("SELECT CASE .* AS ff_bucket, .* AS ff_group, COUNT\\(\\*\\) FROM .* GROUP BY ff_bucket, ff_group").
+			WillReturnRows(sqlmock.NewRows([]string{"ff_bucket", "ff_group", "count"}).
+				AddRow(0, "typeA", 4).
+				AddRow(0, "typeB", 6).
+				AddRow(nil, "typeB", 10))
+
+		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsWithTypes[i]), "")
 
 		assert.NoError(t, err)
-		assert.Equal(t, expectedHistogramResultIsCapped[0].Count, histogram[0].Count)
-		assert.Equal(t, expectedHistogramResultIsCapped[0].IsCapped, histogram[0].IsCapped)
-		assert.Equal(t, expectedHistogramResultIsCapped[0].Timestamp, histogram[0].Timestamp)
-		assert.ElementsMatch(t, histogram[0].Types, expectedHistogramResultIsCapped[0].Types)
+		assert.Equal(t, expectedHistogramResult, histogram)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	}
 }
@@ -164,91 +128,111 @@ func TestGetChartHistogramValidCollectionNameWithTypesAndCapped(t *testing.T) {
 func TestGetChartHistogramValidCollectionNameNoTypes(t *testing.T) {
 	for i := range validCollectionsNoTypes {
 		s, mock := newMockProvider().init()
-		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp"}).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)).
-			AddRow(fftypes.UnixTime(1000000000)))
-
-		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsNoTypes[i]))
+		mock.ExpectQuery("SELECT CASE .* AS ff_bucket, COUNT\\(\\*\\) FROM .* GROUP BY ff_bucket").
+			WillReturnRows(sqlmock.NewRows([]string{"ff_bucket", "count"}).
+				AddRow(0, 10))
+
+		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsNoTypes[i]), "")
 		assert.NoError(t, err)
 		assert.Equal(t, expectedHistogramResultNoTypes, histogram)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	}
 }
 
-func TestGetChartHistogramsQueryFail(t *testing.T) {
+func TestGetChartHistogramGroupByField(t *testing.T) {
 	s, mock := newMockProvider().init()
-	mock.ExpectQuery("SELECT *").WillReturnError(fmt.Errorf("pop"))
-
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
-	assert.Regexp(t, "FF10115", err)
-	assert.NoError(t, mock.ExpectationsWereMet())
-}
+	mock.ExpectQuery("SELECT CASE .* AS ff_bucket, pool_id AS ff_group, COUNT\\(\\*\\) FROM tokenapproval").
+		WillReturnRows(sqlmock.NewRows([]string{"ff_bucket", "ff_group", "count"}).
+			AddRow(0, "pool1", 10))
 
-func TestGetChartHistogramScanFailInvalidRowTypeOnlyTimestamp(t *testing.T) {
-	s, mock := newMockProvider().init()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp"}).
-		AddRow(nil))
-
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"))
-	assert.Regexp(t, "FF10121", err)
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("tokenapprovals"), "Pool")
+	assert.NoError(t, err)
+	assert.Equal(t, "10", histogram[0].Count)
+	assert.Equal(t, []*core.ChartHistogramType{{Count: "10", Type: "pool1"}}, histogram[0].Types)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetChartHistogramScanFailTooManyColsOnlyTimestamp(t *testing.T) {
+func TestGetChartHistogramsQueryFail(t *testing.T) {
 	s, mock := newMockProvider().init()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp", "unexpected_col"}).
-		AddRow(fftypes.UnixTime(1000000000), "test"))
+	mock.ExpectQuery("SELECT *").WillReturnError(fmt.Errorf("pop"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"))
-	assert.Regexp(t, "FF10121", err)
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
+	assert.Regexp(t, "FF10115", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetChartHistogramScanFailInvalidRowType(t *testing.T) {
+func TestGetChartHistogramScanFailOnlyTimestamp(t *testing.T) {
 	s, mock := newMockProvider().init()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp", "type"}).
-		AddRow(fftypes.UnixTime(1000000000), nil))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"ff_bucket", "count"}).
+		AddRow(0, "not a number"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"), "")
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestGetChartHistogramScanFailTooManyCols(t *testing.T) {
 	s, mock := newMockProvider().init()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp", "type", "unexpected_col"}).
-		AddRow(fftypes.UnixTime(1000000000), "typeA", "test"))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"ff_bucket", "ff_group", "count", "unexpected_col"}).
+		AddRow(0, "typeA", 1, "test"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestGetChartHistogramSuccessNoRows(t *testing.T) {
 	s, mock := newMockProvider().init()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp", "type"}))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"ff_bucket", "ff_group", "count"}))
 
-	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.NoError(t, err)
 	assert.Equal(t, emptyHistogramResult, histogram)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetChartHistogramSuccessNoRowsOnlyTimestamp(t *testing.T) {
-	s, mock := newMockProvider().init()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"timestamp"}))
-
-	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"))
+func TestGetChartHistogramE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, mock.Anything, mock.Anything, mock.Anything).Return()
+	insertEvent := func(ns string, eventType core.EventType, created int64) {
+		err := s.InsertEvent(ctx, &core.Event{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+			Type:      eventType,
+			Topic:     "topic1",
+			Created:   fftypes.UnixTime(created),
+		})
+		assert.NoError(t, err)
+	}
+	// More rows than the old per-bucket cap of 100
+	for i := 0; i < 150; i++ {
+		insertEvent("ns1", core.EventTypeMessageConfirmed, 1000)
+	}
+	insertEvent("ns1", core.EventTypeTransactionSubmitted, 1001)
+	insertEvent("ns1", core.EventTypeMessageConfirmed, 2000)
+	insertEvent("ns1", core.EventTypeMessageConfirmed, 3000) // outside the intervals
+	insertEvent("ns2", core.EventTypeMessageConfirmed, 1000) // different namespace
+
+	intervals := []core.ChartHistogramInterval{
+		{StartTime: fftypes.UnixTime(1000), EndTime: fftypes.UnixTime(2000)},
+		{StartTime: fftypes.UnixTime(2000), EndTime: fftypes.UnixTime(3000)},
+	}
+	histogram, err := s.GetChartHistogram(ctx, "ns1", intervals, database.CollectionName(database.CollectionEvents), "")
 	assert.NoError(t, err)
-
-	assert.Equal(t, emptyHistogramResult, histogram)
-	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Len(t, histogram, 2)
+	assert.Equal(t, "151", histogram[0].Count)
+	assert.Equal(t, []*core.ChartHistogramType{
+		{Count: "150", Type: string(core.EventTypeMessageConfirmed)},
+		{Count: "1", Type: string(core.EventTypeTransactionSubmitted)},
+	}, histogram[0].Types)
+	assert.Equal(t, "1", histogram[1].Count)
+
+	histogram, err = s.GetChartHistogram(ctx, "ns1", intervals, database.CollectionName(database.CollectionEvents), "topic")
+	assert.NoError(t, err)
+	assert.Equal(t, []*core.ChartHistogramType{
+		{Count: "151", Type: "topic1"},
+	}, histogram[0].Types)
 }
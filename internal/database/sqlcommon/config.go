@@ -37,6 +37,14 @@ const (
 	SQLConfMaxIdleConns = "maxIdleConns"
 	// SQLConfMaxConnLifetime maximum connections to the database
 	SQLConfMaxConnLifetime = "maxConnLifetime"
+	// SQLConfReadReplica is the sub-section for an optional read replica, for providers that support one
+	SQLConfReadReplica = "replica"
+	// SQLConfDataValueIndexes is a list of paths inside data values to index, for efficient filtering
+	SQLConfDataValueIndexes = "dataValueIndexes"
+	// SQLConfDataValueIndexDatatype restricts the index to data of a datatype
+	SQLConfDataValueIndexDatatype = "datatype"
+	// SQLConfDataValueIndexPaths the dot separated paths inside the value to index
+	SQLConfDataValueIndexPaths = "paths"
 )
 
 const (
@@ -51,4 +59,20 @@ func (s *SQLCommon) InitConfig(provider Provider, config config.Section) {
 	config.AddKnownKey(SQLConfMaxConnIdleTime, "1m")
 	config.AddKnownKey(SQLConfMaxIdleConns) // defaults to the max connections
 	config.AddKnownKey(SQLConfMaxConnLifetime)
+
+	indexConfig := config.SubArray(SQLConfDataValueIndexes)
+	indexConfig.AddKnownKey(SQLConfDataValueIndexDatatype)
+	indexConfig.AddKnownKey(SQLConfDataValueIndexPaths)
+}
+
+// InitReadReplicaConfig adds the config for an optional read replica, which has the same
+// connection settings as the primary database
+func (s *SQLCommon) InitReadReplicaConfig(config config.Section) config.Section {
+	replicaConfig := config.SubSection(SQLConfReadReplica)
+	replicaConfig.AddKnownKey(SQLConfDatasourceURL)
+	replicaConfig.AddKnownKey(SQLConfMaxConnections) // some providers set a default
+	replicaConfig.AddKnownKey(SQLConfMaxConnIdleTime, "1m")
+	replicaConfig.AddKnownKey(SQLConfMaxIdleConns) // defaults to the max connections
+	replicaConfig.AddKnownKey(SQLConfMaxConnLifetime)
+	return replicaConfig
 }
@@ -147,7 +147,8 @@ func (s *SQLCommon) GetConfigRecords(ctx context.Context, filter database.Filter
 		configRecord = append(configRecord, d)
 	}
 
-	return configRecord, s.queryRes(ctx, configTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, configTable, tx, fop, fi)
+	return configRecord, fr, err
 
 }
 
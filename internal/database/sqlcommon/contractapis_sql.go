@@ -176,7 +176,8 @@ func (s *SQLCommon) GetContractAPIs(ctx context.Context, ns string, filter datab
 		apis = append(apis, api)
 	}
 
-	return apis, s.queryRes(ctx, contractapisTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, contractapisTable, tx, fop, fi)
+	return apis, fr, err
 
 }
 
@@ -35,6 +35,7 @@ var (
 		"id",
 		"interface_id",
 		"event",
+		"events",
 		"namespace",
 		"name",
 		"backend_id",
@@ -72,6 +73,7 @@ func (s *SQLCommon) InsertContractListener(ctx context.Context, listener *core.C
 				listener.ID,
 				interfaceID,
 				listener.Event,
+				listener.Events,
 				listener.Namespace,
 				listener.Name,
 				listener.BackendID,
@@ -99,6 +101,7 @@ func (s *SQLCommon) contractListenerResult(ctx context.Context, row *sql.Rows) (
 		&listener.ID,
 		&listener.Interface.ID,
 		&listener.Event,
+		&listener.Events,
 		&listener.Namespace,
 		&listener.Name,
 		&listener.BackendID,
@@ -173,7 +176,8 @@ func (s *SQLCommon) GetContractListeners(ctx context.Context, filter database.Fi
 		subs = append(subs, sub)
 	}
 
-	return subs, s.queryRes(ctx, contractlistenersTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, contractlistenersTable, tx, fop, fi)
+	return subs, fr, err
 }
 
 func (s *SQLCommon) DeleteContractListenerByID(ctx context.Context, id *fftypes.UUID) (err error) {
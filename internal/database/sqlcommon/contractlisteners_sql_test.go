@@ -47,6 +47,10 @@ func TestContractListenerE2EWithDB(t *testing.T) {
 				Name: "event1",
 			},
 		},
+		Events: core.FFISerializedEvents{
+			{FFIEventDefinition: core.FFIEventDefinition{Name: "event1"}},
+			{FFIEventDefinition: core.FFIEventDefinition{Name: "event2"}},
+		},
 		Namespace: "ns",
 		Name:      "sub1",
 		BackendID: "sb-123",
@@ -204,7 +208,7 @@ func TestContractListenerDeleteFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(contractListenerColumns).AddRow(
-		fftypes.NewUUID(), nil, []byte("{}"), "ns1", "sub1", "123", "{}", "sig", "topic1", nil, fftypes.Now()),
+		fftypes.NewUUID(), nil, []byte("{}"), nil, "ns1", "sub1", "123", "{}", "sig", "topic1", nil, fftypes.Now()),
 	)
 	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
 	err := s.DeleteContractListenerByID(context.Background(), fftypes.NewUUID())
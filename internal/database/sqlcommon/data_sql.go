@@ -18,9 +18,13 @@ package sqlcommon
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
@@ -137,6 +141,7 @@ func (s *SQLCommon) UpsertData(ctx context.Context, data *core.Data, optimizatio
 	// The expectation is that the optimization will hit almost all of the time,
 	// as only recovery paths require us to go down the un-optimized route.
 	optimized := false
+	existing := false
 	if optimization == database.UpsertOptimizationNew {
 		_, opErr := s.attemptDataInsert(ctx, tx, data, true /* we want a failure here we can progress past */)
 		optimized = opErr == nil
@@ -156,7 +161,7 @@ func (s *SQLCommon) UpsertData(ctx context.Context, data *core.Data, optimizatio
 			return err
 		}
 
-		existing := dataRows.Next()
+		existing = dataRows.Next()
 		if existing {
 			var hash *fftypes.Bytes32
 			_ = dataRows.Scan(&hash)
@@ -179,6 +184,13 @@ func (s *SQLCommon) UpsertData(ctx context.Context, data *core.Data, optimizatio
 		}
 	}
 
+	// The value of data cannot change, as it is part of the hash, so only new data needs indexing
+	if !optimized || optimization == database.UpsertOptimizationNew {
+		if err = s.indexSearchRecords(ctx, tx, existing, dataSearchRecord(data)); err != nil {
+			return err
+		}
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -214,6 +226,14 @@ func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray core.DataArra
 		}
 	}
 
+	searchRecords := make([]*searchRecord, len(dataArray))
+	for i, data := range dataArray {
+		searchRecords[i] = dataSearchRecord(data)
+	}
+	if err = s.indexSearchRecords(ctx, tx, false, searchRecords...); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 
 }
@@ -306,7 +326,8 @@ func (s *SQLCommon) GetData(ctx context.Context, filter database.Filter) (messag
 		data = append(data, d)
 	}
 
-	return data, s.queryRes(ctx, dataTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, dataTable, tx, fop, fi)
+	return data, fr, err
 
 }
 
@@ -336,7 +357,8 @@ func (s *SQLCommon) GetDataRefs(ctx context.Context, filter database.Filter) (me
 		refs = append(refs, &ref)
 	}
 
-	return refs, s.queryRes(ctx, dataTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, dataTable, tx, fop, fi)
+	return refs, fr, err
 
 }
 
@@ -361,3 +383,49 @@ func (s *SQLCommon) UpdateData(ctx context.Context, id *fftypes.UUID, update dat
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
+
+// createDataValueIndexes creates expression indexes for paths inside data values that are frequently
+// used in filters, optionally restricted to the data of a single datatype
+func (s *SQLCommon) createDataValueIndexes(ctx context.Context, indexConfig config.ArraySection) error {
+	for i := 0; i < indexConfig.ArraySize(); i++ {
+		entry := indexConfig.ArrayEntry(i)
+		datatype := entry.GetString(SQLConfDataValueIndexDatatype)
+		if datatype != "" {
+			if err := core.ValidateFFNameField(ctx, datatype, SQLConfDataValueIndexDatatype); err != nil {
+				return err
+			}
+		}
+		for _, path := range entry.GetStringSlice(SQLConfDataValueIndexPaths) {
+			jsonPath, err := database.ParseJSONPath(ctx, path)
+			if err != nil {
+				return err
+			}
+			if err := s.createDataValueIndex(ctx, datatype, path, jsonPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SQLCommon) createDataValueIndex(ctx context.Context, datatype, path string, jsonPath []string) error {
+	hash := sha256.Sum256([]byte(datatype + ":" + path))
+	indexName := fmt.Sprintf("data_value_%s", hex.EncodeToString(hash[0:8]))
+	where := ""
+	if datatype != "" {
+		where = fmt.Sprintf(" WHERE datatype_name = '%s'", datatype)
+	}
+	// Text values are used for equality and string matching, and numbers for ordering comparisons
+	indexes := map[string]string{
+		indexName + "_t": s.features.JSONExtractTextSQL("value", jsonPath),
+		indexName + "_n": s.features.JSONExtractNumberSQL("value", jsonPath),
+	}
+	for _, name := range []string{indexName + "_t", indexName + "_n"} {
+		log.L(ctx).Infof("Ensuring index %s on data values for datatype='%s' path='%s'", name, datatype, path)
+		ddl := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s ((%s))%s", name, dataTable, indexes[name], where)
+		if _, err := s.db.ExecContext(ctx, ddl); err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgDataValueIndexFailed, datatype, path)
+		}
+	}
+	return nil
+}
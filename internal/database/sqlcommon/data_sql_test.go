@@ -18,15 +18,18 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -167,6 +170,135 @@ func TestDataE2EWithDB(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestDataValueFiltersE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+
+	values := []string{
+		`{"orderId":"123","amount":250,"customer":{"name":"alice"}}`,
+		`{"orderId":"456","amount":99.5,"customer":{"name":"bob"}}`,
+		`{"orderId":123,"amount":"lots"}`,
+	}
+	dataArray := make(core.DataArray, len(values))
+	for i, v := range values {
+		dataArray[i] = &core.Data{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Hash:      fftypes.NewRandB32(),
+			Created:   fftypes.Now(),
+			Value:     fftypes.JSONAnyPtr(v),
+		}
+		err := s.UpsertData(ctx, dataArray[i], database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+
+		msg := &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash: fftypes.NewRandB32(),
+			Data: core.DataRefs{{ID: dataArray[i].ID, Hash: dataArray[i].Hash}},
+		}
+		err = s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+
+	// Equality compares the text of the value, regardless of its JSON type
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	data, _, err := s.GetData(ctx, fb.And(fb.Eq("namespace", "ns1"), fb.Eq("value.orderId", "123")).Sort("created").Ascending())
+	assert.NoError(t, err)
+	assert.Len(t, data, 2)
+	assert.Equal(t, *dataArray[0].ID, *data[0].ID)
+	assert.Equal(t, *dataArray[2].ID, *data[1].ID)
+
+	// Ordering comparisons with a number only match numeric values
+	data, _, err = s.GetData(ctx, fb.And(fb.Eq("namespace", "ns1"), fb.Gte("value.amount", "100")))
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, *dataArray[0].ID, *data[0].ID)
+
+	data, _, err = s.GetData(ctx, fb.And(fb.Eq("namespace", "ns1"), fb.IStartsWith("value.customer.name", "B")))
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, *dataArray[1].ID, *data[0].ID)
+
+	// Messages match if any of their data matches
+	mfb := database.MessageQueryFactory.NewFilter(ctx)
+	msgs, _, err := s.GetMessages(ctx, mfb.And(mfb.Eq("namespace", "ns1"), mfb.Lt("value.amount", "100")))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, *dataArray[1].ID, *msgs[0].Data[0].ID)
+}
+
+func newDataValueIndexConfig(entries ...fftypes.JSONObject) config.ArraySection {
+	indexConfig := config.RootArray("ut.dataValueIndexes")
+	indexConfig.AddKnownKey(SQLConfDataValueIndexDatatype)
+	indexConfig.AddKnownKey(SQLConfDataValueIndexPaths)
+	values := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		values[i] = map[string]interface{}(entry)
+	}
+	// Viper only resolves keys inside arrays in the loaded config, not in values that are set directly
+	_ = viper.MergeConfigMap(map[string]interface{}{
+		"ut": map[string]interface{}{"datavalueindexes": values},
+	})
+	return indexConfig
+}
+
+func TestCreateDataValueIndexes(t *testing.T) {
+	s, mock := newMockProvider().init()
+	indexConfig := newDataValueIndexConfig(
+		fftypes.JSONObject{"datatype": "order", "paths": []interface{}{"orderId"}},
+		fftypes.JSONObject{"paths": []interface{}{"customer.name"}},
+	)
+	mock.ExpectExec(`CREATE INDEX IF NOT EXISTS data_value_[0-9a-f]{16}_t ON data \(\(CAST\(json_extract\(value, '\$\."orderId"'\) AS TEXT\)\)\) WHERE datatype_name = 'order'`).
+		WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec(`CREATE INDEX IF NOT EXISTS data_value_[0-9a-f]{16}_n ON data .* WHERE datatype_name = 'order'`).
+		WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec(`CREATE INDEX IF NOT EXISTS data_value_[0-9a-f]{16}_t ON data \(\(CAST\(json_extract\(value, '\$\."customer"\."name"'\) AS TEXT\)\)\)$`).
+		WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec(`CREATE INDEX IF NOT EXISTS data_value_[0-9a-f]{16}_n ON data .*`).
+		WillReturnResult(driver.ResultNoRows)
+	err := s.createDataValueIndexes(context.Background(), indexConfig)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDataValueIndexesFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	indexConfig := newDataValueIndexConfig(
+		fftypes.JSONObject{"paths": []interface{}{"orderId"}},
+	)
+	mock.ExpectExec("CREATE INDEX .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.createDataValueIndexes(context.Background(), indexConfig)
+	assert.Regexp(t, "FF10450.*orderId.*pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDataValueIndexesBadDatatype(t *testing.T) {
+	s, _ := newMockProvider().init()
+	indexConfig := newDataValueIndexConfig(
+		fftypes.JSONObject{"datatype": "bad'name", "paths": []interface{}{"orderId"}},
+	)
+	err := s.createDataValueIndexes(context.Background(), indexConfig)
+	assert.Regexp(t, "FF00140.*datatype", err)
+}
+
+func TestCreateDataValueIndexesBadPath(t *testing.T) {
+	s, _ := newMockProvider().init()
+	indexConfig := newDataValueIndexConfig(
+		fftypes.JSONObject{"paths": []interface{}{"order'Id"}},
+	)
+	err := s.createDataValueIndexes(context.Background(), indexConfig)
+	assert.Regexp(t, "FF10449", err)
+}
+
 func TestUpsertDataFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
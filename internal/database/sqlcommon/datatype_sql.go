@@ -191,7 +191,8 @@ func (s *SQLCommon) GetDatatypes(ctx context.Context, filter database.Filter) (m
 		datatypes = append(datatypes, datatype)
 	}
 
-	return datatypes, s.queryRes(ctx, datatypesTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, datatypesTable, tx, fop, fi)
+	return datatypes, fr, err
 
 }
 
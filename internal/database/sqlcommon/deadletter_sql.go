@@ -0,0 +1,152 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+var (
+	deadLetterColumns = []string{
+		"id",
+		"namespace",
+		"subscription_id",
+		"subscription_name",
+		"transport",
+		"event_id",
+		"attempts",
+		"error",
+		"last_response",
+		"created",
+	}
+	deadLetterFilterFieldMap = map[string]string{
+		"subscription": "subscription_id",
+		"name":         "subscription_name",
+		"event":        "event_id",
+	}
+)
+
+const deadlettersTable = "deadletters"
+
+func (s *SQLCommon) InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	deadLetter.Created = fftypes.Now()
+	if _, err = s.insertTx(ctx, deadlettersTable, tx,
+		sq.Insert(deadlettersTable).
+			Columns(deadLetterColumns...).
+			Values(
+				deadLetter.ID,
+				deadLetter.Namespace,
+				deadLetter.Subscription.ID,
+				deadLetter.Subscription.Name,
+				deadLetter.Transport,
+				deadLetter.Event,
+				deadLetter.Attempts,
+				deadLetter.Error,
+				deadLetter.LastResponse,
+				deadLetter.Created,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionDeadLetters, core.ChangeEventTypeCreated, deadLetter.Namespace, deadLetter.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) deadLetterResult(ctx context.Context, row *sql.Rows) (*core.DeadLetter, error) {
+	var deadLetter core.DeadLetter
+	err := row.Scan(
+		&deadLetter.ID,
+		&deadLetter.Namespace,
+		&deadLetter.Subscription.ID,
+		&deadLetter.Subscription.Name,
+		&deadLetter.Transport,
+		&deadLetter.Event,
+		&deadLetter.Attempts,
+		&deadLetter.Error,
+		&deadLetter.LastResponse,
+		&deadLetter.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, deadlettersTable)
+	}
+	deadLetter.Subscription.Namespace = deadLetter.Namespace
+	return &deadLetter, nil
+}
+
+func (s *SQLCommon) GetDeadLetterByID(ctx context.Context, id *fftypes.UUID) (*core.DeadLetter, error) {
+	rows, _, err := s.query(ctx, deadlettersTable,
+		sq.Select(deadLetterColumns...).
+			From(deadlettersTable).
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Dead letter '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.deadLetterResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetDeadLetters(ctx context.Context, filter database.Filter) ([]*core.DeadLetter, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(deadLetterColumns...).From(deadlettersTable),
+		filter, deadLetterFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, deadlettersTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	deadLetters := []*core.DeadLetter{}
+	for rows.Next() {
+		deadLetter, err := s.deadLetterResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	fr, err := s.queryRes(ctx, deadlettersTable, tx, fop, fi)
+	return deadLetters, fr, err
+}
@@ -202,7 +202,8 @@ func (s *SQLCommon) GetEvents(ctx context.Context, filter database.Filter) (mess
 		events = append(events, event)
 	}
 
-	return events, s.queryRes(ctx, eventsTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, eventsTable, tx, fop, fi)
+	return events, fr, err
 
 }
 
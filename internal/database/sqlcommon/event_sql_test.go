@@ -284,3 +284,83 @@ func TestEventUpdateFail(t *testing.T) {
 	err := s.UpdateEvent(context.Background(), fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF10117", err)
 }
+
+func TestEventCursorPaginationE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	ids := make([]*fftypes.UUID, 5)
+	for i := range ids {
+		ids[i] = fftypes.NewUUID()
+		err := s.InsertEvent(ctx, &core.Event{
+			ID:        ids[i],
+			Namespace: "ns1",
+			Type:      core.EventTypeMessageConfirmed,
+			Created:   fftypes.Now(),
+		})
+		assert.NoError(t, err)
+	}
+	eventIDs := func(events []*core.Event) []*fftypes.UUID {
+		result := make([]*fftypes.UUID, len(events))
+		for i, e := range events {
+			result[i] = e.ID
+		}
+		return result
+	}
+	nsFilter := func() database.Filter {
+		fb := database.EventQueryFactory.NewFilter(ctx)
+		return fb.Eq("namespace", "ns1")
+	}
+
+	// Cursors are only returned when paging with them
+	_, res, err := s.GetEvents(ctx, nsFilter().Limit(2).Count(true))
+	assert.NoError(t, err)
+	assert.Empty(t, res.Prev)
+	assert.Empty(t, res.Next)
+
+	// First page, newest first
+	events, res, err := s.GetEvents(ctx, nsFilter().Limit(2).Count(true).After(""))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[4], ids[3]}, eventIDs(events))
+	assert.Equal(t, int64(5), *res.TotalCount)
+	assert.Empty(t, res.Prev)
+	assert.Equal(t, database.EncodeCursor(events[1].Sequence), res.Next)
+	newest := events[0].Sequence
+
+	// Page forwards
+	events, res, err = s.GetEvents(ctx, nsFilter().Limit(2).After(res.Next))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[2], ids[1]}, eventIDs(events))
+	assert.NotEmpty(t, res.Prev)
+	assert.NotEmpty(t, res.Next)
+
+	events, res, err = s.GetEvents(ctx, nsFilter().Limit(2).After(res.Next))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[0]}, eventIDs(events))
+	assert.NotEmpty(t, res.Prev)
+	assert.Empty(t, res.Next)
+
+	// Page backwards, getting the page closest to the cursor
+	events, res, err = s.GetEvents(ctx, nsFilter().Limit(2).Before(res.Prev))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[2], ids[1]}, eventIDs(events))
+
+	events, res, err = s.GetEvents(ctx, nsFilter().Limit(2).Before(res.Prev))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[4], ids[3]}, eventIDs(events))
+	assert.Empty(t, res.Prev)
+	assert.NotEmpty(t, res.Next)
+
+	// Ascending order
+	events, _, err = s.GetEvents(ctx, nsFilter().Limit(2).Ascending().After(database.EncodeCursor(newest)))
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, res, err = s.GetEvents(ctx, nsFilter().Limit(3).Ascending().Before(database.EncodeCursor(newest)))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[1], ids[2], ids[3]}, eventIDs(events))
+	assert.NotEmpty(t, res.Prev)
+	assert.NotEmpty(t, res.Next)
+}
@@ -0,0 +1,183 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFFIErrorsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new error entry
+	interfaceID := fftypes.NewUUID()
+	errorID := fftypes.NewUUID()
+	errorDef := &core.FFIError{
+		ID:        errorID,
+		Interface: interfaceID,
+		Namespace: "ns",
+		Pathname:  "InsufficientBalance",
+		FFIErrorDefinition: core.FFIErrorDefinition{
+			Name:        "InsufficientBalance",
+			Description: "Not enough funds",
+			Params: core.FFIParams{
+				{
+					Name:   "available",
+					Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`),
+				},
+			},
+		},
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionFFIErrors, core.ChangeEventTypeCreated, "ns", errorID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionFFIErrors, core.ChangeEventTypeUpdated, "ns", errorID).Return()
+
+	err := s.UpsertFFIError(ctx, errorDef)
+	assert.NoError(t, err)
+
+	// Query back the error (by query filter)
+	fb := database.FFIErrorQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("interface", interfaceID),
+		fb.Eq("name", errorDef.Name),
+	)
+	errors, res, err := s.GetFFIErrors(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(errors))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	errorJson, _ := json.Marshal(&errorDef)
+	errorReadJson, _ := json.Marshal(errors[0])
+	assert.Equal(t, string(errorJson), string(errorReadJson))
+
+	// Update error
+	errorDef.Params = core.FFIParams{}
+	err = s.UpsertFFIError(ctx, errorDef)
+	assert.NoError(t, err)
+
+	// Query back the error
+	errors, _, err = s.GetFFIErrors(ctx, fb.And(fb.Eq("id", errorID)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(errors))
+	errorJson, _ = json.Marshal(&errorDef)
+	errorReadJson, _ = json.Marshal(errors[0])
+	assert.Equal(t, string(errorJson), string(errorReadJson))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestFFIErrorDBFailBeginTransaction(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertFFIError(context.Background(), &core.FFIError{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFFIErrorDBFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertFFIError(context.Background(), &core.FFIError{})
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFFIErrorDBFailInsert(t *testing.T) {
+	rows := sqlmock.NewRows([]string{"id"})
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	errorDef := &core.FFIError{
+		ID: fftypes.NewUUID(),
+	}
+	err := s.UpsertFFIError(context.Background(), errorDef)
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFFIErrorDBFailUpdate(t *testing.T) {
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2")
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	mock.ExpectQuery("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	errorDef := &core.FFIError{
+		ID: fftypes.NewUUID(),
+	}
+	err := s.UpsertFFIError(context.Background(), errorDef)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetFFIErrors(t *testing.T) {
+	fb := database.FFIErrorQueryFactory.NewFilter(context.Background())
+	filter := fb.And(
+		fb.Eq("name", "InsufficientBalance"),
+	)
+	s, mock := newMockProvider().init()
+	rows := sqlmock.NewRows(ffiErrorsColumns).
+		AddRow(fftypes.NewUUID().String(), fftypes.NewUUID().String(), "ns1", "InsufficientBalance", "InsufficientBalance", "", []byte(`[]`))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	errors, _, err := s.GetFFIErrors(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, "InsufficientBalance", errors[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFFIErrorsFilterSelectFail(t *testing.T) {
+	fb := database.FFIErrorQueryFactory.NewFilter(context.Background())
+	s, _ := newMockProvider().init()
+	_, _, err := s.GetFFIErrors(context.Background(), fb.And(fb.Eq("id", map[bool]bool{true: false})))
+	assert.Error(t, err)
+}
+
+func TestGetFFIErrorsQueryFail(t *testing.T) {
+	fb := database.FFIErrorQueryFactory.NewFilter(context.Background())
+	filter := fb.And(
+		fb.Eq("id", fftypes.NewUUID()),
+	)
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.GetFFIErrors(context.Background(), filter)
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFFIErrorsQueryResultFail(t *testing.T) {
+	fb := database.FFIErrorQueryFactory.NewFilter(context.Background())
+	filter := fb.And(
+		fb.Eq("id", fftypes.NewUUID()),
+	)
+	s, mock := newMockProvider().init()
+	rows := sqlmock.NewRows([]string{"id"}).AddRow("only one")
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	_, _, err := s.GetFFIErrors(context.Background(), filter)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -163,7 +163,8 @@ func (s *SQLCommon) GetFFIEvents(ctx context.Context, filter database.Filter) (e
 		events = append(events, ci)
 	}
 
-	return events, s.queryRes(ctx, ffieventsTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, ffieventsTable, tx, fop, fi)
+	return events, fr, err
 
 }
 
@@ -167,7 +167,8 @@ func (s *SQLCommon) GetFFIMethods(ctx context.Context, filter database.Filter) (
 		methods = append(methods, ci)
 	}
 
-	return methods, s.queryRes(ctx, ffimethodsTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, ffimethodsTable, tx, fop, fi)
+	return methods, fr, err
 
 }
 
@@ -97,6 +97,10 @@ func (s *SQLCommon) UpsertFFI(ctx context.Context, ffi *core.FFI) (err error) {
 		}
 	}
 
+	if err = s.indexSearchRecords(ctx, tx, existing, ffiSearchRecord(ffi)); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -162,7 +166,8 @@ func (s *SQLCommon) GetFFIs(ctx context.Context, ns string, filter database.Filt
 		ffis = append(ffis, cd)
 	}
 
-	return ffis, s.queryRes(ctx, ffiTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, ffiTable, tx, fop, fi)
+	return ffis, fr, err
 
 }
 
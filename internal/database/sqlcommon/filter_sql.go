@@ -19,6 +19,7 @@ package sqlcommon
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	sq "github.com/Masterminds/squirrel"
@@ -45,7 +46,18 @@ func (s *SQLCommon) filterSelect(ctx context.Context, tableName string, sel sq.S
 		}
 	}
 	fop, err := s.filterSelectFinalized(ctx, tableName, fi, typeMap, preconditions...)
+	var cursors *cursorFilterOp
+	if err == nil && fi.Cursors {
+		cursors = s.newCursorFilterOp(sel, fop, s.mapField(tableName, "sequence", typeMap), fi)
+	}
 	sel = sel.Where(fop)
+	if cursors != nil {
+		// The cursor conditions are not part of fop, so the total count is unaffected
+		if len(cursors.conditions) > 0 {
+			sel = sel.Where(cursors.conditions)
+		}
+		fop = cursors
+	}
 	sort := make([]string, len(fi.Sort))
 	var sortString string
 	for i, sf := range fi.Sort {
@@ -74,6 +86,74 @@ func (s *SQLCommon) filterSelect(ctx context.Context, tableName string, sel sq.S
 	return sel, fop, fi, err
 }
 
+// afterSequence matches the items that follow the sequence, in the order of the query
+func (s *SQLCommon) afterSequence(seqField string, descending bool, sequence int64) sq.Sqlizer {
+	if descending {
+		return sq.Lt{seqField: sequence}
+	}
+	return sq.Gt{seqField: sequence}
+}
+
+// beforeSequence matches the items that precede the sequence, in the order of the query
+func (s *SQLCommon) beforeSequence(seqField string, descending bool, sequence int64) sq.Sqlizer {
+	if descending {
+		return sq.Gt{seqField: sequence}
+	}
+	return sq.Lt{seqField: sequence}
+}
+
+// cursorFilterOp is the filter of a select that pages with after/before cursors. It keeps the select the
+// filter applies to and its sequence field, so the cursors of the adjacent pages are queried over the same
+// (possibly aliased or joined) rows as the page itself
+type cursorFilterOp struct {
+	sq.Sqlizer
+	source     sq.SelectBuilder
+	seqField   string
+	conditions sq.And
+}
+
+// newCursorFilterOp builds range conditions on the sequence for the after/before cursors of a filter.
+// The source is the select the filter applies to, and is used to find the page immediately
+// preceding a "before" cursor (rather than the first page of everything before it)
+func (s *SQLCommon) newCursorFilterOp(source sq.SelectBuilder, fop sq.Sqlizer, seqField string, fi *database.FilterInfo) *cursorFilterOp {
+	cf := &cursorFilterOp{
+		Sqlizer:    fop,
+		source:     source,
+		seqField:   seqField,
+		conditions: sq.And{},
+	}
+	descending := fi.Sort[0].Descending
+	if fi.After != nil {
+		cf.conditions = append(cf.conditions, s.afterSequence(seqField, descending, *fi.After))
+	}
+	if fi.Before != nil {
+		cf.conditions = append(cf.conditions, s.beforeSequence(seqField, descending, *fi.Before))
+		if fi.Limit > 0 {
+			nearestOrder, boundAgg, boundOp := seqField, "MAX", "<="
+			if !descending {
+				nearestOrder, boundAgg, boundOp = seqField+" DESC", "MIN", ">="
+			}
+			nearest := cf.sequenceQuery(append(sq.And{}, cf.conditions...)).
+				OrderBy(nearestOrder).
+				Limit(fi.Limit)
+			bound := sq.Select(fmt.Sprintf("%s(ff_cursor_page.ff_cursor_seq)", boundAgg)).FromSelect(nearest, "ff_cursor_page")
+			cf.conditions = append(cf.conditions, sq.Expr(fmt.Sprintf("%s %s (?)", seqField, boundOp), bound))
+		}
+	}
+	return cf
+}
+
+// sequenceQuery selects the sequence of the rows of the source that match the filter, and the conditions
+func (cf *cursorFilterOp) sequenceQuery(conditions ...sq.Sqlizer) sq.SelectBuilder {
+	query := cf.source.
+		Column(fmt.Sprintf("%s AS ff_cursor_seq", cf.seqField)).
+		Where(cf.Sqlizer)
+	for _, condition := range conditions {
+		query = query.Where(condition)
+	}
+	return query
+}
+
 func (s *SQLCommon) filterSelectFinalized(ctx context.Context, tableName string, fi *database.FilterInfo, tm map[string]string, preconditions ...sq.Sqlizer) (sq.Sqlizer, error) {
 	fop, err := s.filterOp(ctx, tableName, fi, tm)
 	if err != nil {
@@ -156,56 +236,108 @@ func (s *SQLCommon) newNotILike(field, value string) sq.Sqlizer {
 	return sq.NotLike{fmt.Sprintf("lower(%s)", field): strings.ToLower(value)}
 }
 
+// subqueryField is a filter field that is not a column of the table being queried. Instead it
+// is matched by a subquery, that selects the keys of the rows where the filter matches the column
+type subqueryField struct {
+	key    string
+	column string
+	from   sq.SelectBuilder
+}
+
+var subqueryFields = map[string]*subqueryField{
+	msgDataValueField: {
+		key:    "id",
+		column: "d.value",
+		from:   sq.Select("md.message_id").From("messages_data AS md").Join("data AS d ON d.id = md.data_id"),
+	},
+}
+
 func (s *SQLCommon) filterOp(ctx context.Context, tableName string, op *database.FilterInfo, tm map[string]string) (sq.Sqlizer, error) {
 	switch op.Op {
 	case database.FilterOpOr:
 		return s.filterOr(ctx, tableName, op, tm)
 	case database.FilterOpAnd:
 		return s.filterAnd(ctx, tableName, op, tm)
+	}
+	if sub, ok := subqueryFields[s.mapField("", op.Field, tm)]; ok {
+		subFilter, err := s.filterOpField(ctx, sub.column, op)
+		if err != nil {
+			return nil, err
+		}
+		key := sub.key
+		if tableName != "" {
+			key = fmt.Sprintf("%s.%s", tableName, key)
+		}
+		return sq.Expr(fmt.Sprintf("%s IN (?)", key), sub.from.Where(subFilter)), nil
+	}
+	return s.filterOpField(ctx, s.mapField(tableName, op.Field, tm), op)
+}
+
+// jsonPathField returns the expression to extract the value at the path in a JSON field, and the value to compare
+// it with. Ordering comparisons with numbers are numeric, and all other comparisons are on the text of the value.
+func (s *SQLCommon) jsonPathField(column string, op *database.FilterInfo) (string, interface{}) {
+	switch op.Op {
+	case database.FilterOpGt, database.FilterOpGte, database.FilterOpLt, database.FilterOpLte:
+		v, _ := op.Value.Value()
+		if vs, ok := v.(string); ok {
+			if number, err := strconv.ParseFloat(vs, 64); err == nil {
+				return s.features.JSONExtractNumberSQL(column, op.JSONPath), number
+			}
+		}
+	}
+	return s.features.JSONExtractTextSQL(column, op.JSONPath), op.Value
+}
+
+func (s *SQLCommon) filterOpField(ctx context.Context, field string, op *database.FilterInfo) (sq.Sqlizer, error) {
+	var value interface{} = op.Value
+	if len(op.JSONPath) > 0 {
+		field, value = s.jsonPathField(field, op)
+	}
+	switch op.Op {
 	case database.FilterOpEq:
-		return sq.Eq{s.mapField(tableName, op.Field, tm): op.Value}, nil
+		return sq.Eq{field: value}, nil
 	case database.FilterOpIEq:
-		return s.newILike(s.mapField(tableName, op.Field, tm), s.escapeLike(op.Value)), nil
+		return s.newILike(field, s.escapeLike(op.Value)), nil
 	case database.FilterOpIn:
-		return sq.Eq{s.mapField(tableName, op.Field, tm): op.Values}, nil
+		return sq.Eq{field: op.Values}, nil
 	case database.FilterOpNeq:
-		return sq.NotEq{s.mapField(tableName, op.Field, tm): op.Value}, nil
+		return sq.NotEq{field: value}, nil
 	case database.FilterOpNIeq:
-		return s.newNotILike(s.mapField(tableName, op.Field, tm), s.escapeLike(op.Value)), nil
+		return s.newNotILike(field, s.escapeLike(op.Value)), nil
 	case database.FilterOpNotIn:
-		return sq.NotEq{s.mapField(tableName, op.Field, tm): op.Values}, nil
+		return sq.NotEq{field: op.Values}, nil
 	case database.FilterOpCont:
-		return sq.Like{s.mapField(tableName, op.Field, tm): fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))}, nil
+		return sq.Like{field: fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))}, nil
 	case database.FilterOpNotCont:
-		return sq.NotLike{s.mapField(tableName, op.Field, tm): fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))}, nil
+		return sq.NotLike{field: fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))}, nil
 	case database.FilterOpICont:
-		return s.newILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))), nil
+		return s.newILike(field, fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))), nil
 	case database.FilterOpNotICont:
-		return s.newNotILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%s%%", s.escapeLike(op.Value))), nil
+		return s.newNotILike(field, fmt.Sprintf("%s%%", s.escapeLike(op.Value))), nil
 	case database.FilterOpStartsWith:
-		return sq.Like{s.mapField(tableName, op.Field, tm): fmt.Sprintf("%s%%", s.escapeLike(op.Value))}, nil
+		return sq.Like{field: fmt.Sprintf("%s%%", s.escapeLike(op.Value))}, nil
 	case database.FilterOpNotStartsWith:
-		return sq.NotLike{s.mapField(tableName, op.Field, tm): fmt.Sprintf("%s%%", s.escapeLike(op.Value))}, nil
+		return sq.NotLike{field: fmt.Sprintf("%s%%", s.escapeLike(op.Value))}, nil
 	case database.FilterOpIStartsWith:
-		return s.newILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%s%%", s.escapeLike(op.Value))), nil
+		return s.newILike(field, fmt.Sprintf("%s%%", s.escapeLike(op.Value))), nil
 	case database.FilterOpNotIStartsWith:
-		return s.newNotILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%s%%", s.escapeLike(op.Value))), nil
+		return s.newNotILike(field, fmt.Sprintf("%s%%", s.escapeLike(op.Value))), nil
 	case database.FilterOpEndsWith:
-		return sq.Like{s.mapField(tableName, op.Field, tm): fmt.Sprintf("%%%s", s.escapeLike(op.Value))}, nil
+		return sq.Like{field: fmt.Sprintf("%%%s", s.escapeLike(op.Value))}, nil
 	case database.FilterOpNotEndsWith:
-		return sq.NotLike{s.mapField(tableName, op.Field, tm): fmt.Sprintf("%%%s", s.escapeLike(op.Value))}, nil
+		return sq.NotLike{field: fmt.Sprintf("%%%s", s.escapeLike(op.Value))}, nil
 	case database.FilterOpIEndsWith:
-		return s.newILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%%%s", s.escapeLike(op.Value))), nil
+		return s.newILike(field, fmt.Sprintf("%%%s", s.escapeLike(op.Value))), nil
 	case database.FilterOpNotIEndsWith:
-		return s.newNotILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%%%s", s.escapeLike(op.Value))), nil
+		return s.newNotILike(field, fmt.Sprintf("%%%s", s.escapeLike(op.Value))), nil
 	case database.FilterOpGt:
-		return sq.Gt{s.mapField(tableName, op.Field, tm): op.Value}, nil
+		return sq.Gt{field: value}, nil
 	case database.FilterOpGte:
-		return sq.GtOrEq{s.mapField(tableName, op.Field, tm): op.Value}, nil
+		return sq.GtOrEq{field: value}, nil
 	case database.FilterOpLt:
-		return sq.Lt{s.mapField(tableName, op.Field, tm): op.Value}, nil
+		return sq.Lt{field: value}, nil
 	case database.FilterOpLte:
-		return sq.LtOrEq{s.mapField(tableName, op.Field, tm): op.Value}, nil
+		return sq.LtOrEq{field: value}, nil
 	default:
 		return nil, i18n.NewError(ctx, coremsgs.MsgUnsupportedSQLOpInFilter, op.Op)
 	}
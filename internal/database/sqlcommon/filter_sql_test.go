@@ -225,3 +225,98 @@ func TestNotILIKE(t *testing.T) {
 	sqlString, _, _ = q.ToSql()
 	assert.Regexp(t, "lower\\(test\\)", sqlString)
 }
+
+func TestSQLQueryFactoryAfterCursor(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("namespace", "ns1")).
+		Limit(25).
+		After(database.EncodeCursor(100))
+
+	sel := squirrel.Select("*").From("events")
+	sel, fop, _, err := s.filterSelect(context.Background(), "", sel, f, nil, []interface{}{"created"})
+	assert.NoError(t, err)
+
+	sqlFilter, args, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM events WHERE (namespace = ?) AND (seq < ?) ORDER BY seq DESC LIMIT 25", sqlFilter)
+	assert.Equal(t, []interface{}{"ns1", int64(100)}, args)
+
+	// The cursor does not restrict the total count
+	sqlFilter, _, err = fop.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(namespace = ?)", sqlFilter)
+}
+
+func TestSQLQueryFactoryBeforeCursor(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("namespace", "ns1")).
+		Limit(25).
+		Ascending().
+		Before(database.EncodeCursor(100))
+
+	sel := squirrel.Select("id").From("events AS e")
+	sel, _, _, err := s.filterSelect(context.Background(), "e", sel, f, nil, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, args, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM events AS e WHERE (e.namespace = ?) AND (e.seq < ? AND e.seq >= "+
+		"(SELECT MIN(ff_cursor_page.ff_cursor_seq) FROM "+
+		"(SELECT id, e.seq AS ff_cursor_seq FROM events AS e WHERE (e.namespace = ?) AND (e.seq < ?) ORDER BY e.seq DESC LIMIT 25) AS ff_cursor_page)"+
+		") ORDER BY e.seq LIMIT 25", sqlFilter)
+	assert.Equal(t, []interface{}{"ns1", int64(100), "ns1", int64(100)}, args)
+}
+
+func TestSQLQueryFactoryJSONPath(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.DataQueryFactory.NewFilter(context.Background())
+	f := fb.And(
+		fb.Eq("value.orderId", "123"),
+		fb.Gte("value.amount", "100"),
+		fb.Lt("value.customer.name", "m"),
+		fb.StartsWith("value.customer.name", "a"),
+	)
+
+	sel := squirrel.Select("*").From("data")
+	sel, _, _, err := s.filterSelect(context.Background(), "", sel, f, nil, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, args, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM data WHERE (CAST(json_extract(value, '$."orderId"') AS TEXT) = ? AND `+
+		`(CASE WHEN json_type(value, '$."amount"') IN ('integer', 'real') THEN json_extract(value, '$."amount"') END) >= ? AND `+
+		`CAST(json_extract(value, '$."customer"."name"') AS TEXT) < ? AND `+
+		`CAST(json_extract(value, '$."customer"."name"') AS TEXT) LIKE ?) ORDER BY seq DESC`, sqlFilter)
+	assert.Equal(t, []interface{}{"123", float64(100), "m", "a%"}, args)
+}
+
+func TestSQLQueryFactoryJSONPathSubquery(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	f := fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("value.orderId", "123"),
+	)
+
+	sel := squirrel.Select("*").From("messages AS m")
+	sel, _, _, err := s.filterSelect(context.Background(), "m", sel, f, msgFilterFieldMap, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, args, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM messages AS m WHERE (m.namespace = ? AND m.id IN (SELECT md.message_id FROM messages_data AS md `+
+		`JOIN data AS d ON d.id = md.data_id WHERE CAST(json_extract(d.value, '$."orderId"') AS TEXT) = ?)) ORDER BY m.seq DESC`, sqlFilter)
+	assert.Equal(t, []interface{}{"ns1", "123"}, args)
+}
+
+func TestSQLQueryFactoryJSONPathSubqueryBadOp(t *testing.T) {
+	s, _ := newMockProvider().init()
+	_, err := s.filterSelectFinalized(context.Background(), "m", &database.FilterInfo{
+		Op:       database.FilterOp("wrong"),
+		Field:    "value",
+		JSONPath: []string{"orderId"},
+	}, msgFilterFieldMap)
+	assert.Regexp(t, "FF10150.*wrong", err)
+}
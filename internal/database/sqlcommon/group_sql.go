@@ -301,7 +301,8 @@ func (s *SQLCommon) GetGroups(ctx context.Context, filter database.Filter) (grou
 		}
 	}
 
-	return groups, s.queryRes(ctx, groupsTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, groupsTable, tx, fop, fi)
+	return groups, fr, err
 }
 
 func (s *SQLCommon) UpdateGroup(ctx context.Context, hash *fftypes.Bytes32, update database.Update) (err error) {
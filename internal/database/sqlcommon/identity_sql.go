@@ -114,6 +114,7 @@ func (s *SQLCommon) UpsertIdentity(ctx context.Context, identity *core.Identity,
 	defer s.rollbackTx(ctx, tx, autoCommit)
 
 	optimized := false
+	existing := optimization == database.UpsertOptimizationExisting
 	if optimization == database.UpsertOptimizationNew {
 		opErr := s.attemptIdentityInsert(ctx, tx, identity, true /* we want a failure here we can progress past */)
 		optimized = opErr == nil
@@ -132,7 +133,7 @@ func (s *SQLCommon) UpsertIdentity(ctx context.Context, identity *core.Identity,
 		if err != nil {
 			return err
 		}
-		existing := msgRows.Next()
+		existing = msgRows.Next()
 		msgRows.Close()
 
 		if existing {
@@ -147,6 +148,10 @@ func (s *SQLCommon) UpsertIdentity(ctx context.Context, identity *core.Identity,
 		}
 	}
 
+	if err = s.indexSearchRecords(ctx, tx, existing, identitySearchRecord(identity)); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -227,7 +232,8 @@ func (s *SQLCommon) GetIdentities(ctx context.Context, filter database.Filter) (
 		identities = append(identities, d)
 	}
 
-	return identities, s.queryRes(ctx, identitiesTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, identitiesTable, tx, fop, fi)
+	return identities, fr, err
 
 }
 
@@ -55,12 +55,16 @@ var (
 		"txtype": "tx_type",
 		"batch":  "batch_id",
 		"group":  "group_hash",
+		"value":  msgDataValueField,
 	}
 )
 
 const messagesTable = "messages"
 const messagesDataJoinTable = "messages_data"
 
+// msgDataValueField filters messages by the values of their data, rather than a column of the message
+const msgDataValueField = "data.value"
+
 func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *txWrapper, message *core.Message) (int64, error) {
 	return s.updateTx(ctx, messagesTable, tx,
 		sq.Update(messagesTable).
@@ -185,6 +189,9 @@ func (s *SQLCommon) UpsertMessage(ctx context.Context, message *core.Message, op
 		if err = s.updateMessageDataRefs(ctx, tx, message, recreateDatarefs); err != nil {
 			return err
 		}
+		if err = s.indexSearchRecords(ctx, tx, recreateDatarefs, messageSearchRecord(message)); err != nil {
+			return err
+		}
 	}
 
 	for _, hook := range hooks {
@@ -253,6 +260,14 @@ func (s *SQLCommon) InsertMessages(ctx context.Context, messages []*core.Message
 		}
 	}
 
+	searchRecords := make([]*searchRecord, len(messages))
+	for i, message := range messages {
+		searchRecords[i] = messageSearchRecord(message)
+	}
+	if err = s.indexSearchRecords(ctx, tx, false, searchRecords...); err != nil {
+		return err
+	}
+
 	for _, hook := range hooks {
 		s.postCommitEvent(tx, hook)
 	}
@@ -286,6 +301,10 @@ func (s *SQLCommon) ReplaceMessage(ctx context.Context, message *core.Message) (
 	// Note there is no call to updateMessageDataRefs as the data refs are not allowed to change,
 	// and are correlated by UUID (not sequence)
 
+	if err = s.indexSearchRecords(ctx, tx, true, messageSearchRecord(message)); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -480,7 +499,8 @@ func (s *SQLCommon) getMessagesQuery(ctx context.Context, query sq.SelectBuilder
 			return nil, nil, err
 		}
 	}
-	return msgs, s.queryRes(ctx, messagesTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, messagesTable, tx, fop, fi)
+	return msgs, fr, err
 }
 
 func (s *SQLCommon) GetMessageIDs(ctx context.Context, filter database.Filter) (ids []*core.IDAndSequence, err error) {
@@ -562,13 +582,13 @@ func (s *SQLCommon) GetMessagesForData(ctx context.Context, dataID *fftypes.UUID
 		cols[i] = fmt.Sprintf("m.%s", col)
 	}
 	cols[len(msgColumns)] = "m.seq"
-	query, fop, fi, err := s.filterSelect(ctx, "m", sq.Select(cols...).From("messages_data AS md"), filter, msgFilterFieldMap, []interface{}{"sequence"},
+	query, fop, fi, err := s.filterSelect(ctx, "m", sq.Select(cols...).From("messages_data AS md").LeftJoin("messages AS m ON m.id = md.message_id"),
+		filter, msgFilterFieldMap, []interface{}{"sequence"},
 		sq.Eq{"md.data_id": dataID})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	query = query.LeftJoin("messages AS m ON m.id = md.message_id")
 	return s.getMessagesQuery(ctx, query, fop, fi, false)
 }
 
@@ -229,6 +229,65 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestGetMessagesForDataCursorsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	dataID := fftypes.NewUUID()
+	ids := make([]*fftypes.UUID, 5)
+	for i := range ids {
+		ids[i] = fftypes.NewUUID()
+		msg := &core.Message{
+			Header: core.MessageHeader{
+				ID:        ids[i],
+				Type:      core.MessageTypeBroadcast,
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash: fftypes.NewRandB32(),
+			Data: core.DataRefs{{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}},
+		}
+		// Every other message refers to the data, so the pages are only made of the joined rows
+		if i%2 == 0 {
+			msg.Data = append(msg.Data, &core.DataRef{ID: dataID, Hash: fftypes.NewRandB32()})
+		}
+		err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+	msgIDs := func(msgs []*core.Message) []*fftypes.UUID {
+		result := make([]*fftypes.UUID, len(msgs))
+		for i, m := range msgs {
+			result[i] = m.Header.ID
+		}
+		return result
+	}
+	nsFilter := func() database.Filter {
+		fb := database.MessageQueryFactory.NewFilter(ctx)
+		return fb.Eq("namespace", "ns1")
+	}
+
+	msgs, res, err := s.GetMessagesForData(ctx, dataID, nsFilter().Limit(2).After(""))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[4], ids[2]}, msgIDs(msgs))
+	assert.Empty(t, res.Prev)
+	assert.Equal(t, database.EncodeCursor(msgs[1].Sequence), res.Next)
+
+	msgs, res, err = s.GetMessagesForData(ctx, dataID, nsFilter().Limit(2).After(res.Next))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[0]}, msgIDs(msgs))
+	assert.NotEmpty(t, res.Prev)
+	assert.Empty(t, res.Next)
+
+	msgs, res, err = s.GetMessagesForData(ctx, dataID, nsFilter().Limit(2).Before(res.Prev))
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{ids[4], ids[2]}, msgIDs(msgs))
+	assert.Empty(t, res.Prev)
+	assert.NotEmpty(t, res.Next)
+}
+
 func TestUpsertMessageFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -0,0 +1,151 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/database"
+
+	// Import migrate file source
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// SchemaVersion is the version of the schema this build of FireFly requires, which is the number
+// of the newest migration in each of the db/migrations directories
+const SchemaVersion uint = 97
+
+// InitMigrator connects to the database to manage its migrations, without the checks and setup of Init
+// that require the schema to be up to date
+func (s *SQLCommon) InitMigrator(ctx context.Context, provider Provider, config config.Section) (err error) {
+	s.provider = provider
+	if config.GetString(SQLConfDatasourceURL) == "" {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "url", fmt.Sprintf("database.%s", provider.Name()))
+	}
+	if s.db, err = provider.Open(config.GetString(SQLConfDatasourceURL)); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgDBInitFailed)
+	}
+	s.migrationsDirectory = config.GetString(SQLConfMigrationsDirectory)
+	return nil
+}
+
+func (s *SQLCommon) MigrationStatus(ctx context.Context) (*database.MigrationStatus, error) {
+	version, dirty, err := s.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(s.migrationsDirectory)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBMigrationFailed)
+	}
+	status := &database.MigrationStatus{
+		Version:    version,
+		Dirty:      dirty,
+		Required:   SchemaVersion,
+		Migrations: []*database.Migration{},
+	}
+	// Files are listed in name order, and the versions are zero padded, so they are in version order
+	for _, file := range files {
+		m, err := source.Parse(file.Name())
+		if err != nil || m.Direction != source.Up {
+			continue
+		}
+		status.Migrations = append(status.Migrations, &database.Migration{
+			Version: m.Version,
+			Name:    m.Identifier,
+			Applied: m.Version < version || (m.Version == version && !dirty),
+		})
+	}
+	return status, nil
+}
+
+func (s *SQLCommon) MigrateUp(ctx context.Context) error {
+	return s.runMigration(ctx, func(m *migrate.Migrate) error {
+		return m.Up()
+	})
+}
+
+func (s *SQLCommon) MigrateDown(ctx context.Context, steps int) error {
+	return s.runMigration(ctx, func(m *migrate.Migrate) error {
+		return m.Steps(-steps)
+	})
+}
+
+func (s *SQLCommon) MigrateTo(ctx context.Context, version uint) error {
+	return s.runMigration(ctx, func(m *migrate.Migrate) error {
+		if version == 0 {
+			// There is no migration zero to go to, so revert them all
+			return m.Down()
+		}
+		return m.Migrate(version)
+	})
+}
+
+func (s *SQLCommon) CheckSchema(ctx context.Context) error {
+	version, dirty, err := s.schemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return i18n.NewError(ctx, coremsgs.MsgDBSchemaDirty, s.provider.Name(), version)
+	}
+	if version != SchemaVersion {
+		return i18n.NewError(ctx, coremsgs.MsgDBSchemaVersionMismatch, s.provider.Name(), version, SchemaVersion)
+	}
+	return nil
+}
+
+func (s *SQLCommon) schemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	driver, err := s.provider.GetMigrationDriver(s.db)
+	if err != nil {
+		return 0, false, i18n.WrapError(ctx, err, coremsgs.MsgDBMigrationFailed)
+	}
+	v, dirty, err := driver.Version()
+	if err != nil {
+		return 0, false, i18n.WrapError(ctx, err, coremsgs.MsgDBMigrationFailed)
+	}
+	if v == migratedb.NilVersion {
+		return 0, false, nil
+	}
+	return uint(v), dirty, nil
+}
+
+// runMigration runs a change to the schema, where there being nothing to change is not an error
+func (s *SQLCommon) runMigration(ctx context.Context, fn func(m *migrate.Migrate) error) error {
+	driver, err := s.provider.GetMigrationDriver(s.db)
+	if err == nil {
+		var m *migrate.Migrate
+		m, err = migrate.NewWithDatabaseInstance(
+			"file://"+s.migrationsDirectory,
+			s.provider.MigrationsDir(), driver)
+		if err == nil {
+			err = fn(m)
+		}
+	}
+	if err != nil && err != migrate.ErrNoChange {
+		return i18n.WrapError(ctx, err, coremsgs.MsgDBMigrationFailed)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersionMatchesMigrations(t *testing.T) {
+	for _, dir := range []string{"postgres", "sqlite"} {
+		files, err := os.ReadDir("../../../db/migrations/" + dir)
+		assert.NoError(t, err)
+		latest := uint(0)
+		for _, file := range files {
+			m, err := source.Parse(file.Name())
+			assert.NoError(t, err)
+			if m.Version > latest {
+				latest = m.Version
+			}
+		}
+		assert.Equal(t, SchemaVersion, latest, "the newest %s migration must be the SchemaVersion", dir)
+	}
+}
+
+func TestMigrateDownUpAndStatus(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := s.CheckSchema(ctx)
+	assert.NoError(t, err)
+	status, err := s.MigrationStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, status.Version)
+	assert.Equal(t, SchemaVersion, status.Required)
+	assert.False(t, status.Dirty)
+	last := status.Migrations[len(status.Migrations)-1]
+	assert.Equal(t, SchemaVersion, last.Version)
+	assert.Equal(t, "create_ffierrors_table", last.Name)
+	assert.True(t, last.Applied)
+
+	err = s.MigrateDown(ctx, 2)
+	assert.NoError(t, err)
+	err = s.CheckSchema(ctx)
+	assert.Regexp(t, "FF10463", err)
+	status, err = s.MigrationStatus(ctx)
+	assert.NoError(t, err)
+	previous := status.Migrations[len(status.Migrations)-3]
+	assert.Equal(t, previous.Version, status.Version)
+	assert.True(t, previous.Applied)
+	assert.False(t, status.Migrations[len(status.Migrations)-2].Applied)
+	assert.False(t, status.Migrations[len(status.Migrations)-1].Applied)
+
+	err = s.MigrateTo(ctx, status.Migrations[len(status.Migrations)-2].Version)
+	assert.NoError(t, err)
+	err = s.MigrateUp(ctx)
+	assert.NoError(t, err)
+	err = s.MigrateUp(ctx) // nothing to do
+	assert.NoError(t, err)
+	err = s.CheckSchema(ctx)
+	assert.NoError(t, err)
+
+	err = s.MigrateTo(ctx, 0)
+	assert.NoError(t, err)
+	status, err = s.MigrationStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.False(t, status.Migrations[0].Applied)
+}
+
+func TestCheckSchemaDirty(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	driver, err := s.GetMigrationDriver(s.db)
+	assert.NoError(t, err)
+	err = driver.SetVersion(int(SchemaVersion), true)
+	assert.NoError(t, err)
+
+	err = s.CheckSchema(context.Background())
+	assert.Regexp(t, "FF10464", err)
+	status, err := s.MigrationStatus(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.Dirty)
+	assert.False(t, status.Migrations[len(status.Migrations)-1].Applied)
+}
+
+func TestMigrateToMissingVersion(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	err := s.MigrateTo(context.Background(), SchemaVersion+1000)
+	assert.Regexp(t, "FF10163", err)
+}
+
+func TestMigrationStatusBadDirectory(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	s.migrationsDirectory = "!missing"
+	_, err := s.MigrationStatus(context.Background())
+	assert.Regexp(t, "FF10163", err)
+}
+
+func TestMigrationDriverFail(t *testing.T) {
+	mp := newMockProvider()
+	mp.getMigrationDriverError = fmt.Errorf("pop")
+	s, _ := mp.init()
+
+	err := s.CheckSchema(context.Background())
+	assert.Regexp(t, "FF10163.*pop", err)
+	_, err = s.MigrationStatus(context.Background())
+	assert.Regexp(t, "FF10163.*pop", err)
+	err = s.MigrateDown(context.Background(), 1)
+	assert.Regexp(t, "FF10163.*pop", err)
+}
+
+func TestInitMigrator(t *testing.T) {
+	mp := newMockProvider()
+	err := mp.InitMigrator(context.Background(), mp, mp.config)
+	assert.NoError(t, err)
+	assert.Equal(t, "./db/migrations/mockdb", mp.migrationsDirectory)
+}
+
+func TestInitMigratorMissingURL(t *testing.T) {
+	mp := newMockProvider()
+	mp.config.Set(SQLConfDatasourceURL, "")
+	err := mp.InitMigrator(context.Background(), mp, mp.config)
+	assert.Regexp(t, "FF10138", err)
+}
+
+func TestInitMigratorOpenFail(t *testing.T) {
+	mp := newMockProvider()
+	mp.openError = fmt.Errorf("pop")
+	err := mp.InitMigrator(context.Background(), mp, mp.config)
+	assert.Regexp(t, "FF10112.*pop", err)
+}
@@ -147,7 +147,8 @@ func (s *SQLCommon) GetNextPins(ctx context.Context, filter database.Filter) (me
 		nextpin = append(nextpin, d)
 	}
 
-	return nextpin, s.queryRes(ctx, nextpinsTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, nextpinsTable, tx, fop, fi)
+	return nextpin, fr, err
 
 }
 
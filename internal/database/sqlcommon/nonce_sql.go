@@ -141,7 +141,8 @@ func (s *SQLCommon) GetNonces(ctx context.Context, filter database.Filter) (mess
 		nonce = append(nonce, d)
 	}
 
-	return nonce, s.queryRes(ctx, noncesTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, noncesTable, tx, fop, fi)
+	return nonce, fr, err
 
 }
 
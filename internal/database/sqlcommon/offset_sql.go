@@ -171,7 +171,8 @@ func (s *SQLCommon) GetOffsets(ctx context.Context, filter database.Filter) (mes
 		offset = append(offset, d)
 	}
 
-	return offset, s.queryRes(ctx, offsetsTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, offsetsTable, tx, fop, fi)
+	return offset, fr, err
 
 }
 
@@ -160,7 +160,8 @@ func (s *SQLCommon) GetOperations(ctx context.Context, filter database.Filter) (
 		ops = append(ops, op)
 	}
 
-	return ops, s.queryRes(ctx, operationsTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, operationsTable, tx, fop, fi)
+	return ops, fr, err
 }
 
 func (s *SQLCommon) UpdateOperation(ctx context.Context, ns string, id *fftypes.UUID, update database.Update) (err error) {
@@ -192,7 +192,8 @@ func (s *SQLCommon) GetPins(ctx context.Context, filter database.Filter) (messag
 		pin = append(pin, d)
 	}
 
-	return pin, s.queryRes(ctx, pinsTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, pinsTable, tx, fop, fi)
+	return pin, fr, err
 
 }
 
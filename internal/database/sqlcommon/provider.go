@@ -17,29 +17,161 @@
 package sqlcommon
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
 
 	sq "github.com/Masterminds/squirrel"
 	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/hyperledger/firefly-common/pkg/log"
 )
 
 const (
 	sequenceColumn = "seq"
 )
 
+// FullTextSearchFunc adds the condition to a select on a table with a full-text indexed "content" column,
+// for a search of the supplied text, and a "hit_rank" column where a higher number is a better match
+type FullTextSearchFunc func(query sq.SelectBuilder, table, text string) sq.SelectBuilder
+
 type SQLFeatures struct {
 	UseILIKE              bool
 	MultiRowInsert        bool
 	PlaceholderFormat     sq.PlaceholderFormat
 	ExclusiveTableLockSQL func(table string) string
+	// JSONExtractTextSQL returns an expression for the value at a path in a JSON column, as text
+	JSONExtractTextSQL func(column string, path []string) string
+	// JSONExtractNumberSQL returns an expression for the value at a path in a JSON column, as a number (or NULL if it is not a number)
+	JSONExtractNumberSQL func(column string, path []string) string
+	// FullTextSearch builds the search query, or is nil if search is not supported
+	FullTextSearch FullTextSearchFunc
+	// InitFullTextSearch is called on startup, once the migrations have run, for databases where the full-text
+	// index depends on the capabilities of the database build. It returns the search to use in place of FullTextSearch
+	InitFullTextSearch func(ctx context.Context, db *sql.DB, table string) (FullTextSearchFunc, error)
 }
 
 func DefaultSQLProviderFeatures() SQLFeatures {
 	return SQLFeatures{
-		UseILIKE:          false,
-		MultiRowInsert:    false,
-		PlaceholderFormat: sq.Dollar,
+		UseILIKE:             false,
+		MultiRowInsert:       false,
+		PlaceholderFormat:    sq.Dollar,
+		JSONExtractTextSQL:   sqliteJSONExtractText,
+		JSONExtractNumberSQL: sqliteJSONExtractNumber,
+		FullTextSearch:       sqliteLikeSearch,
+		InitFullTextSearch:   sqliteInitFullTextSearch,
+	}
+}
+
+func sqliteJSONPath(path []string) string {
+	var jsonPath strings.Builder
+	jsonPath.WriteString("$")
+	for _, segment := range path {
+		jsonPath.WriteString(fmt.Sprintf(`."%s"`, segment))
+	}
+	return jsonPath.String()
+}
+
+func sqliteJSONExtractText(column string, path []string) string {
+	return fmt.Sprintf("CAST(json_extract(%s, '%s') AS TEXT)", column, sqliteJSONPath(path))
+}
+
+func sqliteJSONExtractNumber(column string, path []string) string {
+	jsonPath := sqliteJSONPath(path)
+	return fmt.Sprintf("(CASE WHEN json_type(%[1]s, '%[2]s') IN ('integer', 'real') THEN json_extract(%[1]s, '%[2]s') END)", column, jsonPath)
+}
+
+// sqliteInitFullTextSearch creates the FTS5 table that shadows the search table, when SQLite has been built with
+// the FTS5 extension. This is not part of the migrations, so that they run on every build of SQLite, including the
+// pure Go build used without CGO. Without FTS5 each word of a search is matched with LIKE instead.
+func sqliteInitFullTextSearch(ctx context.Context, db *sql.DB, table string) (FullTextSearchFunc, error) {
+	fts := table + "_fts"
+	insertTrigger := table + "_insert"
+	deleteTrigger := table + "_delete"
+
+	var fts5 bool
+	if err := db.QueryRowContext(ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5); err != nil {
+		return nil, err
+	}
+	if !fts5 {
+		log.L(ctx).Warnf("SQLite was built without FTS5, so searches will match words with LIKE")
+		// Triggers created by a build with FTS5 would fail every write, so they are removed. The index is rebuilt
+		// if the database is opened with FTS5 again
+		for _, trigger := range []string{insertTrigger, deleteTrigger} {
+			if _, err := db.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s", trigger)); err != nil {
+				return nil, err
+			}
+		}
+		return sqliteLikeSearch, nil
+	}
+
+	var tables int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&tables); err != nil {
+		return nil, err
+	}
+	if tables == 0 {
+		// The migrations have not been run, so there is nothing to index yet
+		log.L(ctx).Warnf("Table %s does not exist, so the full-text search index is not built", table)
+		return sqliteFullTextSearch, nil
+	}
+
+	var triggers int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (?, ?)", insertTrigger, deleteTrigger).Scan(&triggers); err != nil {
+		return nil, err
+	}
+	if triggers < 2 {
+		// The index is new, or has not been kept up to date, so it is built from the current contents of the table
+		log.L(ctx).Infof("Building full-text search index %s", fts)
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, ddl := range []string{
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(content, content='%s', content_rowid='%s')", fts, table, sequenceColumn),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER INSERT ON %s BEGIN INSERT INTO %s(rowid, content) VALUES (new.%s, new.content); END", insertTrigger, table, fts, sequenceColumn),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER DELETE ON %s BEGIN INSERT INTO %s(%s, rowid, content) VALUES ('delete', old.%s, old.content); END", deleteTrigger, table, fts, fts, sequenceColumn),
+			fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('rebuild')", fts),
+		} {
+			if _, err = tx.ExecContext(ctx, ddl); err != nil {
+				_ = tx.Rollback()
+				return nil, err
+			}
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	return sqliteFullTextSearch, nil
+}
+
+// sqliteLikeSearch requires every word of the text to appear in the content, ignoring case. The text is split into
+// words the same way as the FTS5 tokenizer splits it, but a word can match part of a longer word in the content
+func sqliteLikeSearch(query sq.SelectBuilder, table, text string) sq.SelectBuilder {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	where := sq.And{}
+	for _, word := range words {
+		where = append(where, sq.Expr(fmt.Sprintf("lower(%s.content) LIKE ?", table), "%"+word+"%"))
+	}
+	return query.
+		Column(sq.Alias(sq.Expr("1.0"), "hit_rank")).
+		Where(where)
+}
+
+// sqliteFullTextSearch uses the FTS5 table that shadows the table, where each word of the text
+// is quoted so it is matched as a phrase rather than being parsed as a query expression
+func sqliteFullTextSearch(query sq.SelectBuilder, table, text string) sq.SelectBuilder {
+	fts := table + "_fts"
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
 	}
+	return query.
+		Column(sq.Alias(sq.Expr(fmt.Sprintf("-bm25(%s)", fts)), "hit_rank")).
+		Join(fmt.Sprintf("%[1]s ON %[1]s.rowid = %[2]s.%[3]s", fts, table, sequenceColumn)).
+		Where(sq.Expr(fmt.Sprintf("%s MATCH ?", fts), strings.Join(words, " ")))
 }
 
 // Provider defines the interface an individual provider muse implement to customize the SQLCommon implementation
@@ -43,6 +43,7 @@ type mockProvider struct {
 	fakePSQLInsert          bool
 	openError               error
 	getMigrationDriverError error
+	initFullTextSearchError error
 	individualSort          bool
 }
 
@@ -79,6 +80,12 @@ func (mp *mockProvider) MigrationsDir() string {
 func (psql *mockProvider) Features() SQLFeatures {
 	features := DefaultSQLProviderFeatures()
 	features.UseILIKE = true
+	features.InitFullTextSearch = nil
+	if psql.initFullTextSearchError != nil {
+		features.InitFullTextSearch = func(ctx context.Context, db *sql.DB, table string) (FullTextSearchFunc, error) {
+			return nil, psql.initFullTextSearchError
+		}
+	}
 	features.ExclusiveTableLockSQL = func(table string) string {
 		return fmt.Sprintf(`LOCK TABLE "%s" IN EXCLUSIVE MODE;`, table)
 	}
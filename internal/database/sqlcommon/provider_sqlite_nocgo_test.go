@@ -0,0 +1,40 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cgo
+// +build !cgo
+
+package sqlcommon
+
+import (
+	"database/sql"
+
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+
+	// Import the pure Go SQLite driver, as the CGO driver is unavailable
+	_ "modernc.org/sqlite"
+)
+
+const sqliteTestMigrationsDir = "../../../db/migrations/sqlite"
+
+func openSQLiteTestDB(url string) (*sql.DB, error) {
+	return sql.Open("sqlite", url)
+}
+
+func getSQLiteTestMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	return sqlite.WithInstance(db, &sqlite.Config{})
+}
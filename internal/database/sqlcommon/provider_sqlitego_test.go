@@ -25,14 +25,10 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	migratedb "github.com/golang-migrate/migrate/v4/database"
-	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
-
-	// Import SQLite driver
-	_ "github.com/mattn/go-sqlite3"
 )
 
 // sqliteGoTestProvider uses QL in-memory database
@@ -60,7 +56,7 @@ func newSQLiteTestProvider(t *testing.T) (*sqliteGoTestProvider, func()) {
 	assert.NoError(t, err)
 	tp.config.Set(SQLConfDatasourceURL, "file::memory:")
 	tp.config.Set(SQLConfMigrationsAuto, true)
-	tp.config.Set(SQLConfMigrationsDirectory, "../../../db/migrations/sqlite")
+	tp.config.Set(SQLConfMigrationsDirectory, sqliteTestMigrationsDir)
 	tp.config.Set(SQLConfMaxConnections, 1)
 
 	err = tp.Init(context.Background(), tp, tp.config, tp.capabilities)
@@ -94,9 +90,9 @@ func (tp *sqliteGoTestProvider) ApplyInsertQueryCustomizations(insert sq.InsertB
 }
 
 func (tp *sqliteGoTestProvider) Open(url string) (*sql.DB, error) {
-	return sql.Open("sqlite3", url)
+	return openSQLiteTestDB(url)
 }
 
 func (tp *sqliteGoTestProvider) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
-	return sqlite3.WithInstance(db, &sqlite3.Config{})
+	return getSQLiteTestMigrationDriver(db)
 }
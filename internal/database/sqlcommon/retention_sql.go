@@ -0,0 +1,238 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+func (s *SQLCommon) getPruneTable(ctx context.Context, collection database.CollectionName) (tableName, timestampColumn string, err error) {
+	switch collection {
+	case database.CollectionName(database.CollectionEvents):
+		return eventsTable, "created", nil
+	case database.CollectionName(database.CollectionMessages):
+		return messagesTable, "created", nil
+	case database.CollectionName(database.CollectionOperations):
+		return operationsTable, "created", nil
+	case database.CollectionName(database.CollectionBlockchainEvents):
+		// Blockchain Events have a `timestamp` column name
+		return blockchaineventsTable, "timestamp", nil
+	default:
+		return "", "", i18n.NewError(ctx, coremsgs.MsgUnsupportedCollection, collection)
+	}
+}
+
+// getMaxRowsBoundary returns the sequence of the newest row that is beyond the newest maxRows rows, or -1 if there are not that many rows
+func (s *SQLCommon) getMaxRowsBoundary(ctx context.Context, tableName, ns string, maxRows int64) (int64, error) {
+	rows, _, err := s.query(ctx, tableName,
+		sq.Select(sequenceColumn).
+			From(tableName).
+			Where(sq.Eq{"namespace": ns}).
+			OrderBy(sequenceColumn+" DESC").
+			Offset(uint64(maxRows)).
+			Limit(1),
+	)
+	if err != nil {
+		return -1, err
+	}
+	defer rows.Close()
+
+	boundary := int64(-1)
+	if rows.Next() {
+		if err = rows.Scan(&boundary); err != nil {
+			return -1, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tableName)
+		}
+	}
+	return boundary, nil
+}
+
+func (s *SQLCommon) queryIDsTx(ctx context.Context, tx *txWrapper, tableName, column string, where sq.Sqlizer) ([]*fftypes.UUID, error) {
+	rows, _, err := s.queryTx(ctx, tableName, tx,
+		sq.Select(column).
+			From(tableName).
+			Where(where),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []*fftypes.UUID
+	for rows.Next() {
+		var id fftypes.UUID
+		if err = rows.Scan(&id); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tableName)
+		}
+		ids = append(ids, &id)
+	}
+	return ids, nil
+}
+
+// pruneMessageData removes the data references of pruned messages, and the data itself once no other message refers to it
+func (s *SQLCommon) pruneMessageData(ctx context.Context, tx *txWrapper, ns string, msgIDs []*fftypes.UUID) error {
+	dataIDs, err := s.queryIDsTx(ctx, tx, messagesDataJoinTable, "data_id", sq.Eq{"message_id": msgIDs})
+	if err != nil {
+		return err
+	}
+	if err = s.deleteTx(ctx, messagesDataJoinTable, tx,
+		sq.Delete(messagesDataJoinTable).Where(sq.Eq{"message_id": msgIDs}),
+		nil, // no change event
+	); err != nil && err != database.DeleteRecordNotFound {
+		return err
+	}
+	if len(dataIDs) == 0 {
+		return nil
+	}
+
+	// Data can be attached to more than one message, so keep anything still referenced
+	referenced, err := s.queryIDsTx(ctx, tx, messagesDataJoinTable, "data_id", sq.Eq{"data_id": dataIDs})
+	if err != nil {
+		return err
+	}
+	stillReferenced := make(map[fftypes.UUID]bool, len(referenced))
+	for _, id := range referenced {
+		stillReferenced[*id] = true
+	}
+	orphaned := make([]*fftypes.UUID, 0, len(dataIDs))
+	for _, id := range dataIDs {
+		if !stillReferenced[*id] {
+			stillReferenced[*id] = true // de-duplicate
+			orphaned = append(orphaned, id)
+		}
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	log.L(ctx).Debugf("Pruning %d data items of pruned messages in namespace '%s'", len(orphaned), ns)
+	if err = s.deleteTx(ctx, dataTable, tx,
+		sq.Delete(dataTable).Where(sq.Eq{"id": orphaned}),
+		func() {
+			for _, id := range orphaned {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionData, core.ChangeEventTypeDeleted, ns, id)
+			}
+		},
+	); err != nil && err != database.DeleteRecordNotFound {
+		return err
+	}
+	return s.deleteSearchRecords(ctx, tx, core.SearchHitTypeData, orphaned)
+}
+
+func (s *SQLCommon) PruneCollection(ctx context.Context, ns string, collection database.CollectionName, criteria *database.PruneCriteria) (int64, error) {
+	tableName, timestampColumn, err := s.getPruneTable(ctx, collection)
+	if err != nil {
+		return 0, err
+	}
+
+	prune := sq.Or{}
+	if criteria.Before != nil {
+		prune = append(prune, sq.Lt{timestampColumn: criteria.Before})
+	}
+	if criteria.MaxRows > 0 {
+		boundary, err := s.getMaxRowsBoundary(ctx, tableName, ns, criteria.MaxRows)
+		if err != nil {
+			return 0, err
+		}
+		if boundary >= 0 {
+			prune = append(prune, sq.LtOrEq{sequenceColumn: boundary})
+		}
+	}
+	if len(prune) == 0 {
+		return 0, nil
+	}
+	where := sq.And{sq.Eq{"namespace": ns}, prune}
+	if criteria.MaxSequence >= 0 {
+		where = append(where, sq.LtOrEq{sequenceColumn: criteria.MaxSequence})
+	}
+	if criteria.NotAfter != nil {
+		where = append(where, sq.Lt{timestampColumn: criteria.NotAfter})
+	}
+	if tableName == operationsTable {
+		// Operations that have not completed are still being tracked, so are never pruned
+		where = append(where, sq.Eq{"opstatus": []core.OpStatus{core.OpStatusSucceeded, core.OpStatusFailed}})
+	}
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	rows, _, err := s.queryTx(ctx, tableName, tx,
+		sq.Select("id", sequenceColumn).
+			From(tableName).
+			Where(where).
+			OrderBy(sequenceColumn).
+			Limit(uint64(criteria.Limit)),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var ids []*fftypes.UUID
+	var sequences []int64
+	for rows.Next() {
+		var id fftypes.UUID
+		var sequence int64
+		if err = rows.Scan(&id, &sequence); err != nil {
+			rows.Close()
+			return 0, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tableName)
+		}
+		ids = append(ids, &id)
+		sequences = append(sequences, sequence)
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return 0, s.commitTx(ctx, tx, autoCommit)
+	}
+
+	if tableName == messagesTable {
+		if err = s.pruneMessageData(ctx, tx, ns, ids); err != nil {
+			return 0, err
+		}
+		if err = s.deleteSearchRecords(ctx, tx, core.SearchHitTypeMessage, ids); err != nil {
+			return 0, err
+		}
+	}
+
+	log.L(ctx).Debugf("Pruning %d rows from %s in namespace '%s' (sequences %d-%d)", len(ids), tableName, ns, sequences[0], sequences[len(sequences)-1])
+	if err = s.deleteTx(ctx, tableName, tx, sq.Delete(tableName).Where(sq.Eq{"id": ids}),
+		func() {
+			for i, id := range ids {
+				switch tableName {
+				case eventsTable:
+					s.callbacks.OrderedUUIDCollectionNSEvent(database.CollectionEvents, core.ChangeEventTypeDeleted, ns, id, sequences[i])
+				case messagesTable:
+					s.callbacks.OrderedUUIDCollectionNSEvent(database.CollectionMessages, core.ChangeEventTypeDeleted, ns, id, sequences[i])
+				default:
+					s.callbacks.UUIDCollectionNSEvent(database.UUIDCollectionNS(collection), core.ChangeEventTypeDeleted, ns, id)
+				}
+			}
+		},
+	); err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), s.commitTx(ctx, tx, autoCommit)
+}
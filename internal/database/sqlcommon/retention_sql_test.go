@@ -0,0 +1,432 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func pruneTestTime(t time.Time) *fftypes.FFTime {
+	ft := fftypes.FFTime(t)
+	return &ft
+}
+
+func TestPruneEventsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	base := time.Now().Add(-1 * time.Hour)
+	events := make([]*core.Event, 5)
+	for i := range events {
+		events[i] = &core.Event{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Type:      core.EventTypeMessageConfirmed,
+			Created:   pruneTestTime(base.Add(time.Duration(i) * time.Minute)),
+		}
+		err := s.InsertEvent(ctx, events[i])
+		assert.NoError(t, err)
+	}
+	otherNS := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns2", Type: core.EventTypeMessageConfirmed, Created: pruneTestTime(base)}
+	err := s.InsertEvent(ctx, otherNS)
+	assert.NoError(t, err)
+
+	// Keep the newest three rows
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeDeleted, "ns1", events[0].ID, events[0].Sequence).Return().Once()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeDeleted, "ns1", events[1].ID, events[1].Sequence).Return().Once()
+	pruned, err := s.PruneCollection(ctx, "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		MaxRows:     3,
+		MaxSequence: -1,
+		Limit:       100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), pruned)
+
+	// Prune by age, but not beyond the max sequence
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeDeleted, "ns1", events[2].ID, events[2].Sequence).Return().Once()
+	pruned, err = s.PruneCollection(ctx, "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: events[2].Sequence,
+		Limit:       100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	// Nothing created before the time limit remains
+	pruned, err = s.PruneCollection(ctx, "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+		NotAfter:    pruneTestTime(base.Add(3 * time.Minute)),
+		Limit:       100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pruned)
+
+	remaining, _, err := s.GetEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 0))
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 3)
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestPruneMessagesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	newData := func(value string) *core.Data {
+		data := &core.Data{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Created:   fftypes.Now(),
+			Value:     fftypes.JSONAnyPtr(fmt.Sprintf(`"%s"`, value)),
+		}
+		data.Hash = data.Value.Hash()
+		s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", data.ID).Return()
+		err := s.UpsertData(ctx, data, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		return data
+	}
+	newMessage := func(tag string, data ...*core.Data) *core.Message {
+		msg := &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Type:      core.MessageTypeBroadcast,
+				Created:   fftypes.Now(),
+				Namespace: "ns1",
+				Tag:       tag,
+				TxType:    core.TransactionTypeUnpinned,
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash:  fftypes.NewRandB32(),
+			State: core.MessageStateConfirmed,
+		}
+		for _, d := range data {
+			msg.Data = append(msg.Data, &core.DataRef{ID: d.ID, Hash: d.Hash})
+		}
+		s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", msg.Header.ID, mock.Anything).Return()
+		err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		return msg
+	}
+
+	ownData := newData("own")
+	sharedData := newData("shared")
+	msg1 := newMessage("order", ownData, sharedData)
+	msg2 := newMessage("invoice", sharedData)
+	msgRead, err := s.GetMessageByID(ctx, msg1.Header.ID)
+	assert.NoError(t, err)
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeDeleted, "ns1", msg1.Header.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeDeleted, "ns1", ownData.ID).Return()
+	pruned, err := s.PruneCollection(ctx, "ns1", database.CollectionName(database.CollectionMessages), &database.PruneCriteria{
+		Before:      pruneTestTime(time.Now().Add(1 * time.Minute)),
+		MaxSequence: msgRead.Sequence,
+		Limit:       100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	msgRead, err = s.GetMessageByID(ctx, msg1.Header.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, msgRead)
+	var dataRefCount int
+	err = s.db.QueryRow(`SELECT COUNT(*) FROM messages_data WHERE message_id = ?`, msg1.Header.ID).Scan(&dataRefCount)
+	assert.NoError(t, err)
+	assert.Zero(t, dataRefCount)
+	hits, err := s.Search(ctx, "ns1", "order", nil, 25)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// The data only attached to the pruned message is removed, and the shared data is kept for the other message
+	dataRead, err := s.GetDataByID(ctx, ownData.ID, false)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead)
+	dataRead, err = s.GetDataByID(ctx, sharedData.ID, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, dataRead)
+	msgRead, err = s.GetMessageByID(ctx, msg2.Header.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, msgRead)
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestPruneOperationsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	opID := fftypes.NewUUID()
+	op := &core.Operation{
+		ID:          opID,
+		Namespace:   "ns1",
+		Type:        core.OpTypeBlockchainPinBatch,
+		Transaction: fftypes.NewUUID(),
+		Status:      core.OpStatusSucceeded,
+		Plugin:      "ethereum",
+		Created:     fftypes.Now(),
+	}
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", opID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeDeleted, "ns1", opID).Return()
+	err := s.InsertOperation(ctx, op)
+	assert.NoError(t, err)
+
+	pendingOp := &core.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Type:        core.OpTypeBlockchainPinBatch,
+		Transaction: fftypes.NewUUID(),
+		Status:      core.OpStatusPending,
+		Plugin:      "ethereum",
+		Created:     fftypes.Now(),
+	}
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", pendingOp.ID).Return()
+	err = s.InsertOperation(ctx, pendingOp)
+	assert.NoError(t, err)
+
+	pruned, err := s.PruneCollection(ctx, "ns1", database.CollectionName(database.CollectionOperations), &database.PruneCriteria{
+		Before:      pruneTestTime(time.Now().Add(1 * time.Minute)),
+		MaxSequence: -1,
+		Limit:       100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	opRead, err := s.GetOperationByID(ctx, opID)
+	assert.NoError(t, err)
+	assert.Nil(t, opRead)
+	opRead, err = s.GetOperationByID(ctx, pendingOp.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, opRead)
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestPruneCollectionUnsupported(t *testing.T) {
+	s, _ := newMockProvider().init()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionTokenPools), &database.PruneCriteria{})
+	assert.Regexp(t, "FF10301", err)
+}
+
+func TestPruneCollectionNoCriteria(t *testing.T) {
+	s, mock := newMockProvider().init()
+	pruned, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		MaxSequence: -1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pruned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionMaxRowsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		MaxRows: 10,
+	})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionMaxRowsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow("not a number"))
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		MaxRows: 10,
+	})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionMaxRowsNotReached(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+	pruned, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		MaxRows: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pruned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionOperations), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionQueryMessageDataFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionMessages), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionScanMessageDataFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnRows(sqlmock.NewRows([]string{"data_id"}).AddRow("!uuid"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionMessages), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionQueryDataReferencesFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnRows(sqlmock.NewRows([]string{"data_id"}).AddRow(fftypes.NewUUID().String()))
+	mock.ExpectExec("DELETE .*messages_data").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionMessages), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionDeleteDataFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnRows(sqlmock.NewRows([]string{"data_id"}).AddRow(fftypes.NewUUID().String()))
+	mock.ExpectExec("DELETE .*messages_data").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnRows(sqlmock.NewRows([]string{"data_id"}))
+	mock.ExpectExec("DELETE .*data").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionMessages), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionDeleteMessageDataFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnRows(sqlmock.NewRows([]string{"data_id"}))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionMessages), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionDeleteMessageSearchFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectQuery("SELECT data_id .*").WillReturnRows(sqlmock.NewRows([]string{"data_id"}))
+	mock.ExpectExec("DELETE .*messages_data").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE .*searchindex").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionMessages), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneCollectionCommitFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(fftypes.NewUUID().String(), 12345))
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.PruneCollection(context.Background(), "ns1", database.CollectionName(database.CollectionBlockchainEvents), &database.PruneCriteria{
+		Before:      fftypes.Now(),
+		MaxSequence: -1,
+	})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,229 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+var (
+	searchColumns = []string{
+		"namespace",
+		"hit_type",
+		"id",
+		"content",
+	}
+)
+
+const searchTable = "searchindex"
+
+// searchRecord is the text indexed for full-text search of a record
+type searchRecord struct {
+	namespace string
+	hitType   core.SearchHitType
+	id        *fftypes.UUID
+	content   []string
+}
+
+func messageSearchRecord(message *core.Message) *searchRecord {
+	header := &message.Header
+	content := append([]string{header.Tag}, header.Topics...)
+	return &searchRecord{
+		namespace: header.Namespace,
+		hitType:   core.SearchHitTypeMessage,
+		id:        header.ID,
+		content:   append(content, header.Author),
+	}
+}
+
+func dataSearchRecord(data *core.Data) *searchRecord {
+	var content []string
+	if data.Datatype != nil {
+		content = append(content, data.Datatype.Name)
+	}
+	if data.Blob != nil {
+		content = append(content, data.Blob.Name)
+	}
+	if data.Value != nil {
+		var value interface{}
+		decoder := json.NewDecoder(strings.NewReader(data.Value.String()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err == nil {
+			content = appendJSONSearchValues(content, value)
+		}
+	}
+	return &searchRecord{
+		namespace: data.Namespace,
+		hitType:   core.SearchHitTypeData,
+		id:        data.ID,
+		content:   content,
+	}
+}
+
+// appendJSONSearchValues appends the string and number values found anywhere inside a JSON value,
+// visiting the fields of objects in key order so the content is the same each time it is built
+func appendJSONSearchValues(content []string, value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return append(content, v)
+	case json.Number:
+		return append(content, v.String())
+	case []interface{}:
+		for _, entry := range v {
+			content = appendJSONSearchValues(content, entry)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			content = appendJSONSearchValues(content, v[k])
+		}
+	}
+	return content
+}
+
+func identitySearchRecord(identity *core.Identity) *searchRecord {
+	return &searchRecord{
+		namespace: identity.Namespace,
+		hitType:   core.SearchHitTypeIdentity,
+		id:        identity.ID,
+		content:   []string{identity.Name, identity.DID, identity.Description},
+	}
+}
+
+func tokenPoolSearchRecord(pool *core.TokenPool) *searchRecord {
+	return &searchRecord{
+		namespace: pool.Namespace,
+		hitType:   core.SearchHitTypeTokenPool,
+		id:        pool.ID,
+		content:   []string{pool.Name, pool.Symbol},
+	}
+}
+
+func ffiSearchRecord(ffi *core.FFI) *searchRecord {
+	return &searchRecord{
+		namespace: ffi.Namespace,
+		hitType:   core.SearchHitTypeFFI,
+		id:        ffi.ID,
+		content:   []string{ffi.Name, ffi.Version, ffi.Description},
+	}
+}
+
+func (sr *searchRecord) text() string {
+	var text strings.Builder
+	for _, s := range sr.content {
+		if s = strings.TrimSpace(s); s != "" {
+			if text.Len() > 0 {
+				text.WriteRune(' ')
+			}
+			text.WriteString(s)
+		}
+	}
+	return text.String()
+}
+
+// indexSearchRecords adds records to the full-text search index. Records that might have been indexed before
+// (because they were updated or replaced) must set replace, so the existing entries are removed first
+func (s *SQLCommon) indexSearchRecords(ctx context.Context, tx *txWrapper, replace bool, records ...*searchRecord) error {
+	if replace {
+		for _, sr := range records {
+			if err := s.deleteSearchRecords(ctx, tx, sr.hitType, []*fftypes.UUID{sr.id}); err != nil {
+				return err
+			}
+		}
+	}
+
+	query := sq.Insert(searchTable).Columns(searchColumns...)
+	count := 0
+	for _, sr := range records {
+		text := sr.text()
+		if text == "" {
+			continue
+		}
+		if !s.features.MultiRowInsert {
+			if _, err := s.insertTx(ctx, searchTable, tx, sq.Insert(searchTable).Columns(searchColumns...).Values(sr.namespace, sr.hitType, sr.id, text), nil); err != nil {
+				return err
+			}
+			continue
+		}
+		query = query.Values(sr.namespace, sr.hitType, sr.id, text)
+		count++
+	}
+	if count > 0 {
+		return s.insertTxRows(ctx, searchTable, tx, query, nil, make([]int64, count), false)
+	}
+	return nil
+}
+
+func (s *SQLCommon) deleteSearchRecords(ctx context.Context, tx *txWrapper, hitType core.SearchHitType, ids []*fftypes.UUID) error {
+	err := s.deleteTx(ctx, searchTable, tx,
+		sq.Delete(searchTable).Where(sq.Eq{"hit_type": hitType, "id": ids}),
+		nil, // no change event
+	)
+	if err != nil && err != database.DeleteRecordNotFound {
+		return err
+	}
+	return nil
+}
+
+func (s *SQLCommon) Search(ctx context.Context, ns, text string, types []core.SearchHitType, limit int) ([]*core.SearchHit, error) {
+	if s.features.FullTextSearch == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSearchNotSupported, s.provider.Name())
+	}
+
+	where := sq.And{sq.Eq{searchTable + ".namespace": ns}}
+	if len(types) > 0 {
+		hitTypes := make([]string, len(types))
+		for i, t := range types {
+			hitTypes[i] = string(t)
+		}
+		where = append(where, sq.Eq{searchTable + ".hit_type": hitTypes})
+	}
+	query := s.features.FullTextSearch(
+		sq.Select(searchTable+".hit_type", searchTable+".id").From(searchTable),
+		searchTable, text,
+	).Where(where).OrderBy("hit_rank DESC").Limit(uint64(limit))
+
+	rows, _, err := s.query(ctx, searchTable, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []*core.SearchHit{}
+	for rows.Next() {
+		var hit core.SearchHit
+		if err := rows.Scan(&hit.Type, &hit.ID, &hit.Rank); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, searchTable)
+		}
+		hits = append(hits, &hit)
+	}
+	return hits, nil
+}
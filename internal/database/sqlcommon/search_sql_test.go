@@ -0,0 +1,399 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func searchHitTypes(hits []*core.SearchHit) map[core.SearchHitType]*fftypes.UUID {
+	types := make(map[core.SearchHitType]*fftypes.UUID)
+	for _, hit := range hits {
+		types[hit.Type] = hit.ID
+	}
+	return types
+}
+
+func TestSearchE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      core.MessageTypeBroadcast,
+			SignerRef: core.SignerRef{Author: "did:firefly:org/acme", Key: "0x12345"},
+			Namespace: "ns1",
+			Topics:    []string{"orders"},
+			Tag:       "purchase",
+			Created:   fftypes.Now(),
+			DataHash:  fftypes.NewRandB32(),
+			TxType:    core.TransactionTypeBatchPin,
+		},
+		Hash: fftypes.NewRandB32(),
+	}
+	err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	data := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{"customer":"Acme Corp","amount":42,"items":["bolt","nut"]}`),
+	}
+	err = s.InsertDataArray(ctx, core.DataArray{data})
+	assert.NoError(t, err)
+
+	identity := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:org/acme",
+			Type:      core.IdentityTypeOrg,
+			Namespace: "ns1",
+			Name:      "acme",
+		},
+		IdentityProfile: core.IdentityProfile{
+			Description: "Acme Corporation",
+		},
+		Messages: core.IdentityMessages{
+			Claim: fftypes.NewUUID(),
+		},
+	}
+	err = s.UpsertIdentity(ctx, identity, database.UpsertOptimizationSkip)
+	assert.NoError(t, err)
+
+	pool := &core.TokenPool{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "widgetcoin",
+		Symbol:    "WGT",
+		Type:      core.TokenTypeFungible,
+		Locator:   "12345",
+		Connector: "erc1155",
+		State:     core.TokenPoolStateConfirmed,
+		TX: core.TransactionRef{
+			Type: core.TransactionTypeTokenPool,
+			ID:   fftypes.NewUUID(),
+		},
+	}
+	err = s.UpsertTokenPool(ctx, pool)
+	assert.NoError(t, err)
+
+	ffi := &core.FFI{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Name:        "orders",
+		Version:     "v1.0.0",
+		Description: "Acme order contract",
+		Message:     fftypes.NewUUID(),
+	}
+	err = s.UpsertFFI(ctx, ffi)
+	assert.NoError(t, err)
+
+	// Search across all types
+	hits, err := s.Search(ctx, "ns1", "acme", nil, 25)
+	assert.NoError(t, err)
+	assert.Equal(t, map[core.SearchHitType]*fftypes.UUID{
+		core.SearchHitTypeMessage:  msg.Header.ID,
+		core.SearchHitTypeData:     data.ID,
+		core.SearchHitTypeIdentity: identity.ID,
+		core.SearchHitTypeFFI:      ffi.ID,
+	}, searchHitTypes(hits))
+	for _, hit := range hits {
+		assert.Greater(t, hit.Rank, float64(0))
+	}
+
+	// Restrict the types, and the number of hits
+	hits, err = s.Search(ctx, "ns1", "orders", []core.SearchHitType{core.SearchHitTypeMessage, core.SearchHitTypeData}, 25)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, msg.Header.ID, hits[0].ID)
+	hits, err = s.Search(ctx, "ns1", "acme", nil, 2)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+
+	// Values nested inside data, and quoting of the text
+	hits, err = s.Search(ctx, "ns1", `bolt "42`, nil, 25)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, data.ID, hits[0].ID)
+
+	// Other namespaces are not matched
+	hits, err = s.Search(ctx, "ns2", "acme", nil, 25)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// Updates replace the indexed text
+	pool.Symbol = "NEWCOIN"
+	err = s.UpsertTokenPool(ctx, pool)
+	assert.NoError(t, err)
+	hits, err = s.Search(ctx, "ns1", "WGT", nil, 25)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+	hits, err = s.Search(ctx, "ns1", "newcoin", nil, 25)
+	assert.NoError(t, err)
+	assert.Equal(t, map[core.SearchHitType]*fftypes.UUID{
+		core.SearchHitTypeTokenPool: pool.ID,
+	}, searchHitTypes(hits))
+
+	msg.Header.Tag = "refund"
+	err = s.ReplaceMessage(ctx, msg)
+	assert.NoError(t, err)
+	hits, err = s.Search(ctx, "ns1", "purchase", nil, 25)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+	hits, err = s.Search(ctx, "ns1", "refund", nil, 25)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+}
+
+func TestSearchNotSupported(t *testing.T) {
+	s, _ := newMockProvider().init()
+	s.features.FullTextSearch = nil
+	_, err := s.Search(context.Background(), "ns1", "acme", nil, 25)
+	assert.Regexp(t, "FF10462.*mockdb", err)
+}
+
+func TestSearchQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.Search(context.Background(), "ns1", "acme", []core.SearchHitType{core.SearchHitTypeData}, 25)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"hit_type"}).AddRow("data"))
+	_, err := s.Search(context.Background(), "ns1", "acme", nil, 25)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteFullTextSearch(t *testing.T) {
+	query := sqliteFullTextSearch(sq.Select("hit_type").From("searchindex"), "searchindex", `acme "corp`)
+	sql, args, err := query.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT hit_type, (-bm25(searchindex_fts)) AS hit_rank FROM searchindex JOIN searchindex_fts ON searchindex_fts.rowid = searchindex.seq WHERE searchindex_fts MATCH ?", sql)
+	assert.Equal(t, []interface{}{`"acme" """corp"`}, args)
+}
+
+func TestSQLiteLikeSearch(t *testing.T) {
+	query := sqliteLikeSearch(sq.Select("hit_type").From("searchindex"), "searchindex", `Acme "corp`)
+	sql, args, err := query.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT hit_type, (1.0) AS hit_rank FROM searchindex WHERE (lower(searchindex.content) LIKE ? AND lower(searchindex.content) LIKE ?)", sql)
+	assert.Equal(t, []interface{}{"%acme%", "%corp%"}, args)
+}
+
+func searchSQL(t *testing.T, search FullTextSearchFunc) string {
+	sql, _, err := search(sq.Select("hit_type").From("searchindex"), "searchindex", "acme").ToSql()
+	assert.NoError(t, err)
+	return sql
+}
+
+func TestSQLiteInitFullTextSearchCompileOptionFail(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnError(fmt.Errorf("pop"))
+	_, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchNoFTS5(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(false))
+	mock.ExpectExec("DROP TRIGGER IF EXISTS searchindex_insert").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DROP TRIGGER IF EXISTS searchindex_delete").WillReturnResult(sqlmock.NewResult(0, 0))
+	search, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.NoError(t, err)
+	assert.Contains(t, searchSQL(t, search), "LIKE")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchNoFTS5DropTriggerFail(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(false))
+	mock.ExpectExec("DROP TRIGGER .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchTriggerCountFail(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT.*type = 'trigger'.*").WillReturnError(fmt.Errorf("pop"))
+	_, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchTableCountFail(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnError(fmt.Errorf("pop"))
+	_, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchNoTable(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	search, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.NoError(t, err)
+	assert.Contains(t, searchSQL(t, search), "MATCH")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchAlreadyBuilt(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT.*type = 'trigger'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	search, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.NoError(t, err)
+	assert.Contains(t, searchSQL(t, search), "MATCH")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchBuild(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT.*type = 'trigger'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE VIRTUAL TABLE IF NOT EXISTS searchindex_fts USING fts5").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TRIGGER IF NOT EXISTS searchindex_insert").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TRIGGER IF NOT EXISTS searchindex_delete").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO searchindex_fts\\(searchindex_fts\\) VALUES \\('rebuild'\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	search, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.NoError(t, err)
+	assert.Contains(t, searchSQL(t, search), "MATCH")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchBeginFail(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT.*type = 'trigger'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchCreateFail(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT.*type = 'trigger'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE VIRTUAL TABLE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLiteInitFullTextSearchCommitFail(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mock.ExpectQuery("SELECT sqlite_compileoption_used.*").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectQuery("SELECT COUNT.*type = 'table'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT.*type = 'trigger'.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec(".*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(".*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(".*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(".*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	_, err := sqliteInitFullTextSearch(context.Background(), db, "searchindex")
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndexSearchRecordsDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	ctx, tx, _, err := s.beginOrUseTx(context.Background())
+	assert.NoError(t, err)
+	err = s.indexSearchRecords(ctx, tx, true, tokenPoolSearchRecord(&core.TokenPool{ID: fftypes.NewUUID(), Name: "pool1"}))
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndexSearchRecordsInsertFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	ctx, tx, _, err := s.beginOrUseTx(context.Background())
+	assert.NoError(t, err)
+	err = s.indexSearchRecords(ctx, tx, true, tokenPoolSearchRecord(&core.TokenPool{ID: fftypes.NewUUID(), Name: "pool1"}))
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndexSearchRecordsMultiRow(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.features.MultiRowInsert = true
+	s.fakePSQLInsert = true
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT .*").WillReturnRows(sqlmock.NewRows([]string{sequenceColumn}).AddRow(1).AddRow(2))
+	ctx, tx, _, err := s.beginOrUseTx(context.Background())
+	assert.NoError(t, err)
+	err = s.indexSearchRecords(ctx, tx, false,
+		ffiSearchRecord(&core.FFI{ID: fftypes.NewUUID(), Name: "ffi1"}),
+		ffiSearchRecord(&core.FFI{ID: fftypes.NewUUID()}),
+		ffiSearchRecord(&core.FFI{ID: fftypes.NewUUID(), Name: "ffi2"}),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataSearchRecord(t *testing.T) {
+	sr := dataSearchRecord(&core.Data{
+		Datatype: &core.DatatypeRef{Name: "widget"},
+		Blob:     &core.BlobRef{Name: "widget.png"},
+		Value:    fftypes.JSONAnyPtr(`{"nested":{"value":"blue","flag":true,"count":12.5,"empty":null}}`),
+	})
+	assert.Equal(t, "widget widget.png 12.5 blue", sr.text())
+
+	sr = dataSearchRecord(&core.Data{Value: fftypes.JSONAnyPtr(`!json`)})
+	assert.Equal(t, "", sr.text())
+}
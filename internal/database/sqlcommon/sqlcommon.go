@@ -22,7 +22,6 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/golang-migrate/migrate/v4"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -31,17 +30,17 @@ import (
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/sirupsen/logrus"
-
-	// Import migrate file source
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 type SQLCommon struct {
 	db           *sql.DB
+	replica      *sql.DB
 	capabilities *database.Capabilities
 	callbacks    callbacks
 	provider     Provider
 	features     SQLFeatures
+
+	migrationsDirectory string
 }
 
 type callbacks struct {
@@ -105,31 +104,68 @@ func (s *SQLCommon) Init(ctx context.Context, provider Provider, config config.S
 	if s.db, err = provider.Open(config.GetString(SQLConfDatasourceURL)); err != nil {
 		return i18n.WrapError(ctx, err, coremsgs.MsgDBInitFailed)
 	}
-	connLimit := config.GetInt(SQLConfMaxConnections)
-	if connLimit > 0 {
-		s.db.SetMaxOpenConns(connLimit)
-		s.db.SetConnMaxIdleTime(config.GetDuration(SQLConfMaxConnIdleTime))
-		maxIdleConns := config.GetInt(SQLConfMaxIdleConns)
-		if maxIdleConns <= 0 {
-			// By default we rely on the idle time, rather than a maximum number of conns to leave open
-			maxIdleConns = connLimit
-		}
-		s.db.SetMaxIdleConns(maxIdleConns)
-		s.db.SetConnMaxLifetime(config.GetDuration(SQLConfMaxConnLifetime))
-	}
+	connLimit := configureConnPool(s.db, config)
 	if connLimit > 1 {
 		capabilities.Concurrency = true
 	}
 
+	s.migrationsDirectory = config.GetString(SQLConfMigrationsDirectory)
 	if config.GetBool(SQLConfMigrationsAuto) {
-		if err = s.applyDBMigrations(ctx, config, provider); err != nil {
+		if err = s.MigrateUp(ctx); err != nil {
 			return i18n.WrapError(ctx, err, coremsgs.MsgDBMigrationFailed)
 		}
 	}
 
+	if s.features.InitFullTextSearch != nil {
+		search, err := s.features.InitFullTextSearch(ctx, s.db, searchTable)
+		if err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgDBInitFailed)
+		}
+		s.features.FullTextSearch = search
+	}
+
+	return s.createDataValueIndexes(ctx, config.SubArray(SQLConfDataValueIndexes))
+}
+
+// InitReadReplica opens the optional read replica of the database, which serves query-only calls
+// made with a context from database.WithReadReplica, outside of any transaction
+func (s *SQLCommon) InitReadReplica(ctx context.Context, config config.Section) (err error) {
+	url := config.GetString(SQLConfDatasourceURL)
+	if url == "" {
+		return nil
+	}
+	if s.replica, err = s.provider.Open(url); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgDBInitFailed)
+	}
+	configureConnPool(s.replica, config)
+	log.L(ctx).Infof("Query-only calls will be served from the read replica of the %s database", s.provider.Name())
 	return nil
 }
 
+func configureConnPool(db *sql.DB, config config.Section) int {
+	connLimit := config.GetInt(SQLConfMaxConnections)
+	if connLimit > 0 {
+		db.SetMaxOpenConns(connLimit)
+		db.SetConnMaxIdleTime(config.GetDuration(SQLConfMaxConnIdleTime))
+		maxIdleConns := config.GetInt(SQLConfMaxIdleConns)
+		if maxIdleConns <= 0 {
+			// By default we rely on the idle time, rather than a maximum number of conns to leave open
+			maxIdleConns = connLimit
+		}
+		db.SetMaxIdleConns(maxIdleConns)
+		db.SetConnMaxLifetime(config.GetDuration(SQLConfMaxConnLifetime))
+	}
+	return connLimit
+}
+
+// readDB returns the connection pool to use for a query outside of a transaction
+func (s *SQLCommon) readDB(ctx context.Context) *sql.DB {
+	if s.replica != nil && database.IsReadReplica(ctx) {
+		return s.replica
+	}
+	return s.db
+}
+
 func (s *SQLCommon) RegisterListener(listener database.Callbacks) {
 	s.callbacks.listeners = append(s.callbacks.listeners, listener)
 }
@@ -155,23 +191,6 @@ func (s *SQLCommon) RunAsGroup(ctx context.Context, fn func(ctx context.Context)
 	return s.commitTx(ctx, tx, false /* we _are_ the auto-committer */)
 }
 
-func (s *SQLCommon) applyDBMigrations(ctx context.Context, config config.Section, provider Provider) error {
-	driver, err := provider.GetMigrationDriver(s.db)
-	if err == nil {
-		var m *migrate.Migrate
-		m, err = migrate.NewWithDatabaseInstance(
-			"file://"+config.GetString(SQLConfMigrationsDirectory),
-			provider.MigrationsDir(), driver)
-		if err == nil {
-			err = m.Up()
-		}
-	}
-	if err != nil && err != migrate.ErrNoChange {
-		return i18n.WrapError(ctx, err, coremsgs.MsgDBMigrationFailed)
-	}
-	return nil
-}
-
 func getTXFromContext(ctx context.Context) *txWrapper {
 	ctxKey := txContextKey{}
 	txi := ctx.Value(ctxKey)
@@ -225,7 +244,7 @@ func (s *SQLCommon) queryTx(ctx context.Context, table string, tx *txWrapper, q
 	if tx != nil {
 		rows, err = tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
 	} else {
-		rows, err = s.db.QueryContext(ctx, sqlQuery, args...)
+		rows, err = s.readDB(ctx).QueryContext(ctx, sqlQuery, args...)
 	}
 	if err != nil {
 		l.Errorf(`SQL query failed: %s sql=[ %s ]`, err, sqlQuery)
@@ -261,7 +280,7 @@ func (s *SQLCommon) countQuery(ctx context.Context, table string, tx *txWrapper,
 	if tx != nil {
 		rows, err = tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
 	} else {
-		rows, err = s.db.QueryContext(ctx, sqlQuery, args...)
+		rows, err = s.readDB(ctx).QueryContext(ctx, sqlQuery, args...)
 	}
 	if err != nil {
 		l.Errorf(`SQL count query failed: %s sql=[ %s ]`, err, sqlQuery)
@@ -277,7 +296,7 @@ func (s *SQLCommon) countQuery(ctx context.Context, table string, tx *txWrapper,
 	return count, nil
 }
 
-func (s *SQLCommon) queryRes(ctx context.Context, table string, tx *txWrapper, fop sq.Sqlizer, fi *database.FilterInfo) *database.FilterResult {
+func (s *SQLCommon) queryRes(ctx context.Context, table string, tx *txWrapper, fop sq.Sqlizer, fi *database.FilterInfo) (*database.FilterResult, error) {
 	fr := &database.FilterResult{}
 	if fi.Count {
 		count, err := s.countQuery(ctx, table, tx, fop, fi.CountExpr)
@@ -287,7 +306,83 @@ func (s *SQLCommon) queryRes(ctx context.Context, table string, tx *txWrapper, f
 		}
 		fr.TotalCount = &count // could be -1 if the count extract fails - we still return the result
 	}
-	return fr
+	if cursors, ok := fop.(*cursorFilterOp); ok && fi.Limit > 0 {
+		if err := s.pageCursors(ctx, table, tx, cursors, fi, fr); err != nil {
+			return nil, err
+		}
+	}
+	return fr, nil
+}
+
+// pageCursors sets cursors on the result for the first and last items of the page, when there
+// are further matching items in that direction
+func (s *SQLCommon) pageCursors(ctx context.Context, table string, tx *txWrapper, cursors *cursorFilterOp, fi *database.FilterInfo, fr *database.FilterResult) error {
+	descending := fi.Sort[0].Descending
+	order := cursors.seqField
+	if descending {
+		order += " DESC"
+	}
+	q := cursors.sequenceQuery(cursors.conditions).
+		OrderBy(order).
+		Limit(fi.Limit)
+	if fi.Skip > 0 {
+		q = q.Offset(fi.Skip)
+	}
+	page, err := s.querySequences(ctx, table, tx, q)
+	if err != nil || len(page) == 0 {
+		return err
+	}
+	// Rows are not ordered coming out of the sub-query, so bound the page by its lowest and highest sequence
+	low, high := page[0], page[0]
+	for _, sequence := range page {
+		if sequence < low {
+			low = sequence
+		}
+		if sequence > high {
+			high = sequence
+		}
+	}
+	first, last := low, high
+	if descending {
+		first, last = high, low
+	}
+
+	following, err := s.querySequences(ctx, table, tx,
+		cursors.sequenceQuery(s.afterSequence(cursors.seqField, descending, last)).Limit(1))
+	if err != nil {
+		return err
+	}
+	if len(following) > 0 {
+		fr.Next = database.EncodeCursor(last)
+	}
+
+	preceding, err := s.querySequences(ctx, table, tx,
+		cursors.sequenceQuery(s.beforeSequence(cursors.seqField, descending, first)).Limit(1))
+	if err != nil {
+		return err
+	}
+	if len(preceding) > 0 {
+		fr.Prev = database.EncodeCursor(first)
+	}
+	return nil
+}
+
+// querySequences runs a query built with sequenceQuery, and returns the sequence of each row
+func (s *SQLCommon) querySequences(ctx context.Context, table string, tx *txWrapper, q sq.SelectBuilder) ([]int64, error) {
+	rows, _, err := s.queryTx(ctx, table, tx, sq.Select("ff_cursor_page.ff_cursor_seq").FromSelect(q, "ff_cursor_page"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sequences := []int64{}
+	for rows.Next() {
+		var sequence int64
+		if err = rows.Scan(&sequence); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, table)
+		}
+		sequences = append(sequences, sequence)
+	}
+	return sequences, nil
 }
 
 func (s *SQLCommon) insertTx(ctx context.Context, table string, tx *txWrapper, q sq.InsertBuilder, postCommit func()) (int64, error) {
@@ -490,4 +585,8 @@ func (s *SQLCommon) Close() {
 		err := s.db.Close()
 		log.L(context.Background()).Debugf("Database closed (err=%v)", err)
 	}
+	if s.replica != nil {
+		err := s.replica.Close()
+		log.L(context.Background()).Debugf("Database read replica closed (err=%v)", err)
+	}
 }
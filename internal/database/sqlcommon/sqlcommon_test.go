@@ -74,6 +74,13 @@ func TestInitSQLCommonMigrationOpenFailed(t *testing.T) {
 	assert.Regexp(t, "FF10163.*pop", err)
 }
 
+func TestInitSQLCommonFullTextSearchFailed(t *testing.T) {
+	mp := newMockProvider()
+	mp.initFullTextSearchError = fmt.Errorf("pop")
+	err := mp.SQLCommon.Init(context.Background(), mp, mp.config, mp.capabilities)
+	assert.Regexp(t, "FF10112.*pop", err)
+}
+
 func TestMigrationUpDown(t *testing.T) {
 	tp, cleanup := newSQLiteTestProvider(t)
 	defer cleanup()
@@ -82,7 +89,7 @@ func TestMigrationUpDown(t *testing.T) {
 	assert.NoError(t, err)
 	var m *migrate.Migrate
 	m, err = migrate.NewWithDatabaseInstance(
-		"file://../../../db/migrations/sqlite",
+		"file://"+sqliteTestMigrationsDir,
 		tp.MigrationsDir(), driver)
 	assert.NoError(t, err)
 	err = m.Down()
@@ -320,12 +327,80 @@ func TestCountQueryWithExpr(t *testing.T) {
 
 func TestQueryResSwallowError(t *testing.T) {
 	s, _ := newMockProvider().init()
-	res := s.queryRes(context.Background(), "table1", nil, sq.Insert("wrong"), &database.FilterInfo{
+	res, err := s.queryRes(context.Background(), "table1", nil, sq.Insert("wrong"), &database.FilterInfo{
 		Count: true,
 	})
+	assert.NoError(t, err)
 	assert.Equal(t, int64(-1), *res.TotalCount)
 }
 
+func newTestCursorFilterOp() *cursorFilterOp {
+	return &cursorFilterOp{
+		Sqlizer:    sq.Eq{"col1": "val1"},
+		source:     sq.Select().From("table1"),
+		seqField:   "seq",
+		conditions: sq.And{},
+	}
+}
+
+func TestQueryResCursorsFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	mdb.ExpectQuery("^SELECT ff_cursor_page.ff_cursor_seq").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.queryRes(context.Background(), "table1", nil, newTestCursorFilterOp(), &database.FilterInfo{
+		Sort:  []*database.SortField{{Field: "sequence", Descending: true}},
+		Limit: 10,
+	})
+	assert.Regexp(t, "FF10115.*pop", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestQueryResNoCursors(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	res, err := s.queryRes(context.Background(), "table1", nil, sq.Eq{"col1": "val1"}, &database.FilterInfo{
+		Sort:  []*database.SortField{{Field: "sequence", Descending: true}},
+		Limit: 10,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Next)
+	assert.Empty(t, res.Prev)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestPageCursorsFollowingFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	mdb.ExpectQuery("^SELECT ff_cursor_page.ff_cursor_seq").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(10).AddRow(9))
+	mdb.ExpectQuery("^SELECT ff_cursor_page.ff_cursor_seq").WillReturnError(fmt.Errorf("pop"))
+	err := s.pageCursors(context.Background(), "table1", nil, newTestCursorFilterOp(), &database.FilterInfo{
+		Sort:  []*database.SortField{{Field: "sequence", Descending: true}},
+		Limit: 2,
+	}, &database.FilterResult{})
+	assert.Regexp(t, "FF10115.*pop", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestPageCursorsPrecedingFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	mdb.ExpectQuery("^SELECT ff_cursor_page.ff_cursor_seq").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(9).AddRow(10))
+	mdb.ExpectQuery("^SELECT ff_cursor_page.ff_cursor_seq").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(8))
+	mdb.ExpectQuery("^SELECT ff_cursor_page.ff_cursor_seq").WillReturnError(fmt.Errorf("pop"))
+	fr := &database.FilterResult{}
+	err := s.pageCursors(context.Background(), "table1", nil, newTestCursorFilterOp(), &database.FilterInfo{
+		Sort:  []*database.SortField{{Field: "sequence", Descending: true}},
+		Limit: 2,
+		Skip:  2,
+	}, fr)
+	assert.Regexp(t, "FF10115.*pop", err)
+	assert.Equal(t, database.EncodeCursor(9), fr.Next)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestQuerySequencesScanFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	mdb.ExpectQuery("^SELECT ff_cursor_page.ff_cursor_seq").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow("not a number"))
+	_, err := s.querySequences(context.Background(), "table1", nil, newTestCursorFilterOp().sequenceQuery())
+	assert.Regexp(t, "FF10121", err)
+}
+
 func TestDoubleLock(t *testing.T) {
 	s, mdb := newMockProvider().init()
 	mdb.ExpectBegin()
@@ -361,3 +436,63 @@ func TestInsertTxRowsIncompleteReturn(t *testing.T) {
 	err = s.insertTxRows(ctx, "table1", tx, sb, nil, []int64{1, 2}, false)
 	assert.Regexp(t, "FF10116", err)
 }
+
+func TestInitReadReplicaDisabled(t *testing.T) {
+	s, _ := newMockProvider().init()
+	replicaConfig := s.InitReadReplicaConfig(s.config)
+	err := s.InitReadReplica(context.Background(), replicaConfig)
+	assert.NoError(t, err)
+	assert.Nil(t, s.replica)
+	assert.Equal(t, s.db, s.readDB(database.WithReadReplica(context.Background())))
+}
+
+func TestInitReadReplica(t *testing.T) {
+	s, _ := newMockProvider().init()
+	replicaConfig := s.InitReadReplicaConfig(s.config)
+	replicaConfig.Set(SQLConfDatasourceURL, "replica")
+	replicaConfig.Set(SQLConfMaxConnections, 5)
+	err := s.InitReadReplica(context.Background(), replicaConfig)
+	assert.NoError(t, err)
+	assert.NotNil(t, s.replica)
+	s.Close()
+}
+
+func TestInitReadReplicaOpenFailed(t *testing.T) {
+	s, _ := newMockProvider().init()
+	replicaConfig := s.InitReadReplicaConfig(s.config)
+	replicaConfig.Set(SQLConfDatasourceURL, "replica")
+	s.openError = fmt.Errorf("pop")
+	err := s.InitReadReplica(context.Background(), replicaConfig)
+	assert.Regexp(t, "FF10112.*pop", err)
+}
+
+func TestQueryReadReplica(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	replicaDB, mreplica, _ := sqlmock.New()
+	s.replica = replicaDB
+	q := sq.Select(sequenceColumn).From("table1")
+
+	// Only queries with the replica context, outside of a transaction, use the replica
+	mreplica.ExpectQuery("SELECT.*").WillReturnRows(sqlmock.NewRows([]string{sequenceColumn}))
+	mreplica.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mdb.ExpectQuery("SELECT.*").WillReturnRows(sqlmock.NewRows([]string{sequenceColumn}))
+	mdb.ExpectBegin()
+	mdb.ExpectQuery("SELECT.*").WillReturnRows(sqlmock.NewRows([]string{sequenceColumn}))
+	mdb.ExpectCommit()
+
+	ctx := database.WithReadReplica(context.Background())
+	_, err := s.querySequences(ctx, "table1", nil, q)
+	assert.NoError(t, err)
+	_, err = s.countQuery(ctx, "table1", nil, sq.Eq{}, "")
+	assert.NoError(t, err)
+	_, err = s.querySequences(context.Background(), "table1", nil, q)
+	assert.NoError(t, err)
+	err = s.RunAsGroup(ctx, func(ctx context.Context) error {
+		_, err := s.querySequences(ctx, "table1", nil, q)
+		return err
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mreplica.ExpectationsWereMet())
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
@@ -208,7 +208,8 @@ func (s *SQLCommon) GetSubscriptions(ctx context.Context, filter database.Filter
 		subscription = append(subscription, d)
 	}
 
-	return subscription, s.queryRes(ctx, subscriptionsTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, subscriptionsTable, tx, fop, fi)
+	return subscription, fr, err
 
 }
 
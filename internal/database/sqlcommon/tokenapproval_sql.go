@@ -217,7 +217,8 @@ func (s *SQLCommon) GetTokenApprovals(ctx context.Context, filter database.Filte
 		approvals = append(approvals, d)
 	}
 
-	return approvals, s.queryRes(ctx, tokenapprovalTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, tokenapprovalTable, tx, fop, fi)
+	return approvals, fr, err
 }
 
 func (s *SQLCommon) UpdateTokenApprovals(ctx context.Context, filter database.Filter, update database.Update) (err error) {
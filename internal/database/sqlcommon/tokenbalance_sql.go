@@ -125,6 +125,37 @@ func (s *SQLCommon) UpdateTokenBalances(ctx context.Context, transfer *core.Toke
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
+func (s *SQLCommon) InsertTokenBalance(ctx context.Context, balance *core.TokenBalance) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if balance.Updated == nil {
+		balance.Updated = fftypes.Now()
+	}
+	if _, err = s.insertTx(ctx, tokenbalanceTable, tx,
+		sq.Insert(tokenbalanceTable).
+			Columns(tokenBalanceColumns...).
+			Values(
+				balance.Pool,
+				balance.TokenIndex,
+				balance.URI,
+				balance.Connector,
+				balance.Namespace,
+				balance.Key,
+				&balance.Balance,
+				balance.Updated,
+			),
+		nil,
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
 func (s *SQLCommon) tokenBalanceResult(ctx context.Context, row *sql.Rows) (*core.TokenBalance, error) {
 	account := core.TokenBalance{}
 	err := row.Scan(
@@ -197,7 +228,8 @@ func (s *SQLCommon) GetTokenBalances(ctx context.Context, filter database.Filter
 		accounts = append(accounts, d)
 	}
 
-	return accounts, s.queryRes(ctx, tokenbalanceTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, tokenbalanceTable, tx, fop, fi)
+	return accounts, fr, err
 }
 
 func (s *SQLCommon) GetTokenAccounts(ctx context.Context, filter database.Filter) ([]*core.TokenAccount, *database.FilterResult, error) {
@@ -225,7 +257,8 @@ func (s *SQLCommon) GetTokenAccounts(ctx context.Context, filter database.Filter
 		accounts = append(accounts, &account)
 	}
 
-	return accounts, s.queryRes(ctx, tokenbalanceTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, tokenbalanceTable, tx, fop, fi)
+	return accounts, fr, err
 }
 
 func (s *SQLCommon) GetTokenAccountPools(ctx context.Context, key string, filter database.Filter) ([]*core.TokenAccountPool, *database.FilterResult, error) {
@@ -254,5 +287,6 @@ func (s *SQLCommon) GetTokenAccountPools(ctx context.Context, key string, filter
 		pools = append(pools, &pool)
 	}
 
-	return pools, s.queryRes(ctx, tokenbalanceTable, tx, fop, fi), err
+	fr, err := s.queryRes(ctx, tokenbalanceTable, tx, fop, fi)
+	return pools, fr, err
 }
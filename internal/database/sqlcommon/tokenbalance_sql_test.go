@@ -133,6 +133,74 @@ func TestTokenBalanceE2EWithDB(t *testing.T) {
 	assert.Equal(t, *transfer.Pool, *pools[0].Pool)
 }
 
+func TestInsertTokenBalanceE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	balance := &core.TokenBalance{
+		Pool:       fftypes.NewUUID(),
+		TokenIndex: "1",
+		URI:        "firefly://token/1",
+		Connector:  "erc1155",
+		Namespace:  "ns1",
+		Key:        "0x0",
+		Balance:    *fftypes.NewFFBigInt(10),
+		Updated:    fftypes.Now(),
+	}
+	balanceJson, _ := json.Marshal(&balance)
+
+	err := s.InsertTokenBalance(ctx, balance)
+	assert.NoError(t, err)
+
+	balanceRead, err := s.GetTokenBalance(ctx, balance.Pool, "1", "0x0")
+	assert.NoError(t, err)
+	balanceReadJson, _ := json.Marshal(&balanceRead)
+	assert.Equal(t, string(balanceJson), string(balanceReadJson))
+
+	// A subsequent transfer builds on the inserted balance
+	err = s.UpdateTokenBalances(ctx, &core.TokenTransfer{
+		Pool:       balance.Pool,
+		TokenIndex: "1",
+		Namespace:  "ns1",
+		From:       "0x0",
+		Amount:     *fftypes.NewFFBigInt(4),
+	})
+	assert.NoError(t, err)
+	balanceRead, err = s.GetTokenBalance(ctx, balance.Pool, "1", "0x0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), balanceRead.Balance.Int().Int64())
+}
+
+func TestInsertTokenBalanceFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertTokenBalance(context.Background(), &core.TokenBalance{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertTokenBalanceFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertTokenBalance(context.Background(), &core.TokenBalance{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertTokenBalanceFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertTokenBalance(context.Background(), &core.TokenBalance{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateTokenBalancesFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -137,6 +137,10 @@ func (s *SQLCommon) UpsertTokenPool(ctx context.Context, pool *core.TokenPool) (
 		}
 	}
 
+	if err = s.indexSearchRecords(ctx, tx, existing, tokenPoolSearchRecord(pool)); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -225,5 +229,6 @@ func (s *SQLCommon) GetTokenPools(ctx context.Context, filter database.Filter) (
 		pools = append(pools, d)
 	}
 
-	return pools, s.queryRes(ctx, tokenpoolTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, tokenpoolTable, tx, fop, fi)
+	return pools, fr, err
 }
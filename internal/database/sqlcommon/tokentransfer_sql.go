@@ -232,5 +232,6 @@ func (s *SQLCommon) GetTokenTransfers(ctx context.Context, filter database.Filte
 		transfers = append(transfers, d)
 	}
 
-	return transfers, s.queryRes(ctx, tokentransferTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, tokentransferTable, tx, fop, fi)
+	return transfers, fr, err
 }
@@ -135,7 +135,8 @@ func (s *SQLCommon) GetTransactions(ctx context.Context, filter database.Filter)
 		transactions = append(transactions, transaction)
 	}
 
-	return transactions, s.queryRes(ctx, transactionsTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, transactionsTable, tx, fop, fi)
+	return transactions, fr, err
 
 }
 
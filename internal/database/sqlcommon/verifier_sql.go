@@ -189,7 +189,8 @@ func (s *SQLCommon) GetVerifiers(ctx context.Context, filter database.Filter) (v
 		verifiers = append(verifiers, d)
 	}
 
-	return verifiers, s.queryRes(ctx, verifiersTable, tx, fop, fi), err
+	fr, err = s.queryRes(ctx, verifiersTable, tx, fop, fi)
+	return verifiers, fr, err
 
 }
 
@@ -46,8 +46,7 @@ func connHook(conn *sqlite3.SQLiteConn) error {
 	return err
 }
 
-func (sqlite *SQLite3) Init(ctx context.Context, config config.Section) error {
-	capabilities := &database.Capabilities{}
+func registerDriver() {
 	if !ffSQLiteRegistered {
 		sql.Register("sqlite3_ff",
 			&sqlite3.SQLiteDriver{
@@ -55,9 +54,19 @@ func (sqlite *SQLite3) Init(ctx context.Context, config config.Section) error {
 			})
 		ffSQLiteRegistered = true
 	}
+}
+
+func (sqlite *SQLite3) Init(ctx context.Context, config config.Section) error {
+	capabilities := &database.Capabilities{}
+	registerDriver()
 	return sqlite.SQLCommon.Init(ctx, sqlite, config, capabilities)
 }
 
+func (sqlite *SQLite3) InitMigrator(ctx context.Context, config config.Section) error {
+	registerDriver()
+	return sqlite.SQLCommon.InitMigrator(ctx, sqlite, config)
+}
+
 func (sqlite *SQLite3) RegisterListener(listener database.Callbacks) {
 	sqlite.SQLCommon.RegisterListener(listener)
 }
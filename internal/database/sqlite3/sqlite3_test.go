@@ -35,9 +35,10 @@ func TestSQLite3GoProvider(t *testing.T) {
 	sqlite.RegisterListener(&databasemocks.Callbacks{})
 	config := config.RootSection("unittest")
 	sqlite.InitConfig(config)
-	config.Set(sqlcommon.SQLConfDatasourceURL, "!wrong://")
+	config.Set(sqlcommon.SQLConfDatasourceURL, "file::memory:")
 	err := sqlite.Init(context.Background(), config)
 	assert.NoError(t, err)
+	sqlite.DB().Close()
 	_, err = sqlite.GetMigrationDriver(sqlite.DB())
 	assert.Error(t, err)
 
@@ -57,3 +58,27 @@ func TestSQLite3GoProvider(t *testing.T) {
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)", sql)
 	assert.False(t, query)
 }
+
+func TestSQLite3InitMigrator(t *testing.T) {
+	sqlite := &SQLite3{}
+	config := config.RootSection("unittest.migrator")
+	sqlite.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "file::memory:")
+	config.Set(sqlcommon.SQLConfMigrationsDirectory, "../../../db/migrations/sqlite")
+	err := sqlite.InitMigrator(context.Background(), config)
+	assert.NoError(t, err)
+
+	status, err := sqlite.MigrationStatus(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.Equal(t, sqlcommon.SchemaVersion, status.Migrations[len(status.Migrations)-1].Version)
+}
+
+func TestSQLite3InitBadURL(t *testing.T) {
+	sqlite := &SQLite3{}
+	config := config.RootSection("unittest")
+	sqlite.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "!wrong://")
+	err := sqlite.Init(context.Background(), config)
+	assert.Regexp(t, "FF10112", err)
+}
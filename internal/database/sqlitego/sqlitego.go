@@ -0,0 +1,101 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlitego
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	sq "github.com/Masterminds/squirrel"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+
+	// Pure Go translation of the SQLite C library, which does not require CGO
+	moderncsqlite "modernc.org/sqlite"
+)
+
+// SQLiteGo is an embedded SQLite database, using a pure Go implementation of SQLite so
+// that it is available in builds with CGO disabled
+type SQLiteGo struct {
+	sqlcommon.SQLCommon
+}
+
+// connector applies the same per-connection setup as the CGO SQLite plugin on each new connection
+type connector struct {
+	driver *moderncsqlite.Driver
+	url    string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.url)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = conn.(driver.ExecerContext).ExecContext(ctx, "PRAGMA case_sensitive_like=ON;", nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (sqlite *SQLiteGo) Init(ctx context.Context, config config.Section) error {
+	capabilities := &database.Capabilities{}
+	return sqlite.SQLCommon.Init(ctx, sqlite, config, capabilities)
+}
+
+func (sqlite *SQLiteGo) InitMigrator(ctx context.Context, config config.Section) error {
+	return sqlite.SQLCommon.InitMigrator(ctx, sqlite, config)
+}
+
+func (sqlite *SQLiteGo) RegisterListener(listener database.Callbacks) {
+	sqlite.SQLCommon.RegisterListener(listener)
+}
+
+func (sqlite *SQLiteGo) Name() string {
+	return "sqlitego"
+}
+
+func (sqlite *SQLiteGo) MigrationsDir() string {
+	return "sqlite" // Shares the migrations of the CGO SQLite plugin, as the schema is the same
+}
+
+func (sqlite *SQLiteGo) Features() sqlcommon.SQLFeatures {
+	features := sqlcommon.DefaultSQLProviderFeatures()
+	features.PlaceholderFormat = sq.Dollar
+	features.UseILIKE = false // Not supported
+	return features
+}
+
+func (sqlite *SQLiteGo) ApplyInsertQueryCustomizations(insert sq.InsertBuilder, requestConflictEmptyResult bool) (sq.InsertBuilder, bool) {
+	return insert, false
+}
+
+func (sqlite *SQLiteGo) Open(url string) (*sql.DB, error) {
+	return sql.OpenDB(&connector{driver: &moderncsqlite.Driver{}, url: url}), nil
+}
+
+func (sqlite *SQLiteGo) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	return migratesqlite.WithInstance(db, &migratesqlite.Config{})
+}
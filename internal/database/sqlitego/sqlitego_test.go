@@ -0,0 +1,84 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlitego
+
+import (
+	"context"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteGoProvider(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	sqlite.RegisterListener(&databasemocks.Callbacks{})
+	config := config.RootSection("unittest")
+	sqlite.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "file::memory:")
+	err := sqlite.Init(context.Background(), config)
+	assert.NoError(t, err)
+	_, err = sqlite.GetMigrationDriver(sqlite.DB())
+	assert.NoError(t, err)
+
+	db, err := sqlite.Open("file::memory:")
+	assert.NoError(t, err)
+	conn, err := db.Conn(context.Background())
+	assert.NoError(t, err)
+	var caseSensitive bool
+	err = conn.QueryRowContext(context.Background(), "SELECT 'a' LIKE 'A'").Scan(&caseSensitive)
+	assert.NoError(t, err)
+	assert.False(t, caseSensitive)
+	conn.Close()
+
+	assert.Equal(t, "sqlitego", sqlite.Name())
+	assert.Equal(t, "sqlite", sqlite.MigrationsDir())
+	assert.Equal(t, sq.Dollar, sqlite.Features().PlaceholderFormat)
+
+	insert := sq.Insert("test").Columns("col1").Values("val1")
+	insert, query := sqlite.ApplyInsertQueryCustomizations(insert, false)
+	sql, _, err := insert.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)", sql)
+	assert.False(t, query)
+}
+
+func TestSQLiteGoOpenFail(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	db, err := sqlite.Open("/this/path/does/not/exist/firefly.db")
+	assert.NoError(t, err)
+	_, err = db.Conn(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSQLiteGoInitMigrator(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	config := config.RootSection("unittest.migrator")
+	sqlite.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "file::memory:")
+	config.Set(sqlcommon.SQLConfMigrationsDirectory, "../../../db/migrations/sqlite")
+	err := sqlite.InitMigrator(context.Background(), config)
+	assert.NoError(t, err)
+
+	status, err := sqlite.MigrationStatus(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.Equal(t, sqlcommon.SchemaVersion, status.Migrations[len(status.Migrations)-1].Version)
+}
@@ -52,6 +52,13 @@ func (dh *definitionHandlers) persistFFI(ctx context.Context, ffi *core.FFI) (er
 		}
 	}
 
+	for _, errorDef := range ffi.Errors {
+		err := dh.database.UpsertFFIError(ctx, errorDef)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
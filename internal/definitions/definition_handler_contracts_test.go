@@ -72,6 +72,20 @@ func testFFI() *core.FFI {
 				},
 			},
 		},
+		Errors: []*core.FFIError{
+			{
+				ID: fftypes.NewUUID(),
+				FFIErrorDefinition: core.FFIErrorDefinition{
+					Name: "error1",
+					Params: core.FFIParams{
+						{
+							Name:   "code",
+							Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`),
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -100,6 +114,7 @@ func TestHandleFFIBroadcastOk(t *testing.T) {
 	mbi.On("UpsertFFI", mock.Anything, mock.Anything).Return(nil)
 	mbi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(nil)
 	mbi.On("UpsertFFIEvent", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("UpsertFFIError", mock.Anything, mock.Anything).Return(nil)
 	mbi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
 	mcm := dh.contracts.(*contractmocks.Manager)
 	mcm.On("ValidateFFIAndSetPathnames", mock.Anything, mock.Anything).Return(nil)
@@ -180,6 +195,21 @@ func TestPersistFFIUpsertFFIEventFail(t *testing.T) {
 	mcm.AssertExpectations(t)
 }
 
+func TestPersistFFIUpsertFFIErrorFail(t *testing.T) {
+	dh, _ := newTestDefinitionHandler(t)
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("UpsertFFI", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("UpsertFFIEvent", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("UpsertFFIError", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mcm := dh.contracts.(*contractmocks.Manager)
+	mcm.On("ValidateFFIAndSetPathnames", mock.Anything, mock.Anything).Return(nil)
+	err := dh.persistFFI(context.Background(), testFFI())
+	assert.Regexp(t, "pop", err)
+	mbi.AssertExpectations(t)
+	mcm.AssertExpectations(t)
+}
+
 func TestHandleFFIBroadcastValidateFail(t *testing.T) {
 	dh, bs := newTestDefinitionHandler(t)
 	ffi := testFFI()
@@ -40,7 +40,8 @@ import (
 )
 
 const (
-	aggregatorOffsetName = "ff_aggregator"
+	// AggregatorOffsetName is the name of the offset the aggregator stores its progress through the pins under
+	AggregatorOffsetName = "ff_aggregator"
 )
 
 type aggregator struct {
@@ -98,7 +99,7 @@ func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockc
 		firstEvent:       &firstEvent,
 		namespace:        ns,
 		offsetType:       core.OffsetTypeAggregator,
-		offsetName:       aggregatorOffsetName,
+		offsetName:       AggregatorOffsetName,
 		newEventsHandler: ag.processPinsEventsHandler,
 		getItems:         ag.getPins,
 		queryFactory:     database.PinQueryFactory,
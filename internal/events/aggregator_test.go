@@ -710,9 +710,9 @@ func TestAggregationMigratedBroadcastInvalid(t *testing.T) {
 func TestShutdownOnCancel(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	mdi := ag.database.(*databasemocks.Plugin)
-	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, AggregatorOffsetName).Return(&core.Offset{
 		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		Current: 12345,
 		RowID:   333333,
 	}, nil)
@@ -144,3 +144,49 @@ func (em *eventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 		return err != nil, err
 	})
 }
+
+func (em *eventManager) BlockchainEventRemoved(event *blockchain.EventWithSubscription) error {
+	return em.retry.Do(em.ctx, "handle removed blockchain event", func(attempt int) (bool, error) {
+		err := em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
+			// Events that do not match a contract listener are from the FireFly contract, and have no listener
+			var listenerID *fftypes.UUID
+			sub, err := em.getChainListenerByProtocolIDCached(ctx, event.Subscription)
+			if err != nil {
+				return err
+			}
+			if sub != nil {
+				if sub.Namespace != em.namespace {
+					log.L(em.ctx).Debugf("Ignoring removed blockchain event from different namespace '%s'", sub.Namespace)
+					return nil
+				}
+				listenerID = sub.ID
+			}
+
+			existing, err := em.database.GetBlockchainEventByProtocolID(ctx, em.namespace, listenerID, event.ProtocolID)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				log.L(ctx).Debugf("Ignoring removal of unknown blockchain event %s", event.ProtocolID)
+				return nil
+			}
+			log.L(ctx).Warnf("Blockchain event %s (%s) was removed by a chain re-organization", existing.ID, event.ProtocolID)
+
+			topic, err := em.getTopicForChainListener(ctx, listenerID)
+			if err != nil {
+				return err
+			}
+			ffEvent := core.NewEvent(core.EventTypeBlockchainEventRemoved, em.namespace, existing.ID, existing.TX.ID, topic)
+			return em.database.InsertEvent(ctx, ffEvent)
+		})
+		return err != nil, err
+	})
+}
+
+func (em *eventManager) ContractListenerConfirmations(subscription string) (*int, error) {
+	listener, err := em.getChainListenerByProtocolIDCached(em.ctx, subscription)
+	if err != nil || listener == nil || listener.Namespace != em.namespace || listener.Options == nil {
+		return nil, err
+	}
+	return listener.Options.Confirmations, nil
+}
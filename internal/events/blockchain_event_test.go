@@ -263,3 +263,205 @@ func TestBlockchainEventMetric(t *testing.T) {
 	em.emitBlockchainEventMetric(&event)
 	mm.AssertExpectations(t)
 }
+
+func TestBlockchainEventRemoved(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			BlockchainTXID: "0xabcd1234",
+			ProtocolID:     "10/20/30",
+			Name:           "Changed",
+		},
+	}
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+		Topic:     "topic1",
+	}
+	existing := &core.BlockchainEvent{
+		ID: fftypes.NewUUID(),
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-1").Return(sub, nil)
+	mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", sub.ID, ev.ProtocolID).Return(nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", sub.ID, ev.ProtocolID).Return(existing, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, sub.ID).Return(sub, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeBlockchainEventRemoved && e.Reference == existing.ID && e.Topic == "topic1"
+	})).Return(nil).Once()
+
+	err := em.BlockchainEventRemoved(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBlockchainEventRemovedBatchPin(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-firefly",
+		Event: blockchain.Event{
+			BlockchainTXID: "0xabcd1234",
+			ProtocolID:     "10/20/30",
+			Name:           "BatchPin",
+		},
+	}
+	existing := &core.BlockchainEvent{
+		ID: fftypes.NewUUID(),
+		TX: core.BlockchainTransactionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-firefly").Return(nil, nil)
+	mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", (*fftypes.UUID)(nil), ev.ProtocolID).Return(existing, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeBlockchainEventRemoved && e.Reference == existing.ID && e.Transaction == existing.TX.ID && e.Topic == core.SystemBatchPinTopic
+	})).Return(nil).Once()
+
+	err := em.BlockchainEventRemoved(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBlockchainEventRemovedUnknownEvent(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			ProtocolID: "10/20/30",
+		},
+	}
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-1").Return(sub, nil)
+	mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", sub.ID, ev.ProtocolID).Return(nil, nil)
+
+	err := em.BlockchainEventRemoved(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBlockchainEventRemovedWrongNS(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			ProtocolID: "10/20/30",
+		},
+	}
+	sub := &core.ContractListener{
+		Namespace: "ns2",
+		ID:        fftypes.NewUUID(),
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-1").Return(nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-1").Return(sub, nil)
+
+	err := em.BlockchainEventRemoved(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBlockchainEventRemovedTopicFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			ProtocolID: "10/20/30",
+		},
+	}
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-1").Return(sub, nil)
+	mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", sub.ID, ev.ProtocolID).Return(&core.BlockchainEvent{ID: fftypes.NewUUID()}, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, sub.ID).Return(nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetContractListenerByID", mock.Anything, sub.ID).Return(sub, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeBlockchainEventRemoved && e.Topic == sub.ID.String()
+	})).Return(nil).Once()
+
+	err := em.BlockchainEventRemoved(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestContractListenerConfirmations(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	confirmations := 5
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+		Options: &core.ContractListenerOptions{
+			Confirmations: &confirmations,
+		},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-1").Return(sub, nil)
+
+	result, err := em.ContractListenerConfirmations("sb-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, *result)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestContractListenerConfirmationsNotSet(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	sub := &core.ContractListener{
+		Namespace: "ns2",
+		ID:        fftypes.NewUUID(),
+		Options: &core.ContractListenerOptions{
+			Confirmations: new(int),
+		},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-1").Return(sub, nil)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-2").Return(nil, nil)
+	mdi.On("GetContractListenerByBackendID", mock.Anything, "sb-3").Return(nil, fmt.Errorf("pop"))
+
+	result, err := em.ContractListenerConfirmations("sb-1")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	result, err = em.ContractListenerConfirmations("sb-2")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	_, err = em.ContractListenerConfirmations("sb-3")
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
@@ -34,10 +34,18 @@ func (bc *boundCallbacks) EphemeralSubscription(connID, namespace string, filter
 	return bc.sm.ephemeralSubscription(bc.ei, connID, namespace, filter, options)
 }
 
+func (bc *boundCallbacks) RewindSubscription(namespace, name string, sequence int64) error {
+	return bc.sm.rewindDurableSubscription(bc.ei, namespace, name, sequence)
+}
+
 func (bc *boundCallbacks) DeliveryResponse(connID string, inflight *core.EventDeliveryResponse) {
 	bc.sm.deliveryResponse(bc.ei, connID, inflight)
 }
 
+func (bc *boundCallbacks) DeadLetter(connID string, deadLetter *core.DeadLetter) error {
+	return bc.sm.deadLetter(bc.ei, connID, deadLetter)
+}
+
 func (bc *boundCallbacks) ConnectionClosed(connID string) {
 	bc.sm.connectionClosed(bc.ei, connID)
 }
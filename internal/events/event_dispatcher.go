@@ -17,9 +17,14 @@
 package events
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"math/big"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -30,6 +35,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -38,7 +44,8 @@ import (
 )
 
 const (
-	maxReadAhead = 65536
+	maxReadAhead        = 65536
+	maxPartitionWorkers = 32
 )
 
 type ackNack struct {
@@ -49,27 +56,35 @@ type ackNack struct {
 
 type eventDispatcher struct {
 	acksNacks     chan ackNack
+	batch         bool
+	batchTimeout  time.Duration
 	cancelCtx     func()
 	closed        chan struct{}
 	connID        string
 	ctx           context.Context
 	data          data.Manager
 	database      database.Plugin
+	metrics       metrics.Manager
 	transport     events.Plugin
 	broadcast     broadcast.Manager
 	messaging     privatemessaging.Manager
 	elected       bool
 	eventPoller   *eventPoller
 	inflight      map[fftypes.UUID]*core.Event
+	deliveryTimes map[fftypes.UUID]time.Time
 	eventDelivery chan *core.EventDelivery
+	highestSeen   int64
+	lowestQueued  int64
 	mux           sync.Mutex
 	namespace     string
+	partitionBy   core.SubOptsPartitionBy
+	partitionKeys map[fftypes.UUID]string
 	readAhead     int
 	subscription  *subscription
 	txHelper      txcommon.Helper
 }
 
-func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, connID string, sub *subscription, en *eventNotifier, txHelper txcommon.Helper) *eventDispatcher {
+func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, mm metrics.Manager, connID string, sub *subscription, en *eventNotifier, txHelper txcommon.Helper) *eventDispatcher {
 	ctx, cancelCtx := context.WithCancel(ctx)
 	readAhead := config.GetUint(coreconfig.SubscriptionDefaultsReadAhead)
 	if sub.definition.Options.ReadAhead != nil {
@@ -78,11 +93,24 @@ func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugi
 	if readAhead > maxReadAhead {
 		readAhead = maxReadAhead
 	}
+	batch := sub.definition.Options.Batch != nil && *sub.definition.Options.Batch && ei.Capabilities().BatchDelivery
+	batchTimeout := config.GetDuration(coreconfig.SubscriptionDefaultsBatchTimeout)
+	if sub.definition.Options.BatchTimeout != nil {
+		// Validated when the subscription was created
+		if d, err := fftypes.ParseDurationString(*sub.definition.Options.BatchTimeout, time.Millisecond); err == nil {
+			batchTimeout = time.Duration(d)
+		}
+	}
+	var partitionBy core.SubOptsPartitionBy
+	if sub.definition.Options.PartitionBy != nil {
+		partitionBy = *sub.definition.Options.PartitionBy
+	}
 	ed := &eventDispatcher{
 		ctx: log.WithLogField(log.WithLogField(ctx,
 			"role", fmt.Sprintf("ed[%s]", connID)),
 			"sub", fmt.Sprintf("%s/%s:%s", sub.definition.ID, sub.definition.Namespace, sub.definition.Name)),
 		database:      di,
+		metrics:       mm,
 		transport:     ei,
 		broadcast:     bm,
 		messaging:     pm,
@@ -92,8 +120,15 @@ func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugi
 		subscription:  sub,
 		namespace:     sub.definition.Namespace,
 		inflight:      make(map[fftypes.UUID]*core.Event),
+		deliveryTimes: make(map[fftypes.UUID]time.Time),
+		highestSeen:   -1,
+		lowestQueued:  -1,
+		partitionBy:   partitionBy,
+		partitionKeys: make(map[fftypes.UUID]string),
 		eventDelivery: make(chan *core.EventDelivery, readAhead+1),
 		readAhead:     int(readAhead),
+		batch:         batch,
+		batchTimeout:  batchTimeout,
 		acksNacks:     make(chan ackNack),
 		closed:        make(chan struct{}),
 		txHelper:      txHelper,
@@ -148,9 +183,13 @@ func (ed *eventDispatcher) electAndStart() {
 	// We're ready to go - not
 	ed.elected = true
 	ed.eventPoller.start()
-	go ed.deliverEvents()
-	// Wait until the event poller closes
-	<-ed.eventPoller.closed
+	if ed.batch {
+		go ed.deliverBatchedEvents()
+	} else {
+		go ed.deliverEvents()
+	}
+	// Wait until the event poller closes, and has finished committing its offset
+	ed.eventPoller.waitStopped()
 }
 
 func (ed *eventDispatcher) getEvents(ctx context.Context, filter database.Filter, offset int64) ([]core.LocallySequenced, error) {
@@ -179,7 +218,7 @@ func (ed *eventDispatcher) enrichEvents(events []core.LocallySequenced) ([]*core
 	return enriched, nil
 }
 
-func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) []*core.EventDelivery {
+func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) ([]*core.EventDelivery, error) {
 	matchingEvents := make([]*core.EventDelivery, 0, len(candidates))
 	for _, event := range candidates {
 		filter := ed.subscription
@@ -197,6 +236,10 @@ func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) []*cor
 		txType := ""
 		beName := ""
 		beListener := ""
+		tokenPool := ""
+		tokenFrom := ""
+		tokenTo := ""
+		var tokenAmount *big.Int
 
 		if msg != nil {
 			tag = msg.Header.Tag
@@ -215,6 +258,21 @@ func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) []*cor
 			beListener = be.Listener.String()
 		}
 
+		if event.TokenPool != nil && event.TokenPool.ID != nil {
+			tokenPool = event.TokenPool.ID.String()
+		}
+		if event.TokenApproval != nil && event.TokenApproval.Pool != nil {
+			tokenPool = event.TokenApproval.Pool.String()
+		}
+		if tt := event.TokenTransfer; tt != nil {
+			if tt.Pool != nil {
+				tokenPool = tt.Pool.String()
+			}
+			tokenFrom = tt.From
+			tokenTo = tt.To
+			tokenAmount = tt.Amount.Int()
+		}
+
 		if filter.topicFilter != nil {
 			topicsMatch := false
 			if filter.topicFilter.MatchString(topic) {
@@ -252,9 +310,75 @@ func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) []*cor
 			}
 		}
 
+		if filter.tokenFilter != nil {
+			if filter.tokenFilter.poolFilter != nil && !filter.tokenFilter.poolFilter.MatchString(tokenPool) {
+				continue
+			}
+			if filter.tokenFilter.fromFilter != nil && !filter.tokenFilter.fromFilter.MatchString(tokenFrom) {
+				continue
+			}
+			if filter.tokenFilter.toFilter != nil && !filter.tokenFilter.toFilter.MatchString(tokenTo) {
+				continue
+			}
+			// Amount thresholds only match token transfers
+			if filter.tokenFilter.minAmount != nil && (tokenAmount == nil || tokenAmount.Cmp(filter.tokenFilter.minAmount) < 0) {
+				continue
+			}
+			if filter.tokenFilter.maxAmount != nil && (tokenAmount == nil || tokenAmount.Cmp(filter.tokenFilter.maxAmount) > 0) {
+				continue
+			}
+		}
+
+		// The data filter is checked last, as it might require the data to be loaded
+		if filter.dataFilter != nil {
+			match, err := ed.matchMessageData(msg)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+		}
+
 		matchingEvents = append(matchingEvents, event)
 	}
-	return matchingEvents
+	return matchingEvents, nil
+}
+
+// matchMessageData evaluates the JSONPath of the data filter against the value of the first data item in the message
+func (ed *eventDispatcher) matchMessageData(msg *core.Message) (bool, error) {
+	if msg == nil {
+		return false, nil
+	}
+	data, _, err := ed.data.GetMessageDataCached(ed.ctx, msg)
+	if err != nil {
+		return false, err
+	}
+	if len(data) == 0 || data[0].Value == nil {
+		return false, nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data[0].Value.Bytes()))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		log.L(ed.ctx).Debugf("Data '%s' is not JSON: %s", data[0].ID, err)
+		return false, nil
+	}
+
+	selected, ok := ed.subscription.dataFilter.jsonPath.evaluate(value)
+	if !ok || selected == nil {
+		return false, nil
+	}
+	if ed.subscription.dataFilter.valueFilter == nil {
+		return true, nil
+	}
+	strValue, isString := selected.(string)
+	if !isString {
+		b, _ := json.Marshal(selected)
+		strValue = string(b)
+	}
+	return ed.subscription.dataFilter.valueFilter.MatchString(strValue), nil
 }
 
 func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (bool, error) {
@@ -266,6 +390,9 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		return false, nil
 	}
 	highestOffset := events[len(events)-1].LocalSequence()
+	if highestOffset > ed.highestSeen {
+		ed.highestSeen = highestOffset
+	}
 	var lastAck int64
 	var nacks int
 
@@ -275,7 +402,10 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		return false, err
 	}
 
-	matching := ed.filterEvents(candidates)
+	matching, err := ed.filterEvents(candidates)
+	if err != nil {
+		return false, err
+	}
 	matchCount := len(matching)
 	dispatched := 0
 
@@ -286,12 +416,10 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		var disapatchable []*core.EventDelivery
 		inflightCount := len(ed.inflight)
 		maxDispatch := 1 + ed.readAhead - inflightCount
-		if maxDispatch >= len(matching) {
-			disapatchable = matching
-			matching = nil
-		} else if maxDispatch > 0 {
-			disapatchable = matching[0:maxDispatch]
-			matching = matching[maxDispatch:]
+		disapatchable, matching = ed.selectDispatchable(matching, maxDispatch)
+		ed.lowestQueued = -1
+		if len(matching) > 0 {
+			ed.lowestQueued = matching[0].Sequence
 		}
 		ed.mux.Unlock()
 
@@ -301,12 +429,19 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		for _, event := range disapatchable {
 			ed.mux.Lock()
 			ed.inflight[*event.ID] = &event.Event
+			ed.deliveryTimes[*event.ID] = time.Now()
+			if ed.partitionBy != "" {
+				ed.partitionKeys[*event.ID] = ed.partitionKey(event)
+			}
 			inflightCount = len(ed.inflight)
 			ed.mux.Unlock()
 
 			dispatched++
 			ed.eventDelivery <- event
 		}
+		if len(disapatchable) > 0 {
+			ed.reportDelivered(len(disapatchable), inflightCount)
+		}
 
 		if inflightCount == 0 {
 			// We've cleared the decks. Time to look for more messages
@@ -330,9 +465,61 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 	if nacks == 0 && lastAck != highestOffset {
 		ed.eventPoller.commitOffset(highestOffset)
 	}
+	ed.reportOffsetLag()
 	return true, nil // poll again straight away for more messages
 }
 
+// selectDispatchable takes up to maxDispatch events from the front of the queue, and returns them along with
+// the events that remain queued. When the subscription is partitioned, an event is only dispatched if there
+// are no earlier events with the same key still in flight or queued - so order is preserved within each key,
+// while events for different keys are in flight in parallel.
+// Must be called with the mutex held.
+func (ed *eventDispatcher) selectDispatchable(queued []*core.EventDelivery, maxDispatch int) (dispatchable, remaining []*core.EventDelivery) {
+	if maxDispatch <= 0 {
+		return nil, queued
+	}
+	if ed.partitionBy == "" {
+		if maxDispatch >= len(queued) {
+			return queued, nil
+		}
+		return queued[0:maxDispatch], queued[maxDispatch:]
+	}
+
+	busyKeys := make(map[string]bool, len(ed.inflight))
+	for id := range ed.inflight {
+		busyKeys[ed.partitionKeys[id]] = true
+	}
+	remaining = make([]*core.EventDelivery, 0, len(queued))
+	for _, event := range queued {
+		key := ed.partitionKey(event)
+		if len(dispatchable) < maxDispatch && !busyKeys[key] {
+			dispatchable = append(dispatchable, event)
+		} else {
+			remaining = append(remaining, event)
+		}
+		busyKeys[key] = true
+	}
+	return dispatchable, remaining
+}
+
+// partitionKey returns the key used to order delivery of an event on a partitioned subscription.
+// Events without a value for the key (such as non-message events, for author) are delivered in order together.
+func (ed *eventDispatcher) partitionKey(event *core.EventDelivery) string {
+	switch ed.partitionBy {
+	case core.SubOptsPartitionByTopic:
+		return event.Topic
+	case core.SubOptsPartitionByAuthor:
+		if event.Message != nil {
+			return event.Message.Header.Author
+		}
+	case core.SubOptsPartitionByGroup:
+		if event.Message != nil && event.Message.Header.Group != nil {
+			return event.Message.Header.Group.String()
+		}
+	}
+	return ""
+}
+
 func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 	ed.mux.Lock()
 	defer ed.mux.Unlock()
@@ -340,52 +527,187 @@ func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 	// even if we've delivered messages after that.
 	// That means resetting the polling offest, and clearing out all our state
 	delete(ed.inflight, nack.id)
-	if ed.eventPoller.pollingOffset > nack.offset {
-		ed.eventPoller.rewindPollingOffset(nack.offset - 1)
+	rewindTo := nack.offset
+	if ed.partitionBy != "" {
+		// Events for other keys might still be in flight with a lower sequence,
+		// and they must be redelivered too as we are about to forget about them
+		for _, inflight := range ed.inflight {
+			if inflight.Sequence < rewindTo {
+				rewindTo = inflight.Sequence
+			}
+		}
+	}
+	if ed.eventPoller.pollingOffset > rewindTo {
+		ed.eventPoller.rewindPollingOffset(rewindTo - 1)
 	}
 	ed.inflight = map[fftypes.UUID]*core.Event{}
+	ed.deliveryTimes = map[fftypes.UUID]time.Time{}
+	ed.partitionKeys = map[fftypes.UUID]string{}
+	ed.reportInflight(0)
 }
 
 func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 	oldOffset := ed.eventPoller.getPollingOffset()
 	ed.mux.Lock()
 	delete(ed.inflight, ack.id)
+	delete(ed.partitionKeys, ack.id)
 	lowestInflight := int64(-1)
 	for _, inflight := range ed.inflight {
 		if lowestInflight < 0 || inflight.Sequence < lowestInflight {
 			lowestInflight = inflight.Sequence
 		}
 	}
+	// On a partitioned subscription there can be queued events, with a lower sequence than an event
+	// that has been acknowledged, so we must not move the offset past those either
+	if ed.lowestQueued >= 0 && (lowestInflight < 0 || ed.lowestQueued < lowestInflight) {
+		lowestInflight = ed.lowestQueued
+	}
+	inflightCount := len(ed.inflight)
 	ed.mux.Unlock()
+	ed.reportInflight(inflightCount)
 	if (lowestInflight == -1 || lowestInflight > ack.offset) && ack.offset > oldOffset {
 		// This was the lowest in flight, and we can move the offset forwards
 		ed.eventPoller.commitOffset(ack.offset)
+		ed.reportOffsetLag()
 	}
 }
 
 func (ed *eventDispatcher) deliverEvents() {
 	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
+	var workers []chan *core.EventDelivery
+	if ed.partitionBy != "" {
+		// Events with different keys are delivered in parallel by a pool of workers. All events with
+		// the same key go to the same worker, and only one of them is in flight at a time.
+		workerCount := ed.readAhead + 1
+		if workerCount > maxPartitionWorkers {
+			workerCount = maxPartitionWorkers
+		}
+		workers = make([]chan *core.EventDelivery, workerCount)
+		for i := range workers {
+			workers[i] = make(chan *core.EventDelivery, ed.readAhead+1)
+			go ed.deliveryWorker(workers[i], withData)
+		}
+		defer func() {
+			for _, w := range workers {
+				close(w)
+			}
+		}()
+	}
+	for {
+		select {
+		case event, ok := <-ed.eventDelivery:
+			if !ok {
+				return
+			}
+			if workers != nil {
+				workers[partitionWorker(ed.partitionKey(event), len(workers))] <- event
+			} else {
+				ed.deliverEvent(event, withData)
+			}
+		case <-ed.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliveryWorker delivers the events for a subset of the partition keys of a subscription, in order
+func (ed *eventDispatcher) deliveryWorker(events <-chan *core.EventDelivery, withData bool) {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			ed.deliverEvent(event, withData)
+		case <-ed.ctx.Done():
+			return
+		}
+	}
+}
+
+// partitionWorker maps a partition key to one of the delivery workers
+func partitionWorker(key string, workerCount int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(workerCount))
+}
+
+func (ed *eventDispatcher) deliverEvent(event *core.EventDelivery, withData bool) {
+	log.L(ed.ctx).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
+	var data []*core.Data
+	var err error
+	if withData && event.Message != nil {
+		data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
+	}
+	if err == nil {
+		err = ed.transport.DeliveryRequest(ed.ctx, ed.connID, ed.subscription.definition, event, data)
+	}
+	if err != nil {
+		ed.deliveryResponse(&core.EventDeliveryResponse{ID: event.ID, Rejected: true})
+	}
+}
+
+// deliverBatchedEvents accumulates events into batches of up to readAhead events, or whatever has arrived
+// within the batch timeout of the first event in the batch, and hands each batch to the transport
+func (ed *eventDispatcher) deliverBatchedEvents() {
+	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
+	batchSize := ed.readAhead
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	var batch []*core.CombinedEventDataDelivery
+	var batchTimer *time.Timer
+	var batchTimeout <-chan time.Time
+	defer func() {
+		if batchTimer != nil {
+			batchTimer.Stop()
+		}
+	}()
 	for {
+		timedOut := false
 		select {
 		case event, ok := <-ed.eventDelivery:
 			if !ok {
 				return
 			}
-			log.L(ed.ctx).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
+			log.L(ed.ctx).Debugf("Batching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
 			var data []*core.Data
 			var err error
 			if withData && event.Message != nil {
 				data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
 			}
-			if err == nil {
-				err = ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, event, data)
-			}
 			if err != nil {
 				ed.deliveryResponse(&core.EventDeliveryResponse{ID: event.ID, Rejected: true})
+				continue
 			}
+			if len(batch) == 0 {
+				batchTimer = time.NewTimer(ed.batchTimeout)
+				batchTimeout = batchTimer.C
+			}
+			batch = append(batch, &core.CombinedEventDataDelivery{Event: event, Data: data})
+		case <-batchTimeout:
+			timedOut = true
 		case <-ed.ctx.Done():
 			return
 		}
+
+		if len(batch) >= batchSize || (timedOut && len(batch) > 0) {
+			batchTimer.Stop()
+			batchTimer = nil
+			batchTimeout = nil
+			ed.deliverBatch(batch)
+			batch = nil
+		}
+	}
+}
+
+func (ed *eventDispatcher) deliverBatch(batch []*core.CombinedEventDataDelivery) {
+	log.L(ed.ctx).Debugf("Dispatching batch of %d %s events", len(batch), ed.transport.Name())
+	err := ed.transport.BatchDeliveryRequest(ed.connID, ed.subscription.definition, batch)
+	if err != nil {
+		for _, e := range batch {
+			ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID, Rejected: true})
+		}
 	}
 }
 
@@ -394,11 +716,14 @@ func (ed *eventDispatcher) deliveryResponse(response *core.EventDeliveryResponse
 
 	ed.mux.Lock()
 	var an ackNack
+	var deliveredAt time.Time
 	event, found := ed.inflight[*response.ID]
 	if found {
 		an.id = *response.ID
 		an.offset = event.Sequence
 		an.isNack = response.Rejected
+		deliveredAt = ed.deliveryTimes[*response.ID]
+		delete(ed.deliveryTimes, *response.ID)
 	}
 	ed.mux.Unlock()
 
@@ -407,6 +732,7 @@ func (ed *eventDispatcher) deliveryResponse(response *core.EventDeliveryResponse
 		l.Warnf("Response for event not in flight: %s rejected=%t info='%s' (likely previous reject)", response.ID, response.Rejected, response.Info)
 		return
 	}
+	ed.reportResponse(response.Rejected, time.Since(deliveredAt))
 
 	// We might have a message to send, do that before we dispatch the ack
 	// Note a failure to send the reply does not invalidate the ack
@@ -432,5 +758,48 @@ func (ed *eventDispatcher) close() {
 	if ed.elected {
 		close(ed.eventDelivery)
 		ed.elected = false
+		ed.reportInflight(0)
+	}
+}
+
+func (ed *eventDispatcher) metricsLabel() string {
+	return metrics.SubscriptionLabel(ed.subscription.definition)
+}
+
+func (ed *eventDispatcher) reportDelivered(count, inflightCount int) {
+	if ed.metrics.IsMetricsEnabled() {
+		ed.metrics.SubscriptionEventsDelivered(ed.namespace, ed.metricsLabel(), ed.transport.Name(), count)
+		ed.reportInflight(inflightCount)
+	}
+}
+
+func (ed *eventDispatcher) reportResponse(rejected bool, elapsed time.Duration) {
+	if ed.metrics.IsMetricsEnabled() {
+		if rejected {
+			ed.metrics.SubscriptionEventNacked(ed.namespace, ed.metricsLabel(), ed.transport.Name(), elapsed)
+		} else {
+			ed.metrics.SubscriptionEventAcked(ed.namespace, ed.metricsLabel(), ed.transport.Name(), elapsed)
+		}
+	}
+}
+
+// The gauges below are only meaningful for a single dispatcher, so are not reported for ephemeral subscriptions
+
+func (ed *eventDispatcher) reportInflight(count int) {
+	if !ed.subscription.definition.Ephemeral && ed.metrics.IsMetricsEnabled() {
+		ed.metrics.SubscriptionEventsInflight(ed.namespace, ed.metricsLabel(), ed.transport.Name(), count)
+	}
+}
+
+// reportOffsetLag reports the distance from the polling offset to the latest event we know of in the namespace,
+// which is either one we have read, or one we have been notified about
+func (ed *eventDispatcher) reportOffsetLag() {
+	if !ed.subscription.definition.Ephemeral && ed.metrics.IsMetricsEnabled() {
+		latest := ed.eventPoller.eventNotifier.getLatestSequence()
+		if ed.highestSeen > latest {
+			latest = ed.highestSeen
+		}
+		lag := calcOffsetLag(ed.eventPoller.getPollingOffset(), latest)
+		ed.metrics.SubscriptionOffsetLag(ed.namespace, ed.metricsLabel(), ed.transport.Name(), lag)
 	}
 }
@@ -19,8 +19,10 @@ package events
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -31,6 +33,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -48,9 +51,11 @@ func newTestEventDispatcher(sub *subscription) (*eventDispatcher, func()) {
 	mdm := &datamocks.Manager{}
 	mbm := &broadcastmocks.Manager{}
 	mpm := &privatemessagingmocks.Manager{}
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false).Maybe()
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	ctx, cancel := context.WithCancel(context.Background())
-	return newEventDispatcher(ctx, mei, mdi, mdm, mbm, mpm, fftypes.NewUUID().String(), sub, newEventNotifier(ctx, "ut"), txHelper), func() {
+	return newEventDispatcher(ctx, mei, mdi, mdm, mbm, mpm, mmi, fftypes.NewUUID().String(), sub, newEventNotifier(ctx, "ut"), txHelper), func() {
 		cancel()
 		coreconfig.Reset()
 	}
@@ -102,6 +107,35 @@ func TestMaxReadAhead(t *testing.T) {
 	assert.Equal(t, int(65536), ed.readAhead)
 }
 
+func TestEventDispatcherBatchOptions(t *testing.T) {
+	coreconfig.Reset()
+	mei := &eventsmocks.Plugin{}
+	mei.On("Capabilities").Return(&events.Capabilities{BatchDelivery: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	yes := true
+	batchTimeout := "250ms"
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					Batch:        &yes,
+					BatchTimeout: &batchTimeout,
+				},
+			},
+		},
+	}
+	ed := newEventDispatcher(ctx, mei, &databasemocks.Plugin{}, &datamocks.Manager{}, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{}, &metricsmocks.Manager{}, "conn1", sub, newEventNotifier(ctx, "ut"), nil)
+	assert.True(t, ed.batch)
+	assert.Equal(t, 250*time.Millisecond, ed.batchTimeout)
+
+	sub.definition.Options.BatchTimeout = nil
+	ed = newEventDispatcher(ctx, mei, &databasemocks.Plugin{}, &datamocks.Manager{}, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{}, &metricsmocks.Manager{}, "conn1", sub, newEventNotifier(ctx, "ut"), nil)
+	assert.Equal(t, 50*time.Millisecond, ed.batchTimeout)
+}
+
 func TestEventDispatcherLeaderElection(t *testing.T) {
 	log.SetLevel("debug")
 
@@ -168,9 +202,9 @@ func TestEventDispatcherReadAheadOutOfOrderAcks(t *testing.T) {
 	mdm := ed.data.(*datamocks.Manager)
 
 	eventDeliveries := make(chan *core.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*core.EventDelivery)
+		eventDeliveries <- a.Get(3).(*core.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -263,9 +297,9 @@ func TestEventDispatcherNoReadAheadInOrder(t *testing.T) {
 	mei := ed.transport.(*eventsmocks.Plugin)
 
 	eventDeliveries := make(chan *core.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*core.EventDelivery)
+		eventDeliveries <- a.Get(3).(*core.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -334,6 +368,97 @@ func TestEventDispatcherNoReadAheadInOrder(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestEventDispatcherMetrics(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options:         core.SubscriptionOptions{},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdm := ed.data.(*datamocks.Manager)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mmi := &metricsmocks.Manager{}
+	ed.metrics = mmi
+
+	eventDeliveries := make(chan *core.EventDelivery)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock.RunFn = func(a mock.Arguments) {
+		eventDeliveries <- a.Get(3).(*core.EventDelivery)
+	}
+
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionEventsDelivered", "ns1", "sub1", "ut", 1).Return().Twice()
+	mmi.On("SubscriptionEventsInflight", "ns1", "sub1", "ut", 1).Return().Twice()
+	mmi.On("SubscriptionEventsInflight", "ns1", "sub1", "ut", 0).Return().Twice()
+	mmi.On("SubscriptionEventAcked", "ns1", "sub1", "ut", mock.AnythingOfType("time.Duration")).Return().Once()
+	mmi.On("SubscriptionEventNacked", "ns1", "sub1", "ut", mock.AnythingOfType("time.Duration")).Return().Once()
+	mmi.On("SubscriptionOffsetLag", "ns1", "sub1", "ut", int64(1)).Return().Twice()
+
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+	ref2 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&core.Message{
+		Header: core.MessageHeader{ID: ref1},
+	}, nil, true, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref2).Return(&core.Message{
+		Header: core.MessageHeader{ID: ref2},
+	}, nil, true, nil)
+
+	batchDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]core.LocallySequenced{
+			&core.Event{ID: ev1, Sequence: 10000001, Reference: ref1, Type: core.EventTypeMessageConfirmed},
+			&core.Event{ID: ev2, Sequence: 10000002, Reference: ref2, Type: core.EventTypeMessageConfirmed},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(batchDone)
+	}()
+
+	// Ack the first event, which moves the offset one behind the highest event we've read
+	event1 := <-eventDeliveries
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: event1.ID})
+
+	// Nack the second event, which leaves the offset where it is
+	event2 := <-eventDeliveries
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: event2.ID, Rejected: true})
+
+	<-batchDone
+
+	mmi.AssertExpectations(t)
+}
+
+func TestEventDispatcherMetricsEphemeral(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "bd5b84e5-f6ab-4a1e-a2ae-4c2a0a1b2c3d"},
+			Ephemeral:       true,
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mmi := &metricsmocks.Manager{}
+	ed.metrics = mmi
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionEventsDelivered", "ns1", "ephemeral", "ut", 5).Return()
+	mmi.On("SubscriptionEventAcked", "ns1", "ephemeral", "ut", time.Second).Return()
+
+	ed.reportDelivered(5, 5)
+	ed.reportResponse(false, time.Second)
+	ed.reportInflight(0)
+	ed.reportOffsetLag()
+
+	mmi.AssertExpectations(t)
+}
+
 func TestEnrichEventsFailGetMessages(t *testing.T) {
 
 	sub := &subscription{
@@ -404,7 +529,7 @@ func TestFilterEventsMatch(t *testing.T) {
 	id5 := fftypes.NewUUID()
 	id6 := fftypes.NewUUID()
 	lid := fftypes.NewUUID()
-	events := ed.filterEvents([]*core.EventDelivery{
+	events, err := ed.filterEvents([]*core.EventDelivery{
 		{
 			EnrichedEvent: core.EnrichedEvent{
 				Event: core.Event{
@@ -499,12 +624,14 @@ func TestFilterEventsMatch(t *testing.T) {
 			},
 		},
 	})
+	assert.NoError(t, err)
 
 	ed.subscription.eventMatcher = regexp.MustCompile(fmt.Sprintf("^%s$", core.EventTypeMessageConfirmed))
 	ed.subscription.topicFilter = regexp.MustCompile(".*")
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile(".*")
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile(".*")
-	matched := ed.filterEvents(events)
+	matched, err := ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 2, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
@@ -514,7 +641,8 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = nil
 	ed.subscription.messageFilter.groupFilter = nil
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 6, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
@@ -523,46 +651,53 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id5, *matched[4].ID)
 
 	ed.subscription.topicFilter = regexp.MustCompile("topic1")
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 2, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
 
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile("tag2")
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.authorFilter = nil
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile(gid1.String())
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile("^$")
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 0, len(matched))
 
 	ed.subscription.messageFilter.groupFilter = nil
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = nil
 	ed.subscription.messageFilter.authorFilter = regexp.MustCompile("org2")
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.messageFilter = nil
 	ed.subscription.transactionFilter.typeFilter = regexp.MustCompile(fmt.Sprintf("^%s$", core.TransactionTypeBatchPin))
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id5, *matched[0].ID)
 
 	ed.subscription.messageFilter = nil
 	ed.subscription.transactionFilter = nil
 	ed.subscription.blockchainFilter.nameFilter = regexp.MustCompile("flapflip")
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id4, *matched[0].ID)
 
@@ -570,11 +705,201 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.transactionFilter = nil
 	ed.subscription.blockchainFilter.nameFilter = nil
 	ed.subscription.blockchainFilter.listenerFilter = regexp.MustCompile(lid.String())
-	matched = ed.filterEvents(events)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id6, *matched[0].ID)
 }
 
+func TestFilterEventsToken(t *testing.T) {
+	sub := &subscription{
+		definition:  &core.Subscription{},
+		tokenFilter: &tokenFilter{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	pool1 := fftypes.NewUUID()
+	pool2 := fftypes.NewUUID()
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	id3 := fftypes.NewUUID()
+	id4 := fftypes.NewUUID()
+	events := []*core.EventDelivery{
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:     core.Event{ID: id1, Type: core.EventTypePoolConfirmed},
+				TokenPool: &core.TokenPool{ID: pool1},
+			},
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{ID: id2, Type: core.EventTypeTransferConfirmed},
+				TokenTransfer: &core.TokenTransfer{
+					Pool:   pool1,
+					From:   "0x111",
+					To:     "0x222",
+					Amount: *fftypes.NewFFBigInt(10),
+				},
+			},
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{ID: id3, Type: core.EventTypeTransferConfirmed},
+				TokenTransfer: &core.TokenTransfer{
+					Pool:   pool2,
+					From:   "0x222",
+					To:     "0x333",
+					Amount: *fftypes.NewFFBigInt(1000),
+				},
+			},
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:         core.Event{ID: id4, Type: core.EventTypeApprovalConfirmed},
+				TokenApproval: &core.TokenApproval{Pool: pool2},
+			},
+		},
+	}
+
+	matched, err := ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(matched))
+
+	ed.subscription.tokenFilter.poolFilter = regexp.MustCompile(pool1.String())
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(matched))
+	assert.Equal(t, *id1, *matched[0].ID)
+	assert.Equal(t, *id2, *matched[1].ID)
+
+	ed.subscription.tokenFilter.poolFilter = regexp.MustCompile(pool2.String())
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(matched))
+	assert.Equal(t, *id3, *matched[0].ID)
+	assert.Equal(t, *id4, *matched[1].ID)
+
+	ed.subscription.tokenFilter.poolFilter = nil
+	ed.subscription.tokenFilter.fromFilter = regexp.MustCompile("^0x222$")
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id3, *matched[0].ID)
+
+	ed.subscription.tokenFilter.fromFilter = nil
+	ed.subscription.tokenFilter.toFilter = regexp.MustCompile("^0x222$")
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id2, *matched[0].ID)
+
+	ed.subscription.tokenFilter.toFilter = nil
+	ed.subscription.tokenFilter.minAmount = big.NewInt(10)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(matched))
+	assert.Equal(t, *id2, *matched[0].ID)
+	assert.Equal(t, *id3, *matched[1].ID)
+
+	ed.subscription.tokenFilter.minAmount = big.NewInt(11)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id3, *matched[0].ID)
+
+	ed.subscription.tokenFilter.minAmount = nil
+	ed.subscription.tokenFilter.maxAmount = big.NewInt(999)
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id2, *matched[0].ID)
+}
+
+func TestFilterEventsData(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+		dataFilter: &dataFilter{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	var err error
+	ed.subscription.dataFilter.jsonPath, err = parseJSONPath(ed.ctx, "$.order.total")
+	assert.NoError(t, err)
+
+	msg1 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	msg2 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	msg3 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	msg4 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	msg5 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", mock.Anything, msg1).Return(core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"order":{"total":100000000000000000000001}}`)},
+	}, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg2).Return(core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"order":{"total":"12"}}`)},
+	}, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg3).Return(core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"order":{"total":null}}`)},
+	}, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg4).Return(core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`not json`)},
+	}, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg5).Return(core.DataArray{}, true, nil)
+
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	events := []*core.EventDelivery{
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: id1}, Message: msg1}},
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: id2}, Message: msg2}},
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}, Message: msg3}},
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}, Message: msg4}},
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}, Message: msg5}},
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}},
+	}
+
+	matched, err := ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(matched))
+	assert.Equal(t, *id1, *matched[0].ID)
+	assert.Equal(t, *id2, *matched[1].ID)
+
+	ed.subscription.dataFilter.valueFilter = regexp.MustCompile("^1[0-9]{23}$")
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id1, *matched[0].ID)
+
+	ed.subscription.dataFilter.valueFilter = regexp.MustCompile("^12$")
+	matched, err = ed.filterEvents(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id2, *matched[0].ID)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryFilterDataFail(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+		dataFilter: &dataFilter{jsonPath: jsonPath{}},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(msg, nil, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(nil, false, fmt.Errorf("pop"))
+
+	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: fftypes.NewUUID(), Type: core.EventTypeMessageConfirmed}})
+	assert.False(t, repoll)
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+}
+
 func TestEnrichTransactionEvents(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -594,9 +919,9 @@ func TestEnrichTransactionEvents(t *testing.T) {
 	mei := ed.transport.(*eventsmocks.Plugin)
 
 	eventDeliveries := make(chan *core.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*core.EventDelivery)
+		eventDeliveries <- a.Get(3).(*core.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -683,9 +1008,9 @@ func TestEnrichBlockchainEventEvents(t *testing.T) {
 	mei := ed.transport.(*eventsmocks.Plugin)
 
 	eventDeliveries := make(chan *core.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*core.EventDelivery)
+		eventDeliveries <- a.Get(3).(*core.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -805,7 +1130,7 @@ func TestBufferedDeliveryClosedContext(t *testing.T) {
 	mdi := ed.database.(*databasemocks.Plugin)
 	mei := ed.transport.(*eventsmocks.Plugin)
 	mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(nil, nil, nil)
-	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: fftypes.NewUUID()}})
 	assert.False(t, repoll)
@@ -828,7 +1153,7 @@ func TestBufferedDeliveryNackRewind(t *testing.T) {
 	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	delivered := make(chan struct{})
-	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliver.RunFn = func(a mock.Arguments) {
 		close(delivered)
 	}
@@ -873,7 +1198,7 @@ func TestBufferedDeliveryFailNack(t *testing.T) {
 	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 
 	failNacked := make(chan bool)
-	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	deliver.RunFn = func(a mock.Arguments) {
 		failNacked <- true
 	}
@@ -900,6 +1225,311 @@ func TestBufferedDeliveryFailNack(t *testing.T) {
 
 }
 
+func TestBufferedDeliveryBatched(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	ed.readAhead = 2
+	ed.batch = true
+	ed.batchTimeout = 10 * time.Millisecond
+	go ed.deliverBatchedEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	batchSizes := make(chan int, 2)
+	deliver := mei.On("BatchDeliveryRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		batch := a[2].([]*core.CombinedEventDataDelivery)
+		batchSizes <- len(batch)
+		for _, e := range batch {
+			ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID})
+		}
+	}
+
+	ed.eventPoller.pollingOffset = 100000
+	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{
+		&core.Event{ID: fftypes.NewUUID(), Sequence: 100001},
+		&core.Event{ID: fftypes.NewUUID(), Sequence: 100002},
+		&core.Event{ID: fftypes.NewUUID(), Sequence: 100003},
+	})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+
+	// The first batch fills up to the readahead, and the second is sent on the timeout
+	assert.Equal(t, 2, <-batchSizes)
+	assert.Equal(t, 1, <-batchSizes)
+	assert.Equal(t, int64(100003), ed.eventPoller.pollingOffset)
+}
+
+func TestBufferedDeliveryBatchedFailNack(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	ed.readAhead = 50
+	ed.batch = true
+	ed.batchTimeout = 10 * time.Millisecond
+	go ed.deliverBatchedEvents()
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mei.On("BatchDeliveryRequest", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	ed.eventPoller.pollingOffset = 100000
+	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{
+		&core.Event{ID: fftypes.NewUUID(), Sequence: 100001},
+		&core.Event{ID: fftypes.NewUUID(), Sequence: 100002},
+	})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+}
+
+func TestBatchedDeliveryDataFailNack(t *testing.T) {
+	yes := true
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					WithData: &yes,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	ed.batch = true
+	go ed.deliverBatchedEvents()
+
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", mock.Anything, mock.Anything).Return(nil, false, fmt.Errorf("pop"))
+
+	id1 := fftypes.NewUUID()
+	ed.inflight[*id1] = &core.Event{ID: id1, Sequence: 12345}
+	ed.eventDelivery <- &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event:   core.Event{ID: id1, Sequence: 12345},
+			Message: &core.Message{},
+		},
+	}
+
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, *id1, an.id)
+}
+
+func TestBatchedDeliveryClosed(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	close(ed.eventDelivery)
+
+	ed.deliverBatchedEvents()
+	cancel()
+}
+
+func TestBatchedDeliveryContextClosed(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	ed.readAhead = 10
+	ed.batchTimeout = time.Minute
+	ed.eventDelivery <- &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID()},
+		},
+	}
+	go cancel()
+
+	ed.deliverBatchedEvents()
+}
+
+func TestBufferedDeliveryPartitioned(t *testing.T) {
+	one := uint16(1)
+	partitionBy := core.SubOptsPartitionByTopic
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					ReadAhead:   &one,
+					PartitionBy: &partitionBy,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	delivered := make(chan *core.EventDelivery, 4)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		delivered <- a[3].(*core.EventDelivery)
+	}
+
+	bdDone := make(chan struct{})
+	ed.eventPoller.pollingOffset = 100000
+	go func() {
+		repoll, err := ed.bufferedDelivery([]core.LocallySequenced{
+			&core.Event{ID: fftypes.NewUUID(), Sequence: 100001, Topic: "topicA"},
+			&core.Event{ID: fftypes.NewUUID(), Sequence: 100002, Topic: "topicA"},
+			&core.Event{ID: fftypes.NewUUID(), Sequence: 100003, Topic: "topicB"},
+			&core.Event{ID: fftypes.NewUUID(), Sequence: 100004, Topic: "topicC"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// The second event on topicA must wait for the first, so topicB is delivered in parallel
+	ev1, ev3 := <-delivered, <-delivered
+	if ev1.Sequence > ev3.Sequence {
+		ev1, ev3 = ev3, ev1
+	}
+	assert.Equal(t, int64(100001), ev1.Sequence)
+	assert.Equal(t, int64(100003), ev3.Sequence)
+
+	// Acknowledging topicB does not move the offset past the first event, but frees up topicC
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev3.ID})
+	ev4 := <-delivered
+	assert.Equal(t, int64(100004), ev4.Sequence)
+	assert.Equal(t, int64(100000), ed.eventPoller.getPollingOffset())
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev4.ID})
+
+	// Acknowledging the first event commits it, and releases the next on topicA
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev1.ID})
+	ev2 := <-delivered
+	assert.Equal(t, int64(100002), ev2.Sequence)
+	assert.Equal(t, int64(100001), ed.eventPoller.getPollingOffset())
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev2.ID})
+
+	<-bdDone
+	assert.Equal(t, int64(100004), ed.eventPoller.getPollingOffset())
+}
+
+func TestDeliverEventsPartitionedInParallel(t *testing.T) {
+	one := uint16(1)
+	partitionBy := core.SubOptsPartitionByTopic
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					ReadAhead:   &one,
+					PartitionBy: &partitionBy,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	// Neither delivery returns until both are in flight, which deadlocks unless the keys are delivered in parallel
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	mei := ed.transport.(*eventsmocks.Plugin)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		started <- a[3].(*core.EventDelivery).Topic
+		<-release
+	}
+
+	ed.eventDelivery <- &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID(), Topic: "topicA"}}}
+	ed.eventDelivery <- &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID(), Topic: "topicB"}}}
+
+	topics := make([]string, 0, 2)
+	for len(topics) < 2 {
+		select {
+		case topic := <-started:
+			topics = append(topics, topic)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "events for different keys were not delivered in parallel")
+			close(release)
+			return
+		}
+	}
+	close(release)
+	assert.ElementsMatch(t, []string{"topicA", "topicB"}, topics)
+}
+
+func TestPartitionKey(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	group := fftypes.NewRandB32()
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Topic: "topic1"},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					SignerRef: core.SignerRef{Author: "did:firefly:org/org1"},
+					Group:     group,
+				},
+			},
+		},
+	}
+	assert.Equal(t, "", ed.partitionKey(event))
+	ed.partitionBy = core.SubOptsPartitionByTopic
+	assert.Equal(t, "topic1", ed.partitionKey(event))
+	ed.partitionBy = core.SubOptsPartitionByAuthor
+	assert.Equal(t, "did:firefly:org/org1", ed.partitionKey(event))
+	ed.partitionBy = core.SubOptsPartitionByGroup
+	assert.Equal(t, group.String(), ed.partitionKey(event))
+
+	event.Message = nil
+	assert.Equal(t, "", ed.partitionKey(event))
+}
+
+func TestAckNotCommittedPastQueued(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	ed.eventPoller.pollingOffset = 100000
+	ed.lowestQueued = 100001
+	id1 := fftypes.NewUUID()
+	ed.inflight[*id1] = &core.Event{ID: id1, Sequence: 100002}
+	ed.handleAckOffsetUpdate(ackNack{id: *id1, offset: 100002})
+	assert.Equal(t, int64(100000), ed.eventPoller.getPollingOffset())
+	assert.Empty(t, ed.inflight)
+}
+
+func TestNackRewindPartitionedLowestInflight(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	ed.partitionBy = core.SubOptsPartitionByTopic
+	ed.eventPoller.pollingOffset = 100050
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	ed.inflight[*id1] = &core.Event{ID: id1, Sequence: 100001}
+	ed.inflight[*id2] = &core.Event{ID: id2, Sequence: 100010}
+	ed.partitionKeys[*id1] = "topicA"
+	ed.partitionKeys[*id2] = "topicB"
+	ed.handleNackOffsetUpdate(ackNack{id: *id2, offset: 100010, isNack: true})
+	assert.Equal(t, int64(100000), ed.eventPoller.getPollingOffset())
+	assert.Empty(t, ed.inflight)
+	assert.Empty(t, ed.partitionKeys)
+}
+
 func TestAckNotInFlightNoop(t *testing.T) {
 
 	sub := &subscription{
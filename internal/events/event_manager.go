@@ -59,12 +59,16 @@ type EventManager interface {
 	DeletedSubscriptions() chan<- *fftypes.UUID
 	DeleteDurableSubscription(ctx context.Context, subDef *core.Subscription) (err error)
 	CreateUpdateDurableSubscription(ctx context.Context, subDef *core.Subscription, mustNew bool) (err error)
+	ResetDurableSubscription(ctx context.Context, subDef *core.Subscription, reset *core.SubscriptionReset) (err error)
+	GetSubscriptionStatus(ctx context.Context, subDef *core.Subscription) (*core.SubscriptionStatus, error)
 	Start() error
 	WaitStop()
 
 	// Bound blockchain callbacks
 	BatchPinComplete(bi blockchain.Plugin, batch *blockchain.BatchPin, signingKey *core.VerifierRef) error
 	BlockchainEvent(event *blockchain.EventWithSubscription) error
+	BlockchainEventRemoved(event *blockchain.EventWithSubscription) error
+	ContractListenerConfirmations(subscription string) (*int, error)
 	BlockchainNetworkAction(bi blockchain.Plugin, action string, event *blockchain.Event, signingKey *core.VerifierRef) error
 
 	// Bound dataexchange callbacks
@@ -150,7 +154,7 @@ func NewEventManager(ctx context.Context, ns string, ni sysmessaging.LocalNodeIn
 	em.blobReceiver = newBlobReceiver(ctx, em.aggregator)
 
 	var err error
-	if em.subManager, err = newSubscriptionManager(ctx, di, dm, newEventNotifier, bm, pm, txHelper); err != nil {
+	if em.subManager, err = newSubscriptionManager(ctx, di, dm, newEventNotifier, bm, pm, mm, txHelper); err != nil {
 		return nil, err
 	}
 
@@ -201,17 +205,26 @@ func (em *eventManager) CreateUpdateDurableSubscription(ctx context.Context, sub
 		subDef.Transport = em.defaultTransport
 	}
 
+	// Do a check first for existence, to give a nice 409 if we find one
+	existing, _ := em.database.GetSubscriptionByName(ctx, subDef.Namespace, subDef.Name)
+	if existing != nil && mustNew {
+		return i18n.NewError(ctx, coremsgs.MsgAlreadyExists, "subscription", subDef.Namespace, subDef.Name)
+	}
+
+	// The secret is never returned, so an update that sends back the redacted value keeps the existing one
+	options := subDef.Options.TransportOptions()
+	if existing != nil && options.GetString("secret") == core.WebhookSecretRedacted {
+		if secret, ok := existing.Options.TransportOptions()["secret"]; ok {
+			options["secret"] = secret
+		}
+	}
+
 	// Check it can be parsed before inserting (the submanager will check again when processing the creation, so we discard the result)
 	if _, err = em.subManager.parseSubscriptionDef(ctx, subDef); err != nil {
 		return err
 	}
 
-	// Do a check first for existence, to give a nice 409 if we find one
-	existing, _ := em.database.GetSubscriptionByName(ctx, subDef.Namespace, subDef.Name)
 	if existing != nil {
-		if mustNew {
-			return i18n.NewError(ctx, coremsgs.MsgAlreadyExists, "subscription", subDef.Namespace, subDef.Name)
-		}
 		// Copy over the generated fields, so we can do a compare
 		subDef.Created = existing.Created
 		subDef.ID = existing.ID
@@ -244,6 +257,46 @@ func (em *eventManager) DeleteDurableSubscription(ctx context.Context, subDef *c
 	return em.database.DeleteSubscriptionByID(ctx, subDef.ID)
 }
 
+func (em *eventManager) ResetDurableSubscription(ctx context.Context, subDef *core.Subscription, reset *core.SubscriptionReset) (err error) {
+	offset, err := calcResetOffset(ctx, em.database, subDef.Namespace, reset)
+	if err != nil {
+		return err
+	}
+	return em.subManager.resetDurableSubscriptionOffset(ctx, subDef.ID, offset)
+}
+
+func (em *eventManager) GetSubscriptionStatus(ctx context.Context, subDef *core.Subscription) (*core.SubscriptionStatus, error) {
+	status := &core.SubscriptionStatus{}
+	if current, running := em.subManager.getDurableSubscriptionOffset(subDef.ID); running {
+		// Report the same offset as the lag metric, rather than the last one committed to the database
+		status.CurrentOffset = current
+	} else {
+		offset, err := em.database.GetOffset(ctx, core.OffsetTypeSubscription, subDef.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		if offset != nil {
+			status.CurrentOffset = offset.Current
+		} else {
+			// No dispatcher has started yet, so the subscription is still at its first event
+			if status.CurrentOffset, err = calcFirstOffset(ctx, em.database, subDef.Options.FirstEvent); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	f := fb.And(fb.Eq("namespace", subDef.Namespace)).Sort("sequence").Descending().Limit(1)
+	latestEvents, _, err := em.database.GetEvents(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if len(latestEvents) > 0 {
+		status.Lag = calcOffsetLag(status.CurrentOffset, latestEvents[0].Sequence)
+	}
+	return status, nil
+}
+
 func (em *eventManager) AddSystemEventListener(ns string, el system.EventListener) error {
 	return em.internalEvents.AddListener(ns, el)
 }
@@ -108,9 +108,9 @@ func mockRunAsGroupPassthrough(mdi *databasemocks.Plugin) {
 func TestStartStop(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	mdi := em.database.(*databasemocks.Plugin)
-	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, AggregatorOffsetName).Return(&core.Offset{
 		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		Current: 12345,
 		RowID:   333333,
 	}, nil)
@@ -154,9 +154,9 @@ func TestStartStopBadTransports(t *testing.T) {
 func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	mdi := em.database.(*databasemocks.Plugin)
-	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, AggregatorOffsetName).Return(&core.Offset{
 		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		Current: 12345,
 		RowID:   333333,
 	}, nil)
@@ -349,6 +349,33 @@ func TestUpdateDurableSubscriptionOk(t *testing.T) {
 	assert.Equal(t, "12345", string(*sub.Options.FirstEvent))
 }
 
+func TestUpdateDurableSubscriptionKeepsRedactedSecret(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+	}
+	sub.Options.TransportOptions()["secret"] = core.WebhookSecretRedacted
+	existing := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	existing.Options.TransportOptions()["secret"] = "shh"
+	mdi.On("GetSubscriptionByName", mock.Anything, "ns1", "sub1").Return(existing, nil)
+	mdi.On("UpsertSubscription", mock.Anything, mock.MatchedBy(func(s *core.Subscription) bool {
+		return s.Options.TransportOptions().GetString("secret") == "shh"
+	}), true).Return(nil)
+	err := em.CreateUpdateDurableSubscription(em.ctx, sub, false)
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
 func TestUpdateDurableSubscriptionNoOp(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -388,6 +415,191 @@ func TestCreateDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestResetDurableSubscriptionBadReset(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	err := em.ResetDurableSubscription(em.ctx, sub, &core.SubscriptionReset{})
+	assert.Regexp(t, "FF10437", err)
+
+	oldest := core.SubOptsFirstEventOldest
+	err = em.ResetDurableSubscription(em.ctx, sub, &core.SubscriptionReset{
+		FirstEvent: &oldest,
+		Timestamp:  fftypes.Now(),
+	})
+	assert.Regexp(t, "FF10437", err)
+}
+
+func TestResetDurableSubscriptionFirstEventOk(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	subID := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1"}}
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Name == subID.String() && offset.Current == 12345
+	}), true).Return(nil)
+	firstEvent := core.SubOptsFirstEvent("12345")
+	err := em.ResetDurableSubscription(em.ctx, sub, &core.SubscriptionReset{
+		FirstEvent: &firstEvent,
+	})
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestResetDurableSubscriptionTimestampOk(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	subID := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1"}}
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{
+		{Sequence: 42},
+	}, nil, nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Name == subID.String() && offset.Current == 42
+	}), true).Return(nil)
+	err := em.ResetDurableSubscription(em.ctx, sub, &core.SubscriptionReset{
+		Timestamp: fftypes.Now(),
+	})
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestResetDurableSubscriptionTimestampNoEvents(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	subID := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1"}}
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Name == subID.String() && offset.Current == -1
+	}), true).Return(nil)
+	err := em.ResetDurableSubscription(em.ctx, sub, &core.SubscriptionReset{
+		Timestamp: fftypes.Now(),
+	})
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestResetDurableSubscriptionTimestampFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := em.ResetDurableSubscription(em.ctx, sub, &core.SubscriptionReset{
+		Timestamp: fftypes.Now(),
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSubscriptionStatusOk(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	subID := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1"}}
+	em.subManager.connections["conn1"] = &connection{
+		id: "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: {eventPoller: &eventPoller{}}, // waiting to be elected
+		},
+	}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, subID.String()).Return(&core.Offset{Current: 10}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{
+		{Sequence: 15},
+	}, nil, nil)
+	status, err := em.GetSubscriptionStatus(em.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), status.CurrentOffset)
+	assert.Equal(t, int64(5), status.Lag)
+}
+
+func TestGetSubscriptionStatusDispatcherRunning(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	subID := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1"}}
+	em.subManager.connections["conn1"] = &connection{
+		id: "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: {eventPoller: &eventPoller{pollingOffset: 12, offsetRestored: true}},
+		},
+	}
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{
+		{Sequence: 15},
+	}, nil, nil)
+	status, err := em.GetSubscriptionStatus(em.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), status.CurrentOffset)
+	assert.Equal(t, int64(3), status.Lag)
+	mdi.AssertNotCalled(t, "GetOffset", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetSubscriptionStatusNotStarted(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	subID := fftypes.NewUUID()
+	firstEvent := core.SubOptsFirstEvent("7")
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1"},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				FirstEvent: &firstEvent,
+			},
+		},
+	}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, subID.String()).Return(nil, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil)
+	status, err := em.GetSubscriptionStatus(em.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), status.CurrentOffset)
+	assert.Equal(t, int64(0), status.Lag)
+}
+
+func TestGetSubscriptionStatusGetOffsetFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, fmt.Errorf("pop"))
+	_, err := em.GetSubscriptionStatus(em.ctx, sub)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSubscriptionStatusBadFirstEvent(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	firstEvent := core.SubOptsFirstEvent("lobster")
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				FirstEvent: &firstEvent,
+			},
+		},
+	}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, nil)
+	_, err := em.GetSubscriptionStatus(em.ctx, sub)
+	assert.Regexp(t, "FF10191", err)
+}
+
+func TestGetSubscriptionStatusGetEventsFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(&core.Offset{Current: 10}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := em.GetSubscriptionStatus(em.ctx, sub)
+	assert.EqualError(t, err, "pop")
+}
+
 func TestAddInternalListener(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	ie := &system.Events{}
@@ -398,7 +610,7 @@ func TestAddInternalListener(t *testing.T) {
 
 	conf := config.RootSection("ut.events")
 	ie.InitConfig(conf)
-	ie.Init(em.ctx, conf, cbs)
+	ie.Init(em.ctx, conf, cbs, &metricsmocks.Manager{})
 	em.internalEvents = ie
 	defer cancel()
 	err := em.AddSystemEventListener("ns1", func(event *core.EventDelivery) error { return nil })
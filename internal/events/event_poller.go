@@ -35,8 +35,10 @@ type eventPoller struct {
 	eventNotifier   *eventNotifier
 	closed          chan struct{}
 	offsetCommitted chan int64
+	commitLoopDone  chan struct{}
 	offsetID        int64
 	pollingOffset   int64
+	offsetRestored  bool
 	mux             sync.Mutex
 	conf            *eventPollerConf
 }
@@ -67,6 +69,7 @@ func newEventPoller(ctx context.Context, di database.Plugin, en *eventNotifier,
 		database:        di,
 		shoulderTaps:    make(chan bool, 1),
 		offsetCommitted: make(chan int64, 1),
+		commitLoopDone:  make(chan struct{}),
 		eventNotifier:   en,
 		closed:          make(chan struct{}),
 		conf:            conf,
@@ -105,8 +108,11 @@ func (ep *eventPoller) restoreOffset() error {
 				}
 			}
 		}
+		ep.mux.Lock()
 		ep.offsetID = offset.RowID
 		ep.pollingOffset = offset.Current
+		ep.offsetRestored = true
+		ep.mux.Unlock()
 		log.L(ep.ctx).Infof("Event offset restored %d", ep.pollingOffset)
 		return false, nil
 	})
@@ -119,6 +125,7 @@ func (ep *eventPoller) start() {
 	if err != nil {
 		log.L(ep.ctx).Errorf("Event poller context closed before we successfully restored offset: %s", err)
 		close(ep.closed)
+		close(ep.commitLoopDone)
 		return
 	}
 	go ep.newEventNotifications()
@@ -142,6 +149,13 @@ func (ep *eventPoller) getPollingOffset() int64 {
 	return ep.pollingOffset
 }
 
+// getRestoredOffset returns the polling offset, once it has been restored from the database
+func (ep *eventPoller) getRestoredOffset() (int64, bool) {
+	ep.mux.Lock()
+	defer ep.mux.Unlock()
+	return ep.pollingOffset, ep.offsetRestored
+}
+
 func (ep *eventPoller) commitOffset(offset int64) {
 	// Next polling cycle should start one higher than this offset
 	ep.mux.Lock()
@@ -158,6 +172,12 @@ func (ep *eventPoller) commitOffset(offset int64) {
 	}
 }
 
+// waitStopped blocks until the poller has stopped, including any in-flight commit of the offset
+func (ep *eventPoller) waitStopped() {
+	<-ep.closed
+	<-ep.commitLoopDone
+}
+
 func (ep *eventPoller) readPage() ([]core.LocallySequenced, error) {
 
 	var items []core.LocallySequenced
@@ -226,6 +246,7 @@ func (ep *eventPoller) eventLoop() {
 }
 
 func (ep *eventPoller) offsetCommitLoop() {
+	defer close(ep.commitLoopDone)
 	l := log.L(ep.ctx)
 	for range ep.offsetCommitted {
 		_ = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
@@ -67,7 +67,7 @@ func TestStartStopEventPoller(t *testing.T) {
 	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
 	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{
 		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		RowID:   3333333,
 		Current: 12345,
 	}, nil)
@@ -79,6 +79,15 @@ func TestStartStopEventPoller(t *testing.T) {
 	<-ep.closed
 }
 
+func TestStartClosedWaitStopped(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(nil, fmt.Errorf("pop"))
+	ep.start()
+	ep.waitStopped()
+}
+
 func TestRestoreOffsetNewestOK(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
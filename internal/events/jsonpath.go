@@ -0,0 +1,106 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// jsonPath is a compiled form of the simple subset of JSONPath supported in subscription filters.
+// Only child access by name ($.a.b or $['a']['b']) and array indexes ($.a[0]) are supported.
+// Negative array indexes count back from the end of the array.
+type jsonPath []*jsonPathSegment
+
+type jsonPathSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+func parseJSONPath(ctx context.Context, expr string) (jsonPath, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidJSONPath, expr, 0)
+	}
+	path := jsonPath{}
+	i := 1
+	for i < len(expr) {
+		switch expr[i] {
+		case '.':
+			end := i + 1
+			for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+				end++
+			}
+			if end == i+1 {
+				return nil, i18n.NewError(ctx, coremsgs.MsgInvalidJSONPath, expr, i)
+			}
+			path = append(path, &jsonPathSegment{name: expr[i+1 : end]})
+			i = end
+		case '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, i18n.NewError(ctx, coremsgs.MsgInvalidJSONPath, expr, i)
+			}
+			end += i
+			inner := expr[i+1 : end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				path = append(path, &jsonPathSegment{name: inner[1 : len(inner)-1]})
+			} else if index, err := strconv.Atoi(inner); err == nil {
+				path = append(path, &jsonPathSegment{index: index, isIndex: true})
+			} else {
+				return nil, i18n.NewError(ctx, coremsgs.MsgInvalidJSONPath, expr, i)
+			}
+			i = end + 1
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidJSONPath, expr, i)
+		}
+	}
+	return path, nil
+}
+
+// evaluate walks the path through a value parsed from JSON, returning false if any part of the path does not exist
+func (jp jsonPath) evaluate(value interface{}) (interface{}, bool) {
+	for _, segment := range jp {
+		if segment.isIndex {
+			arr, ok := value.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			index := segment.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, false
+			}
+			value = arr[index]
+		} else {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = obj[segment.name]; !ok {
+				return nil, false
+			}
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,79 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONPathEvaluate(t *testing.T) {
+	var value interface{}
+	err := json.Unmarshal([]byte(`{
+		"customer": {
+			"name": "acme",
+			"regions": ["emea", "apac"],
+			"my.key": true
+		}
+	}`), &value)
+	assert.NoError(t, err)
+
+	for expr, expected := range map[string]interface{}{
+		"$.customer.name":           "acme",
+		"$['customer']['name']":     "acme",
+		`$["customer"].regions[1]`:  "apac",
+		"$.customer.regions[-2]":    "emea",
+		"$.customer['my.key']":      true,
+		"$.customer.regions[0]":     "emea",
+		"$.customer.regions[-1]":    "apac",
+		"$['customer'].name":        "acme",
+		"$.customer.regions['len']": nil,
+		"$.customer.name[0]":        nil,
+		"$.customer.regions[2]":     nil,
+		"$.customer.missing":        nil,
+	} {
+		jp, err := parseJSONPath(context.Background(), expr)
+		assert.NoError(t, err)
+		result, ok := jp.evaluate(value)
+		assert.Equal(t, expected != nil, ok, expr)
+		assert.Equal(t, expected, result, expr)
+	}
+
+	jp, err := parseJSONPath(context.Background(), "$")
+	assert.NoError(t, err)
+	result, ok := jp.evaluate(value)
+	assert.True(t, ok)
+	assert.Equal(t, value, result)
+}
+
+func TestJSONPathParseFail(t *testing.T) {
+	for _, expr := range []string{
+		"customer.name",
+		"$customer",
+		"$.",
+		"$..name",
+		"$.customer[",
+		"$.customer[abc]",
+		"$.customer['name]",
+	} {
+		_, err := parseJSONPath(context.Background(), expr)
+		assert.Regexp(t, "FF10436", err, expr)
+	}
+}
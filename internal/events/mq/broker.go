@@ -0,0 +1,98 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// Message is a single record to publish to a topic on the broker
+type Message struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// Broker is the minimal interface to a message broker required by the transport.
+// Publish must only return once the broker has confirmed it has durably accepted every message
+// in the batch, in order, as the confirmation is used as the acknowledgement of the events.
+type Broker interface {
+	Publish(ctx context.Context, topic string, messages []*Message) error
+}
+
+// kafkaREST publishes to Kafka via the Confluent REST Proxy v2 API
+type kafkaREST struct {
+	client *resty.Client
+}
+
+type kafkaRESTRecord struct {
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaRESTProduceRequest struct {
+	Records []*kafkaRESTRecord `json:"records"`
+}
+
+type kafkaRESTOffset struct {
+	Partition *int64 `json:"partition"`
+	Offset    *int64 `json:"offset"`
+	ErrorCode *int64 `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+type kafkaRESTProduceResponse struct {
+	Offsets []*kafkaRESTOffset `json:"offsets"`
+}
+
+const kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+
+func (k *kafkaREST) Publish(ctx context.Context, topic string, messages []*Message) error {
+	body := &kafkaRESTProduceRequest{
+		Records: make([]*kafkaRESTRecord, len(messages)),
+	}
+	for i, m := range messages {
+		body.Records[i] = &kafkaRESTRecord{Key: m.Key, Value: m.Value}
+	}
+	var result kafkaRESTProduceResponse
+	res, err := k.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", kafkaRESTContentType).
+		SetHeader("Accept", "application/vnd.kafka.v2+json").
+		SetBody(body).
+		SetResult(&result).
+		Post("/topics/" + url.PathEscape(topic))
+	if err != nil || !res.IsSuccess() {
+		return ffresty.WrapRestErr(ctx, res, err, coremsgs.MsgMQPublishFailed)
+	}
+	// The proxy returns a per-record result, which must all be successful for the batch to be confirmed
+	if len(result.Offsets) != len(messages) {
+		return i18n.NewError(ctx, coremsgs.MsgMQPublishNotConfirmed, topic, len(result.Offsets), len(messages))
+	}
+	for i, o := range result.Offsets {
+		if o.ErrorCode != nil || o.Offset == nil {
+			return i18n.NewError(ctx, coremsgs.MsgMQPublishRejected, i, topic, o.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKafkaREST(t *testing.T, handler http.HandlerFunc) (*kafkaREST, func()) {
+	coreconfig.Reset()
+	server := httptest.NewServer(handler)
+	restConfig := utConfig.SubSection(MQConfKafkaREST)
+	ffresty.InitConfig(restConfig)
+	restConfig.Set(ffresty.HTTPConfigURL, server.URL)
+	restConfig.Set(ffresty.HTTPConfigRetryEnabled, false)
+	return &kafkaREST{client: ffresty.New(context.Background(), restConfig)}, server.Close
+}
+
+func testMessages() []*Message {
+	return []*Message{
+		{Key: "key1", Value: json.RawMessage(`{"id":"1"}`)},
+		{Value: json.RawMessage(`{"id":"2"}`)},
+	}
+}
+
+func TestKafkaRESTPublishOK(t *testing.T) {
+	k, done := newTestKafkaREST(t, func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/topics/firefly.ns1.sub1", req.URL.Path)
+		assert.Equal(t, kafkaRESTContentType, req.Header.Get("Content-Type"))
+		var body kafkaRESTProduceRequest
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Len(t, body.Records, 2)
+		assert.Equal(t, "key1", body.Records[0].Key)
+		assert.JSONEq(t, `{"id":"2"}`, string(body.Records[1].Value))
+		res.Header().Set("Content-Type", "application/json")
+		res.Write([]byte(`{"offsets":[{"partition":0,"offset":10},{"partition":0,"offset":11}]}`))
+	})
+	defer done()
+
+	err := k.Publish(context.Background(), "firefly.ns1.sub1", testMessages())
+	assert.NoError(t, err)
+}
+
+func TestKafkaRESTPublishFail(t *testing.T) {
+	k, done := newTestKafkaREST(t, func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(500)
+		res.Write([]byte(`{"error_code":50001,"message":"pop"}`))
+	})
+	defer done()
+
+	err := k.Publish(context.Background(), "topic1", testMessages())
+	assert.Regexp(t, "FF10426.*pop", err)
+}
+
+func TestKafkaRESTPublishPartiallyConfirmed(t *testing.T) {
+	k, done := newTestKafkaREST(t, func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		res.Write([]byte(`{"offsets":[{"partition":0,"offset":10}]}`))
+	})
+	defer done()
+
+	err := k.Publish(context.Background(), "topic1", testMessages())
+	assert.Regexp(t, "FF10427", err)
+}
+
+func TestKafkaRESTPublishRecordRejected(t *testing.T) {
+	k, done := newTestKafkaREST(t, func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		res.Write([]byte(`{"offsets":[{"partition":0,"offset":10},{"error_code":1,"error":"too large"}]}`))
+	})
+	defer done()
+
+	err := k.Publish(context.Background(), "topic1", testMessages())
+	assert.Regexp(t, "FF10428.*too large", err)
+}
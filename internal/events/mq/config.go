@@ -0,0 +1,38 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+)
+
+const (
+	defaultTopicPrefix = "firefly"
+)
+
+const (
+	// MQConfTopicPrefix is the prefix for the topic name derived from each subscription, when the subscription does not set its own topic
+	MQConfTopicPrefix = "topicPrefix"
+	// MQConfKafkaREST is the sub-section configuring the Kafka REST Proxy the messages are published through
+	MQConfKafkaREST = "kafkarest"
+)
+
+func (mq *MessageQueue) InitConfig(config config.Section) {
+	config.AddKnownKey(MQConfTopicPrefix, defaultTopicPrefix)
+	ffresty.InitConfig(config.SubSection(MQConfKafkaREST))
+}
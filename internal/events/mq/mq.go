@@ -0,0 +1,222 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+// topicRegex is the set of topic names that are valid across Kafka and the common AMQP brokers
+var topicRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+type MessageQueue struct {
+	ctx          context.Context
+	capabilities *events.Capabilities
+	callbacks    events.Callbacks
+	metrics      metrics.Manager
+	broker       Broker
+	topicPrefix  string
+	connID       string
+	mux          sync.Mutex
+	publishers   map[fftypes.UUID]*publisher
+}
+
+// publisher holds the queue of deliveries for a single subscription, which are published
+// in order by a single goroutine that runs while there is work in the queue
+type publisher struct {
+	namespace    string
+	subscription string
+	topic        string
+	batchSize    int
+	queue        []*delivery
+}
+
+type delivery struct {
+	connID  string
+	event   *core.EventDelivery
+	message *Message
+}
+
+type mqPayload struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+func (mq *MessageQueue) Name() string { return "mq" }
+
+func (mq *MessageQueue) Init(ctx context.Context, config config.Section, callbacks events.Callbacks, metrics metrics.Manager) (err error) {
+	restConfig := config.SubSection(MQConfKafkaREST)
+	if restConfig.GetString(ffresty.HTTPConfigURL) == "" {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "url", "events.mq.kafkarest")
+	}
+	return mq.init(ctx, config, callbacks, metrics, &kafkaREST{client: ffresty.New(ctx, restConfig)})
+}
+
+func (mq *MessageQueue) init(ctx context.Context, config config.Section, callbacks events.Callbacks, metrics metrics.Manager, broker Broker) error {
+	*mq = MessageQueue{
+		ctx:          ctx,
+		capabilities: &events.Capabilities{},
+		callbacks:    callbacks,
+		metrics:      metrics,
+		broker:       broker,
+		topicPrefix:  config.GetString(MQConfTopicPrefix),
+		connID:       fftypes.ShortID(),
+		publishers:   make(map[fftypes.UUID]*publisher),
+	}
+	// We have a single logical connection to the broker, that matches all subscriptions
+	return callbacks.RegisterConnection(mq.connID, func(sr core.SubscriptionRef) bool { return true })
+}
+
+func (mq *MessageQueue) Capabilities() *events.Capabilities {
+	return mq.capabilities
+}
+
+func (mq *MessageQueue) ValidateOptions(options *core.SubscriptionOptions) error {
+	if options.WithData == nil {
+		defaultFalse := false
+		options.WithData = &defaultFalse
+	}
+	if topic, ok := options.TransportOptions()["topic"]; ok {
+		topicStr, isString := topic.(string)
+		if !isString || !topicRegex.MatchString(topicStr) {
+			return i18n.NewError(mq.ctx, coremsgs.MsgMQInvalidTopic, topic)
+		}
+	}
+	return nil
+}
+
+// topicName is the topic set in the options of the subscription, or one derived from its namespace and name
+func (mq *MessageQueue) topicName(sub *core.Subscription) string {
+	if topic := sub.Options.TransportOptions().GetString("topic"); topic != "" {
+		return topic
+	}
+	return fmt.Sprintf("%s.%s.%s", mq.topicPrefix, sub.Namespace, sub.Name)
+}
+
+// batchSize allows everything the dispatcher has in flight for the subscription to be published to the broker together
+func batchSize(sub *core.Subscription) int {
+	readAhead := config.GetUint(coreconfig.SubscriptionDefaultsReadAhead)
+	if sub.Options.ReadAhead != nil {
+		readAhead = uint(*sub.Options.ReadAhead)
+	}
+	return int(readAhead) + 1
+}
+
+func (mq *MessageQueue) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	payload := &mqPayload{EventDelivery: event}
+	if sub.Options.WithData != nil && *sub.Options.WithData {
+		payload.Data = data
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	d := &delivery{
+		connID: connID,
+		event:  event,
+		message: &Message{
+			Key:   event.Topic,
+			Value: b,
+		},
+	}
+
+	mq.mux.Lock()
+	defer mq.mux.Unlock()
+	pub, running := mq.publishers[*sub.ID]
+	if !running {
+		pub = &publisher{}
+		mq.publishers[*sub.ID] = pub
+	}
+	pub.namespace = sub.Namespace
+	pub.subscription = metrics.SubscriptionLabel(sub)
+	pub.topic = mq.topicName(sub)
+	pub.batchSize = batchSize(sub)
+	pub.queue = append(pub.queue, d)
+	if !running {
+		go mq.publishLoop(sub.ID, pub)
+	}
+	return nil
+}
+
+func (mq *MessageQueue) BatchDeliveryRequest(connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	return i18n.NewError(mq.ctx, coremsgs.MsgBatchDeliveryNotSupported, mq.Name())
+}
+
+func (mq *MessageQueue) publishLoop(subID *fftypes.UUID, pub *publisher) {
+	for {
+		mq.mux.Lock()
+		if len(pub.queue) == 0 || mq.ctx.Err() != nil {
+			// Anything left in the queue on shutdown will be redelivered by the dispatcher on restart
+			delete(mq.publishers, *subID)
+			mq.mux.Unlock()
+			return
+		}
+		batch := pub.queue
+		if len(batch) > pub.batchSize {
+			batch = batch[0:pub.batchSize]
+		}
+		pub.queue = pub.queue[len(batch):]
+		topic := pub.topic
+		namespace, subscription := pub.namespace, pub.subscription
+		mq.mux.Unlock()
+
+		if err := mq.publishBatch(topic, batch); err != nil && mq.metrics.IsMetricsEnabled() {
+			mq.metrics.SubscriptionDeliveryFailed(namespace, subscription, mq.Name())
+		}
+	}
+}
+
+func (mq *MessageQueue) publishBatch(topic string, batch []*delivery) error {
+	messages := make([]*Message, len(batch))
+	for i, d := range batch {
+		messages[i] = d.message
+	}
+	var info string
+	err := mq.broker.Publish(mq.ctx, topic, messages)
+	if err != nil {
+		log.L(mq.ctx).Errorf("Failed to publish %d events to topic '%s': %s", len(batch), topic, err)
+		info = err.Error()
+	} else {
+		log.L(mq.ctx).Debugf("Published %d events to topic '%s'", len(batch), topic)
+	}
+	// Confirmation from the broker is the acknowledgement - a failure rejects the events, so the
+	// dispatcher will redeliver them
+	for _, d := range batch {
+		mq.callbacks.DeliveryResponse(d.connID, &core.EventDeliveryResponse{
+			ID:           d.event.ID,
+			Rejected:     err != nil,
+			Info:         info,
+			Subscription: d.event.Subscription,
+		})
+	}
+	return err
+}
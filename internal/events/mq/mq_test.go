@@ -0,0 +1,337 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testBroker is an in-process stand-in for a message broker, which records everything it confirms
+type testBroker struct {
+	mux       sync.Mutex
+	topics    map[string][]*Message
+	batches   []int
+	release   chan bool
+	failCount int
+}
+
+func newTestBroker() *testBroker {
+	return &testBroker{
+		topics: make(map[string][]*Message),
+	}
+}
+
+func (tb *testBroker) Publish(ctx context.Context, topic string, messages []*Message) error {
+	if tb.release != nil {
+		<-tb.release
+	}
+	tb.mux.Lock()
+	defer tb.mux.Unlock()
+	if tb.failCount > 0 {
+		tb.failCount--
+		return fmt.Errorf("pop")
+	}
+	tb.topics[topic] = append(tb.topics[topic], messages...)
+	tb.batches = append(tb.batches, len(messages))
+	return nil
+}
+
+func (tb *testBroker) published(topic string) []*Message {
+	tb.mux.Lock()
+	defer tb.mux.Unlock()
+	return tb.topics[topic]
+}
+
+var utConfig = config.RootSection("ut.mq")
+
+func newTestMQ(t *testing.T, broker Broker) (*MessageQueue, *eventsmocks.Callbacks, func()) {
+	coreconfig.Reset()
+
+	cbs := &eventsmocks.Callbacks{}
+	rc := cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	rc.RunFn = func(a mock.Arguments) {
+		assert.Equal(t, true, a[1].(events.SubscriptionMatcher)(core.SubscriptionRef{}))
+	}
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false)
+	mq := &MessageQueue{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	mq.InitConfig(utConfig)
+	err := mq.init(ctx, utConfig, cbs, mmi, broker)
+	assert.NoError(t, err)
+	assert.Equal(t, "mq", mq.Name())
+	assert.NotNil(t, mq.Capabilities())
+	return mq, cbs, cancelCtx
+}
+
+func newTestSub(readAhead uint16, withData bool) *core.Subscription {
+	return &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				ReadAhead: &readAhead,
+				WithData:  &withData,
+			},
+		},
+	}
+}
+
+func newTestEvent(sub *core.Subscription, topic string) *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Namespace: sub.Namespace,
+				Topic:     topic,
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+}
+
+func TestInitMissingURL(t *testing.T) {
+	coreconfig.Reset()
+	mq := &MessageQueue{}
+	mq.InitConfig(utConfig)
+	err := mq.Init(context.Background(), utConfig, &eventsmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10138.*url", err)
+}
+
+func TestInitKafkaREST(t *testing.T) {
+	coreconfig.Reset()
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	mq := &MessageQueue{}
+	mq.InitConfig(utConfig)
+	utConfig.SubSection(MQConfKafkaREST).Set(ffresty.HTTPConfigURL, "http://localhost:8082")
+	err := mq.Init(context.Background(), utConfig, cbs, &metricsmocks.Manager{})
+	assert.NoError(t, err)
+	assert.IsType(t, &kafkaREST{}, mq.broker)
+	assert.Equal(t, "firefly", mq.topicPrefix)
+}
+
+func TestValidateOptionsDefaults(t *testing.T) {
+	mq, _, cancel := newTestMQ(t, newTestBroker())
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	err := mq.ValidateOptions(opts)
+	assert.NoError(t, err)
+	assert.False(t, *opts.WithData)
+
+	yes := true
+	opts = &core.SubscriptionOptions{
+		SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+			WithData: &yes,
+		},
+	}
+	opts.TransportOptions()["topic"] = "my-app.events_1"
+	err = mq.ValidateOptions(opts)
+	assert.NoError(t, err)
+	assert.True(t, *opts.WithData)
+}
+
+func TestValidateOptionsBadTopic(t *testing.T) {
+	mq, _, cancel := newTestMQ(t, newTestBroker())
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["topic"] = "bad/topic"
+	err := mq.ValidateOptions(opts)
+	assert.Regexp(t, "FF10429", err)
+
+	opts = &core.SubscriptionOptions{}
+	opts.TransportOptions()["topic"] = 12345
+	err = mq.ValidateOptions(opts)
+	assert.Regexp(t, "FF10429", err)
+}
+
+func TestBatchDeliveryNotSupported(t *testing.T) {
+	mq, _, cancel := newTestMQ(t, newTestBroker())
+	defer cancel()
+
+	assert.False(t, mq.Capabilities().BatchDelivery)
+	err := mq.BatchDeliveryRequest("conn1", newTestSub(0, false), []*core.CombinedEventDataDelivery{})
+	assert.Regexp(t, "FF10433.*mq", err)
+}
+
+func TestDeliveryAckedOnConfirm(t *testing.T) {
+	broker := newTestBroker()
+	mq, cbs, cancel := newTestMQ(t, broker)
+	defer cancel()
+
+	sub := newTestSub(0, false)
+	event := newTestEvent(sub, "topic1")
+
+	acked := make(chan *core.EventDeliveryResponse)
+	cbs.On("DeliveryResponse", "conn1", mock.Anything).Run(func(a mock.Arguments) {
+		acked <- a[1].(*core.EventDeliveryResponse)
+	}).Return()
+
+	err := mq.DeliveryRequest(context.Background(), "conn1", sub, event, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"data1"`)},
+	})
+	assert.NoError(t, err)
+
+	res := <-acked
+	assert.Equal(t, event.ID, res.ID)
+	assert.False(t, res.Rejected)
+	assert.Equal(t, sub.SubscriptionRef, res.Subscription)
+
+	published := broker.published("firefly.ns1.sub1")
+	assert.Len(t, published, 1)
+	assert.Equal(t, "topic1", published[0].Key)
+	var payload fftypes.JSONObject
+	err = json.Unmarshal(published[0].Value, &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID.String(), payload.GetString("id"))
+	assert.Equal(t, "sub1", payload.GetObject("subscription").GetString("name"))
+	// withData is false
+	assert.Nil(t, payload["data"])
+}
+
+func TestDeliveryWithDataCustomTopic(t *testing.T) {
+	broker := newTestBroker()
+	mq, cbs, cancel := newTestMQ(t, broker)
+	defer cancel()
+
+	sub := newTestSub(0, true)
+	sub.Options.TransportOptions()["topic"] = "mytopic"
+	event := newTestEvent(sub, "topic1")
+
+	acked := make(chan *core.EventDeliveryResponse)
+	cbs.On("DeliveryResponse", "conn1", mock.Anything).Run(func(a mock.Arguments) {
+		acked <- a[1].(*core.EventDeliveryResponse)
+	}).Return()
+
+	err := mq.DeliveryRequest(context.Background(), "conn1", sub, event, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"data1"`)},
+	})
+	assert.NoError(t, err)
+
+	res := <-acked
+	assert.False(t, res.Rejected)
+
+	published := broker.published("mytopic")
+	assert.Len(t, published, 1)
+	var payload fftypes.JSONObject
+	err = json.Unmarshal(published[0].Value, &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "data1", payload.GetObjectArray("data")[0]["value"])
+}
+
+func TestDeliveryBatchedByReadAhead(t *testing.T) {
+	broker := newTestBroker()
+	broker.release = make(chan bool)
+	mq, cbs, cancel := newTestMQ(t, broker)
+	defer cancel()
+
+	sub := newTestSub(2, false)
+
+	acked := make(chan *core.EventDeliveryResponse, 4)
+	cbs.On("DeliveryResponse", "conn1", mock.Anything).Run(func(a mock.Arguments) {
+		acked <- a[1].(*core.EventDeliveryResponse)
+	}).Return()
+
+	// The first event is picked up on its own, and blocks in the broker while the rest queue
+	deliveries := make([]*core.EventDelivery, 4)
+	for i := range deliveries {
+		deliveries[i] = newTestEvent(sub, "topic1")
+		err := mq.DeliveryRequest(context.Background(), "conn1", sub, deliveries[i], nil)
+		assert.NoError(t, err)
+	}
+	broker.release <- true
+	broker.release <- true
+
+	for i := range deliveries {
+		res := <-acked
+		assert.Equal(t, deliveries[i].ID, res.ID)
+		assert.False(t, res.Rejected)
+	}
+
+	published := broker.published("firefly.ns1.sub1")
+	assert.Len(t, published, 4)
+	assert.LessOrEqual(t, len(broker.batches), 2)
+	for _, b := range broker.batches {
+		assert.LessOrEqual(t, b, 3)
+	}
+}
+
+func TestDeliveryRejectedOnPublishFailure(t *testing.T) {
+	broker := newTestBroker()
+	broker.failCount = 1
+	mq, cbs, cancel := newTestMQ(t, broker)
+	defer cancel()
+
+	failed := make(chan struct{})
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionDeliveryFailed", "ns1", "sub1", "mq").Run(func(a mock.Arguments) {
+		close(failed)
+	}).Return()
+	mq.metrics = mmi
+
+	sub := newTestSub(0, false)
+	event := newTestEvent(sub, "topic1")
+
+	acked := make(chan *core.EventDeliveryResponse)
+	cbs.On("DeliveryResponse", "conn1", mock.Anything).Run(func(a mock.Arguments) {
+		acked <- a[1].(*core.EventDeliveryResponse)
+	}).Return()
+
+	err := mq.DeliveryRequest(context.Background(), "conn1", sub, event, nil)
+	assert.NoError(t, err)
+
+	res := <-acked
+	assert.Equal(t, event.ID, res.ID)
+	assert.True(t, res.Rejected)
+	assert.Regexp(t, "pop", res.Info)
+	assert.Empty(t, broker.published("firefly.ns1.sub1"))
+
+	<-failed
+	mmi.AssertExpectations(t)
+}
+
+func TestPublishLoopStopsOnClose(t *testing.T) {
+	mq, _, cancel := newTestMQ(t, newTestBroker())
+	cancel()
+
+	sub := newTestSub(0, false)
+	pub := &publisher{queue: []*delivery{{event: newTestEvent(sub, "topic1")}}}
+	mq.publishers[*sub.ID] = pub
+	mq.publishLoop(sub.ID, pub)
+	assert.Empty(t, mq.publishers)
+}
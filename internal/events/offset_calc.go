@@ -63,3 +63,36 @@ func calcFirstOffset(ctx context.Context, di database.Plugin, pfe *core.SubOptsF
 	log.L(ctx).Debugf("Event poller initial offest: %d (newest=%t)", firstOffset, useNewest)
 	return firstOffset, err
 }
+
+// calcOffsetLag is the number of events in the namespace with a sequence greater than the offset of a subscription,
+// used for both the lag reported on the subscription status and the lag metric so the two always agree
+func calcOffsetLag(offset, latest int64) int64 {
+	if latest > offset {
+		return latest - offset
+	}
+	return 0
+}
+
+// calcResetOffset determines the offset to move a durable subscription to, so that delivery restarts
+// either after the specified event sequence, or from the first event created at or after the specified time
+func calcResetOffset(ctx context.Context, di database.Plugin, namespace string, reset *core.SubscriptionReset) (int64, error) {
+	if (reset.FirstEvent == nil) == (reset.Timestamp == nil) {
+		return -1, i18n.NewError(ctx, coremsgs.MsgInvalidSubscriptionReset)
+	}
+	if reset.FirstEvent != nil {
+		return calcFirstOffset(ctx, di, reset.FirstEvent)
+	}
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	f := fb.And(
+		fb.Eq("namespace", namespace),
+		fb.Lt("created", reset.Timestamp),
+	).Sort("sequence").Descending().Limit(1)
+	previousEvents, _, err := di.GetEvents(ctx, f)
+	if err != nil {
+		return -1, err
+	}
+	if len(previousEvents) > 0 {
+		return previousEvents[0].Sequence, nil
+	}
+	return -1, nil
+}
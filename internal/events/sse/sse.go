@@ -0,0 +1,235 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+// SSE streams events to clients over Server-Sent Events, which browsers consume natively with EventSource.
+// The stream is one-way, so every event is acknowledged as soon as it has been written to the connection.
+type SSE struct {
+	ctx               context.Context
+	capabilities      *events.Capabilities
+	callbacks         events.Callbacks
+	metrics           metrics.Manager
+	connections       map[string]*sseConnection
+	connMux           sync.Mutex
+	heartbeatInterval time.Duration
+}
+
+// sseStart is the subscription requested in the query parameters of the stream
+type sseStart struct {
+	namespace   string
+	name        string
+	ephemeral   bool
+	filter      core.SubscriptionFilter
+	options     core.SubscriptionOptions
+	lastEventID *int64
+}
+
+func (s *SSE) Name() string { return "sse" }
+
+func (s *SSE) Init(ctx context.Context, config config.Section, callbacks events.Callbacks, metrics metrics.Manager) error {
+	*s = SSE{
+		ctx:               ctx,
+		connections:       make(map[string]*sseConnection),
+		capabilities:      &events.Capabilities{},
+		callbacks:         callbacks,
+		metrics:           metrics,
+		heartbeatInterval: config.GetDuration(HeartbeatInterval),
+	}
+	return nil
+}
+
+func (s *SSE) Capabilities() *events.Capabilities {
+	return s.capabilities
+}
+
+func (s *SSE) ValidateOptions(options *core.SubscriptionOptions) error {
+	if options.WithData == nil {
+		defaultFalse := false
+		options.WithData = &defaultFalse
+	}
+	return nil
+}
+
+func (s *SSE) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	s.connMux.Lock()
+	conn, ok := s.connections[connID]
+	s.connMux.Unlock()
+	if !ok {
+		s.reportDeliveryFailed(sub)
+		return i18n.NewError(s.ctx, coremsgs.MsgSSEConnectionNotActive, connID)
+	}
+	err := conn.dispatch(sub, event, data)
+	if err != nil {
+		s.reportDeliveryFailed(sub)
+	}
+	return err
+}
+
+func (s *SSE) reportDeliveryFailed(sub *core.Subscription) {
+	if s.metrics.IsMetricsEnabled() {
+		s.metrics.SubscriptionDeliveryFailed(sub.Namespace, metrics.SubscriptionLabel(sub), s.Name())
+	}
+}
+
+// reportConnections must be called with the connection lock held
+func (s *SSE) reportConnections() {
+	if s.metrics.IsMetricsEnabled() {
+		s.metrics.TransportConnections(s.Name(), len(s.connections))
+	}
+}
+
+func (s *SSE) BatchDeliveryRequest(connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	return i18n.NewError(s.ctx, coremsgs.MsgBatchDeliveryNotSupported, s.Name())
+}
+
+func (s *SSE) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if s.callbacks == nil {
+		errorReply(res, http.StatusNotFound, i18n.NewError(req.Context(), coremsgs.MsgSSENotEnabled))
+		return
+	}
+	start, err := parseStart(req)
+	if err != nil {
+		errorReply(res, http.StatusBadRequest, err)
+		return
+	}
+
+	s.connMux.Lock()
+	sc := newConnection(s.ctx, s, req, start)
+	s.connections[sc.connID] = sc
+	s.reportConnections()
+	s.connMux.Unlock()
+
+	// Deliveries queue on the connection until the stream is open
+	if err := s.start(sc); err != nil {
+		sc.close()
+		errorReply(res, http.StatusBadRequest, err)
+		return
+	}
+	stream, err := openStream(req.Context(), res, sc.cancelCtx)
+	if err != nil {
+		sc.close()
+		errorReply(res, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Block until the client goes away
+	sc.sendLoop(stream)
+}
+
+func (s *SSE) start(sc *sseConnection) error {
+	start := sc.start
+	if start.ephemeral {
+		return s.callbacks.EphemeralSubscription(sc.connID, start.namespace, &start.filter, &start.options)
+	}
+	if start.lastEventID != nil {
+		if err := s.callbacks.RewindSubscription(start.namespace, start.name, *start.lastEventID); err != nil {
+			return err
+		}
+	}
+	return s.callbacks.RegisterConnection(sc.connID, func(sr core.SubscriptionRef) bool {
+		return sr.Namespace == start.namespace && sr.Name == start.name
+	})
+}
+
+func (s *SSE) ack(connID string, inflight *core.EventDeliveryResponse) {
+	s.callbacks.DeliveryResponse(connID, inflight)
+}
+
+func (s *SSE) connClosed(connID string) {
+	s.connMux.Lock()
+	delete(s.connections, connID)
+	s.reportConnections()
+	s.connMux.Unlock()
+	// Drop lock before calling back
+	s.callbacks.ConnectionClosed(connID)
+}
+
+// parseStart reads the subscription from the query parameters. The filter and options are only used for
+// ephemeral subscriptions - a durable subscription is referred to by name, and uses its stored definition.
+// A browser reconnecting after an error sends the sequence of the last event it received in the Last-Event-ID
+// header, and the stream resumes with the event after that one.
+func parseStart(req *http.Request) (*sseStart, error) {
+	ctx := req.Context()
+	query := req.URL.Query()
+	ephemeral, hasEphemeral := query["ephemeral"]
+	start := &sseStart{
+		namespace: query.Get("namespace"),
+		name:      query.Get("name"),
+		ephemeral: hasEphemeral && (len(ephemeral) == 0 || ephemeral[0] != "false"),
+		filter:    core.NewSubscriptionFilterFromQuery(query),
+	}
+	if start.namespace == "" || (!start.ephemeral && start.name == "") {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSSEInvalidStart)
+	}
+
+	if readAhead := query.Get("readAhead"); readAhead != "" {
+		ra, err := strconv.ParseUint(readAhead, 10, 16)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgSSEInvalidQueryParam, readAhead, "readAhead")
+		}
+		ra16 := uint16(ra)
+		start.options.ReadAhead = &ra16
+	}
+	if withData := query.Get("withData"); withData != "" {
+		wd, err := strconv.ParseBool(withData)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgSSEInvalidQueryParam, withData, "withData")
+		}
+		start.options.WithData = &wd
+	}
+	if firstEvent := query.Get("firstEvent"); firstEvent != "" {
+		fe := core.SubOptsFirstEvent(firstEvent)
+		start.options.FirstEvent = &fe
+	}
+
+	if lastEventID := req.Header.Get("Last-Event-ID"); lastEventID != "" {
+		sequence, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || sequence < -1 {
+			return nil, i18n.NewError(ctx, coremsgs.MsgSSEInvalidLastEventID, lastEventID)
+		}
+		start.lastEventID = &sequence
+		// The offset of a subscription is the last event processed, so this resumes with the event after
+		fe := core.SubOptsFirstEvent(lastEventID)
+		start.options.FirstEvent = &fe
+	}
+	return start, nil
+}
+
+func errorReply(res http.ResponseWriter, status int, err error) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	_ = json.NewEncoder(res).Encode(&fftypes.RESTError{
+		Error: err.Error(),
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+type sseConnection struct {
+	ctx          context.Context
+	sse          *SSE
+	cancelCtx    func()
+	connID       string
+	start        *sseStart
+	sendMessages chan *sseMessage
+	mux          sync.Mutex
+	closed       bool
+}
+
+type sseMessage struct {
+	sequence int64
+	payload  []byte
+	inflight *core.EventDeliveryResponse
+}
+
+type ssePayload struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+func newConnection(pCtx context.Context, s *SSE, req *http.Request, start *sseStart) *sseConnection {
+	connID := fftypes.NewUUID().String()
+	ctx := log.WithLogField(pCtx, "sse", connID)
+	ctx, cancelCtx := context.WithCancel(ctx)
+	sc := &sseConnection{
+		ctx:          ctx,
+		sse:          s,
+		cancelCtx:    cancelCtx,
+		connID:       connID,
+		start:        start,
+		sendMessages: make(chan *sseMessage),
+	}
+	log.L(ctx).Infof("SSE connection from %s (%s) for %s:%s ephemeral=%t", req.RemoteAddr, req.UserAgent(), start.namespace, start.name, start.ephemeral)
+	return sc
+}
+
+func (sc *sseConnection) dispatch(sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	payload := &ssePayload{EventDelivery: event}
+	if sub.Options.WithData != nil && *sub.Options.WithData {
+		payload.Data = data
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	msg := &sseMessage{
+		sequence: event.Sequence,
+		payload:  b,
+		inflight: &core.EventDeliveryResponse{
+			ID:           event.ID,
+			Subscription: event.Subscription,
+		},
+	}
+	select {
+	case sc.sendMessages <- msg:
+		return nil
+	case <-sc.ctx.Done():
+		return i18n.NewError(sc.ctx, coremsgs.MsgSSEConnectionNotActive, sc.connID)
+	}
+}
+
+func (sc *sseConnection) sendLoop(stream *sseStream) {
+	l := log.L(sc.ctx)
+	defer sc.close()
+	heartbeat := time.NewTicker(sc.sse.heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case msg := <-sc.sendMessages:
+			l.Tracef("Sending event %d", msg.sequence)
+			err = stream.writeEvent(msg.sequence, msg.payload)
+			if err == nil {
+				// The client cannot acknowledge over the stream, so we ack once it has been written
+				sc.sse.ack(sc.connID, msg.inflight)
+			}
+		case <-heartbeat.C:
+			err = stream.writeComment("heartbeat")
+		case <-sc.ctx.Done():
+			l.Debugf("Sender closing - context cancelled")
+			return
+		}
+		if err != nil {
+			l.Errorf("Write failed on stream: %s", err)
+			return
+		}
+	}
+}
+
+func (sc *sseConnection) close() {
+	var didClose bool
+	sc.mux.Lock()
+	if !sc.closed {
+		didClose = true
+		sc.closed = true
+		sc.cancelCtx()
+	}
+	sc.mux.Unlock()
+	// Drop lock before callback
+	if didClose {
+		sc.sse.connClosed(sc.connID)
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfig = config.RootSection("ut.sse")
+
+func newTestSSE(t *testing.T, cbs *eventsmocks.Callbacks) (*SSE, *httptest.Server, func()) {
+	coreconfig.Reset()
+
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false)
+	s := &SSE{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	s.InitConfig(utConfig)
+	err := s.Init(ctx, utConfig, cbs, mmi)
+	assert.NoError(t, err)
+	assert.Equal(t, "sse", s.Name())
+	assert.NotNil(t, s.Capabilities())
+
+	svr := httptest.NewServer(s)
+	return s, svr, func() {
+		cancelCtx()
+		svr.Close()
+	}
+}
+
+func connect(t *testing.T, svr *httptest.Server, query string, lastEventID string) (*http.Response, *bufio.Reader) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/?%s", svr.URL, query), nil)
+	assert.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return res, bufio.NewReader(res.Body)
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func newTestEvent(sub *core.Subscription, sequence int64) *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Namespace: sub.Namespace,
+				Sequence:  sequence,
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	s, _, cancel := newTestSSE(t, &eventsmocks.Callbacks{})
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	err := s.ValidateOptions(opts)
+	assert.NoError(t, err)
+	assert.False(t, *opts.WithData)
+}
+
+func TestBatchDeliveryNotSupported(t *testing.T) {
+	s, _, cancel := newTestSSE(t, &eventsmocks.Callbacks{})
+	defer cancel()
+
+	err := s.BatchDeliveryRequest("conn1", &core.Subscription{}, []*core.CombinedEventDataDelivery{})
+	assert.Regexp(t, "FF10433.*sse", err)
+}
+
+func TestDeliveryRequestNoConnection(t *testing.T) {
+	s, _, cancel := newTestSSE(t, &eventsmocks.Callbacks{})
+	defer cancel()
+
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionDeliveryFailed", "ns1", "sub1", "sse").Return()
+	s.metrics = mmi
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+	}
+	err := s.DeliveryRequest(context.Background(), "conn1", sub, &core.EventDelivery{}, nil)
+	assert.Regexp(t, "FF10444", err)
+
+	mmi.AssertExpectations(t)
+}
+
+func TestConnClosedMetrics(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("ConnectionClosed", "conn1").Return()
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("TransportConnections", "sse", 0).Return()
+	s.metrics = mmi
+
+	s.connections["conn1"] = &sseConnection{}
+	s.connClosed("conn1")
+
+	cbs.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestEphemeralStreamAutoAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	connIDs := make(chan string, 1)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.MatchedBy(func(filter *core.SubscriptionFilter) bool {
+		return filter.Topic == "topic1"
+	}), mock.MatchedBy(func(options *core.SubscriptionOptions) bool {
+		return *options.ReadAhead == 5 && *options.WithData && *options.FirstEvent == "10"
+	})).Run(func(a mock.Arguments) {
+		connIDs <- a[0].(string)
+	}).Return(nil)
+	acked := make(chan *core.EventDeliveryResponse, 1)
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Run(func(a mock.Arguments) {
+		acked <- a[1].(*core.EventDeliveryResponse)
+	}).Return()
+	closed := make(chan struct{})
+	cbs.On("ConnectionClosed", mock.Anything).Run(func(a mock.Arguments) {
+		close(closed)
+	}).Return()
+
+	res, reader := connect(t, svr, "namespace=ns1&ephemeral&filter.topic=topic1&readAhead=5&withData=true&firstEvent=oldest", "10")
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	connID := <-connIDs
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: connID},
+		Options:         core.SubscriptionOptions{SubscriptionCoreOptions: core.SubscriptionCoreOptions{WithData: new(bool)}},
+	}
+	*sub.Options.WithData = true
+	event := newTestEvent(sub, 11)
+	go func() {
+		err := s.DeliveryRequest(context.Background(), connID, sub, event, core.DataArray{
+			{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"data1"`)},
+		})
+		assert.NoError(t, err)
+	}()
+
+	lines := readEvent(t, reader)
+	assert.Equal(t, "id: 11", lines[0])
+	payload := fftypes.JSONObject{}
+	err := payload.Scan(strings.TrimPrefix(lines[1], "data: "))
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID.String(), payload.GetString("id"))
+	assert.Equal(t, "data1", payload.GetObjectArray("data")[0]["value"])
+
+	ack := <-acked
+	assert.Equal(t, event.ID, ack.ID)
+	assert.False(t, ack.Rejected)
+
+	res.Body.Close()
+	<-closed
+
+	err = s.DeliveryRequest(context.Background(), connID, sub, event, nil)
+	assert.Regexp(t, "FF10444", err)
+}
+
+func TestDurableStreamResume(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+	s.heartbeatInterval = 1
+
+	cbs.On("RewindSubscription", "ns1", "sub1", int64(5)).Return(nil)
+	cbs.On("RegisterConnection", mock.Anything, mock.MatchedBy(func(matcher events.SubscriptionMatcher) bool {
+		return matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub1"}) &&
+			!matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub2"})
+	})).Return(nil)
+	cbs.On("ConnectionClosed", mock.Anything).Return().Maybe()
+
+	res, reader := connect(t, svr, "namespace=ns1&name=sub1", "5")
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	assert.Equal(t, []string{": heartbeat"}, readEvent(t, reader))
+	cbs.AssertExpectations(t)
+}
+
+func TestDurableStreamRewindFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("RewindSubscription", "ns1", "sub1", int64(5)).Return(fmt.Errorf("pop"))
+	cbs.On("ConnectionClosed", mock.Anything).Return()
+
+	res, _ := connect(t, svr, "namespace=ns1&name=sub1", "5")
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+	cbs.AssertExpectations(t)
+}
+
+func TestStreamBadRequests(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	for _, tc := range []struct {
+		query, lastEventID, err string
+	}{
+		{"name=sub1", "", "FF10440"},
+		{"namespace=ns1", "", "FF10440"},
+		{"namespace=ns1&ephemeral=false", "", "FF10440"},
+		{"namespace=ns1&ephemeral&readAhead=-1", "", "FF10441.*readAhead"},
+		{"namespace=ns1&ephemeral&withData=maybe", "", "FF10441.*withData"},
+		{"namespace=ns1&ephemeral", "abc", "FF10442"},
+		{"namespace=ns1&ephemeral", "-2", "FF10442"},
+	} {
+		res, reader := connect(t, svr, tc.query, tc.lastEventID)
+		assert.Equal(t, 400, res.StatusCode)
+		body, _ := reader.ReadString('\n')
+		assert.Regexp(t, tc.err, body)
+		res.Body.Close()
+	}
+}
+
+func TestStreamNotEnabled(t *testing.T) {
+	s := &SSE{}
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/sse?namespace=ns1&ephemeral", nil))
+	assert.Equal(t, 404, res.Code)
+	assert.Regexp(t, "FF10439", res.Body.String())
+}
+
+func TestStreamOutlivesServerWriteTimeout(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	svr := httptest.NewUnstartedServer(s)
+	svr.Config.WriteTimeout = 10 * time.Millisecond
+	svr.Start()
+	defer svr.Close()
+
+	connIDs := make(chan string, 1)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Run(func(a mock.Arguments) {
+		connIDs <- a[0].(string)
+	}).Return(nil)
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Return().Maybe()
+	cbs.On("ConnectionClosed", mock.Anything).Return().Maybe()
+
+	res, reader := connect(t, svr, "namespace=ns1&ephemeral", "")
+	assert.Equal(t, 200, res.StatusCode)
+	defer res.Body.Close()
+	connID := <-connIDs
+
+	// Deliver after the write timeout of the server has passed
+	time.Sleep(50 * time.Millisecond)
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: connID},
+	}
+	go func() {
+		err := s.DeliveryRequest(context.Background(), connID, sub, newTestEvent(sub, 12), nil)
+		assert.NoError(t, err)
+	}()
+
+	lines := readEvent(t, reader)
+	assert.Equal(t, "id: 12", lines[0])
+}
+
+func TestStreamWithoutWriteDeadline(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	cbs.On("ConnectionClosed", mock.Anything).Return()
+
+	ctx, cancelReq := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/sse?namespace=ns1&ephemeral", nil).WithContext(ctx)
+	res := httptest.NewRecorder()
+	cancelReq()
+	s.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	cbs.AssertExpectations(t)
+}
+
+type nonStreamingWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *nonStreamingWriter) Header() http.Header         { return w.header }
+func (w *nonStreamingWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *nonStreamingWriter) WriteHeader(status int)      { w.status = status }
+
+func TestStreamNotSupported(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	cbs.On("ConnectionClosed", mock.Anything).Return()
+
+	res := &nonStreamingWriter{header: http.Header{}}
+	s.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/sse?namespace=ns1&ephemeral", nil))
+	assert.Equal(t, 500, res.status)
+	assert.Empty(t, s.connections)
+	cbs.AssertExpectations(t)
+}
+
+func TestDispatchMarshalFail(t *testing.T) {
+	s, _, cancel := newTestSSE(t, &eventsmocks.Callbacks{})
+	defer cancel()
+
+	sc := newConnection(s.ctx, s, httptest.NewRequest(http.MethodGet, "/sse", nil), &sseStart{})
+	sub := &core.Subscription{}
+	sub.Options.WithData = new(bool)
+	*sub.Options.WithData = true
+	err := sc.dispatch(sub, &core.EventDelivery{}, core.DataArray{
+		{Value: fftypes.JSONAnyPtr(`!json`)},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+type sseStream struct {
+	writer  http.ResponseWriter
+	flusher http.Flusher
+}
+
+func setStreamHeaders(header http.Header) {
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+}
+
+// openStream writes the response headers for the event stream, and flushes them to the client. The stream
+// stays open for as long as the client is connected, so it is exempt from the write timeout of the HTTP server.
+func openStream(ctx context.Context, res http.ResponseWriter, onClientClosed func()) (*sseStream, error) {
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSSEStreamingNotSupported)
+	}
+	if err := http.NewResponseController(res).SetWriteDeadline(time.Time{}); err != nil {
+		if !errors.Is(err, http.ErrNotSupported) {
+			return nil, err
+		}
+		log.L(ctx).Warnf("Unable to clear the write deadline for the event stream - it will be closed by the server write timeout")
+	}
+	setStreamHeaders(res.Header())
+	res.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	// The request context is cancelled when the client goes away
+	go func() {
+		<-ctx.Done()
+		onClientClosed()
+	}()
+	return &sseStream{
+		writer:  res,
+		flusher: flusher,
+	}, nil
+}
+
+func (ss *sseStream) writeEvent(sequence int64, data []byte) error {
+	_, err := fmt.Fprintf(ss.writer, "id: %d\ndata: %s\n\n", sequence, data)
+	if err == nil {
+		ss.flusher.Flush()
+	}
+	return err
+}
+
+func (ss *sseStream) writeComment(comment string) error {
+	_, err := fmt.Fprintf(ss.writer, ": %s\n\n", comment)
+	if err == nil {
+		ss.flusher.Flush()
+	}
+	return err
+}
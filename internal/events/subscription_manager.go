@@ -18,8 +18,10 @@ package events
 
 import (
 	"context"
+	"math/big"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -32,6 +34,7 @@ import (
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
 	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -47,6 +50,8 @@ type subscription struct {
 	messageFilter      *messageFilter
 	blockchainFilter   *blockchainFilter
 	transactionFilter  *transactionFilter
+	tokenFilter        *tokenFilter
+	dataFilter         *dataFilter
 	topicFilter        *regexp.Regexp
 }
 
@@ -65,6 +70,19 @@ type transactionFilter struct {
 	typeFilter *regexp.Regexp
 }
 
+type tokenFilter struct {
+	poolFilter *regexp.Regexp
+	fromFilter *regexp.Regexp
+	toFilter   *regexp.Regexp
+	minAmount  *big.Int
+	maxAmount  *big.Int
+}
+
+type dataFilter struct {
+	jsonPath    jsonPath
+	valueFilter *regexp.Regexp
+}
+
 type connection struct {
 	id          string
 	transport   string
@@ -81,6 +99,7 @@ type subscriptionManager struct {
 	eventNotifier             *eventNotifier
 	broadcast                 broadcast.Manager
 	messaging                 privatemessaging.Manager
+	metrics                   metrics.Manager
 	transports                map[string]events.Plugin
 	connections               map[string]*connection
 	mux                       sync.Mutex
@@ -92,7 +111,7 @@ type subscriptionManager struct {
 	retry                     retry.Retry
 }
 
-func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Manager, en *eventNotifier, bm broadcast.Manager, pm privatemessaging.Manager, txHelper txcommon.Helper) (*subscriptionManager, error) {
+func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Manager, en *eventNotifier, bm broadcast.Manager, pm privatemessaging.Manager, mm metrics.Manager, txHelper txcommon.Helper) (*subscriptionManager, error) {
 	ctx, cancelCtx := context.WithCancel(ctx)
 	sm := &subscriptionManager{
 		ctx:                       ctx,
@@ -108,6 +127,7 @@ func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Man
 		eventNotifier:             en,
 		broadcast:                 bm,
 		messaging:                 pm,
+		metrics:                   mm,
 		txHelper:                  txHelper,
 		retry: retry.Retry{
 			InitialDelay: config.GetDuration(coreconfig.SubscriptionsRetryInitialDelay),
@@ -146,7 +166,7 @@ func (sm *subscriptionManager) initTransports() error {
 	for _, ei := range sm.transports {
 		config := config.RootSection("events").SubSection(ei.Name())
 		ei.InitConfig(config)
-		err = ei.Init(sm.ctx, config, &boundCallbacks{sm: sm, ei: ei})
+		err = ei.Init(sm.ctx, config, &boundCallbacks{sm: sm, ei: ei}, sm.metrics)
 		if err != nil {
 			return err
 		}
@@ -260,6 +280,7 @@ func (sm *subscriptionManager) closeDurableSubscriptionLocked(id *fftypes.UUID)
 
 func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	sm.mux.Lock()
+	sub := sm.durableSubs[*id]
 	loaded, dispatchers := sm.closeDurableSubscriptionLocked(id)
 	sm.mux.Unlock()
 
@@ -269,6 +290,10 @@ func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	for _, dispatcher := range dispatchers {
 		dispatcher.close()
 	}
+	// Once the dispatchers have stopped reporting, remove the metrics for the subscription
+	if sub != nil && sm.metrics.IsMetricsEnabled() {
+		sm.metrics.SubscriptionDeleted(sub.definition.Namespace, metrics.SubscriptionLabel(sub.definition), sub.definition.Transport)
+	}
 	// Delete the offsets, as the durable subscriptions are gone
 	err := sm.database.DeleteOffset(sm.ctx, core.OffsetTypeSubscription, id.String())
 	if err != nil {
@@ -276,6 +301,81 @@ func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	}
 }
 
+// getDurableSubscriptionOffset returns the offset of the dispatcher delivering a subscription on this node, if there is one.
+// This is the offset the lag metric is reported from, and can be ahead of the last offset committed to the database
+func (sm *subscriptionManager) getDurableSubscriptionOffset(id *fftypes.UUID) (int64, bool) {
+	sm.mux.Lock()
+	defer sm.mux.Unlock()
+	for _, conn := range sm.connections {
+		if dispatcher, ok := conn.dispatchers[*id]; ok {
+			if offset, restored := dispatcher.eventPoller.getRestoredOffset(); restored {
+				return offset, true
+			}
+		}
+	}
+	return -1, false
+}
+
+// resetDurableSubscriptionOffset stops any active dispatchers for the subscription, so the new offset cannot
+// be overwritten by an in-flight commit, then stores the new offset and restarts the dispatchers from it
+func (sm *subscriptionManager) resetDurableSubscriptionOffset(ctx context.Context, id *fftypes.UUID, offset int64) error {
+	sm.mux.Lock()
+	sub := sm.durableSubs[*id]
+	loaded, dispatchers := sm.closeDurableSubscriptionLocked(id)
+	sm.mux.Unlock()
+
+	log.L(ctx).Infof("Resetting subscription %s to offset %d loaded=%t dispatchers=%d", id, offset, loaded, len(dispatchers))
+
+	// Outside the lock, close out the active dispatchers
+	for _, dispatcher := range dispatchers {
+		dispatcher.close()
+	}
+	err := sm.database.UpsertOffset(ctx, &core.Offset{
+		Type:    core.OffsetTypeSubscription,
+		Name:    id.String(),
+		Current: offset,
+	}, true)
+
+	// Restart the subscription even if we failed to store the offset, unless it was updated while we were stopped
+	if loaded {
+		sm.mux.Lock()
+		defer sm.mux.Unlock()
+		if _, updated := sm.durableSubs[*id]; !updated {
+			sm.durableSubs[*id] = sub
+			for _, conn := range sm.connections {
+				sm.matchSubToConnLocked(conn, sub)
+			}
+		}
+	}
+	return err
+}
+
+// rewindDurableSubscription is called by a transport, so only subscriptions delivered over that transport can be rewound
+func (sm *subscriptionManager) rewindDurableSubscription(ei events.Plugin, namespace, name string, sequence int64) error {
+	var id *fftypes.UUID
+	sm.mux.Lock()
+	for _, sub := range sm.durableSubs {
+		if sub.definition.Namespace == namespace && sub.definition.Name == name && sub.definition.Transport == ei.Name() {
+			id = sub.definition.ID
+			break
+		}
+	}
+	sm.mux.Unlock()
+	if id == nil {
+		return i18n.NewError(sm.ctx, coremsgs.MsgDurableSubscriptionNotFound, name, namespace)
+	}
+
+	offset, err := sm.database.GetOffset(sm.ctx, core.OffsetTypeSubscription, id.String())
+	if err != nil {
+		return err
+	}
+	if offset != nil && offset.Current <= sequence {
+		log.L(sm.ctx).Debugf("Subscription %s:%s already at offset %d (requested=%d)", namespace, name, offset.Current, sequence)
+		return nil
+	}
+	return sm.resetDurableSubscriptionOffset(sm.ctx, id, sequence)
+}
+
 func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef *core.Subscription) (sub *subscription, err error) {
 	filter := subDef.Filter
 
@@ -288,6 +388,25 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		return nil, err
 	}
 
+	if subDef.Options.Batch != nil && *subDef.Options.Batch {
+		if !transport.Capabilities().BatchDelivery {
+			return nil, i18n.NewError(ctx, coremsgs.MsgBatchDeliveryNotSupported, transport.Name())
+		}
+		if subDef.Options.BatchTimeout != nil {
+			if _, err := fftypes.ParseDurationString(*subDef.Options.BatchTimeout, time.Millisecond); err != nil {
+				return nil, i18n.NewError(ctx, coremsgs.MsgInvalidSubscriptionBatchTimeout, *subDef.Options.BatchTimeout, err)
+			}
+		}
+	}
+
+	if subDef.Options.PartitionBy != nil {
+		switch *subDef.Options.PartitionBy {
+		case core.SubOptsPartitionByTopic, core.SubOptsPartitionByAuthor, core.SubOptsPartitionByGroup:
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidSubscriptionPartitionBy, *subDef.Options.PartitionBy)
+		}
+	}
+
 	var eventFilter *regexp.Regexp
 	if filter.Events != "" {
 		eventFilter, err = regexp.Compile(filter.Events)
@@ -414,9 +533,66 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		sub.transactionFilter = tf
 	}
 
+	if filter.Token != nil {
+		if sub.tokenFilter, err = parseTokenFilter(ctx, filter.Token); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Data != nil {
+		if sub.dataFilter, err = parseDataFilter(ctx, filter.Data); err != nil {
+			return nil, err
+		}
+	}
+
 	return sub, err
 }
 
+func parseTokenFilter(ctx context.Context, filter *core.TokenFilter) (tf *tokenFilter, err error) {
+	tf = &tokenFilter{}
+	if filter.Pool != "" {
+		if tf.poolFilter, err = regexp.Compile(filter.Pool); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgRegexpCompileFailed, "filter.token.pool", filter.Pool)
+		}
+	}
+	if filter.From != "" {
+		if tf.fromFilter, err = regexp.Compile(filter.From); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgRegexpCompileFailed, "filter.token.from", filter.From)
+		}
+	}
+	if filter.To != "" {
+		if tf.toFilter, err = regexp.Compile(filter.To); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgRegexpCompileFailed, "filter.token.to", filter.To)
+		}
+	}
+	if filter.MinAmount != "" {
+		var ok bool
+		if tf.minAmount, ok = new(big.Int).SetString(filter.MinAmount, 10); !ok {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidSubscriptionAmount, filter.MinAmount, "filter.token.minAmount")
+		}
+	}
+	if filter.MaxAmount != "" {
+		var ok bool
+		if tf.maxAmount, ok = new(big.Int).SetString(filter.MaxAmount, 10); !ok {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidSubscriptionAmount, filter.MaxAmount, "filter.token.maxAmount")
+		}
+	}
+	return tf, nil
+}
+
+func parseDataFilter(ctx context.Context, filter *core.DataFilter) (df *dataFilter, err error) {
+	df = &dataFilter{}
+	if df.jsonPath, err = parseJSONPath(ctx, filter.JSONPath); err != nil {
+		return nil, err
+	}
+	if filter.Value != "" {
+		if df.valueFilter, err = regexp.Compile(filter.Value); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgRegexpCompileFailed, "filter.data.value", filter.Value)
+		}
+	}
+	return df, nil
+}
+
 func (sm *subscriptionManager) close() {
 	sm.mux.Lock()
 	conns := make([]*connection, 0, len(sm.connections))
@@ -479,7 +655,7 @@ func (sm *subscriptionManager) matchSubToConnLocked(conn *connection, sub *subsc
 	}
 	if conn.transport == sub.definition.Transport && conn.matcher(sub.definition.SubscriptionRef) {
 		if _, ok := conn.dispatchers[*sub.definition.ID]; !ok {
-			dispatcher := newEventDispatcher(sm.ctx, conn.ei, sm.database, sm.data, sm.broadcast, sm.messaging, conn.id, sub, sm.eventNotifier, sm.txHelper)
+			dispatcher := newEventDispatcher(sm.ctx, conn.ei, sm.database, sm.data, sm.broadcast, sm.messaging, sm.metrics, conn.id, sub, sm.eventNotifier, sm.txHelper)
 			conn.dispatchers[*sub.definition.ID] = dispatcher
 			dispatcher.start()
 		}
@@ -516,7 +692,7 @@ func (sm *subscriptionManager) ephemeralSubscription(ei events.Plugin, connID, n
 	}
 
 	// Create the dispatcher, and start immediately
-	dispatcher := newEventDispatcher(sm.ctx, ei, sm.database, sm.data, sm.broadcast, sm.messaging, connID, newSub, sm.eventNotifier, sm.txHelper)
+	dispatcher := newEventDispatcher(sm.ctx, ei, sm.database, sm.data, sm.broadcast, sm.messaging, sm.metrics, connID, newSub, sm.eventNotifier, sm.txHelper)
 	dispatcher.start()
 
 	conn.dispatchers[*subID] = dispatcher
@@ -569,3 +745,19 @@ func (sm *subscriptionManager) deliveryResponse(ei events.Plugin, connID string,
 	sm.mux.Unlock()
 	dispatcher.deliveryResponse(inflight)
 }
+
+func (sm *subscriptionManager) deadLetter(ei events.Plugin, connID string, deadLetter *core.DeadLetter) error {
+	sm.mux.Lock()
+	conn, ok := sm.connections[connID]
+	if ok && conn.ei != ei {
+		sm.mux.Unlock()
+		return i18n.NewError(sm.ctx, coremsgs.MsgMismatchedTransport, connID, ei.Name(), conn.ei.Name())
+	}
+	sm.mux.Unlock()
+
+	deadLetter.ID = fftypes.NewUUID()
+	deadLetter.Transport = ei.Name()
+	log.L(sm.ctx).Warnf("Recording dead letter %s for %s event %s on subscription %s:%s after %d attempts: %s",
+		deadLetter.ID, ei.Name(), deadLetter.Event, deadLetter.Namespace, deadLetter.Subscription.Name, deadLetter.Attempts, deadLetter.Error)
+	return sm.database.InsertDeadLetter(sm.ctx, deadLetter)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
@@ -44,16 +45,18 @@ func newTestSubManager(t *testing.T, mei *eventsmocks.Plugin) (*subscriptionMana
 	mdm := &datamocks.Manager{}
 	mbm := &broadcastmocks.Manager{}
 	mpm := &privatemessagingmocks.Manager{}
+	mmi := &metricsmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 
 	ctx, cancel := context.WithCancel(context.Background())
+	mmi.On("IsMetricsEnabled").Return(false).Maybe()
 	mei.On("Name").Return("ut")
 	mei.On("Capabilities").Return(&events.Capabilities{}).Maybe()
 	mei.On("InitConfig", mock.Anything).Return()
-	mei.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mei.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil).Maybe()
 	mdi.On("GetOffset", mock.Anything, mock.Anything, mock.Anything).Return(&core.Offset{RowID: 3333333, Current: 0}, nil).Maybe()
-	sm, err := newSubscriptionManager(ctx, mdi, mdm, newEventNotifier(ctx, "ut"), mbm, mpm, txHelper)
+	sm, err := newSubscriptionManager(ctx, mdi, mdm, newEventNotifier(ctx, "ut"), mbm, mpm, mmi, txHelper)
 	assert.NoError(t, err)
 	sm.transports = map[string]events.Plugin{
 		"ut": mei,
@@ -175,7 +178,7 @@ func TestSubManagerBadPlugin(t *testing.T) {
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	coreconfig.Reset()
 	config.Set(coreconfig.EventTransportsEnabled, []string{"!unknown!"})
-	_, err := newSubscriptionManager(context.Background(), mdi, mdm, newEventNotifier(context.Background(), "ut"), mbm, mpm, txHelper)
+	_, err := newSubscriptionManager(context.Background(), mdi, mdm, newEventNotifier(context.Background(), "ut"), mbm, mpm, &metricsmocks.Manager{}, txHelper)
 	assert.Regexp(t, "FF10172", err)
 }
 
@@ -183,7 +186,7 @@ func TestSubManagerTransportInitError(t *testing.T) {
 	mei := &eventsmocks.Plugin{}
 	mei.On("Name").Return("ut")
 	mei.On("InitConfig", mock.Anything).Return()
-	mei.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mei.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 
 	sm, cancel := newTestSubManager(t, mei)
 	defer cancel()
@@ -275,6 +278,99 @@ func TestCreateSubscriptionBadTransportOptions(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestCreateSubscriptionBatchNotSupported(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	yes := true
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch: &yes,
+			},
+		},
+	})
+	assert.Regexp(t, "FF10433.*ut", err)
+}
+
+func TestCreateSubscriptionBadBatchTimeout(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	mei.On("Capabilities").Return(&events.Capabilities{BatchDelivery: true})
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	yes := true
+	batchTimeout := "!duration"
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch:        &yes,
+				BatchTimeout: &batchTimeout,
+			},
+		},
+	})
+	assert.Regexp(t, "FF10434", err)
+}
+
+func TestCreateSubscriptionBatchOK(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	mei.On("Capabilities").Return(&events.Capabilities{BatchDelivery: true})
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	yes := true
+	batchTimeout := "100ms"
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch:        &yes,
+				BatchTimeout: &batchTimeout,
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, *sub.definition.Options.Batch)
+}
+
+func TestCreateSubscriptionBadPartitionBy(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	partitionBy := core.SubOptsPartitionBy("tag")
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				PartitionBy: &partitionBy,
+			},
+		},
+	})
+	assert.Regexp(t, "FF10445", err)
+}
+
+func TestCreateSubscriptionPartitionByOK(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	partitionBy := core.SubOptsPartitionByAuthor
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				PartitionBy: &partitionBy,
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, core.SubOptsPartitionByAuthor, *sub.definition.Options.PartitionBy)
+}
+
 func TestCreateSubscriptionBadEventilter(t *testing.T) {
 	mei := &eventsmocks.Plugin{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -455,6 +551,148 @@ func TestCreateSubscriptionBadBlockchainEventListenerFilter(t *testing.T) {
 	assert.Regexp(t, "FF10171.*listener", err)
 }
 
+func TestCreateSubscriptionBadTokenPoolFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Token: &core.TokenFilter{
+				Pool: "[[[[! badness",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10171.*token.pool", err)
+}
+
+func TestCreateSubscriptionBadTokenFromFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Token: &core.TokenFilter{
+				From: "[[[[! badness",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10171.*token.from", err)
+}
+
+func TestCreateSubscriptionBadTokenToFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Token: &core.TokenFilter{
+				To: "[[[[! badness",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10171.*token.to", err)
+}
+
+func TestCreateSubscriptionBadTokenMinAmount(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Token: &core.TokenFilter{
+				MinAmount: "1.5",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10435.*minAmount", err)
+}
+
+func TestCreateSubscriptionBadTokenMaxAmount(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Token: &core.TokenFilter{
+				MaxAmount: "lots",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10435.*maxAmount", err)
+}
+
+func TestCreateSubscriptionBadDataJSONPath(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Data: &core.DataFilter{
+				Value: "emea",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestCreateSubscriptionBadDataValueFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Data: &core.DataFilter{
+				JSONPath: "$.region",
+				Value:    "[[[[! badness",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10171.*data.value", err)
+}
+
+func TestCreateSubscriptionSuccessTokenAndDataFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Token: &core.TokenFilter{
+				Pool:      "pool1",
+				From:      "0x111",
+				To:        "0x222",
+				MinAmount: "10",
+				MaxAmount: "100000000000000000000000",
+			},
+			Data: &core.DataFilter{
+				JSONPath: "$.customer.region",
+				Value:    "^emea$",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), sub.tokenFilter.minAmount.Int64())
+	assert.Equal(t, "100000000000000000000000", sub.tokenFilter.maxAmount.String())
+	assert.True(t, sub.tokenFilter.poolFilter.MatchString("pool1"))
+	assert.Len(t, sub.dataFilter.jsonPath, 2)
+	assert.True(t, sub.dataFilter.valueFilter.MatchString("emea"))
+}
+
 func TestCreateSubscriptionSuccessMessageFilter(t *testing.T) {
 	mei := &eventsmocks.Plugin{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -835,3 +1073,232 @@ func TestDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.Empty(t, sm.durableSubs)
 	<-ed.closed
 }
+
+func TestDeleteDurableSubscriptionRemovesMetrics(t *testing.T) {
+	subID := fftypes.NewUUID()
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				ID:        subID,
+				Namespace: "ns1",
+				Name:      "sub1",
+			},
+			Transport: "websockets",
+		},
+	}
+
+	sm, cancel := newTestSubManager(t, &eventsmocks.Plugin{})
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionDeleted", "ns1", "sub1", "websockets").Return()
+	sm.metrics = mmi
+
+	sm.durableSubs[*subID] = sub
+	mdi.On("DeleteOffset", mock.Anything, fftypes.FFEnum("subscription"), subID.String()).Return(nil)
+	sm.deletedDurableSubscription(subID)
+
+	assert.Empty(t, sm.durableSubs)
+	mmi.AssertExpectations(t)
+}
+
+func TestResetDurableSubscriptionOffsetRestartsDispatchers(t *testing.T) {
+	subID := fftypes.NewUUID()
+	subDef := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        subID,
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Transport: "ut",
+	}
+	sub := &subscription{
+		definition:         subDef,
+		dispatcherElection: make(chan bool, 1),
+	}
+	testED1, _ := newTestEventDispatcher(sub)
+
+	mei := testED1.transport.(*eventsmocks.Plugin)
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	sm.durableSubs[*subID] = sub
+	ed, _ := newTestEventDispatcher(sub)
+	ed.database = mdi
+	// Hold the election, so the dispatchers wait rather than polling
+	sub.dispatcherElection <- true
+	ed.start()
+	sm.connections["conn1"] = &connection{
+		ei:        mei,
+		id:        "conn1",
+		transport: "ut",
+		matcher: func(sr core.SubscriptionRef) bool {
+			return sr.Namespace == "ns1" && sr.Name == "sub1"
+		},
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed,
+		},
+	}
+
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Type == core.OffsetTypeSubscription && offset.Name == subID.String() && offset.Current == 42
+	}), true).Return(nil)
+	err := sm.resetDurableSubscriptionOffset(sm.ctx, subID, 42)
+	assert.NoError(t, err)
+
+	<-ed.closed
+	assert.Equal(t, sub, sm.durableSubs[*subID])
+	newED := sm.connections["conn1"].dispatchers[*subID]
+	assert.NotNil(t, newED)
+	assert.NotEqual(t, ed, newED)
+	newED.close()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResetDurableSubscriptionOffsetNotLoadedFail(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+	err := sm.resetDurableSubscriptionOffset(sm.ctx, subID, -1)
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, sm.durableSubs)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRewindSubscription(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	be := &boundCallbacks{sm: sm, ei: mei}
+
+	subID := fftypes.NewUUID()
+	sm.durableSubs[*subID] = &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+			Transport:       "ut",
+		},
+	}
+
+	// Offset is already before the requested sequence
+	err := be.RewindSubscription("ns1", "sub1", 5)
+	assert.NoError(t, err)
+	mdi.AssertNotCalled(t, "UpsertOffset", mock.Anything, mock.Anything, mock.Anything)
+
+	// Offset has moved past the requested sequence
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Name == subID.String() && offset.Current == -1
+	}), true).Return(nil)
+	err = be.RewindSubscription("ns1", "sub1", -1)
+	assert.NoError(t, err)
+	assert.NotNil(t, sm.durableSubs[*subID])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRewindSubscriptionNotFound(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	err := sm.rewindDurableSubscription(mei, "ns1", "sub1", 5)
+	assert.Regexp(t, "FF10438", err)
+}
+
+func TestRewindSubscriptionOtherTransport(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	msse := &eventsmocks.Plugin{}
+	msse.On("Name").Return("sse")
+	be := &boundCallbacks{sm: sm, ei: msse}
+
+	subID := fftypes.NewUUID()
+	sm.durableSubs[*subID] = &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+			Transport:       "webhooks",
+		},
+	}
+
+	// A webhook subscription cannot be rewound by a client of the SSE stream
+	err := be.RewindSubscription("ns1", "sub1", -1)
+	assert.Regexp(t, "FF10438", err)
+	mdi.AssertNotCalled(t, "GetOffset", mock.Anything, mock.Anything, mock.Anything)
+	mdi.AssertNotCalled(t, "UpsertOffset", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRewindSubscriptionGetOffsetFail(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+
+	subID := fftypes.NewUUID()
+	sm.durableSubs[*subID] = &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+			Transport:       "ut",
+		},
+	}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, subID.String()).Return(nil, fmt.Errorf("pop"))
+
+	err := sm.rewindDurableSubscription(mei, "ns1", "sub1", 5)
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestDeadLetterOK(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	be := &boundCallbacks{sm: sm, ei: mei}
+
+	deadLetter := &core.DeadLetter{
+		Namespace: "ns1",
+		Subscription: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Event:    fftypes.NewUUID(),
+		Attempts: 3,
+	}
+	mdi.On("InsertDeadLetter", mock.Anything, deadLetter).Return(nil)
+
+	err := be.DeadLetter("conn1", deadLetter)
+	assert.NoError(t, err)
+	assert.NotNil(t, deadLetter.ID)
+	assert.Equal(t, "ut", deadLetter.Transport)
+	mdi.AssertExpectations(t)
+}
+
+func TestDeadLetterMismatchedTransport(t *testing.T) {
+	mei1 := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei1)
+	defer cancel()
+	mei2 := &eventsmocks.Plugin{}
+	mei2.On("Name").Return("ut2")
+	be2 := &boundCallbacks{sm: sm, ei: mei2}
+
+	sm.connections["conn1"] = &connection{
+		ei:          mei1,
+		id:          "conn1",
+		transport:   "ut",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{},
+	}
+
+	err := be2.DeadLetter("conn1", &core.DeadLetter{})
+	assert.Regexp(t, "FF10190", err)
+}
@@ -22,6 +22,9 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 )
@@ -46,7 +49,7 @@ type EventListener func(event *core.EventDelivery) error
 
 func (se *Events) Name() string { return SystemEventsTransport }
 
-func (se *Events) Init(ctx context.Context, config config.Section, callbacks events.Callbacks) (err error) {
+func (se *Events) Init(ctx context.Context, config config.Section, callbacks events.Callbacks, metrics metrics.Manager) (err error) {
 	*se = Events{
 		ctx:          ctx,
 		capabilities: &events.Capabilities{},
@@ -86,7 +89,7 @@ func (se *Events) AddListener(ns string, el EventListener) error {
 	return nil
 }
 
-func (se *Events) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+func (se *Events) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
 	se.mux.Lock()
 	defer se.mux.Unlock()
 	for ns, listeners := range se.listeners {
@@ -106,3 +109,7 @@ func (se *Events) DeliveryRequest(connID string, sub *core.Subscription, event *
 	})
 	return nil
 }
+
+func (se *Events) BatchDeliveryRequest(connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	return i18n.NewError(se.ctx, coremsgs.MsgBatchDeliveryNotSupported, se.Name())
+}
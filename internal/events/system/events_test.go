@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/stretchr/testify/assert"
@@ -42,7 +43,7 @@ func newTestEvents(t *testing.T) (se *Events, cancel func()) {
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	config := config.RootSection("ut.events")
 	se.InitConfig(config)
-	se.Init(ctx, config, cbs)
+	se.Init(ctx, config, cbs, &metricsmocks.Manager{})
 	assert.Equal(t, "system", se.Name())
 	assert.NotNil(t, se.Capabilities())
 	assert.Nil(t, se.ValidateOptions(&core.SubscriptionOptions{}))
@@ -65,7 +66,7 @@ func TestDeliveryRequestOk(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(se.connID, &core.Subscription{}, &core.EventDelivery{
+	err = se.DeliveryRequest(context.Background(), se.connID, &core.Subscription{}, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{
 				Namespace: "ns1",
@@ -74,7 +75,7 @@ func TestDeliveryRequestOk(t *testing.T) {
 	}, nil)
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(se.connID, &core.Subscription{}, &core.EventDelivery{
+	err = se.DeliveryRequest(context.Background(), se.connID, &core.Subscription{}, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{
 				Namespace: "ns2",
@@ -101,7 +102,7 @@ func TestDeliveryRequestFail(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(mock.Anything, &core.Subscription{}, &core.EventDelivery{
+	err = se.DeliveryRequest(context.Background(), mock.Anything, &core.Subscription{}, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{
 				Namespace: "ns1",
@@ -112,6 +113,14 @@ func TestDeliveryRequestFail(t *testing.T) {
 
 }
 
+func TestBatchDeliveryNotSupported(t *testing.T) {
+	se, cancel := newTestEvents(t)
+	defer cancel()
+
+	err := se.BatchDeliveryRequest(se.connID, &core.Subscription{}, []*core.CombinedEventDataDelivery{})
+	assert.Regexp(t, "FF10433.*system", err)
+}
+
 func TestAddListenerFail(t *testing.T) {
 
 	se, cancel := newTestEvents(t)
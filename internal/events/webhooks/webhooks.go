@@ -19,12 +19,18 @@ package webhooks
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -32,15 +38,40 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-common/pkg/retry"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 )
 
+const (
+	// signatureHeader carries the HMAC-SHA256 of the timestamp and request body, when a secret is configured on the subscription
+	signatureHeader = "X-Firefly-Signature"
+	// timestampHeader carries the time the request was signed, in seconds since the epoch, so receivers can reject replayed requests
+	timestampHeader = "X-Firefly-Timestamp"
+
+	defaultRetryInitialDelay = 250 * time.Millisecond
+	defaultRetryMaximumDelay = 30 * time.Second
+	// defaultRetryMaximumElapsed bounds the total time spent delivering an event, including retries, as the dispatcher
+	// waits for the outcome before delivering the next event on the subscription
+	defaultRetryMaximumElapsed = 1 * time.Minute
+)
+
+// defaultRetryStatusCodes are the HTTP status codes that are retried, if the subscription does not specify its own list
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
 type WebHooks struct {
 	ctx          context.Context
 	capabilities *events.Capabilities
 	callbacks    events.Callbacks
+	metrics      metrics.Manager
 	client       *resty.Client
 	connID       string
 }
@@ -52,6 +83,20 @@ type whRequest struct {
 	body      fftypes.JSONObject
 	forceJSON bool
 	replyTx   string
+	secret    string
+}
+
+// whBatchEvent is an entry in the JSON array sent for a batch of events, including the data when withData is set
+type whBatchEvent struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+type whRetry struct {
+	count       int
+	retry       *retry.Retry
+	maxElapsed  time.Duration
+	statusCodes map[int]bool
 }
 
 type whResponse struct {
@@ -62,11 +107,12 @@ type whResponse struct {
 
 func (wh *WebHooks) Name() string { return "webhooks" }
 
-func (wh *WebHooks) Init(ctx context.Context, config config.Section, callbacks events.Callbacks) (err error) {
+func (wh *WebHooks) Init(ctx context.Context, config config.Section, callbacks events.Callbacks, metrics metrics.Manager) (err error) {
 	*wh = WebHooks{
 		ctx:          ctx,
-		capabilities: &events.Capabilities{},
+		capabilities: &events.Capabilities{BatchDelivery: true},
 		callbacks:    callbacks,
+		metrics:      metrics,
 		client:       ffresty.New(ctx, config),
 		connID:       fftypes.ShortID(),
 	}
@@ -78,13 +124,14 @@ func (wh *WebHooks) Capabilities() *events.Capabilities {
 	return wh.capabilities
 }
 
-func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.JSONObject) (req *whRequest, err error) {
+func (wh *WebHooks) buildRequest(ctx context.Context, options fftypes.JSONObject, firstData fftypes.JSONObject) (req *whRequest, err error) {
 	req = &whRequest{
-		r:         wh.client.R().SetDoNotParseResponse(true),
+		r:         wh.client.R().SetContext(ctx).SetDoNotParseResponse(true),
 		url:       options.GetString("url"),
 		method:    options.GetString("method"),
 		forceJSON: options.GetBool("json"),
 		replyTx:   options.GetString("replytx"),
+		secret:    options.GetString("secret"),
 	}
 	if req.url == "" {
 		return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookURLEmpty)
@@ -162,16 +209,91 @@ func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.J
 	return req, err
 }
 
+func (req *whRequest) hasBody() bool {
+	return req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut
+}
+
+func (wh *WebHooks) buildRetry(options fftypes.JSONObject) (*whRetry, error) {
+	var retryOptions core.WebhookRetryOptions
+	if retryJSON, ok := options["retry"]; ok && retryJSON != nil {
+		b, _ := json.Marshal(retryJSON)
+		if err := json.Unmarshal(b, &retryOptions); err != nil {
+			return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookInvalidRetryOption, "retry", err)
+		}
+	}
+	if retryOptions.Count < 0 {
+		return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookInvalidRetryOption, "retry.count", retryOptions.Count)
+	}
+	r := &whRetry{
+		count: retryOptions.Count,
+		retry: &retry.Retry{
+			InitialDelay: defaultRetryInitialDelay,
+			MaximumDelay: defaultRetryMaximumDelay,
+		},
+		maxElapsed:  defaultRetryMaximumElapsed,
+		statusCodes: make(map[int]bool),
+	}
+	if retryOptions.InitialDelay != "" {
+		d, err := fftypes.ParseDurationString(retryOptions.InitialDelay, time.Millisecond)
+		if err != nil {
+			return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookInvalidRetryOption, "retry.initialDelay", err)
+		}
+		r.retry.InitialDelay = time.Duration(d)
+	}
+	if retryOptions.MaximumDelay != "" {
+		d, err := fftypes.ParseDurationString(retryOptions.MaximumDelay, time.Millisecond)
+		if err != nil {
+			return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookInvalidRetryOption, "retry.maxDelay", err)
+		}
+		r.retry.MaximumDelay = time.Duration(d)
+	}
+	if retryOptions.MaximumElapsed != "" {
+		d, err := fftypes.ParseDurationString(retryOptions.MaximumElapsed, time.Millisecond)
+		if err != nil || d <= 0 {
+			return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookInvalidRetryOption, "retry.maxElapsed", retryOptions.MaximumElapsed)
+		}
+		r.maxElapsed = time.Duration(d)
+	}
+	statusCodes := retryOptions.StatusCodes
+	if statusCodes == nil {
+		statusCodes = defaultRetryStatusCodes
+	}
+	for _, sc := range statusCodes {
+		if sc < 100 || sc > 599 {
+			return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookInvalidRetryOption, "retry.statusCodes", sc)
+		}
+		r.statusCodes[sc] = true
+	}
+	return r, nil
+}
+
 func (wh *WebHooks) ValidateOptions(options *core.SubscriptionOptions) error {
 	if options.WithData == nil {
 		defaultTrue := true
 		options.WithData = &defaultTrue
 	}
-	_, err := wh.buildRequest(options.TransportOptions(), fftypes.JSONObject{})
+	if options.Batch != nil && *options.Batch && options.TransportOptions().GetBool("reply") {
+		return i18n.NewError(wh.ctx, coremsgs.MsgWebhookBatchWithReply)
+	}
+	if options.TransportOptions().GetString("secret") == core.WebhookSecretRedacted {
+		return i18n.NewError(wh.ctx, coremsgs.MsgWebhookSecretRedacted)
+	}
+	if _, err := wh.buildRetry(options.TransportOptions()); err != nil {
+		return err
+	}
+	_, err := wh.buildRequest(wh.ctx, options.TransportOptions(), fftypes.JSONObject{})
 	return err
 }
 
-func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDelivery, data core.DataArray) (req *whRequest, res *whResponse, err error) {
+// signBody computes the signature over "<timestamp>.<body>", so a captured request cannot be replayed with a new timestamp
+func signBody(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (wh *WebHooks) attemptRequest(ctx context.Context, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) (req *whRequest, res *whResponse, err error) {
 	withData := sub.Options.WithData != nil && *sub.Options.WithData
 	allData := make([]*fftypes.JSONAny, 0, len(data))
 	var firstData fftypes.JSONObject
@@ -196,32 +318,82 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 		}
 	}
 
-	req, err = wh.buildRequest(sub.Options.TransportOptions(), firstData)
+	req, err = wh.buildRequest(ctx, sub.Options.TransportOptions(), firstData)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut {
+	var payload interface{}
+	if req.hasBody() {
 		switch {
 		case !withData:
 			// We are just sending the event itself
-			req.r.SetBody(event)
+			payload = event
 		case req.body != nil:
 			// We might have been told to extract a body from the first data record
-			req.r.SetBody(req.body)
+			payload = req.body
 		case len(allData) > 1:
 			// We've got an array of data to POST
-			req.r.SetBody(allData)
+			payload = allData
 		default:
 			// Otherwise just send the first object directly
-			req.r.SetBody(firstData)
+			payload = firstData
 		}
 	}
 
-	resp, err := req.r.Execute(req.method, req.url)
+	res, err = wh.sendRequest(req, payload)
 	if err != nil {
 		return nil, nil, err
 	}
+	return req, res, nil
+}
+
+func (wh *WebHooks) attemptBatchRequest(ctx context.Context, sub *core.Subscription, events []*core.CombinedEventDataDelivery) (req *whRequest, res *whResponse, err error) {
+	// The input options only apply to the data of a single event, so are not used for batches
+	req, err = wh.buildRequest(ctx, sub.Options.TransportOptions(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var payload interface{}
+	if req.hasBody() {
+		withData := sub.Options.WithData != nil && *sub.Options.WithData
+		batch := make([]*whBatchEvent, len(events))
+		for i, e := range events {
+			batch[i] = &whBatchEvent{EventDelivery: e.Event}
+			if withData {
+				batch[i].Data = e.Data
+			}
+		}
+		payload = batch
+	}
+
+	res, err = wh.sendRequest(req, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, res, nil
+}
+
+func (wh *WebHooks) sendRequest(req *whRequest, payload interface{}) (res *whResponse, err error) {
+	var body []byte
+	if payload != nil {
+		// We serialize the body ourselves, so the signature covers exactly the bytes we send
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+		req.r.SetBody(body)
+	}
+	if req.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		_ = req.r.SetHeader(timestampHeader, timestamp)
+		_ = req.r.SetHeader(signatureHeader, signBody(req.secret, timestamp, body))
+	}
+
+	resp, err := req.r.Execute(req.method, req.url)
+	if err != nil {
+		return nil, err
+	}
 	defer func() { _ = resp.RawBody().Close() }()
 
 	res = &whResponse{
@@ -242,7 +414,7 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 		var resData interface{}
 		err = json.NewDecoder(resp.RawBody()).Decode(&resData)
 		if err != nil {
-			return nil, nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhooksReplyBadJSON)
+			return nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhooksReplyBadJSON)
 		}
 		b, _ := json.Marshal(&resData) // we know we can re-marshal It
 		res.Body = fftypes.JSONAnyPtrBytes(b)
@@ -257,17 +429,54 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 		res.Body = fftypes.JSONAnyPtrBytes(buf.Bytes())
 	}
 
-	return req, res, nil
+	return res, nil
 }
 
-func (wh *WebHooks) doDelivery(connID string, reply bool, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
-	req, res, gwErr := wh.attemptRequest(sub, event, data)
-	if gwErr != nil {
+func (wh *WebHooks) attemptWithRetry(sub *core.Subscription, desc string, attemptRequest func(ctx context.Context) (*whRequest, *whResponse, error)) (req *whRequest, res *whResponse, attempts int, err error) {
+	r, err := wh.buildRetry(sub.Options.TransportOptions())
+	if err != nil {
+		return nil, nil, 1, err
+	}
+	// The deadline applies to the requests as well as the delays between them, so the time the dispatcher
+	// waits for this delivery is bounded regardless of the retry count
+	ctx, cancel := context.WithTimeout(wh.ctx, r.maxElapsed)
+	defer cancel()
+	err = r.retry.DoCustomLog(ctx, func(attempt int) (bool, error) {
+		if attempt > 1 && ctx.Err() != nil {
+			// Out of time, so we finish with the error from the previous attempt
+			log.L(wh.ctx).Errorf("Webhook retries for %s stopped after %s", desc, r.maxElapsed)
+			return false, err
+		}
+		attempts = attempt
+		req, res, err = attemptRequest(ctx)
+		if err == nil && r.statusCodes[res.Status] {
+			err = i18n.NewError(wh.ctx, coremsgs.MsgWebhookFailedStatus, res.Status)
+		}
+		if err != nil {
+			log.L(wh.ctx).Errorf("Webhook attempt %d/%d for %s failed: %s", attempt, r.count+1, desc, err)
+			wh.reportDeliveryFailed(sub)
+		}
+		return attempt <= r.count && ctx.Err() == nil, err
+	})
+	if err == nil && (res.Status < 200 || res.Status >= 300) {
+		err = i18n.NewError(wh.ctx, coremsgs.MsgWebhookFailedStatus, res.Status)
+	}
+	return req, res, attempts, err
+}
+
+func (wh *WebHooks) reportDeliveryFailed(sub *core.Subscription) {
+	if wh.metrics.IsMetricsEnabled() {
+		wh.metrics.SubscriptionDeliveryFailed(sub.Namespace, metrics.SubscriptionLabel(sub), wh.Name())
+	}
+}
+
+func (wh *WebHooks) responseJSON(res *whResponse, deliveryErr error) []byte {
+	if res == nil {
 		// Generate a bad-gateway error response - we always want to send something back,
 		// rather than just causing timeouts
-		log.L(wh.ctx).Errorf("Failed to invoke webhook: %s", gwErr)
+		log.L(wh.ctx).Errorf("Failed to invoke webhook: %s", deliveryErr)
 		b, _ := json.Marshal(&fftypes.RESTError{
-			Error: gwErr.Error(),
+			Error: deliveryErr.Error(),
 		})
 		res = &whResponse{
 			Status: http.StatusBadGateway,
@@ -279,6 +488,42 @@ func (wh *WebHooks) doDelivery(connID string, reply bool, sub *core.Subscription
 	}
 	b, _ := json.Marshal(&res)
 	log.L(wh.ctx).Tracef("Webhook response: %s", string(b))
+	return b
+}
+
+func (wh *WebHooks) doDelivery(connID string, reply, ack bool, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) {
+	req, res, attempts, deliveryErr := wh.attemptWithRetry(sub, fmt.Sprintf("event '%s'", event.ID), func(ctx context.Context) (*whRequest, *whResponse, error) {
+		return wh.attemptRequest(ctx, sub, event, data)
+	})
+	if deliveryErr != nil && wh.ctx.Err() != nil {
+		log.L(wh.ctx).Debugf("Webhook delivery for event '%s' abandoned: closing", event.ID)
+		return
+	}
+	b := wh.responseJSON(res, deliveryErr)
+
+	response := &core.EventDeliveryResponse{
+		ID:           event.ID,
+		Rejected:     false,
+		Subscription: event.Subscription,
+	}
+
+	// Once we have exhausted our retries, we record the event as a dead letter and move on
+	if deliveryErr != nil {
+		err := wh.callbacks.DeadLetter(connID, &core.DeadLetter{
+			Namespace:    event.Namespace,
+			Subscription: event.Subscription,
+			Event:        event.ID,
+			Attempts:     attempts,
+			Error:        deliveryErr.Error(),
+			LastResponse: fftypes.JSONAnyPtrBytes(b),
+		})
+		if err != nil {
+			log.L(wh.ctx).Errorf("Failed to record dead letter for event '%s': %s", event.ID, err)
+			response.Rejected = true
+			response.Info = err.Error()
+			reply = false
+		}
+	}
 
 	// Emit the response
 	if reply {
@@ -286,31 +531,28 @@ func (wh *WebHooks) doDelivery(connID string, reply bool, sub *core.Subscription
 		if req != nil && req.replyTx != "" {
 			txType = fftypes.FFEnum(strings.ToLower(req.replyTx))
 		}
-		wh.callbacks.DeliveryResponse(connID, &core.EventDeliveryResponse{
-			ID:           event.ID,
-			Rejected:     false,
-			Subscription: event.Subscription,
-			Reply: &core.MessageInOut{
-				Message: core.Message{
-					Header: core.MessageHeader{
-						CID:    event.Message.Header.ID,
-						Group:  event.Message.Header.Group,
-						Type:   event.Message.Header.Type,
-						Topics: event.Message.Header.Topics,
-						Tag:    sub.Options.TransportOptions().GetString("replytag"),
-						TxType: txType,
-					},
-				},
-				InlineData: core.InlineData{
-					{Value: fftypes.JSONAnyPtrBytes(b)},
+		response.Reply = &core.MessageInOut{
+			Message: core.Message{
+				Header: core.MessageHeader{
+					CID:    event.Message.Header.ID,
+					Group:  event.Message.Header.Group,
+					Type:   event.Message.Header.Type,
+					Topics: event.Message.Header.Topics,
+					Tag:    sub.Options.TransportOptions().GetString("replytag"),
+					TxType: txType,
 				},
 			},
-		})
+			InlineData: core.InlineData{
+				{Value: fftypes.JSONAnyPtrBytes(b)},
+			},
+		}
+	}
+	if ack {
+		wh.callbacks.DeliveryResponse(connID, response)
 	}
-	return nil
 }
 
-func (wh *WebHooks) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+func (wh *WebHooks) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
 	if event.Message == nil && sub.Options.WithData != nil && *sub.Options.WithData {
 		log.L(wh.ctx).Debugf("Webhook withData=true subscription called with non-message event '%s'", event.ID)
 		return nil
@@ -330,14 +572,88 @@ func (wh *WebHooks) DeliveryRequest(connID string, sub *core.Subscription, event
 		return nil
 	}
 
-	// In fastack mode we drive calls in parallel to the backend, immediately acknowledging the event
+	// In fastack mode we drive calls in parallel to the backend, immediately acknowledging the event.
+	// When a reply is required, the acknowledgement is sent with the reply once the call completes.
 	if sub.Options.TransportOptions().GetBool("fastack") {
-		go func() {
-			err := wh.doDelivery(connID, reply, sub, event, data)
-			log.L(wh.ctx).Warnf("Webhook delivery failed in fastack mode for event '%s': %s", event.ID, err)
-		}()
+		if !reply {
+			wh.callbacks.DeliveryResponse(connID, &core.EventDeliveryResponse{
+				ID:           event.ID,
+				Rejected:     false,
+				Subscription: event.Subscription,
+			})
+		}
+		go wh.doDelivery(connID, reply, reply, sub, event, data)
 		return nil
 	}
 
-	return wh.doDelivery(connID, reply, sub, event, data)
+	wh.doDelivery(connID, reply, true, sub, event, data)
+	return nil
+}
+
+func (wh *WebHooks) doBatchDelivery(connID string, ack bool, sub *core.Subscription, events []*core.CombinedEventDataDelivery) {
+	_, res, attempts, deliveryErr := wh.attemptWithRetry(sub, fmt.Sprintf("batch of %d events", len(events)), func(ctx context.Context) (*whRequest, *whResponse, error) {
+		return wh.attemptBatchRequest(ctx, sub, events)
+	})
+	if deliveryErr != nil && wh.ctx.Err() != nil {
+		log.L(wh.ctx).Debugf("Webhook delivery for batch of %d events abandoned: closing", len(events))
+		return
+	}
+
+	// The batch is acknowledged as a unit, so if we fail to record any dead letter we reject the whole batch
+	var rejectErr error
+	if deliveryErr != nil {
+		b := wh.responseJSON(res, deliveryErr)
+		for _, e := range events {
+			err := wh.callbacks.DeadLetter(connID, &core.DeadLetter{
+				Namespace:    e.Event.Namespace,
+				Subscription: e.Event.Subscription,
+				Event:        e.Event.ID,
+				Attempts:     attempts,
+				Error:        deliveryErr.Error(),
+				LastResponse: fftypes.JSONAnyPtrBytes(b),
+			})
+			if err != nil {
+				log.L(wh.ctx).Errorf("Failed to record dead letter for event '%s': %s", e.Event.ID, err)
+				rejectErr = err
+				break
+			}
+		}
+	}
+
+	if ack {
+		for _, e := range events {
+			response := &core.EventDeliveryResponse{
+				ID:           e.Event.ID,
+				Rejected:     false,
+				Subscription: e.Event.Subscription,
+			}
+			if rejectErr != nil {
+				response.Rejected = true
+				response.Info = rejectErr.Error()
+			}
+			wh.callbacks.DeliveryResponse(connID, response)
+		}
+	}
+}
+
+func (wh *WebHooks) BatchDeliveryRequest(connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	if sub.Options.TransportOptions().GetBool("reply") {
+		return i18n.NewError(wh.ctx, coremsgs.MsgWebhookBatchWithReply)
+	}
+
+	// In fastack mode we acknowledge the whole batch immediately, and drive the call in parallel to the backend
+	if sub.Options.TransportOptions().GetBool("fastack") {
+		for _, e := range events {
+			wh.callbacks.DeliveryResponse(connID, &core.EventDeliveryResponse{
+				ID:           e.Event.ID,
+				Rejected:     false,
+				Subscription: e.Event.Subscription,
+			})
+		}
+		go wh.doBatchDelivery(connID, false, sub, events)
+		return nil
+	}
+
+	wh.doBatchDelivery(connID, true, sub, events)
+	return nil
 }
@@ -18,17 +18,25 @@ package webhooks
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/stretchr/testify/assert"
@@ -43,11 +51,13 @@ func newTestWebHooks(t *testing.T) (wh *WebHooks, cancel func()) {
 	rc.RunFn = func(a mock.Arguments) {
 		assert.Equal(t, true, a[1].(events.SubscriptionMatcher)(core.SubscriptionRef{}))
 	}
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false)
 	wh = &WebHooks{}
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	svrConfig := config.RootSection("ut.webhooks")
 	wh.InitConfig(svrConfig)
-	wh.Init(ctx, svrConfig, cbs)
+	wh.Init(ctx, svrConfig, cbs, mmi)
 	assert.Equal(t, "webhooks", wh.Name())
 	assert.NotNil(t, wh.Capabilities())
 	return wh, cancelCtx
@@ -118,6 +128,78 @@ func TestValidateOptionsBadQuery(t *testing.T) {
 	assert.Regexp(t, "FF10243.*query", err)
 }
 
+func TestValidateOptionsBadRetry(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	for field, retry := range map[string]interface{}{
+		"retry":              "not an object",
+		"retry.count":        map[string]interface{}{"count": -1},
+		"retry.initialDelay": map[string]interface{}{"initialDelay": "soon"},
+		"retry.maxDelay":     map[string]interface{}{"maxDelay": "later"},
+		"retry.maxElapsed":   map[string]interface{}{"maxElapsed": "0"},
+		"retry.statusCodes":  map[string]interface{}{"statusCodes": []interface{}{200, 1000}},
+	} {
+		opts := &core.SubscriptionOptions{}
+		opts.TransportOptions()["url"] = "/anything"
+		opts.TransportOptions()["retry"] = retry
+		err := wh.ValidateOptions(opts)
+		assert.Regexp(t, "FF10430.*"+field, err)
+	}
+}
+
+func TestValidateOptionsRedactedSecret(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["secret"] = core.WebhookSecretRedacted
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestValidateOptionsBatchWithReply(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	yes := true
+	opts := &core.SubscriptionOptions{
+		SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+			Batch: &yes,
+		},
+	}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["reply"] = true
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10432", err)
+}
+
+func TestValidateOptionsRetryOK(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["retry"] = map[string]interface{}{
+		"count":        float64(5),
+		"initialDelay": "1s",
+		"maxDelay":     "1m",
+		"maxElapsed":   "5m",
+		"statusCodes":  []interface{}{float64(409)},
+	}
+	err := wh.ValidateOptions(opts)
+	assert.NoError(t, err)
+
+	r, err := wh.buildRetry(opts.TransportOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, r.count)
+	assert.Equal(t, time.Second, r.retry.InitialDelay)
+	assert.Equal(t, time.Minute, r.retry.MaximumDelay)
+	assert.Equal(t, 5*time.Minute, r.maxElapsed)
+	assert.Equal(t, map[int]bool{409: true}, r.statusCodes)
+}
+
 func TestRequestWithBodyReplyEndToEnd(t *testing.T) {
 	wh, cancel := newTestWebHooks(t)
 	defer cancel()
@@ -219,7 +301,7 @@ func TestRequestWithBodyReplyEndToEnd(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{data})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{data})
 	assert.NoError(t, err)
 
 	mcb.AssertExpectations(t)
@@ -326,7 +408,7 @@ func TestRequestWithEmptyStringBodyReplyEndToEnd(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{data})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{data})
 	assert.NoError(t, err)
 
 	mcb.AssertExpectations(t)
@@ -383,9 +465,16 @@ func TestRequestNoBodyNoReply(t *testing.T) {
 		}`),
 	}
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{data})
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected && response.Reply == nil
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{data})
 	assert.NoError(t, err)
 	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
 }
 
 func TestRequestReplyEmptyData(t *testing.T) {
@@ -442,7 +531,7 @@ func TestRequestReplyEmptyData(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{})
 	assert.NoError(t, err)
 	assert.True(t, called)
 }
@@ -489,8 +578,11 @@ func TestRequestReplyBadJSON(t *testing.T) {
 		assert.Regexp(t, "FF10257", response.Reply.InlineData[0].Value.JSONObject().GetObject("body")["error"])
 		return true
 	})).Return(nil)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Attempts == 1 && dl.Event.Equals(event.ID)
+	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{})
 	assert.NoError(t, err)
 }
 func TestRequestReplyDataArrayBadStatusB64(t *testing.T) {
@@ -552,8 +644,14 @@ func TestRequestReplyDataArrayBadStatusB64(t *testing.T) {
 		assert.Equal(t, `c29tZSBieXRlcw==`, response.Reply.InlineData[0].Value.JSONObject()["body"]) // base64 val
 		return true
 	})).Return(nil)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		assert.Equal(t, 1, dl.Attempts)
+		assert.Regexp(t, "FF10431.*500", dl.Error)
+		assert.Equal(t, float64(500), dl.LastResponse.JSONObject()["status"])
+		return true
+	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value2"`)},
 	})
@@ -602,8 +700,11 @@ func TestRequestReplyDataArrayError(t *testing.T) {
 		assert.NotEmpty(t, response.Reply.InlineData[0].Value.JSONObject().GetObject("body")["error"])
 		return true
 	})).Return(nil)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Attempts == 1 && dl.Error != ""
+	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value2"`)},
 	})
@@ -654,8 +755,11 @@ func TestRequestReplyBuildRequestFailFastAsk(t *testing.T) {
 	dr.RunFn = func(a mock.Arguments) {
 		close(waiter)
 	}
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Attempts == 1
+	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, core.DataArray{
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value2"`)},
 	})
@@ -689,7 +793,7 @@ func TestDeliveryRequestNilMessage(t *testing.T) {
 		},
 	}
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
 	assert.NoError(t, err)
 }
 
@@ -729,6 +833,472 @@ func TestDeliveryRequestReplyToReply(t *testing.T) {
 		return !response.Rejected // should be accepted as a no-op so we can move on to other events
 	}))
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+}
+
+func newTestRetrySub(url string, retry map[string]interface{}) *core.Subscription {
+	no := false
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				WithData: &no,
+			},
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = url
+	if retry != nil {
+		to["retry"] = retry
+	}
+	return sub
+}
+
+func newTestRetryEvent(sub *core.Subscription) *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Namespace: sub.Namespace,
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					ID:   fftypes.NewUUID(),
+					Type: core.MessageTypeBroadcast,
+				},
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+}
+
+func TestRequestRetrySuccess(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, map[string]interface{}{
+		"count":        float64(2),
+		"initialDelay": "1ms",
+	})
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.ID.Equals(event.ID) && !response.Rejected
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestRetryExhaustedDeadLetter(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusServiceUnavailable)
+		res.Write([]byte(`{"error":"down"}`))
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, map[string]interface{}{
+		"count":        float64(2),
+		"initialDelay": "1ms",
+		"maxDelay":     "2ms",
+	})
+	event := newTestRetryEvent(sub)
+
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionDeliveryFailed", "ns1", sub.Name, "webhooks").Return().Times(3)
+	wh.metrics = mmi
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		assert.Equal(t, "ns1", dl.Namespace)
+		assert.Equal(t, sub.SubscriptionRef, dl.Subscription)
+		assert.Equal(t, event.ID, dl.Event)
+		assert.Equal(t, 3, dl.Attempts)
+		assert.Regexp(t, "FF10431.*503", dl.Error)
+		assert.Equal(t, float64(503), dl.LastResponse.JSONObject()["status"])
+		assert.Equal(t, "down", dl.LastResponse.JSONObject().GetObject("body").GetString("error"))
+		return true
+	})).Return(nil)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.ID.Equals(event.ID) && !response.Rejected
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	mcb.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestRequestRetryMaxElapsedDeadLetter(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		res.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, map[string]interface{}{
+		"count":        float64(1000),
+		"initialDelay": "10ms",
+		"maxDelay":     "10ms",
+		"maxElapsed":   "50ms",
+	})
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		assert.Less(t, dl.Attempts, 1000)
+		// The final attempt might be cut short by the deadline, rather than fail with a status
+		assert.Regexp(t, "FF10431.*503|deadline exceeded", dl.Error)
+		return true
+	})).Return(nil)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.ID.Equals(event.ID) && !response.Rejected
+	})).Return(nil)
+
+	start := time.Now()
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Less(t, atomic.LoadInt32(&calls), int32(1000))
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestNonRetryableStatusDeadLetter(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		res.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, map[string]interface{}{
+		"count":        float64(5),
+		"initialDelay": "1ms",
+	})
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Attempts == 1
+	})).Return(nil)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestDeadLetterFailNack(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, nil)
+	sub.Options.TransportOptions()["reply"] = true
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeadLetter", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.Rejected && response.Info == "pop" && response.Reply == nil
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestBadRetryOptionsDeadLetter(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := newTestRetrySub("http://localhost:12345", map[string]interface{}{
+		"count": float64(-1),
+	})
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		assert.Regexp(t, "FF10430", dl.Error)
+		return true
+	})).Return(nil)
+	mcb.On("DeliveryResponse", mock.Anything, mock.Anything).Return(nil)
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestRetryClosing(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		cancel()
+		res.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, map[string]interface{}{
+		"count":        float64(5),
+		"initialDelay": "1ms",
+	})
+	event := newTestRetryEvent(sub)
+
+	// No responses are expected, as we are shutting down
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.AssertNotCalled(t, "DeliveryResponse", mock.Anything, mock.Anything)
+	mcb.AssertNotCalled(t, "DeadLetter", mock.Anything, mock.Anything)
+}
+
+func TestRequestSignedWithSecret(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		timestamp := req.Header.Get("X-Firefly-Timestamp")
+		sent, err := strconv.ParseInt(timestamp, 10, 64)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), time.Unix(sent, 0), time.Minute)
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Firefly-Signature"))
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, nil)
+	sub.Options.TransportOptions()["secret"] = "s3cret"
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestFastAckNoReply(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	called := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		close(called)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, nil)
+	sub.Options.TransportOptions()["fastack"] = true
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.ID.Equals(event.ID) && !response.Rejected
+	})).Return(nil).Once()
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	mcb.AssertExpectations(t)
+	<-called
+}
+
+func newTestBatch(sub *core.Subscription, count int) []*core.CombinedEventDataDelivery {
+	batch := make([]*core.CombinedEventDataDelivery, count)
+	for i := range batch {
+		batch[i] = &core.CombinedEventDataDelivery{
+			Event: newTestRetryEvent(sub),
+			Data: core.DataArray{
+				{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(fmt.Sprintf(`{"value":%d}`, i))},
+			},
+		}
+	}
+	return batch
+}
+
+func TestBatchDeliveryRequestOK(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+	assert.True(t, wh.Capabilities().BatchDelivery)
+
+	sub := newTestRetrySub("", nil)
+	batch := newTestBatch(sub, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var body []fftypes.JSONObject
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Len(t, body, 2)
+		for i, e := range body {
+			assert.Equal(t, batch[i].Event.ID.String(), e.GetString("id"))
+			assert.Equal(t, "sub1", e.GetObject("subscription").GetString("name"))
+			assert.Equal(t, float64(i), e.GetObjectArray("data")[0].GetObject("value")["value"])
+		}
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	yes := true
+	sub.Options.WithData = &yes
+	sub.Options.TransportOptions()["url"] = server.URL
+
+	acked := 0
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Run(func(a mock.Arguments) {
+		acked++
+	}).Return(nil)
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batch)
 	assert.NoError(t, err)
+	assert.Equal(t, 2, acked)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestWithReply(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := newTestRetrySub("/anything", nil)
+	sub.Options.TransportOptions()["reply"] = true
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, newTestBatch(sub, 1))
+	assert.Regexp(t, "FF10432", err)
+}
+
+func TestBatchDeliveryRequestFailDeadLetters(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, nil)
+	batch := newTestBatch(sub, 2)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Event.Equals(batch[0].Event.ID) && dl.Attempts == 1
+	})).Return(nil).Once()
+	mcb.On("DeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Event.Equals(batch[1].Event.ID) && dl.Attempts == 1
+	})).Return(nil).Once()
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil).Twice()
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batch)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestDeadLetterFailNack(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, nil)
+	batch := newTestBatch(sub, 2)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeadLetter", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.Rejected && response.Info == "pop"
+	})).Return(nil).Twice()
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batch)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestFastAck(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	called := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		close(called)
+	}))
+	defer server.Close()
+
+	sub := newTestRetrySub(server.URL, nil)
+	sub.Options.TransportOptions()["fastack"] = true
+	batch := newTestBatch(sub, 3)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil).Times(3)
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batch)
+	assert.NoError(t, err)
+	mcb.AssertExpectations(t)
+	<-called
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/auth"
 	"github.com/hyperledger/firefly/pkg/core"
 )
 
@@ -37,6 +38,11 @@ type websocketStartedSub struct {
 	namespace string
 }
 
+type websocketInflight struct {
+	*core.EventDeliveryResponse
+	sequence int64
+}
+
 type websocketConnection struct {
 	ctx          context.Context
 	ws           *WebSockets
@@ -48,11 +54,13 @@ type websocketConnection struct {
 	receiverDone chan struct{}
 	autoAck      bool
 	started      []*websocketStartedSub
-	inflight     []*core.EventDeliveryResponse
+	inflight     []*websocketInflight
+	resumed      chan struct{} // non-nil while delivery is paused
 	mux          sync.Mutex
 	closed       bool
 	remoteAddr   string
 	userAgent    string
+	principal    *auth.Principal
 }
 
 func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn, req *http.Request) *websocketConnection {
@@ -70,6 +78,7 @@ func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn,
 		receiverDone: make(chan struct{}),
 		remoteAddr:   req.RemoteAddr,
 		userAgent:    req.UserAgent(),
+		principal:    auth.GetPrincipal(req.Context()),
 	}
 	go wc.sendLoop()
 	go wc.receiveLoop()
@@ -161,6 +170,22 @@ func (wc *websocketConnection) receiveLoop() {
 			if err == nil {
 				err = wc.handleAck(&msg)
 			}
+		case core.WSClientActionNack:
+			var msg core.WSNack
+			err = json.Unmarshal(msgData, &msg)
+			if err == nil {
+				err = wc.handleNack(&msg)
+			}
+		case core.WSClientActionAckUpTo:
+			var msg core.WSAckUpTo
+			err = json.Unmarshal(msgData, &msg)
+			if err == nil {
+				err = wc.handleAckUpTo(&msg)
+			}
+		case core.WSClientActionPause:
+			wc.handlePause()
+		case core.WSClientActionResume:
+			wc.handleResume()
 		default:
 			err = i18n.NewError(wc.ctx, coremsgs.MsgWSClientUnknownAction, msgHeader.Type)
 		}
@@ -172,10 +197,17 @@ func (wc *websocketConnection) receiveLoop() {
 	}
 }
 
-func (wc *websocketConnection) dispatch(event *core.EventDelivery) error {
-	inflight := &core.EventDeliveryResponse{
-		ID:           event.ID,
-		Subscription: event.Subscription,
+func (wc *websocketConnection) dispatch(ctx context.Context, event *core.EventDelivery) error {
+	if err := wc.waitResumed(ctx); err != nil {
+		return err
+	}
+
+	inflight := &websocketInflight{
+		EventDeliveryResponse: &core.EventDeliveryResponse{
+			ID:           event.ID,
+			Subscription: event.Subscription,
+		},
+		sequence: event.Sequence,
 	}
 
 	var autoAck bool
@@ -192,7 +224,7 @@ func (wc *websocketConnection) dispatch(event *core.EventDelivery) error {
 	}
 
 	if autoAck {
-		wc.ws.ack(wc.connID, inflight)
+		wc.ws.ack(wc.connID, inflight.EventDeliveryResponse)
 	}
 
 	return nil
@@ -222,6 +254,10 @@ func (wc *websocketConnection) send(msg interface{}) error {
 }
 
 func (wc *websocketConnection) handleStart(start *core.WSStart) (err error) {
+	// When the connection was authenticated, the principal must have access to the namespace of every subscription
+	if wc.principal != nil && !wc.principal.Authorized(start.Namespace, true) {
+		return i18n.NewError(wc.ctx, coremsgs.MsgAuthForbidden, wc.principal.Subject, start.Namespace)
+	}
 	wc.mux.Lock()
 	if start.AutoAck != nil {
 		if *start.AutoAck != wc.autoAck && len(wc.started) > 0 {
@@ -250,9 +286,33 @@ func (wc *websocketConnection) durableSubMatcher(sr core.SubscriptionRef) bool {
 	return false
 }
 
-func (wc *websocketConnection) checkAck(ack *core.WSAck) (*core.EventDeliveryResponse, error) {
+// waitResumed blocks delivery while the client has paused the connection.
+// If the dispatcher closes while we wait, the event is dropped without being added to the in-flight list.
+func (wc *websocketConnection) waitResumed(ctx context.Context) error {
+	wc.mux.Lock()
+	resumed := wc.resumed
+	wc.mux.Unlock()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return i18n.NewError(wc.ctx, coremsgs.MsgDispatcherClosing)
+	case <-wc.ctx.Done():
+		return i18n.NewError(wc.ctx, i18n.MsgWSClosing)
+	}
+}
+
+func subscriptionMatches(requested *core.SubscriptionRef, candidate *core.SubscriptionRef) bool {
+	return (requested.ID != nil && requested.ID.Equals(candidate.ID)) ||
+		(requested.Name == candidate.Name && requested.Namespace == candidate.Namespace)
+}
+
+func (wc *websocketConnection) checkAck(ack *core.WSAck) (*websocketInflight, error) {
 	l := log.L(wc.ctx)
-	var inflight *core.EventDeliveryResponse
+	var inflight *websocketInflight
 	wc.mux.Lock()
 	defer wc.mux.Unlock()
 
@@ -261,16 +321,13 @@ func (wc *websocketConnection) checkAck(ack *core.WSAck) (*core.EventDeliveryRes
 	}
 
 	if ack.ID != nil {
-		newInflight := make([]*core.EventDeliveryResponse, 0, len(wc.inflight))
+		newInflight := make([]*websocketInflight, 0, len(wc.inflight))
 		for _, candidate := range wc.inflight {
 			var match bool
 			if *candidate.ID == *ack.ID {
 				if ack.Subscription != nil {
 					// A subscription has been explicitly specified, so it must match
-					if (ack.Subscription.ID != nil && *ack.Subscription.ID == *candidate.Subscription.ID) ||
-						(ack.Subscription.Name == candidate.Subscription.Name && ack.Subscription.Namespace == candidate.Subscription.Namespace) {
-						match = true
-					}
+					match = subscriptionMatches(ack.Subscription, &candidate.Subscription)
 				} else {
 					// If there's more than one started subscription, that's a problem
 					if len(wc.started) != 1 {
@@ -311,10 +368,92 @@ func (wc *websocketConnection) handleAck(ack *core.WSAck) error {
 	}
 
 	// Deliver the ack to the core, now we're unlocked
-	wc.ws.ack(wc.connID, inflight)
+	wc.ws.ack(wc.connID, inflight.EventDeliveryResponse)
 	return nil
 }
 
+func (wc *websocketConnection) handleNack(nack *core.WSNack) error {
+	inflight, err := wc.checkAck(&nack.WSAck)
+	if err != nil {
+		return err
+	}
+
+	// The dispatcher redelivers everything after the rejected event on the subscription,
+	// so nothing else we have in flight for that subscription will be acknowledged
+	wc.mux.Lock()
+	newInflight := make([]*websocketInflight, 0, len(wc.inflight))
+	for _, candidate := range wc.inflight {
+		if !subscriptionMatches(&inflight.Subscription, &candidate.Subscription) {
+			newInflight = append(newInflight, candidate)
+		}
+	}
+	wc.inflight = newInflight
+	wc.mux.Unlock()
+
+	wc.ws.ack(wc.connID, &core.EventDeliveryResponse{
+		ID:           inflight.ID,
+		Rejected:     true,
+		Info:         nack.Reason,
+		Subscription: inflight.Subscription,
+	})
+	return nil
+}
+
+func (wc *websocketConnection) checkAckUpTo(ack *core.WSAckUpTo) ([]*core.EventDeliveryResponse, error) {
+	wc.mux.Lock()
+	defer wc.mux.Unlock()
+
+	if wc.autoAck {
+		return nil, i18n.NewError(wc.ctx, coremsgs.MsgWSAutoAckEnabled)
+	}
+
+	// Event sequences are allocated across the namespace, so they can be compared across subscriptions
+	acked := []*core.EventDeliveryResponse{}
+	newInflight := make([]*websocketInflight, 0, len(wc.inflight))
+	for _, candidate := range wc.inflight {
+		if candidate.sequence <= ack.Sequence &&
+			(ack.Subscription == nil || subscriptionMatches(ack.Subscription, &candidate.Subscription)) {
+			acked = append(acked, candidate.EventDeliveryResponse)
+		} else {
+			newInflight = append(newInflight, candidate)
+		}
+	}
+	wc.inflight = newInflight
+	return acked, nil
+}
+
+func (wc *websocketConnection) handleAckUpTo(ack *core.WSAckUpTo) error {
+	acked, err := wc.checkAckUpTo(ack)
+	if err != nil {
+		return err
+	}
+
+	log.L(wc.ctx).Debugf("Acknowledging %d events up to sequence %d", len(acked), ack.Sequence)
+	for _, inflight := range acked {
+		wc.ws.ack(wc.connID, inflight)
+	}
+	return nil
+}
+
+func (wc *websocketConnection) handlePause() {
+	wc.mux.Lock()
+	defer wc.mux.Unlock()
+	if wc.resumed == nil {
+		log.L(wc.ctx).Infof("Delivery paused by client")
+		wc.resumed = make(chan struct{})
+	}
+}
+
+func (wc *websocketConnection) handleResume() {
+	wc.mux.Lock()
+	defer wc.mux.Unlock()
+	if wc.resumed != nil {
+		log.L(wc.ctx).Infof("Delivery resumed by client")
+		close(wc.resumed)
+		wc.resumed = nil
+	}
+}
+
 func (wc *websocketConnection) close() {
 	var didClosed bool
 	wc.mux.Lock()
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 )
@@ -34,6 +35,7 @@ type WebSockets struct {
 	ctx          context.Context
 	capabilities *events.Capabilities
 	callbacks    events.Callbacks
+	metrics      metrics.Manager
 	connections  map[string]*websocketConnection
 	connMux      sync.Mutex
 	upgrader     websocket.Upgrader
@@ -41,12 +43,13 @@ type WebSockets struct {
 
 func (ws *WebSockets) Name() string { return "websockets" }
 
-func (ws *WebSockets) Init(ctx context.Context, config config.Section, callbacks events.Callbacks) error {
+func (ws *WebSockets) Init(ctx context.Context, config config.Section, callbacks events.Callbacks, metrics metrics.Manager) error {
 	*ws = WebSockets{
 		ctx:          ctx,
 		connections:  make(map[string]*websocketConnection),
 		capabilities: &events.Capabilities{},
 		callbacks:    callbacks,
+		metrics:      metrics,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  int(config.GetByteSize(ReadBufferSize)),
 			WriteBufferSize: int(config.GetByteSize(WriteBufferSize)),
@@ -73,14 +76,36 @@ func (ws *WebSockets) ValidateOptions(options *core.SubscriptionOptions) error {
 	return nil
 }
 
-func (ws *WebSockets) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+func (ws *WebSockets) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
 	ws.connMux.Lock()
 	conn, ok := ws.connections[connID]
 	ws.connMux.Unlock()
 	if !ok {
+		ws.reportDeliveryFailed(sub)
 		return i18n.NewError(ws.ctx, coremsgs.MsgWSConnectionNotActive, connID)
 	}
-	return conn.dispatch(event)
+	err := conn.dispatch(ctx, event)
+	if err != nil {
+		ws.reportDeliveryFailed(sub)
+	}
+	return err
+}
+
+func (ws *WebSockets) reportDeliveryFailed(sub *core.Subscription) {
+	if ws.metrics.IsMetricsEnabled() {
+		ws.metrics.SubscriptionDeliveryFailed(sub.Namespace, metrics.SubscriptionLabel(sub), ws.Name())
+	}
+}
+
+// reportConnections must be called with the connection lock held
+func (ws *WebSockets) reportConnections() {
+	if ws.metrics.IsMetricsEnabled() {
+		ws.metrics.TransportConnections(ws.Name(), len(ws.connections))
+	}
+}
+
+func (ws *WebSockets) BatchDeliveryRequest(connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	return i18n.NewError(ws.ctx, coremsgs.MsgBatchDeliveryNotSupported, ws.Name())
 }
 
 func (ws *WebSockets) ServeHTTP(res http.ResponseWriter, req *http.Request) {
@@ -93,6 +118,7 @@ func (ws *WebSockets) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	ws.connMux.Lock()
 	wc := newConnection(ws.ctx, ws, wsConn, req)
 	ws.connections[wc.connID] = wc
+	ws.reportConnections()
 	ws.connMux.Unlock()
 
 	wc.processAutoStart(req)
@@ -118,6 +144,7 @@ func (ws *WebSockets) start(wc *websocketConnection, start *core.WSStart) error
 func (ws *WebSockets) connClosed(connID string) {
 	ws.connMux.Lock()
 	delete(ws.connections, connID)
+	ws.reportConnections()
 	ws.connMux.Unlock()
 	// Drop lock before calling back
 	ws.callbacks.ConnectionClosed(connID)
@@ -25,6 +25,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffresty"
@@ -33,6 +34,8 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/wsclient"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/auth"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/stretchr/testify/assert"
@@ -42,11 +45,13 @@ import (
 func newTestWebsockets(t *testing.T, cbs *eventsmocks.Callbacks, queryParams ...string) (ws *WebSockets, wsc wsclient.WSClient, cancel func()) {
 	coreconfig.Reset()
 
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false)
 	ws = &WebSockets{}
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	svrConfig := config.RootSection("ut.websockets")
 	ws.InitConfig(svrConfig)
-	ws.Init(ctx, svrConfig, cbs)
+	ws.Init(ctx, svrConfig, cbs, mmi)
 	assert.Equal(t, "websockets", ws.Name())
 	assert.NotNil(t, ws.Capabilities())
 	cbs.On("ConnectionClosed", mock.Anything).Return(nil).Maybe()
@@ -177,7 +182,7 @@ func TestStartReceiveAckEphemeral(t *testing.T) {
 	assert.NoError(t, err)
 
 	<-waitSubscribed
-	ws.DeliveryRequest(connID, nil, &core.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{ID: fftypes.NewUUID()},
 		},
@@ -227,7 +232,7 @@ func TestStartReceiveDurable(t *testing.T) {
 	assert.NoError(t, err)
 
 	<-waitSubscribed
-	ws.DeliveryRequest(connID, nil, &core.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{ID: fftypes.NewUUID()},
 		},
@@ -238,7 +243,7 @@ func TestStartReceiveDurable(t *testing.T) {
 		},
 	}, nil)
 	// Put a second in flight
-	ws.DeliveryRequest(connID, nil, &core.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{ID: fftypes.NewUUID()},
 		},
@@ -300,7 +305,7 @@ func TestAutoStartReceiveAckEphemeral(t *testing.T) {
 	defer cancel()
 
 	<-waitSubscribed
-	ws.DeliveryRequest(connID, nil, &core.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{ID: fftypes.NewUUID()},
 		},
@@ -332,14 +337,27 @@ func TestAutoStartBadOptions(t *testing.T) {
 	cbs.AssertExpectations(t)
 }
 
+func TestHandleStartNamespaceNotAuthorized(t *testing.T) {
+	wc := &websocketConnection{
+		ctx: context.Background(),
+		principal: &auth.Principal{
+			Subject: "user1",
+			Grants:  []*auth.Grant{{Namespace: "ns1", ReadOnly: true}},
+		},
+	}
+	err := wc.handleStart(&core.WSStart{Namespace: "ns2", Ephemeral: true})
+	assert.Regexp(t, "FF10415", err)
+	assert.Empty(t, wc.started)
+}
+
 func TestHandleAckWithAutoAck(t *testing.T) {
 	eventUUID := fftypes.NewUUID()
 	wsc := &websocketConnection{
 		ctx:          context.Background(),
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*core.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &core.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 	}
@@ -355,8 +373,8 @@ func TestHandleStartFlippingAutoAck(t *testing.T) {
 		ctx:          context.Background(),
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*core.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &core.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 	}
@@ -377,8 +395,8 @@ func TestHandleAckMultipleStartedMissingSub(t *testing.T) {
 			{ephemeral: false, name: "name3", namespace: "ns1"},
 		},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*core.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &core.EventDeliveryResponse{ID: eventUUID}},
 		},
 	}
 	err := wsc.handleAck(&core.WSAck{
@@ -400,8 +418,8 @@ func TestHandleAckMultipleStartedNoSubSingleMatch(t *testing.T) {
 		},
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*core.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &core.EventDeliveryResponse{ID: eventUUID}},
 		},
 	}
 	err := wsc.handleAck(&core.WSAck{
@@ -415,12 +433,240 @@ func TestHandleAckNoneInflight(t *testing.T) {
 	wsc := &websocketConnection{
 		ctx:          context.Background(),
 		sendMessages: make(chan interface{}, 1),
-		inflight:     []*core.EventDeliveryResponse{},
+		inflight:     []*websocketInflight{},
 	}
 	err := wsc.handleAck(&core.WSAck{})
 	assert.Regexp(t, "FF10175", err)
 }
 
+func TestStartReceiveNackAckUpToEphemeral(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, wsc, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+	var connID string
+	sub := cbs.On("EphemeralSubscription",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		"ns1", mock.Anything, mock.Anything).Return(nil)
+	responses := make(chan *core.EventDeliveryResponse, 1)
+	cbs.On("DeliveryResponse",
+		mock.MatchedBy(func(s string) bool { return s == connID }),
+		mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		responses <- a[1].(*core.EventDeliveryResponse)
+	})
+
+	waitSubscribed := make(chan struct{})
+	sub.RunFn = func(a mock.Arguments) {
+		close(waitSubscribed)
+	}
+
+	err := wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","ephemeral":true}`))
+	assert.NoError(t, err)
+	<-waitSubscribed
+
+	subRef := core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}
+	event1 := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID(), Sequence: 1},
+		},
+		Subscription: subRef,
+	}
+	ws.DeliveryRequest(context.Background(), connID, nil, event1, nil)
+	<-wsc.Receive()
+
+	err = wsc.Send(context.Background(), []byte(`{"type":"nack","reason":"not ready"}`))
+	assert.NoError(t, err)
+	res := <-responses
+	assert.Equal(t, event1.ID, res.ID)
+	assert.True(t, res.Rejected)
+	assert.Equal(t, "not ready", res.Info)
+
+	err = wsc.Send(context.Background(), []byte(`{"type":"pause"}`))
+	assert.NoError(t, err)
+	err = wsc.Send(context.Background(), []byte(`{"type":"resume"}`))
+	assert.NoError(t, err)
+
+	ws.DeliveryRequest(context.Background(), connID, nil, event1, nil)
+	<-wsc.Receive()
+
+	err = wsc.Send(context.Background(), []byte(`{"type":"ack_upto","sequence":1}`))
+	assert.NoError(t, err)
+	res = <-responses
+	assert.Equal(t, event1.ID, res.ID)
+	assert.False(t, res.Rejected)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestHandleNackDropsSubscriptionInflight(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	sub1 := core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}
+	sub2 := core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub2"}
+	event1 := &core.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: sub1}
+	event2 := &core.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: sub2}
+	event3 := &core.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: sub1}
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(res *core.EventDeliveryResponse) bool {
+		return res.ID.Equals(event1.ID) && res.Rejected && res.Info == "pop" && res.Subscription.Name == "sub1"
+	})).Return(nil)
+	wsc := &websocketConnection{
+		ctx:    context.Background(),
+		connID: "conn1",
+		ws: &WebSockets{
+			ctx:       context.Background(),
+			callbacks: cbs,
+		},
+		started: []*websocketStartedSub{
+			{ephemeral: false, name: "sub1", namespace: "ns1"},
+			{ephemeral: false, name: "sub2", namespace: "ns1"},
+		},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: event1, sequence: 1},
+			{EventDeliveryResponse: event2, sequence: 2},
+			{EventDeliveryResponse: event3, sequence: 3},
+		},
+	}
+	err := wsc.handleNack(&core.WSNack{
+		WSAck: core.WSAck{
+			ID:           event1.ID,
+			Subscription: &core.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+		},
+		Reason: "pop",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, wsc.inflight, 1)
+	assert.Equal(t, event2.ID, wsc.inflight[0].ID)
+	cbs.AssertExpectations(t)
+}
+
+func TestHandleNackNoneInflight(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx:      context.Background(),
+		inflight: []*websocketInflight{},
+	}
+	err := wsc.handleNack(&core.WSNack{})
+	assert.Regexp(t, "FF10175", err)
+}
+
+func TestHandleAckUpTo(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	sub1 := core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}
+	sub2 := core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub2"}
+	event1 := &core.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: sub1}
+	event2 := &core.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: sub2}
+	event3 := &core.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: sub1}
+	event4 := &core.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: sub1}
+	cbs.On("DeliveryResponse", "conn1", event1).Return(nil).Once()
+	cbs.On("DeliveryResponse", "conn1", event3).Return(nil).Once()
+	cbs.On("DeliveryResponse", "conn1", event2).Return(nil).Once()
+	wsc := &websocketConnection{
+		ctx:    context.Background(),
+		connID: "conn1",
+		ws: &WebSockets{
+			ctx:       context.Background(),
+			callbacks: cbs,
+		},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: event1, sequence: 1},
+			{EventDeliveryResponse: event2, sequence: 2},
+			{EventDeliveryResponse: event3, sequence: 3},
+			{EventDeliveryResponse: event4, sequence: 4},
+		},
+	}
+
+	// Only the events for the specified subscription are acknowledged
+	err := wsc.handleAckUpTo(&core.WSAckUpTo{
+		Sequence:     3,
+		Subscription: &core.SubscriptionRef{ID: sub1.ID},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, wsc.inflight, 2)
+
+	// Without a subscription, everything up to the sequence is acknowledged
+	err = wsc.handleAckUpTo(&core.WSAckUpTo{
+		Sequence: 3,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, wsc.inflight, 1)
+	assert.Equal(t, event4.ID, wsc.inflight[0].ID)
+
+	// Nothing left to acknowledge is not an error
+	err = wsc.handleAckUpTo(&core.WSAckUpTo{
+		Sequence: 3,
+	})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestHandleAckUpToWithAutoAck(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx:     context.Background(),
+		autoAck: true,
+	}
+	err := wsc.handleAckUpTo(&core.WSAckUpTo{
+		Sequence: 1,
+	})
+	assert.Regexp(t, "FF10180", err)
+}
+
+func TestDispatchPauseResume(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx:          context.Background(),
+		sendMessages: make(chan interface{}, 1),
+	}
+	wsc.handlePause()
+	wsc.handlePause()
+
+	dispatched := make(chan error)
+	go func() {
+		dispatched <- wsc.dispatch(context.Background(), &core.EventDelivery{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{ID: fftypes.NewUUID(), Sequence: 1},
+			},
+		})
+	}()
+	select {
+	case <-dispatched:
+		assert.Fail(t, "dispatched while paused")
+	case <-wsc.sendMessages:
+		assert.Fail(t, "sent while paused")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	wsc.handleResume()
+	wsc.handleResume()
+	assert.NoError(t, <-dispatched)
+	<-wsc.sendMessages
+	assert.Len(t, wsc.inflight, 1)
+	assert.Equal(t, int64(1), wsc.inflight[0].sequence)
+}
+
+func TestDispatchPausedClosed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wsc := &websocketConnection{
+		ctx: ctx,
+	}
+	wsc.handlePause()
+	cancel()
+	err := wsc.dispatch(context.Background(), &core.EventDelivery{})
+	assert.Regexp(t, "FF00147", err)
+}
+
+func TestDispatchPausedDispatcherClosed(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx: context.Background(),
+	}
+	wsc.handlePause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := wsc.dispatch(ctx, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID(), Sequence: 1},
+		},
+	})
+	assert.Regexp(t, "FF10182", err)
+	assert.Empty(t, wsc.inflight)
+}
+
 func TestProtocolErrorSwallowsSendError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -475,17 +721,55 @@ func TestConnectionDispatchAfterClose(t *testing.T) {
 	wsc := &websocketConnection{
 		ctx: ctx,
 	}
-	err := wsc.dispatch(&core.EventDelivery{})
+	err := wsc.dispatch(context.Background(), &core.EventDelivery{})
 	assert.Regexp(t, "FF00147", err)
 }
 
 func TestWebsocketDispatchAfterClose(t *testing.T) {
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionDeliveryFailed", "ns1", "sub1", "websockets").Return()
 	ws := &WebSockets{
 		ctx:         context.Background(),
 		connections: make(map[string]*websocketConnection),
+		metrics:     mmi,
 	}
-	err := ws.DeliveryRequest("gone", nil, &core.EventDelivery{}, nil)
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+	}
+	err := ws.DeliveryRequest(context.Background(), "gone", sub, &core.EventDelivery{}, nil)
 	assert.Regexp(t, "FF10173", err)
+
+	mmi.AssertExpectations(t)
+}
+
+func TestWebsocketConnClosedMetrics(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("ConnectionClosed", "conn1").Return()
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("TransportConnections", "websockets", 1).Return()
+	ws := &WebSockets{
+		ctx: context.Background(),
+		connections: map[string]*websocketConnection{
+			"conn1": {},
+			"conn2": {},
+		},
+		callbacks: cbs,
+		metrics:   mmi,
+	}
+	ws.connClosed("conn1")
+
+	cbs.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestBatchDeliveryNotSupported(t *testing.T) {
+	ws := &WebSockets{
+		ctx: context.Background(),
+	}
+	err := ws.BatchDeliveryRequest("conn1", nil, []*core.CombinedEventDataDelivery{})
+	assert.Regexp(t, "FF10433.*websockets", err)
 }
 
 func TestDispatchAutoAck(t *testing.T) {
@@ -504,7 +788,7 @@ func TestDispatchAutoAck(t *testing.T) {
 		autoAck:      true,
 	}
 	wsc.ws.connections[wsc.connID] = wsc
-	err := wsc.ws.DeliveryRequest(wsc.connID, nil, &core.EventDelivery{
+	err := wsc.ws.DeliveryRequest(context.Background(), wsc.connID, nil, &core.EventDelivery{
 		EnrichedEvent: core.EnrichedEvent{
 			Event: core.Event{ID: fftypes.NewUUID()},
 		},
@@ -38,6 +38,15 @@ type Manager interface {
 	BlockchainTransaction(location, methodName string)
 	BlockchainQuery(location, methodName string)
 	BlockchainEvent(location, signature string)
+	SubscriptionEventsDelivered(namespace, subscription, transport string, count int)
+	SubscriptionEventAcked(namespace, subscription, transport string, elapsed time.Duration)
+	SubscriptionEventNacked(namespace, subscription, transport string, elapsed time.Duration)
+	SubscriptionEventsInflight(namespace, subscription, transport string, count int)
+	SubscriptionOffsetLag(namespace, subscription, transport string, lag int64)
+	SubscriptionDeliveryFailed(namespace, subscription, transport string)
+	SubscriptionDeleted(namespace, subscription, transport string)
+	TransportConnections(transport string, count int)
+	RetentionRowsPruned(namespace, collection string, count int64)
 	AddTime(id string)
 	GetTime(id string) time.Time
 	DeleteTime(id string)
@@ -141,6 +150,51 @@ func (mm *metricsManager) BlockchainEvent(location, signature string) {
 	BlockchainEventsCounter.WithLabelValues(location, signature).Inc()
 }
 
+func (mm *metricsManager) SubscriptionEventsDelivered(namespace, subscription, transport string, count int) {
+	SubscriptionDeliveredCounter.WithLabelValues(namespace, subscription, transport).Add(float64(count))
+}
+
+func (mm *metricsManager) SubscriptionEventAcked(namespace, subscription, transport string, elapsed time.Duration) {
+	SubscriptionAckedCounter.WithLabelValues(namespace, subscription, transport).Inc()
+	SubscriptionDeliveryHistogram.WithLabelValues(namespace, subscription, transport).Observe(elapsed.Seconds())
+}
+
+func (mm *metricsManager) SubscriptionEventNacked(namespace, subscription, transport string, elapsed time.Duration) {
+	SubscriptionNackedCounter.WithLabelValues(namespace, subscription, transport).Inc()
+	SubscriptionDeliveryHistogram.WithLabelValues(namespace, subscription, transport).Observe(elapsed.Seconds())
+}
+
+func (mm *metricsManager) SubscriptionEventsInflight(namespace, subscription, transport string, count int) {
+	SubscriptionInflightGauge.WithLabelValues(namespace, subscription, transport).Set(float64(count))
+}
+
+func (mm *metricsManager) SubscriptionOffsetLag(namespace, subscription, transport string, lag int64) {
+	SubscriptionLagGauge.WithLabelValues(namespace, subscription, transport).Set(float64(lag))
+}
+
+func (mm *metricsManager) SubscriptionDeliveryFailed(namespace, subscription, transport string) {
+	SubscriptionDeliveryErrorsCounter.WithLabelValues(namespace, subscription, transport).Inc()
+}
+
+// SubscriptionDeleted removes the series for a subscription, so they do not accumulate as subscriptions are replaced
+func (mm *metricsManager) SubscriptionDeleted(namespace, subscription, transport string) {
+	SubscriptionDeliveredCounter.DeleteLabelValues(namespace, subscription, transport)
+	SubscriptionAckedCounter.DeleteLabelValues(namespace, subscription, transport)
+	SubscriptionNackedCounter.DeleteLabelValues(namespace, subscription, transport)
+	SubscriptionInflightGauge.DeleteLabelValues(namespace, subscription, transport)
+	SubscriptionDeliveryHistogram.DeleteLabelValues(namespace, subscription, transport)
+	SubscriptionLagGauge.DeleteLabelValues(namespace, subscription, transport)
+	SubscriptionDeliveryErrorsCounter.DeleteLabelValues(namespace, subscription, transport)
+}
+
+func (mm *metricsManager) TransportConnections(transport string, count int) {
+	TransportConnectionsGauge.WithLabelValues(transport).Set(float64(count))
+}
+
+func (mm *metricsManager) RetentionRowsPruned(namespace, collection string, count int64) {
+	RetentionPrunedCounter.WithLabelValues(namespace, collection).Add(float64(count))
+}
+
 func (mm *metricsManager) AddTime(id string) {
 	mutex.Lock()
 	mm.timeMap[id] = time.Now()
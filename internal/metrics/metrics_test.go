@@ -204,6 +204,75 @@ func TestBlockchainEvents(t *testing.T) {
 	assert.Equal(t, float64(1), v)
 }
 
+func TestSubscriptionEvents(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	labels := prometheus.Labels{NamespaceLabelName: "ns1", SubscriptionLabelName: "sub1", TransportLabelName: "websockets"}
+	mm.SubscriptionEventsDelivered("ns1", "sub1", "websockets", 3)
+	mm.SubscriptionEventsInflight("ns1", "sub1", "websockets", 3)
+	mm.SubscriptionEventAcked("ns1", "sub1", "websockets", 100*time.Millisecond)
+	mm.SubscriptionEventAcked("ns1", "sub1", "websockets", 200*time.Millisecond)
+	mm.SubscriptionEventNacked("ns1", "sub1", "websockets", 300*time.Millisecond)
+	mm.SubscriptionEventsInflight("ns1", "sub1", "websockets", 0)
+	mm.SubscriptionOffsetLag("ns1", "sub1", "websockets", 12)
+
+	m, err := SubscriptionDeliveredCounter.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), testutil.ToFloat64(m))
+	m, err = SubscriptionAckedCounter.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m))
+	m, err = SubscriptionNackedCounter.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m))
+	g, err := SubscriptionInflightGauge.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), testutil.ToFloat64(g))
+	g, err = SubscriptionLagGauge.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(12), testutil.ToFloat64(g))
+	assert.Equal(t, 1, testutil.CollectAndCount(SubscriptionDeliveryHistogram, SubscriptionDeliveryHistogramName))
+}
+
+func TestSubscriptionTransportAndDelete(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	labels := prometheus.Labels{NamespaceLabelName: "ns1", SubscriptionLabelName: "sub1", TransportLabelName: "webhooks"}
+	mm.SubscriptionEventsDelivered("ns1", "sub1", "webhooks", 1)
+	mm.SubscriptionEventAcked("ns1", "sub1", "webhooks", 100*time.Millisecond)
+	mm.SubscriptionDeliveryFailed("ns1", "sub1", "webhooks")
+	mm.SubscriptionDeliveryFailed("ns1", "sub1", "webhooks")
+	mm.TransportConnections("websockets", 2)
+
+	m, err := SubscriptionDeliveryErrorsCounter.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m))
+	g, err := TransportConnectionsGauge.GetMetricWith(prometheus.Labels{TransportLabelName: "websockets"})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(g))
+
+	mm.SubscriptionDeleted("ns1", "sub1", "webhooks")
+	assert.Equal(t, 0, testutil.CollectAndCount(SubscriptionDeliveredCounter, SubscriptionDeliveredCounterName))
+	assert.Equal(t, 0, testutil.CollectAndCount(SubscriptionAckedCounter, SubscriptionAckedCounterName))
+	assert.Equal(t, 0, testutil.CollectAndCount(SubscriptionDeliveryHistogram, SubscriptionDeliveryHistogramName))
+	assert.Equal(t, 0, testutil.CollectAndCount(SubscriptionDeliveryErrorsCounter, SubscriptionDeliveryErrorsCounterName))
+}
+
+func TestSubscriptionLabel(t *testing.T) {
+	assert.Equal(t, "sub1", SubscriptionLabel(&core.Subscription{SubscriptionRef: core.SubscriptionRef{Name: "sub1"}}))
+	assert.Equal(t, "ephemeral", SubscriptionLabel(&core.Subscription{SubscriptionRef: core.SubscriptionRef{Name: "abcd"}, Ephemeral: true}))
+}
+
+func TestRetentionRowsPruned(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.RetentionRowsPruned("ns1", "events", 10)
+	mm.RetentionRowsPruned("ns1", "events", 5)
+	m, err := RetentionPrunedCounter.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1", CollectionLabelName: "events"})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(15), testutil.ToFloat64(m))
+}
+
 func TestIsMetricsEnabledTrue(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
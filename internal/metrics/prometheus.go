@@ -88,6 +88,8 @@ func initMetricsCollectors() {
 	InitTokenBurnMetrics()
 	InitBatchPinMetrics()
 	InitBlockchainMetrics()
+	InitSubscriptionMetrics()
+	InitRetentionMetrics()
 }
 
 func registerMetricsCollectors() {
@@ -101,4 +103,6 @@ func registerMetricsCollectors() {
 	RegisterTokenTransferMetrics()
 	RegisterTokenBurnMetrics()
 	RegisterBlockchainMetrics()
+	RegisterSubscriptionMetrics()
+	RegisterRetentionMetrics()
 }
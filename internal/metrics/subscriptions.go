@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var SubscriptionDeliveredCounter *prometheus.CounterVec
+var SubscriptionAckedCounter *prometheus.CounterVec
+var SubscriptionNackedCounter *prometheus.CounterVec
+var SubscriptionInflightGauge *prometheus.GaugeVec
+var SubscriptionDeliveryHistogram *prometheus.HistogramVec
+var SubscriptionLagGauge *prometheus.GaugeVec
+var SubscriptionDeliveryErrorsCounter *prometheus.CounterVec
+var TransportConnectionsGauge *prometheus.GaugeVec
+
+// SubscriptionDeliveredCounterName is the prometheus metric for tracking the total number of events delivered to a subscription
+var SubscriptionDeliveredCounterName = "ff_subscription_delivered_total"
+
+// SubscriptionAckedCounterName is the prometheus metric for tracking the total number of events acknowledged on a subscription
+var SubscriptionAckedCounterName = "ff_subscription_acked_total"
+
+// SubscriptionNackedCounterName is the prometheus metric for tracking the total number of events rejected on a subscription
+var SubscriptionNackedCounterName = "ff_subscription_nacked_total"
+
+// SubscriptionInflightGaugeName is the prometheus metric for tracking the number of events in-flight on a subscription
+var SubscriptionInflightGaugeName = "ff_subscription_inflight"
+
+// SubscriptionDeliveryHistogramName is the prometheus metric for tracking the time from delivery of an event to its acknowledgement - histogram
+var SubscriptionDeliveryHistogramName = "ff_subscription_delivery_seconds"
+
+// SubscriptionLagGaugeName is the prometheus metric for tracking how far the offset of a subscription is behind the latest event
+var SubscriptionLagGaugeName = "ff_subscription_offset_lag"
+
+// SubscriptionDeliveryErrorsCounterName is the prometheus metric for tracking the attempts by a transport to deliver events to a subscription that failed
+var SubscriptionDeliveryErrorsCounterName = "ff_subscription_delivery_errors_total"
+
+// TransportConnectionsGaugeName is the prometheus metric for tracking the number of connections open to a transport
+var TransportConnectionsGaugeName = "ff_transport_connections"
+
+var NamespaceLabelName = "namespace"
+var SubscriptionLabelName = "subscription"
+var TransportLabelName = "transport"
+
+func InitSubscriptionMetrics() {
+	labels := []string{NamespaceLabelName, SubscriptionLabelName, TransportLabelName}
+	SubscriptionDeliveredCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: SubscriptionDeliveredCounterName,
+		Help: "Number of events delivered to subscriptions",
+	}, labels)
+	SubscriptionAckedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: SubscriptionAckedCounterName,
+		Help: "Number of events acknowledged on subscriptions",
+	}, labels)
+	SubscriptionNackedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: SubscriptionNackedCounterName,
+		Help: "Number of events rejected on subscriptions",
+	}, labels)
+	SubscriptionInflightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: SubscriptionInflightGaugeName,
+		Help: "Number of events delivered to subscriptions, that are awaiting acknowledgement",
+	}, labels)
+	SubscriptionDeliveryHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: SubscriptionDeliveryHistogramName,
+		Help: "Histogram of event deliveries on subscriptions, bucketed by time to acknowledgement or rejection",
+	}, labels)
+	SubscriptionLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: SubscriptionLagGaugeName,
+		Help: "Number of events in the namespace with a sequence greater than the offset the subscription is polling from, which moves forwards as events are acknowledged",
+	}, labels)
+	SubscriptionDeliveryErrorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: SubscriptionDeliveryErrorsCounterName,
+		Help: "Number of attempts by transports to deliver events to subscriptions that failed",
+	}, labels)
+	TransportConnectionsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: TransportConnectionsGaugeName,
+		Help: "Number of connections open to transports",
+	}, []string{TransportLabelName})
+}
+
+func RegisterSubscriptionMetrics() {
+	registry.MustRegister(SubscriptionDeliveredCounter)
+	registry.MustRegister(SubscriptionAckedCounter)
+	registry.MustRegister(SubscriptionNackedCounter)
+	registry.MustRegister(SubscriptionInflightGauge)
+	registry.MustRegister(SubscriptionDeliveryHistogram)
+	registry.MustRegister(SubscriptionLagGauge)
+	registry.MustRegister(SubscriptionDeliveryErrorsCounter)
+	registry.MustRegister(TransportConnectionsGauge)
+}
+
+// SubscriptionLabel returns the subscription label for metrics. Ephemeral subscriptions are named with
+// a new UUID on every connection, so they are reported together to bound the number of series.
+func SubscriptionLabel(sub *core.Subscription) string {
+	if sub.Ephemeral {
+		return "ephemeral"
+	}
+	return sub.Name
+}
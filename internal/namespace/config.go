@@ -41,6 +41,16 @@ func InitConfig(withDefaults bool) {
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceMultipartyOrgName)
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceMultipartyOrgDescription)
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceMultipartyOrgKey)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionInterval, "1h")
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionBatchSize, 1000)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionEventsMaxAge)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionEventsMaxRows)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionMessagesMaxAge)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionMessagesMaxRows)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionOperationsMaxAge)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionOperationsMaxRows)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionBlockchainEventsMaxAge)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionBlockchainEventsMaxRows)
 	if withDefaults {
 		namespaceConfig.AddKnownKey(NamespacePredefined+".0."+coreconfig.NamespaceName, "default")
 		namespaceConfig.AddKnownKey(NamespacePredefined+".0."+coreconfig.NamespaceDescription, "Default predefined namespace")
@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/internal/identity/iifactory"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/internal/retention"
 	"github.com/hyperledger/firefly/internal/sharedstorage/ssfactory"
 	"github.com/hyperledger/firefly/internal/spievents"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
@@ -536,6 +537,12 @@ func (nm *namespaceManager) initPlugins(ctx context.Context) (err error) {
 		if err = entry.plugin.Init(ctx, entry.config); err != nil {
 			return err
 		}
+		// Refuse to start against a schema that does not match this build
+		if migrator, ok := entry.plugin.(database.Migrator); ok {
+			if err = migrator.CheckSchema(ctx); err != nil {
+				return err
+			}
+		}
 		entry.plugin.RegisterListener(nm)
 	}
 	for _, entry := range nm.plugins.blockchain {
@@ -645,6 +652,7 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 
 	config := orchestrator.Config{
 		DefaultKey: conf.GetString(coreconfig.NamespaceDefaultKey),
+		Retention:  nm.loadRetentionConfig(conf),
 	}
 	var p *orchestrator.Plugins
 	var err error
@@ -668,6 +676,26 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 	}, nil
 }
 
+func (nm *namespaceManager) loadRetentionConfig(conf config.Section) retention.Config {
+	policy := func(collection database.CollectionName, maxAgeKey, maxRowsKey string) *retention.Policy {
+		return &retention.Policy{
+			Collection: collection,
+			MaxAge:     conf.GetDuration(maxAgeKey),
+			MaxRows:    conf.GetInt64(maxRowsKey),
+		}
+	}
+	return retention.Config{
+		Interval:  conf.GetDuration(coreconfig.NamespaceRetentionInterval),
+		BatchSize: conf.GetInt(coreconfig.NamespaceRetentionBatchSize),
+		Policies: []*retention.Policy{
+			policy(database.CollectionName(database.CollectionEvents), coreconfig.NamespaceRetentionEventsMaxAge, coreconfig.NamespaceRetentionEventsMaxRows),
+			policy(database.CollectionName(database.CollectionMessages), coreconfig.NamespaceRetentionMessagesMaxAge, coreconfig.NamespaceRetentionMessagesMaxRows),
+			policy(database.CollectionName(database.CollectionOperations), coreconfig.NamespaceRetentionOperationsMaxAge, coreconfig.NamespaceRetentionOperationsMaxRows),
+			policy(database.CollectionName(database.CollectionBlockchainEvents), coreconfig.NamespaceRetentionBlockchainEventsMaxAge, coreconfig.NamespaceRetentionBlockchainEventsMaxRows),
+		},
+	}
+}
+
 func (nm *namespaceManager) validateMultiPartyConfig(ctx context.Context, name string, plugins []string) (*orchestrator.Plugins, error) {
 	var result orchestrator.Plugins
 	for _, pluginName := range plugins {
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -42,6 +43,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/spieventsmocks"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/tokens"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -156,6 +158,28 @@ func TestInitDatabaseFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+type mockMigratorPlugin struct {
+	*databasemocks.Plugin
+	*databasemocks.Migrator
+}
+
+func TestInitDatabaseSchemaMismatch(t *testing.T) {
+	nm := newTestNamespaceManager(true)
+	defer nm.cleanup(t)
+
+	nm.utOrchestrator = &orchestratormocks.Orchestrator{}
+
+	mmg := &databasemocks.Migrator{}
+	nm.plugins.database["postgres"] = databasePlugin{plugin: &mockMigratorPlugin{nm.mdi, mmg}}
+	nm.mdi.On("Init", mock.Anything, mock.Anything).Return(nil)
+	mmg.On("CheckSchema", mock.Anything).Return(fmt.Errorf("pop"))
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := nm.Init(ctx, cancelCtx)
+	assert.EqualError(t, err, "pop")
+	mmg.AssertExpectations(t)
+}
+
 func TestInitBlockchainFail(t *testing.T) {
 	nm := newTestNamespaceManager(true)
 	defer nm.cleanup(t)
@@ -765,6 +789,44 @@ func TestLoadNamespacesUseDefaults(t *testing.T) {
 	assert.Len(t, nm.namespaces, 1)
 }
 
+func TestLoadNamespacesRetention(t *testing.T) {
+	nm := newTestNamespaceManager(true)
+	defer nm.cleanup(t)
+
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      retention:
+        interval: 10m
+        batchsize: 500
+        events:
+          maxage: 720h
+          maxrows: 100000
+        operations:
+          maxage: 24h
+  org:
+    name: org1
+  `))
+	assert.NoError(t, err)
+
+	err = nm.loadNamespaces(context.Background())
+	assert.NoError(t, err)
+	conf := nm.namespaces["ns1"].config.Retention
+	assert.True(t, conf.Enabled())
+	assert.Equal(t, 10*time.Minute, conf.Interval)
+	assert.Equal(t, 500, conf.BatchSize)
+	assert.Len(t, conf.Policies, 4)
+	assert.Equal(t, database.CollectionName(database.CollectionEvents), conf.Policies[0].Collection)
+	assert.Equal(t, 720*time.Hour, conf.Policies[0].MaxAge)
+	assert.Equal(t, int64(100000), conf.Policies[0].MaxRows)
+	assert.Equal(t, time.Duration(0), conf.Policies[1].MaxAge)
+	assert.Equal(t, 24*time.Hour, conf.Policies[2].MaxAge)
+	assert.Equal(t, int64(0), conf.Policies[3].MaxRows)
+}
+
 func TestLoadNamespacesGatewayNoDatabase(t *testing.T) {
 	nm := newTestNamespaceManager(true)
 	defer nm.cleanup(t)
@@ -0,0 +1,61 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// GetDatabaseMigrator returns a database plugin from the configuration, connected to manage the migrations
+// of its schema. The name can be empty when there is only one database plugin configured.
+func GetDatabaseMigrator(ctx context.Context, name string) (database.Migrator, error) {
+	nm := &namespaceManager{pluginNames: make(map[string]bool)}
+	plugins, err := nm.getDatabasePlugins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(plugins))
+	for pluginName := range plugins {
+		names = append(names, pluginName)
+	}
+	sort.Strings(names)
+
+	if name == "" {
+		if len(names) > 1 {
+			return nil, i18n.NewError(ctx, coremsgs.MsgDatabasePluginNotSelected, strings.Join(names, ", "))
+		}
+		name = names[0]
+	}
+	entry, ok := plugins[name]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDatabasePluginNotFound, name, strings.Join(names, ", "))
+	}
+	migrator, ok := entry.plugin.(database.Migrator)
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgMigrationsNotSupported, name)
+	}
+	if err := migrator.InitMigrator(ctx, entry.config); err != nil {
+		return nil, err
+	}
+	return migrator, nil
+}
@@ -0,0 +1,100 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/database/difactory"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMigratorConfig(t *testing.T, yaml string) {
+	coreconfig.Reset()
+	InitConfig(true)
+	difactory.InitConfigDeprecated(deprecatedDatabaseConfig)
+	difactory.InitConfig(databaseConfig)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(yaml))
+	assert.NoError(t, err)
+}
+
+const testMigratorConfig = `
+  plugins:
+    database:
+    - name: db1
+      type: postgres
+      postgres:
+        url: postgres://localhost:5432/db1
+    - name: db2
+      type: postgres
+      postgres:
+        url: postgres://localhost:5432/db2
+`
+
+func TestGetDatabaseMigrator(t *testing.T) {
+	newTestMigratorConfig(t, testMigratorConfig)
+	migrator, err := GetDatabaseMigrator(context.Background(), "db2")
+	assert.NoError(t, err)
+	assert.NotNil(t, migrator)
+}
+
+func TestGetDatabaseMigratorOnlyPlugin(t *testing.T) {
+	newTestMigratorConfig(t, `
+  database:
+    type: postgres
+    postgres:
+      url: postgres://localhost:5432/firefly
+`)
+	migrator, err := GetDatabaseMigrator(context.Background(), "")
+	assert.NoError(t, err)
+	assert.NotNil(t, migrator)
+}
+
+func TestGetDatabaseMigratorNotSelected(t *testing.T) {
+	newTestMigratorConfig(t, testMigratorConfig)
+	_, err := GetDatabaseMigrator(context.Background(), "")
+	assert.Regexp(t, "FF10466.*db1, db2", err)
+}
+
+func TestGetDatabaseMigratorNotFound(t *testing.T) {
+	newTestMigratorConfig(t, testMigratorConfig)
+	_, err := GetDatabaseMigrator(context.Background(), "db3")
+	assert.Regexp(t, "FF10467.*db3", err)
+}
+
+func TestGetDatabaseMigratorBadConfig(t *testing.T) {
+	newTestMigratorConfig(t, `
+  database:
+    type: wrong
+`)
+	_, err := GetDatabaseMigrator(context.Background(), "")
+	assert.Regexp(t, "FF10122.*wrong", err)
+}
+
+func TestGetDatabaseMigratorInitFail(t *testing.T) {
+	newTestMigratorConfig(t, `
+  database:
+    type: postgres
+`)
+	_, err := GetDatabaseMigrator(context.Background(), "")
+	assert.Regexp(t, "FF10138", err)
+}
@@ -84,6 +84,14 @@ func (bc *boundCallbacks) BlockchainEvent(event *blockchain.EventWithSubscriptio
 	return bc.ei.BlockchainEvent(event)
 }
 
+func (bc *boundCallbacks) BlockchainEventRemoved(event *blockchain.EventWithSubscription) error {
+	return bc.ei.BlockchainEventRemoved(event)
+}
+
+func (bc *boundCallbacks) ContractListenerConfirmations(subscription string) (*int, error) {
+	return bc.ei.ContractListenerConfirmations(subscription)
+}
+
 func (bc *boundCallbacks) TokensApproved(plugin tokens.Plugin, approval *tokens.TokenApproval) error {
 	return bc.ei.TokensApproved(plugin, approval)
 }
@@ -101,6 +101,14 @@ func TestBoundCallbacks(t *testing.T) {
 	err = bc.BlockchainEvent(&blockchain.EventWithSubscription{})
 	assert.EqualError(t, err, "pop")
 
+	mei.On("BlockchainEventRemoved", mock.AnythingOfType("*blockchain.EventWithSubscription")).Return(fmt.Errorf("pop"))
+	err = bc.BlockchainEventRemoved(&blockchain.EventWithSubscription{})
+	assert.EqualError(t, err, "pop")
+
+	mei.On("ContractListenerConfirmations", "sub1").Return(nil, fmt.Errorf("pop"))
+	_, err = bc.ContractListenerConfirmations("sub1")
+	assert.EqualError(t, err, "pop")
+
 	mei.On("SharedStorageBatchDownloaded", mss, "ns1", "payload1", []byte(`{}`)).Return(nil, fmt.Errorf("pop"))
 	_, err = bc.SharedStorageBatchDownloaded("ns1", "payload1", []byte(`{}`))
 	assert.EqualError(t, err, "pop")
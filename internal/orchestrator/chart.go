@@ -39,7 +39,7 @@ func (or *orchestrator) getHistogramIntervals(startTime int64, endTime int64, nu
 	return intervals
 }
 
-func (or *orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, collection database.CollectionName) ([]*core.ChartHistogram, error) {
+func (or *orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, collection database.CollectionName, groupBy string) ([]*core.ChartHistogram, error) {
 	if buckets > core.ChartHistogramMaxBuckets || buckets < core.ChartHistogramMinBuckets {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidNumberOfIntervals, core.ChartHistogramMinBuckets, core.ChartHistogramMaxBuckets)
 	}
@@ -49,10 +49,15 @@ func (or *orchestrator) GetChartHistogram(ctx context.Context, ns string, startT
 
 	intervals := or.getHistogramIntervals(startTime, endTime, buckets)
 
-	histogram, err := or.database().GetChartHistogram(ctx, ns, intervals, collection)
+	histogram, err := or.database().GetChartHistogram(ctx, ns, intervals, collection, groupBy)
 	if err != nil {
 		return nil, err
 	}
 
 	return histogram, nil
 }
+
+func (or *orchestrator) GetAggregate(ctx context.Context, ns string, collection database.CollectionName, query *core.AggregateQuery, filter database.AndFilter) ([]*core.AggregateResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database().GetAggregate(ctx, collection, query, filter)
+}
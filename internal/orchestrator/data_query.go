@@ -50,10 +50,12 @@ func (or *orchestrator) verifyIDAndNamespace(ctx context.Context, ns, id string)
 }
 
 func (or *orchestrator) GetNamespace(ctx context.Context, ns string) (*core.Namespace, error) {
+	ctx = database.WithReadReplica(ctx)
 	return or.database().GetNamespace(ctx, ns)
 }
 
 func (or *orchestrator) GetTransactionByID(ctx context.Context, ns, id string) (*core.Transaction, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -66,6 +68,7 @@ func (or *orchestrator) GetTransactionByID(ctx context.Context, ns, id string) (
 }
 
 func (or *orchestrator) GetTransactionOperations(ctx context.Context, ns, id string) ([]*core.Operation, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, nil, err
@@ -94,6 +97,7 @@ func (or *orchestrator) getMessageByID(ctx context.Context, ns, id string) (*cor
 }
 
 func (or *orchestrator) GetMessageByID(ctx context.Context, ns, id string) (*core.Message, error) {
+	ctx = database.WithReadReplica(ctx)
 	return or.getMessageByID(ctx, ns, id)
 }
 
@@ -111,6 +115,7 @@ func (or *orchestrator) fetchMessageData(ctx context.Context, msg *core.Message)
 }
 
 func (or *orchestrator) GetMessageByIDWithData(ctx context.Context, ns, id string) (*core.MessageInOut, error) {
+	ctx = database.WithReadReplica(ctx)
 	msg, err := or.getMessageByID(ctx, ns, id)
 	if err == nil && msg != nil {
 		err = or.checkNamespace(ctx, ns, msg.Header.Namespace)
@@ -122,6 +127,7 @@ func (or *orchestrator) GetMessageByIDWithData(ctx context.Context, ns, id strin
 }
 
 func (or *orchestrator) GetBatchByID(ctx context.Context, ns, id string) (*core.BatchPersisted, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -134,6 +140,7 @@ func (or *orchestrator) GetBatchByID(ctx context.Context, ns, id string) (*core.
 }
 
 func (or *orchestrator) GetDataByID(ctx context.Context, ns, id string) (*core.Data, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -146,6 +153,7 @@ func (or *orchestrator) GetDataByID(ctx context.Context, ns, id string) (*core.D
 }
 
 func (or *orchestrator) GetDatatypeByID(ctx context.Context, ns, id string) (*core.Datatype, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -158,6 +166,7 @@ func (or *orchestrator) GetDatatypeByID(ctx context.Context, ns, id string) (*co
 }
 
 func (or *orchestrator) GetDatatypeByName(ctx context.Context, ns, name, version string) (*core.Datatype, error) {
+	ctx = database.WithReadReplica(ctx)
 	if err := core.ValidateFFNameField(ctx, ns, "namespace"); err != nil {
 		return nil, err
 	}
@@ -172,6 +181,7 @@ func (or *orchestrator) GetDatatypeByName(ctx context.Context, ns, name, version
 }
 
 func (or *orchestrator) GetOperationByID(ctx context.Context, ns, id string) (*core.Operation, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -184,6 +194,7 @@ func (or *orchestrator) GetOperationByID(ctx context.Context, ns, id string) (*c
 }
 
 func (or *orchestrator) GetOperationByNamespacedID(ctx context.Context, nsOpID string) (*core.Operation, error) {
+	ctx = database.WithReadReplica(ctx)
 	ns, u, err := core.ParseNamespacedOpID(ctx, nsOpID)
 	if err != nil {
 		return nil, err
@@ -196,6 +207,7 @@ func (or *orchestrator) GetOperationByNamespacedID(ctx context.Context, nsOpID s
 }
 
 func (or *orchestrator) GetEventByID(ctx context.Context, ns, id string) (*core.Event, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -212,16 +224,19 @@ func (or *orchestrator) scopeNS(ns string, filter database.AndFilter) database.A
 }
 
 func (or *orchestrator) GetTransactions(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Transaction, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetTransactions(ctx, filter)
 }
 
 func (or *orchestrator) GetMessages(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Message, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetMessages(ctx, filter)
 }
 
 func (or *orchestrator) GetMessagesWithData(ctx context.Context, ns string, filter database.AndFilter) ([]*core.MessageInOut, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	msgs, fr, err := or.database().GetMessages(ctx, filter)
 	if err != nil {
@@ -237,6 +252,7 @@ func (or *orchestrator) GetMessagesWithData(ctx context.Context, ns string, filt
 }
 
 func (or *orchestrator) GetMessageData(ctx context.Context, ns, id string) (core.DataArray, error) {
+	ctx = database.WithReadReplica(ctx)
 	msg, err := or.getMessageByID(ctx, ns, id)
 	if err != nil || msg == nil {
 		return nil, err
@@ -273,6 +289,7 @@ func (or *orchestrator) getMessageTransactionID(ctx context.Context, ns, id stri
 }
 
 func (or *orchestrator) GetMessageTransaction(ctx context.Context, ns, id string) (*core.Transaction, error) {
+	ctx = database.WithReadReplica(ctx)
 	txID, err := or.getMessageTransactionID(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -281,6 +298,7 @@ func (or *orchestrator) GetMessageTransaction(ctx context.Context, ns, id string
 }
 
 func (or *orchestrator) GetMessageOperations(ctx context.Context, ns, id string) ([]*core.Operation, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	txID, err := or.getMessageTransactionID(ctx, ns, id)
 	if err != nil {
 		return nil, nil, err
@@ -290,6 +308,7 @@ func (or *orchestrator) GetMessageOperations(ctx context.Context, ns, id string)
 }
 
 func (or *orchestrator) GetMessageEvents(ctx context.Context, ns, id string, filter database.AndFilter) ([]*core.Event, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	msg, err := or.getMessageByID(ctx, ns, id)
 	if err != nil || msg == nil {
 		return nil, nil, err
@@ -307,16 +326,19 @@ func (or *orchestrator) GetMessageEvents(ctx context.Context, ns, id string, fil
 }
 
 func (or *orchestrator) GetBatches(ctx context.Context, ns string, filter database.AndFilter) ([]*core.BatchPersisted, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetBatches(ctx, filter)
 }
 
 func (or *orchestrator) GetData(ctx context.Context, ns string, filter database.AndFilter) (core.DataArray, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetData(ctx, filter)
 }
 
 func (or *orchestrator) GetMessagesForData(ctx context.Context, ns, dataID string, filter database.AndFilter) ([]*core.Message, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	u, err := or.verifyIDAndNamespace(ctx, ns, dataID)
 	if err != nil {
@@ -326,25 +348,30 @@ func (or *orchestrator) GetMessagesForData(ctx context.Context, ns, dataID strin
 }
 
 func (or *orchestrator) GetDatatypes(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Datatype, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetDatatypes(ctx, filter)
 }
 
 func (or *orchestrator) GetOperationsNamespaced(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Operation, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetOperations(ctx, filter)
 }
 
 func (or *orchestrator) GetOperations(ctx context.Context, filter database.AndFilter) ([]*core.Operation, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	return or.database().GetOperations(ctx, filter)
 }
 
 func (or *orchestrator) GetEvents(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Event, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetEvents(ctx, filter)
 }
 
 func (or *orchestrator) GetBlockchainEventByID(ctx context.Context, ns, id string) (*core.BlockchainEvent, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
@@ -357,10 +384,12 @@ func (or *orchestrator) GetBlockchainEventByID(ctx context.Context, ns, id strin
 }
 
 func (or *orchestrator) GetBlockchainEvents(ctx context.Context, ns string, filter database.AndFilter) ([]*core.BlockchainEvent, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	return or.database().GetBlockchainEvents(ctx, or.scopeNS(ns, filter))
 }
 
 func (or *orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns, id string) ([]*core.BlockchainEvent, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, nil, err
@@ -374,11 +403,13 @@ func (or *orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns,
 }
 
 func (or *orchestrator) GetPins(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Pin, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	return or.database().GetPins(ctx, filter)
 }
 
 func (or *orchestrator) GetEventsWithReferences(ctx context.Context, ns string, filter database.AndFilter) ([]*core.EnrichedEvent, *database.FilterResult, error) {
+	ctx = database.WithReadReplica(ctx)
 	filter = or.scopeNS(ns, filter)
 	events, fr, err := or.database().GetEvents(ctx, filter)
 	if err != nil {
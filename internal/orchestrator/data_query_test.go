@@ -181,6 +181,15 @@ func TestGetMessages(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetMessagesReadReplica(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.MatchedBy(database.IsReadReplica), mock.Anything).Return([]*core.Message{}, nil, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetMessages(context.Background(), "ns1", fb.And())
+	assert.NoError(t, err)
+	or.mdi.AssertExpectations(t)
+}
+
 func TestGetMessagesWithDataFailMsg(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
@@ -523,7 +532,7 @@ func TestGetDatatypeByIDBadID(t *testing.T) {
 
 func TestGetDatatypeByName(t *testing.T) {
 	or := newTestOrchestrator()
-	or.mdi.On("GetDatatypeByName", context.Background(), "ns1", "dt", "1").Return(&core.Datatype{
+	or.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "dt", "1").Return(&core.Datatype{
 		Namespace: "ns1",
 	}, nil)
 	_, err := or.GetDatatypeByName(context.Background(), "ns1", "dt", "1")
@@ -723,7 +732,7 @@ func TestGetBlockchainEventByID(t *testing.T) {
 	or := newTestOrchestrator()
 
 	id := fftypes.NewUUID()
-	or.mdi.On("GetBlockchainEventByID", context.Background(), id).Return(&core.BlockchainEvent{
+	or.mdi.On("GetBlockchainEventByID", mock.Anything, id).Return(&core.BlockchainEvent{
 		Namespace: "ns1",
 	}, nil)
 
@@ -740,7 +749,7 @@ func TestGetBlockchainEventByIDBadID(t *testing.T) {
 func TestGetBlockchainEvents(t *testing.T) {
 	or := newTestOrchestrator()
 
-	or.mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return(nil, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(nil, nil, nil)
 
 	f := database.ContractListenerQueryFactory.NewFilter(context.Background())
 	_, _, err := or.GetBlockchainEvents(context.Background(), "ns", f.And())
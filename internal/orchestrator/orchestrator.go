@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/assets"
+	"github.com/hyperledger/firefly/internal/backup"
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/batchpin"
 	"github.com/hyperledger/firefly/internal/broadcast"
@@ -36,6 +37,7 @@ import (
 	"github.com/hyperledger/firefly/internal/networkmap"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/retention"
 	"github.com/hyperledger/firefly/internal/shareddownload"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -54,6 +56,7 @@ type Orchestrator interface {
 	Start() error
 	WaitStop() // The close itself is performed by canceling the context
 	Assets() assets.Manager
+	Backup() backup.Manager
 	BatchManager() batch.Manager
 	Broadcast() broadcast.Manager
 	Contracts() contracts.Manager
@@ -69,9 +72,13 @@ type Orchestrator interface {
 	// Subscription management
 	GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Subscription, *database.FilterResult, error)
 	GetSubscriptionByID(ctx context.Context, ns, id string) (*core.Subscription, error)
+	GetSubscriptionByIDWithStatus(ctx context.Context, ns, id string) (*core.SubscriptionWithStatus, error)
 	CreateSubscription(ctx context.Context, ns string, subDef *core.Subscription) (*core.Subscription, error)
 	CreateUpdateSubscription(ctx context.Context, ns string, subDef *core.Subscription) (*core.Subscription, error)
 	DeleteSubscription(ctx context.Context, ns, id string) error
+	ResetSubscription(ctx context.Context, ns, id string, reset *core.SubscriptionReset) (*core.SubscriptionWithStatus, error)
+	GetDeadLetters(ctx context.Context, ns string, filter database.AndFilter) ([]*core.DeadLetter, *database.FilterResult, error)
+	GetDeadLetterByID(ctx context.Context, ns, id string) (*core.DeadLetter, error)
 
 	// Data Query
 	GetNamespace(ctx context.Context, ns string) (*core.Namespace, error)
@@ -108,7 +115,11 @@ type Orchestrator interface {
 	GetPins(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Pin, *database.FilterResult, error)
 
 	// Charts
-	GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error)
+	GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName, groupBy string) ([]*core.ChartHistogram, error)
+	GetAggregate(ctx context.Context, ns string, collection database.CollectionName, query *core.AggregateQuery, filter database.AndFilter) ([]*core.AggregateResult, error)
+
+	// Search
+	Search(ctx context.Context, ns, text string, types []core.SearchHitType, limit int) ([]*core.SearchHit, error)
 
 	// Message Routing
 	RequestReply(ctx context.Context, ns string, msg *core.MessageInOut) (reply *core.MessageInOut, err error)
@@ -158,6 +169,7 @@ type Plugins struct {
 
 type Config struct {
 	DefaultKey string
+	Retention  retention.Config
 	Multiparty struct {
 		Enabled bool
 		OrgName string
@@ -190,6 +202,8 @@ type orchestrator struct {
 	metrics        metrics.Manager
 	operations     operations.Manager
 	sharedDownload shareddownload.Manager
+	retention      retention.Manager
+	backup         backup.Manager
 	txHelper       txcommon.Helper
 }
 
@@ -279,6 +293,9 @@ func (or *orchestrator) Start() (err error) {
 	if err == nil {
 		err = or.sharedDownload.Start()
 	}
+	if err == nil && or.retention != nil {
+		err = or.retention.Start()
+	}
 	if err == nil {
 		for _, el := range or.tokens() {
 			if err = el.Start(); err != nil {
@@ -314,6 +331,10 @@ func (or *orchestrator) WaitStop() {
 		or.operations.WaitStop()
 		or.operations = nil
 	}
+	if or.retention != nil {
+		or.retention.WaitStop()
+		or.retention = nil
+	}
 	or.started = false
 }
 
@@ -345,6 +366,10 @@ func (or *orchestrator) Assets() assets.Manager {
 	return or.assets
 }
 
+func (or *orchestrator) Backup() backup.Manager {
+	return or.backup
+}
+
 func (or *orchestrator) Contracts() contracts.Manager {
 	return or.contracts
 }
@@ -470,6 +495,18 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 
 	or.syncasync.Init(or.events)
 
+	if or.retention == nil && or.config.Retention.Enabled() {
+		if or.retention, err = retention.NewRetentionManager(ctx, or.namespace, or.database(), or.metrics, &or.config.Retention); err != nil {
+			return err
+		}
+	}
+
+	if or.backup == nil {
+		if or.backup, err = backup.NewBackupManager(ctx, or.namespace, or.database()); err != nil {
+			return err
+		}
+	}
+
 	if or.networkmap == nil {
 		or.networkmap, err = networkmap.NewNetworkMap(ctx, or.config.Multiparty.OrgName, or.config.Multiparty.OrgDesc, or.database(), or.data, or.broadcast, or.dataexchange(), or.identity, or.syncasync)
 	}
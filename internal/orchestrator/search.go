@@ -0,0 +1,73 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+func (or *orchestrator) Search(ctx context.Context, ns, text string, types []core.SearchHitType, limit int) ([]*core.SearchHit, error) {
+	ctx = database.WithReadReplica(ctx)
+	if strings.TrimSpace(text) == "" {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSearchTextMissing)
+	}
+	hits, err := or.database().Search(ctx, ns, text, types, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*core.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		found, err := or.loadSearchHit(ctx, hit)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			results = append(results, hit)
+		}
+	}
+	return results, nil
+}
+
+// loadSearchHit sets the record on a hit, returning false if the record no longer exists
+func (or *orchestrator) loadSearchHit(ctx context.Context, hit *core.SearchHit) (found bool, err error) {
+	switch hit.Type {
+	case core.SearchHitTypeMessage:
+		hit.Message, err = or.database().GetMessageByID(ctx, hit.ID)
+		return hit.Message != nil, err
+	case core.SearchHitTypeData:
+		hit.Data, err = or.database().GetDataByID(ctx, hit.ID, true)
+		return hit.Data != nil, err
+	case core.SearchHitTypeIdentity:
+		hit.Identity, err = or.database().GetIdentityByID(ctx, hit.ID)
+		return hit.Identity != nil, err
+	case core.SearchHitTypeTokenPool:
+		hit.TokenPool, err = or.database().GetTokenPoolByID(ctx, hit.ID)
+		return hit.TokenPool != nil, err
+	case core.SearchHitTypeFFI:
+		hit.FFI, err = or.database().GetFFIByID(ctx, hit.ID)
+		return hit.FFI != nil, err
+	default:
+		return false, nil
+	}
+}
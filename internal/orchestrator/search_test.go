@@ -0,0 +1,88 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearch(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	hits := []*core.SearchHit{
+		{Type: core.SearchHitTypeMessage, ID: fftypes.NewUUID(), Rank: 5},
+		{Type: core.SearchHitTypeData, ID: fftypes.NewUUID(), Rank: 4},
+		{Type: core.SearchHitTypeIdentity, ID: fftypes.NewUUID(), Rank: 3},
+		{Type: core.SearchHitTypeTokenPool, ID: fftypes.NewUUID(), Rank: 2},
+		{Type: core.SearchHitTypeFFI, ID: fftypes.NewUUID(), Rank: 1},
+		{Type: core.SearchHitTypeMessage, ID: fftypes.NewUUID(), Rank: 0.5},
+		{Type: "unknown", ID: fftypes.NewUUID(), Rank: 0.1},
+	}
+	types := []core.SearchHitType{core.SearchHitTypeMessage}
+	or.mdi.On("Search", mock.MatchedBy(database.IsReadReplica), "ns", "acme", types, 10).Return(hits, nil)
+	or.mdi.On("GetMessageByID", mock.Anything, hits[0].ID).Return(&core.Message{}, nil)
+	or.mdi.On("GetDataByID", mock.Anything, hits[1].ID, true).Return(&core.Data{}, nil)
+	or.mdi.On("GetIdentityByID", mock.Anything, hits[2].ID).Return(&core.Identity{}, nil)
+	or.mdi.On("GetTokenPoolByID", mock.Anything, hits[3].ID).Return(&core.TokenPool{}, nil)
+	or.mdi.On("GetFFIByID", mock.Anything, hits[4].ID).Return(&core.FFI{}, nil)
+	or.mdi.On("GetMessageByID", mock.Anything, hits[5].ID).Return(nil, nil) // pruned since it was indexed
+
+	results, err := or.Search(or.ctx, "ns", "acme", types, 10)
+	assert.NoError(t, err)
+	assert.Len(t, results, 5)
+	assert.NotNil(t, results[0].Message)
+	assert.NotNil(t, results[1].Data)
+	assert.NotNil(t, results[2].Identity)
+	assert.NotNil(t, results[3].TokenPool)
+	assert.NotNil(t, results[4].FFI)
+}
+
+func TestSearchMissingText(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	_, err := or.Search(or.ctx, "ns", " ", nil, 10)
+	assert.Regexp(t, "FF10459", err)
+}
+
+func TestSearchFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mdi.On("Search", mock.Anything, "ns", "acme", []core.SearchHitType(nil), 10).Return(nil, fmt.Errorf("pop"))
+	_, err := or.Search(or.ctx, "ns", "acme", nil, 10)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSearchLoadHitFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	hit := &core.SearchHit{Type: core.SearchHitTypeData, ID: fftypes.NewUUID()}
+	or.mdi.On("Search", mock.Anything, "ns", "acme", []core.SearchHitType(nil), 10).Return([]*core.SearchHit{hit}, nil)
+	or.mdi.On("GetDataByID", mock.Anything, hit.ID, true).Return(nil, fmt.Errorf("pop"))
+	_, err := or.Search(or.ctx, "ns", "acme", nil, 10)
+	assert.EqualError(t, err, "pop")
+}
@@ -50,7 +50,10 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, ns string,
 		return nil, i18n.NewError(ctx, coremsgs.MsgSystemTransportInternal)
 	}
 
-	return subDef, or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew)
+	if err := or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew); err != nil {
+		return nil, err
+	}
+	return subDef.Redacted(), nil
 }
 
 func (or *orchestrator) DeleteSubscription(ctx context.Context, ns, id string) error {
@@ -70,7 +73,11 @@ func (or *orchestrator) DeleteSubscription(ctx context.Context, ns, id string) e
 
 func (or *orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Subscription, *database.FilterResult, error) {
 	filter = or.scopeNS(ns, filter)
-	return or.database().GetSubscriptions(ctx, filter)
+	subs, fr, err := or.database().GetSubscriptions(ctx, filter)
+	for i, sub := range subs {
+		subs[i] = sub.Redacted()
+	}
+	return subs, fr, err
 }
 
 func (or *orchestrator) GetSubscriptionByID(ctx context.Context, ns, id string) (*core.Subscription, error) {
@@ -78,5 +85,62 @@ func (or *orchestrator) GetSubscriptionByID(ctx context.Context, ns, id string)
 	if err != nil {
 		return nil, err
 	}
-	return or.database().GetSubscriptionByID(ctx, u)
+	sub, err := or.database().GetSubscriptionByID(ctx, u)
+	return sub.Redacted(), err
+}
+
+func (or *orchestrator) GetSubscriptionByIDWithStatus(ctx context.Context, ns, id string) (*core.SubscriptionWithStatus, error) {
+	sub, err := or.GetSubscriptionByID(ctx, ns, id)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	if err := or.checkNamespace(ctx, ns, sub.Namespace); err != nil {
+		return nil, err
+	}
+	return or.subscriptionWithStatus(ctx, sub)
+}
+
+func (or *orchestrator) ResetSubscription(ctx context.Context, ns, id string, reset *core.SubscriptionReset) (*core.SubscriptionWithStatus, error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := or.database().GetSubscriptionByID(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.Namespace != ns {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	if err := or.events.ResetDurableSubscription(ctx, sub, reset); err != nil {
+		return nil, err
+	}
+	return or.subscriptionWithStatus(ctx, sub)
+}
+
+func (or *orchestrator) subscriptionWithStatus(ctx context.Context, sub *core.Subscription) (*core.SubscriptionWithStatus, error) {
+	status, err := or.events.GetSubscriptionStatus(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	return &core.SubscriptionWithStatus{
+		Subscription: *sub.Redacted(),
+		Status:       status,
+	}, nil
+}
+
+func (or *orchestrator) GetDeadLetters(ctx context.Context, ns string, filter database.AndFilter) ([]*core.DeadLetter, *database.FilterResult, error) {
+	return or.database().GetDeadLetters(ctx, or.scopeNS(ns, filter))
+}
+
+func (or *orchestrator) GetDeadLetterByID(ctx context.Context, ns, id string) (*core.DeadLetter, error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	dl, err := or.database().GetDeadLetterByID(ctx, u)
+	if err == nil && dl != nil {
+		err = or.checkNamespace(ctx, ns, dl.Namespace)
+	}
+	return dl, err
 }
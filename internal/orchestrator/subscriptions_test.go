@@ -145,6 +145,17 @@ func TestGetSubscriptions(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetSubscriptionsRedactsSecret(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &core.Subscription{}
+	sub.Options.TransportOptions()["secret"] = "shh"
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	fb := database.SubscriptionQueryFactory.NewFilter(context.Background())
+	subs, _, err := or.GetSubscriptions(context.Background(), "ns1", fb.And())
+	assert.NoError(t, err)
+	assert.Equal(t, core.WebhookSecretRedacted, subs[0].Options.TransportOptions().GetString("secret"))
+}
+
 func TestGetSGetSubscriptionsByID(t *testing.T) {
 	or := newTestOrchestrator()
 	u := fftypes.NewUUID()
@@ -158,3 +169,155 @@ func TestGetSubscriptionDefsByIDBadID(t *testing.T) {
 	_, err := or.GetSubscriptionByID(context.Background(), "", "")
 	assert.Regexp(t, "FF00138", err)
 }
+
+func TestGetSubscriptionByIDWithStatus(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("GetSubscriptionStatus", mock.Anything, sub).Return(&core.SubscriptionStatus{
+		CurrentOffset: 10,
+		Lag:           5,
+	}, nil)
+	res, err := or.GetSubscriptionByIDWithStatus(context.Background(), "ns1", sub.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, *sub.ID, *res.ID)
+	assert.Equal(t, int64(10), res.Status.CurrentOffset)
+	assert.Equal(t, int64(5), res.Status.Lag)
+}
+
+func TestGetSubscriptionByIDWithStatusNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, u).Return(nil, nil)
+	res, err := or.GetSubscriptionByIDWithStatus(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestGetSubscriptionByIDWithStatusWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns2",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	_, err := or.GetSubscriptionByIDWithStatus(context.Background(), "ns1", sub.ID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetSubscriptionByIDWithStatusFail(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("GetSubscriptionStatus", mock.Anything, sub).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetSubscriptionByIDWithStatus(context.Background(), "ns1", sub.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResetSubscription(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	oldest := core.SubOptsFirstEventOldest
+	reset := &core.SubscriptionReset{FirstEvent: &oldest}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("ResetDurableSubscription", mock.Anything, sub, reset).Return(nil)
+	or.mem.On("GetSubscriptionStatus", mock.Anything, sub).Return(&core.SubscriptionStatus{
+		CurrentOffset: -1,
+		Lag:           100,
+	}, nil)
+	res, err := or.ResetSubscription(context.Background(), "ns1", sub.ID.String(), reset)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), res.Status.CurrentOffset)
+	assert.Equal(t, int64(100), res.Status.Lag)
+}
+
+func TestResetSubscriptionBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.ResetSubscription(context.Background(), "ns1", "! a UUID", &core.SubscriptionReset{})
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestResetSubscriptionLookupError(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.ResetSubscription(context.Background(), "ns1", fftypes.NewUUID().String(), &core.SubscriptionReset{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResetSubscriptionNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, err := or.ResetSubscription(context.Background(), "ns1", fftypes.NewUUID().String(), &core.SubscriptionReset{})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestResetSubscriptionFail(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("ResetDurableSubscription", mock.Anything, sub, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.ResetSubscription(context.Background(), "ns1", sub.ID.String(), &core.SubscriptionReset{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetDeadLetters", mock.Anything, mock.Anything).Return([]*core.DeadLetter{}, nil, nil)
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("transport", "webhooks"))
+	_, _, err := or.GetDeadLetters(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetDeadLetterByID(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetDeadLetterByID", mock.Anything, u).Return(&core.DeadLetter{
+		Namespace: "ns1",
+	}, nil)
+	_, err := or.GetDeadLetterByID(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+}
+
+func TestGetDeadLetterByIDWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetDeadLetterByID", mock.Anything, u).Return(&core.DeadLetter{
+		Namespace: "ns2",
+	}, nil)
+	_, err := or.GetDeadLetterByID(context.Background(), "ns1", u.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetDeadLetterByIDBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetDeadLetterByID(context.Background(), "ns1", "")
+	assert.Regexp(t, "FF00138", err)
+}
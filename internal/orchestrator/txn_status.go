@@ -187,7 +187,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 			})
 		}
 
-	case core.TransactionTypeContractInvoke:
+	case core.TransactionTypeContractInvoke, core.TransactionTypeContractDeploy:
 		// no blockchain events or other objects
 
 	default:
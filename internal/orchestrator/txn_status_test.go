@@ -787,6 +787,52 @@ func TestGetTransactionStatusContractInvokeSuccess(t *testing.T) {
 	or.mdi.AssertExpectations(t)
 }
 
+func TestGetTransactionStatusContractDeploy(t *testing.T) {
+	or := newTestOrchestrator()
+
+	txID := fftypes.NewUUID()
+	tx := &core.Transaction{
+		Namespace: "ns1",
+		Type:      core.TransactionTypeContractDeploy,
+	}
+	ops := []*core.Operation{
+		{
+			Namespace: "ns1",
+			Status:    core.OpStatusSucceeded,
+			ID:        fftypes.NewUUID(),
+			Type:      core.OpTypeBlockchainContractDeploy,
+			Updated:   fftypes.UnixTime(0),
+			Output:    fftypes.JSONObject{"contractLocation": map[string]interface{}{"address": "0x123"}},
+		},
+	}
+	events := []*core.BlockchainEvent{}
+
+	or.mdi.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(ops, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(events, nil, nil)
+
+	status, err := or.GetTransactionStatus(context.Background(), "ns1", txID.String())
+	assert.NoError(t, err)
+
+	expectedStatus := compactJSON(`{
+		"status": "Succeeded",
+		"details": [
+			{
+				"type": "Operation",
+				"subtype": "blockchain_deploy",
+				"status": "Succeeded",
+				"timestamp": "1970-01-01T00:00:00Z",
+				"id": "` + ops[0].ID.String() + `",
+				"info": {"contractLocation": {"address": "0x123"}}
+			}
+		]
+	}`)
+	statusJSON, _ := json.Marshal(status)
+	assert.Equal(t, expectedStatus, string(statusJSON))
+
+	or.mdi.AssertExpectations(t)
+}
+
 func TestGetTransactionStatusTXError(t *testing.T) {
 	or := newTestOrchestrator()
 
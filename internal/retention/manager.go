@@ -0,0 +1,237 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// Policy is the retention policy for a single collection - rows are pruned once they are older than
+// MaxAge, or once there are more than MaxRows newer rows in the namespace (whichever comes first)
+type Policy struct {
+	Collection database.CollectionName
+	MaxAge     time.Duration
+	MaxRows    int64
+}
+
+type Config struct {
+	Interval  time.Duration
+	BatchSize int
+	Policies  []*Policy
+}
+
+// Enabled returns true if any collection has a retention policy configured
+func (c *Config) Enabled() bool {
+	for _, p := range c.Policies {
+		if p.MaxAge > 0 || p.MaxRows > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type Manager interface {
+	Start() error
+	WaitStop()
+}
+
+// retentionManager periodically prunes old rows from the database for a single namespace.
+// Pruning never removes events that have not been acknowledged by every subscription, and never
+// removes any row created after the oldest event or pin that is still waiting to be processed.
+type retentionManager struct {
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+	namespace string
+	database  database.Plugin
+	metrics   metrics.Manager
+	conf      Config
+	done      chan struct{}
+}
+
+func NewRetentionManager(ctx context.Context, ns string, di database.Plugin, mm metrics.Manager, conf *Config) (Manager, error) {
+	if di == nil || mm == nil || conf == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "RetentionManager")
+	}
+	rm := &retentionManager{
+		namespace: ns,
+		database:  di,
+		metrics:   mm,
+		conf:      *conf,
+	}
+	if rm.conf.BatchSize <= 0 {
+		rm.conf.BatchSize = 1
+	}
+	rm.ctx, rm.cancelCtx = context.WithCancel(log.WithLogField(ctx, "role", "retention"))
+	return rm, nil
+}
+
+func (rm *retentionManager) Start() error {
+	rm.done = make(chan struct{})
+	go rm.pruneLoop()
+	return nil
+}
+
+func (rm *retentionManager) WaitStop() {
+	rm.cancelCtx()
+	if rm.done != nil {
+		<-rm.done
+	}
+}
+
+func (rm *retentionManager) pruneLoop() {
+	defer close(rm.done)
+	l := log.L(rm.ctx)
+	for {
+		if err := rm.pruneAll(); err != nil {
+			// We will try again on the next interval
+			l.Errorf("Retention pruning failed: %s", err)
+		}
+		timer := time.NewTimer(rm.conf.Interval)
+		select {
+		case <-timer.C:
+		case <-rm.ctx.Done():
+			timer.Stop()
+			l.Debugf("Retention loop exiting")
+			return
+		}
+	}
+}
+
+func (rm *retentionManager) pruneAll() error {
+	maxEventSequence, notAfter, err := rm.getSafetyBounds()
+	if err != nil {
+		return err
+	}
+	for _, policy := range rm.conf.Policies {
+		if policy.MaxAge <= 0 && policy.MaxRows <= 0 {
+			continue
+		}
+		criteria := &database.PruneCriteria{
+			MaxRows:     policy.MaxRows,
+			MaxSequence: -1,
+			NotAfter:    notAfter,
+			Limit:       rm.conf.BatchSize,
+		}
+		if policy.MaxAge > 0 {
+			before := fftypes.FFTime(time.Now().Add(-policy.MaxAge))
+			criteria.Before = &before
+		}
+		if policy.Collection == database.CollectionName(database.CollectionEvents) {
+			criteria.MaxSequence = maxEventSequence
+		}
+		if err := rm.pruneCollection(policy.Collection, criteria); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rm *retentionManager) pruneCollection(collection database.CollectionName, criteria *database.PruneCriteria) error {
+	var total int64
+	defer func() {
+		if total > 0 {
+			log.L(rm.ctx).Infof("Pruned %d rows from %s", total, collection)
+			if rm.metrics.IsMetricsEnabled() {
+				rm.metrics.RetentionRowsPruned(rm.namespace, string(collection), total)
+			}
+		}
+	}()
+	for {
+		count, err := rm.database.PruneCollection(rm.ctx, rm.namespace, collection, criteria)
+		if err != nil {
+			return err
+		}
+		total += count
+		if count < int64(criteria.Limit) {
+			return nil
+		}
+	}
+}
+
+// getSafetyBounds returns the highest event sequence that every subscription has acknowledged (or -1 if
+// there are no subscriptions), and the creation time of the oldest event or pin that is still waiting
+// to be processed (or nil if there is nothing outstanding)
+func (rm *retentionManager) getSafetyBounds() (maxEventSequence int64, notAfter *fftypes.FFTime, err error) {
+	maxEventSequence = -1
+	fb := database.SubscriptionQueryFactory.NewFilter(rm.ctx)
+	subs, _, err := rm.database.GetSubscriptions(rm.ctx, fb.Eq("namespace", rm.namespace))
+	if err != nil {
+		return -1, nil, err
+	}
+	for _, sub := range subs {
+		offset, err := rm.database.GetOffset(rm.ctx, core.OffsetTypeSubscription, sub.ID.String())
+		if err != nil {
+			return -1, nil, err
+		}
+		// A subscription that has not yet stored an offset might still need every event
+		current := int64(0)
+		if offset != nil {
+			current = offset.Current
+		}
+		if maxEventSequence < 0 || current < maxEventSequence {
+			maxEventSequence = current
+		}
+	}
+
+	if len(subs) > 0 {
+		efb := database.EventQueryFactory.NewFilter(rm.ctx)
+		pendingEvents, _, err := rm.database.GetEvents(rm.ctx, efb.And(
+			efb.Eq("namespace", rm.namespace),
+			efb.Gt("sequence", maxEventSequence),
+		).Sort("sequence").Limit(1))
+		if err != nil {
+			return -1, nil, err
+		}
+		if len(pendingEvents) > 0 {
+			notAfter = pendingEvents[0].Created
+		}
+	}
+
+	aggregatorOffset, err := rm.database.GetOffset(rm.ctx, core.OffsetTypeAggregator, events.AggregatorOffsetName)
+	if err != nil {
+		return -1, nil, err
+	}
+	pinSequence := int64(-1)
+	if aggregatorOffset != nil {
+		pinSequence = aggregatorOffset.Current
+	}
+	pfb := database.PinQueryFactory.NewFilter(rm.ctx)
+	pins, _, err := rm.database.GetPins(rm.ctx, pfb.And(
+		pfb.Eq("namespace", rm.namespace),
+		pfb.Gt("sequence", pinSequence),
+	).Sort("sequence").Limit(1))
+	if err != nil {
+		return -1, nil, err
+	}
+	if len(pins) > 0 && pins[0].Created != nil {
+		if notAfter == nil || pins[0].Created.Time().Before(*notAfter.Time()) {
+			notAfter = pins[0].Created
+		}
+	}
+
+	return maxEventSequence, notAfter, nil
+}
@@ -0,0 +1,229 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRetentionManager(t *testing.T, conf *Config) (*retentionManager, func()) {
+	mdi := &databasemocks.Plugin{}
+	mmi := &metricsmocks.Manager{}
+	rm, err := NewRetentionManager(context.Background(), "ns1", mdi, mmi, conf)
+	assert.NoError(t, err)
+	return rm.(*retentionManager), func() {
+		mdi.AssertExpectations(t)
+		mmi.AssertExpectations(t)
+	}
+}
+
+func testConfig() *Config {
+	return &Config{
+		Interval:  1 * time.Hour,
+		BatchSize: 2,
+		Policies: []*Policy{
+			{Collection: database.CollectionName(database.CollectionEvents), MaxRows: 10},
+			{Collection: database.CollectionName(database.CollectionOperations), MaxAge: 24 * time.Hour},
+			{Collection: database.CollectionName(database.CollectionMessages)},
+		},
+	}
+}
+
+func TestNewRetentionManagerMissingDeps(t *testing.T) {
+	_, err := NewRetentionManager(context.Background(), "ns1", nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestConfigEnabled(t *testing.T) {
+	assert.True(t, testConfig().Enabled())
+	assert.False(t, (&Config{Policies: []*Policy{{Collection: "events"}}}).Enabled())
+}
+
+func TestPruneLoopOK(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+	mmi := rm.metrics.(*metricsmocks.Manager)
+
+	sub1 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	sub2 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	eventCreated := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	pinCreated := fftypes.FFTime(time.Now().Add(-2 * time.Hour))
+	pruned := make(chan struct{})
+
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{sub1, sub2}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub1.ID.String()).Return(&core.Offset{Current: 20}, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub2.ID.String()).Return(&core.Offset{Current: 15}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{{Sequence: 16, Created: &eventCreated}}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, events.AggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*core.Pin{{Sequence: 101, Created: &pinCreated}}, nil, nil)
+
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionEvents), mock.MatchedBy(func(c *database.PruneCriteria) bool {
+		return c.MaxRows == 10 && c.Before == nil && c.MaxSequence == 15 && c.NotAfter.Equal(&pinCreated) && c.Limit == 2
+	})).Return(int64(2), nil).Once()
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionEvents), mock.Anything).Return(int64(1), nil).Once()
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), mock.MatchedBy(func(c *database.PruneCriteria) bool {
+		return c.MaxRows == 0 && c.Before != nil && c.MaxSequence == -1 && c.NotAfter.Equal(&pinCreated)
+	})).Return(int64(0), nil).Once().Run(func(args mock.Arguments) {
+		close(pruned)
+	})
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("RetentionRowsPruned", "ns1", "events", int64(3)).Return()
+
+	err := rm.Start()
+	assert.NoError(t, err)
+	<-pruned
+	rm.WaitStop()
+}
+
+func TestPruneLoopRetryAfterError(t *testing.T) {
+	conf := testConfig()
+	conf.Interval = 1 * time.Millisecond
+	rm, done := newTestRetentionManager(t, conf)
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	errCount := 0
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		errCount++
+		if errCount == 2 {
+			rm.cancelCtx()
+		}
+	})
+
+	err := rm.Start()
+	assert.NoError(t, err)
+	<-rm.done
+	rm.WaitStop()
+}
+
+func TestPruneAllNoSubscriptions(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+	mmi := rm.metrics.(*metricsmocks.Manager)
+
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, events.AggregatorOffsetName).Return(nil, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*core.Pin{}, nil, nil)
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionEvents), mock.MatchedBy(func(c *database.PruneCriteria) bool {
+		return c.MaxSequence == -1 && c.NotAfter == nil
+	})).Return(int64(1), nil)
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), mock.Anything).Return(int64(0), nil)
+	mmi.On("IsMetricsEnabled").Return(false)
+
+	err := rm.pruneAll()
+	assert.NoError(t, err)
+}
+
+func TestPruneAllNoOffsetYet(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	sub1 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{sub1}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub1.ID.String()).Return(nil, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, events.AggregatorOffsetName).Return(nil, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*core.Pin{}, nil, nil)
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionEvents), mock.MatchedBy(func(c *database.PruneCriteria) bool {
+		return c.MaxSequence == 0
+	})).Return(int64(0), nil)
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), mock.Anything).Return(int64(0), nil)
+
+	err := rm.pruneAll()
+	assert.NoError(t, err)
+}
+
+func TestPruneAllPruneFail(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, events.AggregatorOffsetName).Return(nil, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*core.Pin{}, nil, nil)
+	mdi.On("PruneCollection", mock.Anything, "ns1", database.CollectionName(database.CollectionEvents), mock.Anything).Return(int64(0), fmt.Errorf("pop"))
+
+	err := rm.pruneAll()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSafetyBoundsSubOffsetFail(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	sub1 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{sub1}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub1.ID.String()).Return(nil, fmt.Errorf("pop"))
+
+	_, _, err := rm.getSafetyBounds()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSafetyBoundsEventsFail(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	sub1 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{sub1}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub1.ID.String()).Return(&core.Offset{Current: 1}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, _, err := rm.getSafetyBounds()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSafetyBoundsAggregatorOffsetFail(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, events.AggregatorOffsetName).Return(nil, fmt.Errorf("pop"))
+
+	_, _, err := rm.getSafetyBounds()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSafetyBoundsPinsFail(t *testing.T) {
+	rm, done := newTestRetentionManager(t, testConfig())
+	defer done()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, events.AggregatorOffsetName).Return(&core.Offset{Current: 1}, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, _, err := rm.getSafetyBounds()
+	assert.EqualError(t, err, "pop")
+}
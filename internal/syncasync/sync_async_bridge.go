@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 )
@@ -56,6 +57,8 @@ type Bridge interface {
 	WaitForTokenApproval(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*core.TokenApproval, error)
 	// WaitForInvokeOperation waits for an operation with the supplied ID
 	WaitForInvokeOperation(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*core.Operation, error)
+	// WaitForDeployOperation waits for a contract deployment operation with the supplied ID
+	WaitForDeployOperation(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*core.Operation, error)
 }
 
 type RequestSender func(ctx context.Context) error
@@ -70,6 +73,7 @@ const (
 	tokenTransferConfirm
 	tokenApproveConfirm
 	invokeOperationConfirm
+	deployOperationConfirm
 )
 
 type inflightRequest struct {
@@ -421,9 +425,9 @@ func (sa *syncAsyncBridge) handleApprovalOpFailedEvent(event *core.EventDelivery
 	return nil
 }
 
-func (sa *syncAsyncBridge) handleOperationSuccededEvent(event *core.EventDelivery) error {
-	// See if this is a failure of an inflight invoke operation
-	inflight := sa.getInFlight(event.Namespace, invokeOperationConfirm, event.Reference)
+func (sa *syncAsyncBridge) handleOperationSuccededEvent(event *core.EventDelivery, reqType requestType, typeName string) error {
+	// See if this is the success of an inflight operation
+	inflight := sa.getInFlight(event.Namespace, reqType, event.Reference)
 	if inflight == nil {
 		return nil
 	}
@@ -433,14 +437,14 @@ func (sa *syncAsyncBridge) handleOperationSuccededEvent(event *core.EventDeliver
 		return err
 	}
 
-	go sa.resolveSuccessfulOperation(inflight, "invoke", op)
+	go sa.resolveSuccessfulOperation(inflight, typeName, op)
 
 	return nil
 }
 
-func (sa *syncAsyncBridge) handleOperationFailedEvent(event *core.EventDelivery) error {
-	// See if this is a failure of an inflight invoke operation
-	inflight := sa.getInFlight(event.Namespace, invokeOperationConfirm, event.Reference)
+func (sa *syncAsyncBridge) handleOperationFailedEvent(event *core.EventDelivery, reqType requestType, typeName string) error {
+	// See if this is a failure of an inflight operation
+	inflight := sa.getInFlight(event.Namespace, reqType, event.Reference)
 	if inflight == nil {
 		return nil
 	}
@@ -450,7 +454,7 @@ func (sa *syncAsyncBridge) handleOperationFailedEvent(event *core.EventDelivery)
 		return err
 	}
 
-	go sa.resolveFailedOperation(inflight, "invoke", op)
+	go sa.resolveFailedOperation(inflight, typeName, op)
 
 	return nil
 }
@@ -494,10 +498,16 @@ func (sa *syncAsyncBridge) eventCallback(event *core.EventDelivery) error {
 		return sa.handleApprovalOpFailedEvent(event)
 
 	case core.EventTypeBlockchainInvokeOpSucceeded:
-		return sa.handleOperationSuccededEvent(event)
+		return sa.handleOperationSuccededEvent(event, invokeOperationConfirm, "invoke")
 
 	case core.EventTypeBlockchainInvokeOpFailed:
-		return sa.handleOperationFailedEvent(event)
+		return sa.handleOperationFailedEvent(event, invokeOperationConfirm, "invoke")
+
+	case core.EventTypeBlockchainContractDeployOpSucceeded:
+		return sa.handleOperationSuccededEvent(event, deployOperationConfirm, "deploy")
+
+	case core.EventTypeBlockchainContractDeployOpFailed:
+		return sa.handleOperationFailedEvent(event, deployOperationConfirm, "deploy")
 	}
 
 	return nil
@@ -560,7 +570,7 @@ func (sa *syncAsyncBridge) resolveSuccessfulOperation(inflight *inflightRequest,
 
 func (sa *syncAsyncBridge) resolveFailedOperation(inflight *inflightRequest, typeName string, op *core.Operation) {
 	log.L(sa.ctx).Debugf("Resolving %s request '%s' with error '%s'", typeName, inflight.id, op.Error)
-	inflight.response <- inflightResponse{err: fmt.Errorf(op.Error)}
+	inflight.response <- inflightResponse{err: blockchain.OperationRevertError(op, fmt.Errorf(op.Error))}
 }
 
 func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftypes.UUID, reqType requestType, send RequestSender) (interface{}, error) {
@@ -648,3 +658,11 @@ func (sa *syncAsyncBridge) WaitForInvokeOperation(ctx context.Context, ns string
 	}
 	return reply.(*core.Operation), err
 }
+
+func (sa *syncAsyncBridge) WaitForDeployOperation(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*core.Operation, error) {
+	reply, err := sa.sendAndWait(ctx, ns, id, deployOperationConfirm, send)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*core.Operation), err
+}
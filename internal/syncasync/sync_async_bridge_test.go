@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -253,6 +254,8 @@ func TestEventCallbackNotInflight(t *testing.T) {
 		core.EventTypeIdentityConfirmed,
 		core.EventTypeBlockchainInvokeOpSucceeded,
 		core.EventTypeBlockchainInvokeOpFailed,
+		core.EventTypeBlockchainContractDeployOpSucceeded,
+		core.EventTypeBlockchainContractDeployOpFailed,
 	} {
 		err := sa.eventCallback(&core.EventDelivery{
 			EnrichedEvent: core.EnrichedEvent{
@@ -1526,6 +1529,49 @@ func TestAwaitInvokeOpFailed(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestAwaitInvokeOpFailedReverted(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	op := &core.Operation{
+		ID:     requestID,
+		Status: core.OpStatusFailed,
+		Error:  "pop",
+		Output: fftypes.JSONObject{
+			"revert": map[string]interface{}{
+				"error":  "InsufficientBalance",
+				"params": map[string]interface{}{"available": "1"},
+			},
+		},
+	}
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", sa.ctx, requestID).Return(op, nil)
+
+	_, err := sa.WaitForInvokeOperation(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+		go func() {
+			sa.eventCallback(&core.EventDelivery{
+				EnrichedEvent: core.EnrichedEvent{
+					Event: core.Event{
+						ID:        fftypes.NewUUID(),
+						Type:      core.EventTypeBlockchainInvokeOpFailed,
+						Reference: requestID,
+						Namespace: "ns1",
+					},
+				},
+			})
+		}()
+		return nil
+	})
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, "InsufficientBalance", blockchain.GetRevert(err).Error)
+}
+
 func TestAwaitInvokeOpFailedLookupFail(t *testing.T) {
 
 	sa, cancel := newTestSyncAsyncBridge(t)
@@ -1558,3 +1604,92 @@ func TestAwaitInvokeOpFailedLookupFail(t *testing.T) {
 	})
 	assert.EqualError(t, err, "pop")
 }
+
+func TestAwaitDeployOpSucceeded(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	op := &core.Operation{
+		ID:     requestID,
+		Status: core.OpStatusSucceeded,
+		Output: fftypes.JSONObject{
+			"contractLocation": map[string]interface{}{"address": "0x12345"},
+		},
+	}
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", sa.ctx, requestID).Return(op, nil)
+
+	ret, err := sa.WaitForDeployOperation(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+		go func() {
+			sa.eventCallback(&core.EventDelivery{
+				EnrichedEvent: core.EnrichedEvent{
+					Event: core.Event{
+						ID:        fftypes.NewUUID(),
+						Type:      core.EventTypeBlockchainContractDeployOpSucceeded,
+						Reference: requestID,
+						Namespace: "ns1",
+					},
+				},
+			})
+		}()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ret, op)
+}
+
+func TestAwaitDeployOpFailed(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	op := &core.Operation{
+		ID:     requestID,
+		Status: core.OpStatusFailed,
+		Error:  "pop",
+	}
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", sa.ctx, requestID).Return(op, nil)
+
+	_, err := sa.WaitForDeployOperation(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+		go func() {
+			sa.eventCallback(&core.EventDelivery{
+				EnrichedEvent: core.EnrichedEvent{
+					Event: core.Event{
+						ID:        fftypes.NewUUID(),
+						Type:      core.EventTypeBlockchainContractDeployOpFailed,
+						Reference: requestID,
+						Namespace: "ns1",
+					},
+				},
+			})
+		}()
+		return nil
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestAwaitDeployOpSendFail(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	_, err := sa.WaitForDeployOperation(sa.ctx, "ns1", fftypes.NewUUID(), func(ctx context.Context) error {
+		return fmt.Errorf("pop")
+	})
+	assert.EqualError(t, err, "pop")
+}
@@ -40,7 +40,7 @@ func (t *transactionHelper) EnrichEvent(ctx context.Context, event *core.Event)
 			return nil, err
 		}
 		e.Message = msg
-	case core.EventTypeBlockchainEventReceived:
+	case core.EventTypeBlockchainEventReceived, core.EventTypeBlockchainEventRemoved:
 		be, err := t.GetBlockchainEventByIDCached(ctx, event.Reference)
 		if err != nil {
 			return nil, err
@@ -94,7 +94,8 @@ func (t *transactionHelper) EnrichEvent(ctx context.Context, event *core.Event)
 			return nil, err
 		}
 		e.TokenTransfer = transfer
-	case core.EventTypeApprovalOpFailed, core.EventTypeTransferOpFailed, core.EventTypeBlockchainInvokeOpFailed, core.EventTypePoolOpFailed, core.EventTypeBlockchainInvokeOpSucceeded:
+	case core.EventTypeApprovalOpFailed, core.EventTypeTransferOpFailed, core.EventTypeBlockchainInvokeOpFailed, core.EventTypePoolOpFailed, core.EventTypeBlockchainInvokeOpSucceeded,
+		core.EventTypeBlockchainContractDeployOpSucceeded, core.EventTypeBlockchainContractDeployOpFailed:
 		operation, err := t.database.GetOperationByID(ctx, event.Reference)
 		if err != nil {
 			return nil, err
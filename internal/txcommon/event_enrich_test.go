@@ -202,6 +202,32 @@ func TestEnrichBlockchainEventFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestEnrichBlockchainEventRemoved(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi.On("GetBlockchainEventByID", mock.Anything, ref1).Return(&core.BlockchainEvent{
+		ID: ref1,
+	}, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeBlockchainEventRemoved,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.BlockchainEvent.ID)
+}
+
 func TestEnrichContractAPISubmitted(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -550,6 +576,36 @@ func TestEnrichTokenApprovalFailed(t *testing.T) {
 	assert.Equal(t, ref1, enriched.Operation.ID)
 }
 
+func TestEnrichContractDeploySucceeded(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi.On("GetOperationByID", mock.Anything, ref1).Return(&core.Operation{
+		ID: ref1,
+		Output: fftypes.JSONObject{
+			"contractLocation": map[string]interface{}{"address": "0x12345"},
+		},
+	}, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeBlockchainContractDeployOpSucceeded,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Operation.ID)
+	assert.Equal(t, "0x12345", enriched.Operation.Output.GetObject("contractLocation").GetString("address"))
+}
+
 func TestEnrichTokenApprovalConfirmedFail(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -0,0 +1,92 @@
+// Code generated by mockery v1.0.0. DO NOT EDIT.
+
+package authmocks
+
+import (
+	context "context"
+
+	auth "github.com/hyperledger/firefly/pkg/auth"
+
+	config "github.com/hyperledger/firefly-common/pkg/config"
+
+	mock "github.com/stretchr/testify/mock"
+
+	openapi3 "github.com/getkin/kin-openapi/openapi3"
+)
+
+// Plugin is an autogenerated mock type for the Plugin type
+type Plugin struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: ctx, req
+func (_m *Plugin) Authenticate(ctx context.Context, req *auth.Request) (*auth.Principal, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *auth.Principal
+	if rf, ok := ret.Get(0).(func(context.Context, *auth.Request) *auth.Principal); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.Principal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *auth.Request) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with given fields: ctx, _a1
+func (_m *Plugin) Init(ctx context.Context, _a1 config.Section) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, config.Section) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InitConfig provides a mock function with given fields: _a0
+func (_m *Plugin) InitConfig(_a0 config.Section) {
+	_m.Called(_a0)
+}
+
+// Name provides a mock function with given fields:
+func (_m *Plugin) Name() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// SecurityScheme provides a mock function with given fields:
+func (_m *Plugin) SecurityScheme() *openapi3.SecurityScheme {
+	ret := _m.Called()
+
+	var r0 *openapi3.SecurityScheme
+	if rf, ok := ret.Get(0).(func() *openapi3.SecurityScheme); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*openapi3.SecurityScheme)
+		}
+	}
+
+	return r0
+}
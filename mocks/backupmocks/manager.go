@@ -0,0 +1,54 @@
+// Code generated by mockery v1.0.0. DO NOT EDIT.
+
+package backupmocks
+
+import (
+	context "context"
+	io "io"
+
+	core "github.com/hyperledger/firefly/pkg/core"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Manager is an autogenerated mock type for the Manager type
+type Manager struct {
+	mock.Mock
+}
+
+// Export provides a mock function with given fields: ctx, w
+func (_m *Manager) Export(ctx context.Context, w io.Writer) error {
+	ret := _m.Called(ctx, w)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Import provides a mock function with given fields: ctx, r
+func (_m *Manager) Import(ctx context.Context, r io.Reader) (*core.NamespaceImportResult, error) {
+	ret := _m.Called(ctx, r)
+
+	var r0 *core.NamespaceImportResult
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader) *core.NamespaceImportResult); ok {
+		r0 = rf(ctx, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.NamespaceImportResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
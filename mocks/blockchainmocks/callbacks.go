@@ -44,6 +44,20 @@ func (_m *Callbacks) BlockchainEvent(event *blockchain.EventWithSubscription) er
 	return r0
 }
 
+// BlockchainEventRemoved provides a mock function with given fields: event
+func (_m *Callbacks) BlockchainEventRemoved(event *blockchain.EventWithSubscription) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*blockchain.EventWithSubscription) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BlockchainNetworkAction provides a mock function with given fields: action, event, signingKey
 func (_m *Callbacks) BlockchainNetworkAction(action string, event *blockchain.Event, signingKey *core.VerifierRef) error {
 	ret := _m.Called(action, event, signingKey)
@@ -62,3 +76,26 @@ func (_m *Callbacks) BlockchainNetworkAction(action string, event *blockchain.Ev
 func (_m *Callbacks) BlockchainOpUpdate(plugin blockchain.Plugin, nsOpID string, txState core.OpStatus, blockchainTXID string, errorMessage string, opOutput fftypes.JSONObject) {
 	_m.Called(plugin, nsOpID, txState, blockchainTXID, errorMessage, opOutput)
 }
+
+// ContractListenerConfirmations provides a mock function with given fields: subscription
+func (_m *Callbacks) ContractListenerConfirmations(subscription string) (*int, error) {
+	ret := _m.Called(subscription)
+
+	var r0 *int
+	if rf, ok := ret.Get(0).(func(string) *int); ok {
+		r0 = rf(subscription)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(subscription)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
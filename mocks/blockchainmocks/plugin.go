@@ -66,6 +66,22 @@ func (_m *Plugin) ConfigureContract(ctx context.Context, contracts *core.FireFly
 	return r0
 }
 
+// DecodeOperationRevert provides a mock function with given fields: ctx, opOutput, errors
+func (_m *Plugin) DecodeOperationRevert(ctx context.Context, opOutput fftypes.JSONObject, errors []*core.FFIError) *core.ContractRevert {
+	ret := _m.Called(ctx, opOutput, errors)
+
+	var r0 *core.ContractRevert
+	if rf, ok := ret.Get(0).(func(context.Context, fftypes.JSONObject, []*core.FFIError) *core.ContractRevert); ok {
+		r0 = rf(ctx, opOutput, errors)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractRevert)
+		}
+	}
+
+	return r0
+}
+
 // DeleteContractListener provides a mock function with given fields: ctx, subscription
 func (_m *Plugin) DeleteContractListener(ctx context.Context, subscription *core.ContractListener) error {
 	ret := _m.Called(ctx, subscription)
@@ -80,6 +96,34 @@ func (_m *Plugin) DeleteContractListener(ctx context.Context, subscription *core
 	return r0
 }
 
+// DeployContract provides a mock function with given fields: ctx, nsOpID, signingKey, definition, contract, input, options
+func (_m *Plugin) DeployContract(ctx context.Context, nsOpID string, signingKey string, definition *fftypes.JSONAny, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) error {
+	ret := _m.Called(ctx, nsOpID, signingKey, definition, contract, input, options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.JSONAny, *fftypes.JSONAny, []interface{}, map[string]interface{}) error); ok {
+		r0 = rf(ctx, nsOpID, signingKey, definition, contract, input, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GenerateErrorSignature provides a mock function with given fields: ctx, errorDef
+func (_m *Plugin) GenerateErrorSignature(ctx context.Context, errorDef *core.FFIErrorDefinition) string {
+	ret := _m.Called(ctx, errorDef)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, *core.FFIErrorDefinition) string); ok {
+		r0 = rf(ctx, errorDef)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // GenerateEventSignature provides a mock function with given fields: ctx, event
 func (_m *Plugin) GenerateEventSignature(ctx context.Context, event *core.FFIEventDefinition) string {
 	ret := _m.Called(ctx, event)
@@ -159,13 +203,13 @@ func (_m *Plugin) InitConfig(_a0 config.Section) {
 	_m.Called(_a0)
 }
 
-// InvokeContract provides a mock function with given fields: ctx, nsOpID, signingKey, location, method, input, options
-func (_m *Plugin) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) error {
-	ret := _m.Called(ctx, nsOpID, signingKey, location, method, input, options)
+// InvokeContract provides a mock function with given fields: ctx, nsOpID, signingKey, location, method, input, errors, options
+func (_m *Plugin) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) error {
+	ret := _m.Called(ctx, nsOpID, signingKey, location, method, input, errors, options)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.JSONAny, *core.FFIMethod, map[string]interface{}, map[string]interface{}) error); ok {
-		r0 = rf(ctx, nsOpID, signingKey, location, method, input, options)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.JSONAny, *core.FFIMethod, map[string]interface{}, []*core.FFIError, map[string]interface{}) error); ok {
+		r0 = rf(ctx, nsOpID, signingKey, location, method, input, errors, options)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -245,13 +289,13 @@ func (_m *Plugin) NormalizeSigningKey(ctx context.Context, keyRef string) (strin
 	return r0, r1
 }
 
-// QueryContract provides a mock function with given fields: ctx, location, method, input, options
-func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) (interface{}, error) {
-	ret := _m.Called(ctx, location, method, input, options)
+// QueryContract provides a mock function with given fields: ctx, location, method, input, errors, options
+func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) (interface{}, error) {
+	ret := _m.Called(ctx, location, method, input, errors, options)
 
 	var r0 interface{}
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.JSONAny, *core.FFIMethod, map[string]interface{}, map[string]interface{}) interface{}); ok {
-		r0 = rf(ctx, location, method, input, options)
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.JSONAny, *core.FFIMethod, map[string]interface{}, []*core.FFIError, map[string]interface{}) interface{}); ok {
+		r0 = rf(ctx, location, method, input, errors, options)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(interface{})
@@ -259,8 +303,8 @@ func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.JSONAny, *core.FFIMethod, map[string]interface{}, map[string]interface{}) error); ok {
-		r1 = rf(ctx, location, method, input, options)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.JSONAny, *core.FFIMethod, map[string]interface{}, []*core.FFIError, map[string]interface{}) error); ok {
+		r1 = rf(ctx, location, method, input, errors, options)
 	} else {
 		r1 = ret.Error(1)
 	}
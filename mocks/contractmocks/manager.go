@@ -125,6 +125,29 @@ func (_m *Manager) DeleteContractListenerByNameOrID(ctx context.Context, ns stri
 	return r0
 }
 
+// DeployContract provides a mock function with given fields: ctx, ns, req, waitConfirm
+func (_m *Manager) DeployContract(ctx context.Context, ns string, req *core.ContractDeployRequest, waitConfirm bool) (interface{}, error) {
+	ret := _m.Called(ctx, ns, req, waitConfirm)
+
+	var r0 interface{}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.ContractDeployRequest, bool) interface{}); ok {
+		r0 = rf(ctx, ns, req, waitConfirm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *core.ContractDeployRequest, bool) error); ok {
+		r1 = rf(ctx, ns, req, waitConfirm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateFFI provides a mock function with given fields: ctx, ns, generationRequest
 func (_m *Manager) GenerateFFI(ctx context.Context, ns string, generationRequest *core.FFIGenerationRequest) (*core.FFI, error) {
 	ret := _m.Called(ctx, ns, generationRequest)
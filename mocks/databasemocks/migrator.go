@@ -0,0 +1,111 @@
+// Code generated by mockery v1.0.0. DO NOT EDIT.
+
+package databasemocks
+
+import (
+	context "context"
+
+	config "github.com/hyperledger/firefly-common/pkg/config"
+
+	database "github.com/hyperledger/firefly/pkg/database"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Migrator is an autogenerated mock type for the Migrator type
+type Migrator struct {
+	mock.Mock
+}
+
+// CheckSchema provides a mock function with given fields: ctx
+func (_m *Migrator) CheckSchema(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InitMigrator provides a mock function with given fields: ctx, _a1
+func (_m *Migrator) InitMigrator(ctx context.Context, _a1 config.Section) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, config.Section) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MigrateDown provides a mock function with given fields: ctx, steps
+func (_m *Migrator) MigrateDown(ctx context.Context, steps int) error {
+	ret := _m.Called(ctx, steps)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, steps)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MigrateTo provides a mock function with given fields: ctx, version
+func (_m *Migrator) MigrateTo(ctx context.Context, version uint) error {
+	ret := _m.Called(ctx, version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MigrateUp provides a mock function with given fields: ctx
+func (_m *Migrator) MigrateUp(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MigrationStatus provides a mock function with given fields: ctx
+func (_m *Migrator) MigrationStatus(ctx context.Context) (*database.MigrationStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *database.MigrationStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *database.MigrationStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*database.MigrationStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
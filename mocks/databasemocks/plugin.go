@@ -149,6 +149,29 @@ func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID)
 	return r0
 }
 
+// GetAggregate provides a mock function with given fields: ctx, collection, query, filter
+func (_m *Plugin) GetAggregate(ctx context.Context, collection database.CollectionName, query *core.AggregateQuery, filter database.Filter) ([]*core.AggregateResult, error) {
+	ret := _m.Called(ctx, collection, query, filter)
+
+	var r0 []*core.AggregateResult
+	if rf, ok := ret.Get(0).(func(context.Context, database.CollectionName, *core.AggregateQuery, database.Filter) []*core.AggregateResult); ok {
+		r0 = rf(ctx, collection, query, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.AggregateResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, database.CollectionName, *core.AggregateQuery, database.Filter) error); ok {
+		r1 = rf(ctx, collection, query, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetBatchByID(ctx context.Context, id *fftypes.UUID) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, id)
@@ -383,13 +406,13 @@ func (_m *Plugin) GetBlockchainEvents(ctx context.Context, filter database.Filte
 	return r0, r1, r2
 }
 
-// GetChartHistogram provides a mock function with given fields: ctx, ns, intervals, collection
-func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []core.ChartHistogramInterval, collection database.CollectionName) ([]*core.ChartHistogram, error) {
-	ret := _m.Called(ctx, ns, intervals, collection)
+// GetChartHistogram provides a mock function with given fields: ctx, ns, intervals, collection, groupBy
+func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []core.ChartHistogramInterval, collection database.CollectionName, groupBy string) ([]*core.ChartHistogram, error) {
+	ret := _m.Called(ctx, ns, intervals, collection, groupBy)
 
 	var r0 []*core.ChartHistogram
-	if rf, ok := ret.Get(0).(func(context.Context, string, []core.ChartHistogramInterval, database.CollectionName) []*core.ChartHistogram); ok {
-		r0 = rf(ctx, ns, intervals, collection)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []core.ChartHistogramInterval, database.CollectionName, string) []*core.ChartHistogram); ok {
+		r0 = rf(ctx, ns, intervals, collection, groupBy)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*core.ChartHistogram)
@@ -397,8 +420,8 @@ func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, []core.ChartHistogramInterval, database.CollectionName) error); ok {
-		r1 = rf(ctx, ns, intervals, collection)
+	if rf, ok := ret.Get(1).(func(context.Context, string, []core.ChartHistogramInterval, database.CollectionName, string) error); ok {
+		r1 = rf(ctx, ns, intervals, collection, groupBy)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -805,6 +828,61 @@ func (_m *Plugin) GetDatatypes(ctx context.Context, filter database.Filter) ([]*
 	return r0, r1, r2
 }
 
+// GetDeadLetterByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetDeadLetterByID(ctx context.Context, id *fftypes.UUID) (*core.DeadLetter, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *core.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *core.DeadLetter); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadLetter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetDeadLetters(ctx context.Context, filter database.Filter) ([]*core.DeadLetter, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*core.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetEventByID(ctx context.Context, id *fftypes.UUID) (*core.Event, error) {
 	ret := _m.Called(ctx, id)
@@ -906,6 +984,38 @@ func (_m *Plugin) GetFFIByID(ctx context.Context, id *fftypes.UUID) (*core.FFI,
 	return r0, r1
 }
 
+// GetFFIErrors provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetFFIErrors(ctx context.Context, filter database.Filter) ([]*core.FFIError, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*core.FFIError
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*core.FFIError); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.FFIError)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetFFIEvent provides a mock function with given fields: ctx, ns, interfaceID, pathName
 func (_m *Plugin) GetFFIEvent(ctx context.Context, ns string, interfaceID *fftypes.UUID, pathName string) (*core.FFIEvent, error) {
 	ret := _m.Called(ctx, ns, interfaceID, pathName)
@@ -2334,6 +2444,20 @@ func (_m *Plugin) InsertDataArray(ctx context.Context, data core.DataArray) erro
 	return r0
 }
 
+// InsertDeadLetter provides a mock function with given fields: ctx, deadLetter
+func (_m *Plugin) InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error {
+	ret := _m.Called(ctx, deadLetter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeadLetter) error); ok {
+		r0 = rf(ctx, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertEvent provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertEvent(ctx context.Context, data *core.Event) error {
 	ret := _m.Called(ctx, data)
@@ -2432,6 +2556,20 @@ func (_m *Plugin) InsertPins(ctx context.Context, pins []*core.Pin) error {
 	return r0
 }
 
+// InsertTokenBalance provides a mock function with given fields: ctx, balance
+func (_m *Plugin) InsertTokenBalance(ctx context.Context, balance *core.TokenBalance) error {
+	ret := _m.Called(ctx, balance)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.TokenBalance) error); ok {
+		r0 = rf(ctx, balance)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertTransaction provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertTransaction(ctx context.Context, data *core.Transaction) error {
 	ret := _m.Called(ctx, data)
@@ -2460,6 +2598,27 @@ func (_m *Plugin) Name() string {
 	return r0
 }
 
+// PruneCollection provides a mock function with given fields: ctx, ns, collection, criteria
+func (_m *Plugin) PruneCollection(ctx context.Context, ns string, collection database.CollectionName, criteria *database.PruneCriteria) (int64, error) {
+	ret := _m.Called(ctx, ns, collection, criteria)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.CollectionName, *database.PruneCriteria) int64); ok {
+		r0 = rf(ctx, ns, collection, criteria)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.CollectionName, *database.PruneCriteria) error); ok {
+		r1 = rf(ctx, ns, collection, criteria)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RegisterListener provides a mock function with given fields: listener
 func (_m *Plugin) RegisterListener(listener database.Callbacks) {
 	_m.Called(listener)
@@ -2507,6 +2666,29 @@ func (_m *Plugin) RunAsGroup(ctx context.Context, fn func(context.Context) error
 	return r0
 }
 
+// Search provides a mock function with given fields: ctx, ns, text, types, limit
+func (_m *Plugin) Search(ctx context.Context, ns string, text string, types []fftypes.FFEnum, limit int) ([]*core.SearchHit, error) {
+	ret := _m.Called(ctx, ns, text, types, limit)
+
+	var r0 []*core.SearchHit
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []fftypes.FFEnum, int) []*core.SearchHit); ok {
+		r0 = rf(ctx, ns, text, types, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.SearchHit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []fftypes.FFEnum, int) error); ok {
+		r1 = rf(ctx, ns, text, types, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateBatch provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateBatch(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -2843,6 +3025,20 @@ func (_m *Plugin) UpsertFFI(ctx context.Context, cd *core.FFI) error {
 	return r0
 }
 
+// UpsertFFIError provides a mock function with given fields: ctx, errorDef
+func (_m *Plugin) UpsertFFIError(ctx context.Context, errorDef *core.FFIError) error {
+	ret := _m.Called(ctx, errorDef)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.FFIError) error); ok {
+		r0 = rf(ctx, errorDef)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertFFIEvent provides a mock function with given fields: ctx, method
 func (_m *Plugin) UpsertFFIEvent(ctx context.Context, method *core.FFIEvent) error {
 	ret := _m.Called(ctx, method)
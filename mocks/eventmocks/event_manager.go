@@ -69,6 +69,20 @@ func (_m *EventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 	return r0
 }
 
+// BlockchainEventRemoved provides a mock function with given fields: event
+func (_m *EventManager) BlockchainEventRemoved(event *blockchain.EventWithSubscription) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*blockchain.EventWithSubscription) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BlockchainNetworkAction provides a mock function with given fields: bi, action, event, signingKey
 func (_m *EventManager) BlockchainNetworkAction(bi blockchain.Plugin, action string, event *blockchain.Event, signingKey *core.VerifierRef) error {
 	ret := _m.Called(bi, action, event, signingKey)
@@ -83,6 +97,29 @@ func (_m *EventManager) BlockchainNetworkAction(bi blockchain.Plugin, action str
 	return r0
 }
 
+// ContractListenerConfirmations provides a mock function with given fields: subscription
+func (_m *EventManager) ContractListenerConfirmations(subscription string) (*int, error) {
+	ret := _m.Called(subscription)
+
+	var r0 *int
+	if rf, ok := ret.Get(0).(func(string) *int); ok {
+		r0 = rf(subscription)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(subscription)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateUpdateDurableSubscription provides a mock function with given fields: ctx, subDef, mustNew
 func (_m *EventManager) CreateUpdateDurableSubscription(ctx context.Context, subDef *core.Subscription, mustNew bool) error {
 	ret := _m.Called(ctx, subDef, mustNew)
@@ -148,6 +185,29 @@ func (_m *EventManager) GetPlugins() []*core.NodeStatusPlugin {
 	return r0
 }
 
+// GetSubscriptionStatus provides a mock function with given fields: ctx, subDef
+func (_m *EventManager) GetSubscriptionStatus(ctx context.Context, subDef *core.Subscription) (*core.SubscriptionStatus, error) {
+	ret := _m.Called(ctx, subDef)
+
+	var r0 *core.SubscriptionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Subscription) *core.SubscriptionStatus); ok {
+		r0 = rf(ctx, subDef)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SubscriptionStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *core.Subscription) error); ok {
+		r1 = rf(ctx, subDef)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewEvents provides a mock function with given fields:
 func (_m *EventManager) NewEvents() chan<- int64 {
 	ret := _m.Called()
@@ -196,6 +256,20 @@ func (_m *EventManager) NewSubscriptions() chan<- *fftypes.UUID {
 	return r0
 }
 
+// ResetDurableSubscription provides a mock function with given fields: ctx, subDef, reset
+func (_m *EventManager) ResetDurableSubscription(ctx context.Context, subDef *core.Subscription, reset *core.SubscriptionReset) error {
+	ret := _m.Called(ctx, subDef, reset)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Subscription, *core.SubscriptionReset) error); ok {
+		r0 = rf(ctx, subDef, reset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SharedStorageBatchDownloaded provides a mock function with given fields: ss, ns, payloadRef, data
 func (_m *EventManager) SharedStorageBatchDownloaded(ss sharedstorage.Plugin, ns string, payloadRef string, data []byte) (*fftypes.UUID, error) {
 	ret := _m.Called(ss, ns, payloadRef, data)
@@ -19,6 +19,20 @@ func (_m *Callbacks) ConnectionClosed(connID string) {
 	_m.Called(connID)
 }
 
+// DeadLetter provides a mock function with given fields: connID, deadLetter
+func (_m *Callbacks) DeadLetter(connID string, deadLetter *core.DeadLetter) error {
+	ret := _m.Called(connID, deadLetter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *core.DeadLetter) error); ok {
+		r0 = rf(connID, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeliveryResponse provides a mock function with given fields: connID, inflight
 func (_m *Callbacks) DeliveryResponse(connID string, inflight *core.EventDeliveryResponse) {
 	_m.Called(connID, inflight)
@@ -51,3 +65,17 @@ func (_m *Callbacks) RegisterConnection(connID string, matcher events.Subscripti
 
 	return r0
 }
+
+// RewindSubscription provides a mock function with given fields: namespace, name, sequence
+func (_m *Callbacks) RewindSubscription(namespace string, name string, sequence int64) error {
+	ret := _m.Called(namespace, name, sequence)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) error); ok {
+		r0 = rf(namespace, name, sequence)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
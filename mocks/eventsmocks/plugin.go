@@ -7,6 +7,8 @@ import (
 
 	config "github.com/hyperledger/firefly-common/pkg/config"
 
+	metrics "github.com/hyperledger/firefly/internal/metrics"
+
 	core "github.com/hyperledger/firefly/pkg/core"
 
 	events "github.com/hyperledger/firefly/pkg/events"
@@ -19,6 +21,20 @@ type Plugin struct {
 	mock.Mock
 }
 
+// BatchDeliveryRequest provides a mock function with given fields: connID, sub, _a2
+func (_m *Plugin) BatchDeliveryRequest(connID string, sub *core.Subscription, _a2 []*core.CombinedEventDataDelivery) error {
+	ret := _m.Called(connID, sub, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *core.Subscription, []*core.CombinedEventDataDelivery) error); ok {
+		r0 = rf(connID, sub, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Capabilities provides a mock function with given fields:
 func (_m *Plugin) Capabilities() *events.Capabilities {
 	ret := _m.Called()
@@ -35,13 +51,13 @@ func (_m *Plugin) Capabilities() *events.Capabilities {
 	return r0
 }
 
-// DeliveryRequest provides a mock function with given fields: connID, sub, event, data
-func (_m *Plugin) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
-	ret := _m.Called(connID, sub, event, data)
+// DeliveryRequest provides a mock function with given fields: ctx, connID, sub, event, data
+func (_m *Plugin) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	ret := _m.Called(ctx, connID, sub, event, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, *core.Subscription, *core.EventDelivery, core.DataArray) error); ok {
-		r0 = rf(connID, sub, event, data)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.Subscription, *core.EventDelivery, core.DataArray) error); ok {
+		r0 = rf(ctx, connID, sub, event, data)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,13 +65,13 @@ func (_m *Plugin) DeliveryRequest(connID string, sub *core.Subscription, event *
 	return r0
 }
 
-// Init provides a mock function with given fields: ctx, _a1, callbacks
-func (_m *Plugin) Init(ctx context.Context, _a1 config.Section, callbacks events.Callbacks) error {
-	ret := _m.Called(ctx, _a1, callbacks)
+// Init provides a mock function with given fields: ctx, _a1, callbacks, _a3
+func (_m *Plugin) Init(ctx context.Context, _a1 config.Section, callbacks events.Callbacks, _a3 metrics.Manager) error {
+	ret := _m.Called(ctx, _a1, callbacks, _a3)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, config.Section, events.Callbacks) error); ok {
-		r0 = rf(ctx, _a1, callbacks)
+	if rf, ok := ret.Get(0).(func(context.Context, config.Section, events.Callbacks, metrics.Manager) error); ok {
+		r0 = rf(ctx, _a1, callbacks, _a3)
 	} else {
 		r0 = ret.Error(0)
 	}
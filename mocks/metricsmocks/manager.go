@@ -4,11 +4,12 @@ package metricsmocks
 
 import (
 	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
-	core "github.com/hyperledger/firefly/pkg/core"
 
-	mock "github.com/stretchr/testify/mock"
+	core "github.com/hyperledger/firefly/pkg/core"
 
 	time "time"
+
+	mock "github.com/stretchr/testify/mock"
 )
 
 // Manager is an autogenerated mock type for the Manager type
@@ -84,6 +85,46 @@ func (_m *Manager) MessageSubmitted(msg *core.Message) {
 	_m.Called(msg)
 }
 
+// RetentionRowsPruned provides a mock function with given fields: namespace, collection, count
+func (_m *Manager) RetentionRowsPruned(namespace string, collection string, count int64) {
+	_m.Called(namespace, collection, count)
+}
+
+// SubscriptionDeleted provides a mock function with given fields: namespace, subscription, transport
+func (_m *Manager) SubscriptionDeleted(namespace string, subscription string, transport string) {
+	_m.Called(namespace, subscription, transport)
+}
+
+// SubscriptionDeliveryFailed provides a mock function with given fields: namespace, subscription, transport
+func (_m *Manager) SubscriptionDeliveryFailed(namespace string, subscription string, transport string) {
+	_m.Called(namespace, subscription, transport)
+}
+
+// SubscriptionEventAcked provides a mock function with given fields: namespace, subscription, transport, elapsed
+func (_m *Manager) SubscriptionEventAcked(namespace string, subscription string, transport string, elapsed time.Duration) {
+	_m.Called(namespace, subscription, transport, elapsed)
+}
+
+// SubscriptionEventNacked provides a mock function with given fields: namespace, subscription, transport, elapsed
+func (_m *Manager) SubscriptionEventNacked(namespace string, subscription string, transport string, elapsed time.Duration) {
+	_m.Called(namespace, subscription, transport, elapsed)
+}
+
+// SubscriptionEventsDelivered provides a mock function with given fields: namespace, subscription, transport, count
+func (_m *Manager) SubscriptionEventsDelivered(namespace string, subscription string, transport string, count int) {
+	_m.Called(namespace, subscription, transport, count)
+}
+
+// SubscriptionEventsInflight provides a mock function with given fields: namespace, subscription, transport, count
+func (_m *Manager) SubscriptionEventsInflight(namespace string, subscription string, transport string, count int) {
+	_m.Called(namespace, subscription, transport, count)
+}
+
+// SubscriptionOffsetLag provides a mock function with given fields: namespace, subscription, transport, lag
+func (_m *Manager) SubscriptionOffsetLag(namespace string, subscription string, transport string, lag int64) {
+	_m.Called(namespace, subscription, transport, lag)
+}
+
 // TransferConfirmed provides a mock function with given fields: transfer
 func (_m *Manager) TransferConfirmed(transfer *core.TokenTransfer) {
 	_m.Called(transfer)
@@ -93,3 +134,8 @@ func (_m *Manager) TransferConfirmed(transfer *core.TokenTransfer) {
 func (_m *Manager) TransferSubmitted(transfer *core.TokenTransfer) {
 	_m.Called(transfer)
 }
+
+// TransportConnections provides a mock function with given fields: transport, count
+func (_m *Manager) TransportConnections(transport string, count int) {
+	_m.Called(transport, count)
+}
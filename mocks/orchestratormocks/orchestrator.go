@@ -4,6 +4,9 @@ package orchestratormocks
 
 import (
 	assets "github.com/hyperledger/firefly/internal/assets"
+
+	backup "github.com/hyperledger/firefly/internal/backup"
+
 	batch "github.com/hyperledger/firefly/internal/batch"
 
 	broadcast "github.com/hyperledger/firefly/internal/broadcast"
@@ -20,7 +23,7 @@ import (
 
 	events "github.com/hyperledger/firefly/internal/events"
 
-	metrics "github.com/hyperledger/firefly/internal/metrics"
+	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -52,6 +55,22 @@ func (_m *Orchestrator) Assets() assets.Manager {
 	return r0
 }
 
+// Backup provides a mock function with given fields:
+func (_m *Orchestrator) Backup() backup.Manager {
+	ret := _m.Called()
+
+	var r0 backup.Manager
+	if rf, ok := ret.Get(0).(func() backup.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(backup.Manager)
+		}
+	}
+
+	return r0
+}
+
 // BatchManager provides a mock function with given fields:
 func (_m *Orchestrator) BatchManager() batch.Manager {
 	ret := _m.Called()
@@ -192,6 +211,29 @@ func (_m *Orchestrator) Events() events.EventManager {
 	return r0
 }
 
+// GetAggregate provides a mock function with given fields: ctx, ns, collection, query, filter
+func (_m *Orchestrator) GetAggregate(ctx context.Context, ns string, collection database.CollectionName, query *core.AggregateQuery, filter database.AndFilter) ([]*core.AggregateResult, error) {
+	ret := _m.Called(ctx, ns, collection, query, filter)
+
+	var r0 []*core.AggregateResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.CollectionName, *core.AggregateQuery, database.AndFilter) []*core.AggregateResult); ok {
+		r0 = rf(ctx, ns, collection, query, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.AggregateResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.CollectionName, *core.AggregateQuery, database.AndFilter) error); ok {
+		r1 = rf(ctx, ns, collection, query, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetBatchByID(ctx context.Context, ns string, id string) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -302,13 +344,13 @@ func (_m *Orchestrator) GetBlockchainEvents(ctx context.Context, ns string, filt
 	return r0, r1, r2
 }
 
-// GetChartHistogram provides a mock function with given fields: ctx, ns, startTime, endTime, buckets, tableName
-func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error) {
-	ret := _m.Called(ctx, ns, startTime, endTime, buckets, tableName)
+// GetChartHistogram provides a mock function with given fields: ctx, ns, startTime, endTime, buckets, tableName, groupBy
+func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName, groupBy string) ([]*core.ChartHistogram, error) {
+	ret := _m.Called(ctx, ns, startTime, endTime, buckets, tableName, groupBy)
 
 	var r0 []*core.ChartHistogram
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, int64, database.CollectionName) []*core.ChartHistogram); ok {
-		r0 = rf(ctx, ns, startTime, endTime, buckets, tableName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, int64, database.CollectionName, string) []*core.ChartHistogram); ok {
+		r0 = rf(ctx, ns, startTime, endTime, buckets, tableName, groupBy)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*core.ChartHistogram)
@@ -316,8 +358,8 @@ func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startT
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64, int64, database.CollectionName) error); ok {
-		r1 = rf(ctx, ns, startTime, endTime, buckets, tableName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64, int64, database.CollectionName, string) error); ok {
+		r1 = rf(ctx, ns, startTime, endTime, buckets, tableName, groupBy)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -458,6 +500,61 @@ func (_m *Orchestrator) GetDatatypes(ctx context.Context, ns string, filter data
 	return r0, r1, r2
 }
 
+// GetDeadLetterByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetDeadLetterByID(ctx context.Context, ns string, id string) (*core.DeadLetter, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *core.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.DeadLetter); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadLetter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetDeadLetters(ctx context.Context, ns string, filter database.AndFilter) ([]*core.DeadLetter, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*core.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventByID provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetEventByID(ctx context.Context, ns string, id string) (*core.Event, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -1008,6 +1105,29 @@ func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id s
 	return r0, r1
 }
 
+// GetSubscriptionByIDWithStatus provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetSubscriptionByIDWithStatus(ctx context.Context, ns string, id string) (*core.SubscriptionWithStatus, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *core.SubscriptionWithStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.SubscriptionWithStatus); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SubscriptionWithStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSubscriptions provides a mock function with given fields: ctx, ns, filter
 func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*core.Subscription, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
@@ -1196,22 +1316,6 @@ func (_m *Orchestrator) Init(ctx context.Context, cancelCtx context.CancelFunc)
 	return r0
 }
 
-// Metrics provides a mock function with given fields:
-func (_m *Orchestrator) Metrics() metrics.Manager {
-	ret := _m.Called()
-
-	var r0 metrics.Manager
-	if rf, ok := ret.Get(0).(func() metrics.Manager); ok {
-		r0 = rf()
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(metrics.Manager)
-		}
-	}
-
-	return r0
-}
-
 // NetworkMap provides a mock function with given fields:
 func (_m *Orchestrator) NetworkMap() networkmap.Manager {
 	ret := _m.Called()
@@ -1283,6 +1387,52 @@ func (_m *Orchestrator) RequestReply(ctx context.Context, ns string, msg *core.M
 	return r0, r1
 }
 
+// ResetSubscription provides a mock function with given fields: ctx, ns, id, reset
+func (_m *Orchestrator) ResetSubscription(ctx context.Context, ns string, id string, reset *core.SubscriptionReset) (*core.SubscriptionWithStatus, error) {
+	ret := _m.Called(ctx, ns, id, reset)
+
+	var r0 *core.SubscriptionWithStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *core.SubscriptionReset) *core.SubscriptionWithStatus); ok {
+		r0 = rf(ctx, ns, id, reset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SubscriptionWithStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *core.SubscriptionReset) error); ok {
+		r1 = rf(ctx, ns, id, reset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, ns, text, types, limit
+func (_m *Orchestrator) Search(ctx context.Context, ns string, text string, types []fftypes.FFEnum, limit int) ([]*core.SearchHit, error) {
+	ret := _m.Called(ctx, ns, text, types, limit)
+
+	var r0 []*core.SearchHit
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []fftypes.FFEnum, int) []*core.SearchHit); ok {
+		r0 = rf(ctx, ns, text, types, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.SearchHit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []fftypes.FFEnum, int) error); ok {
+		r1 = rf(ctx, ns, text, types, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *Orchestrator) Start() error {
 	ret := _m.Called()
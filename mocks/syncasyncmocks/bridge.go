@@ -25,6 +25,29 @@ func (_m *Bridge) Init(sysevents sysmessaging.SystemEvents) {
 	_m.Called(sysevents)
 }
 
+// WaitForDeployOperation provides a mock function with given fields: ctx, ns, id, send
+func (_m *Bridge) WaitForDeployOperation(ctx context.Context, ns string, id *fftypes.UUID, send syncasync.RequestSender) (*core.Operation, error) {
+	ret := _m.Called(ctx, ns, id, send)
+
+	var r0 *core.Operation
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, syncasync.RequestSender) *core.Operation); ok {
+		r0 = rf(ctx, ns, id, send)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, syncasync.RequestSender) error); ok {
+		r1 = rf(ctx, ns, id, send)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WaitForIdentity provides a mock function with given fields: ctx, ns, id, send
 func (_m *Bridge) WaitForIdentity(ctx context.Context, ns string, id *fftypes.UUID, send syncasync.RequestSender) (*core.Identity, error) {
 	ret := _m.Called(ctx, ns, id, send)
@@ -0,0 +1,97 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// Plugin is the interface implemented by each API authentication plugin
+type Plugin interface {
+	core.Named
+
+	// InitConfig initializes the set of configuration options that are valid, with defaults. Called on all plugins.
+	InitConfig(config config.Section)
+
+	// Init initializes the plugin, with configuration
+	Init(ctx context.Context, config config.Section) error
+
+	// Authenticate verifies the credentials on an inbound API request, and returns the principal
+	// they identify - along with the namespaces and access level that principal has been granted.
+	// An error must be returned if the request does not carry valid credentials.
+	Authenticate(ctx context.Context, req *Request) (*Principal, error)
+
+	// SecurityScheme returns the OpenAPI security scheme advertised in generated Swagger for APIs protected by this plugin
+	SecurityScheme() *openapi3.SecurityScheme
+}
+
+// Request is the information about an inbound API request that is passed to the plugin for authentication
+type Request struct {
+	Method    string
+	URL       *url.URL
+	Header    http.Header
+	RouteName string
+	Namespace string
+	ReadOnly  bool
+}
+
+// AllNamespaces can be used in a Grant to provide access to every namespace
+const AllNamespaces = "*"
+
+// Grant is a level of access to a namespace (or all namespaces)
+type Grant struct {
+	Namespace string
+	ReadOnly  bool
+}
+
+// Principal is an authenticated caller of the API
+type Principal struct {
+	Subject string
+	Grants  []*Grant
+}
+
+// Authorized checks whether the principal has been granted access to perform a request on the given
+// namespace. An empty namespace is used for global routes, which any principal with a grant can read.
+func (p *Principal) Authorized(namespace string, readOnly bool) bool {
+	for _, g := range p.Grants {
+		if g.Namespace == AllNamespaces || g.Namespace == namespace || (namespace == "" && readOnly) {
+			if readOnly || !g.ReadOnly {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal that was authorized for a request
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// GetPrincipal returns the principal that was authorized for a request, or nil if no auth plugin is configured
+func GetPrincipal(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
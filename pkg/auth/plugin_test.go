@@ -0,0 +1,59 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalAuthorized(t *testing.T) {
+	p := &Principal{
+		Subject: "user1",
+		Grants: []*Grant{
+			{Namespace: "ns1", ReadOnly: true},
+			{Namespace: "ns2", ReadOnly: false},
+		},
+	}
+	assert.True(t, p.Authorized("ns1", true))
+	assert.False(t, p.Authorized("ns1", false))
+	assert.True(t, p.Authorized("ns2", true))
+	assert.True(t, p.Authorized("ns2", false))
+	assert.False(t, p.Authorized("ns3", true))
+	assert.True(t, p.Authorized("", true))
+	assert.False(t, p.Authorized("", false))
+
+	p = &Principal{
+		Subject: "admin",
+		Grants: []*Grant{
+			{Namespace: AllNamespaces},
+		},
+	}
+	assert.True(t, p.Authorized("ns1", false))
+	assert.True(t, p.Authorized("", false))
+
+	p = &Principal{Subject: "nobody"}
+	assert.False(t, p.Authorized("", true))
+}
+
+func TestPrincipalContext(t *testing.T) {
+	assert.Nil(t, GetPrincipal(context.Background()))
+	p := &Principal{Subject: "user1"}
+	assert.Equal(t, p, GetPrincipal(WithPrincipal(context.Background(), p)))
+}
@@ -69,11 +69,16 @@ type Plugin interface {
 	// SubmitNetworkAction writes a special "BatchPin" event which signals the plugin to take an action
 	SubmitNetworkAction(ctx context.Context, nsOpID string, signingKey string, action core.NetworkActionType) error
 
-	// InvokeContract submits a new transaction to be executed by custom on-chain logic
-	InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) error
+	// InvokeContract submits a new transaction to be executed by custom on-chain logic.
+	// The errors are the error definitions of the interface, used to decode the reason if the transaction is reverted.
+	InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) error
 
-	// QueryContract executes a method via custom on-chain logic and returns the result
-	QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, options map[string]interface{}) (interface{}, error)
+	// DeployContract submits a new transaction to deploy a smart contract, passing the input to its constructor
+	DeployContract(ctx context.Context, nsOpID string, signingKey string, definition *fftypes.JSONAny, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) error
+
+	// QueryContract executes a method via custom on-chain logic and returns the result.
+	// The errors are the error definitions of the interface, used to decode the reason if the query is reverted.
+	QueryContract(ctx context.Context, location *fftypes.JSONAny, method *core.FFIMethod, input map[string]interface{}, errors []*core.FFIError, options map[string]interface{}) (interface{}, error)
 
 	// AddContractListener adds a new subscription to a user-specified contract and event
 	AddContractListener(ctx context.Context, subscription *core.ContractListenerInput) error
@@ -93,6 +98,13 @@ type Plugin interface {
 	// GenerateEventSignature generates a strigified signature for the event, incorporating any fields significant to identifying the event as unique
 	GenerateEventSignature(ctx context.Context, event *core.FFIEventDefinition) string
 
+	// GenerateErrorSignature generates a stringified signature for the error, incorporating any fields significant to identifying the error as unique
+	GenerateErrorSignature(ctx context.Context, errorDef *core.FFIErrorDefinition) string
+
+	// DecodeOperationRevert decodes the revert data recorded in the output of a failed transaction, against the error definitions
+	// of the interface that was invoked. Returns nil if there is no revert data, or it does not match any of the definitions.
+	DecodeOperationRevert(ctx context.Context, opOutput fftypes.JSONObject, errors []*core.FFIError) *core.ContractRevert
+
 	// NetworkVersion returns the version of the network rules being used by this plugin
 	NetworkVersion() int
 }
@@ -125,6 +137,17 @@ type Callbacks interface {
 
 	// BlockchainEvent notifies on the arrival of any event from a user-created subscription.
 	BlockchainEvent(event *EventWithSubscription) error
+
+	// BlockchainEventRemoved notifies that an event previously delivered via BlockchainEvent, BatchPinComplete
+	// or BlockchainNetworkAction has been removed from the chain by a re-organization.
+	// The subscription is the one the event was originally delivered on.
+	//
+	// Error should only be returned in shutdown scenarios
+	BlockchainEventRemoved(event *EventWithSubscription) error
+
+	// ContractListenerConfirmations returns the number of block confirmations requested on the contract listener
+	// with the given backend subscription ID, or nil if the listener is unknown or uses the plugin default.
+	ContractListenerConfirmations(subscription string) (*int, error)
 }
 
 // Capabilities the supported featureset of the blockchain
@@ -207,6 +230,13 @@ type Event struct {
 	Signature string
 }
 
+const (
+	// EventInfoBlockNumber is the key in Event.Info of the decimal block number containing the event, set by all plugins
+	EventInfoBlockNumber = "blockNumber"
+	// EventInfoBlockHash is the key in Event.Info of the hash of the block containing the event, where available
+	EventInfoBlockHash = "blockHash"
+)
+
 type EventWithSubscription struct {
 	Event
 
@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// RevertError is returned when a smart contract transaction or query is reverted by the blockchain,
+// and the data returned with the revert could be decoded against the error definitions of the interface
+type RevertError struct {
+	Err    error
+	Revert *core.ContractRevert
+}
+
+func (re *RevertError) Error() string {
+	return re.Err.Error()
+}
+
+func (re *RevertError) Unwrap() error {
+	return re.Err
+}
+
+// GetRevert returns the decoded revert carried by the error, or nil if it is not a RevertError
+func GetRevert(err error) *core.ContractRevert {
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) {
+		return revertErr.Revert
+	}
+	return nil
+}
+
+// OperationRevertError returns the error of a failed operation, as a RevertError if the blockchain
+// plugin recorded a decoded revert in the "revert" field of the operation output
+func OperationRevertError(op *core.Operation, err error) error {
+	revertJSON := op.Output.GetObject("revert")
+	if len(revertJSON) == 0 {
+		return err
+	}
+	var revert core.ContractRevert
+	b, _ := json.Marshal(revertJSON)
+	if json.Unmarshal(b, &revert) != nil || revert.Error == "" {
+		return err
+	}
+	return &RevertError{Err: err, Revert: &revert}
+}
@@ -0,0 +1,64 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockchain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevertError(t *testing.T) {
+	revert := &core.ContractRevert{
+		Error:  "InsufficientBalance",
+		Params: fftypes.JSONObject{"available": "1"},
+	}
+	err := fmt.Errorf("wrapped: %w", &RevertError{Err: fmt.Errorf("pop"), Revert: revert})
+	assert.Equal(t, "wrapped: pop", err.Error())
+	assert.Equal(t, revert, GetRevert(err))
+	assert.Nil(t, GetRevert(fmt.Errorf("pop")))
+}
+
+func TestOperationRevertError(t *testing.T) {
+	op := &core.Operation{
+		Output: fftypes.JSONObject{
+			"revert": map[string]interface{}{
+				"error":  "InsufficientBalance",
+				"params": map[string]interface{}{"available": "1"},
+			},
+		},
+	}
+	err := OperationRevertError(op, fmt.Errorf("pop"))
+	assert.Regexp(t, "pop", err)
+	revert := GetRevert(err)
+	assert.Equal(t, "InsufficientBalance", revert.Error)
+	assert.Equal(t, "1", revert.Params.GetString("available"))
+}
+
+func TestOperationRevertErrorNoRevert(t *testing.T) {
+	err := fmt.Errorf("pop")
+	assert.Equal(t, err, OperationRevertError(&core.Operation{}, err))
+	op := &core.Operation{
+		Output: fftypes.JSONObject{
+			"revert": map[string]interface{}{"params": map[string]interface{}{}},
+		},
+	}
+	assert.Equal(t, err, OperationRevertError(op, err))
+}
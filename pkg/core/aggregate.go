@@ -0,0 +1,56 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// AggregateFunction is a function calculated over a numeric field, for the records in each group of an aggregation
+type AggregateFunction = fftypes.FFEnum
+
+var (
+	// AggregateFunctionSum is the total of the values of the field
+	AggregateFunctionSum = fftypes.FFEnumValue("aggregatefunction", "sum")
+	// AggregateFunctionMin is the lowest value of the field
+	AggregateFunctionMin = fftypes.FFEnumValue("aggregatefunction", "min")
+	// AggregateFunctionMax is the highest value of the field
+	AggregateFunctionMax = fftypes.FFEnumValue("aggregatefunction", "max")
+)
+
+// AggregateMetric is a function to calculate over a field, for the records in each group
+type AggregateMetric struct {
+	Function AggregateFunction `ffstruct:"AggregateMetric" json:"function" ffenum:"aggregatefunction"`
+	Field    string            `ffstruct:"AggregateMetric" json:"field"`
+}
+
+// AggregateQuery is the fields to group the records matching a filter by, and the metrics to calculate for each group
+type AggregateQuery struct {
+	GroupBy []string           `ffstruct:"AggregateQuery" json:"groupBy"`
+	Metrics []*AggregateMetric `ffstruct:"AggregateQuery" json:"metrics"`
+}
+
+// AggregateResult is the count of records, and the value of each metric, for one combination of the groupBy field values
+type AggregateResult struct {
+	Group   fftypes.JSONObject       `ffstruct:"AggregateResult" json:"group"`
+	Count   string                   `ffstruct:"AggregateResult" json:"count"`
+	Metrics []*AggregateMetricResult `ffstruct:"AggregateResult" json:"metrics"`
+}
+
+// AggregateMetricResult is the value of a metric for a group, which is nil if no record in the group had a value for the field
+type AggregateMetricResult struct {
+	AggregateMetric
+	Value *string `ffstruct:"AggregateMetricResult" json:"value"`
+}
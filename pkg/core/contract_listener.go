@@ -34,24 +34,42 @@ type ContractListener struct {
 	Location  *fftypes.JSONAny         `ffstruct:"ContractListener" json:"location,omitempty"`
 	Created   *fftypes.FFTime          `ffstruct:"ContractListener" json:"created,omitempty" ffexcludeinput:"true"`
 	Event     *FFISerializedEvent      `ffstruct:"ContractListener" json:"event,omitempty" ffexcludeinput:"postContractAPIListeners"`
+	Events    FFISerializedEvents      `ffstruct:"ContractListener" json:"events,omitempty" ffexcludeinput:"postContractAPIListeners"`
 	Signature string                   `ffstruct:"ContractListener" json:"signature" ffexcludeinput:"true"`
 	Topic     string                   `ffstruct:"ContractListener" json:"topic,omitempty"`
 	Options   *ContractListenerOptions `ffstruct:"ContractListener" json:"options,omitempty"`
 }
 
 type ContractListenerOptions struct {
-	FirstEvent string `ffstruct:"ContractListenerOptions" json:"firstEvent,omitempty"`
+	FirstEvent    string             `ffstruct:"ContractListenerOptions" json:"firstEvent,omitempty"`
+	Confirmations *int               `ffstruct:"ContractListenerOptions" json:"confirmations,omitempty"`
+	Filters       fftypes.JSONObject `ffstruct:"ContractListenerOptions" json:"filters,omitempty"`
 }
 
 type ContractListenerInput struct {
 	ContractListener
-	EventPath string `ffstruct:"ContractListener" json:"eventPath,omitempty"`
+	EventPath  string   `ffstruct:"ContractListener" json:"eventPath,omitempty"`
+	EventPaths []string `ffstruct:"ContractListener" json:"eventPaths,omitempty"`
 }
 
 type FFISerializedEvent struct {
 	FFIEventDefinition
 }
 
+type FFISerializedEvents []*FFISerializedEvent
+
+// AllEvents returns every event the listener covers - the Events list for a listener
+// on multiple events, otherwise just the single Event
+func (cl *ContractListener) AllEvents() FFISerializedEvents {
+	if len(cl.Events) > 0 {
+		return cl.Events
+	}
+	if cl.Event == nil {
+		return nil
+	}
+	return FFISerializedEvents{cl.Event}
+}
+
 // Scan implements sql.Scanner
 func (fse *FFISerializedEvent) Scan(src interface{}) error {
 	switch src := src.(type) {
@@ -72,6 +90,29 @@ func (fse FFISerializedEvent) Value() (driver.Value, error) {
 	return bytes, nil
 }
 
+// Scan implements sql.Scanner
+func (fse *FFISerializedEvents) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*fse = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), fse)
+	case []byte:
+		return json.Unmarshal(src, fse)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgTypeRestoreFailed, src, fse)
+	}
+}
+
+func (fse FFISerializedEvents) Value() (driver.Value, error) {
+	if fse == nil {
+		return nil, nil
+	}
+	bytes, _ := json.Marshal(fse)
+	return bytes, nil
+}
+
 // Scan implements sql.Scanner
 func (o *ContractListenerOptions) Scan(src interface{}) error {
 	switch src := src.(type) {
@@ -96,3 +96,68 @@ func TestContractListenerOptionsValue(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, `{"firstEvent":"newest"}`, string(val.([]byte)))
 }
+
+func TestFFISerializedEventsScan(t *testing.T) {
+	events := FFISerializedEvents{}
+	err := events.Scan([]byte(`[{"name":"event1"},{"name":"event2"}]`))
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "event2", events[1].Name)
+}
+
+func TestFFISerializedEventsScanNil(t *testing.T) {
+	events := FFISerializedEvents{}
+	err := events.Scan(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, events)
+}
+
+func TestFFISerializedEventsScanString(t *testing.T) {
+	events := FFISerializedEvents{}
+	err := events.Scan(`[{"name":"event1"}]`)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestFFISerializedEventsScanError(t *testing.T) {
+	events := FFISerializedEvents{}
+	err := events.Scan(false)
+	assert.Regexp(t, "FF00105", err)
+}
+
+func TestFFISerializedEventsValue(t *testing.T) {
+	events := FFISerializedEvents{
+		{FFIEventDefinition: FFIEventDefinition{Name: "event1"}},
+	}
+	val, err := events.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"name":"event1","description":"","params":null}]`, string(val.([]byte)))
+
+	val, err = FFISerializedEvents(nil).Value()
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+}
+
+func TestContractListenerAllEvents(t *testing.T) {
+	event1 := &FFISerializedEvent{FFIEventDefinition: FFIEventDefinition{Name: "event1"}}
+	event2 := &FFISerializedEvent{FFIEventDefinition: FFIEventDefinition{Name: "event2"}}
+
+	listener := &ContractListener{}
+	assert.Nil(t, listener.AllEvents())
+
+	listener.Event = event1
+	assert.Equal(t, FFISerializedEvents{event1}, listener.AllEvents())
+
+	listener.Events = FFISerializedEvents{event1, event2}
+	assert.Equal(t, FFISerializedEvents{event1, event2}, listener.AllEvents())
+}
+
+func TestContractListenerOptionsValueFilters(t *testing.T) {
+	options := &ContractListenerOptions{
+		Filters: fftypes.JSONObject{"to": "0x123"},
+	}
+
+	val, err := options.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"filters":{"to":"0x123"}}`, string(val.([]byte)))
+}
@@ -38,10 +38,19 @@ type ContractCallRequest struct {
 	Key        string                 `ffstruct:"ContractCallRequest" json:"key,omitempty"`
 	Method     *FFIMethod             `ffstruct:"ContractCallRequest" json:"method,omitempty" ffexcludeinput:"postContractAPIInvoke,postContractAPIQuery"`
 	MethodPath string                 `ffstruct:"ContractCallRequest" json:"methodPath,omitempty" ffexcludeinput:"postContractAPIInvoke,postContractAPIQuery"`
+	Errors     []*FFIError            `ffstruct:"ContractCallRequest" json:"errors,omitempty" ffexcludeinput:"postContractAPIInvoke,postContractAPIQuery"`
 	Input      map[string]interface{} `ffstruct:"ContractCallRequest" json:"input"`
 	Options    map[string]interface{} `ffstruct:"ContractCallRequest" json:"options"`
 }
 
+type ContractDeployRequest struct {
+	Key        string                 `ffstruct:"ContractDeployRequest" json:"key,omitempty"`
+	Definition *fftypes.JSONAny       `ffstruct:"ContractDeployRequest" json:"definition"`
+	Contract   *fftypes.JSONAny       `ffstruct:"ContractDeployRequest" json:"contract"`
+	Input      []interface{}          `ffstruct:"ContractDeployRequest" json:"input"`
+	Options    map[string]interface{} `ffstruct:"ContractDeployRequest" json:"options"`
+}
+
 type ContractURLs struct {
 	OpenAPI string `ffstruct:"ContractURLs" json:"openapi"`
 	UI      string `ffstruct:"ContractURLs" json:"ui"`
@@ -0,0 +1,32 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// DeadLetter records an event that could not be delivered to a subscription, after all retries were exhausted
+type DeadLetter struct {
+	ID           *fftypes.UUID    `ffstruct:"DeadLetter" json:"id"`
+	Namespace    string           `ffstruct:"DeadLetter" json:"namespace"`
+	Subscription SubscriptionRef  `ffstruct:"DeadLetter" json:"subscription"`
+	Transport    string           `ffstruct:"DeadLetter" json:"transport"`
+	Event        *fftypes.UUID    `ffstruct:"DeadLetter" json:"event"`
+	Attempts     int              `ffstruct:"DeadLetter" json:"attempts"`
+	Error        string           `ffstruct:"DeadLetter" json:"error,omitempty"`
+	LastResponse *fftypes.JSONAny `ffstruct:"DeadLetter" json:"lastResponse,omitempty"`
+	Created      *fftypes.FFTime  `ffstruct:"DeadLetter" json:"created"`
+}
@@ -55,10 +55,16 @@ var (
 	EventTypeContractAPIConfirmed = fftypes.FFEnumValue("eventtype", "contract_api_confirmed")
 	// EventTypeBlockchainEventReceived occurs when a new event has been received from the blockchain
 	EventTypeBlockchainEventReceived = fftypes.FFEnumValue("eventtype", "blockchain_event_received")
+	// EventTypeBlockchainEventRemoved occurs when a previously delivered blockchain event is removed by a chain re-organization
+	EventTypeBlockchainEventRemoved = fftypes.FFEnumValue("eventtype", "blockchain_event_removed")
 	// EventTypeBlockchainInvokeOpSucceeded occurs when a blockchain "invoke" request has succeeded
 	EventTypeBlockchainInvokeOpSucceeded = fftypes.FFEnumValue("eventtype", "blockchain_invoke_op_succeeded")
 	// EventTypeBlockchainInvokeOpFailed occurs when a blockchain "invoke" request has failed
 	EventTypeBlockchainInvokeOpFailed = fftypes.FFEnumValue("eventtype", "blockchain_invoke_op_failed")
+	// EventTypeBlockchainContractDeployOpSucceeded occurs when a contract deployment request has been submitted successfully
+	EventTypeBlockchainContractDeployOpSucceeded = fftypes.FFEnumValue("eventtype", "blockchain_contract_deploy_op_succeeded")
+	// EventTypeBlockchainContractDeployOpFailed occurs when a contract deployment request has failed
+	EventTypeBlockchainContractDeployOpFailed = fftypes.FFEnumValue("eventtype", "blockchain_contract_deploy_op_failed")
 )
 
 // Event is an activity in the system, delivered reliably to applications, that indicates something has happened in the network
@@ -98,6 +104,12 @@ type EventDelivery struct {
 	Subscription SubscriptionRef `json:"subscription"`
 }
 
+// CombinedEventDataDelivery combines an event delivery with its data, for transports that deliver batches of events
+type CombinedEventDataDelivery struct {
+	Event *EventDelivery
+	Data  DataArray
+}
+
 // EventDeliveryResponse is the payload an application sends back, to confirm it has accepted (or rejected) the event and as such
 // does not need to receive it again.
 type EventDeliveryResponse struct {
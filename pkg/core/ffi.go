@@ -63,6 +63,7 @@ type FFI struct {
 	Version     string        `ffstruct:"FFI" json:"version"`
 	Methods     []*FFIMethod  `ffstruct:"FFI" json:"methods,omitempty"`
 	Events      []*FFIEvent   `ffstruct:"FFI" json:"events,omitempty"`
+	Errors      []*FFIError   `ffstruct:"FFI" json:"errors,omitempty"`
 }
 
 type FFIMethod struct {
@@ -93,6 +94,28 @@ type FFIEvent struct {
 	FFIEventDefinition
 }
 
+type FFIErrorDefinition struct {
+	Name        string    `ffstruct:"FFIError" json:"name"`
+	Description string    `ffstruct:"FFIError" json:"description"`
+	Params      FFIParams `ffstruct:"FFIError" json:"params"`
+}
+
+type FFIError struct {
+	ID        *fftypes.UUID `ffstruct:"FFIError" json:"id,omitempty" ffexcludeinput:"true"`
+	Interface *fftypes.UUID `ffstruct:"FFIError" json:"interface,omitempty" ffexcludeinput:"true"`
+	Namespace string        `ffstruct:"FFIError" json:"namespace,omitempty" ffexcludeinput:"true"`
+	Pathname  string        `ffstruct:"FFIError" json:"pathname,omitempty" ffexcludeinput:"true"`
+	Signature string        `ffstruct:"FFIError" json:"signature" ffexcludeinput:"true"`
+	FFIErrorDefinition
+}
+
+// ContractRevert is the structured form of the data returned by a smart contract when a
+// transaction or query is reverted, decoded against the error definitions of the interface
+type ContractRevert struct {
+	Error  string             `ffstruct:"ContractRevert" json:"error"`
+	Params fftypes.JSONObject `ffstruct:"ContractRevert" json:"params"`
+}
+
 type FFIParam struct {
 	Name   string           `ffstruct:"FFIParam" json:"name"`
 	Schema *fftypes.JSONAny `ffstruct:"FFIParam" json:"schema,omitempty"`
@@ -73,6 +73,12 @@ type NetworkAction struct {
 	Type NetworkActionType `ffstruct:"NetworkAction" json:"type" ffenum:"networkactiontype"`
 }
 
+// NamespaceImportResult summarizes the records restored into a namespace from an export
+type NamespaceImportResult struct {
+	Namespace string           `ffstruct:"NamespaceImportResult" json:"namespace"`
+	Records   map[string]int64 `ffstruct:"NamespaceImportResult" json:"records"`
+}
+
 func (ns *Namespace) Validate(ctx context.Context, existing bool) (err error) {
 	if err = ValidateFFNameField(ctx, ns.Name, "name"); err != nil {
 		return err
@@ -0,0 +1,48 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// SearchHitType is the type of record matched by a full-text search
+type SearchHitType = fftypes.FFEnum
+
+var (
+	// SearchHitTypeMessage matches the tag, topics and author of a message
+	SearchHitTypeMessage = fftypes.FFEnumValue("searchhittype", "message")
+	// SearchHitTypeData matches the values inside the JSON value of data
+	SearchHitTypeData = fftypes.FFEnumValue("searchhittype", "data")
+	// SearchHitTypeIdentity matches the name, DID and description of an identity
+	SearchHitTypeIdentity = fftypes.FFEnumValue("searchhittype", "identity")
+	// SearchHitTypeTokenPool matches the name and symbol of a token pool
+	SearchHitTypeTokenPool = fftypes.FFEnumValue("searchhittype", "tokenpool")
+	// SearchHitTypeFFI matches the name, version and description of a FireFly interface
+	SearchHitTypeFFI = fftypes.FFEnumValue("searchhittype", "ffi")
+)
+
+// SearchHit is a record matched by a full-text search, with the relevance of the match.
+// Only the field for the type of the hit is set
+type SearchHit struct {
+	Type      SearchHitType `ffstruct:"SearchHit" json:"type" ffenum:"searchhittype"`
+	ID        *fftypes.UUID `ffstruct:"SearchHit" json:"id"`
+	Rank      float64       `ffstruct:"SearchHit" json:"rank"`
+	Message   *Message      `ffstruct:"SearchHit" json:"message,omitempty"`
+	Data      *Data         `ffstruct:"SearchHit" json:"data,omitempty"`
+	Identity  *Identity     `ffstruct:"SearchHit" json:"identity,omitempty"`
+	TokenPool *TokenPool    `ffstruct:"SearchHit" json:"tokenPool,omitempty"`
+	FFI       *FFI          `ffstruct:"SearchHit" json:"ffi,omitempty"`
+}
@@ -32,6 +32,8 @@ type SubscriptionFilter struct {
 	Message          MessageFilter         `ffstruct:"SubscriptionFilter" json:"message,omitempty"`
 	Transaction      TransactionFilter     `ffstruct:"SubscriptionFilter" json:"transaction,omitempty"`
 	BlockchainEvent  BlockchainEventFilter `ffstruct:"SubscriptionFilter" json:"blockchainevent,omitempty"`
+	Token            *TokenFilter          `ffstruct:"SubscriptionFilter" json:"token,omitempty"`
+	Data             *DataFilter           `ffstruct:"SubscriptionFilter" json:"data,omitempty"`
 	Topic            string                `ffstruct:"SubscriptionFilter" json:"topic,omitempty"`
 	DeprecatedTopics string                `ffstruct:"SubscriptionFilter" json:"topics,omitempty"`
 	DeprecatedTag    string                `ffstruct:"SubscriptionFilter" json:"tag,omitempty"`
@@ -40,7 +42,7 @@ type SubscriptionFilter struct {
 }
 
 func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
-	return SubscriptionFilter{
+	filter := SubscriptionFilter{
 		Events: query.Get("filter.events"),
 		Message: MessageFilter{
 			Group:  query.Get("filter.message.group"),
@@ -60,6 +62,24 @@ func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 		DeprecatedGroup:  query.Get("filter.group"),
 		DeprecatedAuthor: query.Get("filter.author"),
 	}
+	tokenFilter := TokenFilter{
+		Pool:      query.Get("filter.token.pool"),
+		From:      query.Get("filter.token.from"),
+		To:        query.Get("filter.token.to"),
+		MinAmount: query.Get("filter.token.minAmount"),
+		MaxAmount: query.Get("filter.token.maxAmount"),
+	}
+	if tokenFilter != (TokenFilter{}) {
+		filter.Token = &tokenFilter
+	}
+	dataFilter := DataFilter{
+		JSONPath: query.Get("filter.data.jsonpath"),
+		Value:    query.Get("filter.data.value"),
+	}
+	if dataFilter != (DataFilter{}) {
+		filter.Data = &dataFilter
+	}
+	return filter
 }
 
 type MessageFilter struct {
@@ -77,6 +97,19 @@ type BlockchainEventFilter struct {
 	Listener string `ffstruct:"SubscriptionBlockchainEventFilter" json:"listener,omitempty"`
 }
 
+type TokenFilter struct {
+	Pool      string `ffstruct:"SubscriptionTokenFilter" json:"pool,omitempty"`
+	From      string `ffstruct:"SubscriptionTokenFilter" json:"from,omitempty"`
+	To        string `ffstruct:"SubscriptionTokenFilter" json:"to,omitempty"`
+	MinAmount string `ffstruct:"SubscriptionTokenFilter" json:"minAmount,omitempty"`
+	MaxAmount string `ffstruct:"SubscriptionTokenFilter" json:"maxAmount,omitempty"`
+}
+
+type DataFilter struct {
+	JSONPath string `ffstruct:"SubscriptionDataFilter" json:"jsonpath,omitempty"`
+	Value    string `ffstruct:"SubscriptionDataFilter" json:"value,omitempty"`
+}
+
 // SubOptsFirstEvent picks the first event that should be dispatched on the subscription, and can be a string containing an exact sequence as well as one of the enum values
 type SubOptsFirstEvent string
 
@@ -87,11 +120,26 @@ const (
 	SubOptsFirstEventNewest SubOptsFirstEvent = "newest"
 )
 
+// SubOptsPartitionBy selects the key used to group events, so they can be delivered in parallel across keys while remaining in order within each key
+type SubOptsPartitionBy string
+
+const (
+	// SubOptsPartitionByTopic delivers events in order within each topic
+	SubOptsPartitionByTopic SubOptsPartitionBy = "topic"
+	// SubOptsPartitionByAuthor delivers events in order for each message author
+	SubOptsPartitionByAuthor SubOptsPartitionBy = "author"
+	// SubOptsPartitionByGroup delivers events in order for each private messaging group
+	SubOptsPartitionByGroup SubOptsPartitionBy = "group"
+)
+
 // SubscriptionCoreOptions are the core options that apply across all transports
 type SubscriptionCoreOptions struct {
-	FirstEvent *SubOptsFirstEvent `ffstruct:"SubscriptionCoreOptions" json:"firstEvent,omitempty"`
-	ReadAhead  *uint16            `ffstruct:"SubscriptionCoreOptions" json:"readAhead,omitempty"`
-	WithData   *bool              `ffstruct:"SubscriptionCoreOptions" json:"withData,omitempty"`
+	FirstEvent   *SubOptsFirstEvent  `ffstruct:"SubscriptionCoreOptions" json:"firstEvent,omitempty"`
+	ReadAhead    *uint16             `ffstruct:"SubscriptionCoreOptions" json:"readAhead,omitempty"`
+	WithData     *bool               `ffstruct:"SubscriptionCoreOptions" json:"withData,omitempty"`
+	Batch        *bool               `ffstruct:"SubscriptionCoreOptions" json:"batch,omitempty"`
+	BatchTimeout *string             `ffstruct:"SubscriptionCoreOptions" json:"batchTimeout,omitempty"`
+	PartitionBy  *SubOptsPartitionBy `ffstruct:"SubscriptionCoreOptions" json:"partitionBy,omitempty"`
 }
 
 // SubscriptionOptions customize the behavior of subscriptions
@@ -122,6 +170,39 @@ type Subscription struct {
 	Updated   *fftypes.FFTime     `ffstruct:"Subscription" json:"updated" ffexcludeinput:"true"`
 }
 
+// SubscriptionStatus is the position of a durable subscription in the stream of events
+type SubscriptionStatus struct {
+	CurrentOffset int64 `ffstruct:"SubscriptionStatus" json:"currentOffset"`
+	Lag           int64 `ffstruct:"SubscriptionStatus" json:"lag"`
+}
+
+// SubscriptionWithStatus is a subscription, along with its current position in the stream of events
+type SubscriptionWithStatus struct {
+	Subscription
+	Status *SubscriptionStatus `ffstruct:"SubscriptionWithStatus" json:"status,omitempty"`
+}
+
+// SubscriptionReset moves a durable subscription to a new position in the stream of events.
+// Exactly one of FirstEvent or Timestamp must be set.
+type SubscriptionReset struct {
+	FirstEvent *SubOptsFirstEvent `ffstruct:"SubscriptionReset" json:"firstEvent,omitempty"`
+	Timestamp  *fftypes.FFTime    `ffstruct:"SubscriptionReset" json:"timestamp,omitempty"`
+}
+
+// Redacted returns the subscription as it can be shown outside of FireFly, with any webhook secret replaced
+func (sub *Subscription) Redacted() *Subscription {
+	if sub == nil || sub.Options.additionalOptions["secret"] == nil {
+		return sub
+	}
+	redacted := *sub
+	redacted.Options.additionalOptions = make(fftypes.JSONObject, len(sub.Options.additionalOptions))
+	for k, v := range sub.Options.additionalOptions {
+		redacted.Options.additionalOptions[k] = v
+	}
+	redacted.Options.additionalOptions["secret"] = WebhookSecretRedacted
+	return &redacted
+}
+
 func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	so.additionalOptions = fftypes.JSONObject{}
 	err := json.Unmarshal(b, &so.additionalOptions)
@@ -134,6 +215,9 @@ func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	delete(so.additionalOptions, "firstEvent")
 	delete(so.additionalOptions, "readAhead")
 	delete(so.additionalOptions, "withData")
+	delete(so.additionalOptions, "batch")
+	delete(so.additionalOptions, "batchTimeout")
+	delete(so.additionalOptions, "partitionBy")
 	return nil
 }
 
@@ -150,6 +234,15 @@ func (so SubscriptionOptions) MarshalJSON() ([]byte, error) {
 	if so.ReadAhead != nil {
 		so.additionalOptions["readAhead"] = float64(*so.ReadAhead)
 	}
+	if so.Batch != nil {
+		so.additionalOptions["batch"] = *so.Batch
+	}
+	if so.BatchTimeout != nil {
+		so.additionalOptions["batchTimeout"] = *so.BatchTimeout
+	}
+	if so.PartitionBy != nil {
+		so.additionalOptions["partitionBy"] = *so.PartitionBy
+	}
 	return json.Marshal(&so.additionalOptions)
 }
 
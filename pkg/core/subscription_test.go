@@ -24,6 +24,48 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestSubscriptionOptionsBatchSerialization(t *testing.T) {
+	yes := true
+	batchTimeout := "100ms"
+	opts1 := SubscriptionOptions{
+		SubscriptionCoreOptions: SubscriptionCoreOptions{
+			Batch:        &yes,
+			BatchTimeout: &batchTimeout,
+		},
+	}
+
+	b1, err := opts1.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"batch":true,"batchTimeout":"100ms"}`, string(b1.([]byte)))
+
+	var opts2 SubscriptionOptions
+	err = opts2.Scan(b1)
+	assert.NoError(t, err)
+	assert.True(t, *opts2.Batch)
+	assert.Equal(t, "100ms", *opts2.BatchTimeout)
+	assert.Nil(t, opts2.TransportOptions()["batch"])
+	assert.Nil(t, opts2.TransportOptions()["batchTimeout"])
+}
+
+func TestSubscriptionOptionsPartitionBySerialization(t *testing.T) {
+	partitionBy := SubOptsPartitionByTopic
+	opts1 := SubscriptionOptions{
+		SubscriptionCoreOptions: SubscriptionCoreOptions{
+			PartitionBy: &partitionBy,
+		},
+	}
+
+	b1, err := opts1.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"partitionBy":"topic"}`, string(b1.([]byte)))
+
+	var opts2 SubscriptionOptions
+	err = opts2.Scan(b1)
+	assert.NoError(t, err)
+	assert.Equal(t, SubOptsPartitionByTopic, *opts2.PartitionBy)
+	assert.Nil(t, opts2.TransportOptions()["partitionBy"])
+}
+
 func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	firstEvent := SubOptsFirstEventNewest
 	readAhead := uint16(50)
@@ -127,3 +169,40 @@ func TestNewSubscriptionFilterFromQuery(t *testing.T) {
 	assert.Equal(t, expectedFilter, filter)
 
 }
+
+func TestNewSubscriptionFilterFromQueryTokenAndData(t *testing.T) {
+	query, _ := url.ParseQuery("filter.token.pool=pool1&filter.token.from=0x111&filter.token.to=0x222&filter.token.minAmount=10&filter.token.maxAmount=100&filter.data.jsonpath=$.customer.region&filter.data.value=^emea$")
+	expectedFilter := SubscriptionFilter{
+		Token: &TokenFilter{
+			Pool:      "pool1",
+			From:      "0x111",
+			To:        "0x222",
+			MinAmount: "10",
+			MaxAmount: "100",
+		},
+		Data: &DataFilter{
+			JSONPath: "$.customer.region",
+			Value:    "^emea$",
+		},
+	}
+	filter := NewSubscriptionFilterFromQuery(query)
+	assert.Equal(t, expectedFilter, filter)
+}
+
+func TestSubscriptionRedacted(t *testing.T) {
+	sub := &Subscription{}
+	sub.Options.TransportOptions()["url"] = "http://example.com"
+	assert.Equal(t, sub, sub.Redacted())
+
+	sub.Options.TransportOptions()["secret"] = "shh"
+	redacted := sub.Redacted()
+	b, err := json.Marshal(redacted)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "shh")
+	assert.Equal(t, WebhookSecretRedacted, redacted.Options.TransportOptions().GetString("secret"))
+	assert.Equal(t, "http://example.com", redacted.Options.TransportOptions().GetString("url"))
+	assert.Equal(t, "shh", sub.Options.TransportOptions().GetString("secret"))
+
+	var nilSub *Subscription
+	assert.Nil(t, nilSub.Redacted())
+}
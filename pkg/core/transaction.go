@@ -35,6 +35,8 @@ var (
 	TransactionTypeTokenTransfer = fftypes.FFEnumValue("txtype", "token_transfer")
 	// TransactionTypeContractInvoke is a smart contract invoke
 	TransactionTypeContractInvoke = fftypes.FFEnumValue("txtype", "contract_invoke")
+	// TransactionTypeContractDeploy is a smart contract deployment
+	TransactionTypeContractDeploy = fftypes.FFEnumValue("txtype", "contract_deploy")
 	// TransactionTypeTokenTransfer represents a token approval
 	TransactionTypeTokenApproval = fftypes.FFEnumValue("txtype", "token_approval")
 )
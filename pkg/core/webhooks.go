@@ -16,6 +16,10 @@
 
 package core
 
+// WebhookSecretRedacted is returned in place of the secret of a webhook subscription, as the secret is write-only.
+// Sending it back when updating the subscription keeps the existing secret.
+const WebhookSecretRedacted = "********"
+
 type WebhookSubOptions struct {
 	Fastack  bool                `ffstruct:"WebhookSubOptions" json:"fastack,omitempty"`
 	URL      string              `ffstruct:"WebhookSubOptions" json:"url,omitempty"`
@@ -27,6 +31,8 @@ type WebhookSubOptions struct {
 	Headers  map[string]string   `ffstruct:"WebhookSubOptions" json:"headers,omitempty"`
 	Query    map[string]string   `ffstruct:"WebhookSubOptions" json:"query,omitempty"`
 	Input    WebhookInputOptions `ffstruct:"WebhookSubOptions" json:"input,omitempty"`
+	Retry    WebhookRetryOptions `ffstruct:"WebhookSubOptions" json:"retry,omitempty"`
+	Secret   string              `ffstruct:"WebhookSubOptions" json:"secret,omitempty"`
 }
 
 type WebhookInputOptions struct {
@@ -36,3 +42,11 @@ type WebhookInputOptions struct {
 	Path    string `ffstruct:"WebhookInputOptions" json:"path,omitempty"`
 	ReplyTX string `ffstruct:"WebhookInputOptions" json:"replytx,omitempty"`
 }
+
+type WebhookRetryOptions struct {
+	Count          int    `ffstruct:"WebhookRetryOptions" json:"count,omitempty"`
+	InitialDelay   string `ffstruct:"WebhookRetryOptions" json:"initialDelay,omitempty"`
+	MaximumDelay   string `ffstruct:"WebhookRetryOptions" json:"maxDelay,omitempty"`
+	MaximumElapsed string `ffstruct:"WebhookRetryOptions" json:"maxElapsed,omitempty"`
+	StatusCodes    []int  `ffstruct:"WebhookRetryOptions" json:"statusCodes,omitempty"`
+}
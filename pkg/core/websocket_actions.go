@@ -26,6 +26,14 @@ var (
 	WSClientActionStart = fftypes.FFEnumValue("wstype", "start")
 	// WSClientActionAck acknowledges an event that was delivered, allowing further messages to be sent
 	WSClientActionAck = fftypes.FFEnumValue("wstype", "ack")
+	// WSClientActionNack rejects an event that was delivered, causing it to be redelivered
+	WSClientActionNack = fftypes.FFEnumValue("wstype", "nack")
+	// WSClientActionAckUpTo acknowledges all events in flight, up to and including a given event sequence
+	WSClientActionAckUpTo = fftypes.FFEnumValue("wstype", "ack_upto")
+	// WSClientActionPause stops delivery of events on the connection, without disconnecting
+	WSClientActionPause = fftypes.FFEnumValue("wstype", "pause")
+	// WSClientActionResume restarts delivery of events on a paused connection
+	WSClientActionResume = fftypes.FFEnumValue("wstype", "resume")
 
 	// WSProtocolErrorEventType is a special event "type" field for server to send the client, if it performs a ProtocolError
 	WSProtocolErrorEventType = fftypes.FFEnumValue("wstype", "protocol_error")
@@ -56,6 +64,21 @@ type WSAck struct {
 	Subscription *SubscriptionRef `ffstruct:"WSAck" json:"subscription,omitempty"`
 }
 
+// WSNack rejects a received event, so it will be redelivered (not applicable in AutoAck mode)
+type WSNack struct {
+	WSAck
+
+	Reason string `ffstruct:"WSNack" json:"reason,omitempty"`
+}
+
+// WSAckUpTo acknowledges all events in flight with a sequence less than or equal to the one supplied (not applicable in AutoAck mode)
+type WSAckUpTo struct {
+	WSActionBase
+
+	Sequence     int64            `ffstruct:"WSAckUpTo" json:"sequence"`
+	Subscription *SubscriptionRef `ffstruct:"WSAckUpTo" json:"subscription,omitempty"`
+}
+
 // WSError is sent to the client by the server in the case of a protocol error
 type WSError struct {
 	Type  WSClientPayloadType `ffstruct:"WSAck" json:"type" ffenum:"wstype"`
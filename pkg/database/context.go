@@ -0,0 +1,35 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import "context"
+
+type readReplicaContextKey struct{}
+
+// WithReadReplica returns a context that allows query-only calls made with it to be served
+// from a read replica of the database, when the plugin has one configured.
+// Reads that need to see the latest writes (such as those of the event poller and aggregator)
+// must not use it. Calls inside of a RunAsGroup transaction are always served by the primary.
+func WithReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readReplicaContextKey{}, true)
+}
+
+// IsReadReplica returns true if the context allows query-only calls to be served from a read replica
+func IsReadReplica(ctx context.Context) bool {
+	replica, _ := ctx.Value(readReplicaContextKey{}).(bool)
+	return replica
+}
@@ -19,12 +19,15 @@ package database
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/base64"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
 )
 
 // Filter is the output of the builder
@@ -47,6 +50,13 @@ type Filter interface {
 	// Request a count to be returned on the total number that match the query
 	Count(c bool) Filter
 
+	// After restricts the results to those that follow the cursor, in the order of the query.
+	// An empty cursor requests the first page, with the cursors of the adjacent pages
+	After(cursor string) Filter
+
+	// Before restricts the results to the page that precedes the cursor, in the order of the query
+	Before(cursor string) Filter
+
 	// Finalize completes the filter, and for the plugin to validated output structure to convert
 	Finalize() (*FilterInfo, error)
 
@@ -147,6 +157,10 @@ func filterCannotAcceptNull(op FilterOp) bool {
 type FilterBuilder interface {
 	// Fields is the list of available fields
 	Fields() []string
+	// JSONFields is the list of JSON fields, which can also be filtered by a path within the JSON (such as value.orderId)
+	JSONFields() []string
+	// NumericFields is the list of fields with numeric values
+	NumericFields() []string
 	// And requires all sub-filters to match
 	And(and ...Filter) AndFilter
 	// Or requires any of the sub-filters to match
@@ -221,16 +235,42 @@ type FilterInfo struct {
 	Limit     uint64
 	Count     bool
 	CountExpr string
+	After     *int64
+	Before    *int64
+	Cursors   bool
 	Field     string
+	JSONPath  []string
 	Op        FilterOp
 	Values    []FieldSerialization
 	Value     FieldSerialization
 	Children  []*FilterInfo
 }
 
-// FilterResult is has additional info if requested on the query - currently only the total count
+// FilterResult is has additional info if requested on the query - the total count, and cursors
+// that can be passed to After/Before to fetch the adjacent pages of a sequence ordered query
 type FilterResult struct {
 	TotalCount *int64
+	Next       string
+	Prev       string
+}
+
+const cursorPrefix = "seq:"
+
+// EncodeCursor returns an opaque cursor for the position of the item with the supplied sequence
+func EncodeCursor(sequence int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatInt(sequence, 10)))
+}
+
+// DecodeCursor extracts the sequence from a cursor created by EncodeCursor
+func DecodeCursor(ctx context.Context, cursor string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(b), cursorPrefix) {
+		var sequence int64
+		if sequence, err = strconv.ParseInt(strings.TrimPrefix(string(b), cursorPrefix), 10, 64); err == nil && sequence >= 0 {
+			return sequence, nil
+		}
+	}
+	return -1, i18n.NewError(ctx, coremsgs.MsgInvalidFilterCursor, cursor)
 }
 
 func valueString(f FieldSerialization) string {
@@ -249,6 +289,13 @@ func valueString(f FieldSerialization) string {
 	}
 }
 
+func (f *FilterInfo) fieldString() string {
+	if len(f.JSONPath) > 0 {
+		return fmt.Sprintf("%s.%s", f.Field, strings.Join(f.JSONPath, "."))
+	}
+	return f.Field
+}
+
 func (f *FilterInfo) filterString() string {
 	switch f.Op {
 	case FilterOpAnd, FilterOpOr:
@@ -262,9 +309,9 @@ func (f *FilterInfo) filterString() string {
 		for i, v := range f.Values {
 			strValues[i] = valueString(v)
 		}
-		return fmt.Sprintf("%s %s [%s]", f.Field, f.Op, strings.Join(strValues, ","))
+		return fmt.Sprintf("%s %s [%s]", f.fieldString(), f.Op, strings.Join(strValues, ","))
 	default:
-		return fmt.Sprintf("%s %s %s", f.Field, f.Op, valueString(f.Value))
+		return fmt.Sprintf("%s %s %s", f.fieldString(), f.Op, valueString(f.Value))
 	}
 }
 
@@ -293,6 +340,12 @@ func (f *FilterInfo) String() string {
 	if f.Count {
 		val.WriteString(" count=true")
 	}
+	if f.After != nil {
+		val.WriteString(fmt.Sprintf(" after=%d", *f.After))
+	}
+	if f.Before != nil {
+		val.WriteString(fmt.Sprintf(" before=%d", *f.Before))
+	}
 
 	return val.String()
 }
@@ -307,6 +360,60 @@ func (fb *filterBuilder) Fields() []string {
 	return keys
 }
 
+// resolveField finds the field for a filter. As well as the fields of the query factory, a path
+// inside a JSON field can be used - such as "value.orderId" to filter on the orderId property
+// of the value of a data item
+func (fb *filterBuilder) resolveField(name string) (field Field, fieldName string, jsonPath []string, err error) {
+	lowerName := strings.ToLower(name)
+	if field, ok := fb.queryFields[lowerName]; ok {
+		return field, name, nil, nil
+	}
+	if dot := strings.Index(name, "."); dot > 0 {
+		if _, isJSON := fb.queryFields[lowerName[:dot]].(*JSONField); isJSON {
+			if jsonPath, err = ParseJSONPath(fb.ctx, name[dot+1:]); err != nil {
+				return nil, "", nil, err
+			}
+			return &jsonPathField{}, lowerName[:dot], jsonPath, nil
+		}
+	}
+	return nil, "", nil, i18n.NewError(fb.ctx, i18n.MsgInvalidFilterField, lowerName)
+}
+
+// JSONFields is the list of JSON fields, which can also be filtered by a path within the JSON
+func (fb *filterBuilder) JSONFields() []string {
+	keys := make([]string, 0)
+	for k, f := range fb.queryFields {
+		if _, isJSON := f.(*JSONField); isJSON {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// NumericFields is the list of fields with numeric values
+func (fb *filterBuilder) NumericFields() []string {
+	keys := make([]string, 0)
+	for k, f := range fb.queryFields {
+		if _, isInt := f.(*Int64Field); isInt {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+var jsonPathSegment = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+// ParseJSONPath splits a dot separated path into a JSON document, such as "order.id", into its segments
+func ParseJSONPath(ctx context.Context, path string) ([]string, error) {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if !jsonPathSegment.MatchString(segment) {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidJSONFilterPath, path)
+		}
+	}
+	return segments, nil
+}
+
 type filterBuilder struct {
 	ctx             context.Context
 	queryFields     queryFields
@@ -314,6 +421,9 @@ type filterBuilder struct {
 	skip            uint64
 	limit           uint64
 	count           bool
+	after           string
+	before          string
+	cursors         bool
 	forceAscending  bool
 	forceDescending bool
 }
@@ -334,6 +444,8 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 	var children []*FilterInfo
 	var value FieldSerialization
 	var values []FieldSerialization
+	var jsonPath []string
+	fieldName := f.field
 
 	switch f.op {
 	case FilterOpAnd, FilterOpOr:
@@ -347,9 +459,9 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		fValues := f.value.([]driver.Value)
 		values = make([]FieldSerialization, len(fValues))
 		name := strings.ToLower(f.field)
-		field, ok := f.fb.queryFields[name]
-		if !ok {
-			return nil, i18n.NewError(f.fb.ctx, i18n.MsgInvalidFilterField, name)
+		var field Field
+		if field, fieldName, jsonPath, err = f.fb.resolveField(f.field); err != nil {
+			return nil, err
 		}
 		for i, fv := range fValues {
 			values[i] = field.getSerialization()
@@ -359,9 +471,9 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		}
 	default:
 		name := strings.ToLower(f.field)
-		field, ok := f.fb.queryFields[name]
-		if !ok {
-			return nil, i18n.NewError(f.fb.ctx, i18n.MsgInvalidFilterField, name)
+		var field Field
+		if field, fieldName, jsonPath, err = f.fb.resolveField(f.field); err != nil {
+			return nil, err
 		}
 		skipScan := false
 		switch f.value.(type) {
@@ -400,17 +512,51 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		}
 	}
 
-	return &FilterInfo{
+	fi = &FilterInfo{
 		Children: children,
 		Op:       f.op,
-		Field:    f.field,
+		Field:    fieldName,
+		JSONPath: jsonPath,
 		Values:   values,
 		Value:    value,
 		Sort:     f.fb.sort,
 		Skip:     f.fb.skip,
 		Limit:    f.fb.limit,
 		Count:    f.fb.count,
-	}, nil
+	}
+	if err = f.finalizeCursors(fi); err != nil {
+		return nil, err
+	}
+	return fi, nil
+}
+
+func (f *baseFilter) finalizeCursors(fi *FilterInfo) error {
+	if f.fb.after != "" {
+		after, err := DecodeCursor(f.fb.ctx, f.fb.after)
+		if err != nil {
+			return err
+		}
+		fi.After = &after
+	}
+	if f.fb.before != "" {
+		before, err := DecodeCursor(f.fb.ctx, f.fb.before)
+		if err != nil {
+			return err
+		}
+		fi.Before = &before
+	}
+	fi.Cursors = f.fb.cursors
+	if !fi.Cursors {
+		return nil
+	}
+	// Cursors are positions in the sequence, so the query must be ordered by sequence alone
+	if len(fi.Sort) == 0 {
+		fi.Sort = []*SortField{{Field: "sequence", Descending: !f.fb.forceAscending}}
+	}
+	if len(fi.Sort) != 1 || fi.Sort[0].Field != "sequence" {
+		return i18n.NewError(f.fb.ctx, coremsgs.MsgFilterCursorRequiresSequenceSort)
+	}
+	return nil
 }
 
 func (f *baseFilter) Sort(fields ...string) Filter {
@@ -445,6 +591,18 @@ func (f *baseFilter) Count(c bool) Filter {
 	return f
 }
 
+func (f *baseFilter) After(cursor string) Filter {
+	f.fb.after = cursor
+	f.fb.cursors = true
+	return f
+}
+
+func (f *baseFilter) Before(cursor string) Filter {
+	f.fb.before = cursor
+	f.fb.cursors = true
+	return f
+}
+
 func (f *baseFilter) Ascending() Filter {
 	f.fb.forceAscending = true
 	return f
@@ -19,6 +19,7 @@ package database
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/base64"
 	"testing"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -343,3 +344,100 @@ func TestStringsForTypes(t *testing.T) {
 	assert.Equal(t, "t1,t2", (&ffNameArrayField{na: core.FFStringArray{"t1", "t2"}}).String())
 	assert.Equal(t, "true", (&boolField{b: true}).String())
 }
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := EncodeCursor(12345)
+	seq, err := DecodeCursor(context.Background(), cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), seq)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	_, err := DecodeCursor(context.Background(), "!!!")
+	assert.Regexp(t, "FF10446", err)
+	_, err = DecodeCursor(context.Background(), base64.RawURLEncoding.EncodeToString([]byte("id:12345")))
+	assert.Regexp(t, "FF10446", err)
+	_, err = DecodeCursor(context.Background(), base64.RawURLEncoding.EncodeToString([]byte("seq:abc")))
+	assert.Regexp(t, "FF10446", err)
+	_, err = DecodeCursor(context.Background(), base64.RawURLEncoding.EncodeToString([]byte("seq:-1")))
+	assert.Regexp(t, "FF10446", err)
+}
+
+func TestBuildFilterCursorsDefaultSort(t *testing.T) {
+	fb := EventQueryFactory.NewFilter(context.Background())
+	fi, err := fb.Eq("type", "message_confirmed").
+		After(EncodeCursor(100)).
+		Before(EncodeCursor(200)).
+		Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), *fi.After)
+	assert.Equal(t, int64(200), *fi.Before)
+	assert.Equal(t, "type == 'message_confirmed' sort=-sequence after=100 before=200", fi.String())
+}
+
+func TestBuildFilterCursorAscending(t *testing.T) {
+	fb := EventQueryFactory.NewFilter(context.Background())
+	fi, err := fb.And().After(EncodeCursor(100)).Ascending().Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "sequence", fi.Sort[0].Field)
+	assert.False(t, fi.Sort[0].Descending)
+}
+
+func TestBuildFilterCursorFirstPage(t *testing.T) {
+	fb := EventQueryFactory.NewFilter(context.Background())
+	fi, err := fb.And().After("").Finalize()
+	assert.NoError(t, err)
+	assert.True(t, fi.Cursors)
+	assert.Nil(t, fi.After)
+	assert.Equal(t, "sequence", fi.Sort[0].Field)
+	assert.True(t, fi.Sort[0].Descending)
+
+	fb = EventQueryFactory.NewFilter(context.Background())
+	fi, err = fb.And().Finalize()
+	assert.NoError(t, err)
+	assert.False(t, fi.Cursors)
+}
+
+func TestBuildFilterCursorBadSort(t *testing.T) {
+	fb := EventQueryFactory.NewFilter(context.Background())
+	_, err := fb.And().Sort("created").After(EncodeCursor(100)).Finalize()
+	assert.Regexp(t, "FF10447", err)
+}
+
+func TestBuildFilterCursorInvalid(t *testing.T) {
+	fb := EventQueryFactory.NewFilter(context.Background())
+	_, err := fb.And().After("bad").Finalize()
+	assert.Regexp(t, "FF10446", err)
+	fb = EventQueryFactory.NewFilter(context.Background())
+	_, err = fb.And().Before("bad").Finalize()
+	assert.Regexp(t, "FF10446", err)
+}
+
+func TestBuildFilterJSONPath(t *testing.T) {
+	fb := DataQueryFactory.NewFilter(context.Background())
+	fi, err := fb.And(
+		fb.Eq("value.orderId", "123"),
+		fb.Gte("Value.amount", 100),
+		fb.In("value.customer.name", []driver.Value{"alice", "bob"}),
+	).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( value.orderId == '123' ) && ( value.amount >= '100' ) && ( value.customer.name IN ['alice','bob'] )", fi.String())
+	assert.Equal(t, "value", fi.Children[0].Field)
+	assert.Equal(t, []string{"orderId"}, fi.Children[0].JSONPath)
+	assert.Equal(t, []string{"customer", "name"}, fi.Children[2].JSONPath)
+	assert.Equal(t, []string{"value"}, fb.JSONFields())
+}
+
+func TestBuildFilterJSONPathNotJSONField(t *testing.T) {
+	fb := DataQueryFactory.NewFilter(context.Background())
+	_, err := fb.Eq("hash.orderId", "123").Finalize()
+	assert.Regexp(t, "FF00142.*hash.orderid", err)
+}
+
+func TestBuildFilterJSONPathInvalid(t *testing.T) {
+	fb := DataQueryFactory.NewFilter(context.Background())
+	_, err := fb.Eq("value.order..id", "123").Finalize()
+	assert.Regexp(t, "FF10449", err)
+	_, err = fb.In("value.order'id", []driver.Value{"123"}).Finalize()
+	assert.Regexp(t, "FF10449", err)
+}
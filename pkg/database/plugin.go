@@ -60,6 +60,43 @@ type Plugin interface {
 	Capabilities() *Capabilities
 }
 
+// Migrator is implemented by plugins that manage their schema with numbered migrations, so that the
+// migrations can be run as a separate step to starting the node
+type Migrator interface {
+	// InitMigrator connects to the database to manage its migrations, without requiring the schema to be up to date
+	InitMigrator(ctx context.Context, config config.Section) error
+
+	// MigrationStatus returns the schema version of the database, and the migrations that are available
+	MigrationStatus(ctx context.Context) (*MigrationStatus, error)
+
+	// MigrateUp applies all the pending migrations
+	MigrateUp(ctx context.Context) error
+
+	// MigrateDown reverts the given number of migrations
+	MigrateDown(ctx context.Context, steps int) error
+
+	// MigrateTo applies or reverts migrations until the schema is at the given version
+	MigrateTo(ctx context.Context, version uint) error
+
+	// CheckSchema returns an error if the schema version of the database is not the one this build requires
+	CheckSchema(ctx context.Context) error
+}
+
+// MigrationStatus is the state of the schema of a database, against the migrations available
+type MigrationStatus struct {
+	Version    uint         // the version of the last migration applied, or zero if none have been
+	Dirty      bool         // true if the last migration failed part way through, and the schema needs repair
+	Required   uint         // the version this build of FireFly requires
+	Migrations []*Migration // the migrations available, in version order
+}
+
+// Migration is a single numbered change to the schema
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
 type iNamespaceCollection interface {
 	// UpsertNamespace - Upsert a namespace
 	// Throws IDMismatch error if updating and ids don't match
@@ -407,6 +444,9 @@ type iTokenBalanceCollection interface {
 	// UpdateTokenBalances - Move some token balance from one account to another
 	UpdateTokenBalances(ctx context.Context, transfer *core.TokenTransfer) error
 
+	// InsertTokenBalance - Insert a token balance directly, rather than calculating it from a transfer
+	InsertTokenBalance(ctx context.Context, balance *core.TokenBalance) error
+
 	// GetTokenBalance - Get a token balance by pool and account identity
 	GetTokenBalance(ctx context.Context, poolID *fftypes.UUID, tokenIndex, identity string) (*core.TokenBalance, error)
 
@@ -471,6 +511,11 @@ type iFFIEventCollection interface {
 	GetFFIEvents(ctx context.Context, filter Filter) (events []*core.FFIEvent, res *FilterResult, err error)
 }
 
+type iFFIErrorCollection interface {
+	UpsertFFIError(ctx context.Context, errorDef *core.FFIError) error
+	GetFFIErrors(ctx context.Context, filter Filter) (errors []*core.FFIError, res *FilterResult, err error)
+}
+
 type iContractAPICollection interface {
 	UpsertContractAPI(ctx context.Context, cd *core.ContractAPI) error
 	GetContractAPIs(ctx context.Context, ns string, filter AndFilter) ([]*core.ContractAPI, *FilterResult, error)
@@ -512,10 +557,38 @@ type iBlockchainEventCollection interface {
 	GetBlockchainEvents(ctx context.Context, filter Filter) ([]*core.BlockchainEvent, *FilterResult, error)
 }
 
+type iDeadLetterCollection interface {
+	// InsertDeadLetter - insert a record of an event that could not be delivered to a subscription
+	InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (err error)
+
+	// GetDeadLetterByID - get dead letter by ID
+	GetDeadLetterByID(ctx context.Context, id *fftypes.UUID) (*core.DeadLetter, error)
+
+	// GetDeadLetters - get dead letters
+	GetDeadLetters(ctx context.Context, filter Filter) ([]*core.DeadLetter, *FilterResult, error)
+}
+
+type iRetentionCollection interface {
+	// PruneCollection - delete rows from the events, messages, operations or blockchainevents collection in a namespace,
+	// returning the number of rows deleted. A deleted change event is emitted for each row.
+	PruneCollection(ctx context.Context, ns string, collection CollectionName, criteria *PruneCriteria) (int64, error)
+}
+
 // PersistenceInterface are the operations that must be implemented by a database interface plugin.
 type iChartCollection interface {
-	// GetChartHistogram - Get charting data for a histogram
-	GetChartHistogram(ctx context.Context, ns string, intervals []core.ChartHistogramInterval, collection CollectionName) ([]*core.ChartHistogram, error)
+	// GetChartHistogram - Get charting data for a histogram, with the count in each bucket broken down by the groupBy field
+	GetChartHistogram(ctx context.Context, ns string, intervals []core.ChartHistogramInterval, collection CollectionName, groupBy string) ([]*core.ChartHistogram, error)
+}
+
+type iAggregateCollection interface {
+	// GetAggregate - Get the count of records matching the filter, and the requested metrics, grouped by the values of the groupBy fields
+	GetAggregate(ctx context.Context, collection CollectionName, query *core.AggregateQuery, filter Filter) ([]*core.AggregateResult, error)
+}
+
+type iSearchCollection interface {
+	// Search - Full-text search of the messages, data, identities, token pools and FFIs in a namespace, returning
+	// the best matches first. Only the type, ID and rank of each hit are set
+	Search(ctx context.Context, ns, text string, types []core.SearchHitType, limit int) ([]*core.SearchHit, error)
 }
 
 // PeristenceInterface are the operations that must be implemented by a database interfavce plugin.
@@ -578,10 +651,26 @@ type PersistenceInterface interface {
 	iFFICollection
 	iFFIMethodCollection
 	iFFIEventCollection
+	iFFIErrorCollection
 	iContractAPICollection
 	iContractListenerCollection
 	iBlockchainEventCollection
+	iDeadLetterCollection
 	iChartCollection
+	iAggregateCollection
+	iSearchCollection
+	iRetentionCollection
+}
+
+// PruneCriteria selects rows to remove from a collection. Rows are pruned if they were created before Before,
+// or if they are older than the newest MaxRows rows in the namespace. Rows with a sequence greater than MaxSequence,
+// or created at or after NotAfter, are never pruned - so history that has not been processed is kept.
+type PruneCriteria struct {
+	Before      *fftypes.FFTime // nil for no age limit
+	MaxRows     int64           // zero for no limit on the number of rows
+	MaxSequence int64           // negative for no sequence limit
+	NotAfter    *fftypes.FFTime // nil for no time limit
+	Limit       int             // the maximum number of rows to delete in one call
 }
 
 // CollectionName represents all collections
@@ -624,9 +713,11 @@ const (
 	CollectionFFIs              UUIDCollectionNS = "ffi"
 	CollectionFFIMethods        UUIDCollectionNS = "ffimethods"
 	CollectionFFIEvents         UUIDCollectionNS = "ffievents"
+	CollectionFFIErrors         UUIDCollectionNS = "ffierrors"
 	CollectionContractAPIs      UUIDCollectionNS = "contractapis"
 	CollectionContractListeners UUIDCollectionNS = "contractlisteners"
 	CollectionIdentities        UUIDCollectionNS = "identities"
+	CollectionDeadLetters       UUIDCollectionNS = "deadletters"
 )
 
 // HashCollectionNS is a collection where the primary key is a hash, such that it can
@@ -717,6 +808,7 @@ var MessageQueryFactory = &queryFields{
 	"sequence":  &Int64Field{},
 	"txtype":    &StringField{},
 	"batch":     &UUIDField{},
+	"value":     &JSONField{},
 }
 
 // BatchQueryFactory filter fields for batches
@@ -1008,6 +1100,16 @@ var FFIEventQueryFactory = &queryFields{
 	"description": &StringField{},
 }
 
+// FFIErrorQueryFactory filter fields for contract errors
+var FFIErrorQueryFactory = &queryFields{
+	"id":          &UUIDField{},
+	"namespace":   &StringField{},
+	"name":        &StringField{},
+	"pathname":    &StringField{},
+	"interface":   &UUIDField{},
+	"description": &StringField{},
+}
+
 // ContractListenerQueryFactory filter fields for contract listeners
 var ContractListenerQueryFactory = &queryFields{
 	"id":        &UUIDField{},
@@ -1036,6 +1138,18 @@ var BlockchainEventQueryFactory = &queryFields{
 	"timestamp":       &TimeField{},
 }
 
+// DeadLetterQueryFactory filter fields for dead letters
+var DeadLetterQueryFactory = &queryFields{
+	"id":           &UUIDField{},
+	"namespace":    &StringField{},
+	"subscription": &UUIDField{},
+	"name":         &StringField{},
+	"transport":    &StringField{},
+	"event":        &UUIDField{},
+	"attempts":     &Int64Field{},
+	"created":      &TimeField{},
+}
+
 // ContractAPIQueryFactory filter fields for Contract APIs
 var ContractAPIQueryFactory = &queryFields{
 	"id":        &UUIDField{},
@@ -1043,3 +1157,19 @@ var ContractAPIQueryFactory = &queryFields{
 	"namespace": &StringField{},
 	"interface": &UUIDField{},
 }
+
+// AggregateQueryFactories filter fields for the collections that support aggregation
+var AggregateQueryFactories = map[CollectionName]QueryFactory{
+	CollectionName(CollectionMessages):         MessageQueryFactory,
+	CollectionName(CollectionTransactions):     TransactionQueryFactory,
+	CollectionName(CollectionOperations):       OperationQueryFactory,
+	CollectionName(CollectionEvents):           EventQueryFactory,
+	CollectionName(CollectionTokenTransfers):   TokenTransferQueryFactory,
+	CollectionName(CollectionTokenApprovals):   TokenApprovalQueryFactory,
+	CollectionName(CollectionTokenPools):       TokenPoolQueryFactory,
+	CollectionName(CollectionBlockchainEvents): BlockchainEventQueryFactory,
+	CollectionName(CollectionPins):             PinQueryFactory,
+	CollectionName(CollectionIdentities):       IdentityQueryFactory,
+	CollectionName(CollectionBatches):          BatchQueryFactory,
+	CollectionName(CollectionData):             DataQueryFactory,
+}
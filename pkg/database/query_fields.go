@@ -300,6 +300,15 @@ func (f *JSONField) getSerialization() FieldSerialization { return &jsonField{}
 func (f *JSONField) filterAsString() bool                 { return true }
 func (f *JSONField) description() string                  { return "JSON-blob" }
 
+// jsonPathField is a value inside a JSON field, filtered by a path such as "value.orderId".
+// Values are compared as strings, or as numbers for the ordering operators when the
+// filter value is numeric
+type jsonPathField struct{}
+
+func (f *jsonPathField) getSerialization() FieldSerialization { return &stringField{} }
+func (f *jsonPathField) filterAsString() bool                 { return true }
+func (f *jsonPathField) description() string                  { return "JSON-path" }
+
 type FFStringArrayField struct{}
 type ffNameArrayField struct{ na core.FFStringArray }
 
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/core"
 )
 
@@ -34,7 +35,7 @@ type Plugin interface {
 
 	// Init initializes the plugin, with configuration
 	// Returns the supported featureset of the interface
-	Init(ctx context.Context, config config.Section, callbacks Callbacks) error
+	Init(ctx context.Context, config config.Section, callbacks Callbacks, metrics metrics.Manager) error
 
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
@@ -44,8 +45,13 @@ type Plugin interface {
 	ValidateOptions(options *core.SubscriptionOptions) error
 
 	// DeliveryRequest requests delivery of work on a connection, which must later be responded to
-	// Data will only be supplied as non-nil if the subscription is set to include data
-	DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error
+	// Data will only be supplied as non-nil if the subscription is set to include data.
+	// The context is cancelled when the dispatcher for the subscription closes, and any delivery still blocked should be abandoned
+	DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error
+
+	// BatchDeliveryRequest requests delivery of a batch of events on a connection, which must later each be responded to
+	// Only called for subscriptions with batch delivery enabled, on plugins that report the BatchDelivery capability
+	BatchDeliveryRequest(connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error
 }
 
 type SubscriptionMatcher func(core.SubscriptionRef) bool
@@ -62,6 +68,11 @@ type Callbacks interface {
 	// EphemeralSubscription creates an ephemeral (non-durable) subscription, and associates it with a connection
 	EphemeralSubscription(connID, namespace string, filter *core.SubscriptionFilter, options *core.SubscriptionOptions) error
 
+	// RewindSubscription moves the offset of a durable subscription back to the supplied event sequence, if it has moved past it.
+	// For a "connect-in" style plugin where the client application supplies the position it wants to resume from.
+	// Only subscriptions delivered over the calling plugin can be rewound
+	RewindSubscription(namespace, name string, sequence int64) error
+
 	// ConnectionClosed is a notification that a connection has closed, and all dispatchers should be re-allocated.
 	// Note the plugin must not crash if it receives PublishEvent calls on the connID after the ConnectionClosed event is fired
 	ConnectionClosed(connID string)
@@ -73,6 +84,15 @@ type Callbacks interface {
 	// - Reject it: This resets the associated subscription back to the last committed offset
 	//   * Note all message since the last committed offet will be redelivered, so additional messages to be redelivered if streaming ahead
 	DeliveryResponse(connID string, inflight *core.EventDeliveryResponse)
+
+	// DeadLetter records an event the plugin has given up trying to deliver, so it can be queried later.
+	// The plugin should still acknowledge the event via DeliveryResponse once this returns successfully,
+	// or reject it if an error is returned so that it is not lost.
+	DeadLetter(connID string, deadLetter *core.DeadLetter) error
 }
 
-type Capabilities struct{}
+// Capabilities defines the capabilities a plugin can report as implementing or not
+type Capabilities struct {
+	// BatchDelivery is true if the plugin can deliver multiple events in a single BatchDeliveryRequest
+	BatchDelivery bool
+}